@@ -0,0 +1,139 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// walletCache is the optional in-memory cache behind Get and List. Entries
+// expire after ttl. Registering or unregistering an asset drops the cached
+// Get result for that wallet and every cached List, since any list may
+// include it. A nil *walletCache is a disabled cache.
+type walletCache struct {
+	ttl time.Duration
+	now func() time.Time // overridden in tests
+
+	mu      sync.Mutex
+	wallets map[walletCacheKey]cachedWallet
+	lists   map[string]cachedList // keyed by encoded list query
+}
+
+type walletCacheKey struct {
+	address string
+	network string
+}
+
+type cachedWallet struct {
+	wallet  *Wallet
+	expires time.Time
+}
+
+type cachedList struct {
+	wallets []*Wallet
+	expires time.Time
+}
+
+// newWalletCache returns a cache with the given TTL, or nil (disabled) if ttl
+// is not positive.
+func newWalletCache(ttl time.Duration) *walletCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &walletCache{
+		ttl:     ttl,
+		now:     time.Now,
+		wallets: make(map[walletCacheKey]cachedWallet),
+		lists:   make(map[string]cachedList),
+	}
+}
+
+func (c *walletCache) getWallet(address, network string) (*Wallet, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := walletCacheKey{address, network}
+	entry, ok := c.wallets[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.wallets, key)
+		return nil, false
+	}
+	return copyWallet(entry.wallet), true
+}
+
+func (c *walletCache) putWallet(address, network string, w *Wallet) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.wallets[walletCacheKey{address, network}] = cachedWallet{
+		wallet:  copyWallet(w),
+		expires: c.now().Add(c.ttl),
+	}
+}
+
+func (c *walletCache) getList(query string) ([]*Wallet, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lists[query]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.lists, query)
+		return nil, false
+	}
+	return copyWallets(entry.wallets), true
+}
+
+func (c *walletCache) putList(query string, wallets []*Wallet) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lists[query] = cachedList{
+		wallets: copyWallets(wallets),
+		expires: c.now().Add(c.ttl),
+	}
+}
+
+// invalidate drops everything that may describe the wallet at address on
+// network.
+func (c *walletCache) invalidate(address, network string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.wallets, walletCacheKey{address, network})
+	clear(c.lists)
+}
+
+// copyWallet returns a shallow copy so callers can't modify a cached entry's
+// fields. Metadata and Tags are shared and must be treated as read-only.
+func copyWallet(w *Wallet) *Wallet {
+	cp := *w
+	return &cp
+}
+
+func copyWallets(wallets []*Wallet) []*Wallet {
+	out := make([]*Wallet, len(wallets))
+	for i, w := range wallets {
+		out[i] = copyWallet(w)
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// walletServer serves Get, List, register and unregister, counting the
+// read requests that reach it.
+func walletServer(t *testing.T, reads *atomic.Int32) *httptest.Server {
+	t.Helper()
+	wallet := map[string]interface{}{
+		"address":    "wallet123",
+		"network":    "mainnet",
+		"asset_type": "sol",
+		"status":     "active",
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v1/wallet-assets":
+			reads.Add(1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"wallets": []interface{}{wallet}})
+		case r.Method == "GET":
+			reads.Add(1)
+			json.NewEncoder(w).Encode(wallet)
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestCache_DisabledByDefault(t *testing.T) {
+	var reads atomic.Int32
+	server := walletServer(t, &reads)
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(ctx, "wallet123", "mainnet")
+		require.NoError(t, err)
+		_, err = client.List(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), reads.Load())
+}
+
+func TestCache_Hits(t *testing.T) {
+	var reads atomic.Int32
+	server := walletServer(t, &reads)
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithCache(time.Minute))
+	ctx := context.Background()
+
+	w1, err := client.Get(ctx, "wallet123", "mainnet")
+	require.NoError(t, err)
+	w1.Status = "modified" // must not leak into the cache
+
+	w2, err := client.Get(ctx, "wallet123", "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, "active", w2.Status)
+	assert.Equal(t, int32(1), reads.Load())
+
+	// Different network, tags or flags are different entries.
+	_, err = client.Get(ctx, "wallet123", "devnet")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), reads.Load())
+
+	_, err = client.List(ctx)
+	require.NoError(t, err)
+	_, err = client.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), reads.Load())
+
+	_, err = client.ListWithOptions(ctx, ListOptions{Tags: []string{"customer:acme"}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), reads.Load())
+}
+
+func TestCache_TTLExpiry(t *testing.T) {
+	var reads atomic.Int32
+	server := walletServer(t, &reads)
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithCache(time.Minute))
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client.cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "wallet123", "mainnet")
+	require.NoError(t, err)
+	_, err = client.List(ctx)
+	require.NoError(t, err)
+
+	now = now.Add(59 * time.Second)
+	_, err = client.Get(ctx, "wallet123", "mainnet")
+	require.NoError(t, err)
+	_, err = client.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), reads.Load(), "still fresh")
+
+	now = now.Add(time.Second)
+	_, err = client.Get(ctx, "wallet123", "mainnet")
+	require.NoError(t, err)
+	_, err = client.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), reads.Load(), "expired")
+}
+
+func TestCache_Invalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Client) error
+	}{
+		{
+			name: "register",
+			mutate: func(c *Client) error {
+				return c.RegisterAsset(context.Background(), "wallet123", "mainnet", "sol", "")
+			},
+		},
+		{
+			name: "unregister",
+			mutate: func(c *Client) error {
+				return c.UnregisterAsset(context.Background(), "wallet123", "mainnet", "sol", "")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reads atomic.Int32
+			server := walletServer(t, &reads)
+			defer server.Close()
+
+			client := NewClient(server.URL, nil, nil, WithCache(time.Minute))
+			ctx := context.Background()
+
+			_, err := client.Get(ctx, "wallet123", "mainnet")
+			require.NoError(t, err)
+			_, err = client.Get(ctx, "wallet123", "devnet")
+			require.NoError(t, err)
+			_, err = client.List(ctx)
+			require.NoError(t, err)
+			require.Equal(t, int32(3), reads.Load())
+
+			require.NoError(t, tt.mutate(client))
+
+			_, err = client.Get(ctx, "wallet123", "mainnet")
+			require.NoError(t, err)
+			_, err = client.List(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, int32(5), reads.Load(), "changed wallet and lists are refetched")
+
+			_, err = client.Get(ctx, "wallet123", "devnet")
+			require.NoError(t, err)
+			assert.Equal(t, int32(5), reads.Load(), "other networks stay cached")
+		})
+	}
+}
+
+func TestCache_ErrorsNotCached(t *testing.T) {
+	var reads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "wallet not found"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithCache(time.Minute))
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), "wallet123", "mainnet")
+		require.Error(t, err)
+	}
+	assert.Equal(t, int32(2), reads.Load())
+}
+
+func TestCache_ConcurrentAccess(t *testing.T) {
+	var reads atomic.Int32
+	server := walletServer(t, &reads)
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithCache(time.Minute))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%5 == 0 {
+				assert.NoError(t, client.RegisterAsset(ctx, "wallet123", "mainnet", "sol", ""))
+				return
+			}
+			_, err := client.Get(ctx, "wallet123", "mainnet")
+			assert.NoError(t, err)
+			_, err = client.List(ctx)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// CompileJQ parses and compiles jq expressions for MemoMatchesJQ.
+func CompileJQ(exprs []string) ([]*gojq.Code, error) {
+	codes := make([]*gojq.Code, len(exprs))
+	for i, expr := range exprs {
+		query, err := gojq.Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jq filter %q: %w", expr, err)
+		}
+		codes[i], err = gojq.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile jq filter %q: %w", expr, err)
+		}
+	}
+	return codes, nil
+}
+
+// MemoMatchesJQ reports whether every jq filter evaluates truthy against memo
+// parsed as JSON. Missing memos, memos that aren't valid JSON and truncated
+// memos never match: a truncated memo is not the payload the sender wrote,
+// even when the cut happens to leave valid JSON. A filter that errors or
+// yields nothing doesn't match.
+func MemoMatchesJQ(memo *string, memoTruncated bool, filters []*gojq.Code) bool {
+	if memo == nil || memoTruncated {
+		return false
+	}
+
+	var memoJSON interface{}
+	if err := json.Unmarshal([]byte(*memo), &memoJSON); err != nil {
+		return false
+	}
+
+	for _, code := range filters {
+		v, ok := code.Run(memoJSON).Next()
+		if !ok {
+			return false
+		}
+		if _, isErr := v.(error); isErr {
+			return false
+		}
+		if !JQTruthy(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// JQTruthy reports whether a jq result is truthy. As in jq, false and null
+// are falsy and everything else (numbers, strings, objects, arrays) is
+// truthy.
+func JQTruthy(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return true
+}
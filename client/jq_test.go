@@ -0,0 +1,47 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoMatchesJQ(t *testing.T) {
+	filters, err := CompileJQ([]string{`.workflow_id == "test-123"`, `.amount_usd`})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		memo      *string
+		truncated bool
+		want      bool
+	}{
+		{"all filters truthy", stringPtr(`{"workflow_id": "test-123", "amount_usd": 5}`), false, true},
+		{"one filter falsy", stringPtr(`{"workflow_id": "test-123", "amount_usd": null}`), false, false},
+		{"mismatch", stringPtr(`{"workflow_id": "other", "amount_usd": 5}`), false, false},
+		{"no memo", nil, false, false},
+		{"invalid JSON", stringPtr(`not-json`), false, false},
+		{"truncated", stringPtr(`{"workflow_id": "test-123", "amount_usd": 5}`), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MemoMatchesJQ(tt.memo, tt.truncated, filters))
+		})
+	}
+}
+
+func TestCompileJQ_Invalid(t *testing.T) {
+	_, err := CompileJQ([]string{`.ok`, `.[`})
+	assert.ErrorContains(t, err, `".["`)
+}
+
+func TestJQTruthy(t *testing.T) {
+	assert.False(t, JQTruthy(nil))
+	assert.False(t, JQTruthy(false))
+	assert.True(t, JQTruthy(true))
+	assert.True(t, JQTruthy(0))
+	assert.True(t, JQTruthy(""))
+	assert.True(t, JQTruthy([]interface{}{}))
+}
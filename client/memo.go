@@ -0,0 +1,54 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MemoMatchMode controls how a transaction memo is compared with an expected
+// memo.
+type MemoMatchMode string
+
+const (
+	// MemoMatchExact requires the memo to equal the expected memo. It is the
+	// default and the safest mode.
+	MemoMatchExact MemoMatchMode = "exact"
+	// MemoMatchPrefix requires the memo to start with the expected memo.
+	MemoMatchPrefix MemoMatchMode = "prefix"
+	// MemoMatchContains requires the memo to contain the expected memo, for
+	// wallets that wrap the memo in text of their own.
+	MemoMatchContains MemoMatchMode = "contains"
+)
+
+// ParseMemoMatchMode parses a memo match mode. An empty string means
+// MemoMatchExact.
+func ParseMemoMatchMode(s string) (MemoMatchMode, error) {
+	switch mode := MemoMatchMode(s); mode {
+	case "":
+		return MemoMatchExact, nil
+	case MemoMatchExact, MemoMatchPrefix, MemoMatchContains:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid memo match mode %q: must be exact, prefix, or contains", s)
+	}
+}
+
+// MemoMatches reports whether memo matches want under mode. A nil memo never
+// matches, and an empty or unknown mode is treated as MemoMatchExact.
+//
+// Prefix and contains also accept a memo that extends want, so want should end
+// in something that can't be extended into another valid memo (e.g. a
+// fixed-length ID).
+func MemoMatches(memo *string, want string, mode MemoMatchMode) bool {
+	if memo == nil {
+		return false
+	}
+	switch mode {
+	case MemoMatchPrefix:
+		return strings.HasPrefix(*memo, want)
+	case MemoMatchContains:
+		return strings.Contains(*memo, want)
+	default:
+		return *memo == want
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoMatches(t *testing.T) {
+	want := "forohtoo-reg:abc123"
+
+	tests := []struct {
+		name string
+		memo *string
+		mode MemoMatchMode
+		want bool
+	}{
+		{"exact", stringPtr(want), MemoMatchExact, true},
+		{"exact is the default", stringPtr(want), "", true},
+		{"exact rejects wrong memo", stringPtr("forohtoo-reg:xyz789"), MemoMatchExact, false},
+		{"exact rejects wrapped memo", stringPtr("phantom: forohtoo-reg:abc123"), MemoMatchExact, false},
+		{"exact rejects suffixed memo", stringPtr("forohtoo-reg:abc123 thanks"), MemoMatchExact, false},
+		{"prefix matches suffixed memo", stringPtr("forohtoo-reg:abc123 thanks"), MemoMatchPrefix, true},
+		{"prefix rejects wrapped memo", stringPtr("phantom: forohtoo-reg:abc123"), MemoMatchPrefix, false},
+		{"prefix rejects wrong memo", stringPtr("forohtoo-reg:xyz789"), MemoMatchPrefix, false},
+		{"contains matches wrapped memo", stringPtr("phantom: forohtoo-reg:abc123"), MemoMatchContains, true},
+		{"contains rejects wrong memo", stringPtr("phantom: forohtoo-reg:xyz789"), MemoMatchContains, false},
+		{"nil memo never matches", nil, MemoMatchContains, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MemoMatches(tt.memo, want, tt.mode))
+		})
+	}
+}
+
+func TestParseMemoMatchMode(t *testing.T) {
+	for in, want := range map[string]MemoMatchMode{
+		"":         MemoMatchExact,
+		"exact":    MemoMatchExact,
+		"prefix":   MemoMatchPrefix,
+		"contains": MemoMatchContains,
+	} {
+		mode, err := ParseMemoMatchMode(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, mode)
+	}
+
+	_, err := ParseMemoMatchMode("regex")
+	assert.Error(t, err)
+}
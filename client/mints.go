@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SupportedMint is an SPL token mint that wallets may register for on a network.
+// Configured mints come from the server's environment and can't be removed via the API.
+type SupportedMint struct {
+	Network    string     `json:"network"`
+	Mint       string     `json:"mint"`
+	Configured bool       `json:"configured"`
+	Decimals   *int       `json:"decimals,omitempty"` // resolved on-chain; nil if unknown
+	Symbol     *string    `json:"symbol,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+}
+
+// ListSupportedMints retrieves the mints the server accepts for registration.
+// If network is empty, mints for all networks are returned.
+func (c *Client) ListSupportedMints(ctx context.Context, network string) ([]*SupportedMint, error) {
+	u := c.baseURL + "/api/v1/supported-mints"
+	if network != "" {
+		u += "?network=" + url.QueryEscape(network)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var response struct {
+		Mints []*SupportedMint `json:"mints"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Mints, nil
+}
+
+// AddSupportedMint adds a mint to the server's supported-mints registry.
+// Adding a mint that is already supported is a no-op.
+func (c *Client) AddSupportedMint(ctx context.Context, network string, mint string) (*SupportedMint, error) {
+	body, err := json.Marshal(map[string]string{
+		"network": network,
+		"mint":    mint,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/supported-mints", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var m SupportedMint
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	c.logger.Debug("supported mint added", "network", network, "mint", mint)
+	return &m, nil
+}
+
+// RemoveSupportedMint removes a mint from the server's supported-mints registry.
+// Existing wallet registrations for the mint are unaffected.
+func (c *Client) RemoveSupportedMint(ctx context.Context, network string, mint string) error {
+	u := fmt.Sprintf("%s/api/v1/supported-mints/%s?network=%s", c.baseURL, url.PathEscape(mint), url.QueryEscape(network))
+	req, err := http.NewRequestWithContext(ctx, "DELETE", u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return c.parseErrorResponse(resp)
+	}
+
+	c.logger.Debug("supported mint removed", "network", network, "mint", mint)
+	return nil
+}
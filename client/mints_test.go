@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSupportedMints_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/supported-mints", r.URL.Path)
+		assert.Equal(t, "devnet", r.URL.Query().Get("network"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"mints": []map[string]interface{}{
+				{"network": "devnet", "mint": "usdc-devnet", "configured": true},
+				{"network": "devnet", "mint": "bonk-devnet", "configured": false},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	mints, err := client.ListSupportedMints(context.Background(), "devnet")
+	require.NoError(t, err)
+	require.Len(t, mints, 2)
+
+	assert.Equal(t, "usdc-devnet", mints[0].Mint)
+	assert.True(t, mints[0].Configured)
+	assert.Equal(t, "bonk-devnet", mints[1].Mint)
+	assert.False(t, mints[1].Configured)
+}
+
+func TestAddSupportedMint_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/supported-mints", r.URL.Path)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "mainnet", body["network"])
+		assert.Equal(t, "mint123", body["mint"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"network":    "mainnet",
+			"mint":       "mint123",
+			"configured": false,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	mint, err := client.AddSupportedMint(context.Background(), "mainnet", "mint123")
+	require.NoError(t, err)
+	assert.Equal(t, "mint123", mint.Mint)
+	assert.Equal(t, "mainnet", mint.Network)
+}
+
+func TestRemoveSupportedMint_ConfiguredMintRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/api/v1/supported-mints/usdc", r.URL.Path)
+		assert.Equal(t, "mainnet", r.URL.Query().Get("network"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "mint is configured via the environment and cannot be removed",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	err := client.RemoveSupportedMint(context.Background(), "mainnet", "usdc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be removed")
+}
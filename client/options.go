@@ -0,0 +1,130 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Option configures a Client. Transport options apply to both regular
+// requests and SSE streams (Await).
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	transport  http.RoundTripper
+	tlsConfig  *tls.Config
+	http2      *bool
+	keepAlives *bool
+	cacheTTL   time.Duration
+	apiKey     string
+
+	reconnectPolicy *ReconnectPolicy
+}
+
+// WithTransport uses rt for all requests. It takes precedence over
+// WithTLSConfig, WithHTTP2 and WithKeepAlives.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *clientOptions) { o.transport = rt }
+}
+
+// WithTLSConfig sets the TLS configuration, e.g. a RootCAs pool for servers
+// behind an internal CA or a VerifyPeerCertificate hook for certificate pinning.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *clientOptions) { o.tlsConfig = cfg }
+}
+
+// WithHTTP2 enables or disables HTTP/2 over TLS. HTTP/2 lets concurrent SSE
+// streams share one connection. HTTP/1.1 is always allowed as a fallback.
+func WithHTTP2(enabled bool) Option {
+	return func(o *clientOptions) { o.http2 = &enabled }
+}
+
+// WithKeepAlives enables or disables connection reuse between requests.
+func WithKeepAlives(enabled bool) Option {
+	return func(o *clientOptions) { o.keepAlives = &enabled }
+}
+
+// WithCache caches Get and List responses in memory for ttl, for read-heavy
+// consumers such as dashboards that poll wallet state. RegisterAsset* and
+// UnregisterAsset invalidate entries for the wallet they change, but changes
+// made by other clients are only seen once an entry expires. A ttl of zero
+// (the default) disables caching.
+func WithCache(ttl time.Duration) Option {
+	return func(o *clientOptions) { o.cacheTTL = ttl }
+}
+
+// WithAPIKey sends key as "Authorization: Bearer <key>" on every request,
+// SSE streams included, for servers that set API_KEY. Requests that already
+// carry an Authorization header keep it.
+func WithAPIKey(key string) Option {
+	return func(o *clientOptions) { o.apiKey = key }
+}
+
+// WithReconnectPolicy sets how Await reopens its stream after it drops
+// without a reconnect event or can't be reopened, e.g. while the server
+// restarts. The default is DefaultReconnectPolicy.
+func WithReconnectPolicy(p ReconnectPolicy) Option {
+	return func(o *clientOptions) { o.reconnectPolicy = &p }
+}
+
+// transportFor returns the RoundTripper described by o, derived from base
+// (the http.Client's existing transport). It returns base unchanged when no
+// transport options were given.
+func (o *clientOptions) transportFor(base http.RoundTripper) http.RoundTripper {
+	rt := o.baseTransport(base)
+	if o.apiKey == "" {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &apiKeyTransport{base: rt, key: o.apiKey}
+}
+
+// baseTransport applies the transport options to base.
+func (o *clientOptions) baseTransport(base http.RoundTripper) http.RoundTripper {
+	if o.transport != nil {
+		return o.transport
+	}
+	if o.tlsConfig == nil && o.http2 == nil && o.keepAlives == nil {
+		return base
+	}
+
+	var t *http.Transport
+	if bt, ok := base.(*http.Transport); ok {
+		t = bt.Clone()
+	} else {
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	if o.tlsConfig != nil {
+		t.TLSClientConfig = o.tlsConfig.Clone()
+	}
+	if o.http2 != nil {
+		var protocols http.Protocols
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(*o.http2)
+		t.Protocols = &protocols
+	}
+	if o.keepAlives != nil {
+		t.DisableKeepAlives = !*o.keepAlives
+	}
+	return t
+}
+
+// apiKeyTransport adds the API key to requests without an Authorization
+// header.
+type apiKeyTransport struct {
+	base http.RoundTripper
+	key  string
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.key)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingTransport struct {
+	calls atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewClient_WithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/stream/transactions/wallet123" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("event: transaction\ndata: {\"signature\":\"sig1\"}\n\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"wallets":[]}`))
+	}))
+	defer server.Close()
+
+	rt := &countingTransport{}
+	client := NewClient(server.URL, nil, nil, WithTransport(rt))
+
+	_, err := client.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), rt.calls.Load())
+
+	// SSE streams use the same transport.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = client.Await(ctx, "wallet123", "mainnet", 0, func(*Transaction) bool { return true })
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), rt.calls.Load())
+}
+
+func TestNewClient_DoesNotModifyCallerClient(t *testing.T) {
+	hc := &http.Client{Timeout: 5 * time.Second}
+	client := NewClient("http://example.com", hc, nil, WithKeepAlives(false))
+
+	assert.Nil(t, hc.Transport)
+	require.IsType(t, &http.Transport{}, client.httpClient.Transport)
+	assert.True(t, client.httpClient.Transport.(*http.Transport).DisableKeepAlives)
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+}
+
+func TestNewClient_WithTLSConfigAndHTTP2(t *testing.T) {
+	var proto atomic.Value
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proto.Store(r.Proto)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"wallets":[]}`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	// Without the server's CA the request fails verification.
+	_, err := NewClient(server.URL, nil, nil).List(context.Background())
+	require.Error(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	tlsConfig := &tls.Config{RootCAs: roots}
+
+	_, err = NewClient(server.URL, nil, nil, WithTLSConfig(tlsConfig), WithHTTP2(true)).List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", proto.Load())
+
+	_, err = NewClient(server.URL, nil, nil, WithTLSConfig(tlsConfig), WithHTTP2(false)).List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", proto.Load())
+}
+
+func TestNewClient_WithAPIKey(t *testing.T) {
+	var auths []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auths = append(auths, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer k3y" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"unauthorized"}`))
+			return
+		}
+		if r.URL.Path == "/api/v1/stream/transactions/wallet123" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("event: transaction\ndata: {\"signature\":\"sig1\"}\n\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"wallets":[]}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, nil, nil).List(context.Background())
+	require.Error(t, err, "server rejects requests without the key")
+
+	client := NewClient(server.URL, nil, nil, WithAPIKey("k3y"))
+	_, err = client.List(context.Background())
+	require.NoError(t, err)
+
+	// SSE streams carry the key too.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = client.Await(ctx, "wallet123", "mainnet", 0, func(*Transaction) bool { return true })
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"", "Bearer k3y", "Bearer k3y"}, auths)
+}
+
+func TestAPIKeyTransport_KeepsExplicitAuthorization(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithAPIKey("k3y"))
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer admin")
+
+	resp, err := client.httpClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Bearer admin", got)
+}
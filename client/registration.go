@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Registration statuses reported by GetRegistrationStatus.
+const (
+	RegistrationPending   = "pending"   // waiting for the fee payment
+	RegistrationExpired   = "expired"   // invoice expired; a late payment is still accepted for a grace period
+	RegistrationCompleted = "completed" // paid and registered
+	RegistrationFailed    = "failed"
+)
+
+// Stages of a pending registration, from the server's progress query.
+const (
+	RegistrationStageAwaitingPayment = "awaiting_payment"
+	RegistrationStageRegistering     = "registering" // payment detected
+)
+
+// Invoice is the fee the server's payment gateway charges to register a new
+// wallet. Pay Amount (in base units of the fee asset) to PayToAddress with
+// Memo before AcceptUntil.
+type Invoice struct {
+	ID           string    `json:"id"`
+	PayToAddress string    `json:"pay_to_address"`
+	PayToAccount string    `json:"pay_to_account"` // account credited: the ATA for tokens, the wallet for SOL
+	Network      string    `json:"network"`        // network the fee is paid on
+	AssetType    string    `json:"asset_type"`
+	TokenMint    string    `json:"token_mint,omitempty"`
+	Amount       int64     `json:"amount"`
+	FeeTier      string    `json:"fee_tier,omitempty"` // fee tier charged, if any
+	FeeReason    string    `json:"fee_reason"`         // why Amount applies
+	Decimals     int       `json:"decimals"`
+	AmountUI     float64   `json:"amount_ui"`
+	Memo         string    `json:"memo"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	AcceptUntil  time.Time `json:"accept_until"`
+	StatusURL    string    `json:"status_url"`
+	PaymentURL   string    `json:"payment_url"` // Solana Pay URL
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PaymentRequired is the server's 402 answer to registering a new wallet
+// while its payment gateway is on. Follow it with GetRegistrationStatus.
+type PaymentRequired struct {
+	Invoice    Invoice `json:"invoice"`
+	WorkflowID string  `json:"workflow_id"`
+	StatusURL  string  `json:"status_url"`
+}
+
+// RegistrationStatus is the progress of a payment-gated registration, as
+// returned by the status_url in a 402 Payment Required response.
+type RegistrationStatus struct {
+	WorkflowID string     `json:"workflow_id"`
+	Status     string     `json:"status"`               // pending, expired, completed or failed
+	Stage      string     `json:"stage,omitempty"`      // while pending or expired; empty from older servers
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // while pending or expired
+	// PaymentMemo is the memo the payment must carry while pending, and the
+	// memo as observed on-chain once completed.
+	PaymentMemo      string     `json:"payment_memo,omitempty"`
+	PaymentSignature string     `json:"payment_signature,omitempty"` // set once payment is detected
+	PaymentAmount    int64      `json:"payment_amount,omitempty"`    // in base units of the fee asset
+	Address          string     `json:"address,omitempty"`
+	Network          string     `json:"network,omitempty"`
+	AssetType        string     `json:"asset_type,omitempty"`
+	TokenMint        string     `json:"token_mint,omitempty"`
+	RegisteredAt     *time.Time `json:"registered_at,omitempty"`
+	Error            string     `json:"error,omitempty"`
+}
+
+// ErrRegistrationFailed is wrapped by the error WaitForRegistration returns
+// when the registration fails, e.g. because the invoice was never paid.
+var ErrRegistrationFailed = errors.New("registration failed")
+
+// Done reports whether the registration has finished, successfully or not.
+func (s *RegistrationStatus) Done() bool {
+	return s.Status == RegistrationCompleted || s.Status == RegistrationFailed
+}
+
+// GetRegistrationStatus retrieves the status of a payment-gated registration
+// by the workflow_id returned with its invoice. The server must have the
+// payment gateway enabled.
+func (c *Client) GetRegistrationStatus(ctx context.Context, workflowID string) (*RegistrationStatus, error) {
+	u := c.baseURL + "/api/v1/registration-status/" + url.PathEscape(workflowID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var status RegistrationStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &status, nil
+}
+
+// WaitForRegistration checks the status of a payment-gated registration every
+// pollInterval until it completes or fails, and returns the final status: the
+// payment signature, amount and registration time once completed. If the
+// registration failed, the status is returned with an error wrapping
+// ErrRegistrationFailed and the server's message. A failed status check ends
+// the wait only if it is the first; later ones are logged and retried, so a
+// server restart doesn't cut a long wait short. Cancel ctx to stop waiting.
+func (c *Client) WaitForRegistration(ctx context.Context, workflowID string, pollInterval time.Duration) (*RegistrationStatus, error) {
+	if pollInterval <= 0 {
+		return nil, fmt.Errorf("poll interval must be positive")
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for first := true; ; first = false {
+		status, err := c.GetRegistrationStatus(ctx, workflowID)
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case err != nil && first:
+			return nil, err
+		case err != nil:
+			c.logger.Warn("registration status check failed", "workflow_id", workflowID, "error", err)
+		case status.Status == RegistrationFailed:
+			return status, fmt.Errorf("%w: %s", ErrRegistrationFailed, status.Error)
+		case status.Done():
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
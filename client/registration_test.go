@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRegistrationStatus_Pending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/registration-status/payment-registration:inv1", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"workflow_id":  "payment-registration:inv1",
+			"status":       "pending",
+			"state":        "Running",
+			"expires_at":   "2026-01-02T15:04:05Z",
+			"stage":        "awaiting_payment",
+			"payment_memo": "forohtoo-reg:inv1",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	status, err := client.GetRegistrationStatus(context.Background(), "payment-registration:inv1")
+	require.NoError(t, err)
+
+	assert.Equal(t, RegistrationPending, status.Status)
+	assert.Equal(t, RegistrationStageAwaitingPayment, status.Stage)
+	assert.Equal(t, "forohtoo-reg:inv1", status.PaymentMemo)
+	require.NotNil(t, status.ExpiresAt)
+	assert.False(t, status.Done())
+}
+
+func TestGetRegistrationStatus_Completed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"workflow_id":       "payment-registration:inv1",
+			"status":            "completed",
+			"address":           "wallet1",
+			"network":           "mainnet",
+			"asset_type":        "sol",
+			"token_mint":        "",
+			"payment_amount":    1000000,
+			"payment_signature": "sig1",
+			"registered_at":     "2026-01-02T15:04:05Z",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	status, err := client.GetRegistrationStatus(context.Background(), "payment-registration:inv1")
+	require.NoError(t, err)
+
+	assert.True(t, status.Done())
+	assert.Equal(t, "sig1", status.PaymentSignature)
+	assert.Equal(t, int64(1000000), status.PaymentAmount)
+	require.NotNil(t, status.RegisteredAt)
+}
+
+func TestGetRegistrationStatus_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "workflow not found"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	_, err := client.GetRegistrationStatus(context.Background(), "missing")
+	assert.EqualError(t, err, "request failed: workflow not found")
+}
+
+func TestWaitForRegistration_Completed(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch n {
+		case 1:
+			json.NewEncoder(w).Encode(map[string]interface{}{"workflow_id": "wf1", "status": "pending", "stage": "awaiting_payment"})
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"draining"}`))
+		case 3:
+			json.NewEncoder(w).Encode(map[string]interface{}{"workflow_id": "wf1", "status": "pending", "stage": "registering"})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"workflow_id":       "wf1",
+				"status":            "completed",
+				"payment_signature": "sig1",
+				"payment_amount":    1000000,
+				"registered_at":     "2026-01-02T15:04:05Z",
+			})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, err := client.WaitForRegistration(ctx, "wf1", 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, RegistrationCompleted, status.Status)
+	assert.Equal(t, "sig1", status.PaymentSignature)
+	assert.Equal(t, int64(1000000), status.PaymentAmount)
+	require.NotNil(t, status.RegisteredAt)
+	assert.Equal(t, int32(4), calls.Load(), "a failed check after the first is retried")
+}
+
+func TestWaitForRegistration_Failed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"workflow_id": "wf1",
+			"status":      "failed",
+			"error":       "payment not received before invoice expired",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	status, err := client.WaitForRegistration(context.Background(), "wf1", 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrRegistrationFailed)
+	assert.EqualError(t, err, "registration failed: payment not received before invoice expired")
+	require.NotNil(t, status)
+	assert.Equal(t, RegistrationFailed, status.Status)
+}
+
+func TestWaitForRegistration_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"workflow_id": "wf1", "status": "pending"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	status, err := client.WaitForRegistration(ctx, "wf1", 10*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, status)
+}
+
+func TestWaitForRegistration_FirstCheckFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"workflow not found"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	_, err := client.WaitForRegistration(context.Background(), "wf1", 10*time.Millisecond)
+	assert.EqualError(t, err, "request failed: workflow not found")
+}
@@ -5,25 +5,52 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
 // Wallet represents a registered wallet+asset that the server is monitoring.
 type Wallet struct {
-	Address                string    `json:"address"`
-	Network                string    `json:"network"` // "mainnet" or "devnet"
-	AssetType              string    `json:"asset_type"`
-	TokenMint              string    `json:"token_mint"`
-	AssociatedTokenAddress *string   `json:"associated_token_address,omitempty"`
-	Status                 string    `json:"status"` // active, paused, error
-	CreatedAt              time.Time `json:"created_at"`
-	UpdatedAt              time.Time `json:"updated_at"`
+	Address                string          `json:"address"`
+	Network                string          `json:"network"` // "mainnet" or "devnet"
+	AssetType              string          `json:"asset_type"`
+	TokenMint              string          `json:"token_mint"`
+	AssociatedTokenAddress *string         `json:"associated_token_address,omitempty"`
+	TokenProgram           string          `json:"token_program,omitempty"` // program the ATA is derived under; spl-token only
+	Status                 string          `json:"status"`                  // active, paused, error
+	CreatedAt              time.Time       `json:"created_at"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	Metadata               json.RawMessage `json:"metadata,omitempty"`   // set at registration
+	Tags                   []string        `json:"tags,omitempty"`       // grouping tags, e.g. "customer:acme"
+	DeletedAt              *time.Time      `json:"deleted_at,omitempty"` // set when unregistered; only listed with include_deleted
+}
+
+// RegisterOptions holds optional registration fields.
+type RegisterOptions struct {
+	// Metadata is a JSON object attached to the registration. Nil keeps the
+	// existing value when re-registering.
+	Metadata json.RawMessage
+	// Tags group wallets for listing, as a name or key:value (e.g.
+	// "customer:acme"). Nil keeps the existing tags when re-registering; an
+	// empty slice clears them.
+	Tags []string
+	// CallbackURL receives a signed POST once a payment-gated registration
+	// completes. The server must have payment callbacks enabled.
+	CallbackURL string
+}
+
+// ListOptions filters List results.
+type ListOptions struct {
+	Tags           []string // only wallets carrying all of these tags
+	IncludeDeleted bool     // include unregistered (soft-deleted) wallets
 }
 
 // Client is the HTTP client for the forohtoo wallet service.
@@ -31,54 +58,93 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	logger     *slog.Logger
+	cache      *walletCache // nil unless WithCache is given
+
+	reconnect ReconnectPolicy // see WithReconnectPolicy
 }
 
-// NewClient creates a new wallet service client.
-func NewClient(baseURL string, httpClient *http.Client, logger *slog.Logger) *Client {
+// NewClient creates a new wallet service client. baseURL may include a path
+// prefix when the server runs behind a reverse proxy
+// (e.g. "https://example.com/forohtoo"); a trailing slash is ignored.
+// Transport options (TLS, HTTP/2, keep-alives) apply to a copy of httpClient,
+// so the caller's client is not modified.
+func NewClient(baseURL string, httpClient *http.Client, logger *slog.Logger, opts ...Option) *Client {
 	if httpClient == nil {
 		httpClient = &http.Client{Timeout: 30 * time.Second}
 	}
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(opts) > 0 {
+		hc := *httpClient
+		hc.Transport = o.transportFor(httpClient.Transport)
+		httpClient = &hc
+	}
 	if logger == nil {
 		logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
 	}
+	reconnect := DefaultReconnectPolicy
+	if o.reconnectPolicy != nil {
+		reconnect = *o.reconnectPolicy
+	}
 	return &Client{
-		baseURL:    baseURL,
+		baseURL:    strings.TrimRight(baseURL, "/"),
 		httpClient: httpClient,
 		logger:     logger,
+		cache:      newWalletCache(o.cacheTTL),
+
+		reconnect: reconnect,
 	}
 }
 
 // RegisterAsset tells the server to start monitoring a wallet asset for transactions.
 func (c *Client) RegisterAsset(ctx context.Context, address string, network string, assetType string, tokenMint string) error {
-	reqBody := map[string]interface{}{
-		"address": address,
-		"network": network,
-		"asset": map[string]interface{}{
-			"type":       assetType,
-			"token_mint": tokenMint,
-		},
-	}
+	return c.RegisterAssetWithMetadata(ctx, address, network, assetType, tokenMint, nil)
+}
 
-	body, err := json.Marshal(reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
+// RegisterAssetWithMetadata is like RegisterAsset but attaches a JSON object
+// to the registration. Re-registering with nil metadata keeps the existing value.
+func (c *Client) RegisterAssetWithMetadata(ctx context.Context, address string, network string, assetType string, tokenMint string, metadata json.RawMessage) error {
+	return c.RegisterAssetWithOptions(ctx, address, network, assetType, tokenMint, RegisterOptions{Metadata: metadata})
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/wallet-assets", bytes.NewReader(body))
+// RegisterAssetWithOptions is like RegisterAsset but sets the optional
+// registration fields in opts.
+func (c *Client) RegisterAssetWithOptions(ctx context.Context, address string, network string, assetType string, tokenMint string, opts RegisterOptions) error {
+	_, err := c.registerAsset(ctx, address, network, assetType, tokenMint, opts, false)
+	return err
+}
+
+// RegisterAssetWithResult is like RegisterAssetWithOptions but returns the
+// registration as stored by the server. For spl-token assets this includes
+// the AssociatedTokenAddress the server watches and the TokenProgram it was
+// derived under.
+func (c *Client) RegisterAssetWithResult(ctx context.Context, address string, network string, assetType string, tokenMint string, opts RegisterOptions) (*Wallet, error) {
+	return c.registerAsset(ctx, address, network, assetType, tokenMint, opts, true)
+}
+
+// registerAsset sends a registration request, decoding the response into a
+// Wallet if decode is set.
+func (c *Client) registerAsset(ctx context.Context, address string, network string, assetType string, tokenMint string, opts RegisterOptions, decode bool) (*Wallet, error) {
+	// Invalidate even on failure: the server may have applied the change
+	// before the error reached us.
+	defer c.cache.invalidate(address, network)
+
+	req, err := c.newRegisterRequest(ctx, address, network, assetType, tokenMint, opts)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Accept both 201 (Created) and 200 (OK - updated existing)
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return c.parseErrorResponse(resp)
+		return nil, c.parseErrorResponse(resp)
 	}
 
 	if resp.StatusCode == http.StatusOK {
@@ -94,11 +160,141 @@ func (c *Client) RegisterAsset(ctx context.Context, address string, network stri
 			"token_mint", tokenMint,
 		)
 	}
-	return nil
+
+	if !decode {
+		return nil, nil
+	}
+	var apiWallet walletResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiWallet); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return responseToWallet(&apiWallet)
+}
+
+// newRegisterRequest builds a POST /api/v1/wallet-assets request.
+func (c *Client) newRegisterRequest(ctx context.Context, address string, network string, assetType string, tokenMint string, opts RegisterOptions) (*http.Request, error) {
+	reqBody := map[string]interface{}{
+		"address": address,
+		"network": network,
+		"asset": map[string]interface{}{
+			"type":       assetType,
+			"token_mint": tokenMint,
+		},
+	}
+	if opts.Metadata != nil {
+		reqBody["metadata"] = opts.Metadata
+	}
+	if opts.Tags != nil {
+		reqBody["tags"] = opts.Tags
+	}
+	if opts.CallbackURL != "" {
+		reqBody["callback_url"] = opts.CallbackURL
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/wallet-assets", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// batchRegisterConcurrency bounds the registrations BatchRegisterAssets has
+// in flight at once.
+const batchRegisterConcurrency = 4
+
+// RegisterAssetRequest is one registration in a BatchRegisterAssets call.
+type RegisterAssetRequest struct {
+	Address   string
+	Network   string
+	AssetType string
+	TokenMint string
+	Options   RegisterOptions
+}
+
+// RegisterAssetResult is the outcome of one RegisterAssetRequest.
+type RegisterAssetResult struct {
+	Request RegisterAssetRequest
+	// StatusCode is the server's HTTP status, or 0 if no response arrived.
+	StatusCode int
+	// Wallet is the stored registration after a 200 or 201.
+	Wallet *Wallet
+	// PaymentRequired is set on a 402: the wallet is registered once its
+	// invoice is paid.
+	PaymentRequired *PaymentRequired
+	// Err is set when the registration failed.
+	Err error
+}
+
+// BatchRegisterAssets registers several wallet assets, sending up to four
+// requests at once, and returns a result per request in the same order. A
+// failure is reported in its own result and doesn't stop the others. The
+// error is non-nil only if ctx ended before every request was sent.
+func (c *Client) BatchRegisterAssets(ctx context.Context, reqs []RegisterAssetRequest) ([]RegisterAssetResult, error) {
+	results := make([]RegisterAssetResult, len(reqs))
+	sem := make(chan struct{}, batchRegisterConcurrency)
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = c.registerAssetResult(ctx, req)
+		}()
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
+
+// registerAssetResult sends one registration and reports its outcome.
+func (c *Client) registerAssetResult(ctx context.Context, r RegisterAssetRequest) RegisterAssetResult {
+	defer c.cache.invalidate(r.Address, r.Network)
+	result := RegisterAssetResult{Request: r}
+
+	req, err := c.newRegisterRequest(ctx, r.Address, r.Network, r.AssetType, r.TokenMint, r.Options)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		result.Err = fmt.Errorf("request failed: %w", err)
+		return result
+	}
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		var apiWallet walletResponse
+		if err := json.NewDecoder(resp.Body).Decode(&apiWallet); err != nil {
+			result.Err = fmt.Errorf("failed to decode response: %w", err)
+			return result
+		}
+		result.Wallet, result.Err = responseToWallet(&apiWallet)
+	case http.StatusPaymentRequired:
+		var payment PaymentRequired
+		if err := json.NewDecoder(resp.Body).Decode(&payment); err != nil {
+			result.Err = fmt.Errorf("failed to decode response: %w", err)
+			return result
+		}
+		result.PaymentRequired = &payment
+	default:
+		result.Err = c.parseErrorResponse(resp)
+	}
+	return result
 }
 
 // UnregisterAsset tells the server to stop monitoring a wallet asset.
 func (c *Client) UnregisterAsset(ctx context.Context, address string, network string, assetType string, tokenMint string) error {
+	defer c.cache.invalidate(address, network)
+
 	u := fmt.Sprintf("%s/api/v1/wallet-assets/%s?network=%s&asset_type=%s&token_mint=%s",
 		c.baseURL,
 		url.PathEscape(address),
@@ -129,8 +325,13 @@ func (c *Client) UnregisterAsset(ctx context.Context, address string, network st
 	return nil
 }
 
-// Get retrieves the registration details for a specific wallet.
+// Get retrieves the registration details for a specific wallet. With
+// WithCache, a cached result is returned while it is fresh.
 func (c *Client) Get(ctx context.Context, address string, network string) (*Wallet, error) {
+	if w, ok := c.cache.getWallet(address, network); ok {
+		return w, nil
+	}
+
 	u := fmt.Sprintf("%s/api/v1/wallet-assets/%s?network=%s", c.baseURL, url.PathEscape(address), url.QueryEscape(network))
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
@@ -152,12 +353,91 @@ func (c *Client) Get(ctx context.Context, address string, network string) (*Wall
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return responseToWallet(&apiWallet)
+	wallet, err := responseToWallet(&apiWallet)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.putWallet(address, network, wallet)
+	return wallet, nil
+}
+
+// AddressAssets holds every asset registered for an address, keyed by
+// network. Both "mainnet" and "devnet" are always present.
+type AddressAssets struct {
+	Address  string               `json:"address"`
+	Networks map[string][]*Wallet `json:"networks"`
+}
+
+// GetAll retrieves every asset registered for address on any network in one
+// call. An unregistered address yields empty lists, not an error.
+func (c *Client) GetAll(ctx context.Context, address string) (*AddressAssets, error) {
+	u := fmt.Sprintf("%s/api/v1/wallet-assets/%s/all", c.baseURL, url.PathEscape(address))
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var response struct {
+		Address  string                      `json:"address"`
+		Networks map[string][]walletResponse `json:"networks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	result := &AddressAssets{
+		Address:  response.Address,
+		Networks: make(map[string][]*Wallet, len(response.Networks)),
+	}
+	for network, apiWallets := range response.Networks {
+		wallets := make([]*Wallet, len(apiWallets))
+		for i, apiWallet := range apiWallets {
+			wallet, err := responseToWallet(&apiWallet)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse wallet %s: %w", apiWallet.Address, err)
+			}
+			wallets[i] = wallet
+		}
+		result.Networks[network] = wallets
+	}
+	return result, nil
 }
 
 // List retrieves all registered wallets.
 func (c *Client) List(ctx context.Context) ([]*Wallet, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/wallet-assets", nil)
+	return c.ListWithOptions(ctx, ListOptions{})
+}
+
+// ListWithOptions retrieves registered wallets matching opts. With
+// WithCache, a cached result for the same opts is returned while it is fresh.
+func (c *Client) ListWithOptions(ctx context.Context, opts ListOptions) ([]*Wallet, error) {
+	params := url.Values{}
+	for _, tag := range opts.Tags {
+		params.Add("tag", tag)
+	}
+	if opts.IncludeDeleted {
+		params.Set("include_deleted", "true")
+	}
+	cacheKey := params.Encode()
+	if wallets, ok := c.cache.getList(cacheKey); ok {
+		return wallets, nil
+	}
+	u := c.baseURL + "/api/v1/wallet-assets"
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -189,19 +469,24 @@ func (c *Client) List(ctx context.Context) ([]*Wallet, error) {
 		wallets[i] = wallet
 	}
 
+	c.cache.putList(cacheKey, wallets)
 	return wallets, nil
 }
 
 // walletResponse is the API response format for a wallet asset.
 type walletResponse struct {
-	Address                string    `json:"address"`
-	Network                string    `json:"network"`
-	AssetType              string    `json:"asset_type"`
-	TokenMint              string    `json:"token_mint"`
-	AssociatedTokenAddress *string   `json:"associated_token_address,omitempty"`
-	Status                 string    `json:"status"`
-	CreatedAt              time.Time `json:"created_at"`
-	UpdatedAt              time.Time `json:"updated_at"`
+	Address                string          `json:"address"`
+	Network                string          `json:"network"`
+	AssetType              string          `json:"asset_type"`
+	TokenMint              string          `json:"token_mint"`
+	AssociatedTokenAddress *string         `json:"associated_token_address,omitempty"`
+	TokenProgram           string          `json:"token_program,omitempty"`
+	Status                 string          `json:"status"`
+	CreatedAt              time.Time       `json:"created_at"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	Metadata               json.RawMessage `json:"metadata,omitempty"`
+	Tags                   []string        `json:"tags,omitempty"`
+	DeletedAt              *time.Time      `json:"deleted_at,omitempty"`
 }
 
 // responseToWallet converts an API response to a domain Wallet.
@@ -212,25 +497,42 @@ func responseToWallet(resp *walletResponse) (*Wallet, error) {
 		AssetType:              resp.AssetType,
 		TokenMint:              resp.TokenMint,
 		AssociatedTokenAddress: resp.AssociatedTokenAddress,
+		TokenProgram:           resp.TokenProgram,
 		Status:                 resp.Status,
 		CreatedAt:              resp.CreatedAt,
 		UpdatedAt:              resp.UpdatedAt,
+		Metadata:               resp.Metadata,
+		Tags:                   resp.Tags,
+		DeletedAt:              resp.DeletedAt,
 	}, nil
 }
 
 // Transaction represents a Solana transaction event.
 type Transaction struct {
-	Signature          string    `json:"signature"`
-	Slot               int64     `json:"slot"`
-	WalletAddress      string    `json:"wallet_address"`         // Destination/receiver wallet
-	FromAddress        *string   `json:"from_address,omitempty"` // Source/sender wallet
-	Amount             int64     `json:"amount"`
-	TokenType          string    `json:"token_type"`
-	Memo               *string   `json:"memo,omitempty"`
-	Timestamp          time.Time `json:"timestamp"`
-	BlockTime          time.Time `json:"block_time"`
-	ConfirmationStatus string    `json:"confirmation_status"`
-	PublishedAt        time.Time `json:"published_at"`
+	Signature           string          `json:"signature"`
+	Slot                int64           `json:"slot"`
+	WalletAddress       string          `json:"wallet_address"`         // Destination/receiver wallet
+	Network             string          `json:"network,omitempty"`      // "mainnet" or "devnet"
+	FromAddress         *string         `json:"from_address,omitempty"` // Source/sender wallet
+	Amount              int64           `json:"amount"`
+	TokenType           string          `json:"token_type"`
+	Decimals            *int            `json:"decimals,omitempty"` // mint decimals, when the server knows them
+	Memo                *string         `json:"memo,omitempty"`
+	MemoTruncated       bool            `json:"memo_truncated"`       // Memo was cut to the server's MAX_MEMO_LENGTH
+	Fee                 int64           `json:"fee"`                  // network fee in lamports paid by the fee payer; 0 if unknown
+	PaymentID           *string         `json:"payment_id,omitempty"` // logical payment ID read from the memo
+	DuplicateLogical    bool            `json:"duplicate_logical"`    // PaymentID was already seen for this wallet
+	Direction           string          `json:"direction,omitempty"`  // one of the Direction* constants; empty from older servers means DirectionIn
+	Timestamp           time.Time       `json:"timestamp"`
+	BlockTime           time.Time       `json:"block_time"`
+	ConfirmationStatus  string          `json:"confirmation_status"`
+	DetectedAt          time.Time       `json:"detected_at"`           // when the server stored the transaction; zero from older servers
+	DetectionLagSeconds float64         `json:"detection_lag_seconds"` // DetectedAt minus BlockTime, in seconds
+	PublishedAt         time.Time       `json:"published_at"`
+	Metadata            json.RawMessage `json:"metadata,omitempty"` // client-supplied annotations
+	// FinalizedUpgrade is set by Stream when it delivers a transaction again
+	// because the server re-published it once finalized.
+	FinalizedUpgrade bool `json:"finalized_upgrade,omitempty"`
 }
 
 // Await blocks until a transaction matching the matcher function arrives.
@@ -244,6 +546,21 @@ type Transaction struct {
 // This is designed for payment gating in Temporal workflows - an activity can
 // call this method and block until a payment arrives.
 //
+// If the server restarts during a rolling deploy it sends a reconnect event;
+// Await reconnects after the advised delay and resumes from the last delivered
+// transaction, so nothing arriving during the hand-off is missed. If the
+// stream instead drops without one (a crash or a network failure), Await
+// reconnects on its own, resuming from the newest transaction it received,
+// backing off between attempts as set by WithReconnectPolicy. Either way
+// transactions replayed on the new stream don't reach the matcher twice. If
+// the stream still can't be reopened, the error wraps
+// ErrAwaitReconnectsExhausted.
+//
+// The matcher sees each transaction once, with the confirmation status it
+// first arrived with; a later finalized re-publish of it is skipped. To act
+// on finality, match ConfirmationStatus "finalized" from history, or use
+// Stream, which delivers the upgrade.
+//
 // Example:
 //
 //	// Wait for a transaction with specific memo, checking last 24 hours
@@ -252,6 +569,250 @@ type Transaction struct {
 //	    return strings.Contains(txn.Memo, "payment-workflow-123")
 //	})
 func (c *Client) Await(ctx context.Context, address string, network string, lookback time.Duration, matcher func(*Transaction) bool) (*Transaction, error) {
+	return c.await(ctx, address, network, lookback, matcher, false)
+}
+
+// await implements Await and Stream. With upgrades, a transaction
+// re-published once finalized reaches the matcher a second time, marked
+// FinalizedUpgrade.
+func (c *Client) await(ctx context.Context, address string, network string, lookback time.Duration, matcher func(*Transaction) bool, upgrades bool) (*Transaction, error) {
+	// The seen-set and cursor outlive a single connection: when the server
+	// drains for a restart, Await reconnects and resumes from the cursor, and
+	// anything replayed twice is filtered out.
+	seen := newSeenTransactions(upgrades)
+	windowStart := time.Now().Add(-lookback)
+	var cursor string
+	var newest awaitCursor
+	connected := false
+	retries := 0
+
+	for {
+		txn, reconnect, status, err := c.awaitStream(ctx, address, network, lookback, cursor, seen, &newest, matcher)
+		// Reopening the stream ends a run of failed reconnects, so
+		// MaxAttempts bounds consecutive failures, not drops over the call.
+		if status == http.StatusOK {
+			connected = true
+			retries = 0
+		}
+		var delay time.Duration
+		switch {
+		case reconnect != nil:
+			if newest.Signature != "" {
+				cursor = newest.Signature
+			}
+			if reconnect.Cursor != "" {
+				cursor = reconnect.Cursor
+			}
+			delay = time.Duration(reconnect.RetryMS) * time.Millisecond
+			retries = 0
+			c.logger.Info("SSE server draining, reconnecting", "address", address, "cursor", cursor, "delay", delay)
+		case err != nil:
+			// Nothing can have been missed before the first stream opened, so
+			// only reconnect once one has: after it drops, while the server
+			// is unreachable during a restart, or while a draining replica
+			// still answers 503.
+			if ctx.Err() != nil || !connected || !retryableAwaitStatus(status) {
+				return nil, err
+			}
+			if retries >= c.reconnect.MaxAttempts {
+				if retries == 0 {
+					return nil, err
+				}
+				return nil, fmt.Errorf("%w after %d attempts: %w", ErrAwaitReconnectsExhausted, retries, err)
+			}
+			retries++
+			if newest.Signature != "" {
+				cursor = newest.Signature
+			}
+			delay = c.reconnect.delay(retries)
+			c.logger.Warn("SSE stream lost, reconnecting", "address", address, "cursor", cursor, "attempt", retries, "error", err)
+		default:
+			return txn, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		// If the server can't resolve the cursor it falls back to lookback;
+		// cover everything since the original window.
+		lookback = time.Since(windowStart)
+	}
+}
+
+// AwaitAny is like Await with several independent matchers: it blocks until
+// a transaction satisfies any of them and returns the transaction together
+// with the index of the matcher that fired. Matchers are tried in order for
+// each transaction, historical ones from lookback included, so when more than
+// one would match, the lowest index wins and later matchers aren't called.
+//
+// Example:
+//
+//	// Wait for a payment covering either pricing tier
+//	txn, tier, err := client.AwaitAny(ctx, serviceWallet, "mainnet", time.Hour, []func(*Transaction) bool{
+//	    func(txn *Transaction) bool { return txn.Amount >= 5_000_000 },
+//	    func(txn *Transaction) bool { return txn.Amount >= 1_000_000 },
+//	})
+func (c *Client) AwaitAny(ctx context.Context, address string, network string, lookback time.Duration, matchers []func(*Transaction) bool) (*Transaction, int, error) {
+	if len(matchers) == 0 {
+		return nil, -1, fmt.Errorf("at least one matcher is required")
+	}
+	matched := -1
+	txn, err := c.Await(ctx, address, network, lookback, func(txn *Transaction) bool {
+		for i, m := range matchers {
+			if m(txn) {
+				matched = i
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, -1, err
+	}
+	return txn, matched, nil
+}
+
+// Stream delivers every transaction for the wallet on the returned channel,
+// from lookback onwards, until ctx is done. It shares Await's stream
+// handling: reconnects, resuming from a cursor, and skipping replayed
+// transactions. A transaction re-published once finalized is delivered again,
+// once, with its new ConfirmationStatus and FinalizedUpgrade set.
+//
+// The transaction channel is unbuffered, so a slow consumer holds back
+// reading from the server; nothing is dropped. If the stream fails for a
+// reason other than ctx ending, the error is sent on the error channel. Both
+// channels are closed when Stream stops, so the caller can range over the
+// transactions and check the error afterwards:
+//
+//	txns, errs := client.Stream(ctx, walletAddress, "mainnet", time.Hour)
+//	for txn := range txns {
+//	    fmt.Println(txn.Signature, txn.Amount)
+//	}
+//	if err := <-errs; err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Client) Stream(ctx context.Context, address string, network string, lookback time.Duration) (<-chan *Transaction, <-chan error) {
+	txns := make(chan *Transaction)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(txns)
+		_, err := c.await(ctx, address, network, lookback, func(txn *Transaction) bool {
+			select {
+			case txns <- txn:
+			case <-ctx.Done():
+			}
+			return false
+		}, true)
+		if ctx.Err() == nil {
+			errs <- err
+		}
+	}()
+	return txns, errs
+}
+
+// ReconnectPolicy controls how Await reopens a stream that dropped without a
+// reconnect event. Attempt n waits about BaseDelay·2^(n-1), capped at
+// maxReconnectDelay and randomly shortened by up to half, so clients that lost the same
+// server don't all come back at once. Reopening the stream, or a reconnect
+// event from the server, resets the count.
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of consecutive reconnects before Await gives
+	// up. 0 makes Await return the error instead of reconnecting.
+	MaxAttempts int
+	// BaseDelay is the wait before the first attempt.
+	BaseDelay time.Duration
+}
+
+// DefaultReconnectPolicy is used when WithReconnectPolicy isn't given.
+var DefaultReconnectPolicy = ReconnectPolicy{MaxAttempts: 5, BaseDelay: time.Second}
+
+// maxReconnectDelay caps the backoff between Await reconnects.
+const maxReconnectDelay = 30 * time.Second
+
+// ErrAwaitReconnectsExhausted is wrapped by the error Await returns when its
+// stream dropped and every reconnect allowed by the ReconnectPolicy failed.
+var ErrAwaitReconnectsExhausted = errors.New("await reconnects exhausted")
+
+// delay returns the wait before reconnect attempt n, counting from 1.
+func (p ReconnectPolicy) delay(n int) time.Duration {
+	d := max(p.BaseDelay, 0)
+	for i := 1; i < n && d < maxReconnectDelay; i++ {
+		d *= 2
+	}
+	d = min(d, maxReconnectDelay)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryableAwaitStatus reports whether an Await stream that ended with status
+// may be reopened: OK means an open stream dropped, 0 that the server
+// couldn't be reached, and 503 that a draining replica answered.
+func retryableAwaitStatus(status int) bool {
+	return status == http.StatusOK || status == 0 || status == http.StatusServiceUnavailable
+}
+
+// awaitCursor is the transaction with the latest block time Await has
+// received. Resuming from it replays everything since its block.
+type awaitCursor struct {
+	Signature string
+	BlockTime time.Time
+}
+
+// advance moves the cursor to txn if it is at least as recent.
+func (a *awaitCursor) advance(txn *Transaction) {
+	if a.Signature == "" || !txn.BlockTime.Before(a.BlockTime) {
+		a.Signature = txn.Signature
+		a.BlockTime = txn.BlockTime
+	}
+}
+
+// seenTransactions records the confirmation status each transaction was
+// delivered with, keyed on (signature, network), so a transaction reaches the
+// matcher at most once, or twice when upgrades lets its finalized re-publish
+// through.
+type seenTransactions struct {
+	statuses map[string]string
+	upgrades bool
+}
+
+func newSeenTransactions(upgrades bool) *seenTransactions {
+	return &seenTransactions{statuses: make(map[string]string), upgrades: upgrades}
+}
+
+// admit reports whether txn should be delivered and records it. A finalized
+// re-publish of a transaction delivered unfinalized is admitted only with
+// upgrades, and is marked FinalizedUpgrade.
+func (s *seenTransactions) admit(txn *Transaction) bool {
+	key := txn.Signature + ":" + txn.Network
+	status, ok := s.statuses[key]
+	switch {
+	case !ok:
+	case s.upgrades && txn.ConfirmationStatus == "finalized" && status != "finalized":
+		txn.FinalizedUpgrade = true
+	default:
+		return false
+	}
+	s.statuses[key] = txn.ConfirmationStatus
+	return true
+}
+
+// sseReconnect is the payload of a reconnect event, sent when the server
+// drains for a restart.
+type sseReconnect struct {
+	Cursor  string `json:"cursor"`
+	RetryMS int64  `json:"retry_ms"`
+}
+
+// awaitStream opens one SSE connection and reads it until the matcher
+// succeeds, the server asks for a reconnect, or an error occurs. It returns
+// the response status, or 0 if the request failed before one arrived.
+func (c *Client) awaitStream(ctx context.Context, address, network string, lookback time.Duration, cursor string, seen *seenTransactions, newest *awaitCursor, matcher func(*Transaction) bool) (*Transaction, *sseReconnect, int, error) {
 	// Build SSE stream URL
 	u := fmt.Sprintf("%s/api/v1/stream/transactions/%s?network=%s", c.baseURL, url.PathEscape(address), url.QueryEscape(network))
 
@@ -259,10 +820,13 @@ func (c *Client) Await(ctx context.Context, address string, network string, look
 	if lookback > 0 {
 		u += fmt.Sprintf("&lookback=%s", url.QueryEscape(lookback.String()))
 	}
+	if cursor != "" {
+		u += fmt.Sprintf("&cursor=%s", url.QueryEscape(cursor))
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Accept", "text/event-stream")
 
@@ -270,32 +834,41 @@ func (c *Client) Await(ctx context.Context, address string, network string, look
 
 	// Create HTTP client with no timeout for streaming
 	streamClient := &http.Client{
-		Timeout: 0, // No timeout for SSE
+		Transport: c.httpClient.Transport,
+		Timeout:   0, // No timeout for SSE
 	}
 
 	resp, err := streamClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to SSE stream: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to connect to SSE stream: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.parseErrorResponse(resp)
+		return nil, nil, resp.StatusCode, c.parseErrorResponse(resp)
 	}
 
 	// Parse SSE events
-	return c.parseSSEStream(ctx, resp.Body, matcher)
+	txn, reconnect, err := c.parseSSEStream(ctx, resp.Body, network, seen, newest, matcher)
+	return txn, reconnect, resp.StatusCode, err
 }
 
 // parseSSEStream parses SSE events and calls matcher on each transaction.
-func (c *Client) parseSSEStream(ctx context.Context, body io.Reader, matcher func(*Transaction) bool) (*Transaction, error) {
+//
+// The server replays historical transactions before switching to live events,
+// so a transaction landing during that window can be delivered twice. The
+// seen-set keyed on (signature, network) ensures the matcher is called at most
+// once per transaction. Events that don't carry a network are attributed to
+// the network being awaited. Each new transaction advances newest. A
+// reconnect event ends the stream and is returned to the caller.
+func (c *Client) parseSSEStream(ctx context.Context, body io.Reader, network string, seen *seenTransactions, newest *awaitCursor, matcher func(*Transaction) bool) (*Transaction, *sseReconnect, error) {
 	scanner := bufio.NewScanner(body)
 	var currentEvent, currentData string
 
 	for scanner.Scan() {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return nil, nil, ctx.Err()
 		default:
 		}
 
@@ -303,9 +876,16 @@ func (c *Client) parseSSEStream(ctx context.Context, body io.Reader, matcher fun
 
 		// Empty line indicates end of event
 		if line == "" {
+			if currentEvent == "reconnect" {
+				var reconnect sseReconnect
+				if err := json.Unmarshal([]byte(currentData), &reconnect); err != nil {
+					c.logger.Warn("failed to unmarshal reconnect event", "error", err)
+				}
+				return nil, &reconnect, nil
+			}
 			if currentEvent != "" && currentData != "" {
-				if txn, done := c.handleSSEEvent(currentEvent, currentData, matcher); done {
-					return txn, nil
+				if txn, done := c.handleSSEEvent(currentEvent, currentData, network, seen, newest, matcher); done {
+					return txn, nil, nil
 				}
 			}
 			currentEvent = ""
@@ -323,16 +903,17 @@ func (c *Client) parseSSEStream(ctx context.Context, body io.Reader, matcher fun
 
 	if err := scanner.Err(); err != nil {
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			return nil, nil, ctx.Err()
 		}
-		return nil, fmt.Errorf("error reading SSE stream: %w", err)
+		return nil, nil, fmt.Errorf("error reading SSE stream: %w", err)
 	}
 
-	return nil, fmt.Errorf("SSE stream closed unexpectedly")
+	return nil, nil, fmt.Errorf("SSE stream closed unexpectedly")
 }
 
 // handleSSEEvent processes an SSE event and returns transaction if matcher succeeds.
-func (c *Client) handleSSEEvent(eventType, data string, matcher func(*Transaction) bool) (*Transaction, bool) {
+// Transactions seen doesn't admit are skipped without calling matcher.
+func (c *Client) handleSSEEvent(eventType, data string, network string, seen *seenTransactions, newest *awaitCursor, matcher func(*Transaction) bool) (*Transaction, bool) {
 	switch eventType {
 	case "connected":
 		c.logger.Debug("SSE stream connected")
@@ -344,6 +925,18 @@ func (c *Client) handleSSEEvent(eventType, data string, matcher func(*Transactio
 			c.logger.Warn("failed to unmarshal transaction", "error", err)
 			return nil, false
 		}
+		if txn.Network == "" {
+			txn.Network = network
+		}
+
+		if !seen.admit(&txn) {
+			c.logger.Debug("skipping duplicate transaction",
+				"signature", txn.Signature,
+				"network", txn.Network,
+			)
+			return nil, false
+		}
+		newest.advance(&txn)
 
 		c.logger.Debug("received transaction",
 			"signature", txn.Signature,
@@ -371,15 +964,80 @@ func (c *Client) handleSSEEvent(eventType, data string, matcher func(*Transactio
 	}
 }
 
-// ListTransactions retrieves transactions for a specific wallet.
+// Transaction orderings accepted by ListTransactionsWithOptions.
+const (
+	SortBlockTimeDesc = "block_time_desc" // newest first (default)
+	SortBlockTimeAsc  = "block_time_asc"
+	SortAmountDesc    = "amount_desc"
+	SortAmountAsc     = "amount_asc"
+)
+
+// Transfer directions, relative to Transaction.WalletAddress. Amount is
+// always a magnitude.
+const (
+	DirectionIn   = "in"   // received from another account
+	DirectionOut  = "out"  // sent to another account; only stored when the server records outgoing transfers
+	DirectionSelf = "self" // sent by the wallet to itself
+)
+
+// ListTransactionsOptions controls ListTransactionsWithOptions.
+type ListTransactionsOptions struct {
+	Limit     int    // 0 uses the server default of 100
+	Offset    int    // rows to skip, in Sort order
+	Sort      string // one of the Sort* constants; empty means newest first
+	Direction string // one of the Direction* constants; empty means every direction
+	// Before is the NextCursor of the previous page. Unlike Offset, it stays
+	// put while new transactions arrive. Only for the default newest-first
+	// Sort, and not with Offset.
+	Before string
+}
+
+// TransactionPage is one page of ListTransactionsPage.
+type TransactionPage struct {
+	Transactions []*Transaction
+	// NextCursor is set when the page is full in the default newest-first
+	// order; pass it as ListTransactionsOptions.Before for the next page.
+	NextCursor string
+}
+
+// ListTransactions retrieves transactions for a specific wallet, newest first.
 func (c *Client) ListTransactions(ctx context.Context, walletAddress string, network string, limit, offset int) ([]*Transaction, error) {
-	u := fmt.Sprintf("%s/api/v1/transactions?wallet_address=%s&network=%s&limit=%d&offset=%d",
-		c.baseURL,
-		url.QueryEscape(walletAddress),
-		url.QueryEscape(network),
-		limit,
-		offset,
-	)
+	return c.ListTransactionsWithOptions(ctx, walletAddress, network, ListTransactionsOptions{Limit: limit, Offset: offset})
+}
+
+// ListTransactionsWithOptions retrieves transactions for a specific wallet in
+// the requested order. Sorting happens server-side, so pages are consistent.
+func (c *Client) ListTransactionsWithOptions(ctx context.Context, walletAddress string, network string, opts ListTransactionsOptions) ([]*Transaction, error) {
+	page, err := c.ListTransactionsPage(ctx, walletAddress, network, opts)
+	if err != nil {
+		return nil, err
+	}
+	return page.Transactions, nil
+}
+
+// ListTransactionsPage is like ListTransactionsWithOptions but also returns
+// the cursor for the next page. To walk a wallet's whole history, newest
+// first, pass each page's NextCursor as Before until it comes back empty.
+func (c *Client) ListTransactionsPage(ctx context.Context, walletAddress string, network string, opts ListTransactionsOptions) (*TransactionPage, error) {
+	params := url.Values{}
+	params.Set("wallet_address", walletAddress)
+	params.Set("network", network)
+	if opts.Limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", opts.Limit))
+	}
+	if opts.Offset > 0 {
+		params.Set("offset", fmt.Sprintf("%d", opts.Offset))
+	}
+	if opts.Sort != "" {
+		params.Set("sort", opts.Sort)
+	}
+	if opts.Direction != "" {
+		params.Set("direction", opts.Direction)
+	}
+	if opts.Before != "" {
+		params.Set("before", opts.Before)
+	}
+	u := c.baseURL + "/api/v1/transactions?" + params.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
@@ -401,6 +1059,7 @@ func (c *Client) ListTransactions(ctx context.Context, walletAddress string, net
 		Count        int           `json:"count"`
 		Limit        int           `json:"limit"`
 		Offset       int           `json:"offset"`
+		NextCursor   string        `json:"next_cursor"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
@@ -412,7 +1071,317 @@ func (c *Client) ListTransactions(ctx context.Context, walletAddress string, net
 		transactions[i] = &response.Transactions[i]
 	}
 
-	return transactions, nil
+	return &TransactionPage{Transactions: transactions, NextCursor: response.NextCursor}, nil
+}
+
+// MemoSearchOptions controls SearchTransactionsByMemo.
+type MemoSearchOptions struct {
+	Prefix bool // match only memos starting with the query
+	Limit  int  // 0 uses the server default of 100
+}
+
+// SearchTransactionsByMemo returns a wallet's transactions whose memo
+// contains query, ignoring case, newest first. The query is matched
+// literally, so a JSON fragment such as `"workflow_id":"abc"` finds memos
+// carrying that field.
+func (c *Client) SearchTransactionsByMemo(ctx context.Context, walletAddress string, network string, query string, opts MemoSearchOptions) ([]*Transaction, error) {
+	params := url.Values{}
+	params.Set("wallet_address", walletAddress)
+	params.Set("network", network)
+	params.Set("q", query)
+	if opts.Prefix {
+		params.Set("match", "prefix")
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", opts.Limit))
+	}
+	u := fmt.Sprintf("%s/api/v1/transactions/search?%s", c.baseURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var response struct {
+		Transactions []*Transaction `json:"transactions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Transactions, nil
+}
+
+// AmountRangeOptions selects transactions for ListTransactionsByAmount.
+// Amounts are inclusive base units of one asset: lamports of native SOL when
+// TokenMint is empty, otherwise base units of that token.
+type AmountRangeOptions struct {
+	TokenMint string // "" for native SOL
+	MinAmount int64  // 0 leaves the range open below
+	MaxAmount int64  // 0 leaves the range open above
+	Limit     int    // 0 uses the server default of 100
+	Offset    int    // rows to skip, newest first
+}
+
+// ListTransactionsByAmount returns a wallet's stored transactions in one
+// asset whose amount is within a range, newest first. It is the historical
+// counterpart to awaiting a payment of a given amount; set MinAmount and
+// MaxAmount equal to find every payment of exactly that amount.
+func (c *Client) ListTransactionsByAmount(ctx context.Context, walletAddress string, network string, opts AmountRangeOptions) ([]*Transaction, error) {
+	params := url.Values{}
+	params.Set("network", network)
+	if opts.TokenMint != "" {
+		params.Set("token_mint", opts.TokenMint)
+	}
+	if opts.MinAmount > 0 {
+		params.Set("min_amount", fmt.Sprintf("%d", opts.MinAmount))
+	}
+	if opts.MaxAmount > 0 {
+		params.Set("max_amount", fmt.Sprintf("%d", opts.MaxAmount))
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", opts.Limit))
+	}
+	if opts.Offset > 0 {
+		params.Set("offset", fmt.Sprintf("%d", opts.Offset))
+	}
+	u := fmt.Sprintf("%s/api/v1/wallets/%s/transactions?%s", c.baseURL, url.PathEscape(walletAddress), params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var response struct {
+		Transactions []*Transaction `json:"transactions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Transactions, nil
+}
+
+// CheckPaymentOptions narrows CheckPayment.
+type CheckPaymentOptions struct {
+	MinAmount int64  // in base units of the asset; 0 accepts any amount
+	AssetType string // "sol" or "spl-token"; empty accepts any asset
+	TokenMint string // required when AssetType is "spl-token"
+}
+
+// CheckPayment reports whether address has received a payment whose memo is
+// exactly memo. It returns the earliest matching transaction, or nil (and no
+// error) if there is none yet. Unlike Await it never blocks; it only sees
+// transactions the server has already stored.
+func (c *Client) CheckPayment(ctx context.Context, address string, network string, memo string, opts CheckPaymentOptions) (*Transaction, error) {
+	params := url.Values{}
+	params.Set("address", address)
+	params.Set("network", network)
+	params.Set("memo", memo)
+	if opts.MinAmount > 0 {
+		params.Set("min_amount", fmt.Sprintf("%d", opts.MinAmount))
+	}
+	if opts.AssetType != "" {
+		params.Set("asset_type", opts.AssetType)
+	}
+	if opts.TokenMint != "" {
+		params.Set("token_mint", opts.TokenMint)
+	}
+	u := fmt.Sprintf("%s/api/v1/payments/check?%s", c.baseURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var txn Transaction
+	if err := json.NewDecoder(resp.Body).Decode(&txn); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &txn, nil
+}
+
+// WalletKey identifies a wallet on a network in a multi-wallet query.
+type WalletKey struct {
+	Address string `json:"address"`
+	Network string `json:"network"`
+}
+
+// WalletTransactions holds the transactions returned for one wallet by
+// ListTransactionsMulti.
+type WalletTransactions struct {
+	Address      string         `json:"address"`
+	Network      string         `json:"network"`
+	Transactions []*Transaction `json:"transactions"`
+	Count        int            `json:"count"`
+}
+
+// ListTransactionsMulti retrieves recent transactions for several wallets in
+// one request, grouped by wallet in the order requested. Zero start/end leave
+// the window unbounded / ending now; limit is per wallet (0 uses the server
+// default of 100).
+func (c *Client) ListTransactionsMulti(ctx context.Context, wallets []WalletKey, start, end time.Time, limit int) ([]*WalletTransactions, error) {
+	reqBody := map[string]interface{}{
+		"wallets": wallets,
+	}
+	if !start.IsZero() {
+		reqBody["start"] = start
+	}
+	if !end.IsZero() {
+		reqBody["end"] = end
+	}
+	if limit > 0 {
+		reqBody["limit"] = limit
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/transactions/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var response struct {
+		Wallets []*WalletTransactions `json:"wallets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Wallets, nil
+}
+
+// UpdateTransactionMetadata replaces the metadata attached to a transaction.
+// Passing nil metadata clears it.
+func (c *Client) UpdateTransactionMetadata(ctx context.Context, signature string, network string, metadata json.RawMessage) (*Transaction, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"network":  network,
+		"metadata": metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	u := fmt.Sprintf("%s/api/v1/transactions/%s/metadata", c.baseURL, url.PathEscape(signature))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var txn Transaction
+	if err := json.NewDecoder(resp.Body).Decode(&txn); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	c.logger.Debug("transaction metadata updated", "signature", signature, "network", network)
+	return &txn, nil
+}
+
+// IngestResult is the outcome of IngestTransaction.
+type IngestResult struct {
+	Signature    string         `json:"signature"`
+	Network      string         `json:"network"`
+	Matched      int            `json:"matched"` // transfers involving a monitored wallet
+	Written      int            `json:"written"` // newly stored (and published)
+	Skipped      int            `json:"skipped"` // already stored
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// IngestTransaction asks the server to fetch a transaction by signature and
+// ingest it as if a webhook had delivered it, to recover a missed delivery.
+// Ingesting an already-stored transaction is a no-op. The transaction must
+// involve a wallet monitored on network.
+func (c *Client) IngestTransaction(ctx context.Context, signature string, network string) (*IngestResult, error) {
+	body, err := json.Marshal(map[string]string{
+		"signature": signature,
+		"network":   network,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/admin/ingest", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	var result IngestResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	c.logger.Debug("transaction ingested", "signature", signature, "network", network, "written", result.Written)
+	return &result, nil
 }
 
 // parseErrorResponse attempts to parse an error response from the server.
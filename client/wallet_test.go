@@ -3,8 +3,13 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,6 +39,56 @@ func TestRegister_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRegisterAssetWithResult_SPLToken(t *testing.T) {
+	const (
+		mint         = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+		ata          = "5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1"
+		tokenProgram = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"address":                  "wallet123",
+			"network":                  "mainnet",
+			"asset_type":               "spl-token",
+			"token_mint":               mint,
+			"associated_token_address": ata,
+			"token_program":            tokenProgram,
+			"status":                   "active",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	wallet, err := client.RegisterAssetWithResult(context.Background(), "wallet123", "mainnet", "spl-token", mint, RegisterOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, wallet)
+
+	assert.Equal(t, "spl-token", wallet.AssetType)
+	assert.Equal(t, mint, wallet.TokenMint)
+	require.NotNil(t, wallet.AssociatedTokenAddress)
+	assert.Equal(t, ata, *wallet.AssociatedTokenAddress)
+	assert.Equal(t, tokenProgram, wallet.TokenProgram)
+}
+
+func TestRegisterAssetWithResult_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "unsupported token mint"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	wallet, err := client.RegisterAssetWithResult(context.Background(), "wallet123", "mainnet", "spl-token", "mint", RegisterOptions{})
+	require.Error(t, err)
+	assert.Nil(t, wallet)
+	assert.Contains(t, err.Error(), "unsupported token mint")
+}
+
 func TestRegister_ServerError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -132,6 +187,38 @@ func TestGet_NotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "wallet not found")
 }
 
+func TestGetAll_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/wallet-assets/wallet123/all", r.URL.Path)
+
+		response := map[string]interface{}{
+			"address": "wallet123",
+			"networks": map[string]interface{}{
+				"mainnet": []map[string]interface{}{
+					{"address": "wallet123", "network": "mainnet", "asset_type": "sol", "status": "active"},
+					{"address": "wallet123", "network": "mainnet", "asset_type": "spl-token", "token_mint": "mint1", "status": "active"},
+				},
+				"devnet": []map[string]interface{}{},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	assets, err := client.GetAll(context.Background(), "wallet123")
+	require.NoError(t, err)
+
+	assert.Equal(t, "wallet123", assets.Address)
+	require.Len(t, assets.Networks["mainnet"], 2)
+	assert.Equal(t, "mint1", assets.Networks["mainnet"][1].TokenMint)
+	assert.Empty(t, assets.Networks["devnet"])
+}
+
 func TestList_Success(t *testing.T) {
 	now := time.Now()
 
@@ -491,6 +578,167 @@ func TestClient_Await_ContextCancelled(t *testing.T) {
 // - No waiting for new transactions
 //
 // This handles the race condition where users pay before workflow starts.
+// TestClient_AwaitAny tests that client.AwaitAny() returns the first
+// transaction matching any matcher, and the index of the matcher that fired.
+//
+// EXPECTED BEHAVIOR:
+// - A historical transaction from lookback is checked against every matcher
+// - Non-matching transactions are skipped
+// - When several matchers match, the lowest index is reported
+func TestClient_AwaitAny(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		for _, tx := range []Transaction{
+			{Signature: "small-sig", BlockTime: time.Now().Add(-time.Hour), Amount: 10},
+			{Signature: "tier-sig", BlockTime: time.Now().Add(-time.Minute), Amount: 2000000},
+		} {
+			data, _ := json.Marshal(tx)
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	var calls []int
+	matcher := func(i int, min int64) func(*Transaction) bool {
+		return func(tx *Transaction) bool {
+			calls = append(calls, i)
+			return tx.Amount >= min
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, idx, err := client.AwaitAny(ctx, "wallet123", "mainnet", 24*time.Hour, []func(*Transaction) bool{
+		matcher(0, 5000000),
+		matcher(1, 1000000),
+		matcher(2, 100),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, tx)
+	assert.Equal(t, "tier-sig", tx.Signature)
+	assert.Equal(t, 1, idx)
+	assert.Equal(t, []int{0, 1, 2, 0, 1}, calls, "every matcher sees the historical transaction")
+}
+
+func TestClient_AwaitAny_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	tx, idx, err := client.AwaitAny(ctx, "wallet123", "mainnet", 0, []func(*Transaction) bool{
+		func(*Transaction) bool { return true },
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, tx)
+	assert.Equal(t, -1, idx)
+}
+
+func TestClient_AwaitAny_NoMatchers(t *testing.T) {
+	client := NewClient("http://127.0.0.1:1", nil, nil)
+	_, idx, err := client.AwaitAny(context.Background(), "wallet123", "mainnet", 0, nil)
+	assert.EqualError(t, err, "at least one matcher is required")
+	assert.Equal(t, -1, idx)
+}
+
+// TestClient_Stream tests that client.Stream() delivers every transaction
+// until the context is cancelled, then closes both channels.
+func TestClient_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		for i := range 3 {
+			data, _ := json.Marshal(Transaction{Signature: fmt.Sprintf("sig-%d", i), BlockTime: time.Now(), Amount: int64(i)})
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	txns, errs := client.Stream(ctx, "wallet123", "mainnet", time.Hour)
+	var got []string
+	for txn := range txns {
+		got = append(got, txn.Signature)
+		assert.Equal(t, "mainnet", txn.Network)
+		if len(got) == 3 {
+			cancel()
+		}
+	}
+	assert.Equal(t, []string{"sig-0", "sig-1", "sig-2"}, got)
+	assert.NoError(t, <-errs, "cancelling the context isn't an error")
+}
+
+// TestClient_Stream_SlowConsumer tests that a producer blocked on a consumer
+// that stopped reading exits once the context is cancelled.
+func TestClient_Stream_SlowConsumer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		for i := range 10 {
+			data, _ := json.Marshal(Transaction{Signature: fmt.Sprintf("sig-%d", i), BlockTime: time.Now()})
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	txns, errs := client.Stream(ctx, "wallet123", "mainnet", time.Hour)
+	first := <-txns
+	assert.Equal(t, "sig-0", first.Signature)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err, ok := <-errs:
+		assert.NoError(t, err)
+		assert.False(t, ok, "error channel should be closed")
+	case <-time.After(2 * time.Second):
+		t.Fatal("producer did not stop after the context was cancelled")
+	}
+}
+
+func TestClient_Stream_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"wallet not registered"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	txns, errs := client.Stream(context.Background(), "wallet123", "mainnet", 0)
+	for range txns {
+		t.Fatal("no transactions expected")
+	}
+	err := <-errs
+	require.Error(t, err)
+	assert.EqualError(t, err, "request failed: wallet not registered")
+}
+
 func TestClient_Await_LookbackFindsTransaction(t *testing.T) {
 	// Mock SSE server that includes lookback query parameter
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -550,7 +798,855 @@ func TestClient_Await_LookbackFindsTransaction(t *testing.T) {
 	t.Logf("✓ Await found historical transaction via lookback in %v", elapsed)
 }
 
+// TestClient_Await_DedupsReplayLiveOverlap tests that a transaction delivered
+// twice (once during lookback replay, once as a live event) only reaches the
+// matcher once.
+//
+// WHAT IS BEING TESTED:
+// The server replays historical transactions and then subscribes to live
+// events. A transaction landing between the two is delivered on both paths.
+// Matchers with side effects (counters, "first payment wins" logic) must not
+// observe it twice.
+//
+// EXPECTED BEHAVIOR:
+// - The same (signature, network) is sent twice and the matcher sees it once
+// - The same signature on a different network is treated as distinct
+// - Await still returns the eventual match
+func TestClient_Await_DedupsReplayLiveOverlap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		send := func(tx Transaction) {
+			data, _ := json.Marshal(tx)
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+			flusher.Flush()
+		}
+
+		overlap := Transaction{
+			Signature: "overlap-sig",
+			BlockTime: time.Now(),
+			Amount:    500000,
+		}
+
+		// Replayed from lookback (no network set, as older servers send it)
+		send(overlap)
+		// Delivered again as a live event
+		overlap.Network = "mainnet"
+		send(overlap)
+		// Same signature on another network is a different transaction
+		overlap.Network = "devnet"
+		send(overlap)
+
+		send(Transaction{
+			Signature: "match-sig",
+			Network:   "mainnet",
+			BlockTime: time.Now(),
+			Amount:    1000000,
+		})
+
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+
+	calls := make(map[string]int)
+	matcher := func(tx *Transaction) bool {
+		calls[tx.Signature+":"+tx.Network]++
+		return tx.Amount == 1000000
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := client.Await(ctx, "wallet123", "mainnet", 1*time.Hour, matcher)
+	require.NoError(t, err)
+	require.NotNil(t, tx)
+
+	assert.Equal(t, "match-sig", tx.Signature)
+	assert.Equal(t, 1, calls["overlap-sig:mainnet"], "matcher should see the overlapping transaction once")
+	assert.Equal(t, 1, calls["overlap-sig:devnet"])
+	assert.Equal(t, 1, calls["match-sig:mainnet"])
+}
+
 // Helper function
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestUpdateTransactionMetadata_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/api/v1/transactions/sig123/metadata", r.URL.Path)
+
+		var body map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.JSONEq(t, `"mainnet"`, string(body["network"]))
+		assert.JSONEq(t, `{"order_id":"abc"}`, string(body["metadata"]))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"signature": "sig123",
+			"amount":    1000000,
+			"metadata":  map[string]string{"order_id": "abc"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	txn, err := client.UpdateTransactionMetadata(context.Background(), "sig123", "mainnet", json.RawMessage(`{"order_id":"abc"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "sig123", txn.Signature)
+	assert.JSONEq(t, `{"order_id":"abc"}`, string(txn.Metadata))
+}
+
+func TestListTransactionsMulti_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/transactions/query", r.URL.Path)
+
+		var body map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.JSONEq(t, `[{"address":"walletA","network":"mainnet"},{"address":"walletB","network":"devnet"}]`, string(body["wallets"]))
+		assert.JSONEq(t, `"2025-01-01T00:00:00Z"`, string(body["start"]))
+		assert.NotContains(t, body, "end")
+		assert.JSONEq(t, `10`, string(body["limit"]))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"wallets": []map[string]interface{}{
+				{
+					"address":      "walletA",
+					"network":      "mainnet",
+					"transactions": []map[string]interface{}{{"signature": "sig1", "amount": 1000}},
+					"count":        1,
+				},
+				{
+					"address":      "walletB",
+					"network":      "devnet",
+					"transactions": []map[string]interface{}{},
+					"count":        0,
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	results, err := client.ListTransactionsMulti(context.Background(),
+		[]WalletKey{{Address: "walletA", Network: "mainnet"}, {Address: "walletB", Network: "devnet"}},
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "walletA", results[0].Address)
+	require.Len(t, results[0].Transactions, 1)
+	assert.Equal(t, "sig1", results[0].Transactions[0].Signature)
+	assert.Equal(t, "walletB", results[1].Address)
+	assert.Empty(t, results[1].Transactions)
+}
+
+func TestListTransactionsMulti_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "too many wallets: maximum is 50 per query"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	_, err := client.ListTransactionsMulti(context.Background(), []WalletKey{{Address: "walletA", Network: "mainnet"}}, time.Time{}, time.Time{}, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many wallets")
+}
+
+func TestListTransactionsWithOptions_Sort(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/transactions", r.URL.Path)
+		assert.Equal(t, "walletA", r.URL.Query().Get("wallet_address"))
+		assert.Equal(t, "mainnet", r.URL.Query().Get("network"))
+		assert.Equal(t, "amount_desc", r.URL.Query().Get("sort"))
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		assert.Equal(t, "20", r.URL.Query().Get("offset"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"transactions":[{"signature":"big","amount":9000000},{"signature":"small","amount":1000}],"count":2,"limit":10,"offset":20,"sort":"amount_desc"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	txns, err := client.ListTransactionsWithOptions(context.Background(), "walletA", "mainnet", ListTransactionsOptions{
+		Limit:  10,
+		Offset: 20,
+		Sort:   SortAmountDesc,
+	})
+	require.NoError(t, err)
+	require.Len(t, txns, 2)
+	assert.Equal(t, "big", txns[0].Signature)
+	assert.Equal(t, "small", txns[1].Signature)
+}
+
+func TestListTransactionsPage_Cursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("before") {
+		case "":
+			w.Write([]byte(`{"transactions":[{"signature":"sig3"},{"signature":"sig2"}],"count":2,"limit":2,"offset":0,"next_cursor":"c2"}`))
+		case "c2":
+			assert.Empty(t, r.URL.Query().Get("offset"))
+			w.Write([]byte(`{"transactions":[{"signature":"sig1"}],"count":1,"limit":2,"offset":0}`))
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("before"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	opts := ListTransactionsOptions{Limit: 2}
+	var got []string
+	for {
+		page, err := client.ListTransactionsPage(context.Background(), "walletA", "mainnet", opts)
+		require.NoError(t, err)
+		for _, txn := range page.Transactions {
+			got = append(got, txn.Signature)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Before = page.NextCursor
+	}
+	assert.Equal(t, []string{"sig3", "sig2", "sig1"}, got)
+}
+
+func TestListTransactionsWithOptions_Direction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "out", r.URL.Query().Get("direction"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"transactions":[{"signature":"withdraw","amount":500000000,"direction":"out"}],"count":1,"limit":100,"offset":0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	txns, err := client.ListTransactionsWithOptions(context.Background(), "walletA", "mainnet", ListTransactionsOptions{
+		Direction: DirectionOut,
+	})
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, DirectionOut, txns[0].Direction)
+}
+
+func TestSearchTransactionsByMemo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/transactions/search", r.URL.Path)
+		assert.Equal(t, "walletA", r.URL.Query().Get("wallet_address"))
+		assert.Equal(t, "mainnet", r.URL.Query().Get("network"))
+		assert.Equal(t, `"workflow_id":"abc"`, r.URL.Query().Get("q"))
+		assert.Equal(t, "prefix", r.URL.Query().Get("match"))
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"transactions": []map[string]interface{}{{"signature": "sig1", "memo": `{"workflow_id":"abc"}`}},
+			"count":        1,
+			"limit":        5,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	txns, err := client.SearchTransactionsByMemo(context.Background(), "walletA", "mainnet", `"workflow_id":"abc"`, MemoSearchOptions{Prefix: true, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, "sig1", txns[0].Signature)
+}
+
+func TestListTransactionsByAmount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/wallets/walletA/transactions", r.URL.Path)
+		assert.Equal(t, "mainnet", r.URL.Query().Get("network"))
+		assert.Equal(t, "mint1", r.URL.Query().Get("token_mint"))
+		assert.Equal(t, "10000000", r.URL.Query().Get("min_amount"))
+		assert.Equal(t, "10000000", r.URL.Query().Get("max_amount"))
+		assert.Equal(t, "", r.URL.Query().Get("limit"), "server default")
+		assert.Equal(t, "20", r.URL.Query().Get("offset"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"transactions": []map[string]interface{}{{"signature": "sig1", "amount": 10000000}},
+			"count":        1,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	txns, err := client.ListTransactionsByAmount(context.Background(), "walletA", "mainnet", AmountRangeOptions{
+		TokenMint: "mint1",
+		MinAmount: 10000000,
+		MaxAmount: 10000000,
+		Offset:    20,
+	})
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, int64(10000000), txns[0].Amount)
+}
+
+func TestCheckPayment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/payments/check", r.URL.Path)
+		assert.Equal(t, "walletA", r.URL.Query().Get("address"))
+		assert.Equal(t, "mainnet", r.URL.Query().Get("network"))
+		assert.Equal(t, "1000000", r.URL.Query().Get("min_amount"))
+		assert.Equal(t, "spl-token", r.URL.Query().Get("asset_type"))
+		assert.Equal(t, "mint1", r.URL.Query().Get("token_mint"))
+
+		if r.URL.Query().Get("memo") != "order-42" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "payment not found"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"signature": "sig1", "amount": 1000000, "memo": "order-42"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	opts := CheckPaymentOptions{MinAmount: 1000000, AssetType: "spl-token", TokenMint: "mint1"}
+
+	txn, err := client.CheckPayment(context.Background(), "walletA", "mainnet", "order-42", opts)
+	require.NoError(t, err)
+	require.NotNil(t, txn)
+	assert.Equal(t, "sig1", txn.Signature)
+	assert.Equal(t, int64(1000000), txn.Amount)
+
+	txn, err = client.CheckPayment(context.Background(), "walletA", "mainnet", "order-43", opts)
+	require.NoError(t, err)
+	assert.Nil(t, txn, "no payment yet is not an error")
+}
+
+func TestRegisterAssetWithMetadata_SendsMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.JSONEq(t, `{"customer":"acme"}`, string(body["metadata"]))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	err := client.RegisterAssetWithMetadata(context.Background(), "wallet123", "mainnet", "sol", "", json.RawMessage(`{"customer":"acme"}`))
+	require.NoError(t, err)
+}
+
+func TestRegisterAssetWithOptions_SendsTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.JSONEq(t, `["customer:acme","env:prod"]`, string(body["tags"]))
+		assert.JSONEq(t, `"https://example.com/paid"`, string(body["callback_url"]))
+		_, hasMetadata := body["metadata"]
+		assert.False(t, hasMetadata)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	err := client.RegisterAssetWithOptions(context.Background(), "wallet123", "mainnet", "sol", "", RegisterOptions{
+		Tags:        []string{"customer:acme", "env:prod"},
+		CallbackURL: "https://example.com/paid",
+	})
+	require.NoError(t, err)
+}
+
+func TestListWithOptions_FiltersByTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"customer:acme", "env:prod"}, r.URL.Query()["tag"])
+		assert.Equal(t, "true", r.URL.Query().Get("include_deleted"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"wallets":[{"address":"wallet123","network":"mainnet","asset_type":"sol","status":"active","tags":["customer:acme","env:prod"],"metadata":{"k":"v"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	wallets, err := client.ListWithOptions(context.Background(), ListOptions{
+		Tags:           []string{"customer:acme", "env:prod"},
+		IncludeDeleted: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, wallets, 1)
+	assert.Equal(t, []string{"customer:acme", "env:prod"}, wallets[0].Tags)
+	assert.JSONEq(t, `{"k":"v"}`, string(wallets[0].Metadata))
+}
+
+func TestClient_BaseURLWithPathPrefix(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/forohtoo/api/v1/wallet-assets/wallet1":
+			json.NewEncoder(w).Encode(Wallet{Address: "wallet1", Network: "mainnet"})
+		case "/forohtoo/api/v1/stream/transactions/wallet1":
+			w.Header().Set("Content-Type", "text/event-stream")
+			data, _ := json.Marshal(Transaction{Signature: "sig1", Network: "mainnet", Amount: 1})
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// Trailing slash must not produce "//api/v1/..." paths.
+	client := NewClient(server.URL+"/forohtoo/", nil, nil)
+
+	wallet, err := client.Get(context.Background(), "wallet1", "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, "wallet1", wallet.Address)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	txn, err := client.Await(ctx, "wallet1", "mainnet", 0, func(*Transaction) bool { return true })
+	require.NoError(t, err)
+	assert.Equal(t, "sig1", txn.Signature)
+
+	assert.Equal(t, []string{
+		"/forohtoo/api/v1/wallet-assets/wallet1",
+		"/forohtoo/api/v1/stream/transactions/wallet1",
+	}, paths)
+}
+
+// finalizedUpgradeServer streams one transaction as confirmed, then again as
+// finalized, then the confirmed copy once more, as a replay would.
+func finalizedUpgradeServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		tx := Transaction{
+			Signature: "pay-sig",
+			Network:   "mainnet",
+			BlockTime: time.Now(),
+			Amount:    1000000,
+		}
+		for _, status := range []string{"confirmed", "finalized", "confirmed", "finalized"} {
+			tx.ConfirmationStatus = status
+			data, _ := json.Marshal(tx)
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+			flusher.Flush()
+		}
+		data, _ := json.Marshal(Transaction{Signature: "end-sig", Network: "mainnet", BlockTime: time.Now()})
+		w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+		flusher.Flush()
+
+		<-r.Context().Done()
+	}))
+}
+
+func TestClient_Await_IgnoresFinalizedUpgrade(t *testing.T) {
+	server := finalizedUpgradeServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The matcher sees each (signature, network) once, so the finalized
+	// re-publish can't make an Await match a second time.
+	var statuses []string
+	tx, err := client.Await(ctx, "wallet123", "mainnet", 0, func(tx *Transaction) bool {
+		if tx.Signature == "pay-sig" {
+			statuses = append(statuses, tx.ConfirmationStatus)
+		}
+		return tx.Signature == "end-sig"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "end-sig", tx.Signature)
+	assert.Equal(t, []string{"confirmed"}, statuses)
+}
+
+func TestClient_Stream_FinalizedUpgrade(t *testing.T) {
+	server := finalizedUpgradeServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	txns, errs := client.Stream(ctx, "wallet123", "mainnet", 0)
+	var got []*Transaction
+	for txn := range txns {
+		got = append(got, txn)
+		if txn.Signature == "end-sig" {
+			cancel()
+		}
+	}
+	require.NoError(t, <-errs)
+
+	// The upgrade is delivered once and marked; replays of either status
+	// are not.
+	require.Len(t, got, 3)
+	assert.Equal(t, "confirmed", got[0].ConfirmationStatus)
+	assert.False(t, got[0].FinalizedUpgrade)
+	assert.Equal(t, "pay-sig", got[1].Signature)
+	assert.Equal(t, "finalized", got[1].ConfirmationStatus)
+	assert.True(t, got[1].FinalizedUpgrade)
+	assert.Equal(t, "end-sig", got[2].Signature)
+	assert.False(t, got[2].FinalizedUpgrade)
+}
+
+func TestClient_Await_ResumesAfterReconnect(t *testing.T) {
+	var mu sync.Mutex
+	var queries []url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		attempt := len(queries)
+		mu.Unlock()
+
+		switch attempt {
+		case 2:
+			// The draining replica is still in the load balancer.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"server is shutting down, reconnect shortly"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		send := func(tx Transaction) {
+			data, _ := json.Marshal(tx)
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+			flusher.Flush()
+		}
+
+		seen := Transaction{Signature: "seen-sig", Network: "mainnet", BlockTime: time.Now(), Amount: 1}
+		if attempt == 1 {
+			send(seen)
+			w.Write([]byte("event: reconnect\ndata: {\"cursor\":\"seen-sig\",\"retry_ms\":10}\n\n"))
+			flusher.Flush()
+			return
+		}
+
+		// Resumed stream: the cursor's block may be replayed.
+		send(seen)
+		send(Transaction{Signature: "match-sig", Network: "mainnet", BlockTime: time.Now(), Amount: 1000000})
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+
+	calls := make(map[string]int)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := client.Await(ctx, "wallet123", "mainnet", 0, func(tx *Transaction) bool {
+		calls[tx.Signature]++
+		return tx.Amount == 1000000
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "match-sig", tx.Signature)
+	assert.Equal(t, 1, calls["seen-sig"], "matcher should not see the replayed cursor transaction again")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, queries, 3)
+	assert.Empty(t, queries[0].Get("cursor"))
+	assert.Equal(t, "seen-sig", queries[2].Get("cursor"))
+	assert.NotEmpty(t, queries[2].Get("lookback"), "reconnect should look back over the gap")
+}
+
+func TestClient_Await_UnavailableWithoutReconnect(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"server is shutting down, reconnect shortly"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	_, err := client.Await(context.Background(), "wallet123", "mainnet", 0, func(*Transaction) bool { return true })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shutting down")
+	assert.Equal(t, 1, attempts, "a 503 on the first connection is returned, not retried")
+}
+
+func TestClient_Await_ResumesAfterDisconnect(t *testing.T) {
+	var mu sync.Mutex
+	var queries []url.Values
+	t0 := time.Now().Add(-time.Minute)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		attempt := len(queries)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		send := func(tx Transaction) {
+			data, _ := json.Marshal(tx)
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+			flusher.Flush()
+		}
+
+		newest := Transaction{Signature: "newest-sig", Network: "mainnet", BlockTime: t0.Add(time.Second), Amount: 1}
+		if attempt == 1 {
+			send(newest)
+			// Received after newest but from an earlier block.
+			send(Transaction{Signature: "older-sig", Network: "mainnet", BlockTime: t0, Amount: 1})
+			// The server dies without a reconnect event.
+			return
+		}
+
+		// Resumed stream: the cursor's block is replayed, then the payment
+		// that landed while the client was disconnected.
+		send(newest)
+		send(Transaction{Signature: "gap-sig", Network: "mainnet", BlockTime: t0.Add(2 * time.Second), Amount: 1000000})
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+
+	calls := make(map[string]int)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := client.Await(ctx, "wallet123", "mainnet", 0, func(tx *Transaction) bool {
+		calls[tx.Signature]++
+		return tx.Amount == 1000000
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gap-sig", tx.Signature)
+	assert.Equal(t, 1, calls["newest-sig"], "matcher should not see the replayed cursor transaction again")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, queries, 2)
+	assert.Empty(t, queries[0].Get("cursor"))
+	assert.Equal(t, "newest-sig", queries[1].Get("cursor"), "resume from the latest block received")
+}
+
+func TestClient_Await_DisconnectWithoutReconnects(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: connected\ndata: {}\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithReconnectPolicy(ReconnectPolicy{}))
+	_, err := client.Await(context.Background(), "wallet123", "mainnet", 0, func(*Transaction) bool { return true })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "closed unexpectedly")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestClient_Await_ReconnectsExhausted(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: connected\ndata: {}\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithReconnectPolicy(ReconnectPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond}))
+	_, err := client.Await(context.Background(), "wallet123", "mainnet", 0, func(*Transaction) bool { return true })
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrAwaitReconnectsExhausted)
+	assert.NotErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "after 3 attempts")
+	assert.Equal(t, int32(4), attempts.Load())
+}
+
+func TestClient_Await_ReconnectsResetAfterSuccess(t *testing.T) {
+	const drops = 5
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		if attempts.Add(1) <= drops {
+			// Every reconnect succeeds, then the stream drops again.
+			w.Write([]byte("event: connected\ndata: {}\n\n"))
+			return
+		}
+		data, _ := json.Marshal(Transaction{Signature: "paid-sig", Network: "mainnet", BlockTime: time.Now(), Amount: 1})
+		w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithReconnectPolicy(ReconnectPolicy{MaxAttempts: 2, BaseDelay: 10 * time.Millisecond}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := client.Await(ctx, "wallet123", "mainnet", 0, func(*Transaction) bool { return true })
+	require.NoError(t, err, "separate drops, each followed by a successful reconnect, shouldn't exhaust MaxAttempts")
+	assert.Equal(t, "paid-sig", tx.Signature)
+	assert.Equal(t, int32(drops+1), attempts.Load())
+}
+
+func TestReconnectPolicy_Delay(t *testing.T) {
+	p := ReconnectPolicy{MaxAttempts: 10, BaseDelay: time.Second}
+	for n, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 6: maxReconnectDelay, 50: maxReconnectDelay} {
+		for range 20 {
+			d := p.delay(n)
+			assert.GreaterOrEqual(t, d, want/2, "attempt %d", n)
+			assert.LessOrEqual(t, d, want, "attempt %d", n)
+		}
+	}
+	assert.Zero(t, ReconnectPolicy{}.delay(1))
+}
+
+func TestAwaitCursor_Advance(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	var cursor awaitCursor
+
+	cursor.advance(&Transaction{Signature: "a", BlockTime: t0})
+	assert.Equal(t, "a", cursor.Signature)
+
+	cursor.advance(&Transaction{Signature: "b", BlockTime: t0.Add(-time.Second)})
+	assert.Equal(t, "a", cursor.Signature, "an older block doesn't move the cursor back")
+
+	cursor.advance(&Transaction{Signature: "c", BlockTime: t0})
+	assert.Equal(t, "c", cursor.Signature, "the same block moves it to the latest received")
+}
+
+func TestBatchRegisterAssets(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		address := body["address"].(string)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(address, "new"):
+			w.WriteHeader(http.StatusPaymentRequired)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":      "payment_required",
+				"workflow_id": "payment-registration:" + address,
+				"status_url":  "/api/v1/registration-status/payment-registration:" + address,
+				"invoice":     map[string]interface{}{"id": address, "amount": 1000000, "memo": "forohtoo-reg:" + address},
+			})
+		case strings.HasPrefix(address, "bad"):
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid address"}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"address": address, "network": "mainnet", "asset_type": "sol", "status": "active",
+				"created_at": time.Now(), "updated_at": time.Now(),
+			})
+		}
+	}))
+	defer server.Close()
+
+	var reqs []RegisterAssetRequest
+	for i := 0; i < 10; i++ {
+		reqs = append(reqs, RegisterAssetRequest{Address: fmt.Sprintf("wallet%d", i), Network: "mainnet", AssetType: "sol"})
+	}
+	reqs = append(reqs,
+		RegisterAssetRequest{Address: "new1", Network: "mainnet", AssetType: "sol"},
+		RegisterAssetRequest{Address: "bad1", Network: "mainnet", AssetType: "sol"},
+	)
+
+	client := NewClient(server.URL, nil, nil)
+	results, err := client.BatchRegisterAssets(context.Background(), reqs)
+	require.NoError(t, err)
+	require.Len(t, results, 12)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, reqs[i], results[i].Request, "results are in request order")
+		assert.Equal(t, http.StatusCreated, results[i].StatusCode)
+		require.NoError(t, results[i].Err)
+		require.NotNil(t, results[i].Wallet)
+		assert.Equal(t, reqs[i].Address, results[i].Wallet.Address)
+	}
+
+	paid := results[10]
+	assert.Equal(t, http.StatusPaymentRequired, paid.StatusCode)
+	require.NoError(t, paid.Err)
+	require.NotNil(t, paid.PaymentRequired)
+	assert.Equal(t, "payment-registration:new1", paid.PaymentRequired.WorkflowID)
+	assert.Equal(t, int64(1000000), paid.PaymentRequired.Invoice.Amount)
+	assert.Equal(t, "forohtoo-reg:new1", paid.PaymentRequired.Invoice.Memo)
+
+	bad := results[11]
+	assert.Equal(t, http.StatusBadRequest, bad.StatusCode)
+	assert.EqualError(t, bad.Err, "request failed: invalid address")
+
+	assert.LessOrEqual(t, peak.Load(), int32(batchRegisterConcurrency))
+}
+
+func TestBatchRegisterAssets_Unreachable(t *testing.T) {
+	client := NewClient("http://127.0.0.1:1", nil, nil)
+	results, err := client.BatchRegisterAssets(context.Background(), []RegisterAssetRequest{{Address: "wallet1", Network: "mainnet", AssetType: "sol"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Zero(t, results[0].StatusCode)
+	assert.Error(t, results[0].Err)
+}
+
+func TestIngestTransaction_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/admin/ingest", r.URL.Path)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]string{"signature": "sig123", "network": "devnet"}, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"signature":"sig123","network":"devnet","matched":1,"written":1,"skipped":0,
+			"transactions":[{"signature":"sig123","wallet_address":"wallet123","amount":250000}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	result, err := client.IngestTransaction(context.Background(), "sig123", "devnet")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Written)
+	require.Len(t, result.Transactions, 1)
+	assert.Equal(t, int64(250000), result.Transactions[0].Amount)
+}
+
+func TestIngestTransaction_NotMonitored(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":"transaction does not involve a monitored wallet on devnet"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	_, err := client.IngestTransaction(context.Background(), "sig123", "devnet")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "monitored wallet")
+}
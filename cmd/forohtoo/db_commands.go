@@ -1,13 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"slices"
+	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/brojonat/forohtoo/client"
 	"github.com/brojonat/forohtoo/service/db"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/urfave/cli/v2"
@@ -24,6 +30,14 @@ func listWalletsCommand() *cli.Command {
 				Aliases: []string{"s"},
 				Usage:   "Filter by status (active, paused, error)",
 			},
+			&cli.BoolFlag{
+				Name:  "include-deleted",
+				Usage: "Include unregistered (soft-deleted) wallets",
+			},
+			&cli.StringSliceFlag{
+				Name:  "tag",
+				Usage: "Only list wallets with this tag (e.g. customer:acme); repeat to require several",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			store, closer, err := getStore(c)
@@ -32,7 +46,10 @@ func listWalletsCommand() *cli.Command {
 			}
 			defer closer()
 
-			wallets, err := store.ListWallets(context.Background())
+			wallets, err := store.ListWallets(context.Background(), db.ListWalletsParams{
+				IncludeDeleted: c.Bool("include-deleted"),
+				Tags:           c.StringSlice("tag"),
+			})
 			if err != nil {
 				return fmt.Errorf("failed to list wallets: %w", err)
 			}
@@ -49,30 +66,34 @@ func listWalletsCommand() *cli.Command {
 				wallets = filtered
 			}
 
-			if c.Bool("json") {
-				return outputJSON(wallets)
-			}
-
-			// Pretty table output
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "ADDRESS\tNETWORK\tASSET\tSTATUS\tCREATED")
-			for _, wallet := range wallets {
-				asset := wallet.AssetType
-				if wallet.TokenMint != "" {
-					asset = wallet.AssetType + ":" + wallet.TokenMint
+			return render(c, formatTable, wallets, func(out io.Writer) error {
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ADDRESS\tNETWORK\tASSET\tSTATUS\tTAGS\tCREATED")
+				for _, wallet := range wallets {
+					asset := wallet.AssetType
+					if wallet.TokenMint != "" {
+						asset = wallet.AssetType + ":" + wallet.TokenMint
+					}
+					status := wallet.Status
+					if wallet.DeletedAt != nil {
+						status = "deleted"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+						wallet.Address,
+						wallet.Network,
+						asset,
+						status,
+						strings.Join(wallet.Tags, ","),
+						wallet.CreatedAt.Format(time.RFC3339),
+					)
+				}
+				if err := w.Flush(); err != nil {
+					return err
 				}
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-					wallet.Address,
-					wallet.Network,
-					asset,
-					wallet.Status,
-					wallet.CreatedAt.Format(time.RFC3339),
-				)
-			}
-			w.Flush()
 
-			fmt.Fprintf(os.Stderr, "\nTotal: %d wallets\n", len(wallets))
-			return nil
+				fmt.Fprintf(os.Stderr, "\nTotal: %d wallets\n", len(wallets))
+				return nil
+			})
 		},
 	}
 }
@@ -126,26 +147,343 @@ func getWalletCommand() *cli.Command {
 				return fmt.Errorf("failed to get wallet: %w", err)
 			}
 
-			if c.Bool("json") {
-				return outputJSON(wallet)
+			return render(c, formatTable, wallet, func(w io.Writer) error {
+				fmt.Fprintf(w, "Address:       %s\n", wallet.Address)
+				fmt.Fprintf(w, "Network:       %s\n", wallet.Network)
+				fmt.Fprintf(w, "Asset Type:    %s\n", wallet.AssetType)
+				if wallet.TokenMint != "" {
+					fmt.Fprintf(w, "Token Mint:    %s\n", wallet.TokenMint)
+				}
+				fmt.Fprintf(w, "Status:        %s\n", wallet.Status)
+				fmt.Fprintf(w, "Created:       %s\n", wallet.CreatedAt.Format(time.RFC3339))
+				fmt.Fprintf(w, "Updated:       %s\n", wallet.UpdatedAt.Format(time.RFC3339))
+				if len(wallet.Tags) > 0 {
+					fmt.Fprintf(w, "Tags:          %s\n", strings.Join(wallet.Tags, ", "))
+				}
+				if wallet.DeletedAt != nil {
+					fmt.Fprintf(w, "Deleted:       %s\n", wallet.DeletedAt.Format(time.RFC3339))
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func purgeWalletsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "purge-wallets",
+		Usage: "Permanently remove unregistered (soft-deleted) wallets",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "older-than",
+				Usage: "Only purge wallets unregistered at least this long ago (e.g. 2160h)",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "List the wallets that would be purged without deleting them",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			olderThan := c.Duration("older-than")
+			if olderThan < 0 {
+				return fmt.Errorf("--older-than must not be negative")
+			}
+			cutoff := time.Now().Add(-olderThan)
+
+			store, closer, err := getStore(c)
+			if err != nil {
+				return err
+			}
+			defer closer()
+
+			if c.Bool("dry-run") {
+				wallets, err := store.ListWallets(context.Background(), db.ListWalletsParams{IncludeDeleted: true})
+				if err != nil {
+					return fmt.Errorf("failed to list wallets: %w", err)
+				}
+				purgeable := make([]*db.Wallet, 0)
+				for _, w := range wallets {
+					if w.DeletedAt != nil && w.DeletedAt.Before(cutoff) {
+						purgeable = append(purgeable, w)
+					}
+				}
+
+				return render(c, formatTable, purgeable, func(out io.Writer) error {
+					w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "ADDRESS\tNETWORK\tASSET\tDELETED")
+					for _, wallet := range purgeable {
+						asset := wallet.AssetType
+						if wallet.TokenMint != "" {
+							asset = wallet.AssetType + ":" + wallet.TokenMint
+						}
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+							wallet.Address,
+							wallet.Network,
+							asset,
+							wallet.DeletedAt.Format(time.RFC3339),
+						)
+					}
+					if err := w.Flush(); err != nil {
+						return err
+					}
+
+					fmt.Fprintf(os.Stderr, "\nWould purge: %d wallets\n", len(purgeable))
+					return nil
+				})
+			}
+
+			purged, err := store.PurgeDeletedWallets(context.Background(), cutoff)
+			if err != nil {
+				return fmt.Errorf("failed to purge wallets: %w", err)
+			}
+
+			return render(c, formatTable, map[string]int64{"purged": purged}, func(io.Writer) error {
+				fmt.Fprintf(os.Stderr, "Purged: %d wallets\n", purged)
+				return nil
+			})
+		},
+	}
+}
+
+// walletKey identifies a wallet registration across environments.
+type walletKey struct {
+	Address   string `json:"address"`
+	Network   string `json:"network"`
+	AssetType string `json:"asset_type"`
+	TokenMint string `json:"token_mint,omitempty"`
+}
+
+// row formats the key as tab-separated ADDRESS, NETWORK and ASSET columns.
+func (k walletKey) row() string {
+	asset := k.AssetType
+	if k.TokenMint != "" {
+		asset = k.AssetType + ":" + k.TokenMint
+	}
+	return k.Address + "\t" + k.Network + "\t" + asset
+}
+
+func keyOf(w *db.Wallet) walletKey {
+	return walletKey{Address: w.Address, Network: w.Network, AssetType: w.AssetType, TokenMint: w.TokenMint}
+}
+
+// walletChange is a registration present in both environments whose fields
+// differ.
+type walletChange struct {
+	walletKey
+	Fields []string `json:"fields"` // status, tags and/or metadata
+}
+
+// walletApplyFailure is a registration --apply could not create in the target.
+type walletApplyFailure struct {
+	walletKey
+	Error string `json:"error"`
+}
+
+// walletDiff compares the registered wallets of a source and a target
+// environment. Added and Removed are relative to the target: Added are in
+// the source only, Removed in the target only.
+type walletDiff struct {
+	Added   []walletKey          `json:"added"`
+	Removed []walletKey          `json:"removed"`
+	Changed []walletChange       `json:"changed"`
+	Applied []walletKey          `json:"applied,omitempty"`
+	Failed  []walletApplyFailure `json:"failed,omitempty"`
+
+	missing []*db.Wallet // source wallets behind Added, for --apply
+}
+
+// diffWallets compares two environments' registrations by wallet key
+// (address, network, asset type, token mint). Results are sorted by key.
+func diffWallets(source, target []*db.Wallet) *walletDiff {
+	diff := &walletDiff{Added: []walletKey{}, Removed: []walletKey{}, Changed: []walletChange{}}
+
+	targetByKey := make(map[walletKey]*db.Wallet, len(target))
+	for _, w := range target {
+		targetByKey[keyOf(w)] = w
+	}
+	sourceKeys := make(map[walletKey]bool, len(source))
+	for _, w := range source {
+		key := keyOf(w)
+		sourceKeys[key] = true
+		t, ok := targetByKey[key]
+		if !ok {
+			diff.Added = append(diff.Added, key)
+			diff.missing = append(diff.missing, w)
+			continue
+		}
+		if fields := walletFieldDiff(w, t); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, walletChange{walletKey: key, Fields: fields})
+		}
+	}
+	for _, w := range target {
+		if key := keyOf(w); !sourceKeys[key] {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	less := func(a, b walletKey) int {
+		return cmp.Or(
+			strings.Compare(a.Address, b.Address),
+			strings.Compare(a.Network, b.Network),
+			strings.Compare(a.AssetType, b.AssetType),
+			strings.Compare(a.TokenMint, b.TokenMint),
+		)
+	}
+	slices.SortFunc(diff.Added, less)
+	slices.SortFunc(diff.Removed, less)
+	slices.SortFunc(diff.Changed, func(a, b walletChange) int { return less(a.walletKey, b.walletKey) })
+	slices.SortFunc(diff.missing, func(a, b *db.Wallet) int { return less(keyOf(a), keyOf(b)) })
+	return diff
+}
+
+// walletFieldDiff lists the fields that differ between two registrations of
+// the same wallet key. Tag order is ignored.
+func walletFieldDiff(a, b *db.Wallet) []string {
+	var fields []string
+	if a.Status != b.Status {
+		fields = append(fields, "status")
+	}
+	aTags, bTags := slices.Clone(a.Tags), slices.Clone(b.Tags)
+	slices.Sort(aTags)
+	slices.Sort(bTags)
+	if !slices.Equal(aTags, bTags) {
+		fields = append(fields, "tags")
+	}
+	if !bytes.Equal(a.Metadata, b.Metadata) {
+		fields = append(fields, "metadata")
+	}
+	return fields
+}
+
+func diffWalletsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "diff-wallets",
+		Usage: "Compare wallet registrations between two environments' databases",
+		Description: `Lists registrations present in the source database but not the target
+(added), in the target but not the source (removed), and in both with a
+different status, tags or metadata (changed). Wallets are matched by
+address, network, asset type and token mint; unregistered wallets are ignored.
+
+With --apply, the added wallets are registered in the target through its HTTP
+API (--target-server), carrying their tags and metadata. Removed and changed
+wallets are only reported. A target with the payment gateway enabled will
+refuse registrations that need payment; those are reported as failed.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "source-url",
+				Usage:    "Database URL of the source environment",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "target-url",
+				Usage:    "Database URL of the target environment",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "apply",
+				Usage: "Register the wallets missing from the target via --target-server",
+			},
+			&cli.StringFlag{
+				Name:  "target-server",
+				Usage: "Target server URL, required with --apply",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "With --apply, report what would be registered without registering it",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			apply := c.Bool("apply")
+			if apply && c.String("target-server") == "" {
+				return fmt.Errorf("--target-server is required with --apply")
+			}
+			dryRun := c.Bool("dry-run")
+
+			source, closeSource, err := openStore(c.String("source-url"))
+			if err != nil {
+				return fmt.Errorf("source: %w", err)
+			}
+			defer closeSource()
+			target, closeTarget, err := openStore(c.String("target-url"))
+			if err != nil {
+				return fmt.Errorf("target: %w", err)
 			}
+			defer closeTarget()
 
-			// Pretty output
-			fmt.Printf("Address:       %s\n", wallet.Address)
-			fmt.Printf("Network:       %s\n", wallet.Network)
-			fmt.Printf("Asset Type:    %s\n", wallet.AssetType)
-			if wallet.TokenMint != "" {
-				fmt.Printf("Token Mint:    %s\n", wallet.TokenMint)
+			ctx := context.Background()
+			sourceWallets, err := source.ListWallets(ctx, db.ListWalletsParams{})
+			if err != nil {
+				return fmt.Errorf("failed to list source wallets: %w", err)
+			}
+			targetWallets, err := target.ListWallets(ctx, db.ListWalletsParams{})
+			if err != nil {
+				return fmt.Errorf("failed to list target wallets: %w", err)
 			}
-			fmt.Printf("Status:        %s\n", wallet.Status)
-			fmt.Printf("Created:       %s\n", wallet.CreatedAt.Format(time.RFC3339))
-			fmt.Printf("Updated:       %s\n", wallet.UpdatedAt.Format(time.RFC3339))
 
+			diff := diffWallets(sourceWallets, targetWallets)
+
+			if apply && !dryRun {
+				logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+				cl := client.NewClient(c.String("target-server"), nil, logger, apiKeyOption(c))
+				applyWalletDiff(ctx, cl, diff)
+			}
+
+			err = render(c, formatTable, diff, func(out io.Writer) error {
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "\tADDRESS\tNETWORK\tASSET\tDETAIL")
+				for _, key := range diff.Added {
+					fmt.Fprintf(w, "+\t%s\tsource only\n", key.row())
+				}
+				for _, key := range diff.Removed {
+					fmt.Fprintf(w, "-\t%s\ttarget only\n", key.row())
+				}
+				for _, change := range diff.Changed {
+					fmt.Fprintf(w, "~\t%s\t%s differ\n", change.row(), strings.Join(change.Fields, ", "))
+				}
+				for _, failure := range diff.Failed {
+					fmt.Fprintf(w, "!\t%s\tapply failed: %s\n", failure.row(), failure.Error)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(os.Stderr, "\nAdded: %d, removed: %d, changed: %d\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+				switch {
+				case apply && dryRun:
+					fmt.Fprintf(os.Stderr, "Would register: %d wallets\n", len(diff.Added))
+				case apply:
+					fmt.Fprintf(os.Stderr, "Registered: %d, failed: %d\n", len(diff.Applied), len(diff.Failed))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if len(diff.Failed) > 0 {
+				return fmt.Errorf("failed to register %d of %d wallets in the target", len(diff.Failed), len(diff.Added))
+			}
 			return nil
 		},
 	}
 }
 
+// applyWalletDiff registers the wallets missing from the target, recording
+// each outcome on diff. A failure doesn't stop the remaining registrations.
+func applyWalletDiff(ctx context.Context, cl *client.Client, diff *walletDiff) {
+	for _, w := range diff.missing {
+		key := keyOf(w)
+		err := cl.RegisterAssetWithOptions(ctx, w.Address, w.Network, w.AssetType, w.TokenMint, client.RegisterOptions{
+			Metadata: w.Metadata,
+			Tags:     w.Tags,
+		})
+		if err != nil {
+			diff.Failed = append(diff.Failed, walletApplyFailure{walletKey: key, Error: err.Error()})
+			continue
+		}
+		diff.Applied = append(diff.Applied, key)
+	}
+}
+
 func listTransactionsCommand() *cli.Command {
 	return &cli.Command{
 		Name:    "list-transactions",
@@ -175,7 +513,7 @@ func listTransactionsCommand() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:  "format",
-				Usage: "Output format: json (default) or human",
+				Usage: "Deprecated: use --output. json (default) or human",
 				Value: "json",
 			},
 		},
@@ -222,54 +560,54 @@ func listTransactionsCommand() *cli.Command {
 				return fmt.Errorf("please specify --wallet flag to list transactions")
 			}
 
-			format := c.String("format")
-
 			// Default to JSON output (following project philosophy: stdout = JSON)
-			if format == "json" {
-				return outputJSON(transactions)
+			def := formatJSON
+			if c.String("format") == "human" {
+				def = formatTable
 			}
 
-			// Human-readable output (to stdout, per user preference)
-			if len(transactions) == 0 {
-				fmt.Println("No transactions found")
-				return nil
-			}
-
-			for i, tx := range transactions {
-				if i > 0 {
-					fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			return render(c, def, transactions, func(w io.Writer) error {
+				if len(transactions) == 0 {
+					fmt.Fprintln(w, "No transactions found")
+					return nil
 				}
 
-				fmt.Printf("Signature:      %s\n", tx.Signature)
-				fmt.Printf("From:           %s\n", formatOptionalAddress(tx.FromAddress))
-				fmt.Printf("To (monitored): %s\n", tx.WalletAddress)
-				fmt.Printf("Block Time:     %s\n", tx.BlockTime.Format(time.RFC3339))
-				fmt.Printf("Slot:           %d\n", tx.Slot)
-
-				// Format amount based on whether it's SOL or a token
-				if tx.TokenMint != nil && *tx.TokenMint != "" {
-					fmt.Printf("Amount:         %d (token units)\n", tx.Amount)
-					fmt.Printf("Token Mint:     %s\n", *tx.TokenMint)
-				} else {
-					// Native SOL - convert lamports to SOL for readability
-					solAmount := float64(tx.Amount) / 1e9
-					fmt.Printf("Amount:         %.9f SOL (%d lamports)\n", solAmount, tx.Amount)
-					fmt.Printf("Token Mint:     (native SOL)\n")
-				}
+				for i, tx := range transactions {
+					if i > 0 {
+						fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+					}
 
-				if tx.Memo != nil && *tx.Memo != "" {
-					fmt.Printf("Memo:           %s\n", *tx.Memo)
-				} else {
-					fmt.Printf("Memo:           (none)\n")
-				}
+					fmt.Fprintf(w, "Signature:      %s\n", tx.Signature)
+					fmt.Fprintf(w, "From:           %s\n", formatOptionalAddress(tx.FromAddress))
+					fmt.Fprintf(w, "To (monitored): %s\n", tx.WalletAddress)
+					fmt.Fprintf(w, "Block Time:     %s\n", tx.BlockTime.Format(time.RFC3339))
+					fmt.Fprintf(w, "Slot:           %d\n", tx.Slot)
+
+					// Format amount based on whether it's SOL or a token
+					if tx.TokenMint != nil && *tx.TokenMint != "" {
+						fmt.Fprintf(w, "Amount:         %d (token units)\n", tx.Amount)
+						fmt.Fprintf(w, "Token Mint:     %s\n", *tx.TokenMint)
+					} else {
+						// Native SOL - convert lamports to SOL for readability
+						solAmount := float64(tx.Amount) / 1e9
+						fmt.Fprintf(w, "Amount:         %.9f SOL (%d lamports)\n", solAmount, tx.Amount)
+						fmt.Fprintf(w, "Token Mint:     (native SOL)\n")
+					}
 
-				fmt.Printf("Status:         %s\n", tx.ConfirmationStatus)
-				fmt.Printf("Created At:     %s\n", tx.CreatedAt.Format(time.RFC3339))
-			}
+					if tx.Memo != nil && *tx.Memo != "" {
+						fmt.Fprintf(w, "Memo:           %s%s\n", *tx.Memo, truncatedSuffix(tx.MemoTruncated))
+					} else {
+						fmt.Fprintf(w, "Memo:           (none)\n")
+					}
 
-			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-			fmt.Fprintf(os.Stderr, "\nTotal: %d transactions\n", len(transactions))
-			return nil
+					fmt.Fprintf(w, "Status:         %s\n", tx.ConfirmationStatus)
+					fmt.Fprintf(w, "Created At:     %s\n", tx.CreatedAt.Format(time.RFC3339))
+				}
+
+				fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+				fmt.Fprintf(os.Stderr, "\nTotal: %d transactions\n", len(transactions))
+				return nil
+			})
 		},
 	}
 }
@@ -285,7 +623,12 @@ func getStore(c *cli.Context) (*db.Store, func(), error) {
 	if dbURL == "" {
 		return nil, nil, fmt.Errorf("database-url is required (set DATABASE_URL env var or use --database-url)")
 	}
+	return openStore(dbURL)
+}
 
+// openStore connects to the database at dbURL. The returned func closes the
+// connection pool.
+func openStore(dbURL string) (*db.Store, func(), error) {
 	pool, err := pgxpool.New(context.Background(), dbURL)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -302,13 +645,6 @@ func getStore(c *cli.Context) (*db.Store, func(), error) {
 	return store, closer, nil
 }
 
-// Helper function to output JSON
-func outputJSON(v interface{}) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(v)
-}
-
 // Helper function to format optional address
 func formatOptionalAddress(addr *string) string {
 	if addr != nil && *addr != "" {
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/brojonat/forohtoo/client"
 	"github.com/brojonat/forohtoo/service/db"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -292,6 +295,74 @@ func TestListTransactionsCommand_RequiresWallet(t *testing.T) {
 }
 
 // createTestApp creates a CLI app for testing
+func TestDiffWallets(t *testing.T) {
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	source := []*db.Wallet{
+		{Address: "walletB", Network: "mainnet", AssetType: "sol", Status: "active", Tags: []string{}},
+		{Address: "walletA", Network: "mainnet", AssetType: "spl-token", TokenMint: usdc, Status: "active", Tags: []string{"b", "a"}},
+		{Address: "walletC", Network: "devnet", AssetType: "sol", Status: "paused", Tags: []string{}, Metadata: json.RawMessage(`{"k": 1}`)},
+		{Address: "walletD", Network: "mainnet", AssetType: "sol", Status: "active", Tags: []string{"x"}},
+	}
+	target := []*db.Wallet{
+		// Same key, tags in another order: unchanged.
+		{Address: "walletA", Network: "mainnet", AssetType: "spl-token", TokenMint: usdc, Status: "active", Tags: []string{"a", "b"}},
+		{Address: "walletC", Network: "devnet", AssetType: "sol", Status: "active", Tags: []string{}},
+		// Same address, other network: a different key.
+		{Address: "walletD", Network: "devnet", AssetType: "sol", Status: "active", Tags: []string{"x"}},
+	}
+
+	diff := diffWallets(source, target)
+
+	assert.Equal(t, []walletKey{
+		{Address: "walletB", Network: "mainnet", AssetType: "sol"},
+		{Address: "walletD", Network: "mainnet", AssetType: "sol"},
+	}, diff.Added)
+	assert.Equal(t, []walletKey{{Address: "walletD", Network: "devnet", AssetType: "sol"}}, diff.Removed)
+	assert.Equal(t, []walletChange{{
+		walletKey: walletKey{Address: "walletC", Network: "devnet", AssetType: "sol"},
+		Fields:    []string{"status", "metadata"},
+	}}, diff.Changed)
+	require.Len(t, diff.missing, 2)
+	assert.Equal(t, "walletB", diff.missing[0].Address)
+
+	empty := diffWallets(nil, nil)
+	body, err := json.Marshal(empty)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"added": [], "removed": [], "changed": []}`, string(body))
+}
+
+func TestApplyWalletDiff(t *testing.T) {
+	var registered []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["address"] == "walletPaid" {
+			w.WriteHeader(http.StatusPaymentRequired)
+			json.NewEncoder(w).Encode(map[string]string{"error": "payment required"})
+			return
+		}
+		registered = append(registered, body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	source := []*db.Wallet{
+		{Address: "walletA", Network: "mainnet", AssetType: "sol", Status: "active", Tags: []string{"customer:acme"}, Metadata: json.RawMessage(`{"order":1}`)},
+		{Address: "walletPaid", Network: "mainnet", AssetType: "sol", Status: "active", Tags: []string{}},
+	}
+	diff := diffWallets(source, nil)
+	applyWalletDiff(context.Background(), client.NewClient(server.URL, nil, nil), diff)
+
+	assert.Equal(t, []walletKey{{Address: "walletA", Network: "mainnet", AssetType: "sol"}}, diff.Applied)
+	require.Len(t, diff.Failed, 1)
+	assert.Equal(t, "walletPaid", diff.Failed[0].Address)
+	assert.Contains(t, diff.Failed[0].Error, "payment required")
+
+	require.Len(t, registered, 1)
+	assert.Equal(t, []interface{}{"customer:acme"}, registered[0]["tags"])
+	assert.Equal(t, map[string]interface{}{"order": float64(1)}, registered[0]["metadata"])
+}
+
 func createTestApp() *cli.App {
 	app := &cli.App{
 		Name:  "forohtoo",
@@ -318,6 +389,7 @@ func createTestApp() *cli.App {
 				Aliases: []string{"j"},
 				Usage:   "Output in JSON format",
 			},
+			outputFlag(),
 		},
 	}
 	return app
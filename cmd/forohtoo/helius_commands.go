@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"os/signal"
 	"sort"
+	"syscall"
+	"time"
 
 	"github.com/brojonat/forohtoo/service/helius"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
 	"github.com/urfave/cli/v2"
 )
 
@@ -38,6 +44,7 @@ func heliusCommands() *cli.Command {
 			heliusShowCommand(),
 			heliusDiffCommand(),
 			heliusSyncCommand(),
+			heliusMonitorDriftCommand(),
 		},
 	}
 }
@@ -76,23 +83,22 @@ func heliusListCommand() *cli.Command {
 			if err != nil {
 				return fmt.Errorf("failed to list webhooks: %w", err)
 			}
-			if c.Bool("json") {
-				return outputJSON(webhooks)
-			}
-			if len(webhooks) == 0 {
-				fmt.Fprintln(os.Stderr, "no webhooks configured")
+			return render(c, formatTable, webhooks, func(w io.Writer) error {
+				if len(webhooks) == 0 {
+					fmt.Fprintln(os.Stderr, "no webhooks configured")
+					return nil
+				}
+				for _, wh := range webhooks {
+					fmt.Fprintf(w, "WebhookID:   %s\n", wh.WebhookID)
+					fmt.Fprintf(w, "WebhookURL:  %s\n", wh.WebhookURL)
+					fmt.Fprintf(w, "Type:        %s\n", wh.WebhookType)
+					fmt.Fprintf(w, "TxnTypes:    %v\n", wh.TransactionTypes)
+					fmt.Fprintf(w, "Addresses:   %d\n", len(wh.AccountAddresses))
+					fmt.Fprintln(w, "---")
+				}
+				fmt.Fprintf(os.Stderr, "Total: %d webhook(s)\n", len(webhooks))
 				return nil
-			}
-			for _, wh := range webhooks {
-				fmt.Printf("WebhookID:   %s\n", wh.WebhookID)
-				fmt.Printf("WebhookURL:  %s\n", wh.WebhookURL)
-				fmt.Printf("Type:        %s\n", wh.WebhookType)
-				fmt.Printf("TxnTypes:    %v\n", wh.TransactionTypes)
-				fmt.Printf("Addresses:   %d\n", len(wh.AccountAddresses))
-				fmt.Println("---")
-			}
-			fmt.Fprintf(os.Stderr, "Total: %d webhook(s)\n", len(webhooks))
-			return nil
+			})
 		},
 	}
 }
@@ -136,20 +142,19 @@ This is the same webhook the server manages.`,
 			if err != nil {
 				return fmt.Errorf("failed to get webhook: %w", err)
 			}
-			if c.Bool("json") {
-				return outputJSON(wh)
-			}
-			fmt.Printf("WebhookID:   %s\n", wh.WebhookID)
-			fmt.Printf("WebhookURL:  %s\n", wh.WebhookURL)
-			fmt.Printf("Type:        %s\n", wh.WebhookType)
-			fmt.Printf("TxnTypes:    %v\n", wh.TransactionTypes)
-			fmt.Printf("Addresses:   %d\n", len(wh.AccountAddresses))
-			sorted := append([]string(nil), wh.AccountAddresses...)
-			sort.Strings(sorted)
-			for _, addr := range sorted {
-				fmt.Println(addr)
-			}
-			return nil
+			return render(c, formatTable, wh, func(w io.Writer) error {
+				fmt.Fprintf(w, "WebhookID:   %s\n", wh.WebhookID)
+				fmt.Fprintf(w, "WebhookURL:  %s\n", wh.WebhookURL)
+				fmt.Fprintf(w, "Type:        %s\n", wh.WebhookType)
+				fmt.Fprintf(w, "TxnTypes:    %v\n", wh.TransactionTypes)
+				fmt.Fprintf(w, "Addresses:   %d\n", len(wh.AccountAddresses))
+				sorted := append([]string(nil), wh.AccountAddresses...)
+				sort.Strings(sorted)
+				for _, addr := range sorted {
+					fmt.Fprintln(w, addr)
+				}
+				return nil
+			})
 		},
 	}
 }
@@ -199,6 +204,47 @@ func resolveOurWebhookID(ctx context.Context, client *helius.Client, webhookURL
 	return "", fmt.Errorf("no webhook found matching URL %q", webhookURL)
 }
 
+// addressDrift is the result of comparing the DB's monitorable addresses
+// against the webhook's address list.
+type addressDrift struct {
+	DBCount   int
+	HookCount int
+	Missing   []string // in the DB, not on the webhook (transactions will be missed)
+	Extra     []string // on the webhook, not in the DB (unused capacity)
+	Matched   []string
+}
+
+// computeAddressDrift diffs the desired (DB) addresses against the webhook's
+// addresses. Duplicates are ignored and each group is sorted.
+func computeAddressDrift(desired []string, webhookAddrs []string) addressDrift {
+	webhookSet := make(map[string]bool, len(webhookAddrs))
+	for _, a := range webhookAddrs {
+		webhookSet[a] = true
+	}
+	dbSet := make(map[string]bool, len(desired))
+	for _, a := range desired {
+		dbSet[a] = true
+	}
+
+	drift := addressDrift{DBCount: len(dbSet), HookCount: len(webhookSet)}
+	for a := range dbSet {
+		if webhookSet[a] {
+			drift.Matched = append(drift.Matched, a)
+		} else {
+			drift.Missing = append(drift.Missing, a)
+		}
+	}
+	for a := range webhookSet {
+		if !dbSet[a] {
+			drift.Extra = append(drift.Extra, a)
+		}
+	}
+	sort.Strings(drift.Missing)
+	sort.Strings(drift.Extra)
+	sort.Strings(drift.Matched)
+	return drift
+}
+
 func heliusDiffCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "diff",
@@ -229,69 +275,47 @@ Exits non-zero if there are missing addresses (so it's safe to use as a deploy p
 				return err
 			}
 
-			webhookSet := make(map[string]bool, len(wh.AccountAddresses))
-			for _, a := range wh.AccountAddresses {
-				webhookSet[a] = true
-			}
-			dbSet := make(map[string]bool, len(desired))
-			for _, a := range desired {
-				dbSet[a] = true
-			}
+			drift := computeAddressDrift(desired, wh.AccountAddresses)
+			missing, extra, matched := drift.Missing, drift.Extra, drift.Matched
 
-			var missing, extra, matched []string
-			for a := range dbSet {
-				if webhookSet[a] {
-					matched = append(matched, a)
-				} else {
-					missing = append(missing, a)
-				}
-			}
-			for a := range webhookSet {
-				if !dbSet[a] {
-					extra = append(extra, a)
-				}
-			}
-			sort.Strings(missing)
-			sort.Strings(extra)
-			sort.Strings(matched)
-
-			if c.Bool("json") {
-				return outputJSON(map[string]interface{}{
-					"webhook_id": webhookID,
-					"db_count":   len(dbSet),
-					"hook_count": len(webhookSet),
-					"matched":    matched,
-					"missing":    missing,
-					"extra":      extra,
-				})
+			result := map[string]interface{}{
+				"webhook_id": webhookID,
+				"db_count":   drift.DBCount,
+				"hook_count": drift.HookCount,
+				"matched":    matched,
+				"missing":    missing,
+				"extra":      extra,
 			}
+			// Structured output reports the drift without failing; only the
+			// table view doubles as a deploy precondition.
+			return render(c, formatTable, result, func(w io.Writer) error {
+				fmt.Fprintf(os.Stderr, "webhook:    %s (%s)\n", webhookID, wh.WebhookURL)
+				fmt.Fprintf(os.Stderr, "db active:  %d wallet(s) -> monitorable addresses\n", drift.DBCount)
+				fmt.Fprintf(os.Stderr, "on webhook: %d address(es)\n", drift.HookCount)
+				fmt.Fprintf(os.Stderr, "matched:    %d\n", len(matched))
+				fmt.Fprintf(os.Stderr, "missing:    %d  (in DB, NOT on webhook)\n", len(missing))
+				fmt.Fprintf(os.Stderr, "extra:      %d  (on webhook, NOT in DB)\n\n", len(extra))
 
-			fmt.Fprintf(os.Stderr, "webhook:    %s (%s)\n", webhookID, wh.WebhookURL)
-			fmt.Fprintf(os.Stderr, "db active:  %d wallet(s) -> monitorable addresses\n", len(dbSet))
-			fmt.Fprintf(os.Stderr, "on webhook: %d address(es)\n", len(webhookSet))
-			fmt.Fprintf(os.Stderr, "matched:    %d\n", len(matched))
-			fmt.Fprintf(os.Stderr, "missing:    %d  (in DB, NOT on webhook)\n", len(missing))
-			fmt.Fprintf(os.Stderr, "extra:      %d  (on webhook, NOT in DB)\n\n", len(extra))
-
-			if len(missing) > 0 {
-				fmt.Fprintln(os.Stderr, "MISSING (webhook will not deliver these):")
-				for _, a := range missing {
-					fmt.Println(a)
+				if len(missing) > 0 {
+					fmt.Fprintln(os.Stderr, "MISSING (webhook will not deliver these):")
+					for _, a := range missing {
+						fmt.Fprintln(w, a)
+					}
+					fmt.Fprintln(os.Stderr)
 				}
-				fmt.Fprintln(os.Stderr)
-			}
-			if len(extra) > 0 {
-				fmt.Fprintln(os.Stderr, "EXTRA (webhook monitors but DB doesn't care):")
-				for _, a := range extra {
-					fmt.Fprintln(os.Stderr, "  "+a)
+				if len(extra) > 0 {
+					fmt.Fprintln(os.Stderr, "EXTRA (webhook monitors but DB doesn't care):")
+					for _, a := range extra {
+						fmt.Fprintln(os.Stderr, "  "+a)
+					}
 				}
-			}
 
-			if len(missing) > 0 {
-				return cli.Exit(fmt.Sprintf("%d address(es) missing from webhook — run 'forohtoo helius sync' or restart the server", len(missing)), 1)
-			}
-			fmt.Fprintln(os.Stderr, "OK: webhook is in sync with DB")
-			return nil
+				if len(missing) > 0 {
+					return cli.Exit(fmt.Sprintf("%d address(es) missing from webhook — run 'forohtoo helius sync' or restart the server", len(missing)), 1)
+				}
+				fmt.Fprintln(os.Stderr, "OK: webhook is in sync with DB")
+				return nil
+			})
 		},
 	}
 }
@@ -350,3 +374,110 @@ func heliusSyncCommand() *cli.Command {
 		},
 	}
 }
+
+func heliusMonitorDriftCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "monitor-drift",
+		Usage: "Repeatedly diff DB wallets against the Helius webhook and report drift (read-only)",
+		Description: `Runs the same comparison as 'helius diff' every --interval and reports the
+number of missing and extra addresses. Never modifies the webhook.
+
+With --json (or --output json), each iteration is written to stdout as a single JSON line so the
+output can be piped into a log pipeline. With --pushgateway, the counts are
+also pushed as the forohtoo_helius_drift_addresses gauge.`,
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "interval",
+				Value: 5 * time.Minute,
+				Usage: "Time between drift checks",
+			},
+			&cli.StringFlag{
+				Name:    "pushgateway",
+				EnvVars: []string{"PUSHGATEWAY_URL"},
+				Usage:   "Prometheus Pushgateway URL to push drift gauges to (optional)",
+			},
+			&cli.IntFlag{
+				Name:  "count",
+				Usage: "Stop after this many iterations (0 = run until interrupted)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			interval := c.Duration("interval")
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			jsonOutput, err := streamJSON(c)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			client, err := heliusClientFromCtx(c, false)
+			if err != nil {
+				return err
+			}
+			webhookID, err := resolveOurWebhookID(ctx, client, c.String("helius-webhook-url"))
+			if err != nil {
+				return err
+			}
+
+			driftGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "forohtoo_helius_drift_addresses",
+				Help: "Addresses that differ between the DB and the Helius webhook",
+			}, []string{"kind"})
+			var pusher *push.Pusher
+			if url := c.String("pushgateway"); url != "" {
+				pusher = push.New(url, "forohtoo_helius_drift").Collector(driftGauge)
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for i := 1; ; i++ {
+				checkedAt := time.Now().UTC()
+				wh, err := client.GetWebhook(ctx, webhookID)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%s drift check failed: %v\n", checkedAt.Format(time.RFC3339), err)
+				} else if desired, err := desiredAddressesFromDB(c); err != nil {
+					fmt.Fprintf(os.Stderr, "%s drift check failed: %v\n", checkedAt.Format(time.RFC3339), err)
+				} else {
+					drift := computeAddressDrift(desired, wh.AccountAddresses)
+
+					if jsonOutput {
+						data, _ := json.Marshal(map[string]interface{}{
+							"checked_at": checkedAt,
+							"webhook_id": webhookID,
+							"db_count":   drift.DBCount,
+							"hook_count": drift.HookCount,
+							"missing":    len(drift.Missing),
+							"extra":      len(drift.Extra),
+						})
+						fmt.Println(string(data))
+					} else {
+						fmt.Printf("%s missing=%d extra=%d matched=%d\n",
+							checkedAt.Format(time.RFC3339), len(drift.Missing), len(drift.Extra), len(drift.Matched))
+					}
+
+					if pusher != nil {
+						driftGauge.WithLabelValues("missing").Set(float64(len(drift.Missing)))
+						driftGauge.WithLabelValues("extra").Set(float64(len(drift.Extra)))
+						if err := pusher.PushContext(ctx); err != nil {
+							fmt.Fprintf(os.Stderr, "failed to push to pushgateway: %v\n", err)
+						}
+					}
+				}
+
+				if n := c.Int("count"); n > 0 && i >= n {
+					return nil
+				}
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeAddressDrift(t *testing.T) {
+	desired := []string{"b", "a", "c", "a"}
+	webhook := []string{"c", "d", "a"}
+
+	drift := computeAddressDrift(desired, webhook)
+
+	assert.Equal(t, 3, drift.DBCount, "duplicates should be ignored")
+	assert.Equal(t, 3, drift.HookCount)
+	assert.Equal(t, []string{"b"}, drift.Missing)
+	assert.Equal(t, []string{"d"}, drift.Extra)
+	assert.Equal(t, []string{"a", "c"}, drift.Matched)
+}
+
+func TestComputeAddressDrift_InSync(t *testing.T) {
+	drift := computeAddressDrift([]string{"a", "b"}, []string{"b", "a"})
+
+	assert.Empty(t, drift.Missing)
+	assert.Empty(t, drift.Extra)
+	assert.Len(t, drift.Matched, 2)
+}
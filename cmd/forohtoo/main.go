@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/brojonat/forohtoo/client"
 	"github.com/urfave/cli/v2"
 )
 
@@ -31,6 +32,8 @@ Use this CLI to inspect database state and stream transactions.`,
 				Subcommands: []*cli.Command{
 					listWalletsCommand(),
 					getWalletCommand(),
+					purgeWalletsCommand(),
+					diffWalletsCommand(),
 					listTransactionsCommand(),
 				},
 			},
@@ -50,13 +53,17 @@ Use this CLI to inspect database state and stream transactions.`,
 			walletCommands(),
 			// Helius webhook management commands
 			heliusCommands(),
+			// Supported-mints registry commands
+			mintCommands(),
 			// Server utility commands
 			{
 				Name:  "server",
 				Usage: "Server utility commands",
 				Subcommands: []*cli.Command{
 					healthCommand(),
+					rpcCheckCommand(),
 					versionCommand(),
+					createAPIKeyCommand(),
 				},
 			},
 		},
@@ -73,6 +80,11 @@ Use this CLI to inspect database state and stream transactions.`,
 				EnvVars: []string{"SERVER_URL"},
 				Value:   "http://localhost:8080",
 			},
+			&cli.StringFlag{
+				Name:    "api-key",
+				Usage:   "API key for servers that set API_KEY, sent as a bearer token",
+				EnvVars: []string{"FOROHTOO_API_KEY"},
+			},
 			&cli.StringFlag{
 				Name:    "nats-url",
 				Usage:   "NATS server URL",
@@ -82,8 +94,9 @@ Use this CLI to inspect database state and stream transactions.`,
 			&cli.BoolFlag{
 				Name:    "json",
 				Aliases: []string{"j"},
-				Usage:   "Output in JSON format",
+				Usage:   "Output in JSON format (same as --output json)",
 			},
+			outputFlag(),
 		},
 	}
 
@@ -91,3 +104,9 @@ Use this CLI to inspect database state and stream transactions.`,
 		log.Fatal(err)
 	}
 }
+
+// apiKeyOption passes the global --api-key to a forohtoo client. An empty key
+// sends no Authorization header.
+func apiKeyOption(c *cli.Context) client.Option {
+	return client.WithAPIKey(c.String("api-key"))
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/brojonat/forohtoo/client"
+	"github.com/urfave/cli/v2"
+)
+
+func mintCommands() *cli.Command {
+	return &cli.Command{
+		Name:  "mints",
+		Usage: "Manage the SPL token mints wallets may register for",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "server",
+				Aliases: []string{"s"},
+				Value:   "https://forohtoo.brojonat.com",
+				Usage:   "HTTP server URL",
+				EnvVars: []string{"FOROHTOO_SERVER_URL"},
+			},
+		},
+		Subcommands: []*cli.Command{
+			mintListCommand(),
+			mintAddCommand(),
+			mintRemoveCommand(),
+		},
+	}
+}
+
+// mintClientFromCtx constructs a forohtoo client for the mints subcommands.
+func mintClientFromCtx(c *cli.Context) *client.Client {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	return client.NewClient(c.String("server"), nil, logger, apiKeyOption(c))
+}
+
+func mintListCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "list",
+		Aliases: []string{"ls"},
+		Usage:   "List supported mints (config-defined and operator-added)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "network",
+				Aliases: []string{"n"},
+				Usage:   "Only list mints for this network (mainnet or devnet)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			network := c.String("network")
+			if network != "" && network != "mainnet" && network != "devnet" {
+				return fmt.Errorf("invalid network: must be 'mainnet' or 'devnet'")
+			}
+
+			mints, err := mintClientFromCtx(c).ListSupportedMints(context.Background(), network)
+			if err != nil {
+				return fmt.Errorf("failed to list supported mints: %w", err)
+			}
+
+			return render(c, formatTable, mints, func(out io.Writer) error {
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "NETWORK\tMINT\tSYMBOL\tDECIMALS\tSOURCE\tADDED")
+				for _, m := range mints {
+					source := "api"
+					if m.Configured {
+						source = "config"
+					}
+					added := "-"
+					if m.CreatedAt != nil {
+						added = m.CreatedAt.Format(time.RFC3339)
+					}
+					symbol, decimals := "-", "-"
+					if m.Symbol != nil {
+						symbol = *m.Symbol
+					}
+					if m.Decimals != nil {
+						decimals = fmt.Sprint(*m.Decimals)
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", m.Network, m.Mint, symbol, decimals, source, added)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(os.Stderr, "\nTotal: %d mints\n", len(mints))
+				return nil
+			})
+		},
+	}
+}
+
+func mintAddCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Allow wallets to register for a token mint",
+		ArgsUsage: "MINT_ADDRESS",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "network",
+				Aliases:  []string{"n"},
+				Usage:    "Network (mainnet or devnet)",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return fmt.Errorf("mint address is required")
+			}
+			mint := c.Args().Get(0)
+			network := c.String("network")
+			if network != "mainnet" && network != "devnet" {
+				return fmt.Errorf("invalid network: must be 'mainnet' or 'devnet'")
+			}
+
+			m, err := mintClientFromCtx(c).AddSupportedMint(context.Background(), network, mint)
+			if err != nil {
+				return fmt.Errorf("failed to add supported mint: %w", err)
+			}
+
+			return render(c, formatTable, m, func(w io.Writer) error {
+				fmt.Fprintf(w, "✓ Mint %s is now supported on %s\n", m.Mint, m.Network)
+				return nil
+			})
+		},
+	}
+}
+
+func mintRemoveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "remove",
+		Aliases:   []string{"rm"},
+		Usage:     "Stop accepting new registrations for a token mint",
+		ArgsUsage: "MINT_ADDRESS",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "network",
+				Aliases:  []string{"n"},
+				Usage:    "Network (mainnet or devnet)",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return fmt.Errorf("mint address is required")
+			}
+			mint := c.Args().Get(0)
+			network := c.String("network")
+			if network != "mainnet" && network != "devnet" {
+				return fmt.Errorf("invalid network: must be 'mainnet' or 'devnet'")
+			}
+
+			if err := mintClientFromCtx(c).RemoveSupportedMint(context.Background(), network, mint); err != nil {
+				return fmt.Errorf("failed to remove supported mint: %w", err)
+			}
+
+			result := map[string]string{
+				"network": network,
+				"mint":    mint,
+				"status":  "removed",
+			}
+			return render(c, formatTable, result, func(w io.Writer) error {
+				fmt.Fprintf(w, "✓ Mint %s removed from %s\n", mint, network)
+				return nil
+			})
+		},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
@@ -55,7 +56,10 @@ Example:
 			natsURL := c.String("nats-url")
 			durable := c.Bool("durable")
 			consumerName := c.String("consumer-name")
-			jsonOutput := c.Bool("json")
+			jsonOutput, err := streamJSON(c)
+			if err != nil {
+				return err
+			}
 
 			return streamTransactions(address, natsURL, durable, consumerName, jsonOutput)
 		},
@@ -98,7 +102,10 @@ Example:
 			address := c.String("wallet")
 			natsURL := c.String("nats-url")
 			timeout := c.Duration("timeout")
-			jsonOutput := c.Bool("json")
+			jsonOutput, err := streamJSON(c)
+			if err != nil {
+				return err
+			}
 
 			if !jsonOutput {
 				fmt.Printf("🧪 Smoke test starting...\n")
@@ -169,7 +176,7 @@ Example:
 						fmt.Printf("   Amount: %d lamports\n", event.Amount)
 						fmt.Printf("   Slot: %d\n", event.Slot)
 						if event.Memo != "" {
-							fmt.Printf("   Memo: %s\n", event.Memo)
+							fmt.Printf("   Memo: %s%s\n", event.Memo, truncatedSuffix(event.MemoTruncated))
 						}
 						fmt.Printf("   Published: %s\n\n", event.PublishedAt.Format(time.RFC3339))
 					}
@@ -285,7 +292,7 @@ func streamTransactions(address, natsURL string, durable bool, consumerName stri
 					fmt.Printf("Token:        %s\n", event.TokenType)
 				}
 				if event.Memo != "" {
-					fmt.Printf("Memo:         %s\n", event.Memo)
+					fmt.Printf("Memo:         %s%s\n", event.Memo, truncatedSuffix(event.MemoTruncated))
 				}
 				fmt.Printf("Published:    %s\n", event.PublishedAt.Format(time.RFC3339))
 				fmt.Printf("\n")
@@ -326,7 +333,6 @@ Example:
 		},
 		Action: func(c *cli.Context) error {
 			natsURL := c.String("nats-url")
-			jsonOutput := c.Bool("json")
 
 			// Connect to NATS
 			nc, err := nats.Connect(natsURL)
@@ -351,25 +357,21 @@ Example:
 				return fmt.Errorf("failed to get stream info: %w", err)
 			}
 
-			if jsonOutput {
-				data, _ := json.MarshalIndent(info, "", "  ")
-				fmt.Println(string(data))
-			} else {
-				fmt.Printf("Stream: %s\n", info.Config.Name)
-				fmt.Printf("─────────────────────────────────────────────────────\n")
-				fmt.Printf("Description:  %s\n", info.Config.Description)
-				fmt.Printf("Subjects:     %v\n", info.Config.Subjects)
-				fmt.Printf("Messages:     %d\n", info.State.Msgs)
-				fmt.Printf("Bytes:        %d\n", info.State.Bytes)
-				fmt.Printf("First Seq:    %d\n", info.State.FirstSeq)
-				fmt.Printf("Last Seq:     %d\n", info.State.LastSeq)
-				fmt.Printf("Consumers:    %d\n", info.State.Consumers)
-				fmt.Printf("Max Age:      %s\n", info.Config.MaxAge)
-				fmt.Printf("Storage:      %s\n", info.Config.Storage)
-				fmt.Printf("\n")
-			}
-
-			return nil
+			return render(c, formatTable, info, func(w io.Writer) error {
+				fmt.Fprintf(w, "Stream: %s\n", info.Config.Name)
+				fmt.Fprintf(w, "─────────────────────────────────────────────────────\n")
+				fmt.Fprintf(w, "Description:  %s\n", info.Config.Description)
+				fmt.Fprintf(w, "Subjects:     %v\n", info.Config.Subjects)
+				fmt.Fprintf(w, "Messages:     %d\n", info.State.Msgs)
+				fmt.Fprintf(w, "Bytes:        %d\n", info.State.Bytes)
+				fmt.Fprintf(w, "First Seq:    %d\n", info.State.FirstSeq)
+				fmt.Fprintf(w, "Last Seq:     %d\n", info.State.LastSeq)
+				fmt.Fprintf(w, "Consumers:    %d\n", info.State.Consumers)
+				fmt.Fprintf(w, "Max Age:      %s\n", info.Config.MaxAge)
+				fmt.Fprintf(w, "Storage:      %s\n", info.Config.Storage)
+				fmt.Fprintf(w, "\n")
+				return nil
+			})
 		},
 	}
 }
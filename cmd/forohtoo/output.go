@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output.
+const (
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatTable = "table"
+)
+
+// outputFlag is the --output flag. It is registered globally on the app and
+// again on commands that declare their own flags (e.g. the wallet commands),
+// so it works on either side of the subcommand name.
+func outputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "output",
+		Usage:   "Output format: json, yaml or table (default depends on the command)",
+		EnvVars: []string{"FOROHTOO_OUTPUT"},
+	}
+}
+
+// outputFormat resolves the output format for a command. An explicit
+// --output (on the command or globally) wins, then the legacy --json flag,
+// then the command's default.
+func outputFormat(c *cli.Context, def string) (string, error) {
+	for _, ctx := range c.Lineage() {
+		if ctx.IsSet("output") {
+			format := strings.ToLower(ctx.String("output"))
+			switch format {
+			case formatJSON, formatYAML, formatTable:
+				return format, nil
+			}
+			return "", fmt.Errorf("invalid --output %q: must be json, yaml or table", ctx.String("output"))
+		}
+	}
+	if c.Bool("json") {
+		return formatJSON, nil
+	}
+	return def, nil
+}
+
+// render writes v to stdout in the format selected for the command. JSON and
+// YAML encode v itself; table calls table, which prints the human-readable
+// view. Summaries that aren't part of the data (totals, hints) belong on
+// stderr so they don't pollute piped output in any format.
+func render(c *cli.Context, def string, v any, table func(w io.Writer) error) error {
+	format, err := outputFormat(c, def)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case formatJSON:
+		return outputJSON(v)
+	case formatYAML:
+		return outputYAML(v)
+	default:
+		return table(os.Stdout)
+	}
+}
+
+// streamJSON reports whether a streaming command should emit one JSON object
+// per line. Streams have no YAML form; they either print JSON lines or text.
+func streamJSON(c *cli.Context) (bool, error) {
+	format, err := outputFormat(c, formatTable)
+	if err != nil {
+		return false, err
+	}
+	if format == formatYAML {
+		return false, fmt.Errorf("--output yaml is not supported for streaming commands; use json or table")
+	}
+	return format == formatJSON, nil
+}
+
+// outputJSON writes v to stdout as indented JSON.
+func outputJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// outputYAML writes v to stdout as YAML. v is encoded through JSON first so
+// the YAML uses the same field names (and order) as --output json, rather than
+// the Go field names the YAML encoder would otherwise pick.
+func outputYAML(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	// JSON is valid YAML, so decoding it into a node keeps key order and
+	// scalar types; clearing the styles switches it from flow to block form.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	clearYAMLStyle(&node)
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return enc.Close()
+}
+
+func clearYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		clearYAMLStyle(child)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// runRender runs a one-command app with a global --output/--json and the
+// same flags on the command, returning what render wrote to stdout.
+func runRender(t *testing.T, def string, v any, args ...string) (string, error) {
+	t.Helper()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	app := &cli.App{
+		Name: "forohtoo",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "json"},
+			outputFlag(),
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "show",
+				Flags: []cli.Flag{outputFlag()},
+				Action: func(c *cli.Context) error {
+					return render(c, def, v, func(w io.Writer) error {
+						_, err := io.WriteString(w, "TABLE\n")
+						return err
+					})
+				},
+			},
+		},
+	}
+	err := app.Run(append([]string{"forohtoo"}, args...))
+
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	return buf.String(), err
+}
+
+func TestRender(t *testing.T) {
+	os.Unsetenv("FOROHTOO_OUTPUT")
+	memo := "123"
+	v := map[string]any{"address": "wallet1", "amount": 1000, "memo": &memo, "tags": []string{"a", "b"}}
+
+	tests := []struct {
+		name string
+		def  string
+		args []string
+		want string
+	}{
+		{"default table", formatTable, []string{"show"}, "TABLE\n"},
+		{"default json", formatJSON, []string{"show"}, "{\n  \"address\": \"wallet1\",\n  \"amount\": 1000,\n  \"memo\": \"123\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n"},
+		{"command flag", formatJSON, []string{"show", "--output", "table"}, "TABLE\n"},
+		{"global flag", formatTable, []string{"--output", "yaml", "show"}, "address: wallet1\namount: 1000\nmemo: \"123\"\ntags:\n  - a\n  - b\n"},
+		{"command flag beats global", formatTable, []string{"--output", "json", "show", "--output", "table"}, "TABLE\n"},
+		{"legacy json flag", formatTable, []string{"--json", "show"}, "{\n  \"address\": \"wallet1\",\n  \"amount\": 1000,\n  \"memo\": \"123\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n"},
+		{"output beats legacy json flag", formatTable, []string{"--json", "show", "--output", "table"}, "TABLE\n"},
+		{"case insensitive", formatJSON, []string{"show", "--output", "TABLE"}, "TABLE\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runRender(t, tt.def, v, tt.args...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := runRender(t, formatTable, v, "show", "--output", "xml")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid --output "xml"`)
+	})
+
+	t.Run("env var", func(t *testing.T) {
+		t.Setenv("FOROHTOO_OUTPUT", "yaml")
+		got, err := runRender(t, formatTable, map[string]string{"status": "ok"}, "show")
+		require.NoError(t, err)
+		assert.Equal(t, "status: ok\n", got)
+	})
+}
+
+func TestOutputYAML_UsesJSONFieldNames(t *testing.T) {
+	type wallet struct {
+		Address   string   `json:"address"`
+		TokenMint string   `json:"token_mint,omitempty"`
+		Tags      []string `json:"tags"`
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := outputYAML([]wallet{{Address: "wallet1", Tags: []string{}}, {Address: "wallet2", TokenMint: "mint", Tags: []string{"x"}}})
+	w.Close()
+	os.Stdout = oldStdout
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	assert.Equal(t, "- address: wallet1\n  tags: []\n- address: wallet2\n  token_mint: mint\n  tags:\n    - x\n", buf.String())
+}
+
+func TestStreamJSON(t *testing.T) {
+	os.Unsetenv("FOROHTOO_OUTPUT")
+	run := func(args ...string) (bool, error) {
+		var got bool
+		app := &cli.App{
+			Name:  "forohtoo",
+			Flags: []cli.Flag{&cli.BoolFlag{Name: "json"}, outputFlag()},
+			Action: func(c *cli.Context) error {
+				var err error
+				got, err = streamJSON(c)
+				return err
+			},
+		}
+		err := app.Run(append([]string{"forohtoo"}, args...))
+		return got, err
+	}
+
+	got, err := run()
+	require.NoError(t, err)
+	assert.False(t, got)
+
+	got, err = run("--json")
+	require.NoError(t, err)
+	assert.True(t, got)
+
+	got, err = run("--output", "json")
+	require.NoError(t, err)
+	assert.True(t, got)
+
+	_, err = run("--output", "yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported for streaming")
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/brojonat/forohtoo/client"
+	"github.com/urfave/cli/v2"
+)
+
+// registrationStageLabels are the human-readable forms of registrationStage.
+var registrationStageLabels = map[string]string{
+	client.RegistrationStageAwaitingPayment: "awaiting payment",
+	client.RegistrationStageRegistering:     "payment detected, registering",
+	client.RegistrationExpired:              "invoice expired, a late payment is still accepted",
+	client.RegistrationCompleted:            "completed",
+	client.RegistrationFailed:               "failed",
+}
+
+func walletWatchRegistrationCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "watch-registration",
+		Usage:     "Follow a payment-gated registration until it completes or fails",
+		ArgsUsage: "WORKFLOW_ID",
+		Description: `Polls the registration status for the workflow_id returned with a 402
+Payment Required invoice and shows a live status line: awaiting payment,
+payment detected, completed or failed, with the elapsed time and the memo the
+payment must carry. On completion it prints the payment signature and an
+explorer link. Exits non-zero if the registration fails. With --json, prints
+the status as one JSON object per line each time the stage changes.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "server",
+				Aliases: []string{"s"},
+				Value:   "https://forohtoo.brojonat.com",
+				Usage:   "HTTP server URL",
+				EnvVars: []string{"FOROHTOO_SERVER_URL"},
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Value: 2 * time.Second,
+				Usage: "How often to check the status",
+			},
+			&cli.StringFlag{
+				Name:  "payment-network",
+				Value: "mainnet",
+				Usage: "Network the fee is paid on, for the explorer link (mainnet or devnet)",
+			},
+			&cli.BoolFlag{
+				Name:    "json",
+				Aliases: []string{"j"},
+				Usage:   "Output status changes as JSON lines",
+			},
+			outputFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return fmt.Errorf("workflow ID is required")
+			}
+			workflowID := c.Args().Get(0)
+			interval := c.Duration("interval")
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			paymentNetwork := c.String("payment-network")
+			if paymentNetwork != "mainnet" && paymentNetwork != "devnet" {
+				return fmt.Errorf("invalid --payment-network: must be 'mainnet' or 'devnet'")
+			}
+			jsonOutput, err := streamJSON(c)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			}))
+			cl := client.NewClient(c.String("server"), nil, logger, apiKeyOption(c))
+
+			line := &liveLine{w: os.Stderr, live: isTerminal(os.Stderr)}
+			start := time.Now()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			var lastStage string
+			for first := true; ; first = false {
+				status, err := cl.GetRegistrationStatus(ctx, workflowID)
+				switch {
+				case ctx.Err() != nil:
+					line.finish()
+					return nil
+				case err != nil && first:
+					return fmt.Errorf("failed to get registration status: %w", err)
+				case err != nil:
+					// Keep watching through a transient failure.
+					line.finish()
+					fmt.Fprintf(os.Stderr, "status check failed: %v\n", err)
+				default:
+					stage := registrationStage(status)
+					if jsonOutput {
+						if stage != lastStage {
+							status.Stage = stage
+							data, _ := json.Marshal(status)
+							fmt.Println(string(data))
+						}
+					} else {
+						line.update(stage, registrationStatusLine(status, stage, time.Since(start), time.Now()))
+					}
+					lastStage = stage
+
+					if status.Done() {
+						line.finish()
+						if !jsonOutput {
+							printRegistrationResult(os.Stdout, status, paymentNetwork)
+						}
+						if status.Status == client.RegistrationFailed {
+							return fmt.Errorf("registration failed: %s", status.Error)
+						}
+						return nil
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					line.finish()
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+}
+
+// registrationStage is the stage to show for a registration status. Servers
+// that predate the progress query report no stage, so a pending registration
+// is shown as awaiting payment until it finishes.
+func registrationStage(status *client.RegistrationStatus) string {
+	switch status.Status {
+	case client.RegistrationCompleted, client.RegistrationFailed, client.RegistrationExpired:
+		return status.Status
+	}
+	if status.Stage != "" {
+		return status.Stage
+	}
+	return client.RegistrationStageAwaitingPayment
+}
+
+// registrationStatusLine renders the live status line for a registration
+// that has been watched for elapsed.
+func registrationStatusLine(status *client.RegistrationStatus, stage string, elapsed time.Duration, now time.Time) string {
+	label, ok := registrationStageLabels[stage]
+	if !ok {
+		label = stage
+	}
+	s := fmt.Sprintf("%s (%s elapsed)", label, elapsed.Truncate(time.Second))
+	if stage == client.RegistrationStageAwaitingPayment {
+		if status.PaymentMemo != "" {
+			s += fmt.Sprintf(" · memo %s", status.PaymentMemo)
+		}
+		if status.ExpiresAt != nil {
+			s += fmt.Sprintf(" · expires in %s", status.ExpiresAt.Sub(now).Truncate(time.Second))
+		}
+	}
+	return s
+}
+
+// printRegistrationResult prints the outcome of a finished registration.
+func printRegistrationResult(w io.Writer, status *client.RegistrationStatus, paymentNetwork string) {
+	if status.Status == client.RegistrationFailed {
+		fmt.Fprintf(w, "✗ Registration failed\n")
+		if status.Error != "" {
+			fmt.Fprintf(w, "  Error: %s\n", status.Error)
+		}
+		return
+	}
+	fmt.Fprintf(w, "✓ Registration completed\n")
+	fmt.Fprintf(w, "  Address: %s\n", status.Address)
+	fmt.Fprintf(w, "  Network: %s\n", status.Network)
+	fmt.Fprintf(w, "  Asset Type: %s\n", status.AssetType)
+	if status.TokenMint != "" {
+		fmt.Fprintf(w, "  Token Mint: %s\n", status.TokenMint)
+	}
+	if status.PaymentSignature != "" {
+		fmt.Fprintf(w, "  Payment Signature: %s\n", status.PaymentSignature)
+		fmt.Fprintf(w, "  Explorer: %s\n", explorerTxURL(status.PaymentSignature, paymentNetwork))
+	}
+	if status.RegisteredAt != nil {
+		fmt.Fprintf(w, "  Registered At: %s\n", status.RegisteredAt.Format(time.RFC3339))
+	}
+}
+
+// explorerTxURL links to a transaction on the Solana explorer.
+func explorerTxURL(signature, network string) string {
+	u := "https://explorer.solana.com/tx/" + signature
+	if network == "devnet" {
+		u += "?cluster=devnet"
+	}
+	return u
+}
+
+// liveLine is a status line redrawn in place on a terminal. Elsewhere (a
+// pipe or a log file) a line is printed only when its key changes, so the
+// output isn't flooded with elapsed-time updates.
+type liveLine struct {
+	w       io.Writer
+	live    bool
+	lastKey string
+	drawn   bool
+}
+
+func (l *liveLine) update(key, s string) {
+	if l.live {
+		fmt.Fprintf(l.w, "\r\033[K%s", s)
+		l.drawn = true
+		return
+	}
+	if key != l.lastKey {
+		fmt.Fprintln(l.w, s)
+	}
+	l.lastKey = key
+}
+
+// finish ends a line drawn in place so later output starts on its own line.
+func (l *liveLine) finish() {
+	if l.drawn {
+		fmt.Fprintln(l.w)
+		l.drawn = false
+	}
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrationStage(t *testing.T) {
+	tests := []struct {
+		name   string
+		status client.RegistrationStatus
+		want   string
+	}{
+		{"pending from older server", client.RegistrationStatus{Status: "pending"}, "awaiting_payment"},
+		{"pending with stage", client.RegistrationStatus{Status: "pending", Stage: "registering"}, "registering"},
+		{"expired", client.RegistrationStatus{Status: "expired", Stage: "awaiting_payment"}, "expired"},
+		{"completed", client.RegistrationStatus{Status: "completed"}, "completed"},
+		{"failed", client.RegistrationStatus{Status: "failed"}, "failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, registrationStage(&tt.status))
+		})
+	}
+}
+
+func TestRegistrationStatusLine(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	expires := now.Add(29*time.Minute + 30*time.Second)
+	status := &client.RegistrationStatus{
+		Status:      "pending",
+		PaymentMemo: "forohtoo-reg:inv1",
+		ExpiresAt:   &expires,
+	}
+
+	assert.Equal(t,
+		"awaiting payment (1m5s elapsed) · memo forohtoo-reg:inv1 · expires in 29m30s",
+		registrationStatusLine(status, "awaiting_payment", 65*time.Second+300*time.Millisecond, now))
+	assert.Equal(t,
+		"payment detected, registering (2m0s elapsed)",
+		registrationStatusLine(status, "registering", 2*time.Minute, now))
+}
+
+func TestExplorerTxURL(t *testing.T) {
+	assert.Equal(t, "https://explorer.solana.com/tx/sig1", explorerTxURL("sig1", "mainnet"))
+	assert.Equal(t, "https://explorer.solana.com/tx/sig1?cluster=devnet", explorerTxURL("sig1", "devnet"))
+}
+
+func TestLiveLine_NotTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	line := &liveLine{w: &buf}
+
+	line.update("awaiting_payment", "awaiting payment (1s elapsed)")
+	line.update("awaiting_payment", "awaiting payment (3s elapsed)")
+	line.update("registering", "payment detected, registering (5s elapsed)")
+	line.finish()
+
+	assert.Equal(t, "awaiting payment (1s elapsed)\npayment detected, registering (5s elapsed)\n", buf.String())
+}
+
+func TestLiveLine_Terminal(t *testing.T) {
+	var buf bytes.Buffer
+	line := &liveLine{w: &buf, live: true}
+
+	line.update("awaiting_payment", "a")
+	line.update("awaiting_payment", "b")
+	line.finish()
+
+	assert.Equal(t, "\r\033[Ka\r\033[Kb\n", buf.String())
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"text/tabwriter"
 	"time"
 
+	"github.com/brojonat/forohtoo/service/helius"
 	"github.com/urfave/cli/v2"
 )
 
@@ -36,13 +43,106 @@ func healthCommand() *cli.Command {
 			}
 			defer resp.Body.Close()
 
-			if resp.StatusCode == http.StatusOK {
-				fmt.Printf("✓ Server is healthy (status: %d)\n", resp.StatusCode)
-				fmt.Printf("  URL: %s\n", serverURL)
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("server returned unhealthy status: %d", resp.StatusCode)
+			}
+
+			result := map[string]interface{}{
+				"status":      "healthy",
+				"status_code": resp.StatusCode,
+				"url":         serverURL,
+			}
+			return render(c, formatTable, result, func(w io.Writer) error {
+				fmt.Fprintf(w, "✓ Server is healthy (status: %d)\n", resp.StatusCode)
+				fmt.Fprintf(w, "  URL: %s\n", serverURL)
 				return nil
+			})
+		},
+	}
+}
+
+func rpcCheckCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "rpc-check",
+		Usage: "Check Helius RPC connectivity and latency for each network",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "helius-api-key",
+				Usage:   "Helius API key",
+				EnvVars: []string{"HELIUS_API_KEY"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "network",
+				Aliases: []string{"n"},
+				Usage:   "Network to check (mainnet or devnet); can be repeated",
+				Value:   cli.NewStringSlice("mainnet", "devnet"),
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Timeout per network",
+				Value: 10 * time.Second,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			apiKey := c.String("helius-api-key")
+			if apiKey == "" {
+				return fmt.Errorf("helius-api-key is required (set HELIUS_API_KEY)")
+			}
+			networks := c.StringSlice("network")
+			for _, network := range networks {
+				if network != "mainnet" && network != "devnet" {
+					return fmt.Errorf("invalid network %q: must be 'mainnet' or 'devnet'", network)
+				}
+			}
+
+			hc := helius.NewClient(apiKey, "", "", quietLogger())
+
+			type result struct {
+				Network   string  `json:"network"`
+				OK        bool    `json:"ok"`
+				Slot      uint64  `json:"slot,omitempty"`
+				LatencyMS float64 `json:"latency_ms,omitempty"`
+				Error     string  `json:"error,omitempty"`
+			}
+			results := make([]result, 0, len(networks))
+			failed := 0
+			for _, network := range networks {
+				ctx, cancel := context.WithTimeout(context.Background(), c.Duration("timeout"))
+				health, err := hc.HealthCheck(ctx, network)
+				cancel()
+
+				res := result{Network: network}
+				if err != nil {
+					res.Error = err.Error()
+					failed++
+				} else {
+					res.OK = true
+					res.Slot = health.Slot
+					res.LatencyMS = float64(health.Latency.Microseconds()) / 1000
+				}
+				results = append(results, res)
+			}
+
+			err := render(c, formatTable, results, func(out io.Writer) error {
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "NETWORK\tSTATUS\tSLOT\tLATENCY\tERROR")
+				for _, res := range results {
+					if res.OK {
+						fmt.Fprintf(w, "%s\tok\t%d\t%.1fms\t\n", res.Network, res.Slot, res.LatencyMS)
+					} else {
+						fmt.Fprintf(w, "%s\tfailed\t-\t-\t%s\n", res.Network, res.Error)
+					}
+				}
+				return w.Flush()
+			})
+			if err != nil {
+				return err
 			}
 
-			return fmt.Errorf("server returned unhealthy status: %d", resp.StatusCode)
+			if failed > 0 {
+				return fmt.Errorf("RPC check failed for %d of %d network(s)", failed, len(networks))
+			}
+			return nil
 		},
 	}
 }
@@ -52,11 +152,84 @@ func versionCommand() *cli.Command {
 		Name:  "version",
 		Usage: "Show version information",
 		Action: func(c *cli.Context) error {
-			fmt.Printf("forohtoo CLI\n")
-			fmt.Printf("  Version: %s\n", version)
-			fmt.Printf("  Commit:  %s\n", commit)
-			fmt.Printf("  Built:   %s\n", date)
-			return nil
+			info := map[string]string{
+				"version": version,
+				"commit":  commit,
+				"built":   date,
+			}
+			return render(c, formatTable, info, func(w io.Writer) error {
+				fmt.Fprintf(w, "forohtoo CLI\n")
+				fmt.Fprintf(w, "  Version: %s\n", version)
+				fmt.Fprintf(w, "  Commit:  %s\n", commit)
+				fmt.Fprintf(w, "  Built:   %s\n", date)
+				return nil
+			})
+		},
+	}
+}
+
+// maxAPIKeyNameLen bounds an API key's name, which is recorded as the audit
+// actor for its requests.
+const maxAPIKeyNameLen = 64
+
+func createAPIKeyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "create-api-key",
+		Usage: "Create a named API key and print it once",
+		Description: `Generates a random API key, stores its SHA-256 hash in the api_keys table
+under --name, and prints the key. The key can't be recovered later. The server
+accepts it as "Authorization: Bearer <key>" when API_KEYS_ENABLED=true, and
+audit log entries for its requests name it as "key:<name>".`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "Unique name identifying the key's holder",
+				Required: true,
+			},
 		},
+		Action: func(c *cli.Context) error {
+			name := c.String("name")
+			if name == "" || len(name) > maxAPIKeyNameLen {
+				return fmt.Errorf("--name must be 1 to %d characters", maxAPIKeyNameLen)
+			}
+
+			store, closer, err := getStore(c)
+			if err != nil {
+				return err
+			}
+			defer closer()
+
+			key, err := generateAPIKey()
+			if err != nil {
+				return err
+			}
+			created, err := store.CreateAPIKey(context.Background(), name, key)
+			if err != nil {
+				return fmt.Errorf("failed to create API key: %w", err)
+			}
+
+			result := map[string]interface{}{
+				"id":         created.ID,
+				"name":       created.Name,
+				"key":        key,
+				"created_at": created.CreatedAt,
+			}
+			return render(c, formatTable, result, func(w io.Writer) error {
+				fmt.Fprintf(w, "✓ API key created\n")
+				fmt.Fprintf(w, "  Name: %s\n", created.Name)
+				fmt.Fprintf(w, "  Key:  %s\n", key)
+				fmt.Fprintf(os.Stderr, "\nStore the key now; it can't be shown again.\n")
+				return nil
+			})
+		},
+	}
+}
+
+// generateAPIKey returns a random 256-bit key, hex encoded.
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
 	}
+	return hex.EncodeToString(b), nil
 }
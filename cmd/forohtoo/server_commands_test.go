@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -130,3 +131,76 @@ func TestVersionCommand(t *testing.T) {
 	err := app.Run([]string{"forohtoo", "server", "version"})
 	require.NoError(t, err)
 }
+
+func TestRPCCheckCommand_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing api key", args: []string{"forohtoo", "server", "rpc-check", "--helius-api-key", ""}, wantErr: "helius-api-key is required"},
+		{name: "invalid network", args: []string{"forohtoo", "server", "rpc-check", "--helius-api-key", "key", "--network", "testnet"}, wantErr: "invalid network"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &cli.App{
+				Name: "forohtoo",
+				Commands: []*cli.Command{
+					{
+						Name: "server",
+						Subcommands: []*cli.Command{
+							rpcCheckCommand(),
+						},
+					},
+				},
+			}
+
+			err := app.Run(tt.args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestCreateAPIKeyCommand_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing name", args: []string{"forohtoo", "server", "create-api-key"}, wantErr: "Required flag \"name\" not set"},
+		{name: "name too long", args: []string{"forohtoo", "server", "create-api-key", "--name", strings.Repeat("a", maxAPIKeyNameLen+1)}, wantErr: "--name must be 1 to 64 characters"},
+		{name: "missing database url", args: []string{"forohtoo", "server", "create-api-key", "--name", "billing"}, wantErr: "database-url is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "")
+			app := &cli.App{
+				Name: "forohtoo",
+				Commands: []*cli.Command{
+					{
+						Name: "server",
+						Subcommands: []*cli.Command{
+							createAPIKeyCommand(),
+						},
+					},
+				},
+			}
+
+			err := app.Run(tt.args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	a, err := generateAPIKey()
+	require.NoError(t, err)
+	b, err := generateAPIKey()
+	require.NoError(t, err)
+	assert.Len(t, a, 64)
+	assert.NotEqual(t, a, b)
+}
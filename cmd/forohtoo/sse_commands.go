@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"os/signal"
 	"strings"
@@ -44,11 +45,23 @@ func streamCommand() *cli.Command {
 				Aliases: []string{"j"},
 				Usage:   "Output transactions as JSON (one per line)",
 			},
+			outputFlag(),
+			&cli.StringFlag{
+				Name:  "fields",
+				Usage: "Comma-separated fields to include in each event (e.g. signature,amount); implies --json",
+			},
 		},
 		Action: func(c *cli.Context) error {
-			serverURL := c.String("server")
+			// The server URL may include a base path (e.g. https://host/forohtoo)
+			serverURL := strings.TrimRight(c.String("server"), "/")
 			walletAddress := c.Args().First()
-			jsonOutput := c.Bool("json")
+			fields := c.String("fields")
+			jsonOutput, err := streamJSON(c)
+			if err != nil {
+				return err
+			}
+			// Projected events don't carry every field the text output needs
+			jsonOutput = jsonOutput || fields != ""
 
 			// Build SSE endpoint URL
 			var url string
@@ -57,6 +70,12 @@ func streamCommand() *cli.Command {
 			} else {
 				url = fmt.Sprintf("%s/api/v1/stream/transactions", serverURL)
 			}
+			if fields != "" {
+				if _, err := natspkg.ParseFields(fields); err != nil {
+					return err
+				}
+				url += "?fields=" + neturl.QueryEscape(fields)
+			}
 
 			// Create context that cancels on interrupt
 			ctx, cancel := context.WithCancel(c.Context)
@@ -75,6 +94,9 @@ func streamCommand() *cli.Command {
 				return fmt.Errorf("failed to create request: %w", err)
 			}
 			req.Header.Set("Accept", "text/event-stream")
+			if key := c.String("api-key"); key != "" {
+				req.Header.Set("Authorization", "Bearer "+key)
+			}
 
 			// Make request
 			client := &http.Client{
@@ -174,6 +196,13 @@ func handleSSEEvent(eventType, data string, jsonOutput bool) error {
 		}
 		return nil
 
+	case "reconnect":
+		// The server is draining for a restart; the stream ends after this.
+		if !jsonOutput {
+			fmt.Fprintf(os.Stderr, "\nServer is restarting; reconnect to keep streaming\n")
+		}
+		return nil
+
 	case "error":
 		var errInfo map[string]interface{}
 		if err := json.Unmarshal([]byte(data), &errInfo); err != nil {
@@ -204,7 +233,7 @@ func printTransaction(txn natspkg.TransactionEvent) {
 	}
 
 	if txn.Memo != "" {
-		fmt.Printf("Memo:       %s\n", txn.Memo)
+		fmt.Printf("Memo:       %s%s\n", txn.Memo, truncatedSuffix(txn.MemoTruncated))
 	}
 
 	fmt.Printf("Published:  %s\n", txn.PublishedAt.Format(time.RFC3339))
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/brojonat/forohtoo/client"
 	"github.com/itchyny/gojq"
+	"github.com/mr-tron/base58"
 	"github.com/urfave/cli/v2"
 )
 
@@ -23,7 +28,9 @@ func walletCommands() *cli.Command {
 			walletGetCommand(),
 			walletListCommand(),
 			walletTransactionsCommand(),
+			walletIngestCommand(),
 			awaitCommand(),
+			walletWatchRegistrationCommand(),
 		},
 	}
 }
@@ -57,11 +64,24 @@ func walletAddCommand() *cli.Command {
 				Name:  "token-mint",
 				Usage: "Token mint address (required when --asset=spl-token, e.g., USDC mint). Leave empty for SOL.",
 			},
+			&cli.StringFlag{
+				Name:  "metadata",
+				Usage: "Optional JSON object to attach to the registration (e.g. '{\"order_id\":\"123\"}')",
+			},
+			&cli.StringSliceFlag{
+				Name:  "tag",
+				Usage: "Tag to group the wallet by, as a name or key:value (e.g. customer:acme); can be repeated",
+			},
+			&cli.StringFlag{
+				Name:  "callback-url",
+				Usage: "URL to POST a signed notification to once a payment-gated registration completes",
+			},
 			&cli.BoolFlag{
 				Name:    "json",
 				Aliases: []string{"j"},
 				Usage:   "Output as JSON",
 			},
+			outputFlag(),
 		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() < 1 {
@@ -73,7 +93,7 @@ func walletAddCommand() *cli.Command {
 			network := c.String("network")
 			assetType := c.String("asset")
 			tokenMint := c.String("token-mint")
-			jsonOutput := c.Bool("json")
+			metadata := c.String("metadata")
 
 			// Validate network
 			if network != "mainnet" && network != "devnet" {
@@ -95,36 +115,48 @@ func walletAddCommand() *cli.Command {
 				return fmt.Errorf("--token-mint should not be specified when --asset=sol")
 			}
 
+			var rawMetadata json.RawMessage
+			if metadata != "" {
+				if !json.Valid([]byte(metadata)) {
+					return fmt.Errorf("invalid --metadata: must be a JSON object")
+				}
+				rawMetadata = json.RawMessage(metadata)
+			}
+
 			logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
 				Level: slog.LevelError,
 			}))
 
-			cl := client.NewClient(serverURL, nil, logger)
+			cl := client.NewClient(serverURL, nil, logger, apiKeyOption(c))
 
-			if err := cl.RegisterAsset(context.Background(), address, network, assetType, tokenMint); err != nil {
+			opts := client.RegisterOptions{Metadata: rawMetadata, CallbackURL: c.String("callback-url")}
+			if c.IsSet("tag") {
+				opts.Tags = c.StringSlice("tag")
+			}
+			if err := cl.RegisterAssetWithOptions(context.Background(), address, network, assetType, tokenMint, opts); err != nil {
 				return fmt.Errorf("failed to register wallet asset: %w", err)
 			}
 
-			if jsonOutput {
-				data, _ := json.Marshal(map[string]interface{}{
-					"address":    address,
-					"network":    network,
-					"asset_type": assetType,
-					"token_mint": tokenMint,
-					"status":     "registered",
-				})
-				fmt.Println(string(data))
-			} else {
-				fmt.Printf("✓ Wallet asset registered successfully\n")
-				fmt.Printf("  Address: %s\n", address)
-				fmt.Printf("  Network: %s\n", network)
-				fmt.Printf("  Asset Type: %s\n", assetType)
+			result := map[string]interface{}{
+				"address":    address,
+				"network":    network,
+				"asset_type": assetType,
+				"token_mint": tokenMint,
+				"status":     "registered",
+			}
+			return render(c, formatTable, result, func(w io.Writer) error {
+				fmt.Fprintf(w, "✓ Wallet asset registered successfully\n")
+				fmt.Fprintf(w, "  Address: %s\n", address)
+				fmt.Fprintf(w, "  Network: %s\n", network)
+				fmt.Fprintf(w, "  Asset Type: %s\n", assetType)
 				if tokenMint != "" {
-					fmt.Printf("  Token Mint: %s\n", tokenMint)
+					fmt.Fprintf(w, "  Token Mint: %s\n", tokenMint)
 				}
-			}
-
-			return nil
+				if len(opts.Tags) > 0 {
+					fmt.Fprintf(w, "  Tags: %s\n", strings.Join(opts.Tags, ", "))
+				}
+				return nil
+			})
 		},
 	}
 }
@@ -163,6 +195,7 @@ func walletRemoveCommand() *cli.Command {
 				Aliases: []string{"j"},
 				Usage:   "Output as JSON",
 			},
+			outputFlag(),
 		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() < 1 {
@@ -174,7 +207,6 @@ func walletRemoveCommand() *cli.Command {
 			network := c.String("network")
 			assetType := c.String("asset")
 			tokenMint := c.String("token-mint")
-			jsonOutput := c.Bool("json")
 
 			// Validate network
 			if network != "mainnet" && network != "devnet" {
@@ -195,32 +227,29 @@ func walletRemoveCommand() *cli.Command {
 				Level: slog.LevelError,
 			}))
 
-			cl := client.NewClient(serverURL, nil, logger)
+			cl := client.NewClient(serverURL, nil, logger, apiKeyOption(c))
 
 			if err := cl.UnregisterAsset(context.Background(), address, network, assetType, tokenMint); err != nil {
 				return fmt.Errorf("failed to unregister wallet asset: %w", err)
 			}
 
-			if jsonOutput {
-				data, _ := json.Marshal(map[string]interface{}{
-					"address":    address,
-					"network":    network,
-					"asset_type": assetType,
-					"token_mint": tokenMint,
-					"status":     "unregistered",
-				})
-				fmt.Println(string(data))
-			} else {
-				fmt.Printf("✓ Wallet asset unregistered successfully\n")
-				fmt.Printf("  Address: %s\n", address)
-				fmt.Printf("  Network: %s\n", network)
-				fmt.Printf("  Asset Type: %s\n", assetType)
+			result := map[string]interface{}{
+				"address":    address,
+				"network":    network,
+				"asset_type": assetType,
+				"token_mint": tokenMint,
+				"status":     "unregistered",
+			}
+			return render(c, formatTable, result, func(w io.Writer) error {
+				fmt.Fprintf(w, "✓ Wallet asset unregistered successfully\n")
+				fmt.Fprintf(w, "  Address: %s\n", address)
+				fmt.Fprintf(w, "  Network: %s\n", network)
+				fmt.Fprintf(w, "  Asset Type: %s\n", assetType)
 				if tokenMint != "" {
-					fmt.Printf("  Token Mint: %s\n", tokenMint)
+					fmt.Fprintf(w, "  Token Mint: %s\n", tokenMint)
 				}
-			}
-
-			return nil
+				return nil
+			})
 		},
 	}
 }
@@ -250,6 +279,7 @@ func walletGetCommand() *cli.Command {
 				Aliases: []string{"j"},
 				Usage:   "Output as JSON",
 			},
+			outputFlag(),
 		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() < 1 {
@@ -259,7 +289,6 @@ func walletGetCommand() *cli.Command {
 			address := c.Args().Get(0)
 			serverURL := c.String("server")
 			network := c.String("network")
-			jsonOutput := c.Bool("json")
 
 			// Validate network
 			if network != "mainnet" && network != "devnet" {
@@ -270,33 +299,29 @@ func walletGetCommand() *cli.Command {
 				Level: slog.LevelError,
 			}))
 
-			cl := client.NewClient(serverURL, nil, logger)
+			cl := client.NewClient(serverURL, nil, logger, apiKeyOption(c))
 
 			wallet, err := cl.Get(context.Background(), address, network)
 			if err != nil {
 				return fmt.Errorf("failed to get wallet: %w", err)
 			}
 
-			if jsonOutput {
-				data, _ := json.MarshalIndent(wallet, "", "  ")
-				fmt.Println(string(data))
-			} else {
-				fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-				fmt.Println("Wallet Details")
-				fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-				fmt.Printf("Address:       %s\n", wallet.Address)
-				fmt.Printf("Network:       %s\n", wallet.Network)
-				fmt.Printf("Asset Type:    %s\n", wallet.AssetType)
+			return render(c, formatTable, wallet, func(w io.Writer) error {
+				fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+				fmt.Fprintln(w, "Wallet Details")
+				fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+				fmt.Fprintf(w, "Address:       %s\n", wallet.Address)
+				fmt.Fprintf(w, "Network:       %s\n", wallet.Network)
+				fmt.Fprintf(w, "Asset Type:    %s\n", wallet.AssetType)
 				if wallet.TokenMint != "" {
-					fmt.Printf("Token Mint:    %s\n", wallet.TokenMint)
+					fmt.Fprintf(w, "Token Mint:    %s\n", wallet.TokenMint)
 				}
-				fmt.Printf("Status:        %s\n", wallet.Status)
-				fmt.Printf("Created At:    %s\n", wallet.CreatedAt.Format(time.RFC3339))
-				fmt.Printf("Updated At:    %s\n", wallet.UpdatedAt.Format(time.RFC3339))
-				fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-			}
-
-			return nil
+				fmt.Fprintf(w, "Status:        %s\n", wallet.Status)
+				fmt.Fprintf(w, "Created At:    %s\n", wallet.CreatedAt.Format(time.RFC3339))
+				fmt.Fprintf(w, "Updated At:    %s\n", wallet.UpdatedAt.Format(time.RFC3339))
+				fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+				return nil
+			})
 		},
 	}
 }
@@ -317,48 +342,55 @@ func walletListCommand() *cli.Command {
 			&cli.BoolFlag{
 				Name:    "table",
 				Aliases: []string{"t"},
-				Usage:   "Output as human-readable table instead of JSON",
+				Usage:   "Output as human-readable table instead of JSON (same as --output table)",
+			},
+			outputFlag(),
+			&cli.StringSliceFlag{
+				Name:  "tag",
+				Usage: "Only list wallets with this tag (e.g. customer:acme); repeat to require several",
 			},
 		},
 		Action: func(c *cli.Context) error {
 			serverURL := c.String("server")
-			tableOutput := c.Bool("table")
 
 			logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
 				Level: slog.LevelError,
 			}))
 
-			cl := client.NewClient(serverURL, nil, logger)
+			cl := client.NewClient(serverURL, nil, logger, apiKeyOption(c))
 
-			wallets, err := cl.List(context.Background())
+			wallets, err := cl.ListWithOptions(context.Background(), client.ListOptions{Tags: c.StringSlice("tag")})
 			if err != nil {
 				return fmt.Errorf("failed to list wallets: %w", err)
 			}
 
 			// Default to JSON output
-			if !tableOutput {
-				data, _ := json.MarshalIndent(wallets, "", "  ")
-				fmt.Println(string(data))
-			} else {
-				// Table output
+			def := formatJSON
+			if c.Bool("table") {
+				def = formatTable
+			}
+
+			return render(c, def, wallets, func(out io.Writer) error {
 				if len(wallets) == 0 {
-					fmt.Println("No wallets registered")
+					fmt.Fprintln(out, "No wallets registered")
 					return nil
 				}
 
-				fmt.Printf("Found %d wallet(s):\n\n", len(wallets))
+				fmt.Fprintf(out, "Found %d wallet(s):\n\n", len(wallets))
 				for _, w := range wallets {
-					fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-					fmt.Printf("Address:       %s\n", w.Address)
-					fmt.Printf("Network:       %s\n", w.Network)
-					fmt.Printf("Asset Type:    %s\n", w.AssetType)
-					fmt.Printf("Status:        %s\n", w.Status)
-					fmt.Println()
+					fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+					fmt.Fprintf(out, "Address:       %s\n", w.Address)
+					fmt.Fprintf(out, "Network:       %s\n", w.Network)
+					fmt.Fprintf(out, "Asset Type:    %s\n", w.AssetType)
+					fmt.Fprintf(out, "Status:        %s\n", w.Status)
+					if len(w.Tags) > 0 {
+						fmt.Fprintf(out, "Tags:          %s\n", strings.Join(w.Tags, ", "))
+					}
+					fmt.Fprintln(out)
 				}
-				fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-			}
-
-			return nil
+				fmt.Fprintln(out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+				return nil
+			})
 		},
 	}
 }
@@ -386,10 +418,39 @@ func awaitCommand() *cli.Command {
 				Name:  "signature",
 				Usage: "Filter by exact transaction signature",
 			},
-			&cli.Float64Flag{
+			&cli.StringFlag{
 				Name:  "usdc-amount-equal",
 				Usage: "Filter by exact USDC amount (e.g., 0.42 for 0.42 USDC). Requires USDC_MINT_ADDRESS env var.",
 			},
+			&cli.StringFlag{
+				Name:  "usdc-amount-gte",
+				Usage: "Filter by minimum USDC amount (e.g., 5 for at least 5 USDC); overpayment is accepted. Requires USDC_MINT_ADDRESS env var.",
+			},
+			&cli.StringFlag{
+				Name:  "sol-amount-gte",
+				Usage: "Filter by minimum SOL amount (e.g., 0.1 for at least 0.1 SOL); overpayment is accepted",
+			},
+			&cli.StringFlag{
+				Name:  "memo",
+				Usage: "Filter by transaction memo (see --memo-match)",
+			},
+			&cli.StringFlag{
+				Name:  "memo-match",
+				Value: "exact",
+				Usage: "How --memo is matched: exact, prefix, or contains (for wallets that wrap the memo)",
+			},
+			&cli.StringFlag{
+				Name:  "from-address",
+				Usage: "Only match transactions sent from this wallet address",
+			},
+			&cli.StringFlag{
+				Name:  "block-time-after",
+				Usage: "Only match transactions with a block time at or after this RFC3339 timestamp (e.g., 2025-01-02T15:04:05Z)",
+			},
+			&cli.StringFlag{
+				Name:  "block-time-before",
+				Usage: "Only match transactions with a block time at or before this RFC3339 timestamp",
+			},
 			&cli.StringSliceFlag{
 				Name:    "must-jq",
 				Usage:   "jq filter expression that must evaluate to true (can be specified multiple times, all must match)",
@@ -412,6 +473,7 @@ func awaitCommand() *cli.Command {
 				Aliases: []string{"j"},
 				Usage:   "Output transaction as JSON",
 			},
+			outputFlag(),
 		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() < 1 {
@@ -422,28 +484,70 @@ func awaitCommand() *cli.Command {
 			serverURL := c.String("server")
 			network := c.String("network")
 			signature := c.String("signature")
-			usdcAmount := c.Float64("usdc-amount-equal")
+			usdcAmount, err := parseDecimalAmount(c.String("usdc-amount-equal"), usdcDecimals)
+			if err != nil {
+				return fmt.Errorf("invalid --usdc-amount-equal: %w", err)
+			}
+			usdcMinAmount, err := parseDecimalAmount(c.String("usdc-amount-gte"), usdcDecimals)
+			if err != nil {
+				return fmt.Errorf("invalid --usdc-amount-gte: %w", err)
+			}
+			solMinAmount, err := parseDecimalAmount(c.String("sol-amount-gte"), solDecimals)
+			if err != nil {
+				return fmt.Errorf("invalid --sol-amount-gte: %w", err)
+			}
+			if solMinAmount != 0 && (usdcAmount != 0 || usdcMinAmount != 0) {
+				return fmt.Errorf("--sol-amount-gte can't be combined with a USDC amount filter")
+			}
+			memo := c.String("memo")
+			memoMatch, err := client.ParseMemoMatchMode(c.String("memo-match"))
+			if err != nil {
+				return fmt.Errorf("invalid --memo-match: %w", err)
+			}
+			fromAddress := c.String("from-address")
+			if fromAddress != "" {
+				if err := validateWalletAddress(fromAddress); err != nil {
+					return fmt.Errorf("invalid --from-address: %w", err)
+				}
+			}
 			jqFilters := c.StringSlice("must-jq")
 			timeout := c.Duration("timeout")
 			lookback := c.Duration("lookback")
-			jsonOutput := c.Bool("json")
+			format, err := outputFormat(c, formatTable)
+			if err != nil {
+				return err
+			}
 
 			// Validate network
 			if network != "mainnet" && network != "devnet" {
 				return fmt.Errorf("invalid network: must be 'mainnet' or 'devnet'")
 			}
 
+			// Parse block time window (bounds are inclusive)
+			blockTimeAfter, err := parseBlockTimeFlag(c.String("block-time-after"))
+			if err != nil {
+				return fmt.Errorf("invalid --block-time-after: %w", err)
+			}
+			blockTimeBefore, err := parseBlockTimeFlag(c.String("block-time-before"))
+			if err != nil {
+				return fmt.Errorf("invalid --block-time-before: %w", err)
+			}
+			if !blockTimeAfter.IsZero() && !blockTimeBefore.IsZero() && blockTimeAfter.After(blockTimeBefore) {
+				return fmt.Errorf("--block-time-after must not be later than --block-time-before")
+			}
+			hasWindow := !blockTimeAfter.IsZero() || !blockTimeBefore.IsZero()
+
 			// Require at least one filter
-			if signature == "" && usdcAmount == 0 && len(jqFilters) == 0 {
-				return fmt.Errorf("must specify at least one filter: --signature, --usdc-amount-equal, or --must-jq")
+			if signature == "" && usdcAmount == 0 && usdcMinAmount == 0 && solMinAmount == 0 && memo == "" && fromAddress == "" && len(jqFilters) == 0 && !hasWindow {
+				return fmt.Errorf("must specify at least one filter: --signature, --usdc-amount-equal, --usdc-amount-gte, --sol-amount-gte, --memo, --from-address, --must-jq, --block-time-after, or --block-time-before")
 			}
 
-			// If using USDC amount filter, require USDC mint address from env
+			// If using a USDC amount filter, require USDC mint address from env
 			var usdcMintAddress string
-			if usdcAmount != 0 {
+			if usdcAmount != 0 || usdcMinAmount != 0 {
 				usdcMintAddress = os.Getenv("USDC_MINT_ADDRESS")
 				if usdcMintAddress == "" {
-					return fmt.Errorf("--usdc-amount-equal requires USDC_MINT_ADDRESS environment variable to be set")
+					return fmt.Errorf("USDC amount filters require USDC_MINT_ADDRESS environment variable to be set")
 				}
 			}
 
@@ -453,20 +557,13 @@ func awaitCommand() *cli.Command {
 			}))
 
 			// Compile jq filters
-			compiledJQFilters := make([]*gojq.Code, len(jqFilters))
-			for i, filter := range jqFilters {
-				query, err := gojq.Parse(filter)
-				if err != nil {
-					return fmt.Errorf("failed to parse jq filter %q: %w", filter, err)
-				}
-				compiledJQFilters[i], err = gojq.Compile(query)
-				if err != nil {
-					return fmt.Errorf("failed to compile jq filter %q: %w", filter, err)
-				}
+			compiledJQFilters, err := client.CompileJQ(jqFilters)
+			if err != nil {
+				return err
 			}
 
 			// Create client
-			cl := client.NewClient(serverURL, nil, logger)
+			cl := client.NewClient(serverURL, nil, logger, apiKeyOption(c))
 
 			// Build matcher function based on flags
 			matcher := func(txn *client.Transaction) bool {
@@ -475,69 +572,72 @@ func awaitCommand() *cli.Command {
 					return false
 				}
 
-				// Check USDC amount (USDC has 6 decimals)
-				if usdcAmount != 0 {
-					// Verify it's actually USDC by checking token_type (which contains the mint address)
-					if txn.TokenType != usdcMintAddress {
-						return false
-					}
+				// Check exact USDC amount (USDC has 6 decimals)
+				if usdcAmount != 0 && !matchesExactAmount(txn, usdcMintAddress, usdcAmount) {
+					return false
+				}
 
-					// Check amount matches (USDC has 6 decimals)
-					expectedLamports := int64(usdcAmount * 1e6)
-					if txn.Amount != expectedLamports {
-						return false
-					}
+				// Check minimum amounts (overpayment is accepted)
+				if usdcMinAmount != 0 && !meetsMinAmount(txn, usdcMintAddress, usdcMinAmount) {
+					return false
+				}
+				if solMinAmount != 0 && !meetsMinAmount(txn, "", solMinAmount) {
+					return false
 				}
 
-				// Check jq filters (all must return true)
-				if len(compiledJQFilters) > 0 {
-					// Parse memo as JSON for jq filtering
-					var memoJSON interface{}
-					if txn.Memo != nil {
-						if err := json.Unmarshal([]byte(*txn.Memo), &memoJSON); err != nil {
-							// If memo is not valid JSON, jq filters will fail
-							return false
-						}
-					} else {
-						// No memo, jq filters can't match
-						return false
-					}
+				// Check memo
+				if memo != "" && !client.MemoMatches(txn.Memo, memo, memoMatch) {
+					return false
+				}
 
-					// All jq filters must evaluate to true
-					for _, code := range compiledJQFilters {
-						iter := code.Run(memoJSON)
-						v, ok := iter.Next()
-						if !ok {
-							// No result means filter failed
-							return false
-						}
-						if err, isErr := v.(error); isErr {
-							// Filter error means it failed
-							logger.Debug("jq filter error", "error", err)
-							return false
-						}
-						// Check if result is truthy (true, non-zero number, non-empty string, etc.)
-						if !isTruthy(v) {
-							return false
-						}
-					}
+				// Check sender
+				if fromAddress != "" && !fromAddressMatches(txn.FromAddress, fromAddress) {
+					return false
+				}
+
+				// Check block time window
+				if !inBlockTimeWindow(txn.BlockTime, blockTimeAfter, blockTimeBefore) {
+					return false
+				}
+
+				// Check jq filters (all must return true)
+				if len(compiledJQFilters) > 0 && !memoMatchesJQ(txn, compiledJQFilters, logger) {
+					return false
 				}
 
 				return true
 			}
 
 			// Print waiting message
-			if !jsonOutput {
+			if format == formatTable {
 				fmt.Fprintf(os.Stderr, "Waiting for transaction on wallet %s...\n", address)
 				if signature != "" {
 					fmt.Fprintf(os.Stderr, "  Signature: %s\n", signature)
 				}
 				if usdcAmount != 0 {
-					fmt.Fprintf(os.Stderr, "  USDC Amount: %.6f USDC\n", usdcAmount)
+					fmt.Fprintf(os.Stderr, "  USDC Amount: %s USDC\n", c.String("usdc-amount-equal"))
+				}
+				if usdcMinAmount != 0 {
+					fmt.Fprintf(os.Stderr, "  USDC Amount: at least %s USDC\n", c.String("usdc-amount-gte"))
+				}
+				if solMinAmount != 0 {
+					fmt.Fprintf(os.Stderr, "  SOL Amount: at least %s SOL\n", c.String("sol-amount-gte"))
+				}
+				if memo != "" {
+					fmt.Fprintf(os.Stderr, "  Memo (%s): %s\n", memoMatch, memo)
+				}
+				if fromAddress != "" {
+					fmt.Fprintf(os.Stderr, "  From Address: %s\n", fromAddress)
 				}
 				for _, filter := range jqFilters {
 					fmt.Fprintf(os.Stderr, "  jq Filter: %s\n", filter)
 				}
+				if !blockTimeAfter.IsZero() {
+					fmt.Fprintf(os.Stderr, "  Block Time After: %s\n", blockTimeAfter.Format(time.RFC3339))
+				}
+				if !blockTimeBefore.IsZero() {
+					fmt.Fprintf(os.Stderr, "  Block Time Before: %s\n", blockTimeBefore.Format(time.RFC3339))
+				}
 				if lookback > 0 {
 					fmt.Fprintf(os.Stderr, "  Lookback: %v\n", lookback)
 				}
@@ -554,34 +654,190 @@ func awaitCommand() *cli.Command {
 			}
 
 			// Output transaction
-			if jsonOutput {
-				data, err := json.MarshalIndent(txn, "", "  ")
-				if err != nil {
-					return fmt.Errorf("failed to marshal transaction: %w", err)
-				}
-				fmt.Println(string(data))
-			} else {
-				printTransactionDetailed(txn)
+			return render(c, format, txn, func(w io.Writer) error {
+				printTransactionDetailed(w, txn)
+				return nil
+			})
+		},
+	}
+}
+
+// memoMatchesJQ reports whether every jq filter evaluates truthy against the
+// transaction's memo parsed as JSON, as client.MemoMatchesJQ does. Missing
+// memos, memos that aren't valid JSON and memos the server truncated never
+// match: a truncated memo is not the payload the sender wrote, even when the
+// cut happens to leave valid JSON.
+func memoMatchesJQ(txn *client.Transaction, filters []*gojq.Code, logger *slog.Logger) bool {
+	if txn.MemoTruncated {
+		logger.Debug("skipping jq filters for truncated memo", "signature", txn.Signature)
+	}
+	return client.MemoMatchesJQ(txn.Memo, txn.MemoTruncated, filters)
+}
+
+// usdcDecimals is the number of decimal places in a USDC amount.
+const usdcDecimals = 6
+
+// solDecimals is the number of decimal places in a SOL amount (lamports).
+const solDecimals = 9
+
+// meetsMinAmount reports whether txn is an incoming transfer of the asset
+// tokenType (a mint address, or empty for SOL) of at least min base units.
+// Like the payment gateway, overpayment is accepted and a transfer the wallet
+// sent never counts.
+func meetsMinAmount(txn *client.Transaction, tokenType string, min int64) bool {
+	return txn.Direction != client.DirectionOut && txn.TokenType == tokenType && txn.Amount >= min
+}
+
+// matchesExactAmount reports whether txn is an incoming transfer of the asset
+// tokenType (a mint address, or empty for SOL) of exactly amount base units.
+// As with meetsMinAmount, a transfer the wallet sent never counts.
+func matchesExactAmount(txn *client.Transaction, tokenType string, amount int64) bool {
+	return txn.Direction != client.DirectionOut && txn.TokenType == tokenType && txn.Amount == amount
+}
+
+// parseDecimalAmount converts a decimal string such as "0.42" into base units
+// of a token with the given decimals (420000 for USDC), without going through
+// a float, so values like 0.1 convert exactly. An empty value yields 0. More
+// fractional digits than decimals is an error rather than a rounding.
+func parseDecimalAmount(value string, decimals int) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	whole, frac, _ := strings.Cut(value, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("%q is not a decimal amount", value)
+	}
+	for _, part := range []string{whole, frac} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return 0, fmt.Errorf("%q is not a non-negative decimal amount", value)
 			}
+		}
+	}
+	if len(frac) > decimals {
+		return 0, fmt.Errorf("%q has more than %d decimal places", value, decimals)
+	}
 
-			return nil
-		},
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is too large", value)
+	}
+	return amount, nil
+}
+
+// validateWalletAddress checks that address is a base58-encoded 32-byte
+// Solana public key.
+func validateWalletAddress(address string) error {
+	key, err := base58.Decode(address)
+	if err != nil || len(key) != 32 {
+		return fmt.Errorf("%q is not a base58-encoded Solana address", address)
 	}
+	return nil
 }
 
-// isTruthy checks if a jq result value is truthy.
-// In jq, false and null are falsy, everything else is truthy.
-func isTruthy(v interface{}) bool {
-	if v == nil {
+// fromAddressMatches reports whether a transaction's sender is want. A
+// transaction whose sender wasn't parsed (nil) never matches.
+func fromAddressMatches(from *string, want string) bool {
+	return from != nil && *from == want
+}
+
+// parseBlockTimeFlag parses an RFC3339 --block-time-* value. An empty value
+// means the bound is unset and yields the zero time.
+func parseBlockTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// inBlockTimeWindow reports whether blockTime falls within [after, before].
+// Both bounds are inclusive and a zero bound is unset. When any bound is set,
+// a transaction without a block time never matches, since we can't tell
+// whether it landed inside the window.
+func inBlockTimeWindow(blockTime, after, before time.Time) bool {
+	if after.IsZero() && before.IsZero() {
+		return true
+	}
+	if blockTime.IsZero() {
 		return false
 	}
-	if b, ok := v.(bool); ok {
-		return b
+	if !after.IsZero() && blockTime.Before(after) {
+		return false
+	}
+	if !before.IsZero() && blockTime.After(before) {
+		return false
 	}
-	// Everything else (numbers, strings, objects, arrays) is truthy
 	return true
 }
 
+func walletIngestCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "ingest",
+		Usage:     "Ingest a transaction by signature (recover a missed webhook delivery)",
+		ArgsUsage: "SIGNATURE",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "server",
+				Aliases: []string{"s"},
+				Value:   "https://forohtoo.brojonat.com",
+				Usage:   "HTTP server URL",
+				EnvVars: []string{"FOROHTOO_SERVER_URL"},
+			},
+			&cli.StringFlag{
+				Name:    "network",
+				Aliases: []string{"n"},
+				Value:   "mainnet",
+				Usage:   "Network (mainnet or devnet)",
+			},
+			&cli.BoolFlag{
+				Name:    "json",
+				Aliases: []string{"j"},
+				Usage:   "Output as JSON",
+			},
+			outputFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return fmt.Errorf("transaction signature is required")
+			}
+
+			signature := c.Args().Get(0)
+			network := c.String("network")
+
+			// Validate network
+			if network != "mainnet" && network != "devnet" {
+				return fmt.Errorf("invalid network: must be 'mainnet' or 'devnet'")
+			}
+
+			logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelError,
+			}))
+
+			cl := client.NewClient(c.String("server"), nil, logger, apiKeyOption(c))
+
+			result, err := cl.IngestTransaction(context.Background(), signature, network)
+			if err != nil {
+				return fmt.Errorf("failed to ingest transaction: %w", err)
+			}
+
+			return render(c, formatTable, result, func(w io.Writer) error {
+				if result.Written == 0 {
+					fmt.Fprintf(w, "Transaction already stored; nothing to do\n")
+				} else {
+					fmt.Fprintf(w, "✓ Ingested %d transfer(s)\n", result.Written)
+				}
+				fmt.Fprintf(w, "  Signature: %s\n", result.Signature)
+				fmt.Fprintf(w, "  Network:   %s\n", result.Network)
+				for _, txn := range result.Transactions {
+					fmt.Fprintf(w, "  Wallet:    %s (amount %d)\n", txn.WalletAddress, txn.Amount)
+				}
+				return nil
+			})
+		},
+	}
+}
+
 func walletTransactionsCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "transactions",
@@ -614,11 +870,33 @@ func walletTransactionsCommand() *cli.Command {
 				Value:   0,
 				Usage:   "Number of transactions to skip",
 			},
+			&cli.StringFlag{
+				Name:  "sort",
+				Value: client.SortBlockTimeDesc,
+				Usage: "Order: block_time_desc, block_time_asc, amount_desc or amount_asc",
+			},
+			&cli.StringFlag{
+				Name:  "direction",
+				Usage: "Only transfers in this direction relative to the wallet: in, out or self",
+			},
+			&cli.Int64Flag{
+				Name:  "min-amount",
+				Usage: "Only transactions of at least this amount, in base units (lamports for SOL)",
+			},
+			&cli.Int64Flag{
+				Name:  "max-amount",
+				Usage: "Only transactions of at most this amount, in base units (lamports for SOL)",
+			},
+			&cli.StringFlag{
+				Name:  "token-mint",
+				Usage: "With --min-amount/--max-amount, the token the amounts are in (default: native SOL)",
+			},
 			&cli.BoolFlag{
 				Name:    "json",
 				Aliases: []string{"j"},
 				Usage:   "Output as JSON",
 			},
+			outputFlag(),
 		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() < 1 {
@@ -630,7 +908,6 @@ func walletTransactionsCommand() *cli.Command {
 			network := c.String("network")
 			limit := c.Int("limit")
 			offset := c.Int("offset")
-			jsonOutput := c.Bool("json")
 
 			// Validate network
 			if network != "mainnet" && network != "devnet" {
@@ -644,97 +921,145 @@ func walletTransactionsCommand() *cli.Command {
 				return fmt.Errorf("offset cannot be negative")
 			}
 
+			// Amount filters select one asset and are always newest first.
+			byAmount := c.IsSet("min-amount") || c.IsSet("max-amount") || c.IsSet("token-mint")
+			if byAmount {
+				if c.IsSet("sort") || c.IsSet("direction") {
+					return fmt.Errorf("--sort and --direction cannot be combined with --min-amount, --max-amount or --token-mint")
+				}
+				if c.Int64("min-amount") < 0 || c.Int64("max-amount") < 0 {
+					return fmt.Errorf("amounts cannot be negative")
+				}
+				if c.IsSet("max-amount") && c.Int64("min-amount") > c.Int64("max-amount") {
+					return fmt.Errorf("--min-amount cannot exceed --max-amount")
+				}
+			}
+
 			logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
 				Level: slog.LevelError,
 			}))
 
-			cl := client.NewClient(serverURL, nil, logger)
-
-			transactions, err := cl.ListTransactions(context.Background(), address, network, limit, offset)
+			cl := client.NewClient(serverURL, nil, logger, apiKeyOption(c))
+
+			var transactions []*client.Transaction
+			var err error
+			if byAmount {
+				transactions, err = cl.ListTransactionsByAmount(context.Background(), address, network, client.AmountRangeOptions{
+					TokenMint: c.String("token-mint"),
+					MinAmount: c.Int64("min-amount"),
+					MaxAmount: c.Int64("max-amount"),
+					Limit:     limit,
+					Offset:    offset,
+				})
+			} else {
+				transactions, err = cl.ListTransactionsWithOptions(context.Background(), address, network, client.ListTransactionsOptions{
+					Limit:     limit,
+					Offset:    offset,
+					Sort:      c.String("sort"),
+					Direction: c.String("direction"),
+				})
+			}
 			if err != nil {
 				return fmt.Errorf("failed to list transactions: %w", err)
 			}
 
-			if jsonOutput {
-				data, _ := json.MarshalIndent(transactions, "", "  ")
-				fmt.Println(string(data))
-			} else {
+			return render(c, formatTable, transactions, func(w io.Writer) error {
 				if len(transactions) == 0 {
-					fmt.Println("No transactions found")
+					fmt.Fprintln(w, "No transactions found")
 					return nil
 				}
 
-				fmt.Printf("Found %d transaction(s) for wallet %s:\n\n", len(transactions), address)
+				fmt.Fprintf(w, "Found %d transaction(s) for wallet %s:\n\n", len(transactions), address)
 				for i, txn := range transactions {
-					fmt.Printf("[%d] Signature: %s\n", i+1, txn.Signature)
+					fmt.Fprintf(w, "[%d] Signature: %s\n", i+1, txn.Signature)
 					if txn.FromAddress != nil {
-						fmt.Printf("    From:      %s\n", *txn.FromAddress)
+						fmt.Fprintf(w, "    From:      %s\n", *txn.FromAddress)
 					}
-					fmt.Printf("    To:        %s\n", txn.WalletAddress)
+					fmt.Fprintf(w, "    To:        %s\n", txn.WalletAddress)
 
 					// Format amount based on token type
-					amount, token := formatAmount(txn.Amount, txn.TokenType)
-					fmt.Printf("    Amount:    %s %s\n", amount, token)
+					amount, token := formatAmount(txn.Amount, txn.TokenType, txn.Decimals)
+					fmt.Fprintf(w, "    Amount:    %s %s\n", amount, token)
 
-					fmt.Printf("    Slot:      %d\n", txn.Slot)
-					fmt.Printf("    Status:    %s\n", txn.ConfirmationStatus)
+					fmt.Fprintf(w, "    Slot:      %d\n", txn.Slot)
+					fmt.Fprintf(w, "    Status:    %s\n", txn.ConfirmationStatus)
 					if !txn.BlockTime.IsZero() {
-						fmt.Printf("    Block Time: %s\n", txn.BlockTime.Format(time.RFC3339))
+						fmt.Fprintf(w, "    Block Time: %s\n", txn.BlockTime.Format(time.RFC3339))
 					}
 					if txn.TokenType != "" {
-						fmt.Printf("    Token:     %s\n", txn.TokenType)
+						fmt.Fprintf(w, "    Token:     %s\n", txn.TokenType)
 					}
 					if txn.Memo != nil && *txn.Memo != "" {
-						fmt.Printf("    Memo:      %s\n", *txn.Memo)
+						fmt.Fprintf(w, "    Memo:      %s%s\n", *txn.Memo, truncatedSuffix(txn.MemoTruncated))
 					}
 					if !txn.PublishedAt.IsZero() {
-						fmt.Printf("    Published: %s\n", txn.PublishedAt.Format(time.RFC3339))
+						fmt.Fprintf(w, "    Published: %s\n", txn.PublishedAt.Format(time.RFC3339))
 					}
-					fmt.Println()
+					fmt.Fprintln(w)
 				}
-			}
-
-			return nil
+				return nil
+			})
 		},
 	}
 }
 
-func printTransactionDetailed(txn *client.Transaction) {
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("✓ Transaction Received")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("Signature:   %s\n", txn.Signature)
+func printTransactionDetailed(w io.Writer, txn *client.Transaction) {
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(w, "✓ Transaction Received")
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(w, "Signature:   %s\n", txn.Signature)
 	if txn.FromAddress != nil {
-		fmt.Printf("From:        %s\n", *txn.FromAddress)
+		fmt.Fprintf(w, "From:        %s\n", *txn.FromAddress)
+	}
+	fmt.Fprintf(w, "To:          %s\n", txn.WalletAddress)
+	if txn.Direction != "" && txn.Direction != client.DirectionIn {
+		fmt.Fprintf(w, "Direction:   %s\n", txn.Direction)
 	}
-	fmt.Printf("To:          %s\n", txn.WalletAddress)
 
 	// Format amount based on token type
-	amount, token := formatAmount(txn.Amount, txn.TokenType)
-	fmt.Printf("Amount:      %s %s\n", amount, token)
+	amount, token := formatAmount(txn.Amount, txn.TokenType, txn.Decimals)
+	fmt.Fprintf(w, "Amount:      %s %s\n", amount, token)
 
-	fmt.Printf("Slot:        %d\n", txn.Slot)
-	fmt.Printf("Status:      %s\n", txn.ConfirmationStatus)
+	fmt.Fprintf(w, "Slot:        %d\n", txn.Slot)
+	fmt.Fprintf(w, "Status:      %s\n", txn.ConfirmationStatus)
 
 	if !txn.BlockTime.IsZero() {
-		fmt.Printf("Block Time:  %s\n", txn.BlockTime.Format(time.RFC3339))
+		fmt.Fprintf(w, "Block Time:  %s\n", txn.BlockTime.Format(time.RFC3339))
 	}
 
 	if txn.TokenType != "" {
-		fmt.Printf("Token:       %s\n", txn.TokenType)
+		fmt.Fprintf(w, "Token:       %s\n", txn.TokenType)
 	}
 
 	if txn.Memo != nil && *txn.Memo != "" {
-		fmt.Printf("Memo:        %s\n", *txn.Memo)
+		fmt.Fprintf(w, "Memo:        %s%s\n", *txn.Memo, truncatedSuffix(txn.MemoTruncated))
+	}
+
+	if txn.PaymentID != nil {
+		if txn.DuplicateLogical {
+			fmt.Fprintf(w, "Payment ID:  %s (duplicate)\n", *txn.PaymentID)
+		} else {
+			fmt.Fprintf(w, "Payment ID:  %s\n", *txn.PaymentID)
+		}
 	}
 
-	fmt.Printf("Published:   %s\n", txn.PublishedAt.Format(time.RFC3339))
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(w, "Published:   %s\n", txn.PublishedAt.Format(time.RFC3339))
+	fmt.Fprintln(w, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+}
+
+// truncatedSuffix marks a memo the server cut to its MAX_MEMO_LENGTH.
+func truncatedSuffix(truncated bool) string {
+	if truncated {
+		return " … (truncated)"
+	}
+	return ""
 }
 
 // formatAmount formats a transaction amount based on the token type.
+// decimals, when the server reports them, override the 6-decimal default for
+// unknown SPL tokens.
 // Returns the formatted amount string and token symbol.
-func formatAmount(amount int64, tokenType string) (string, string) {
+func formatAmount(amount int64, tokenType string, decimals *int) (string, string) {
 	// USDC mint address (6 decimals)
 	usdcMint := os.Getenv("USDC_MINT_ADDRESS")
 
@@ -748,6 +1073,10 @@ func formatAmount(amount int64, tokenType string) (string, string) {
 		return fmt.Sprintf("%.2f", float64(amount)/1e6), "USDC"
 	}
 
+	if decimals != nil {
+		return fmt.Sprintf("%.*f", *decimals, float64(amount)/math.Pow10(*decimals)), "SPL"
+	}
+
 	// Unknown SPL token - use 6 decimals as default for most SPL tokens
 	return fmt.Sprintf("%.6f", float64(amount)/1e6), "SPL"
 }
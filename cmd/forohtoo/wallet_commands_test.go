@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -108,7 +110,7 @@ func TestJQFilterMatching(t *testing.T) {
 			}
 
 			// Check truthiness
-			matched := isTruthy(v)
+			matched := client.JQTruthy(v)
 			if matched != tt.expectMatch {
 				t.Errorf("expected match=%v, got match=%v (jq result: %v)", tt.expectMatch, matched, v)
 			}
@@ -207,7 +209,7 @@ func TestMatcherClosure(t *testing.T) {
 				_ = filterErr // Silence unused warning
 				return false
 			}
-			if !isTruthy(v) {
+			if !client.JQTruthy(v) {
 				return false
 			}
 		}
@@ -244,6 +246,227 @@ func TestMatcherClosure(t *testing.T) {
 	}
 }
 
+func TestMemoMatchesJQ(t *testing.T) {
+	query, err := gojq.Parse(`.workflow_id == "test-123"`)
+	if err != nil {
+		t.Fatalf("failed to parse jq: %v", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		t.Fatalf("failed to compile jq: %v", err)
+	}
+	filters := []*gojq.Code{code}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	memo := func(s string) *string { return &s }
+	tests := []struct {
+		name      string
+		memo      *string
+		truncated bool
+		want      bool
+	}{
+		{"match", memo(`{"workflow_id": "test-123"}`), false, true},
+		{"mismatch", memo(`{"workflow_id": "other"}`), false, false},
+		{"no memo", nil, false, false},
+		{"invalid JSON", memo(`not-json`), false, false},
+		// The cut left invalid JSON: no match, and no error.
+		{"truncated to invalid JSON", memo(`{"workflow_id": "test-1`), true, false},
+		// Even a cut that leaves valid JSON isn't the sender's payload.
+		{"truncated to valid JSON", memo(`{"workflow_id": "test-123"}`), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &client.Transaction{Signature: "sig1", Memo: tt.memo, MemoTruncated: tt.truncated}
+			if got := memoMatchesJQ(txn, filters, logger); got != tt.want {
+				t.Errorf("memoMatchesJQ() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWalletAddress(t *testing.T) {
+	if err := validateWalletAddress("DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"); err != nil {
+		t.Errorf("valid address rejected: %v", err)
+	}
+	for _, address := range []string{"", "not-base58-0OIl", "abc"} {
+		if err := validateWalletAddress(address); err == nil {
+			t.Errorf("validateWalletAddress(%q) = nil, want error", address)
+		}
+	}
+}
+
+func TestFromAddressMatches(t *testing.T) {
+	sender := "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
+	other := "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+
+	tests := []struct {
+		name string
+		from *string
+		want bool
+	}{
+		{"same sender", &sender, true},
+		{"other sender", &other, false},
+		{"sender not parsed", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fromAddressMatches(tt.from, sender); got != tt.want {
+				t.Errorf("fromAddressMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeetsMinAmount(t *testing.T) {
+	const usdcMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	tests := []struct {
+		name      string
+		txn       client.Transaction
+		tokenType string
+		min       int64
+		want      bool
+	}{
+		{"exact USDC", client.Transaction{TokenType: usdcMint, Amount: 5000000}, usdcMint, 5000000, true},
+		{"USDC overpayment", client.Transaction{TokenType: usdcMint, Amount: 7500000}, usdcMint, 5000000, true},
+		{"USDC underpayment", client.Transaction{TokenType: usdcMint, Amount: 4999999}, usdcMint, 5000000, false},
+		{"other token", client.Transaction{TokenType: "otherMint", Amount: 9000000}, usdcMint, 5000000, false},
+		{"SOL", client.Transaction{Amount: 100000000}, "", 100000000, true},
+		{"token when SOL expected", client.Transaction{TokenType: usdcMint, Amount: 100000000}, "", 100000000, false},
+		{"outgoing", client.Transaction{Amount: 100000000, Direction: client.DirectionOut}, "", 100000000, false},
+		{"self transfer", client.Transaction{Amount: 100000000, Direction: client.DirectionSelf}, "", 100000000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := meetsMinAmount(&tt.txn, tt.tokenType, tt.min); got != tt.want {
+				t.Errorf("meetsMinAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesExactAmount(t *testing.T) {
+	const usdcMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	tests := []struct {
+		name      string
+		txn       client.Transaction
+		tokenType string
+		amount    int64
+		want      bool
+	}{
+		{"exact USDC", client.Transaction{TokenType: usdcMint, Amount: 8200000}, usdcMint, 8200000, true},
+		{"USDC overpayment", client.Transaction{TokenType: usdcMint, Amount: 8200001}, usdcMint, 8200000, false},
+		{"USDC underpayment", client.Transaction{TokenType: usdcMint, Amount: 8199999}, usdcMint, 8200000, false},
+		{"other token", client.Transaction{TokenType: "otherMint", Amount: 8200000}, usdcMint, 8200000, false},
+		{"outgoing", client.Transaction{TokenType: usdcMint, Amount: 8200000, Direction: client.DirectionOut}, usdcMint, 8200000, false},
+		{"self transfer", client.Transaction{TokenType: usdcMint, Amount: 8200000, Direction: client.DirectionSelf}, usdcMint, 8200000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesExactAmount(&tt.txn, tt.tokenType, tt.amount); got != tt.want {
+				t.Errorf("matchesExactAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInBlockTimeWindow(t *testing.T) {
+	after := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	before := after.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		blockTime time.Time
+		after     time.Time
+		before    time.Time
+		want      bool
+	}{
+		{"no bounds", after.Add(-24 * time.Hour), time.Time{}, time.Time{}, true},
+		{"no bounds, zero block time", time.Time{}, time.Time{}, time.Time{}, true},
+		{"inside window", after.Add(30 * time.Minute), after, before, true},
+		{"lower bound is inclusive", after, after, before, true},
+		{"upper bound is inclusive", before, after, before, true},
+		{"just before window", after.Add(-time.Nanosecond), after, before, false},
+		{"just after window", before.Add(time.Nanosecond), after, before, false},
+		{"after only", after.Add(24 * time.Hour), after, time.Time{}, true},
+		{"before only", before.Add(-24 * time.Hour), time.Time{}, before, true},
+		{"zero block time with bounds", time.Time{}, after, before, false},
+		{"zero block time with after only", time.Time{}, after, time.Time{}, false},
+		{"other time zone", after.In(time.FixedZone("PST", -8*3600)), after, before, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inBlockTimeWindow(tt.blockTime, tt.after, tt.before); got != tt.want {
+				t.Errorf("inBlockTimeWindow(%v, %v, %v) = %v, want %v", tt.blockTime, tt.after, tt.before, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlockTimeFlag(t *testing.T) {
+	got, err := parseBlockTimeFlag("")
+	if err != nil || !got.IsZero() {
+		t.Errorf("empty value: got %v, %v; want zero time", got, err)
+	}
+
+	got, err = parseBlockTimeFlag("2025-01-02T15:04:05Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := parseBlockTimeFlag("2025-01-02"); err == nil {
+		t.Error("expected error for non-RFC3339 value")
+	}
+}
+
+func TestParseDecimalAmount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"", 0},
+		{"0.1", 100000},
+		{"0.07", 70000},
+		{"0.29", 290000},
+		{"0.42", 420000},
+		// int64(x * 1e6) on a float64 lands one base unit short on these.
+		{"1.005", 1005000},
+		{"8.2", 8200000},
+		{"12", 12000000},
+		{"12.", 12000000},
+		{".5", 500000},
+		{"0.000001", 1},
+		{"007.100", 7100000},
+	}
+	for _, tt := range tests {
+		got, err := parseDecimalAmount(tt.value, usdcDecimals)
+		if err != nil {
+			t.Errorf("parseDecimalAmount(%q): unexpected error: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDecimalAmount(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{".", "-1", "+1", "1e3", "0.1.2", "abc", "1,5", " 1", "0.0000001", "99999999999999.999999"} {
+		if _, err := parseDecimalAmount(value, usdcDecimals); err == nil {
+			t.Errorf("parseDecimalAmount(%q): expected error", value)
+		}
+	}
+
+	// SOL has 9 decimals.
+	if got, err := parseDecimalAmount("8.2", 9); err != nil || got != 8200000000 {
+		t.Errorf("parseDecimalAmount(8.2, 9) = %d, %v; want 8200000000", got, err)
+	}
+}
+
 // Test helpers for mocking HTTP server
 
 func TestWalletAddCommand(t *testing.T) {
@@ -682,3 +905,31 @@ func TestWalletRemoveCommand_Aliases(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatAmount(t *testing.T) {
+	nine := 9
+	two := 2
+
+	tests := []struct {
+		name       string
+		amount     int64
+		tokenType  string
+		decimals   *int
+		wantAmount string
+		wantToken  string
+	}{
+		{"native SOL", 1500000000, "", nil, "1.5000", "SOL"},
+		{"unknown SPL defaults to 6 decimals", 1500000, "SomeMint", nil, "1.500000", "SPL"},
+		{"SPL with server-reported decimals", 1500000000, "SomeMint", &nine, "1.500000000", "SPL"},
+		{"SPL with few decimals", 150, "SomeMint", &two, "1.50", "SPL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, token := formatAmount(tt.amount, tt.tokenType, tt.decimals)
+			if amount != tt.wantAmount || token != tt.wantToken {
+				t.Errorf("formatAmount() = %q %q, want %q %q", amount, token, tt.wantAmount, tt.wantToken)
+			}
+		})
+	}
+}
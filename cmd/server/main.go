@@ -6,7 +6,6 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	forohtooclient "github.com/brojonat/forohtoo/client"
 	"github.com/brojonat/forohtoo/service/config"
@@ -19,11 +18,25 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	// Version information (set via ldflags during build)
+	version = "dev"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
 func main() {
 	cfg := config.MustLoad()
 
 	logger := setupLogger(cfg.LogLevel)
-	logger.Info("starting server", "addr", cfg.ServerAddr)
+	identity := temporal.WorkerIdentity(cfg.TemporalWorkerIdentity, version)
+	logger.Info("starting server",
+		"addr", cfg.ServerAddr,
+		"version", version,
+		"commit", commit,
+		"built", date,
+		"identity", identity,
+	)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -43,9 +56,12 @@ func main() {
 
 	// Prometheus metrics
 	metricsCollector := metrics.NewMetrics(nil)
+	metricsCollector.SetBuildInfo(version, commit, date, identity)
 
 	// Helius webhook client - the sole transaction ingestion path.
 	heliusClient := helius.NewClient(cfg.HeliusAPIKey, cfg.HeliusWebhookURL, cfg.HeliusWebhookAuthToken, logger)
+	heliusClient.SetRPCFallbacks(cfg.RPCFallbackURLs)
+	heliusClient.SetRPCRecorder(metricsCollector)
 	if err := heliusClient.EnsureWebhooks(ctx); err != nil {
 		logger.Error("failed to initialize Helius webhooks", "error", err)
 		os.Exit(1)
@@ -82,12 +98,13 @@ func main() {
 	}
 	defer natsPublisher.Close()
 
-	ssePublisher, err := server.NewSSEPublisher(cfg.NATSURL, store, logger)
+	ssePublisher, err := server.NewSSEPublisher(cfg.NATSURL, store, cfg.SSEReconnectDelay, cfg.SSESubscriptionCleanupInterval, metricsCollector, logger)
 	if err != nil {
 		logger.Error("failed to create SSE publisher", "error", err)
 		os.Exit(1)
 	}
 	defer ssePublisher.Close()
+	go ssePublisher.Run(ctx)
 
 	// Temporal client + in-process worker for the payment-gated registration
 	// workflow. Only spun up when the payment gateway is enabled.
@@ -104,15 +121,18 @@ func main() {
 
 		// The payment workflow's AwaitPayment activity hits the SSE endpoint of
 		// this same server, so the client URL is just our own listen address.
-		forohtooClient := forohtooclient.NewClient("http://localhost"+cfg.ServerAddr, nil, logger)
+		forohtooClient := forohtooclient.NewClient("http://localhost"+cfg.ServerAddr, nil, logger, forohtooclient.WithAPIKey(cfg.APIKey))
 
 		w, err := temporal.NewWorker(temporal.WorkerConfig{
 			TemporalHost:      cfg.TemporalHost,
 			TemporalNamespace: cfg.TemporalNamespace,
 			TaskQueue:         cfg.TemporalTaskQueue,
+			Identity:          identity,
 			Store:             store,
 			HeliusClient:      heliusClient,
 			ForohtooClient:    forohtooClient,
+			AlertPublisher:    natsPublisher,
+			CallbackSecret:    cfg.PaymentGateway.CallbackSecret,
 			Metrics:           metricsCollector,
 			Logger:            logger,
 		})
@@ -125,7 +145,36 @@ func main() {
 			os.Exit(1)
 		}
 		temporalWorker = w
-		logger.Info("payment-gateway temporal worker running")
+		logger.Info("payment-gateway temporal worker running", "identity", w.Identity())
+	}
+
+	// Webhooks deliver transactions at "confirmed"; the finalizer upgrades
+	// them once the RPC node reports them finalized.
+	if cfg.FinalizationTrackingEnabled {
+		var finalizedPublisher natspkg.Publisher
+		if cfg.FinalizationPublishEvents {
+			finalizedPublisher = natsPublisher
+		}
+		finalizer := server.NewFinalizer(store, heliusClient, finalizedPublisher, cfg.FinalizationCheckInterval, logger)
+		go finalizer.Run(ctx)
+	}
+
+	// Transaction digests batch a wallet's transactions into one signed
+	// delivery per subscription interval.
+	if cfg.DigestSigningSecret != "" {
+		digester := server.NewDigester(store, server.NewWebhookDigestNotifier(cfg.DigestSigningSecret), cfg.DigestCheckInterval, logger)
+		go digester.Run(ctx)
+	}
+
+	// Transaction webhooks POST each published transaction to the URLs
+	// registered for its wallet.
+	if cfg.TransactionWebhooksEnabled {
+		dispatcher := server.NewTransactionWebhookDispatcher(store, logger)
+		go func() {
+			if err := dispatcher.Run(ctx, cfg.NATSURL); err != nil {
+				logger.Error("transaction webhooks stopped", "error", err)
+			}
+		}()
 	}
 
 	httpServer := server.New(cfg.ServerAddr, cfg, store, temporalClient, heliusClient, natsPublisher, ssePublisher, metricsCollector, logger)
@@ -154,7 +203,7 @@ func main() {
 		if temporalWorker != nil {
 			temporalWorker.Stop()
 		}
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 		defer shutdownCancel()
 		if err := httpServer.Shutdown(shutdownCtx); err != nil {
 			logger.Error("failed to shutdown gracefully", "error", err)
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,63 @@ type Config struct {
 	ServerAddr string
 	LogLevel   string
 
+	// LogTransactionPayloads logs full transaction payloads, with the
+	// written/skipped/published/sent decision, at each pipeline stage. Payloads
+	// may contain PII, so this is opt-in and only takes effect at debug level.
+	LogTransactionPayloads bool
+
+	// BasePath prefixes every route (e.g. "/forohtoo") when the server is
+	// hosted under a reverse proxy. Empty means routes are served from "/".
+	BasePath string
+
+	// HTTP server timeouts. Long-lived routes (SSE streams) clear their own
+	// read/write deadlines, so these only bound regular request/response routes.
+	ServerReadTimeout  time.Duration
+	ServerWriteTimeout time.Duration
+	ServerIdleTimeout  time.Duration
+
+	// ResponseCompressionEnabled gzips JSON list/get responses and SSE streams
+	// for clients that accept it. Responses shorter than
+	// ResponseCompressionMinBytes are sent uncompressed.
+	ResponseCompressionEnabled  bool
+	ResponseCompressionMinBytes int
+
+	// MaxMemoLength caps the stored memo in bytes. Longer memos are truncated
+	// at a UTF-8 boundary and the transaction is flagged memo_truncated. 0
+	// means unlimited.
+	MaxMemoLength int
+
+	// LogicalPaymentIDField names a field in JSON object memos that
+	// identifies a logical payment. Transactions carrying a payment ID
+	// already seen for the same wallet are stored but flagged
+	// duplicate_logical. Empty disables the check.
+	LogicalPaymentIDField string
+
+	// IngestWriteBatchSize is how many matched transactions ingestion writes
+	// per database round trip. A failed batch is retried row by row. 0 or 1
+	// writes every transaction separately.
+	IngestWriteBatchSize int
+
+	// RecordOutgoingTransfers also stores transfers sent by monitored
+	// wallets, with direction "out". By default only transfers received are
+	// stored.
+	RecordOutgoingTransfers bool
+
+	// MaintenanceMode starts the server with mutating routes refusing calls
+	// with 503. It can be toggled at runtime through the admin API.
+	MaintenanceMode bool
+
+	// ShutdownTimeout bounds graceful shutdown, including draining SSE
+	// streams. SSEReconnectDelay is how long drained SSE clients are asked to
+	// wait before reconnecting, giving the load balancer time to route them
+	// to another replica.
+	ShutdownTimeout   time.Duration
+	SSEReconnectDelay time.Duration
+
+	// SSESubscriptionCleanupInterval is how often the SSE publisher sweeps
+	// for NATS consumers whose stream has gone away without unsubscribing.
+	SSESubscriptionCleanupInterval time.Duration
+
 	// Database configuration
 	DatabaseURL string
 
@@ -30,23 +90,218 @@ type Config struct {
 	TemporalNamespace string
 	TemporalTaskQueue string
 
+	// TemporalWorkerIdentity overrides the worker identity reported to
+	// Temporal. Empty means "<hostname>@<version>".
+	TemporalWorkerIdentity string
+
 	// Helius webhook configuration (the only ingestion path)
 	HeliusAPIKey           string
 	HeliusWebhookURL       string
 	HeliusWebhookAuthToken string
 
+	// AdminAuthToken is the bearer token required by admin routes that expose
+	// operator-only data (the service wallet's payment history). Those routes
+	// refuse every request until it is set.
+	AdminAuthToken string
+
+	// APIKey, when set, is a shared secret every /api/v1 route except the
+	// Helius webhook requires as a bearer token. Empty leaves the API open
+	// unless APIKeysEnabled is set.
+	APIKey string
+
+	// APIKeysEnabled also requires a bearer token on /api/v1 routes, and
+	// accepts the named keys stored in the api_keys table (created with
+	// `forohtoo server create-api-key`) as well as APIKey.
+	APIKeysEnabled bool
+
+	// APIKeyPublicReads lets GET requests through without a key, so only
+	// mutating routes require one.
+	APIKeyPublicReads bool
+
+	// RegistrationRateLimit caps wallet registration requests per client (its
+	// stored API key, else its IP) to this many per minute, after an initial
+	// RegistrationBurst. Each new-wallet registration can start a payment
+	// workflow, so this keeps a client from piling them up. Zero disables it.
+	RegistrationRateLimit int
+	RegistrationBurst     int
+
+	// TrustedProxies lists the reverse proxies (e.g. the ingress) whose
+	// X-Forwarded-For and X-Real-IP headers are believed when a request's
+	// client IP is rate limited. Requests from other peers are counted
+	// against the peer address.
+	TrustedProxies []netip.Prefix
+
+	// RPCFallbackURLs lists, per network, JSON-RPC endpoints tried in order
+	// when the Helius RPC endpoint fails or is rate limited. Credentials, if
+	// any, go in the URL; the Helius API key is never sent to them.
+	RPCFallbackURLs map[string][]string
+
+	// Finalization tracking. Helius webhooks deliver transactions at
+	// "confirmed" commitment; when enabled, a background pass periodically
+	// asks the RPC node for their status and upgrades them to "finalized",
+	// optionally re-publishing them so consumers waiting on finality can act.
+	FinalizationTrackingEnabled bool
+	FinalizationCheckInterval   time.Duration
+	FinalizationPublishEvents   bool
+
+	// Transaction digests. When DigestSigningSecret is set, consumers can
+	// subscribe to a periodic batch of a wallet's transactions instead of
+	// per-transaction events; due digests are checked every
+	// DigestCheckInterval and signed with the secret.
+	DigestSigningSecret string
+	DigestCheckInterval time.Duration
+
+	// Transaction webhooks. When enabled, consumers can register a URL and
+	// secret per wallet and receive each of its transactions as a signed POST
+	// as soon as it is published to NATS.
+	TransactionWebhooksEnabled bool
+
 	// Payment gateway configuration
 	PaymentGateway PaymentGatewayConfig
 }
 
 // PaymentGatewayConfig holds payment gateway settings for wallet registration fees.
+//
+// Fees are charged in USDC by default. Set FeeAssetType to "sol" to charge in
+// SOL, or set FeeMint (and FeeDecimals) to charge in another SPL token.
+//
+// Payment timing is two-tier: the invoice shows a "pay by" time InvoiceExpiry
+// after creation, but the workflow keeps accepting payment for GracePeriod
+// beyond that so a payment sent just before the deadline still counts.
 type PaymentGatewayConfig struct {
 	Enabled        bool          `json:"enabled"`
 	ServiceWallet  string        `json:"service_wallet"`
 	ServiceNetwork string        `json:"service_network"`
-	FeeAmount      int64         `json:"fee_amount"`
-	PaymentTimeout time.Duration `json:"payment_timeout"`
+	FeeAssetType   string        `json:"fee_asset_type"`     // "spl-token" (default) or "sol"
+	FeeMint        string        `json:"fee_mint,omitempty"` // SPL fee mint; defaults to USDC on ServiceNetwork
+	FeeDecimals    int           `json:"fee_decimals"`       // SPL fee mint decimals; SOL is always 9
+	FeeAmount      int64         `json:"fee_amount"`         // in base units of the fee asset
+	PaymentTimeout time.Duration `json:"payment_timeout"`    // invoice expiry when InvoiceExpiry is unset
+	InvoiceExpiry  time.Duration `json:"invoice_expiry"`     // displayed pay-by window
+	GracePeriod    time.Duration `json:"grace_period"`       // extra acceptance time past expiry
 	MemoPrefix     string        `json:"memo_prefix"`
+	MemoMatchMode  string        `json:"memo_match_mode"`           // "exact" (default), "prefix", or "contains"
+	AllowedSenders []string      `json:"allowed_senders,omitempty"` // if non-empty, only payments from these addresses count
+
+	// FeeAmountMainnet and FeeAmountDevnet override FeeAmount for
+	// registrations on that network; nil means FeeAmount applies. A zero
+	// override makes registration on that network free: no invoice, no
+	// workflow.
+	FeeAmountMainnet *int64 `json:"fee_amount_mainnet,omitempty"`
+	FeeAmountDevnet  *int64 `json:"fee_amount_devnet,omitempty"`
+
+	// FeeTiers charge registrations matching their conditions a different
+	// fee. The first matching tier wins over the per-network overrides and
+	// FeeAmount.
+	FeeTiers []FeeTier `json:"fee_tiers,omitempty"`
+
+	// RequireFinalized holds a detected payment until the RPC node reports it
+	// finalized, so a confirmed payment that is later dropped can't complete
+	// a registration. The wait is bounded by FinalizationTimeout.
+	RequireFinalized    bool          `json:"require_finalized"`
+	FinalizationTimeout time.Duration `json:"finalization_timeout"`
+
+	// CallbackSecret signs registration-completed callbacks (HMAC-SHA256).
+	// Callbacks are only accepted when it is set.
+	CallbackSecret string `json:"-"`
+}
+
+// FeeAssetDecimals returns the number of decimals of the fee asset, used to
+// convert FeeAmount to a human-readable amount. An unset FeeAssetType means
+// the USDC default.
+func (p *PaymentGatewayConfig) FeeAssetDecimals() int {
+	switch p.FeeAssetType {
+	case "sol":
+		return 9
+	case "":
+		return 6
+	default:
+		return p.FeeDecimals
+	}
+}
+
+// FeeTier is a registration fee for wallets matching its conditions. An
+// empty condition matches any value.
+type FeeTier struct {
+	Name      string `json:"name"`
+	Network   string `json:"network,omitempty"`    // "mainnet" or "devnet"
+	AssetType string `json:"asset_type,omitempty"` // "sol" or "spl-token"
+	Amount    int64  `json:"amount"`               // in base units of the fee asset; 0 is free
+}
+
+// matches reports whether a registration of assetType on network falls in
+// the tier.
+func (t FeeTier) matches(network, assetType string) bool {
+	return (t.Network == "" || t.Network == network) &&
+		(t.AssetType == "" || t.AssetType == assetType)
+}
+
+// conditions describes the tier's conditions, e.g.
+// "network=mainnet, asset_type=sol".
+func (t FeeTier) conditions() string {
+	var conds []string
+	if t.Network != "" {
+		conds = append(conds, "network="+t.Network)
+	}
+	if t.AssetType != "" {
+		conds = append(conds, "asset_type="+t.AssetType)
+	}
+	if len(conds) == 0 {
+		return "any registration"
+	}
+	return strings.Join(conds, ", ")
+}
+
+// AppliedFee is the fee charged for a registration, with the tier it came
+// from (empty when no tier matched) and why it applies.
+type AppliedFee struct {
+	Amount int64
+	Tier   string
+	Reason string
+}
+
+// FeeFor returns the fee, in base units of the fee asset, for registering a
+// wallet of assetType on network: the first matching tier, else the
+// network's override, else FeeAmount.
+func (p *PaymentGatewayConfig) FeeFor(network, assetType string) AppliedFee {
+	for _, tier := range p.FeeTiers {
+		if tier.matches(network, assetType) {
+			return AppliedFee{
+				Amount: tier.Amount,
+				Tier:   tier.Name,
+				Reason: fmt.Sprintf("fee tier %q matches %s", tier.Name, tier.conditions()),
+			}
+		}
+	}
+	switch {
+	case network == "mainnet" && p.FeeAmountMainnet != nil:
+		return AppliedFee{Amount: *p.FeeAmountMainnet, Reason: "mainnet fee override"}
+	case network == "devnet" && p.FeeAmountDevnet != nil:
+		return AppliedFee{Amount: *p.FeeAmountDevnet, Reason: "devnet fee override"}
+	default:
+		return AppliedFee{Amount: p.FeeAmount, Reason: "default fee"}
+	}
+}
+
+// RequiresPayment reports whether registering a new wallet of assetType on
+// network must go through the payment gateway.
+func (p *PaymentGatewayConfig) RequiresPayment(network, assetType string) bool {
+	return p.Enabled && p.FeeFor(network, assetType).Amount > 0
+}
+
+// InvoiceWindow returns how long after creation an invoice is displayed as
+// payable. It falls back to PaymentTimeout when InvoiceExpiry is unset.
+func (p *PaymentGatewayConfig) InvoiceWindow() time.Duration {
+	if p.InvoiceExpiry > 0 {
+		return p.InvoiceExpiry
+	}
+	return p.PaymentTimeout
+}
+
+// AcceptanceWindow returns how long the registration workflow waits for
+// payment: the invoice window plus the grace period.
+func (p *PaymentGatewayConfig) AcceptanceWindow() time.Duration {
+	return p.InvoiceWindow() + p.GracePeriod
 }
 
 // Load reads configuration from environment variables and validates required fields.
@@ -56,6 +311,62 @@ func Load() (*Config, error) {
 
 	cfg.ServerAddr = getEnvOrDefault("SERVER_ADDR", ":8080")
 	cfg.LogLevel = getEnvOrDefault("LOG_LEVEL", "info")
+	cfg.LogTransactionPayloads = os.Getenv("LOG_TRANSACTION_PAYLOADS") == "true"
+
+	basePath, err := normalizeBasePath(os.Getenv("BASE_PATH"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	cfg.BasePath = basePath
+
+	cfg.ServerReadTimeout = getDurationEnvOrDefault("SERVER_READ_TIMEOUT", 15*time.Second, &errs)
+	cfg.ServerWriteTimeout = getDurationEnvOrDefault("SERVER_WRITE_TIMEOUT", 15*time.Second, &errs)
+	cfg.ServerIdleTimeout = getDurationEnvOrDefault("SERVER_IDLE_TIMEOUT", 60*time.Second, &errs)
+	cfg.ShutdownTimeout = getDurationEnvOrDefault("SHUTDOWN_TIMEOUT", 30*time.Second, &errs)
+	if cfg.ShutdownTimeout == 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	cfg.SSEReconnectDelay = getDurationEnvOrDefault("SSE_RECONNECT_DELAY", time.Second, &errs)
+	cfg.SSESubscriptionCleanupInterval = getDurationEnvOrDefault("SSE_SUBSCRIPTION_CLEANUP_INTERVAL", 30*time.Second, &errs)
+	if cfg.SSESubscriptionCleanupInterval == 0 {
+		errs = append(errs, fmt.Errorf("SSE_SUBSCRIPTION_CLEANUP_INTERVAL must be positive"))
+	}
+
+	cfg.ResponseCompressionEnabled = os.Getenv("RESPONSE_COMPRESSION_ENABLED") == "true"
+	cfg.ResponseCompressionMinBytes = 1024
+	if value := os.Getenv("RESPONSE_COMPRESSION_MIN_BYTES"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			errs = append(errs, fmt.Errorf("RESPONSE_COMPRESSION_MIN_BYTES must be a non-negative integer"))
+		} else {
+			cfg.ResponseCompressionMinBytes = parsed
+		}
+	}
+
+	if value := os.Getenv("MAX_MEMO_LENGTH"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			errs = append(errs, fmt.Errorf("MAX_MEMO_LENGTH must be a non-negative integer"))
+		} else {
+			cfg.MaxMemoLength = parsed
+		}
+	}
+
+	cfg.LogicalPaymentIDField = strings.TrimSpace(os.Getenv("LOGICAL_PAYMENT_ID_FIELD"))
+
+	cfg.IngestWriteBatchSize = 100
+	if value := os.Getenv("INGEST_WRITE_BATCH_SIZE"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			errs = append(errs, fmt.Errorf("INGEST_WRITE_BATCH_SIZE must be a non-negative integer"))
+		} else {
+			cfg.IngestWriteBatchSize = parsed
+		}
+	}
+
+	cfg.RecordOutgoingTransfers = os.Getenv("RECORD_OUTGOING_TRANSFERS") == "true"
+
+	cfg.MaintenanceMode = os.Getenv("MAINTENANCE_MODE") == "true"
 
 	cfg.DatabaseURL = os.Getenv("DATABASE_URL")
 	if cfg.DatabaseURL == "" {
@@ -90,10 +401,76 @@ func Load() (*Config, error) {
 	if cfg.HeliusWebhookAuthToken == "" {
 		errs = append(errs, fmt.Errorf("HELIUS_WEBHOOK_AUTH_TOKEN is required"))
 	}
+	cfg.AdminAuthToken = os.Getenv("ADMIN_AUTH_TOKEN")
+	cfg.APIKey = os.Getenv("API_KEY")
+	cfg.APIKeysEnabled = os.Getenv("API_KEYS_ENABLED") == "true"
+	cfg.APIKeyPublicReads = os.Getenv("API_KEY_PUBLIC_READS") == "true"
+
+	if value := os.Getenv("REGISTRATION_RATE_LIMIT"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			errs = append(errs, fmt.Errorf("REGISTRATION_RATE_LIMIT must be a non-negative integer"))
+		} else {
+			cfg.RegistrationRateLimit = parsed
+		}
+	}
+	cfg.RegistrationBurst = 5
+	if value := os.Getenv("REGISTRATION_BURST"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			errs = append(errs, fmt.Errorf("REGISTRATION_BURST must be a positive integer"))
+		} else {
+			cfg.RegistrationBurst = parsed
+		}
+	}
+
+	for _, raw := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		prefix, err := parseTrustedProxy(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES must be a comma-separated list of IP addresses or CIDR ranges"))
+			break
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, prefix)
+	}
+
+	cfg.RPCFallbackURLs = make(map[string][]string)
+	for network, key := range map[string]string{"mainnet": "RPC_FALLBACK_URLS_MAINNET", "devnet": "RPC_FALLBACK_URLS_DEVNET"} {
+		for _, raw := range strings.Split(os.Getenv(key), ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("%s must be a comma-separated list of http(s) URLs", key))
+				break
+			}
+			cfg.RPCFallbackURLs[network] = append(cfg.RPCFallbackURLs[network], raw)
+		}
+	}
+
+	cfg.FinalizationTrackingEnabled = os.Getenv("FINALIZATION_TRACKING_ENABLED") == "true"
+	cfg.FinalizationCheckInterval = getDurationEnvOrDefault("FINALIZATION_CHECK_INTERVAL", 30*time.Second, &errs)
+	if cfg.FinalizationTrackingEnabled && cfg.FinalizationCheckInterval == 0 {
+		errs = append(errs, fmt.Errorf("FINALIZATION_CHECK_INTERVAL must be positive when finalization tracking is enabled"))
+	}
+	cfg.FinalizationPublishEvents = os.Getenv("FINALIZATION_PUBLISH_EVENTS") == "true"
+
+	cfg.DigestSigningSecret = os.Getenv("DIGEST_SIGNING_SECRET")
+	cfg.DigestCheckInterval = getDurationEnvOrDefault("DIGEST_CHECK_INTERVAL", time.Minute, &errs)
+	if cfg.DigestSigningSecret != "" && cfg.DigestCheckInterval == 0 {
+		errs = append(errs, fmt.Errorf("DIGEST_CHECK_INTERVAL must be positive when digests are enabled"))
+	}
+
+	cfg.TransactionWebhooksEnabled = os.Getenv("TRANSACTION_WEBHOOKS_ENABLED") == "true"
 
 	cfg.TemporalHost = getEnvOrDefault("TEMPORAL_HOST", "localhost:7233")
 	cfg.TemporalNamespace = getEnvOrDefault("TEMPORAL_NAMESPACE", "default")
 	cfg.TemporalTaskQueue = getEnvOrDefault("TEMPORAL_TASK_QUEUE", "forohtoo-payment-gateway")
+	cfg.TemporalWorkerIdentity = os.Getenv("TEMPORAL_WORKER_IDENTITY")
 
 	cfg.PaymentGateway = loadPaymentGatewayConfig()
 	if err := cfg.PaymentGateway.Validate(); err != nil {
@@ -123,6 +500,58 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getDurationEnvOrDefault parses a duration from the environment, recording an
+// error if the value is malformed or negative. Zero disables the timeout.
+func getDurationEnvOrDefault(key string, defaultValue time.Duration, errs *[]error) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("invalid %s: %w", key, err))
+		return defaultValue
+	}
+	if parsed < 0 {
+		*errs = append(*errs, fmt.Errorf("%s must not be negative", key))
+		return defaultValue
+	}
+	return parsed
+}
+
+// normalizeBasePath returns the base path with a leading slash and no trailing
+// slash, or "" when the server is mounted at the root.
+func normalizeBasePath(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "/" {
+		return "", nil
+	}
+	if !strings.HasPrefix(value, "/") {
+		return "", fmt.Errorf("BASE_PATH must start with '/': %q", value)
+	}
+	if strings.ContainsAny(value, "?#{} \t") {
+		return "", fmt.Errorf("BASE_PATH contains invalid characters: %q", value)
+	}
+	return strings.TrimRight(value, "/"), nil
+}
+
+// parseTrustedProxy parses a TRUSTED_PROXIES entry: a CIDR range, or a bare
+// IP address meaning just that address.
+func parseTrustedProxy(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 // GetSupportedMints returns the list of supported SPL token mint addresses for a given network.
 func (c *Config) GetSupportedMints(network string) ([]string, error) {
 	switch network {
@@ -149,6 +578,20 @@ func (c *Config) IsMintSupported(network string, mint string) bool {
 	return false
 }
 
+// PaymentFeeMint returns the SPL mint payment gateway fees are charged in, or
+// "" when fees are paid in SOL. Without an explicit FeeMint this is the USDC
+// mint for the service network.
+func (c *Config) PaymentFeeMint() string {
+	if c.PaymentGateway.FeeAssetType == "sol" {
+		return ""
+	}
+	if c.PaymentGateway.FeeMint != "" {
+		return c.PaymentGateway.FeeMint
+	}
+	mint, _ := c.GetUSDCMintForNetwork(c.PaymentGateway.ServiceNetwork)
+	return mint
+}
+
 // GetUSDCMintForNetwork returns the USDC mint address for a given network.
 func (c *Config) GetUSDCMintForNetwork(network string) (string, error) {
 	switch network {
@@ -164,10 +607,16 @@ func (c *Config) GetUSDCMintForNetwork(network string) (string, error) {
 // LoadDefaults sets default values for payment gateway configuration.
 func (p *PaymentGatewayConfig) LoadDefaults() {
 	p.Enabled = false
+	p.FeeAssetType = "spl-token"
+	p.FeeDecimals = 6
 	p.FeeAmount = 1000000 // 1 USDC (USDC has 6 decimals)
+	p.FeeAmountMainnet = nil
+	p.FeeAmountDevnet = nil
 	p.PaymentTimeout = 24 * time.Hour
 	p.MemoPrefix = "forohtoo-reg:"
+	p.MemoMatchMode = "exact"
 	p.ServiceNetwork = "mainnet"
+	p.FinalizationTimeout = 2 * time.Minute
 }
 
 // LoadFromEnv loads payment gateway configuration from environment variables.
@@ -184,6 +633,20 @@ func (p *PaymentGatewayConfig) LoadFromEnv() error {
 		p.ServiceNetwork = network
 	}
 
+	if assetType := os.Getenv("PAYMENT_GATEWAY_FEE_ASSET_TYPE"); assetType != "" {
+		p.FeeAssetType = assetType
+	}
+
+	p.FeeMint = os.Getenv("PAYMENT_GATEWAY_FEE_MINT")
+
+	if decimalsStr := os.Getenv("PAYMENT_GATEWAY_FEE_DECIMALS"); decimalsStr != "" {
+		parsed, err := strconv.Atoi(decimalsStr)
+		if err != nil {
+			return fmt.Errorf("invalid PAYMENT_GATEWAY_FEE_DECIMALS: %w", err)
+		}
+		p.FeeDecimals = parsed
+	}
+
 	if feeAmountStr := os.Getenv("PAYMENT_GATEWAY_FEE_AMOUNT"); feeAmountStr != "" {
 		parsed, err := strconv.ParseInt(feeAmountStr, 10, 64)
 		if err != nil {
@@ -192,6 +655,30 @@ func (p *PaymentGatewayConfig) LoadFromEnv() error {
 		p.FeeAmount = parsed
 	}
 
+	if feeStr := os.Getenv("PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET"); feeStr != "" {
+		parsed, err := strconv.ParseInt(feeStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET: %w", err)
+		}
+		p.FeeAmountMainnet = &parsed
+	}
+
+	if feeStr := os.Getenv("PAYMENT_GATEWAY_FEE_AMOUNT_DEVNET"); feeStr != "" {
+		parsed, err := strconv.ParseInt(feeStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid PAYMENT_GATEWAY_FEE_AMOUNT_DEVNET: %w", err)
+		}
+		p.FeeAmountDevnet = &parsed
+	}
+
+	if tiersStr := os.Getenv("PAYMENT_GATEWAY_FEE_TIERS"); tiersStr != "" {
+		tiers, err := parseFeeTiers(tiersStr)
+		if err != nil {
+			return fmt.Errorf("invalid PAYMENT_GATEWAY_FEE_TIERS: %w", err)
+		}
+		p.FeeTiers = tiers
+	}
+
 	if timeoutStr := os.Getenv("PAYMENT_GATEWAY_PAYMENT_TIMEOUT"); timeoutStr != "" {
 		parsed, err := time.ParseDuration(timeoutStr)
 		if err != nil {
@@ -200,13 +687,95 @@ func (p *PaymentGatewayConfig) LoadFromEnv() error {
 		p.PaymentTimeout = parsed
 	}
 
+	if expiryStr := os.Getenv("PAYMENT_GATEWAY_INVOICE_EXPIRY"); expiryStr != "" {
+		parsed, err := time.ParseDuration(expiryStr)
+		if err != nil {
+			return fmt.Errorf("invalid PAYMENT_GATEWAY_INVOICE_EXPIRY: %w", err)
+		}
+		p.InvoiceExpiry = parsed
+	}
+
+	if graceStr := os.Getenv("PAYMENT_GATEWAY_GRACE_PERIOD"); graceStr != "" {
+		parsed, err := time.ParseDuration(graceStr)
+		if err != nil {
+			return fmt.Errorf("invalid PAYMENT_GATEWAY_GRACE_PERIOD: %w", err)
+		}
+		p.GracePeriod = parsed
+	}
+
 	if prefix := os.Getenv("PAYMENT_GATEWAY_MEMO_PREFIX"); prefix != "" {
 		p.MemoPrefix = prefix
 	}
 
+	if mode := os.Getenv("PAYMENT_GATEWAY_MEMO_MATCH_MODE"); mode != "" {
+		p.MemoMatchMode = mode
+	}
+
+	if senders := os.Getenv("PAYMENT_GATEWAY_ALLOWED_SENDERS"); senders != "" {
+		for _, sender := range strings.Split(senders, ",") {
+			if sender = strings.TrimSpace(sender); sender != "" {
+				p.AllowedSenders = append(p.AllowedSenders, sender)
+			}
+		}
+	}
+
+	p.RequireFinalized = os.Getenv("PAYMENT_GATEWAY_REQUIRE_FINALIZED") == "true"
+	if timeoutStr := os.Getenv("PAYMENT_GATEWAY_FINALIZATION_TIMEOUT"); timeoutStr != "" {
+		parsed, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return fmt.Errorf("invalid PAYMENT_GATEWAY_FINALIZATION_TIMEOUT: %w", err)
+		}
+		p.FinalizationTimeout = parsed
+	}
+
+	p.CallbackSecret = os.Getenv("PAYMENT_GATEWAY_CALLBACK_SECRET")
+
 	return nil
 }
 
+// parseFeeTiers parses PAYMENT_GATEWAY_FEE_TIERS: semicolon-separated
+// NAME=AMOUNT[:key=value,...] entries, where key is network or asset_type.
+// For example "spl=2000000:asset_type=spl-token;devnet=0:network=devnet".
+// Values are checked by Validate.
+func parseFeeTiers(value string) ([]FeeTier, error) {
+	var tiers []FeeTier
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		head, conds, _ := strings.Cut(entry, ":")
+		name, amountStr, ok := strings.Cut(head, "=")
+		if !ok {
+			return nil, fmt.Errorf("tier %q must be NAME=AMOUNT", head)
+		}
+		tier := FeeTier{Name: strings.TrimSpace(name)}
+		amount, err := strconv.ParseInt(strings.TrimSpace(amountStr), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("tier %q amount: %w", tier.Name, err)
+		}
+		tier.Amount = amount
+		if conds != "" {
+			for _, cond := range strings.Split(conds, ",") {
+				key, val, ok := strings.Cut(cond, "=")
+				if !ok {
+					return nil, fmt.Errorf("tier %q condition %q must be key=value", tier.Name, cond)
+				}
+				switch strings.TrimSpace(key) {
+				case "network":
+					tier.Network = strings.TrimSpace(val)
+				case "asset_type":
+					tier.AssetType = strings.TrimSpace(val)
+				default:
+					return nil, fmt.Errorf("tier %q has unknown condition %q", tier.Name, key)
+				}
+			}
+		}
+		tiers = append(tiers, tier)
+	}
+	return tiers, nil
+}
+
 func loadPaymentGatewayConfig() PaymentGatewayConfig {
 	var cfg PaymentGatewayConfig
 	_ = cfg.LoadFromEnv()
@@ -230,15 +799,73 @@ func (p *PaymentGatewayConfig) Validate() error {
 	if p.ServiceNetwork != "mainnet" && p.ServiceNetwork != "devnet" {
 		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_SERVICE_NETWORK must be 'mainnet' or 'devnet'"))
 	}
+	switch p.FeeAssetType {
+	case "", "spl-token":
+		if p.FeeMint != "" && (len(p.FeeMint) < 32 || len(p.FeeMint) > 44) {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_MINT must be a valid Solana address (32-44 characters)"))
+		}
+		if p.FeeDecimals < 0 || p.FeeDecimals > 18 {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_DECIMALS must be between 0 and 18"))
+		}
+	case "sol":
+		if p.FeeMint != "" {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_MINT must not be set when PAYMENT_GATEWAY_FEE_ASSET_TYPE is 'sol'"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_ASSET_TYPE must be 'sol' or 'spl-token'"))
+	}
 	if p.FeeAmount <= 0 {
 		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_AMOUNT must be positive"))
 	}
+	if p.FeeAmountMainnet != nil && *p.FeeAmountMainnet < 0 {
+		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET must not be negative"))
+	}
+	if p.FeeAmountDevnet != nil && *p.FeeAmountDevnet < 0 {
+		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_AMOUNT_DEVNET must not be negative"))
+	}
+	tierNames := make(map[string]bool, len(p.FeeTiers))
+	for _, tier := range p.FeeTiers {
+		if tier.Name == "" {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_TIERS contains a tier without a name"))
+		} else if tierNames[tier.Name] {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_TIERS contains duplicate tier %q", tier.Name))
+		}
+		tierNames[tier.Name] = true
+		if tier.Amount < 0 {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_TIERS tier %q must not have a negative amount", tier.Name))
+		}
+		if tier.Network != "" && tier.Network != "mainnet" && tier.Network != "devnet" {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_TIERS tier %q network must be 'mainnet' or 'devnet'", tier.Name))
+		}
+		if tier.AssetType != "" && tier.AssetType != "sol" && tier.AssetType != "spl-token" {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FEE_TIERS tier %q asset_type must be 'sol' or 'spl-token'", tier.Name))
+		}
+	}
 	if p.PaymentTimeout <= 0 {
 		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_PAYMENT_TIMEOUT must be positive"))
 	}
+	if p.InvoiceExpiry < 0 {
+		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_INVOICE_EXPIRY must not be negative"))
+	}
+	if p.GracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_GRACE_PERIOD must not be negative"))
+	}
+	if p.RequireFinalized && p.FinalizationTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_FINALIZATION_TIMEOUT must be positive when PAYMENT_GATEWAY_REQUIRE_FINALIZED is true"))
+	}
 	if p.MemoPrefix == "" {
 		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_MEMO_PREFIX should not be empty"))
 	}
+	switch p.MemoMatchMode {
+	case "", "exact", "prefix", "contains": // empty means exact
+	default:
+		errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_MEMO_MATCH_MODE must be exact, prefix, or contains"))
+	}
+	for _, sender := range p.AllowedSenders {
+		if len(sender) < 32 || len(sender) > 44 {
+			errs = append(errs, fmt.Errorf("PAYMENT_GATEWAY_ALLOWED_SENDERS contains an invalid Solana address: %q", sender))
+		}
+	}
 
 	if len(errs) > 0 {
 		return fmt.Errorf("payment gateway configuration validation failed: %v", errs)
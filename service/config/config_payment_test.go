@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,9 +28,21 @@ func TestPaymentGatewayConfig_Defaults(t *testing.T) {
 		"PAYMENT_GATEWAY_ENABLED",
 		"PAYMENT_GATEWAY_SERVICE_WALLET",
 		"PAYMENT_GATEWAY_SERVICE_NETWORK",
+		"PAYMENT_GATEWAY_FEE_ASSET_TYPE",
+		"PAYMENT_GATEWAY_FEE_MINT",
+		"PAYMENT_GATEWAY_FEE_DECIMALS",
 		"PAYMENT_GATEWAY_FEE_AMOUNT",
+		"PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET",
+		"PAYMENT_GATEWAY_FEE_AMOUNT_DEVNET",
+		"PAYMENT_GATEWAY_FEE_TIERS",
 		"PAYMENT_GATEWAY_PAYMENT_TIMEOUT",
+		"PAYMENT_GATEWAY_INVOICE_EXPIRY",
+		"PAYMENT_GATEWAY_GRACE_PERIOD",
 		"PAYMENT_GATEWAY_MEMO_PREFIX",
+		"PAYMENT_GATEWAY_ALLOWED_SENDERS",
+		"PAYMENT_GATEWAY_CALLBACK_SECRET",
+		"PAYMENT_GATEWAY_REQUIRE_FINALIZED",
+		"PAYMENT_GATEWAY_FINALIZATION_TIMEOUT",
 	}
 	for _, key := range envVars {
 		os.Unsetenv(key)
@@ -46,6 +60,14 @@ func TestPaymentGatewayConfig_Defaults(t *testing.T) {
 		t.Errorf("Expected FeeAmount=1000000 (1 USDC), got %d", cfg.FeeAmount)
 	}
 
+	if cfg.FeeAmountMainnet != nil || cfg.FeeAmountDevnet != nil {
+		t.Errorf("Expected no per-network fee overrides by default, got %v/%v", cfg.FeeAmountMainnet, cfg.FeeAmountDevnet)
+	}
+
+	if cfg.FeeAssetType != "spl-token" || cfg.FeeMint != "" || cfg.FeeAssetDecimals() != 6 {
+		t.Errorf("Expected USDC fee asset by default, got %q/%q with %d decimals", cfg.FeeAssetType, cfg.FeeMint, cfg.FeeAssetDecimals())
+	}
+
 	if cfg.PaymentTimeout != 24*time.Hour {
 		t.Errorf("Expected PaymentTimeout=24h, got %v", cfg.PaymentTimeout)
 	}
@@ -54,9 +76,22 @@ func TestPaymentGatewayConfig_Defaults(t *testing.T) {
 		t.Errorf("Expected MemoPrefix=\"forohtoo-reg:\", got %q", cfg.MemoPrefix)
 	}
 
+	// No grace period by default: the invoice expires when the workflow stops waiting
+	if cfg.InvoiceWindow() != 24*time.Hour || cfg.AcceptanceWindow() != 24*time.Hour {
+		t.Errorf("Expected 24h invoice and acceptance windows, got %v and %v", cfg.InvoiceWindow(), cfg.AcceptanceWindow())
+	}
+
 	if cfg.ServiceNetwork != "mainnet" {
 		t.Errorf("Expected ServiceNetwork=\"mainnet\", got %q", cfg.ServiceNetwork)
 	}
+
+	if len(cfg.AllowedSenders) != 0 {
+		t.Errorf("Expected no AllowedSenders by default (open), got %v", cfg.AllowedSenders)
+	}
+
+	if cfg.CallbackSecret != "" {
+		t.Errorf("Expected no CallbackSecret by default (callbacks disabled), got %q", cfg.CallbackSecret)
+	}
 }
 
 // TestPaymentGatewayConfig_LoadFromEnv tests that payment gateway configuration
@@ -72,6 +107,7 @@ func TestPaymentGatewayConfig_LoadFromEnv(t *testing.T) {
 		"PAYMENT_GATEWAY_FEE_AMOUNT":      "5000000", // 5 USDC
 		"PAYMENT_GATEWAY_PAYMENT_TIMEOUT": "48h",
 		"PAYMENT_GATEWAY_MEMO_PREFIX":     "custom-prefix:",
+		"PAYMENT_GATEWAY_CALLBACK_SECRET": "s3cret",
 	}
 
 	for key, value := range envVars {
@@ -109,6 +145,10 @@ func TestPaymentGatewayConfig_LoadFromEnv(t *testing.T) {
 	if cfg.MemoPrefix != "custom-prefix:" {
 		t.Errorf("Expected MemoPrefix=\"custom-prefix:\", got %q", cfg.MemoPrefix)
 	}
+
+	if cfg.CallbackSecret != "s3cret" {
+		t.Errorf("Expected CallbackSecret=\"s3cret\", got %q", cfg.CallbackSecret)
+	}
 }
 
 // TestPaymentGatewayConfig_Validation_MissingServiceWallet tests that validation
@@ -296,6 +336,393 @@ func TestPaymentGatewayConfig_Validation_InvalidTimeout(t *testing.T) {
 	}
 }
 
+// TestPaymentGatewayConfig_InvoiceExpiryAndGracePeriod tests the two-tier
+// timing: the invoice shows InvoiceExpiry, the workflow accepts payment for
+// InvoiceExpiry + GracePeriod.
+func TestPaymentGatewayConfig_InvoiceExpiryAndGracePeriod(t *testing.T) {
+	envVars := map[string]string{
+		"PAYMENT_GATEWAY_PAYMENT_TIMEOUT": "24h",
+		"PAYMENT_GATEWAY_INVOICE_EXPIRY":  "30m",
+		"PAYMENT_GATEWAY_GRACE_PERIOD":    "10m",
+	}
+	for key, value := range envVars {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	cfg := &PaymentGatewayConfig{}
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+
+	if cfg.InvoiceWindow() != 30*time.Minute {
+		t.Errorf("Expected InvoiceWindow=30m, got %v", cfg.InvoiceWindow())
+	}
+	if cfg.AcceptanceWindow() != 40*time.Minute {
+		t.Errorf("Expected AcceptanceWindow=40m, got %v", cfg.AcceptanceWindow())
+	}
+
+	os.Setenv("PAYMENT_GATEWAY_GRACE_PERIOD", "soon")
+	if err := cfg.LoadFromEnv(); err == nil {
+		t.Error("Expected error for invalid PAYMENT_GATEWAY_GRACE_PERIOD, got nil")
+	}
+}
+
+// TestPaymentGatewayConfig_Validation_NegativeGracePeriod tests that negative
+// invoice expiry and grace period values are rejected.
+func TestPaymentGatewayConfig_Validation_NegativeGracePeriod(t *testing.T) {
+	cfg := &PaymentGatewayConfig{
+		Enabled:        true,
+		ServiceWallet:  "FoRoHtOoWaLLeTaDdReSs1234567890123456789012",
+		ServiceNetwork: "mainnet",
+		FeeAmount:      1000000,
+		PaymentTimeout: 24 * time.Hour,
+		InvoiceExpiry:  -time.Minute,
+		GracePeriod:    -time.Minute,
+		MemoPrefix:     "forohtoo-reg:",
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error for negative durations, got nil")
+	}
+	for _, want := range []string{"PAYMENT_GATEWAY_INVOICE_EXPIRY", "PAYMENT_GATEWAY_GRACE_PERIOD"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got: %v", want, err)
+		}
+	}
+}
+
+// TestPaymentGatewayConfig_RequireFinalized tests the opt-in finality wait and
+// its timeout.
+func TestPaymentGatewayConfig_RequireFinalized(t *testing.T) {
+	cfg := &PaymentGatewayConfig{}
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.RequireFinalized || cfg.FinalizationTimeout != 2*time.Minute {
+		t.Errorf("Expected finality wait off with a 2m timeout by default, got %v/%v", cfg.RequireFinalized, cfg.FinalizationTimeout)
+	}
+
+	os.Setenv("PAYMENT_GATEWAY_REQUIRE_FINALIZED", "true")
+	os.Setenv("PAYMENT_GATEWAY_FINALIZATION_TIMEOUT", "45s")
+	defer os.Unsetenv("PAYMENT_GATEWAY_REQUIRE_FINALIZED")
+	defer os.Unsetenv("PAYMENT_GATEWAY_FINALIZATION_TIMEOUT")
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if !cfg.RequireFinalized || cfg.FinalizationTimeout != 45*time.Second {
+		t.Errorf("Expected finality wait on with a 45s timeout, got %v/%v", cfg.RequireFinalized, cfg.FinalizationTimeout)
+	}
+
+	os.Setenv("PAYMENT_GATEWAY_FINALIZATION_TIMEOUT", "later")
+	if err := cfg.LoadFromEnv(); err == nil {
+		t.Error("Expected error for invalid PAYMENT_GATEWAY_FINALIZATION_TIMEOUT, got nil")
+	}
+
+	invalid := &PaymentGatewayConfig{
+		Enabled:          true,
+		ServiceWallet:    "FoRoHtOoWaLLeTaDdReSs1234567890123456789012",
+		ServiceNetwork:   "mainnet",
+		FeeAmount:        1000000,
+		PaymentTimeout:   24 * time.Hour,
+		MemoPrefix:       "forohtoo-reg:",
+		RequireFinalized: true,
+	}
+	if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), "PAYMENT_GATEWAY_FINALIZATION_TIMEOUT") {
+		t.Errorf("Expected validation error for a zero finalization timeout, got: %v", err)
+	}
+}
+
+// TestPaymentGatewayConfig_PerNetworkFees tests per-network fee overrides,
+// including a zero fee that skips the gateway for that network.
+func TestPaymentGatewayConfig_PerNetworkFees(t *testing.T) {
+	os.Setenv("PAYMENT_GATEWAY_ENABLED", "true")
+	os.Setenv("PAYMENT_GATEWAY_FEE_AMOUNT", "1000000")
+	os.Setenv("PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET", "2500000")
+	os.Setenv("PAYMENT_GATEWAY_FEE_AMOUNT_DEVNET", "0")
+	defer os.Unsetenv("PAYMENT_GATEWAY_ENABLED")
+	defer os.Unsetenv("PAYMENT_GATEWAY_FEE_AMOUNT")
+	defer os.Unsetenv("PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET")
+	defer os.Unsetenv("PAYMENT_GATEWAY_FEE_AMOUNT_DEVNET")
+
+	cfg := &PaymentGatewayConfig{}
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if got := cfg.FeeFor("mainnet", "sol").Amount; got != 2500000 {
+		t.Errorf("Expected mainnet fee 2500000, got %d", got)
+	}
+	if got := cfg.FeeFor("devnet", "sol").Amount; got != 0 {
+		t.Errorf("Expected devnet fee 0, got %d", got)
+	}
+	if !cfg.RequiresPayment("mainnet", "sol") {
+		t.Error("Expected mainnet registrations to require payment")
+	}
+	if cfg.RequiresPayment("devnet", "sol") {
+		t.Error("Expected free devnet registrations to skip the payment gateway")
+	}
+
+	// Without an override a network is charged FeeAmount.
+	os.Unsetenv("PAYMENT_GATEWAY_FEE_AMOUNT_DEVNET")
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if got := cfg.FeeFor("devnet", "sol").Amount; got != 1000000 || !cfg.RequiresPayment("devnet", "sol") {
+		t.Errorf("Expected devnet to fall back to FeeAmount, got %d", got)
+	}
+
+	cfg.Enabled = false
+	if cfg.RequiresPayment("mainnet", "sol") {
+		t.Error("Expected no payment when the gateway is disabled")
+	}
+
+	os.Setenv("PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET", "lots")
+	if err := cfg.LoadFromEnv(); err == nil {
+		t.Error("Expected error for invalid PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET, got nil")
+	}
+
+	negative := int64(-1)
+	invalid := &PaymentGatewayConfig{
+		Enabled:         true,
+		ServiceWallet:   "FoRoHtOoWaLLeTaDdReSs1234567890123456789012",
+		ServiceNetwork:  "mainnet",
+		FeeAmount:       1000000,
+		FeeAmountDevnet: &negative,
+		PaymentTimeout:  24 * time.Hour,
+		MemoPrefix:      "forohtoo-reg:",
+	}
+	if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), "PAYMENT_GATEWAY_FEE_AMOUNT_DEVNET") {
+		t.Errorf("Expected validation error for a negative devnet fee, got: %v", err)
+	}
+}
+
+// TestPaymentGatewayConfig_FeeTiers tests parsing of fee tiers and that the
+// first matching tier takes precedence over the per-network overrides.
+func TestPaymentGatewayConfig_FeeTiers(t *testing.T) {
+	os.Setenv("PAYMENT_GATEWAY_ENABLED", "true")
+	os.Setenv("PAYMENT_GATEWAY_FEE_AMOUNT", "1000000")
+	os.Setenv("PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET", "2500000")
+	os.Setenv("PAYMENT_GATEWAY_FEE_TIERS", "devnet-sol=0:network=devnet,asset_type=sol; spl=3000000:asset_type=spl-token")
+	defer os.Unsetenv("PAYMENT_GATEWAY_ENABLED")
+	defer os.Unsetenv("PAYMENT_GATEWAY_FEE_AMOUNT")
+	defer os.Unsetenv("PAYMENT_GATEWAY_FEE_AMOUNT_MAINNET")
+	defer os.Unsetenv("PAYMENT_GATEWAY_FEE_TIERS")
+
+	cfg := &PaymentGatewayConfig{}
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	want := []FeeTier{
+		{Name: "devnet-sol", Network: "devnet", AssetType: "sol", Amount: 0},
+		{Name: "spl", AssetType: "spl-token", Amount: 3000000},
+	}
+	if !reflect.DeepEqual(cfg.FeeTiers, want) {
+		t.Fatalf("Expected FeeTiers %+v, got %+v", want, cfg.FeeTiers)
+	}
+
+	tests := []struct {
+		network   string
+		assetType string
+		amount    int64
+		tier      string
+	}{
+		{"mainnet", "spl-token", 3000000, "spl"},
+		{"devnet", "spl-token", 3000000, "spl"},
+		{"mainnet", "sol", 2500000, ""},
+		{"devnet", "sol", 0, "devnet-sol"},
+	}
+	for _, tt := range tests {
+		fee := cfg.FeeFor(tt.network, tt.assetType)
+		if fee.Amount != tt.amount || fee.Tier != tt.tier || fee.Reason == "" {
+			t.Errorf("FeeFor(%q, %q) = %+v, expected amount %d from tier %q", tt.network, tt.assetType, fee, tt.amount, tt.tier)
+		}
+	}
+	if cfg.RequiresPayment("devnet", "sol") {
+		t.Error("Expected a free tier to skip the payment gateway")
+	}
+
+	for _, bad := range []string{"spl", "spl=lots", "spl=1:asset_type", "spl=1:poll_interval=1m"} {
+		os.Setenv("PAYMENT_GATEWAY_FEE_TIERS", bad)
+		if err := cfg.LoadFromEnv(); err == nil {
+			t.Errorf("Expected error for PAYMENT_GATEWAY_FEE_TIERS=%q, got nil", bad)
+		}
+	}
+
+	invalid := &PaymentGatewayConfig{
+		Enabled:        true,
+		ServiceWallet:  "FoRoHtOoWaLLeTaDdReSs1234567890123456789012",
+		ServiceNetwork: "mainnet",
+		FeeAmount:      1000000,
+		FeeTiers: []FeeTier{
+			{Name: "a", AssetType: "nft", Amount: 1},
+			{Name: "a", Network: "testnet", Amount: -1},
+		},
+		PaymentTimeout: 24 * time.Hour,
+		MemoPrefix:     "forohtoo-reg:",
+	}
+	err := invalid.Validate()
+	for _, msg := range []string{"asset_type must be", "duplicate tier", "negative amount", "network must be"} {
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected validation error containing %q, got: %v", msg, err)
+		}
+	}
+}
+
+// TestPaymentGatewayConfig_AllowedSenders tests parsing and validation of the
+// comma-separated sender allowlist.
+func TestPaymentGatewayConfig_AllowedSenders(t *testing.T) {
+	senderA := "SenderAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	senderB := "SenderBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB"
+	os.Setenv("PAYMENT_GATEWAY_ALLOWED_SENDERS", " "+senderA+", ,"+senderB+" ")
+	defer os.Unsetenv("PAYMENT_GATEWAY_ALLOWED_SENDERS")
+
+	cfg := &PaymentGatewayConfig{}
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if len(cfg.AllowedSenders) != 2 || cfg.AllowedSenders[0] != senderA || cfg.AllowedSenders[1] != senderB {
+		t.Errorf("Expected [%s %s], got %v", senderA, senderB, cfg.AllowedSenders)
+	}
+
+	cfg.Enabled = true
+	cfg.ServiceWallet = "FoRoHtOoWaLLeTaDdReSs1234567890123456789012"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got: %v", err)
+	}
+
+	cfg.AllowedSenders = append(cfg.AllowedSenders, "short")
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "PAYMENT_GATEWAY_ALLOWED_SENDERS") {
+		t.Errorf("Expected PAYMENT_GATEWAY_ALLOWED_SENDERS validation error, got: %v", err)
+	}
+}
+
+// TestPaymentGatewayConfig_MemoMatchMode tests the memo match mode, which
+// defaults to exact.
+func TestPaymentGatewayConfig_MemoMatchMode(t *testing.T) {
+	cfg := &PaymentGatewayConfig{}
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.MemoMatchMode != "exact" {
+		t.Errorf("Expected memo match mode exact by default, got %q", cfg.MemoMatchMode)
+	}
+
+	os.Setenv("PAYMENT_GATEWAY_MEMO_MATCH_MODE", "contains")
+	defer os.Unsetenv("PAYMENT_GATEWAY_MEMO_MATCH_MODE")
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.MemoMatchMode != "contains" {
+		t.Errorf("Expected memo match mode contains, got %q", cfg.MemoMatchMode)
+	}
+
+	cfg.Enabled = true
+	cfg.ServiceWallet = "FoRoHtOoWaLLeTaDdReSs1234567890123456789012"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got: %v", err)
+	}
+
+	cfg.MemoMatchMode = "regex"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "PAYMENT_GATEWAY_MEMO_MATCH_MODE") {
+		t.Errorf("Expected PAYMENT_GATEWAY_MEMO_MATCH_MODE validation error, got: %v", err)
+	}
+}
+
+// TestPaymentGatewayConfig_SOLFee tests configuring fees in SOL.
+func TestPaymentGatewayConfig_SOLFee(t *testing.T) {
+	os.Setenv("PAYMENT_GATEWAY_FEE_ASSET_TYPE", "sol")
+	os.Setenv("PAYMENT_GATEWAY_FEE_AMOUNT", "10000000") // 0.01 SOL
+	defer os.Unsetenv("PAYMENT_GATEWAY_FEE_ASSET_TYPE")
+	defer os.Unsetenv("PAYMENT_GATEWAY_FEE_AMOUNT")
+
+	cfg := &PaymentGatewayConfig{}
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.FeeAssetType != "sol" || cfg.FeeAmount != 10000000 {
+		t.Errorf("Expected 10000000 lamport SOL fee, got %d %s", cfg.FeeAmount, cfg.FeeAssetType)
+	}
+	if cfg.FeeAssetDecimals() != 9 {
+		t.Errorf("Expected 9 decimals for SOL, got %d", cfg.FeeAssetDecimals())
+	}
+
+	cfg.Enabled = true
+	cfg.ServiceWallet = "FoRoHtOoWaLLeTaDdReSs1234567890123456789012"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got: %v", err)
+	}
+
+	root := &Config{
+		USDCMainnetMintAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		PaymentGateway:         *cfg,
+	}
+	if mint := root.PaymentFeeMint(); mint != "" {
+		t.Errorf("Expected no fee mint for SOL fees, got %q", mint)
+	}
+
+	cfg.FeeMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "PAYMENT_GATEWAY_FEE_MINT") {
+		t.Errorf("Expected PAYMENT_GATEWAY_FEE_MINT validation error for SOL fees, got: %v", err)
+	}
+}
+
+// TestPaymentGatewayConfig_FeeMint tests the fee mint default and override.
+func TestPaymentGatewayConfig_FeeMint(t *testing.T) {
+	cfg := &Config{
+		USDCMainnetMintAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		USDCDevnetMintAddress:  "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+	}
+	cfg.PaymentGateway.LoadDefaults()
+	cfg.PaymentGateway.ServiceNetwork = "devnet"
+
+	if mint := cfg.PaymentFeeMint(); mint != cfg.USDCDevnetMintAddress {
+		t.Errorf("Expected devnet USDC fee mint by default, got %q", mint)
+	}
+
+	cfg.PaymentGateway.FeeMint = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+	if mint := cfg.PaymentFeeMint(); mint != cfg.PaymentGateway.FeeMint {
+		t.Errorf("Expected configured fee mint, got %q", mint)
+	}
+}
+
+// TestPaymentGatewayConfig_Validation_InvalidFeeAsset tests that unknown fee
+// asset types and out-of-range decimals are rejected.
+func TestPaymentGatewayConfig_Validation_InvalidFeeAsset(t *testing.T) {
+	tests := []struct {
+		name      string
+		assetType string
+		mint      string
+		decimals  int
+		want      string
+	}{
+		{"unknown asset type", "usdc", "", 6, "PAYMENT_GATEWAY_FEE_ASSET_TYPE"},
+		{"short mint", "spl-token", "short", 6, "PAYMENT_GATEWAY_FEE_MINT"},
+		{"negative decimals", "spl-token", "", -1, "PAYMENT_GATEWAY_FEE_DECIMALS"},
+		{"too many decimals", "spl-token", "", 19, "PAYMENT_GATEWAY_FEE_DECIMALS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &PaymentGatewayConfig{}
+			cfg.LoadDefaults()
+			cfg.Enabled = true
+			cfg.ServiceWallet = "FoRoHtOoWaLLeTaDdReSs1234567890123456789012"
+			cfg.FeeAssetType = tt.assetType
+			cfg.FeeMint = tt.mint
+			cfg.FeeDecimals = tt.decimals
+
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected %s validation error, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
 // TestPaymentGatewayConfig_Validation_DisabledSkipsValidation tests that when
 // the payment gateway is disabled, other validation rules are not enforced.
 func TestPaymentGatewayConfig_Validation_DisabledSkipsValidation(t *testing.T) {
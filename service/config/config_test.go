@@ -1,8 +1,10 @@
 package config
 
 import (
+	"net/netip"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,6 +33,13 @@ func TestLoad_ValidConfig(t *testing.T) {
 	assert.Equal(t, "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU", cfg.USDCDevnetMintAddress)
 	assert.Equal(t, ":8080", cfg.ServerAddr)
 	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, 15*time.Second, cfg.ServerReadTimeout)
+	assert.Equal(t, 15*time.Second, cfg.ServerWriteTimeout)
+	assert.Equal(t, 60*time.Second, cfg.ServerIdleTimeout)
+	assert.Equal(t, 30*time.Second, cfg.ShutdownTimeout)
+	assert.Equal(t, time.Second, cfg.SSEReconnectDelay)
+	assert.Equal(t, 30*time.Second, cfg.SSESubscriptionCleanupInterval)
+	assert.Equal(t, "", cfg.BasePath)
 }
 
 func TestLoad_MissingDatabaseURL(t *testing.T) {
@@ -64,6 +73,12 @@ func TestLoad_CustomValues(t *testing.T) {
 	os.Setenv("LOG_LEVEL", "debug")
 	os.Setenv("NATS_URL", "nats://nats.example.com:4222")
 	os.Setenv("TEMPORAL_HOST", "temporal.example.com:7233")
+	os.Setenv("SERVER_READ_TIMEOUT", "5s")
+	os.Setenv("SERVER_WRITE_TIMEOUT", "30s")
+	os.Setenv("SERVER_IDLE_TIMEOUT", "2m")
+	os.Setenv("SHUTDOWN_TIMEOUT", "1m")
+	os.Setenv("SSE_RECONNECT_DELAY", "3s")
+	os.Setenv("SSE_SUBSCRIPTION_CLEANUP_INTERVAL", "10s")
 	defer cleanupEnv()
 
 	cfg, err := Load()
@@ -76,6 +91,362 @@ func TestLoad_CustomValues(t *testing.T) {
 	assert.Equal(t, "temporal.example.com:7233", cfg.TemporalHost)
 	assert.Equal(t, "default", cfg.TemporalNamespace)
 	assert.Equal(t, "forohtoo-payment-gateway", cfg.TemporalTaskQueue)
+	assert.Equal(t, 5*time.Second, cfg.ServerReadTimeout)
+	assert.Equal(t, 30*time.Second, cfg.ServerWriteTimeout)
+	assert.Equal(t, 2*time.Minute, cfg.ServerIdleTimeout)
+	assert.Equal(t, time.Minute, cfg.ShutdownTimeout)
+	assert.Equal(t, 3*time.Second, cfg.SSEReconnectDelay)
+	assert.Equal(t, 10*time.Second, cfg.SSESubscriptionCleanupInterval)
+}
+
+func TestLoad_ZeroShutdownTimeout(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	os.Setenv("SHUTDOWN_TIMEOUT", "0s")
+	defer cleanupEnv()
+
+	_, err := Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SHUTDOWN_TIMEOUT must be positive")
+}
+
+func TestLoad_InvalidServerTimeout(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	os.Setenv("SERVER_WRITE_TIMEOUT", "soon")
+	os.Setenv("SERVER_IDLE_TIMEOUT", "-1s")
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.Error(t, err)
+	assert.Nil(t, cfg)
+	assert.Contains(t, err.Error(), "invalid SERVER_WRITE_TIMEOUT")
+	assert.Contains(t, err.Error(), "SERVER_IDLE_TIMEOUT must not be negative")
+}
+
+func TestLoad_BasePath(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"/", ""},
+		{"/forohtoo", "/forohtoo"},
+		{"/forohtoo/", "/forohtoo"},
+		{" /apps/forohtoo ", "/apps/forohtoo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cleanupEnv()
+			setRequiredEnv()
+			os.Setenv("BASE_PATH", tt.value)
+			defer cleanupEnv()
+
+			cfg, err := Load()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg.BasePath)
+		})
+	}
+}
+
+func TestLoad_LogTransactionPayloads(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.LogTransactionPayloads, "payload logging should be opt-in")
+
+	os.Setenv("LOG_TRANSACTION_PAYLOADS", "true")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.LogTransactionPayloads)
+}
+
+func TestLoad_ResponseCompression(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.ResponseCompressionEnabled, "compression should be opt-in")
+	assert.Equal(t, 1024, cfg.ResponseCompressionMinBytes)
+
+	os.Setenv("RESPONSE_COMPRESSION_ENABLED", "true")
+	os.Setenv("RESPONSE_COMPRESSION_MIN_BYTES", "256")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.ResponseCompressionEnabled)
+	assert.Equal(t, 256, cfg.ResponseCompressionMinBytes)
+
+	os.Setenv("RESPONSE_COMPRESSION_MIN_BYTES", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "RESPONSE_COMPRESSION_MIN_BYTES")
+}
+
+func TestLoad_AdminAuthToken(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.AdminAuthToken, "admin token should be optional")
+
+	os.Setenv("ADMIN_AUTH_TOKEN", "s3cret")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", cfg.AdminAuthToken)
+}
+
+func TestLoad_APIKey(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.APIKey, "api key auth should be off by default")
+
+	os.Setenv("API_KEY", "k3y")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "k3y", cfg.APIKey)
+	assert.False(t, cfg.APIKeysEnabled)
+	assert.False(t, cfg.APIKeyPublicReads)
+
+	os.Setenv("API_KEYS_ENABLED", "true")
+	os.Setenv("API_KEY_PUBLIC_READS", "true")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.APIKeysEnabled)
+	assert.True(t, cfg.APIKeyPublicReads)
+}
+
+func TestLoad_RegistrationRateLimit(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.RegistrationRateLimit, "rate limiting should be off by default")
+	assert.Equal(t, 5, cfg.RegistrationBurst)
+
+	os.Setenv("REGISTRATION_RATE_LIMIT", "10")
+	os.Setenv("REGISTRATION_BURST", "3")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.RegistrationRateLimit)
+	assert.Equal(t, 3, cfg.RegistrationBurst)
+
+	os.Setenv("REGISTRATION_RATE_LIMIT", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REGISTRATION_RATE_LIMIT")
+
+	os.Setenv("REGISTRATION_RATE_LIMIT", "10")
+	os.Setenv("REGISTRATION_BURST", "0")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REGISTRATION_BURST")
+}
+
+func TestLoad_TrustedProxies(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.TrustedProxies, "no proxy should be trusted by default")
+
+	os.Setenv("TRUSTED_PROXIES", "10.42.0.0/16, 192.168.1.7,fd00::1")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.42.0.0/16"),
+		netip.MustParsePrefix("192.168.1.7/32"),
+		netip.MustParsePrefix("fd00::1/128"),
+	}, cfg.TrustedProxies)
+
+	os.Setenv("TRUSTED_PROXIES", "10.42.0.0/16,ingress")
+	_, err = Load()
+	assert.ErrorContains(t, err, "TRUSTED_PROXIES")
+}
+
+func TestLoad_MaxMemoLength(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.MaxMemoLength, "memos should be unlimited by default")
+
+	os.Setenv("MAX_MEMO_LENGTH", "256")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 256, cfg.MaxMemoLength)
+
+	os.Setenv("MAX_MEMO_LENGTH", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MAX_MEMO_LENGTH")
+
+	os.Setenv("MAX_MEMO_LENGTH", "lots")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MAX_MEMO_LENGTH")
+}
+
+func TestLoad_IngestWriteBatchSize(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 100, cfg.IngestWriteBatchSize)
+
+	os.Setenv("INGEST_WRITE_BATCH_SIZE", "0")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.IngestWriteBatchSize, "0 writes row by row")
+
+	os.Setenv("INGEST_WRITE_BATCH_SIZE", "-5")
+	_, err = Load()
+	assert.ErrorContains(t, err, "INGEST_WRITE_BATCH_SIZE")
+}
+
+func TestLoad_RecordOutgoingTransfers(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.RecordOutgoingTransfers, "only incoming transfers by default")
+
+	os.Setenv("RECORD_OUTGOING_TRANSFERS", "true")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.RecordOutgoingTransfers)
+}
+
+func TestLoad_LogicalPaymentIDField(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.LogicalPaymentIDField, "the check should be off by default")
+
+	os.Setenv("LOGICAL_PAYMENT_ID_FIELD", " payment_id ")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "payment_id", cfg.LogicalPaymentIDField)
+}
+
+func TestLoad_FinalizationTracking(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.FinalizationTrackingEnabled)
+	assert.Equal(t, 30*time.Second, cfg.FinalizationCheckInterval)
+	assert.False(t, cfg.FinalizationPublishEvents)
+
+	os.Setenv("FINALIZATION_TRACKING_ENABLED", "true")
+	os.Setenv("FINALIZATION_CHECK_INTERVAL", "1m")
+	os.Setenv("FINALIZATION_PUBLISH_EVENTS", "true")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.FinalizationTrackingEnabled)
+	assert.Equal(t, time.Minute, cfg.FinalizationCheckInterval)
+	assert.True(t, cfg.FinalizationPublishEvents)
+
+	os.Setenv("FINALIZATION_CHECK_INTERVAL", "0s")
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FINALIZATION_CHECK_INTERVAL")
+}
+
+func TestLoad_Digests(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.DigestSigningSecret)
+	assert.Equal(t, time.Minute, cfg.DigestCheckInterval)
+
+	os.Setenv("DIGEST_SIGNING_SECRET", "s3cret")
+	os.Setenv("DIGEST_CHECK_INTERVAL", "30s")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", cfg.DigestSigningSecret)
+	assert.Equal(t, 30*time.Second, cfg.DigestCheckInterval)
+
+	os.Setenv("DIGEST_CHECK_INTERVAL", "0s")
+	_, err = Load()
+	assert.ErrorContains(t, err, "DIGEST_CHECK_INTERVAL")
+}
+
+func TestLoad_TransactionWebhooks(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.TransactionWebhooksEnabled)
+
+	os.Setenv("TRANSACTION_WEBHOOKS_ENABLED", "true")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.TransactionWebhooksEnabled)
+}
+
+func TestLoad_RPCFallbackURLs(t *testing.T) {
+	cleanupEnv()
+	setRequiredEnv()
+	defer cleanupEnv()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.RPCFallbackURLs)
+
+	os.Setenv("RPC_FALLBACK_URLS_MAINNET", "https://rpc-a.example.com/?token=x, https://rpc-b.example.com")
+	os.Setenv("RPC_FALLBACK_URLS_DEVNET", "https://api.devnet.solana.com")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"mainnet": {"https://rpc-a.example.com/?token=x", "https://rpc-b.example.com"},
+		"devnet":  {"https://api.devnet.solana.com"},
+	}, cfg.RPCFallbackURLs)
+
+	os.Setenv("RPC_FALLBACK_URLS_DEVNET", "api.devnet.solana.com")
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RPC_FALLBACK_URLS_DEVNET")
+}
+
+func TestLoad_InvalidBasePath(t *testing.T) {
+	for _, value := range []string{"forohtoo", "/foro htoo", "/{id}"} {
+		t.Run(value, func(t *testing.T) {
+			cleanupEnv()
+			setRequiredEnv()
+			os.Setenv("BASE_PATH", value)
+			defer cleanupEnv()
+
+			cfg, err := Load()
+			require.Error(t, err)
+			assert.Nil(t, cfg)
+			assert.Contains(t, err.Error(), "BASE_PATH")
+		})
+	}
 }
 
 func TestLoad_USDCMintsMustDiffer(t *testing.T) {
@@ -116,6 +487,27 @@ func cleanupEnv() {
 	os.Unsetenv("USDC_DEVNET_MINT_ADDRESS")
 	os.Unsetenv("SERVER_ADDR")
 	os.Unsetenv("LOG_LEVEL")
+	os.Unsetenv("SERVER_READ_TIMEOUT")
+	os.Unsetenv("SERVER_WRITE_TIMEOUT")
+	os.Unsetenv("SERVER_IDLE_TIMEOUT")
+	os.Unsetenv("SHUTDOWN_TIMEOUT")
+	os.Unsetenv("SSE_RECONNECT_DELAY")
+	os.Unsetenv("SSE_SUBSCRIPTION_CLEANUP_INTERVAL")
+	os.Unsetenv("BASE_PATH")
+	os.Unsetenv("LOG_TRANSACTION_PAYLOADS")
+	os.Unsetenv("RESPONSE_COMPRESSION_ENABLED")
+	os.Unsetenv("RESPONSE_COMPRESSION_MIN_BYTES")
+	os.Unsetenv("MAX_MEMO_LENGTH")
+	os.Unsetenv("LOGICAL_PAYMENT_ID_FIELD")
+	os.Unsetenv("INGEST_WRITE_BATCH_SIZE")
+	os.Unsetenv("RECORD_OUTGOING_TRANSFERS")
+	os.Unsetenv("MAINTENANCE_MODE")
+	os.Unsetenv("FINALIZATION_TRACKING_ENABLED")
+	os.Unsetenv("FINALIZATION_CHECK_INTERVAL")
+	os.Unsetenv("FINALIZATION_PUBLISH_EVENTS")
+	os.Unsetenv("DIGEST_SIGNING_SECRET")
+	os.Unsetenv("DIGEST_CHECK_INTERVAL")
+	os.Unsetenv("TRANSACTION_WEBHOOKS_ENABLED")
 	os.Unsetenv("NATS_URL")
 	os.Unsetenv("TEMPORAL_HOST")
 	os.Unsetenv("TEMPORAL_NAMESPACE")
@@ -123,4 +515,13 @@ func cleanupEnv() {
 	os.Unsetenv("HELIUS_API_KEY")
 	os.Unsetenv("HELIUS_WEBHOOK_URL")
 	os.Unsetenv("HELIUS_WEBHOOK_AUTH_TOKEN")
+	os.Unsetenv("ADMIN_AUTH_TOKEN")
+	os.Unsetenv("API_KEY")
+	os.Unsetenv("API_KEYS_ENABLED")
+	os.Unsetenv("API_KEY_PUBLIC_READS")
+	os.Unsetenv("REGISTRATION_RATE_LIMIT")
+	os.Unsetenv("REGISTRATION_BURST")
+	os.Unsetenv("TRUSTED_PROXIES")
+	os.Unsetenv("RPC_FALLBACK_URLS_MAINNET")
+	os.Unsetenv("RPC_FALLBACK_URLS_DEVNET")
 }
@@ -8,6 +8,60 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID        int64              `json:"id"`
+	Name      string             `json:"name"`
+	KeyHash   string             `json:"key_hash"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID            int64              `json:"id"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Actor         string             `json:"actor"`
+	Action        string             `json:"action"`
+	WalletAddress pgtype.Text        `json:"wallet_address"`
+	Network       pgtype.Text        `json:"network"`
+	Method        string             `json:"method"`
+	Path          string             `json:"path"`
+	Status        int32              `json:"status"`
+	Outcome       string             `json:"outcome"`
+	RequestID     string             `json:"request_id"`
+	RemoteAddr    string             `json:"remote_addr"`
+}
+
+type DigestSubscription struct {
+	ID              int64              `json:"id"`
+	Address         string             `json:"address"`
+	Network         string             `json:"network"`
+	Url             string             `json:"url"`
+	IntervalSeconds int32              `json:"interval_seconds"`
+	LastDeliveredAt pgtype.Timestamptz `json:"last_delivered_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	FilterID        pgtype.Int8        `json:"filter_id"`
+}
+
+type RegistrationInvoice struct {
+	WorkflowID  string             `json:"workflow_id"`
+	Address     string             `json:"address"`
+	Network     string             `json:"network"`
+	AssetType   string             `json:"asset_type"`
+	TokenMint   string             `json:"token_mint"`
+	Invoice     []byte             `json:"invoice"`
+	AcceptUntil pgtype.Timestamptz `json:"accept_until"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type SupportedMint struct {
+	Network   string             `json:"network"`
+	Mint      string             `json:"mint"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// Mint decimals from the on-chain mint account
+	Decimals pgtype.Int2 `json:"decimals"`
+	// Token symbol from Metaplex metadata, if any
+	Symbol pgtype.Text `json:"symbol"`
+}
+
 type Transaction struct {
 	Signature string `json:"signature"`
 	// Destination wallet address (receiver/monitored wallet)
@@ -23,6 +77,28 @@ type Transaction struct {
 	FromAddress pgtype.Text `json:"from_address"`
 	// Solana network where transaction occurred (mainnet, devnet, testnet)
 	Network string `json:"network"`
+	// Client-supplied JSON metadata attached after ingestion
+	Metadata []byte `json:"metadata"`
+	// Network fee in lamports paid by the fee payer (0 if unknown)
+	Fee int64 `json:"fee"`
+	// True if memo was truncated to MAX_MEMO_LENGTH bytes at ingestion
+	MemoTruncated bool `json:"memo_truncated"`
+	// Logical payment ID read from the memo's LOGICAL_PAYMENT_ID_FIELD, if any
+	PaymentID pgtype.Text `json:"payment_id"`
+	// True if an earlier transaction to the wallet carried the same payment_id
+	DuplicateLogical bool `json:"duplicate_logical"`
+	// Transfer direction relative to wallet_address: in, out or self
+	Direction string `json:"direction"`
+}
+
+type TransactionWebhook struct {
+	ID        int64              `json:"id"`
+	Address   string             `json:"address"`
+	Network   string             `json:"network"`
+	Url       string             `json:"url"`
+	Secret    string             `json:"secret"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	FilterID  pgtype.Int8        `json:"filter_id"`
 }
 
 type Wallet struct {
@@ -34,4 +110,41 @@ type Wallet struct {
 	AssetType              string             `json:"asset_type"`
 	TokenMint              string             `json:"token_mint"`
 	AssociatedTokenAddress pgtype.Text        `json:"associated_token_address"`
+	// Client-supplied JSON metadata attached at registration
+	Metadata []byte `json:"metadata"`
+	// When the wallet was unregistered; NULL while registered
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	// Operator-supplied organizational tags (JSON array of strings)
+	Tags []byte `json:"tags"`
+}
+
+type WalletFilter struct {
+	ID        int64              `json:"id"`
+	Address   string             `json:"address"`
+	Network   string             `json:"network"`
+	Name      string             `json:"name"`
+	AssetType pgtype.Text        `json:"asset_type"`
+	TokenMint pgtype.Text        `json:"token_mint"`
+	MinAmount pgtype.Int8        `json:"min_amount"`
+	Memo      pgtype.Text        `json:"memo"`
+	MemoMatch string             `json:"memo_match"`
+	Jq        []string           `json:"jq"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	Event          string             `json:"event"`
+	SourceID       string             `json:"source_id"`
+	Network        string             `json:"network"`
+	Url            string             `json:"url"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	LastStatusCode pgtype.Int4        `json:"last_status_code"`
+	LastError      pgtype.Text        `json:"last_error"`
+	LastAttemptAt  pgtype.Timestamptz `json:"last_attempt_at"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 }
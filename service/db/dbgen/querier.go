@@ -11,24 +11,132 @@ import (
 )
 
 type Querier interface {
+	AddSupportedMint(ctx context.Context, arg AddSupportedMintParams) (SupportedMint, error)
+	// Inserts the invoice for a workflow ID, or replaces one whose acceptance
+	// window has passed. Returns no row while an unexpired invoice exists.
+	ClaimRegistrationInvoice(ctx context.Context, arg ClaimRegistrationInvoiceParams) (RegistrationInvoice, error)
+	// Transactions written (by created_at) per time bucket across all wallets,
+	// optionally limited to one network. Empty buckets are included with a count
+	// of zero so ingestion gaps show up as flat periods.
+	CountTransactionsByTimeBucket(ctx context.Context, arg CountTransactionsByTimeBucketParams) ([]CountTransactionsByTimeBucketRow, error)
 	CountTransactionsByWallet(ctx context.Context, arg CountTransactionsByWalletParams) (int64, error)
+	// Registered (not unregistered) wallet assets per network, asset type and
+	// status.
+	CountWalletsByStatus(ctx context.Context) ([]CountWalletsByStatusRow, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateAuditLogEntry(ctx context.Context, arg CreateAuditLogEntryParams) (AuditLog, error)
+	CreateDigestSubscription(ctx context.Context, arg CreateDigestSubscriptionParams) (DigestSubscription, error)
+	// duplicate_logical is set when the wallet already has a transaction with the
+	// same payment_id: a retried send of one logical payment.
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
+	CreateTransactionWebhook(ctx context.Context, arg CreateTransactionWebhookParams) (TransactionWebhook, error)
+	// Inserts a batch of transactions in one round trip. Rows already stored are
+	// skipped, and only newly inserted rows are returned. Empty strings in the
+	// nullable text columns are stored as NULL. duplicate_logical also counts
+	// earlier rows of the same batch.
+	CreateTransactionsBatch(ctx context.Context, arg CreateTransactionsBatchParams) ([]Transaction, error)
 	CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error)
+	DeleteDigestSubscription(ctx context.Context, id int64) (int64, error)
+	DeleteTransactionWebhook(ctx context.Context, id int64) (int64, error)
 	DeleteTransactionsOlderThan(ctx context.Context, blockTime pgtype.Timestamptz) error
 	DeleteWallet(ctx context.Context, arg DeleteWalletParams) error
+	DeleteWalletFilter(ctx context.Context, arg DeleteWalletFilterParams) (int64, error)
+	// The earliest transaction to a wallet whose memo is exactly @memo and whose
+	// amount is at least @min_amount. An empty @asset matches any asset, 'sol'
+	// native transfers, and a token mint that token. Outgoing transfers are not
+	// payments to the wallet and never match. The md5 comparison uses the
+	// idx_transactions_memo_md5 index; the memo comparison rules out collisions.
+	FindPaymentByMemo(ctx context.Context, arg FindPaymentByMemoParams) (Transaction, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	// Delay between block time and write (created_at) for transactions written
+	// in [@start_time, @end_time), optionally limited to one network and/or
+	// wallet. Percentiles and max are in seconds, and 0 when nothing was written.
+	GetIngestionLatency(ctx context.Context, arg GetIngestionLatencyParams) (GetIngestionLatencyRow, error)
 	GetLatestTransactionByWallet(ctx context.Context, arg GetLatestTransactionByWalletParams) (Transaction, error)
+	GetRegistrationInvoice(ctx context.Context, workflowID string) (RegistrationInvoice, error)
 	GetTransaction(ctx context.Context, arg GetTransactionParams) (Transaction, error)
 	GetTransactionsSince(ctx context.Context, arg GetTransactionsSinceParams) ([]Transaction, error)
 	GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, error)
+	GetWalletFilter(ctx context.Context, arg GetWalletFilterParams) (WalletFilter, error)
+	GetWalletFilterByID(ctx context.Context, id int64) (WalletFilter, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
 	ListActiveWallets(ctx context.Context) ([]Wallet, error)
+	ListAllSupportedMints(ctx context.Context) ([]SupportedMint, error)
+	// Entries recorded in [@start_time, @end_time), newest first. An empty
+	// @actor or @wallet_address matches every entry.
+	ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error)
+	// An empty @address or @network matches every subscription.
+	ListDigestSubscriptions(ctx context.Context, arg ListDigestSubscriptionsParams) ([]DigestSubscription, error)
+	// Subscriptions whose interval has elapsed since their last delivery, most
+	// overdue first.
+	ListDueDigestSubscriptions(ctx context.Context, arg ListDueDigestSubscriptionsParams) ([]DigestSubscription, error)
+	ListSupportedMints(ctx context.Context, network string) ([]SupportedMint, error)
+	// An empty @address or @network matches every webhook.
+	ListTransactionWebhooks(ctx context.Context, arg ListTransactionWebhooksParams) ([]TransactionWebhook, error)
+	// A wallet's transactions in one asset whose amount is within
+	// [@min_amount, @max_amount], newest first. @asset is 'sol' for native
+	// transfers or a token mint; amounts are in that asset's base units. The
+	// range is served by idx_transactions_wallet_network_amount.
+	ListTransactionsByAmountRange(ctx context.Context, arg ListTransactionsByAmountRangeParams) ([]Transaction, error)
+	ListTransactionsByConfirmationStatus(ctx context.Context, arg ListTransactionsByConfirmationStatusParams) ([]Transaction, error)
 	ListTransactionsByTimeRange(ctx context.Context, arg ListTransactionsByTimeRangeParams) ([]Transaction, error)
+	// Newest first; ties by signature, descending, so the order matches
+	// ListTransactionsByWalletBeforeCursor. An empty @direction matches every
+	// direction.
 	ListTransactionsByWallet(ctx context.Context, arg ListTransactionsByWalletParams) ([]Transaction, error)
+	// Smallest payments first; ties oldest first. Same filter as ListTransactionsByWallet.
+	ListTransactionsByWalletAmountAsc(ctx context.Context, arg ListTransactionsByWalletAmountAscParams) ([]Transaction, error)
+	// Largest payments first; ties newest first. Same filter as ListTransactionsByWallet.
+	ListTransactionsByWalletAmountDesc(ctx context.Context, arg ListTransactionsByWalletAmountDescParams) ([]Transaction, error)
 	ListTransactionsByWalletAndTimeRange(ctx context.Context, arg ListTransactionsByWalletAndTimeRangeParams) ([]Transaction, error)
+	// The page after the (@before_block_time, @before_signature) cursor in
+	// ListTransactionsByWallet order. Keyset paging doesn't shift when new
+	// transactions arrive, unlike OFFSET. Same filter as ListTransactionsByWallet.
+	ListTransactionsByWalletBeforeCursor(ctx context.Context, arg ListTransactionsByWalletBeforeCursorParams) ([]Transaction, error)
+	// Chronological order (oldest first). Same filter as ListTransactionsByWallet.
+	ListTransactionsByWalletBlockTimeAsc(ctx context.Context, arg ListTransactionsByWalletBlockTimeAscParams) ([]Transaction, error)
+	// Most recent transactions for several (wallet_address, network) pairs in one
+	// round trip, capped per wallet so a busy wallet can't crowd out the rest.
+	ListTransactionsByWallets(ctx context.Context, arg ListTransactionsByWalletsParams) ([]ListTransactionsByWalletsRow, error)
 	ListTransactionsWithNullFromAddress(ctx context.Context, arg ListTransactionsWithNullFromAddressParams) ([]Transaction, error)
 	ListWalletAssets(ctx context.Context, arg ListWalletAssetsParams) ([]Wallet, error)
-	ListWallets(ctx context.Context) ([]Wallet, error)
+	ListWalletFilters(ctx context.Context, arg ListWalletFiltersParams) ([]WalletFilter, error)
+	// @tags is a JSON array; only wallets carrying all of those tags are returned
+	// ('[]' matches every wallet). Ordered oldest first with the primary key as a
+	// tiebreaker so the order is total and stable for pagination.
+	ListWallets(ctx context.Context, arg ListWalletsParams) ([]Wallet, error)
 	ListWalletsByAddress(ctx context.Context, address string) ([]Wallet, error)
+	// Active wallet assets ordered by the block time of their latest stored
+	// transaction in that asset, newest first. Wallets without a transaction are
+	// left out. The primary key breaks ties so pagination is stable.
+	ListWalletsByRecentActivity(ctx context.Context, arg ListWalletsByRecentActivityParams) ([]ListWalletsByRecentActivityRow, error)
+	// Deliveries by most recent attempt, newest first. An empty @status matches
+	// every delivery.
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	MarkDigestDelivered(ctx context.Context, arg MarkDigestDeliveredParams) error
+	PurgeDeletedWallets(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error)
+	// Records one delivery attempt, creating the delivery on its first attempt.
+	// Later attempts for the same event and source, redeliveries included,
+	// update the row. delivered_at is the last successful attempt.
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	RemoveSupportedMint(ctx context.Context, arg RemoveSupportedMintParams) (int64, error)
+	// Creates the named filter, or replaces its criteria if it exists. The ID
+	// is kept, so digests using the filter pick up the new criteria.
+	SaveWalletFilter(ctx context.Context, arg SaveWalletFilterParams) (WalletFilter, error)
+	// Transactions for a wallet whose memo matches an ILIKE pattern, newest
+	// first. The pattern is served by the idx_transactions_memo_trgm trigram
+	// index, so substring and prefix matches don't scan the wallet's history.
+	SearchTransactionsByMemo(ctx context.Context, arg SearchTransactionsByMemoParams) ([]Transaction, error)
+	SetSupportedMintInfo(ctx context.Context, arg SetSupportedMintInfoParams) (SupportedMint, error)
+	SoftDeleteWallet(ctx context.Context, arg SoftDeleteWalletParams) (int64, error)
+	// Stored transactions per network and asset ('sol' or a token mint), with
+	// how many were received (direction other than 'out') and their summed
+	// amount in base units. The sum is text because it can exceed bigint.
+	SummarizeTransactionsByAsset(ctx context.Context) ([]SummarizeTransactionsByAssetRow, error)
 	UpdateTransactionFromAddress(ctx context.Context, arg UpdateTransactionFromAddressParams) error
+	UpdateTransactionMetadata(ctx context.Context, arg UpdateTransactionMetadataParams) (Transaction, error)
+	// Status belongs to the transaction, so every wallet's row for it is updated.
+	UpdateTransactionStatus(ctx context.Context, arg UpdateTransactionStatusParams) ([]Transaction, error)
 	UpdateWalletStatus(ctx context.Context, arg UpdateWalletStatusParams) (Wallet, error)
 	UpsertWallet(ctx context.Context, arg UpsertWalletParams) (Wallet, error)
 	WalletExists(ctx context.Context, arg WalletExistsParams) (bool, error)
@@ -11,6 +11,64 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countTransactionsByTimeBucket = `-- name: CountTransactionsByTimeBucket :many
+SELECT buckets.bucket::timestamptz AS bucket, COALESCE(counts.count, 0)::bigint AS count
+FROM generate_series(
+    time_bucket($1::interval, $2::timestamptz),
+    $3::timestamptz - INTERVAL '1 microsecond',
+    $1::interval
+) AS buckets(bucket)
+LEFT JOIN (
+    SELECT time_bucket($1::interval, created_at) AS bucket, COUNT(*) AS count
+    FROM transactions
+    WHERE created_at >= $2::timestamptz
+      AND created_at < $3::timestamptz
+      AND ($4::text = '' OR network = $4::text)
+    GROUP BY 1
+) counts ON counts.bucket = buckets.bucket
+ORDER BY buckets.bucket
+`
+
+type CountTransactionsByTimeBucketParams struct {
+	BucketWidth pgtype.Interval    `json:"bucket_width"`
+	StartTime   pgtype.Timestamptz `json:"start_time"`
+	EndTime     pgtype.Timestamptz `json:"end_time"`
+	Network     string             `json:"network"`
+}
+
+type CountTransactionsByTimeBucketRow struct {
+	Bucket pgtype.Timestamptz `json:"bucket"`
+	Count  int64              `json:"count"`
+}
+
+// Transactions written (by created_at) per time bucket across all wallets,
+// optionally limited to one network. Empty buckets are included with a count
+// of zero so ingestion gaps show up as flat periods.
+func (q *Queries) CountTransactionsByTimeBucket(ctx context.Context, arg CountTransactionsByTimeBucketParams) ([]CountTransactionsByTimeBucketRow, error) {
+	rows, err := q.db.Query(ctx, countTransactionsByTimeBucket,
+		arg.BucketWidth,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Network,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountTransactionsByTimeBucketRow
+	for rows.Next() {
+		var i CountTransactionsByTimeBucketRow
+		if err := rows.Scan(&i.Bucket, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const countTransactionsByWallet = `-- name: CountTransactionsByWallet :one
 SELECT COUNT(*) FROM transactions
 WHERE wallet_address = $1
@@ -40,11 +98,23 @@ INSERT INTO transactions (
     token_mint,
     memo,
     confirmation_status,
-    from_address
+    from_address,
+    fee,
+    memo_truncated,
+    payment_id,
+    duplicate_logical,
+    direction
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+    $13::text IS NOT NULL AND EXISTS (
+        SELECT 1 FROM transactions
+        WHERE wallet_address = $2
+          AND network = $3
+          AND payment_id = $13::text
+    ),
+    $14
 )
-RETURNING signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network
+RETURNING signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction
 `
 
 type CreateTransactionParams struct {
@@ -58,8 +128,14 @@ type CreateTransactionParams struct {
 	Memo               pgtype.Text        `json:"memo"`
 	ConfirmationStatus string             `json:"confirmation_status"`
 	FromAddress        pgtype.Text        `json:"from_address"`
+	Fee                int64              `json:"fee"`
+	MemoTruncated      bool               `json:"memo_truncated"`
+	PaymentID          pgtype.Text        `json:"payment_id"`
+	Direction          string             `json:"direction"`
 }
 
+// duplicate_logical is set when the wallet already has a transaction with the
+// same payment_id: a retried send of one logical payment.
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
 	row := q.db.QueryRow(ctx, createTransaction,
 		arg.Signature,
@@ -72,6 +148,10 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.Memo,
 		arg.ConfirmationStatus,
 		arg.FromAddress,
+		arg.Fee,
+		arg.MemoTruncated,
+		arg.PaymentID,
+		arg.Direction,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -86,10 +166,164 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.CreatedAt,
 		&i.FromAddress,
 		&i.Network,
+		&i.Metadata,
+		&i.Fee,
+		&i.MemoTruncated,
+		&i.PaymentID,
+		&i.DuplicateLogical,
+		&i.Direction,
 	)
 	return i, err
 }
 
+const createTransactionsBatch = `-- name: CreateTransactionsBatch :many
+WITH batch AS (
+    SELECT signature, wallet_address, network, slot, block_time, amount, token_mint, memo, confirmation_status, from_address, fee, memo_truncated, payment_id, direction, ord FROM unnest(
+        $1::text[],
+        $2::text[],
+        $3::text[],
+        $4::bigint[],
+        $5::timestamptz[],
+        $6::bigint[],
+        $7::text[],
+        $8::text[],
+        $9::text[],
+        $10::text[],
+        $11::bigint[],
+        $12::boolean[],
+        $13::text[],
+        $14::text[]
+    ) WITH ORDINALITY AS b(
+        signature, wallet_address, network, slot, block_time, amount, token_mint,
+        memo, confirmation_status, from_address, fee, memo_truncated, payment_id, direction, ord
+    )
+)
+INSERT INTO transactions (
+    signature,
+    wallet_address,
+    network,
+    slot,
+    block_time,
+    amount,
+    token_mint,
+    memo,
+    confirmation_status,
+    from_address,
+    fee,
+    memo_truncated,
+    payment_id,
+    duplicate_logical,
+    direction
+)
+SELECT
+    b.signature,
+    b.wallet_address,
+    b.network,
+    b.slot,
+    b.block_time,
+    b.amount,
+    NULLIF(b.token_mint, ''),
+    NULLIF(b.memo, ''),
+    b.confirmation_status,
+    NULLIF(b.from_address, ''),
+    b.fee,
+    b.memo_truncated,
+    NULLIF(b.payment_id, ''),
+    b.payment_id <> '' AND (
+        EXISTS (
+            SELECT 1 FROM transactions t
+            WHERE t.wallet_address = b.wallet_address
+              AND t.network = b.network
+              AND t.payment_id = b.payment_id
+        ) OR EXISTS (
+            SELECT 1 FROM batch e
+            WHERE e.ord < b.ord
+              AND e.wallet_address = b.wallet_address
+              AND e.network = b.network
+              AND e.payment_id = b.payment_id
+        )
+    ),
+    b.direction
+FROM batch b
+ORDER BY b.ord
+ON CONFLICT DO NOTHING
+RETURNING signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction
+`
+
+type CreateTransactionsBatchParams struct {
+	Signatures           []string             `json:"signatures"`
+	WalletAddresses      []string             `json:"wallet_addresses"`
+	Networks             []string             `json:"networks"`
+	Slots                []int64              `json:"slots"`
+	BlockTimes           []pgtype.Timestamptz `json:"block_times"`
+	Amounts              []int64              `json:"amounts"`
+	TokenMints           []string             `json:"token_mints"`
+	Memos                []string             `json:"memos"`
+	ConfirmationStatuses []string             `json:"confirmation_statuses"`
+	FromAddresses        []string             `json:"from_addresses"`
+	Fees                 []int64              `json:"fees"`
+	MemoTruncated        []bool               `json:"memo_truncated"`
+	PaymentIds           []string             `json:"payment_ids"`
+	Directions           []string             `json:"directions"`
+}
+
+// Inserts a batch of transactions in one round trip. Rows already stored are
+// skipped, and only newly inserted rows are returned. Empty strings in the
+// nullable text columns are stored as NULL. duplicate_logical also counts
+// earlier rows of the same batch.
+func (q *Queries) CreateTransactionsBatch(ctx context.Context, arg CreateTransactionsBatchParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, createTransactionsBatch,
+		arg.Signatures,
+		arg.WalletAddresses,
+		arg.Networks,
+		arg.Slots,
+		arg.BlockTimes,
+		arg.Amounts,
+		arg.TokenMints,
+		arg.Memos,
+		arg.ConfirmationStatuses,
+		arg.FromAddresses,
+		arg.Fees,
+		arg.MemoTruncated,
+		arg.PaymentIds,
+		arg.Directions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const deleteTransactionsOlderThan = `-- name: DeleteTransactionsOlderThan :exec
 DELETE FROM transactions
 WHERE block_time < $1
@@ -100,8 +334,112 @@ func (q *Queries) DeleteTransactionsOlderThan(ctx context.Context, blockTime pgt
 	return err
 }
 
+const findPaymentByMemo = `-- name: FindPaymentByMemo :one
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE wallet_address = $1
+  AND network = $2
+  AND direction <> 'out'
+  AND md5(memo) = md5($3::text)
+  AND memo = $3::text
+  AND amount >= $4::bigint
+  AND ($5::text = '' OR COALESCE(token_mint, 'sol') = $5::text)
+ORDER BY block_time ASC
+LIMIT 1
+`
+
+type FindPaymentByMemoParams struct {
+	WalletAddress string `json:"wallet_address"`
+	Network       string `json:"network"`
+	Memo          string `json:"memo"`
+	MinAmount     int64  `json:"min_amount"`
+	Asset         string `json:"asset"`
+}
+
+// The earliest transaction to a wallet whose memo is exactly @memo and whose
+// amount is at least @min_amount. An empty @asset matches any asset, 'sol'
+// native transfers, and a token mint that token. Outgoing transfers are not
+// payments to the wallet and never match. The md5 comparison uses the
+// idx_transactions_memo_md5 index; the memo comparison rules out collisions.
+func (q *Queries) FindPaymentByMemo(ctx context.Context, arg FindPaymentByMemoParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, findPaymentByMemo,
+		arg.WalletAddress,
+		arg.Network,
+		arg.Memo,
+		arg.MinAmount,
+		arg.Asset,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.Signature,
+		&i.WalletAddress,
+		&i.Slot,
+		&i.BlockTime,
+		&i.Amount,
+		&i.TokenMint,
+		&i.Memo,
+		&i.ConfirmationStatus,
+		&i.CreatedAt,
+		&i.FromAddress,
+		&i.Network,
+		&i.Metadata,
+		&i.Fee,
+		&i.MemoTruncated,
+		&i.PaymentID,
+		&i.DuplicateLogical,
+		&i.Direction,
+	)
+	return i, err
+}
+
+const getIngestionLatency = `-- name: GetIngestionLatency :one
+SELECT
+    COUNT(*)::bigint AS count,
+    COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM created_at - block_time)), 0)::float8 AS p50_seconds,
+    COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM created_at - block_time)), 0)::float8 AS p99_seconds,
+    COALESCE(MAX(EXTRACT(EPOCH FROM created_at - block_time)), 0)::float8 AS max_seconds
+FROM transactions
+WHERE created_at >= $1::timestamptz
+  AND created_at < $2::timestamptz
+  AND ($3::text = '' OR network = $3::text)
+  AND ($4::text = '' OR wallet_address = $4::text)
+`
+
+type GetIngestionLatencyParams struct {
+	StartTime     pgtype.Timestamptz `json:"start_time"`
+	EndTime       pgtype.Timestamptz `json:"end_time"`
+	Network       string             `json:"network"`
+	WalletAddress string             `json:"wallet_address"`
+}
+
+type GetIngestionLatencyRow struct {
+	Count      int64   `json:"count"`
+	P50Seconds float64 `json:"p50_seconds"`
+	P99Seconds float64 `json:"p99_seconds"`
+	MaxSeconds float64 `json:"max_seconds"`
+}
+
+// Delay between block time and write (created_at) for transactions written
+// in [@start_time, @end_time), optionally limited to one network and/or
+// wallet. Percentiles and max are in seconds, and 0 when nothing was written.
+func (q *Queries) GetIngestionLatency(ctx context.Context, arg GetIngestionLatencyParams) (GetIngestionLatencyRow, error) {
+	row := q.db.QueryRow(ctx, getIngestionLatency,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Network,
+		arg.WalletAddress,
+	)
+	var i GetIngestionLatencyRow
+	err := row.Scan(
+		&i.Count,
+		&i.P50Seconds,
+		&i.P99Seconds,
+		&i.MaxSeconds,
+	)
+	return i, err
+}
+
 const getLatestTransactionByWallet = `-- name: GetLatestTransactionByWallet :one
-SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network FROM transactions
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
 WHERE wallet_address = $1
   AND network = $2
 ORDER BY block_time DESC
@@ -128,12 +466,18 @@ func (q *Queries) GetLatestTransactionByWallet(ctx context.Context, arg GetLates
 		&i.CreatedAt,
 		&i.FromAddress,
 		&i.Network,
+		&i.Metadata,
+		&i.Fee,
+		&i.MemoTruncated,
+		&i.PaymentID,
+		&i.DuplicateLogical,
+		&i.Direction,
 	)
 	return i, err
 }
 
 const getTransaction = `-- name: GetTransaction :one
-SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network FROM transactions
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
 WHERE signature = $1
   AND network = $2
 LIMIT 1
@@ -159,12 +503,18 @@ func (q *Queries) GetTransaction(ctx context.Context, arg GetTransactionParams)
 		&i.CreatedAt,
 		&i.FromAddress,
 		&i.Network,
+		&i.Metadata,
+		&i.Fee,
+		&i.MemoTruncated,
+		&i.PaymentID,
+		&i.DuplicateLogical,
+		&i.Direction,
 	)
 	return i, err
 }
 
 const getTransactionsSince = `-- name: GetTransactionsSince :many
-SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network FROM transactions
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
 WHERE wallet_address = $1
   AND network = $2
   AND block_time > $3
@@ -198,6 +548,135 @@ func (q *Queries) GetTransactionsSince(ctx context.Context, arg GetTransactionsS
 			&i.CreatedAt,
 			&i.FromAddress,
 			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByAmountRange = `-- name: ListTransactionsByAmountRange :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE wallet_address = $1
+  AND network = $2
+  AND amount >= $3::bigint
+  AND amount <= $4::bigint
+  AND COALESCE(token_mint, 'sol') = $5::text
+ORDER BY block_time DESC
+LIMIT $6 OFFSET $7
+`
+
+type ListTransactionsByAmountRangeParams struct {
+	WalletAddress string `json:"wallet_address"`
+	Network       string `json:"network"`
+	MinAmount     int64  `json:"min_amount"`
+	MaxAmount     int64  `json:"max_amount"`
+	Asset         string `json:"asset"`
+	LimitCount    int32  `json:"limit_count"`
+	OffsetCount   int32  `json:"offset_count"`
+}
+
+// A wallet's transactions in one asset whose amount is within
+// [@min_amount, @max_amount], newest first. @asset is 'sol' for native
+// transfers or a token mint; amounts are in that asset's base units. The
+// range is served by idx_transactions_wallet_network_amount.
+func (q *Queries) ListTransactionsByAmountRange(ctx context.Context, arg ListTransactionsByAmountRangeParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByAmountRange,
+		arg.WalletAddress,
+		arg.Network,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Asset,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByConfirmationStatus = `-- name: ListTransactionsByConfirmationStatus :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE confirmation_status = $1
+  AND network = $2
+ORDER BY block_time ASC
+LIMIT $3
+`
+
+type ListTransactionsByConfirmationStatusParams struct {
+	ConfirmationStatus string `json:"confirmation_status"`
+	Network            string `json:"network"`
+	Limit              int32  `json:"limit"`
+}
+
+func (q *Queries) ListTransactionsByConfirmationStatus(ctx context.Context, arg ListTransactionsByConfirmationStatusParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByConfirmationStatus, arg.ConfirmationStatus, arg.Network, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
 		); err != nil {
 			return nil, err
 		}
@@ -210,7 +689,7 @@ func (q *Queries) GetTransactionsSince(ctx context.Context, arg GetTransactionsS
 }
 
 const listTransactionsByTimeRange = `-- name: ListTransactionsByTimeRange :many
-SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network FROM transactions
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
 WHERE block_time >= $1::timestamptz
   AND block_time <= $2::timestamptz
 ORDER BY block_time ASC
@@ -242,6 +721,12 @@ func (q *Queries) ListTransactionsByTimeRange(ctx context.Context, arg ListTrans
 			&i.CreatedAt,
 			&i.FromAddress,
 			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
 		); err != nil {
 			return nil, err
 		}
@@ -254,27 +739,33 @@ func (q *Queries) ListTransactionsByTimeRange(ctx context.Context, arg ListTrans
 }
 
 const listTransactionsByWallet = `-- name: ListTransactionsByWallet :many
-SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network FROM transactions
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
 WHERE wallet_address = $1
   AND network = $2
   AND from_address IS NOT NULL
-ORDER BY block_time DESC
-LIMIT $3 OFFSET $4
+  AND ($3::text = '' OR direction = $3::text)
+ORDER BY block_time DESC, signature DESC
+LIMIT $4 OFFSET $5
 `
 
 type ListTransactionsByWalletParams struct {
 	WalletAddress string `json:"wallet_address"`
 	Network       string `json:"network"`
-	Limit         int32  `json:"limit"`
-	Offset        int32  `json:"offset"`
+	Direction     string `json:"direction"`
+	LimitCount    int32  `json:"limit_count"`
+	OffsetCount   int32  `json:"offset_count"`
 }
 
+// Newest first; ties by signature, descending, so the order matches
+// ListTransactionsByWalletBeforeCursor. An empty @direction matches every
+// direction.
 func (q *Queries) ListTransactionsByWallet(ctx context.Context, arg ListTransactionsByWalletParams) ([]Transaction, error) {
 	rows, err := q.db.Query(ctx, listTransactionsByWallet,
 		arg.WalletAddress,
 		arg.Network,
-		arg.Limit,
-		arg.Offset,
+		arg.Direction,
+		arg.LimitCount,
+		arg.OffsetCount,
 	)
 	if err != nil {
 		return nil, err
@@ -295,6 +786,12 @@ func (q *Queries) ListTransactionsByWallet(ctx context.Context, arg ListTransact
 			&i.CreatedAt,
 			&i.FromAddress,
 			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
 		); err != nil {
 			return nil, err
 		}
@@ -306,28 +803,32 @@ func (q *Queries) ListTransactionsByWallet(ctx context.Context, arg ListTransact
 	return items, nil
 }
 
-const listTransactionsByWalletAndTimeRange = `-- name: ListTransactionsByWalletAndTimeRange :many
-SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network FROM transactions
+const listTransactionsByWalletAmountAsc = `-- name: ListTransactionsByWalletAmountAsc :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
 WHERE wallet_address = $1
   AND network = $2
-  AND block_time >= $3
-  AND block_time <= $4
-ORDER BY block_time DESC
+  AND from_address IS NOT NULL
+  AND ($3::text = '' OR direction = $3::text)
+ORDER BY amount ASC, block_time ASC
+LIMIT $4 OFFSET $5
 `
 
-type ListTransactionsByWalletAndTimeRangeParams struct {
-	WalletAddress string             `json:"wallet_address"`
-	Network       string             `json:"network"`
-	BlockTime     pgtype.Timestamptz `json:"block_time"`
-	BlockTime_2   pgtype.Timestamptz `json:"block_time_2"`
+type ListTransactionsByWalletAmountAscParams struct {
+	WalletAddress string `json:"wallet_address"`
+	Network       string `json:"network"`
+	Direction     string `json:"direction"`
+	LimitCount    int32  `json:"limit_count"`
+	OffsetCount   int32  `json:"offset_count"`
 }
 
-func (q *Queries) ListTransactionsByWalletAndTimeRange(ctx context.Context, arg ListTransactionsByWalletAndTimeRangeParams) ([]Transaction, error) {
-	rows, err := q.db.Query(ctx, listTransactionsByWalletAndTimeRange,
+// Smallest payments first; ties oldest first. Same filter as ListTransactionsByWallet.
+func (q *Queries) ListTransactionsByWalletAmountAsc(ctx context.Context, arg ListTransactionsByWalletAmountAscParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByWalletAmountAsc,
 		arg.WalletAddress,
 		arg.Network,
-		arg.BlockTime,
-		arg.BlockTime_2,
+		arg.Direction,
+		arg.LimitCount,
+		arg.OffsetCount,
 	)
 	if err != nil {
 		return nil, err
@@ -348,6 +849,12 @@ func (q *Queries) ListTransactionsByWalletAndTimeRange(ctx context.Context, arg
 			&i.CreatedAt,
 			&i.FromAddress,
 			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
 		); err != nil {
 			return nil, err
 		}
@@ -359,21 +866,33 @@ func (q *Queries) ListTransactionsByWalletAndTimeRange(ctx context.Context, arg
 	return items, nil
 }
 
-const listTransactionsWithNullFromAddress = `-- name: ListTransactionsWithNullFromAddress :many
-SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network FROM transactions
-WHERE from_address IS NULL
-  AND network = $1
-ORDER BY block_time DESC
-LIMIT $2
+const listTransactionsByWalletAmountDesc = `-- name: ListTransactionsByWalletAmountDesc :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE wallet_address = $1
+  AND network = $2
+  AND from_address IS NOT NULL
+  AND ($3::text = '' OR direction = $3::text)
+ORDER BY amount DESC, block_time DESC
+LIMIT $4 OFFSET $5
 `
 
-type ListTransactionsWithNullFromAddressParams struct {
-	Network    string `json:"network"`
-	LimitCount int32  `json:"limit_count"`
+type ListTransactionsByWalletAmountDescParams struct {
+	WalletAddress string `json:"wallet_address"`
+	Network       string `json:"network"`
+	Direction     string `json:"direction"`
+	LimitCount    int32  `json:"limit_count"`
+	OffsetCount   int32  `json:"offset_count"`
 }
 
-func (q *Queries) ListTransactionsWithNullFromAddress(ctx context.Context, arg ListTransactionsWithNullFromAddressParams) ([]Transaction, error) {
-	rows, err := q.db.Query(ctx, listTransactionsWithNullFromAddress, arg.Network, arg.LimitCount)
+// Largest payments first; ties newest first. Same filter as ListTransactionsByWallet.
+func (q *Queries) ListTransactionsByWalletAmountDesc(ctx context.Context, arg ListTransactionsByWalletAmountDescParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByWalletAmountDesc,
+		arg.WalletAddress,
+		arg.Network,
+		arg.Direction,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -393,6 +912,12 @@ func (q *Queries) ListTransactionsWithNullFromAddress(ctx context.Context, arg L
 			&i.CreatedAt,
 			&i.FromAddress,
 			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
 		); err != nil {
 			return nil, err
 		}
@@ -404,20 +929,555 @@ func (q *Queries) ListTransactionsWithNullFromAddress(ctx context.Context, arg L
 	return items, nil
 }
 
-const updateTransactionFromAddress = `-- name: UpdateTransactionFromAddress :exec
-UPDATE transactions
-SET from_address = $1
-WHERE signature = $2
-  AND network = $3
+const listTransactionsByWalletAndTimeRange = `-- name: ListTransactionsByWalletAndTimeRange :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE wallet_address = $1
+  AND network = $2
+  AND block_time >= $3
+  AND block_time <= $4
+ORDER BY block_time DESC
 `
 
-type UpdateTransactionFromAddressParams struct {
-	FromAddress pgtype.Text `json:"from_address"`
-	Signature   string      `json:"signature"`
-	Network     string      `json:"network"`
+type ListTransactionsByWalletAndTimeRangeParams struct {
+	WalletAddress string             `json:"wallet_address"`
+	Network       string             `json:"network"`
+	BlockTime     pgtype.Timestamptz `json:"block_time"`
+	BlockTime_2   pgtype.Timestamptz `json:"block_time_2"`
 }
 
-func (q *Queries) UpdateTransactionFromAddress(ctx context.Context, arg UpdateTransactionFromAddressParams) error {
-	_, err := q.db.Exec(ctx, updateTransactionFromAddress, arg.FromAddress, arg.Signature, arg.Network)
-	return err
+func (q *Queries) ListTransactionsByWalletAndTimeRange(ctx context.Context, arg ListTransactionsByWalletAndTimeRangeParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByWalletAndTimeRange,
+		arg.WalletAddress,
+		arg.Network,
+		arg.BlockTime,
+		arg.BlockTime_2,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByWalletBeforeCursor = `-- name: ListTransactionsByWalletBeforeCursor :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE wallet_address = $1
+  AND network = $2
+  AND from_address IS NOT NULL
+  AND ($3::text = '' OR direction = $3::text)
+  AND (block_time, signature) < ($4::timestamptz, $5::text)
+ORDER BY block_time DESC, signature DESC
+LIMIT $6
+`
+
+type ListTransactionsByWalletBeforeCursorParams struct {
+	WalletAddress   string             `json:"wallet_address"`
+	Network         string             `json:"network"`
+	Direction       string             `json:"direction"`
+	BeforeBlockTime pgtype.Timestamptz `json:"before_block_time"`
+	BeforeSignature string             `json:"before_signature"`
+	LimitCount      int32              `json:"limit_count"`
+}
+
+// The page after the (@before_block_time, @before_signature) cursor in
+// ListTransactionsByWallet order. Keyset paging doesn't shift when new
+// transactions arrive, unlike OFFSET. Same filter as ListTransactionsByWallet.
+func (q *Queries) ListTransactionsByWalletBeforeCursor(ctx context.Context, arg ListTransactionsByWalletBeforeCursorParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByWalletBeforeCursor,
+		arg.WalletAddress,
+		arg.Network,
+		arg.Direction,
+		arg.BeforeBlockTime,
+		arg.BeforeSignature,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByWalletBlockTimeAsc = `-- name: ListTransactionsByWalletBlockTimeAsc :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE wallet_address = $1
+  AND network = $2
+  AND from_address IS NOT NULL
+  AND ($3::text = '' OR direction = $3::text)
+ORDER BY block_time ASC
+LIMIT $4 OFFSET $5
+`
+
+type ListTransactionsByWalletBlockTimeAscParams struct {
+	WalletAddress string `json:"wallet_address"`
+	Network       string `json:"network"`
+	Direction     string `json:"direction"`
+	LimitCount    int32  `json:"limit_count"`
+	OffsetCount   int32  `json:"offset_count"`
+}
+
+// Chronological order (oldest first). Same filter as ListTransactionsByWallet.
+func (q *Queries) ListTransactionsByWalletBlockTimeAsc(ctx context.Context, arg ListTransactionsByWalletBlockTimeAscParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByWalletBlockTimeAsc,
+		arg.WalletAddress,
+		arg.Network,
+		arg.Direction,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByWallets = `-- name: ListTransactionsByWallets :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction
+FROM (
+    SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction,
+        ROW_NUMBER() OVER (PARTITION BY wallet_address, network ORDER BY block_time DESC) AS row_num
+    FROM transactions
+    WHERE (wallet_address, network) IN (
+        SELECT UNNEST($1::text[]), UNNEST($2::text[])
+    )
+      AND from_address IS NOT NULL
+      AND block_time >= $3::timestamptz
+      AND block_time <= $4::timestamptz
+) ranked
+WHERE row_num <= $5::int
+ORDER BY wallet_address, network, block_time DESC
+`
+
+type ListTransactionsByWalletsParams struct {
+	WalletAddresses []string           `json:"wallet_addresses"`
+	Networks        []string           `json:"networks"`
+	StartTime       pgtype.Timestamptz `json:"start_time"`
+	EndTime         pgtype.Timestamptz `json:"end_time"`
+	LimitPerWallet  int32              `json:"limit_per_wallet"`
+}
+
+type ListTransactionsByWalletsRow struct {
+	Signature          string             `json:"signature"`
+	WalletAddress      string             `json:"wallet_address"`
+	Slot               int64              `json:"slot"`
+	BlockTime          pgtype.Timestamptz `json:"block_time"`
+	Amount             int64              `json:"amount"`
+	TokenMint          pgtype.Text        `json:"token_mint"`
+	Memo               pgtype.Text        `json:"memo"`
+	ConfirmationStatus string             `json:"confirmation_status"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	FromAddress        pgtype.Text        `json:"from_address"`
+	Network            string             `json:"network"`
+	Metadata           []byte             `json:"metadata"`
+	Fee                int64              `json:"fee"`
+	MemoTruncated      bool               `json:"memo_truncated"`
+	PaymentID          pgtype.Text        `json:"payment_id"`
+	DuplicateLogical   bool               `json:"duplicate_logical"`
+	Direction          string             `json:"direction"`
+}
+
+// Most recent transactions for several (wallet_address, network) pairs in one
+// round trip, capped per wallet so a busy wallet can't crowd out the rest.
+func (q *Queries) ListTransactionsByWallets(ctx context.Context, arg ListTransactionsByWalletsParams) ([]ListTransactionsByWalletsRow, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByWallets,
+		arg.WalletAddresses,
+		arg.Networks,
+		arg.StartTime,
+		arg.EndTime,
+		arg.LimitPerWallet,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTransactionsByWalletsRow
+	for rows.Next() {
+		var i ListTransactionsByWalletsRow
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsWithNullFromAddress = `-- name: ListTransactionsWithNullFromAddress :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE from_address IS NULL
+  AND network = $1
+ORDER BY block_time DESC
+LIMIT $2
+`
+
+type ListTransactionsWithNullFromAddressParams struct {
+	Network    string `json:"network"`
+	LimitCount int32  `json:"limit_count"`
+}
+
+func (q *Queries) ListTransactionsWithNullFromAddress(ctx context.Context, arg ListTransactionsWithNullFromAddressParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsWithNullFromAddress, arg.Network, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransactionsByMemo = `-- name: SearchTransactionsByMemo :many
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction FROM transactions
+WHERE wallet_address = $1
+  AND network = $2
+  AND memo ILIKE $3::text
+ORDER BY block_time DESC
+LIMIT $4
+`
+
+type SearchTransactionsByMemoParams struct {
+	WalletAddress string `json:"wallet_address"`
+	Network       string `json:"network"`
+	Pattern       string `json:"pattern"`
+	LimitCount    int32  `json:"limit_count"`
+}
+
+// Transactions for a wallet whose memo matches an ILIKE pattern, newest
+// first. The pattern is served by the idx_transactions_memo_trgm trigram
+// index, so substring and prefix matches don't scan the wallet's history.
+func (q *Queries) SearchTransactionsByMemo(ctx context.Context, arg SearchTransactionsByMemoParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, searchTransactionsByMemo,
+		arg.WalletAddress,
+		arg.Network,
+		arg.Pattern,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const summarizeTransactionsByAsset = `-- name: SummarizeTransactionsByAsset :many
+SELECT
+    network,
+    COALESCE(token_mint, 'sol')::text AS asset,
+    COUNT(*)::bigint AS count,
+    (COUNT(*) FILTER (WHERE direction <> 'out'))::bigint AS received_count,
+    COALESCE(SUM(amount) FILTER (WHERE direction <> 'out'), 0)::text AS received_amount
+FROM transactions
+GROUP BY network, COALESCE(token_mint, 'sol')
+ORDER BY network, asset
+`
+
+type SummarizeTransactionsByAssetRow struct {
+	Network        string `json:"network"`
+	Asset          string `json:"asset"`
+	Count          int64  `json:"count"`
+	ReceivedCount  int64  `json:"received_count"`
+	ReceivedAmount string `json:"received_amount"`
+}
+
+// Stored transactions per network and asset ('sol' or a token mint), with
+// how many were received (direction other than 'out') and their summed
+// amount in base units. The sum is text because it can exceed bigint.
+func (q *Queries) SummarizeTransactionsByAsset(ctx context.Context) ([]SummarizeTransactionsByAssetRow, error) {
+	rows, err := q.db.Query(ctx, summarizeTransactionsByAsset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SummarizeTransactionsByAssetRow
+	for rows.Next() {
+		var i SummarizeTransactionsByAssetRow
+		if err := rows.Scan(
+			&i.Network,
+			&i.Asset,
+			&i.Count,
+			&i.ReceivedCount,
+			&i.ReceivedAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTransactionFromAddress = `-- name: UpdateTransactionFromAddress :exec
+UPDATE transactions
+SET from_address = $1
+WHERE signature = $2
+  AND network = $3
+`
+
+type UpdateTransactionFromAddressParams struct {
+	FromAddress pgtype.Text `json:"from_address"`
+	Signature   string      `json:"signature"`
+	Network     string      `json:"network"`
+}
+
+func (q *Queries) UpdateTransactionFromAddress(ctx context.Context, arg UpdateTransactionFromAddressParams) error {
+	_, err := q.db.Exec(ctx, updateTransactionFromAddress, arg.FromAddress, arg.Signature, arg.Network)
+	return err
+}
+
+const updateTransactionMetadata = `-- name: UpdateTransactionMetadata :one
+UPDATE transactions
+SET metadata = $1
+WHERE signature = $2
+  AND network = $3
+RETURNING signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction
+`
+
+type UpdateTransactionMetadataParams struct {
+	Metadata  []byte `json:"metadata"`
+	Signature string `json:"signature"`
+	Network   string `json:"network"`
+}
+
+func (q *Queries) UpdateTransactionMetadata(ctx context.Context, arg UpdateTransactionMetadataParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, updateTransactionMetadata, arg.Metadata, arg.Signature, arg.Network)
+	var i Transaction
+	err := row.Scan(
+		&i.Signature,
+		&i.WalletAddress,
+		&i.Slot,
+		&i.BlockTime,
+		&i.Amount,
+		&i.TokenMint,
+		&i.Memo,
+		&i.ConfirmationStatus,
+		&i.CreatedAt,
+		&i.FromAddress,
+		&i.Network,
+		&i.Metadata,
+		&i.Fee,
+		&i.MemoTruncated,
+		&i.PaymentID,
+		&i.DuplicateLogical,
+		&i.Direction,
+	)
+	return i, err
+}
+
+const updateTransactionStatus = `-- name: UpdateTransactionStatus :many
+UPDATE transactions
+SET confirmation_status = $1
+WHERE signature = $2
+  AND network = $3
+RETURNING signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction
+`
+
+type UpdateTransactionStatusParams struct {
+	ConfirmationStatus string `json:"confirmation_status"`
+	Signature          string `json:"signature"`
+	Network            string `json:"network"`
+}
+
+// Status belongs to the transaction, so every wallet's row for it is updated.
+func (q *Queries) UpdateTransactionStatus(ctx context.Context, arg UpdateTransactionStatusParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, updateTransactionStatus, arg.ConfirmationStatus, arg.Signature, arg.Network)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.Signature,
+			&i.WalletAddress,
+			&i.Slot,
+			&i.BlockTime,
+			&i.Amount,
+			&i.TokenMint,
+			&i.Memo,
+			&i.ConfirmationStatus,
+			&i.CreatedAt,
+			&i.FromAddress,
+			&i.Network,
+			&i.Metadata,
+			&i.Fee,
+			&i.MemoTruncated,
+			&i.PaymentID,
+			&i.DuplicateLogical,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
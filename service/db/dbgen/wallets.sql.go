@@ -11,6 +11,48 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countWalletsByStatus = `-- name: CountWalletsByStatus :many
+SELECT network, asset_type, status, COUNT(*)::bigint AS count
+FROM wallets
+WHERE deleted_at IS NULL
+GROUP BY network, asset_type, status
+ORDER BY network, asset_type, status
+`
+
+type CountWalletsByStatusRow struct {
+	Network   string `json:"network"`
+	AssetType string `json:"asset_type"`
+	Status    string `json:"status"`
+	Count     int64  `json:"count"`
+}
+
+// Registered (not unregistered) wallet assets per network, asset type and
+// status.
+func (q *Queries) CountWalletsByStatus(ctx context.Context) ([]CountWalletsByStatusRow, error) {
+	rows, err := q.db.Query(ctx, countWalletsByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountWalletsByStatusRow
+	for rows.Next() {
+		var i CountWalletsByStatusRow
+		if err := rows.Scan(
+			&i.Network,
+			&i.AssetType,
+			&i.Status,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createWallet = `-- name: CreateWallet :one
 INSERT INTO wallets (
     address,
@@ -18,11 +60,14 @@ INSERT INTO wallets (
     asset_type,
     token_mint,
     associated_token_address,
-    status
+    status,
+    metadata,
+    tags
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7,
+    COALESCE($8::jsonb, '[]'::jsonb)
 )
-RETURNING address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address
+RETURNING address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address, metadata, deleted_at, tags
 `
 
 type CreateWalletParams struct {
@@ -32,6 +77,8 @@ type CreateWalletParams struct {
 	TokenMint              string      `json:"token_mint"`
 	AssociatedTokenAddress pgtype.Text `json:"associated_token_address"`
 	Status                 string      `json:"status"`
+	Metadata               []byte      `json:"metadata"`
+	Tags                   []byte      `json:"tags"`
 }
 
 func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error) {
@@ -42,6 +89,8 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		arg.TokenMint,
 		arg.AssociatedTokenAddress,
 		arg.Status,
+		arg.Metadata,
+		arg.Tags,
 	)
 	var i Wallet
 	err := row.Scan(
@@ -53,6 +102,9 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		&i.AssetType,
 		&i.TokenMint,
 		&i.AssociatedTokenAddress,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -80,7 +132,7 @@ func (q *Queries) DeleteWallet(ctx context.Context, arg DeleteWalletParams) erro
 }
 
 const getWallet = `-- name: GetWallet :one
-SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address FROM wallets
+SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address, metadata, deleted_at, tags FROM wallets
 WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4
 `
 
@@ -108,14 +160,17 @@ func (q *Queries) GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, e
 		&i.AssetType,
 		&i.TokenMint,
 		&i.AssociatedTokenAddress,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.Tags,
 	)
 	return i, err
 }
 
 const listActiveWallets = `-- name: ListActiveWallets :many
-SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address FROM wallets
-WHERE status = 'active'
-ORDER BY created_at DESC
+SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address, metadata, deleted_at, tags FROM wallets
+WHERE status = 'active' AND deleted_at IS NULL
+ORDER BY created_at, address, network, asset_type, token_mint
 `
 
 func (q *Queries) ListActiveWallets(ctx context.Context) ([]Wallet, error) {
@@ -136,6 +191,9 @@ func (q *Queries) ListActiveWallets(ctx context.Context) ([]Wallet, error) {
 			&i.AssetType,
 			&i.TokenMint,
 			&i.AssociatedTokenAddress,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -148,18 +206,19 @@ func (q *Queries) ListActiveWallets(ctx context.Context) ([]Wallet, error) {
 }
 
 const listWalletAssets = `-- name: ListWalletAssets :many
-SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address FROM wallets
-WHERE address = $1 AND network = $2
+SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address, metadata, deleted_at, tags FROM wallets
+WHERE address = $1 AND network = $2 AND ($3::boolean OR deleted_at IS NULL)
 ORDER BY asset_type, token_mint
 `
 
 type ListWalletAssetsParams struct {
-	Address string `json:"address"`
-	Network string `json:"network"`
+	Address        string `json:"address"`
+	Network        string `json:"network"`
+	IncludeDeleted bool   `json:"include_deleted"`
 }
 
 func (q *Queries) ListWalletAssets(ctx context.Context, arg ListWalletAssetsParams) ([]Wallet, error) {
-	rows, err := q.db.Query(ctx, listWalletAssets, arg.Address, arg.Network)
+	rows, err := q.db.Query(ctx, listWalletAssets, arg.Address, arg.Network, arg.IncludeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +235,9 @@ func (q *Queries) ListWalletAssets(ctx context.Context, arg ListWalletAssetsPara
 			&i.AssetType,
 			&i.TokenMint,
 			&i.AssociatedTokenAddress,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -188,12 +250,22 @@ func (q *Queries) ListWalletAssets(ctx context.Context, arg ListWalletAssetsPara
 }
 
 const listWallets = `-- name: ListWallets :many
-SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address FROM wallets
-ORDER BY created_at DESC
+SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address, metadata, deleted_at, tags FROM wallets
+WHERE ($1::boolean OR deleted_at IS NULL)
+  AND tags @> $2::jsonb
+ORDER BY created_at, address, network, asset_type, token_mint
 `
 
-func (q *Queries) ListWallets(ctx context.Context) ([]Wallet, error) {
-	rows, err := q.db.Query(ctx, listWallets)
+type ListWalletsParams struct {
+	IncludeDeleted bool   `json:"include_deleted"`
+	Tags           []byte `json:"tags"`
+}
+
+// @tags is a JSON array; only wallets carrying all of those tags are returned
+// ('[]' matches every wallet). Ordered oldest first with the primary key as a
+// tiebreaker so the order is total and stable for pagination.
+func (q *Queries) ListWallets(ctx context.Context, arg ListWalletsParams) ([]Wallet, error) {
+	rows, err := q.db.Query(ctx, listWallets, arg.IncludeDeleted, arg.Tags)
 	if err != nil {
 		return nil, err
 	}
@@ -210,6 +282,9 @@ func (q *Queries) ListWallets(ctx context.Context) ([]Wallet, error) {
 			&i.AssetType,
 			&i.TokenMint,
 			&i.AssociatedTokenAddress,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -222,7 +297,7 @@ func (q *Queries) ListWallets(ctx context.Context) ([]Wallet, error) {
 }
 
 const listWalletsByAddress = `-- name: ListWalletsByAddress :many
-SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address FROM wallets
+SELECT address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address, metadata, deleted_at, tags FROM wallets
 WHERE address = $1
 ORDER BY network, asset_type, token_mint
 `
@@ -245,6 +320,9 @@ func (q *Queries) ListWalletsByAddress(ctx context.Context, address string) ([]W
 			&i.AssetType,
 			&i.TokenMint,
 			&i.AssociatedTokenAddress,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -256,13 +334,129 @@ func (q *Queries) ListWalletsByAddress(ctx context.Context, address string) ([]W
 	return items, nil
 }
 
+const listWalletsByRecentActivity = `-- name: ListWalletsByRecentActivity :many
+SELECT w.address, w.status, w.created_at, w.updated_at, w.network, w.asset_type, w.token_mint, w.associated_token_address, w.metadata, w.deleted_at, w.tags, latest.block_time AS last_transaction_at, latest.signature AS last_transaction_signature
+FROM wallets w
+CROSS JOIN LATERAL (
+    SELECT t.block_time, t.signature
+    FROM transactions t
+    WHERE t.wallet_address = w.address
+      AND t.network = w.network
+      AND COALESCE(t.token_mint, '') = w.token_mint
+    ORDER BY t.block_time DESC
+    LIMIT 1
+) latest
+WHERE w.status = 'active' AND w.deleted_at IS NULL
+ORDER BY latest.block_time DESC, w.address, w.network, w.asset_type, w.token_mint
+LIMIT $1 OFFSET $2
+`
+
+type ListWalletsByRecentActivityParams struct {
+	LimitCount  int32 `json:"limit_count"`
+	OffsetCount int32 `json:"offset_count"`
+}
+
+type ListWalletsByRecentActivityRow struct {
+	Address                  string             `json:"address"`
+	Status                   string             `json:"status"`
+	CreatedAt                pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt                pgtype.Timestamptz `json:"updated_at"`
+	Network                  string             `json:"network"`
+	AssetType                string             `json:"asset_type"`
+	TokenMint                string             `json:"token_mint"`
+	AssociatedTokenAddress   pgtype.Text        `json:"associated_token_address"`
+	Metadata                 []byte             `json:"metadata"`
+	DeletedAt                pgtype.Timestamptz `json:"deleted_at"`
+	Tags                     []byte             `json:"tags"`
+	LastTransactionAt        pgtype.Timestamptz `json:"last_transaction_at"`
+	LastTransactionSignature string             `json:"last_transaction_signature"`
+}
+
+// Active wallet assets ordered by the block time of their latest stored
+// transaction in that asset, newest first. Wallets without a transaction are
+// left out. The primary key breaks ties so pagination is stable.
+func (q *Queries) ListWalletsByRecentActivity(ctx context.Context, arg ListWalletsByRecentActivityParams) ([]ListWalletsByRecentActivityRow, error) {
+	rows, err := q.db.Query(ctx, listWalletsByRecentActivity, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWalletsByRecentActivityRow
+	for rows.Next() {
+		var i ListWalletsByRecentActivityRow
+		if err := rows.Scan(
+			&i.Address,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Network,
+			&i.AssetType,
+			&i.TokenMint,
+			&i.AssociatedTokenAddress,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.Tags,
+			&i.LastTransactionAt,
+			&i.LastTransactionSignature,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedWallets = `-- name: PurgeDeletedWallets :execrows
+DELETE FROM wallets
+WHERE deleted_at IS NOT NULL AND deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedWallets(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedWallets, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteWallet = `-- name: SoftDeleteWallet :execrows
+UPDATE wallets
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4 AND deleted_at IS NULL
+`
+
+type SoftDeleteWalletParams struct {
+	Address   string `json:"address"`
+	Network   string `json:"network"`
+	AssetType string `json:"asset_type"`
+	TokenMint string `json:"token_mint"`
+}
+
+func (q *Queries) SoftDeleteWallet(ctx context.Context, arg SoftDeleteWalletParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteWallet,
+		arg.Address,
+		arg.Network,
+		arg.AssetType,
+		arg.TokenMint,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateWalletStatus = `-- name: UpdateWalletStatus :one
 UPDATE wallets
 SET
     status = $5,
     updated_at = NOW()
 WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4
-RETURNING address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address
+RETURNING address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address, metadata, deleted_at, tags
 `
 
 type UpdateWalletStatusParams struct {
@@ -291,6 +485,9 @@ func (q *Queries) UpdateWalletStatus(ctx context.Context, arg UpdateWalletStatus
 		&i.AssetType,
 		&i.TokenMint,
 		&i.AssociatedTokenAddress,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -302,16 +499,22 @@ INSERT INTO wallets (
     asset_type,
     token_mint,
     associated_token_address,
-    status
+    status,
+    metadata,
+    tags
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7,
+    COALESCE($8::jsonb, '[]'::jsonb)
 )
 ON CONFLICT (address, network, asset_type, token_mint)
 DO UPDATE SET
     associated_token_address = EXCLUDED.associated_token_address,
     status = EXCLUDED.status,
+    metadata = COALESCE(EXCLUDED.metadata, wallets.metadata),
+    tags = COALESCE($8::jsonb, wallets.tags),
+    deleted_at = NULL,
     updated_at = NOW()
-RETURNING address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address
+RETURNING address, status, created_at, updated_at, network, asset_type, token_mint, associated_token_address, metadata, deleted_at, tags
 `
 
 type UpsertWalletParams struct {
@@ -321,6 +524,8 @@ type UpsertWalletParams struct {
 	TokenMint              string      `json:"token_mint"`
 	AssociatedTokenAddress pgtype.Text `json:"associated_token_address"`
 	Status                 string      `json:"status"`
+	Metadata               []byte      `json:"metadata"`
+	Tags                   []byte      `json:"tags"`
 }
 
 func (q *Queries) UpsertWallet(ctx context.Context, arg UpsertWalletParams) (Wallet, error) {
@@ -331,6 +536,8 @@ func (q *Queries) UpsertWallet(ctx context.Context, arg UpsertWalletParams) (Wal
 		arg.TokenMint,
 		arg.AssociatedTokenAddress,
 		arg.Status,
+		arg.Metadata,
+		arg.Tags,
 	)
 	var i Wallet
 	err := row.Scan(
@@ -342,12 +549,15 @@ func (q *Queries) UpsertWallet(ctx context.Context, arg UpsertWalletParams) (Wal
 		&i.AssetType,
 		&i.TokenMint,
 		&i.AssociatedTokenAddress,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.Tags,
 	)
 	return i, err
 }
 
 const walletExists = `-- name: WalletExists :one
-SELECT EXISTS(SELECT 1 FROM wallets WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4)
+SELECT EXISTS(SELECT 1 FROM wallets WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4 AND deleted_at IS NULL)
 `
 
 type WalletExistsParams struct {
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS supported_mints;
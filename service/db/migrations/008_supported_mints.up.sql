@@ -0,0 +1,10 @@
+-- Operator-managed registry of SPL token mints that wallets may register for.
+-- The USDC mints from the environment are seeded into this table on server
+-- startup; additional mints can be added/removed via the admin API without a
+-- redeploy.
+CREATE TABLE supported_mints (
+    network VARCHAR(20) NOT NULL,
+    mint VARCHAR(44) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (network, mint)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS metadata;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS metadata;
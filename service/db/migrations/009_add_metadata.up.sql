@@ -0,0 +1,9 @@
+-- Free-form, integrator-supplied metadata. Memos are size-limited and live
+-- on-chain; these columns let clients attach structured data (e.g. an order
+-- ID) to a registration or a received payment without touching the memo.
+ALTER TABLE wallets ADD COLUMN metadata JSONB;
+
+ALTER TABLE transactions ADD COLUMN metadata JSONB;
+
+COMMENT ON COLUMN wallets.metadata IS 'Client-supplied JSON metadata attached at registration';
+COMMENT ON COLUMN transactions.metadata IS 'Client-supplied JSON metadata attached after ingestion';
@@ -0,0 +1,3 @@
+ALTER TABLE supported_mints DROP COLUMN IF EXISTS symbol;
+
+ALTER TABLE supported_mints DROP COLUMN IF EXISTS decimals;
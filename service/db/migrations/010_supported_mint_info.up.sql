@@ -0,0 +1,9 @@
+-- On-chain token info for registry mints, resolved via Helius when a mint is
+-- added so clients can render amounts without configuring decimals
+-- themselves. NULL until resolved.
+ALTER TABLE supported_mints ADD COLUMN decimals SMALLINT;
+
+ALTER TABLE supported_mints ADD COLUMN symbol TEXT;
+
+COMMENT ON COLUMN supported_mints.decimals IS 'Mint decimals from the on-chain mint account';
+COMMENT ON COLUMN supported_mints.symbol IS 'Token symbol from Metaplex metadata, if any';
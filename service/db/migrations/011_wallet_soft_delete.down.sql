@@ -0,0 +1,3 @@
+DELETE FROM wallets WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+-- Unregistering a wallet marks it deleted instead of removing the row, so the
+-- history of what was monitored is preserved. Soft-deleted wallets are not
+-- monitored and are hidden from list/get unless explicitly requested;
+-- re-registering clears the mark. Purge removes them permanently.
+ALTER TABLE wallets ADD COLUMN deleted_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN wallets.deleted_at IS 'When the wallet was unregistered; NULL while registered';
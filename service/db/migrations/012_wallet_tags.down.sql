@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_wallets_tags;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,8 @@
+-- Operator-supplied tags for grouping wallets (by customer, environment,
+-- purpose). Stored as a JSON array of strings such as "customer:acme" and
+-- filtered with containment (@>), which the GIN index serves.
+ALTER TABLE wallets ADD COLUMN tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+CREATE INDEX idx_wallets_tags ON wallets USING GIN (tags);
+
+COMMENT ON COLUMN wallets.tags IS 'Operator-supplied organizational tags (JSON array of strings)';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_memo_trgm;
+
+-- pg_trgm is left installed; other database objects may depend on it.
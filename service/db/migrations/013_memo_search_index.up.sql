@@ -0,0 +1,8 @@
+-- Trigram index on transaction memos. Memo search (substring and prefix
+-- matching with ILIKE) would otherwise scan every row for the wallet; the
+-- GIN index lets Postgres narrow candidates to rows sharing the query's
+-- trigrams. JSON memos are matched on their text, e.g. a search for
+-- "workflow_id":"abc" finds memos containing that key/value pair.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_transactions_memo_trgm ON transactions USING GIN (memo gin_trgm_ops);
@@ -0,0 +1,4 @@
+-- Index on ingestion time. Throughput queries bucket transactions by when we
+-- wrote them (created_at), not when they landed on-chain (block_time), so
+-- without this a one-day window scans every chunk in full.
+CREATE INDEX idx_transactions_created_at ON transactions(created_at DESC);
@@ -0,0 +1,6 @@
+-- Network fee paid by the transaction's fee payer, for reconciling gross
+-- receipts against net. Rows ingested before this column existed, and
+-- webhooks that omit it, record 0.
+ALTER TABLE transactions ADD COLUMN fee BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN transactions.fee IS 'Network fee in lamports paid by the fee payer (0 if unknown)';
@@ -0,0 +1,5 @@
+-- Memos longer than the server's MAX_MEMO_LENGTH are stored truncated; this
+-- flag records that the stored memo is not the full on-chain memo.
+ALTER TABLE transactions ADD COLUMN memo_truncated BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN transactions.memo_truncated IS 'True if memo was truncated to MAX_MEMO_LENGTH bytes at ingestion';
@@ -0,0 +1,14 @@
+-- Periodic transaction digests. Each subscription batches a wallet's
+-- transactions over its interval into one delivery to url. The window runs
+-- from last_delivered_at, which only advances once a digest is delivered.
+CREATE TABLE digest_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    address VARCHAR(44) NOT NULL,
+    network VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    interval_seconds INTEGER NOT NULL CHECK (interval_seconds > 0),
+    last_delivered_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_digest_subscriptions_wallet ON digest_subscriptions (address, network);
@@ -0,0 +1,5 @@
+-- Exact-memo lookup index. Payment checks look for one wallet's transaction
+-- with a given memo; the trigram index serves substring search but not
+-- equality. Memos are indexed by their md5 because a btree entry must fit in
+-- a page and memos are only bounded by MAX_MEMO_LENGTH.
+CREATE INDEX idx_transactions_memo_md5 ON transactions (wallet_address, network, md5(memo));
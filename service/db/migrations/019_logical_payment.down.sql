@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_payment_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS duplicate_logical;
+ALTER TABLE transactions DROP COLUMN IF EXISTS payment_id;
@@ -0,0 +1,11 @@
+-- Logical payment IDs. A client may put its own payment ID in a JSON memo;
+-- when it retries a send, two on-chain transactions carry the same ID. The
+-- later one is kept but flagged duplicate_logical so consumers don't credit
+-- the payment twice.
+ALTER TABLE transactions ADD COLUMN payment_id TEXT;
+ALTER TABLE transactions ADD COLUMN duplicate_logical BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_transactions_payment_id ON transactions (wallet_address, network, payment_id) WHERE payment_id IS NOT NULL;
+
+COMMENT ON COLUMN transactions.payment_id IS 'Logical payment ID read from the memo''s LOGICAL_PAYMENT_ID_FIELD, if any';
+COMMENT ON COLUMN transactions.duplicate_logical IS 'True if a transaction to the wallet with the same payment_id was already stored';
@@ -0,0 +1,31 @@
+-- Append-only audit trail of mutating API calls: who (actor) did what
+-- (action) to which wallet, and whether it succeeded. Rows are never updated
+-- or deleted; the trigger rejects both.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    wallet_address TEXT,
+    network TEXT,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    outcome TEXT NOT NULL CHECK (outcome IN ('success', 'failure')),
+    request_id TEXT NOT NULL,
+    remote_addr TEXT NOT NULL
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at DESC);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor, created_at DESC);
+CREATE INDEX idx_audit_log_wallet ON audit_log (wallet_address, created_at DESC) WHERE wallet_address IS NOT NULL;
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,2 @@
+ALTER TABLE digest_subscriptions DROP COLUMN IF EXISTS filter_id;
+DROP TABLE IF EXISTS wallet_filters;
@@ -0,0 +1,22 @@
+-- Named transaction filters, stored per wallet so SSE subscribers and digest
+-- subscriptions can reference criteria by name instead of repeating them.
+-- A NULL criterion matches every transaction; the set ones must all match.
+CREATE TABLE wallet_filters (
+    id BIGSERIAL PRIMARY KEY,
+    address VARCHAR(44) NOT NULL,
+    network VARCHAR(20) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    asset_type VARCHAR(20) CHECK (asset_type IN ('sol', 'spl-token')),
+    token_mint VARCHAR(44),
+    min_amount BIGINT CHECK (min_amount >= 0),
+    memo TEXT,
+    memo_match VARCHAR(10) NOT NULL DEFAULT 'exact' CHECK (memo_match IN ('exact', 'prefix', 'contains')),
+    jq TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (address, network, name)
+);
+
+-- A digest may apply a filter. A filter in use can't be deleted.
+ALTER TABLE digest_subscriptions
+    ADD COLUMN filter_id BIGINT REFERENCES wallet_filters (id) ON DELETE RESTRICT;
@@ -0,0 +1,15 @@
+-- The invoice issued for a payment-gated registration, keyed by its
+-- deterministic workflow ID. Concurrent registrations of the same new wallet
+-- race to insert the row; the losers read the winner's invoice, so every
+-- caller is asked to pay the same invoice and only one workflow runs.
+-- A row whose acceptance window has passed is replaced by the next claim.
+CREATE TABLE registration_invoices (
+    workflow_id TEXT PRIMARY KEY,
+    address VARCHAR(44) NOT NULL,
+    network VARCHAR(20) NOT NULL,
+    asset_type VARCHAR(20) NOT NULL,
+    token_mint VARCHAR(44) NOT NULL DEFAULT '',
+    invoice JSONB NOT NULL,
+    accept_until TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+-- Amount range index. Amount queries look for one wallet's transactions
+-- within a range of base units (e.g. every payment of exactly 10 USDC);
+-- without this they scan the wallet's whole history.
+CREATE INDEX idx_transactions_wallet_network_amount ON transactions (wallet_address, network, amount);
@@ -0,0 +1,7 @@
+-- Transfer direction. A monitored wallet can send funds as well as receive
+-- them; direction says which way a row's amount moved relative to
+-- wallet_address. amount stays a magnitude. Existing rows were all incoming.
+ALTER TABLE transactions ADD COLUMN direction TEXT NOT NULL DEFAULT 'in'
+    CHECK (direction IN ('in', 'out', 'self'));
+
+COMMENT ON COLUMN transactions.direction IS 'Transfer direction relative to wallet_address: in, out or self';
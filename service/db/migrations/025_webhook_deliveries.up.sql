@@ -0,0 +1,23 @@
+-- Outbound webhook deliveries, one row per event and source (for
+-- registration.completed callbacks, the registration's workflow ID). Every
+-- attempt updates the row, so operators can see how many attempts were made,
+-- the last response, and the outcome. The URL and payload are kept so a
+-- failed delivery can be sent again.
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    event TEXT NOT NULL,
+    source_id TEXT NOT NULL,
+    network VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('retrying', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_status_code INTEGER,
+    last_error TEXT,
+    last_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (event, source_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_status ON webhook_deliveries (status, last_attempt_at DESC);
@@ -0,0 +1,13 @@
+-- Per-transaction webhooks. Every transaction published for (address,
+-- network) is POSTed to url, signed with the subscriber's own secret.
+-- Deliveries are recorded in webhook_deliveries.
+CREATE TABLE transaction_webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    address VARCHAR(44) NOT NULL,
+    network VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transaction_webhooks_wallet ON transaction_webhooks (address, network);
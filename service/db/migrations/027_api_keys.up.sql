@@ -0,0 +1,8 @@
+-- Named API keys for the HTTP API. Only the SHA-256 of a key is stored; the
+-- key itself is printed once, when it is created.
+CREATE TABLE api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+ALTER TABLE transaction_webhooks DROP COLUMN IF EXISTS filter_id;
@@ -0,0 +1,4 @@
+-- A transaction webhook may apply a filter, like a digest subscription. A
+-- filter in use can't be deleted.
+ALTER TABLE transaction_webhooks
+    ADD COLUMN filter_id BIGINT REFERENCES wallet_filters (id) ON DELETE RESTRICT;
@@ -0,0 +1,11 @@
+-- Back to one row per signature: keep the incoming row, as ingestion did
+-- before, and drop the rest.
+DELETE FROM transactions a
+USING transactions b
+WHERE a.signature = b.signature
+  AND a.block_time = b.block_time
+  AND (a.direction = 'out', a.network, a.wallet_address) > (b.direction = 'out', b.network, b.wallet_address);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_pkey;
+ALTER TABLE transactions ADD PRIMARY KEY (signature, block_time);
+CREATE UNIQUE INDEX idx_transactions_signature_network ON transactions(signature, network, block_time);
@@ -0,0 +1,9 @@
+-- A transfer between two monitored wallets is one signature but two rows: the
+-- sender's outgoing one and the receiver's incoming one. Key rows on wallet
+-- and direction as well, so neither is dropped as a duplicate. block_time
+-- stays in the key, as TimescaleDB requires.
+ALTER TABLE transactions DROP CONSTRAINT transactions_pkey;
+DROP INDEX IF EXISTS idx_transactions_signature_network;
+
+ALTER TABLE transactions
+    ADD PRIMARY KEY (signature, network, wallet_address, direction, block_time);
@@ -0,0 +1,12 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    name,
+    key_hash
+) VALUES (
+    $1, $2
+)
+RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1;
@@ -0,0 +1,27 @@
+-- name: CreateAuditLogEntry :one
+INSERT INTO audit_log (
+    actor,
+    action,
+    wallet_address,
+    network,
+    method,
+    path,
+    status,
+    outcome,
+    request_id,
+    remote_addr
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+RETURNING *;
+
+-- name: ListAuditLog :many
+-- Entries recorded in [@start_time, @end_time), newest first. An empty
+-- @actor or @wallet_address matches every entry.
+SELECT * FROM audit_log
+WHERE created_at >= @start_time
+  AND created_at < @end_time
+  AND (@actor::text = '' OR actor = @actor::text)
+  AND (@wallet_address::text = '' OR wallet_address = @wallet_address::text)
+ORDER BY created_at DESC, id DESC
+LIMIT @limit_count;
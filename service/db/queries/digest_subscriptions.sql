@@ -0,0 +1,35 @@
+-- name: CreateDigestSubscription :one
+INSERT INTO digest_subscriptions (
+    address,
+    network,
+    url,
+    interval_seconds,
+    filter_id
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: DeleteDigestSubscription :execrows
+DELETE FROM digest_subscriptions
+WHERE id = $1;
+
+-- name: ListDigestSubscriptions :many
+-- An empty @address or @network matches every subscription.
+SELECT * FROM digest_subscriptions
+WHERE (@address::text = '' OR address = @address::text)
+  AND (@network::text = '' OR network = @network::text)
+ORDER BY id ASC;
+
+-- name: ListDueDigestSubscriptions :many
+-- Subscriptions whose interval has elapsed since their last delivery, most
+-- overdue first.
+SELECT * FROM digest_subscriptions
+WHERE last_delivered_at + make_interval(secs => interval_seconds) <= @now::timestamptz
+ORDER BY last_delivered_at ASC
+LIMIT @limit_count;
+
+-- name: MarkDigestDelivered :exec
+UPDATE digest_subscriptions
+SET last_delivered_at = $2
+WHERE id = $1;
@@ -0,0 +1,28 @@
+-- name: ClaimRegistrationInvoice :one
+-- Inserts the invoice for a workflow ID, or replaces one whose acceptance
+-- window has passed. Returns no row while an unexpired invoice exists.
+INSERT INTO registration_invoices (
+    workflow_id,
+    address,
+    network,
+    asset_type,
+    token_mint,
+    invoice,
+    accept_until
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (workflow_id) DO UPDATE SET
+    address = EXCLUDED.address,
+    network = EXCLUDED.network,
+    asset_type = EXCLUDED.asset_type,
+    token_mint = EXCLUDED.token_mint,
+    invoice = EXCLUDED.invoice,
+    accept_until = EXCLUDED.accept_until,
+    created_at = NOW()
+WHERE registration_invoices.accept_until <= NOW()
+RETURNING *;
+
+-- name: GetRegistrationInvoice :one
+SELECT * FROM registration_invoices
+WHERE workflow_id = $1;
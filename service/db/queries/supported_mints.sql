@@ -0,0 +1,29 @@
+-- name: AddSupportedMint :one
+INSERT INTO supported_mints (
+    network,
+    mint
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (network, mint)
+DO UPDATE SET network = EXCLUDED.network
+RETURNING *;
+
+-- name: RemoveSupportedMint :execrows
+DELETE FROM supported_mints
+WHERE network = $1 AND mint = $2;
+
+-- name: ListSupportedMints :many
+SELECT * FROM supported_mints
+WHERE network = $1
+ORDER BY created_at ASC, mint ASC;
+
+-- name: ListAllSupportedMints :many
+SELECT * FROM supported_mints
+ORDER BY network ASC, created_at ASC, mint ASC;
+
+-- name: SetSupportedMintInfo :one
+UPDATE supported_mints
+SET decimals = $3, symbol = $4
+WHERE network = $1 AND mint = $2
+RETURNING *;
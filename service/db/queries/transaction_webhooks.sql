@@ -0,0 +1,22 @@
+-- name: CreateTransactionWebhook :one
+INSERT INTO transaction_webhooks (
+    address,
+    network,
+    url,
+    secret,
+    filter_id
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: DeleteTransactionWebhook :execrows
+DELETE FROM transaction_webhooks
+WHERE id = $1;
+
+-- name: ListTransactionWebhooks :many
+-- An empty @address or @network matches every webhook.
+SELECT * FROM transaction_webhooks
+WHERE (@address::text = '' OR address = @address::text)
+  AND (@network::text = '' OR network = @network::text)
+ORDER BY id ASC;
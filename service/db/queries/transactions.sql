@@ -1,4 +1,6 @@
 -- name: CreateTransaction :one
+-- duplicate_logical is set when the wallet already has a transaction with the
+-- same payment_id: a retried send of one logical payment.
 INSERT INTO transactions (
     signature,
     wallet_address,
@@ -9,12 +11,101 @@ INSERT INTO transactions (
     token_mint,
     memo,
     confirmation_status,
-    from_address
+    from_address,
+    fee,
+    memo_truncated,
+    payment_id,
+    duplicate_logical,
+    direction
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+    $13::text IS NOT NULL AND EXISTS (
+        SELECT 1 FROM transactions
+        WHERE wallet_address = $2
+          AND network = $3
+          AND payment_id = $13::text
+    ),
+    $14
 )
 RETURNING *;
 
+-- name: CreateTransactionsBatch :many
+-- Inserts a batch of transactions in one round trip. Rows already stored are
+-- skipped, and only newly inserted rows are returned. Empty strings in the
+-- nullable text columns are stored as NULL. duplicate_logical also counts
+-- earlier rows of the same batch.
+WITH batch AS (
+    SELECT signature, wallet_address, network, slot, block_time, amount, token_mint, memo, confirmation_status, from_address, fee, memo_truncated, payment_id, direction, ord FROM unnest(
+        @signatures::text[],
+        @wallet_addresses::text[],
+        @networks::text[],
+        @slots::bigint[],
+        @block_times::timestamptz[],
+        @amounts::bigint[],
+        @token_mints::text[],
+        @memos::text[],
+        @confirmation_statuses::text[],
+        @from_addresses::text[],
+        @fees::bigint[],
+        @memo_truncated::boolean[],
+        @payment_ids::text[],
+        @directions::text[]
+    ) WITH ORDINALITY AS b(
+        signature, wallet_address, network, slot, block_time, amount, token_mint,
+        memo, confirmation_status, from_address, fee, memo_truncated, payment_id, direction, ord
+    )
+)
+INSERT INTO transactions (
+    signature,
+    wallet_address,
+    network,
+    slot,
+    block_time,
+    amount,
+    token_mint,
+    memo,
+    confirmation_status,
+    from_address,
+    fee,
+    memo_truncated,
+    payment_id,
+    duplicate_logical,
+    direction
+)
+SELECT
+    b.signature,
+    b.wallet_address,
+    b.network,
+    b.slot,
+    b.block_time,
+    b.amount,
+    NULLIF(b.token_mint, ''),
+    NULLIF(b.memo, ''),
+    b.confirmation_status,
+    NULLIF(b.from_address, ''),
+    b.fee,
+    b.memo_truncated,
+    NULLIF(b.payment_id, ''),
+    b.payment_id <> '' AND (
+        EXISTS (
+            SELECT 1 FROM transactions t
+            WHERE t.wallet_address = b.wallet_address
+              AND t.network = b.network
+              AND t.payment_id = b.payment_id
+        ) OR EXISTS (
+            SELECT 1 FROM batch e
+            WHERE e.ord < b.ord
+              AND e.wallet_address = b.wallet_address
+              AND e.network = b.network
+              AND e.payment_id = b.payment_id
+        )
+    ),
+    b.direction
+FROM batch b
+ORDER BY b.ord
+ON CONFLICT DO NOTHING
+RETURNING *;
+
 -- name: GetTransaction :one
 SELECT * FROM transactions
 WHERE signature = $1
@@ -22,12 +113,73 @@ WHERE signature = $1
 LIMIT 1;
 
 -- name: ListTransactionsByWallet :many
+-- Newest first; ties by signature, descending, so the order matches
+-- ListTransactionsByWalletBeforeCursor. An empty @direction matches every
+-- direction.
 SELECT * FROM transactions
-WHERE wallet_address = $1
-  AND network = $2
+WHERE wallet_address = @wallet_address
+  AND network = @network
+  AND from_address IS NOT NULL
+  AND (@direction::text = '' OR direction = @direction::text)
+ORDER BY block_time DESC, signature DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListTransactionsByWalletBeforeCursor :many
+-- The page after the (@before_block_time, @before_signature) cursor in
+-- ListTransactionsByWallet order. Keyset paging doesn't shift when new
+-- transactions arrive, unlike OFFSET. Same filter as ListTransactionsByWallet.
+SELECT * FROM transactions
+WHERE wallet_address = @wallet_address
+  AND network = @network
+  AND from_address IS NOT NULL
+  AND (@direction::text = '' OR direction = @direction::text)
+  AND (block_time, signature) < (@before_block_time::timestamptz, @before_signature::text)
+ORDER BY block_time DESC, signature DESC
+LIMIT @limit_count;
+
+-- name: ListTransactionsByWalletAmountAsc :many
+-- Smallest payments first; ties oldest first. Same filter as ListTransactionsByWallet.
+SELECT * FROM transactions
+WHERE wallet_address = @wallet_address
+  AND network = @network
+  AND from_address IS NOT NULL
+  AND (@direction::text = '' OR direction = @direction::text)
+ORDER BY amount ASC, block_time ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListTransactionsByWalletAmountDesc :many
+-- Largest payments first; ties newest first. Same filter as ListTransactionsByWallet.
+SELECT * FROM transactions
+WHERE wallet_address = @wallet_address
+  AND network = @network
+  AND from_address IS NOT NULL
+  AND (@direction::text = '' OR direction = @direction::text)
+ORDER BY amount DESC, block_time DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListTransactionsByWalletBlockTimeAsc :many
+-- Chronological order (oldest first). Same filter as ListTransactionsByWallet.
+SELECT * FROM transactions
+WHERE wallet_address = @wallet_address
+  AND network = @network
   AND from_address IS NOT NULL
+  AND (@direction::text = '' OR direction = @direction::text)
+ORDER BY block_time ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListTransactionsByAmountRange :many
+-- A wallet's transactions in one asset whose amount is within
+-- [@min_amount, @max_amount], newest first. @asset is 'sol' for native
+-- transfers or a token mint; amounts are in that asset's base units. The
+-- range is served by idx_transactions_wallet_network_amount.
+SELECT * FROM transactions
+WHERE wallet_address = @wallet_address
+  AND network = @network
+  AND amount >= @min_amount::bigint
+  AND amount <= @max_amount::bigint
+  AND COALESCE(token_mint, 'sol') = @asset::text
 ORDER BY block_time DESC
-LIMIT $3 OFFSET $4;
+LIMIT @limit_count OFFSET @offset_count;
 
 -- name: ListTransactionsByWalletAndTimeRange :many
 SELECT * FROM transactions
@@ -37,11 +189,78 @@ WHERE wallet_address = $1
   AND block_time <= $4
 ORDER BY block_time DESC;
 
+-- name: ListTransactionsByWallets :many
+-- Most recent transactions for several (wallet_address, network) pairs in one
+-- round trip, capped per wallet so a busy wallet can't crowd out the rest.
+SELECT signature, wallet_address, slot, block_time, amount, token_mint, memo, confirmation_status, created_at, from_address, network, metadata, fee, memo_truncated, payment_id, duplicate_logical, direction
+FROM (
+    SELECT *,
+        ROW_NUMBER() OVER (PARTITION BY wallet_address, network ORDER BY block_time DESC) AS row_num
+    FROM transactions
+    WHERE (wallet_address, network) IN (
+        SELECT UNNEST(@wallet_addresses::text[]), UNNEST(@networks::text[])
+    )
+      AND from_address IS NOT NULL
+      AND block_time >= @start_time::timestamptz
+      AND block_time <= @end_time::timestamptz
+) ranked
+WHERE row_num <= @limit_per_wallet::int
+ORDER BY wallet_address, network, block_time DESC;
+
 -- name: CountTransactionsByWallet :one
 SELECT COUNT(*) FROM transactions
 WHERE wallet_address = $1
   AND network = $2;
 
+-- name: CountTransactionsByTimeBucket :many
+-- Transactions written (by created_at) per time bucket across all wallets,
+-- optionally limited to one network. Empty buckets are included with a count
+-- of zero so ingestion gaps show up as flat periods.
+SELECT buckets.bucket::timestamptz AS bucket, COALESCE(counts.count, 0)::bigint AS count
+FROM generate_series(
+    time_bucket(@bucket_width::interval, @start_time::timestamptz),
+    @end_time::timestamptz - INTERVAL '1 microsecond',
+    @bucket_width::interval
+) AS buckets(bucket)
+LEFT JOIN (
+    SELECT time_bucket(@bucket_width::interval, created_at) AS bucket, COUNT(*) AS count
+    FROM transactions
+    WHERE created_at >= @start_time::timestamptz
+      AND created_at < @end_time::timestamptz
+      AND (@network::text = '' OR network = @network::text)
+    GROUP BY 1
+) counts ON counts.bucket = buckets.bucket
+ORDER BY buckets.bucket;
+
+-- name: GetIngestionLatency :one
+-- Delay between block time and write (created_at) for transactions written
+-- in [@start_time, @end_time), optionally limited to one network and/or
+-- wallet. Percentiles and max are in seconds, and 0 when nothing was written.
+SELECT
+    COUNT(*)::bigint AS count,
+    COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM created_at - block_time)), 0)::float8 AS p50_seconds,
+    COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM created_at - block_time)), 0)::float8 AS p99_seconds,
+    COALESCE(MAX(EXTRACT(EPOCH FROM created_at - block_time)), 0)::float8 AS max_seconds
+FROM transactions
+WHERE created_at >= @start_time::timestamptz
+  AND created_at < @end_time::timestamptz
+  AND (@network::text = '' OR network = @network::text)
+  AND (@wallet_address::text = '' OR wallet_address = @wallet_address::text);
+
+-- name: SummarizeTransactionsByAsset :many
+-- Stored transactions per network and asset ('sol' or a token mint), with
+-- how many were received (direction other than 'out') and their summed
+-- amount in base units. The sum is text because it can exceed bigint.
+SELECT
+    network,
+    COALESCE(token_mint, 'sol')::text AS asset,
+    COUNT(*)::bigint AS count,
+    (COUNT(*) FILTER (WHERE direction <> 'out'))::bigint AS received_count,
+    COALESCE(SUM(amount) FILTER (WHERE direction <> 'out'), 0)::text AS received_amount
+FROM transactions
+GROUP BY network, COALESCE(token_mint, 'sol')
+ORDER BY network, asset;
+
 -- name: GetLatestTransactionByWallet :one
 SELECT * FROM transactions
 WHERE wallet_address = $1
@@ -73,8 +292,58 @@ WHERE from_address IS NULL
 ORDER BY block_time DESC
 LIMIT @limit_count;
 
+-- name: SearchTransactionsByMemo :many
+-- Transactions for a wallet whose memo matches an ILIKE pattern, newest
+-- first. The pattern is served by the idx_transactions_memo_trgm trigram
+-- index, so substring and prefix matches don't scan the wallet's history.
+SELECT * FROM transactions
+WHERE wallet_address = @wallet_address
+  AND network = @network
+  AND memo ILIKE @pattern::text
+ORDER BY block_time DESC
+LIMIT @limit_count;
+
+-- name: FindPaymentByMemo :one
+-- The earliest transaction to a wallet whose memo is exactly @memo and whose
+-- amount is at least @min_amount. An empty @asset matches any asset, 'sol'
+-- native transfers, and a token mint that token. Outgoing transfers are not
+-- payments to the wallet and never match. The md5 comparison uses the
+-- idx_transactions_memo_md5 index; the memo comparison rules out collisions.
+SELECT * FROM transactions
+WHERE wallet_address = @wallet_address
+  AND network = @network
+  AND direction <> 'out'
+  AND md5(memo) = md5(@memo::text)
+  AND memo = @memo::text
+  AND amount >= @min_amount::bigint
+  AND (@asset::text = '' OR COALESCE(token_mint, 'sol') = @asset::text)
+ORDER BY block_time ASC
+LIMIT 1;
+
 -- name: UpdateTransactionFromAddress :exec
 UPDATE transactions
 SET from_address = $1
 WHERE signature = $2
   AND network = $3;
+
+-- name: UpdateTransactionMetadata :one
+UPDATE transactions
+SET metadata = $1
+WHERE signature = $2
+  AND network = $3
+RETURNING *;
+
+-- name: ListTransactionsByConfirmationStatus :many
+SELECT * FROM transactions
+WHERE confirmation_status = $1
+  AND network = $2
+ORDER BY block_time ASC
+LIMIT $3;
+
+-- name: UpdateTransactionStatus :many
+-- Status belongs to the transaction, so every wallet's row for it is updated.
+UPDATE transactions
+SET confirmation_status = $1
+WHERE signature = $2
+  AND network = $3
+RETURNING *;
@@ -0,0 +1,42 @@
+-- name: DeleteWalletFilter :execrows
+DELETE FROM wallet_filters
+WHERE address = $1 AND network = $2 AND name = $3;
+
+-- name: GetWalletFilter :one
+SELECT * FROM wallet_filters
+WHERE address = $1 AND network = $2 AND name = $3;
+
+-- name: GetWalletFilterByID :one
+SELECT * FROM wallet_filters
+WHERE id = $1;
+
+-- name: ListWalletFilters :many
+SELECT * FROM wallet_filters
+WHERE address = $1 AND network = $2
+ORDER BY name ASC;
+
+-- name: SaveWalletFilter :one
+-- Creates the named filter, or replaces its criteria if it exists. The ID
+-- is kept, so digests using the filter pick up the new criteria.
+INSERT INTO wallet_filters (
+    address,
+    network,
+    name,
+    asset_type,
+    token_mint,
+    min_amount,
+    memo,
+    memo_match,
+    jq
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (address, network, name) DO UPDATE SET
+    asset_type = EXCLUDED.asset_type,
+    token_mint = EXCLUDED.token_mint,
+    min_amount = EXCLUDED.min_amount,
+    memo = EXCLUDED.memo,
+    memo_match = EXCLUDED.memo_match,
+    jq = EXCLUDED.jq,
+    updated_at = NOW()
+RETURNING *;
@@ -5,9 +5,12 @@ INSERT INTO wallets (
     asset_type,
     token_mint,
     associated_token_address,
-    status
+    status,
+    metadata,
+    tags
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    @address, @network, @asset_type, @token_mint, @associated_token_address, @status, @metadata,
+    COALESCE(sqlc.narg('tags')::jsonb, '[]'::jsonb)
 )
 RETURNING *;
 
@@ -18,14 +21,20 @@ INSERT INTO wallets (
     asset_type,
     token_mint,
     associated_token_address,
-    status
+    status,
+    metadata,
+    tags
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    @address, @network, @asset_type, @token_mint, @associated_token_address, @status, @metadata,
+    COALESCE(sqlc.narg('tags')::jsonb, '[]'::jsonb)
 )
 ON CONFLICT (address, network, asset_type, token_mint)
 DO UPDATE SET
     associated_token_address = EXCLUDED.associated_token_address,
     status = EXCLUDED.status,
+    metadata = COALESCE(EXCLUDED.metadata, wallets.metadata),
+    tags = COALESCE(sqlc.narg('tags')::jsonb, wallets.tags),
+    deleted_at = NULL,
     updated_at = NOW()
 RETURNING *;
 
@@ -34,13 +43,46 @@ SELECT * FROM wallets
 WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4;
 
 -- name: ListWallets :many
+-- @tags is a JSON array; only wallets carrying all of those tags are returned
+-- ('[]' matches every wallet). Ordered oldest first with the primary key as a
+-- tiebreaker so the order is total and stable for pagination.
 SELECT * FROM wallets
-ORDER BY created_at DESC;
+WHERE (@include_deleted::boolean OR deleted_at IS NULL)
+  AND tags @> @tags::jsonb
+ORDER BY created_at, address, network, asset_type, token_mint;
 
 -- name: ListActiveWallets :many
 SELECT * FROM wallets
-WHERE status = 'active'
-ORDER BY created_at DESC;
+WHERE status = 'active' AND deleted_at IS NULL
+ORDER BY created_at, address, network, asset_type, token_mint;
+
+-- name: ListWalletsByRecentActivity :many
+-- Active wallet assets ordered by the block time of their latest stored
+-- transaction in that asset, newest first. Wallets without a transaction are
+-- left out. The primary key breaks ties so pagination is stable.
+SELECT w.*, latest.block_time AS last_transaction_at, latest.signature AS last_transaction_signature
+FROM wallets w
+CROSS JOIN LATERAL (
+    SELECT t.block_time, t.signature
+    FROM transactions t
+    WHERE t.wallet_address = w.address
+      AND t.network = w.network
+      AND COALESCE(t.token_mint, '') = w.token_mint
+    ORDER BY t.block_time DESC
+    LIMIT 1
+) latest
+WHERE w.status = 'active' AND w.deleted_at IS NULL
+ORDER BY latest.block_time DESC, w.address, w.network, w.asset_type, w.token_mint
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: CountWalletsByStatus :many
+-- Registered (not unregistered) wallet assets per network, asset type and
+-- status.
+SELECT network, asset_type, status, COUNT(*)::bigint AS count
+FROM wallets
+WHERE deleted_at IS NULL
+GROUP BY network, asset_type, status
+ORDER BY network, asset_type, status;
 
 -- name: UpdateWalletStatus :one
 UPDATE wallets
@@ -54,8 +96,19 @@ RETURNING *;
 DELETE FROM wallets
 WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4;
 
+-- name: SoftDeleteWallet :execrows
+UPDATE wallets
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4 AND deleted_at IS NULL;
+
+-- name: PurgeDeletedWallets :execrows
+DELETE FROM wallets
+WHERE deleted_at IS NOT NULL AND deleted_at < $1;
+
 -- name: WalletExists :one
-SELECT EXISTS(SELECT 1 FROM wallets WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4);
+SELECT EXISTS(SELECT 1 FROM wallets WHERE address = $1 AND network = $2 AND asset_type = $3 AND token_mint = $4 AND deleted_at IS NULL);
 
 -- name: ListWalletsByAddress :many
 SELECT * FROM wallets
@@ -64,5 +117,5 @@ ORDER BY network, asset_type, token_mint;
 
 -- name: ListWalletAssets :many
 SELECT * FROM wallets
-WHERE address = $1 AND network = $2
+WHERE address = @address AND network = @network AND (@include_deleted::boolean OR deleted_at IS NULL)
 ORDER BY asset_type, token_mint;
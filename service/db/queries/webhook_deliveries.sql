@@ -0,0 +1,52 @@
+-- name: RecordWebhookDeliveryAttempt :one
+-- Records one delivery attempt, creating the delivery on its first attempt.
+-- Later attempts for the same event and source, redeliveries included,
+-- update the row. delivered_at is the last successful attempt.
+INSERT INTO webhook_deliveries (
+    event,
+    source_id,
+    network,
+    url,
+    payload,
+    status,
+    attempts,
+    last_status_code,
+    last_error,
+    last_attempt_at,
+    delivered_at
+) VALUES (
+    @event,
+    @source_id,
+    @network,
+    @url,
+    @payload,
+    @status,
+    1,
+    @last_status_code,
+    @last_error,
+    NOW(),
+    CASE WHEN @status::text = 'delivered' THEN NOW() END
+)
+ON CONFLICT (event, source_id) DO UPDATE SET
+    network = EXCLUDED.network,
+    url = EXCLUDED.url,
+    payload = EXCLUDED.payload,
+    status = EXCLUDED.status,
+    attempts = webhook_deliveries.attempts + 1,
+    last_status_code = EXCLUDED.last_status_code,
+    last_error = EXCLUDED.last_error,
+    last_attempt_at = EXCLUDED.last_attempt_at,
+    delivered_at = COALESCE(EXCLUDED.delivered_at, webhook_deliveries.delivered_at)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+-- Deliveries by most recent attempt, newest first. An empty @status matches
+-- every delivery.
+SELECT * FROM webhook_deliveries
+WHERE (@status::text = '' OR status = @status::text)
+ORDER BY last_attempt_at DESC, id DESC
+LIMIT @limit_count OFFSET @offset_count;
@@ -2,9 +2,17 @@ package db
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/brojonat/forohtoo/service/db/dbgen"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -29,7 +37,7 @@ func NewStore(pool *pgxpool.Pool) *Store {
 type Transaction struct {
 	Signature          string
 	WalletAddress      string
-	Network            string  // "mainnet" or "devnet"
+	Network            string // "mainnet" or "devnet"
 	Slot               int64
 	BlockTime          time.Time
 	Amount             int64
@@ -37,9 +45,26 @@ type Transaction struct {
 	Memo               *string
 	ConfirmationStatus string
 	CreatedAt          time.Time
-	FromAddress        *string // source wallet (sender)
+	FromAddress        *string         // source wallet (sender)
+	Metadata           json.RawMessage // client-supplied; nil if never set
+	Fee                int64           // network fee in lamports; 0 if unknown
+	MemoTruncated      bool            // Memo was cut to the server's MAX_MEMO_LENGTH
+	PaymentID          *string         // logical payment ID from the memo; nil if none
+	DuplicateLogical   bool            // an earlier transaction to the wallet had the same PaymentID
+	Direction          string          // DirectionIn, DirectionOut or DirectionSelf, relative to WalletAddress
 }
 
+// Transfer directions, relative to the transaction's wallet. Amount is always
+// a magnitude; Direction says which way it moved.
+const (
+	DirectionIn   = "in"   // received from another account
+	DirectionOut  = "out"  // sent to another account
+	DirectionSelf = "self" // sent by the wallet to itself
+)
+
+// Directions lists the supported transfer directions.
+var Directions = []string{DirectionIn, DirectionOut, DirectionSelf}
+
 // CreateTransactionParams contains the parameters for creating a transaction.
 type CreateTransactionParams struct {
 	Signature          string
@@ -52,14 +77,51 @@ type CreateTransactionParams struct {
 	Memo               *string
 	ConfirmationStatus string
 	FromAddress        *string
+	Fee                int64   // network fee in lamports; 0 if unknown
+	MemoTruncated      bool    // Memo was cut to the server's MAX_MEMO_LENGTH
+	PaymentID          *string // logical payment ID; the write flags DuplicateLogical if the wallet already has it
+	Direction          string  // empty means DirectionIn
 }
 
-// ListTransactionsByWalletParams contains pagination parameters.
+// TransactionSort orders ListTransactionsByWallet results.
+type TransactionSort string
+
+const (
+	SortBlockTimeDesc TransactionSort = "block_time_desc" // newest first (default)
+	SortBlockTimeAsc  TransactionSort = "block_time_asc"
+	SortAmountDesc    TransactionSort = "amount_desc"
+	SortAmountAsc     TransactionSort = "amount_asc"
+)
+
+// TransactionSorts lists the supported orderings.
+var TransactionSorts = []TransactionSort{SortBlockTimeDesc, SortBlockTimeAsc, SortAmountDesc, SortAmountAsc}
+
+// ListTransactionsByWalletParams contains pagination parameters. An empty
+// Sort means SortBlockTimeDesc; an empty Direction matches every direction.
 type ListTransactionsByWalletParams struct {
 	WalletAddress string
 	Network       string
+	Direction     string
 	Limit         int32
 	Offset        int32
+	Sort          TransactionSort
+}
+
+// TransactionCursor is the position of a transaction in newest-first order.
+// Ties on BlockTime are broken by Signature, descending.
+type TransactionCursor struct {
+	BlockTime time.Time
+	Signature string
+}
+
+// ListTransactionsByWalletBeforeCursorParams selects the page after Before in
+// newest-first order. An empty Direction matches every direction.
+type ListTransactionsByWalletBeforeCursorParams struct {
+	WalletAddress string
+	Network       string
+	Direction     string
+	Before        TransactionCursor
+	Limit         int32
 }
 
 // ListTransactionsByWalletAndTimeRangeParams contains time range query parameters.
@@ -84,6 +146,10 @@ func (s *Store) CreateTransaction(ctx context.Context, params CreateTransactionP
 		Memo:               pgtextFromStringPtr(params.Memo),
 		ConfirmationStatus: params.ConfirmationStatus,
 		FromAddress:        pgtextFromStringPtr(params.FromAddress),
+		Fee:                params.Fee,
+		MemoTruncated:      params.MemoTruncated,
+		PaymentID:          pgtextFromStringPtr(params.PaymentID),
+		Direction:          transactionDirection(params.Direction),
 	}
 
 	result, err := s.q.CreateTransaction(ctx, sqlcParams)
@@ -94,6 +160,63 @@ func (s *Store) CreateTransaction(ctx context.Context, params CreateTransactionP
 	return dbTransactionToDomain(&result), nil
 }
 
+// CreateTransactions writes a batch of transactions in one round trip and
+// returns the newly stored ones. Transactions already stored (or repeated
+// within the batch) are skipped rather than failing the batch, so
+// len(params) minus the number returned is the skipped count. Empty-string
+// TokenMint, Memo, FromAddress and PaymentID values are stored as NULL.
+func (s *Store) CreateTransactions(ctx context.Context, params []CreateTransactionParams) ([]*Transaction, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	n := len(params)
+	sqlcParams := dbgen.CreateTransactionsBatchParams{
+		Signatures:           make([]string, n),
+		WalletAddresses:      make([]string, n),
+		Networks:             make([]string, n),
+		Slots:                make([]int64, n),
+		BlockTimes:           make([]pgtype.Timestamptz, n),
+		Amounts:              make([]int64, n),
+		TokenMints:           make([]string, n),
+		Memos:                make([]string, n),
+		ConfirmationStatuses: make([]string, n),
+		FromAddresses:        make([]string, n),
+		Fees:                 make([]int64, n),
+		MemoTruncated:        make([]bool, n),
+		PaymentIds:           make([]string, n),
+		Directions:           make([]string, n),
+	}
+	for i, p := range params {
+		sqlcParams.Signatures[i] = p.Signature
+		sqlcParams.WalletAddresses[i] = p.WalletAddress
+		sqlcParams.Networks[i] = p.Network
+		sqlcParams.Slots[i] = p.Slot
+		sqlcParams.BlockTimes[i] = pgtype.Timestamptz{Time: p.BlockTime, Valid: true}
+		sqlcParams.Amounts[i] = p.Amount
+		sqlcParams.TokenMints[i] = stringFromPtr(p.TokenMint)
+		sqlcParams.Memos[i] = stringFromPtr(p.Memo)
+		sqlcParams.ConfirmationStatuses[i] = p.ConfirmationStatus
+		sqlcParams.FromAddresses[i] = stringFromPtr(p.FromAddress)
+		sqlcParams.Fees[i] = p.Fee
+		sqlcParams.MemoTruncated[i] = p.MemoTruncated
+		sqlcParams.PaymentIds[i] = stringFromPtr(p.PaymentID)
+		sqlcParams.Directions[i] = transactionDirection(p.Direction)
+	}
+
+	results, err := s.q.CreateTransactionsBatch(ctx, sqlcParams)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, len(results))
+	for i, result := range results {
+		transactions[i] = dbTransactionToDomain(&result)
+	}
+
+	return transactions, nil
+}
+
 // GetTransaction retrieves a transaction by its signature and network.
 func (s *Store) GetTransaction(ctx context.Context, signature string, network string) (*Transaction, error) {
 	params := dbgen.GetTransactionParams{
@@ -113,11 +236,51 @@ func (s *Store) ListTransactionsByWallet(ctx context.Context, params ListTransac
 	sqlcParams := dbgen.ListTransactionsByWalletParams{
 		WalletAddress: params.WalletAddress,
 		Network:       params.Network,
-		Limit:         params.Limit,
-		Offset:        params.Offset,
+		Direction:     params.Direction,
+		LimitCount:    params.Limit,
+		OffsetCount:   params.Offset,
 	}
 
-	results, err := s.q.ListTransactionsByWallet(ctx, sqlcParams)
+	// One query per ordering keeps ORDER BY out of user input.
+	var results []dbgen.Transaction
+	var err error
+	switch params.Sort {
+	case "", SortBlockTimeDesc:
+		results, err = s.q.ListTransactionsByWallet(ctx, sqlcParams)
+	case SortBlockTimeAsc:
+		results, err = s.q.ListTransactionsByWalletBlockTimeAsc(ctx, dbgen.ListTransactionsByWalletBlockTimeAscParams(sqlcParams))
+	case SortAmountDesc:
+		results, err = s.q.ListTransactionsByWalletAmountDesc(ctx, dbgen.ListTransactionsByWalletAmountDescParams(sqlcParams))
+	case SortAmountAsc:
+		results, err = s.q.ListTransactionsByWalletAmountAsc(ctx, dbgen.ListTransactionsByWalletAmountAscParams(sqlcParams))
+	default:
+		return nil, fmt.Errorf("unsupported transaction sort %q", params.Sort)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, len(results))
+	for i, result := range results {
+		transactions[i] = dbTransactionToDomain(&result)
+	}
+
+	return transactions, nil
+}
+
+// ListTransactionsByWalletBeforeCursor retrieves the transactions that follow
+// params.Before in newest-first order. Unlike Offset, the cursor stays put
+// while new transactions arrive, so paging through a wallet's history never
+// skips or repeats one.
+func (s *Store) ListTransactionsByWalletBeforeCursor(ctx context.Context, params ListTransactionsByWalletBeforeCursorParams) ([]*Transaction, error) {
+	results, err := s.q.ListTransactionsByWalletBeforeCursor(ctx, dbgen.ListTransactionsByWalletBeforeCursorParams{
+		WalletAddress:   params.WalletAddress,
+		Network:         params.Network,
+		Direction:       params.Direction,
+		BeforeBlockTime: pgtype.Timestamptz{Time: params.Before.BlockTime, Valid: true},
+		BeforeSignature: params.Before.Signature,
+		LimitCount:      params.Limit,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -152,6 +315,52 @@ func (s *Store) ListTransactionsByWalletAndTimeRange(ctx context.Context, params
 	return transactions, nil
 }
 
+// WalletKey identifies a monitored wallet on a network.
+type WalletKey struct {
+	Address string
+	Network string
+}
+
+// ListTransactionsByWalletsParams contains parameters for a multi-wallet query.
+type ListTransactionsByWalletsParams struct {
+	Wallets        []WalletKey
+	StartTime      time.Time
+	EndTime        time.Time
+	LimitPerWallet int32
+}
+
+// ListTransactionsByWallets retrieves the most recent transactions within a
+// time range for several wallets in a single query, returning at most
+// LimitPerWallet per wallet. Results are ordered by wallet, then newest first.
+func (s *Store) ListTransactionsByWallets(ctx context.Context, params ListTransactionsByWalletsParams) ([]*Transaction, error) {
+	addresses := make([]string, len(params.Wallets))
+	networks := make([]string, len(params.Wallets))
+	for i, w := range params.Wallets {
+		addresses[i] = w.Address
+		networks[i] = w.Network
+	}
+
+	results, err := s.q.ListTransactionsByWallets(ctx, dbgen.ListTransactionsByWalletsParams{
+		WalletAddresses: addresses,
+		Networks:        networks,
+		StartTime:       pgtype.Timestamptz{Time: params.StartTime, Valid: true},
+		EndTime:         pgtype.Timestamptz{Time: params.EndTime, Valid: true},
+		LimitPerWallet:  params.LimitPerWallet,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, len(results))
+	for i, result := range results {
+		// The row has the same columns as a transaction.
+		t := dbgen.Transaction(result)
+		transactions[i] = dbTransactionToDomain(&t)
+	}
+
+	return transactions, nil
+}
+
 // CountTransactionsByWallet counts transactions for a wallet.
 func (s *Store) CountTransactionsByWallet(ctx context.Context, walletAddress string, network string) (int64, error) {
 	params := dbgen.CountTransactionsByWalletParams{
@@ -161,6 +370,118 @@ func (s *Store) CountTransactionsByWallet(ctx context.Context, walletAddress str
 	return s.q.CountTransactionsByWallet(ctx, params)
 }
 
+// CountTransactionsByTimeBucketParams selects a throughput window.
+type CountTransactionsByTimeBucketParams struct {
+	Start       time.Time
+	End         time.Time // exclusive
+	BucketWidth time.Duration
+	Network     string // empty for all networks
+}
+
+// TimeBucketCount is the number of transactions written in one time bucket.
+type TimeBucketCount struct {
+	Start time.Time
+	Count int64
+}
+
+// CountTransactionsByTimeBucket counts transactions written (by CreatedAt, not
+// BlockTime) per bucket of BucketWidth between Start and End, oldest first.
+// Buckets are aligned by TimescaleDB's time_bucket, so the first one may
+// start before Start; empty buckets are returned with a zero count.
+func (s *Store) CountTransactionsByTimeBucket(ctx context.Context, params CountTransactionsByTimeBucketParams) ([]TimeBucketCount, error) {
+	results, err := s.q.CountTransactionsByTimeBucket(ctx, dbgen.CountTransactionsByTimeBucketParams{
+		BucketWidth: pgtype.Interval{Microseconds: params.BucketWidth.Microseconds(), Valid: true},
+		StartTime:   pgtype.Timestamptz{Time: params.Start, Valid: true},
+		EndTime:     pgtype.Timestamptz{Time: params.End, Valid: true},
+		Network:     params.Network,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]TimeBucketCount, len(results))
+	for i, result := range results {
+		buckets[i] = TimeBucketCount{
+			Start: result.Bucket.Time.UTC(),
+			Count: result.Count,
+		}
+	}
+
+	return buckets, nil
+}
+
+// IngestionLatencyParams selects the transactions GetIngestionLatency covers.
+type IngestionLatencyParams struct {
+	Start         time.Time
+	End           time.Time
+	Network       string // empty for all networks
+	WalletAddress string // empty for all wallets
+}
+
+// IngestionLatency summarizes how long transactions took to be written after
+// their block time.
+type IngestionLatency struct {
+	Count int64
+	P50   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// GetIngestionLatency summarizes the delay between block time and write for
+// transactions written (by CreatedAt) between Start and End. The durations
+// are zero when nothing was written.
+func (s *Store) GetIngestionLatency(ctx context.Context, params IngestionLatencyParams) (*IngestionLatency, error) {
+	result, err := s.q.GetIngestionLatency(ctx, dbgen.GetIngestionLatencyParams{
+		StartTime:     pgtype.Timestamptz{Time: params.Start, Valid: true},
+		EndTime:       pgtype.Timestamptz{Time: params.End, Valid: true},
+		Network:       params.Network,
+		WalletAddress: params.WalletAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seconds := func(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+	return &IngestionLatency{
+		Count: result.Count,
+		P50:   seconds(result.P50Seconds),
+		P99:   seconds(result.P99Seconds),
+		Max:   seconds(result.MaxSeconds),
+	}, nil
+}
+
+// AssetTransactionTotals summarizes the stored transactions in one asset on
+// one network.
+type AssetTransactionTotals struct {
+	Network        string
+	Asset          string // "sol" or a token mint
+	Count          int64  // all stored transactions, outgoing included
+	ReceivedCount  int64  // transactions with direction other than "out"
+	ReceivedAmount string // sum of received amounts in base units, in decimal; may exceed int64
+}
+
+// SummarizeTransactionsByAsset totals stored transactions per network and
+// asset. It scans the whole table, so callers should cache the result.
+func (s *Store) SummarizeTransactionsByAsset(ctx context.Context) ([]AssetTransactionTotals, error) {
+	results, err := s.q.SummarizeTransactionsByAsset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]AssetTransactionTotals, len(results))
+	for i, r := range results {
+		totals[i] = AssetTransactionTotals{
+			Network:        r.Network,
+			Asset:          r.Asset,
+			Count:          r.Count,
+			ReceivedCount:  r.ReceivedCount,
+			ReceivedAmount: r.ReceivedAmount,
+		}
+	}
+
+	return totals, nil
+}
+
 // GetLatestTransactionByWallet retrieves the most recent transaction for a wallet.
 func (s *Store) GetLatestTransactionByWallet(ctx context.Context, walletAddress string, network string) (*Transaction, error) {
 	params := dbgen.GetLatestTransactionByWalletParams{
@@ -218,6 +539,173 @@ func (s *Store) ListTransactionsByTimeRange(ctx context.Context, start time.Time
 	return transactions, nil
 }
 
+// UpdateTransactionMetadata replaces the client-supplied metadata on a transaction.
+// Returns pgx.ErrNoRows if the transaction doesn't exist.
+func (s *Store) UpdateTransactionMetadata(ctx context.Context, signature string, network string, metadata json.RawMessage) (*Transaction, error) {
+	result, err := s.q.UpdateTransactionMetadata(ctx, dbgen.UpdateTransactionMetadataParams{
+		Metadata:  metadata,
+		Signature: signature,
+		Network:   network,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbTransactionToDomain(&result), nil
+}
+
+// UpdateTransactionStatus sets a transaction's confirmation status, e.g. to
+// upgrade it from "confirmed" to "finalized", and returns the updated rows:
+// one per wallet and direction the transaction was stored for. Returns
+// pgx.ErrNoRows if the transaction does not exist.
+func (s *Store) UpdateTransactionStatus(ctx context.Context, signature string, network string, status string) ([]*Transaction, error) {
+	results, err := s.q.UpdateTransactionStatus(ctx, dbgen.UpdateTransactionStatusParams{
+		ConfirmationStatus: status,
+		Signature:          signature,
+		Network:            network,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	transactions := make([]*Transaction, len(results))
+	for i := range results {
+		transactions[i] = dbTransactionToDomain(&results[i])
+	}
+	return transactions, nil
+}
+
+// ListTransactionsByConfirmationStatus returns up to limit transactions on a
+// network with the given confirmation status, oldest first.
+func (s *Store) ListTransactionsByConfirmationStatus(ctx context.Context, status string, network string, limit int32) ([]*Transaction, error) {
+	results, err := s.q.ListTransactionsByConfirmationStatus(ctx, dbgen.ListTransactionsByConfirmationStatusParams{
+		ConfirmationStatus: status,
+		Network:            network,
+		Limit:              limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, len(results))
+	for i, result := range results {
+		transactions[i] = dbTransactionToDomain(&result)
+	}
+
+	return transactions, nil
+}
+
+// SearchTransactionsByMemoParams contains memo search parameters.
+type SearchTransactionsByMemoParams struct {
+	WalletAddress string
+	Network       string
+	Query         string // matched literally; % and _ are not wildcards
+	Prefix        bool   // match only memos starting with Query
+	Limit         int32
+}
+
+// SearchTransactionsByMemo returns up to Limit transactions for a wallet
+// whose memo contains Query (or starts with it, if Prefix is set), ignoring
+// case. Results are newest first. Queries of three or more characters are
+// served by the memo trigram index; shorter ones fall back to a scan of the
+// wallet's transactions.
+func (s *Store) SearchTransactionsByMemo(ctx context.Context, params SearchTransactionsByMemoParams) ([]*Transaction, error) {
+	pattern := escapeLikePattern(params.Query) + "%"
+	if !params.Prefix {
+		pattern = "%" + pattern
+	}
+
+	results, err := s.q.SearchTransactionsByMemo(ctx, dbgen.SearchTransactionsByMemoParams{
+		WalletAddress: params.WalletAddress,
+		Network:       params.Network,
+		Pattern:       pattern,
+		LimitCount:    params.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, len(results))
+	for i, result := range results {
+		transactions[i] = dbTransactionToDomain(&result)
+	}
+
+	return transactions, nil
+}
+
+// ListTransactionsByAmountRangeParams selects a wallet's transactions in one
+// asset by amount.
+type ListTransactionsByAmountRangeParams struct {
+	WalletAddress string
+	Network       string
+	Asset         string // "sol" for native transfers, or a token mint
+	MinAmount     int64  // inclusive, in base units of the asset
+	MaxAmount     int64  // inclusive, in base units of the asset
+	Limit         int32
+	Offset        int32
+}
+
+// ListTransactionsByAmountRange returns a wallet's transactions in one asset
+// whose amount is between MinAmount and MaxAmount, newest first. Amounts of
+// different assets aren't comparable, so the asset is always fixed.
+func (s *Store) ListTransactionsByAmountRange(ctx context.Context, params ListTransactionsByAmountRangeParams) ([]*Transaction, error) {
+	results, err := s.q.ListTransactionsByAmountRange(ctx, dbgen.ListTransactionsByAmountRangeParams{
+		WalletAddress: params.WalletAddress,
+		Network:       params.Network,
+		MinAmount:     params.MinAmount,
+		MaxAmount:     params.MaxAmount,
+		Asset:         params.Asset,
+		LimitCount:    params.Limit,
+		OffsetCount:   params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, len(results))
+	for i, result := range results {
+		transactions[i] = dbTransactionToDomain(&result)
+	}
+
+	return transactions, nil
+}
+
+// FindPaymentByMemoParams identifies a payment by its exact memo.
+type FindPaymentByMemoParams struct {
+	WalletAddress string
+	Network       string
+	Memo          string
+	MinAmount     int64  // in base units of the asset
+	Asset         string // "" for any asset, "sol", or a token mint
+}
+
+// FindPaymentByMemo returns the earliest transaction to a wallet whose memo
+// is exactly Memo (case-sensitive) and whose amount is at least MinAmount.
+// Returns pgx.ErrNoRows if there is none.
+func (s *Store) FindPaymentByMemo(ctx context.Context, params FindPaymentByMemoParams) (*Transaction, error) {
+	result, err := s.q.FindPaymentByMemo(ctx, dbgen.FindPaymentByMemoParams{
+		WalletAddress: params.WalletAddress,
+		Network:       params.Network,
+		Memo:          params.Memo,
+		MinAmount:     params.MinAmount,
+		Asset:         params.Asset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbTransactionToDomain(&result), nil
+}
+
+// escapeLikePattern escapes LIKE metacharacters so s matches literally.
+// Backslash is Postgres's default LIKE escape character.
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 // Wallet represents a registered wallet+asset combination that the server monitors.
 type Wallet struct {
 	Address                string
@@ -228,6 +716,9 @@ type Wallet struct {
 	Status                 string
 	CreatedAt              time.Time
 	UpdatedAt              time.Time
+	Metadata               json.RawMessage // client-supplied at registration; nil if none
+	DeletedAt              *time.Time      // set when unregistered (soft-deleted); nil while registered
+	Tags                   []string        // operator-supplied grouping tags, e.g. "customer:acme"; never nil
 }
 
 // CreateWalletParams contains the parameters for registering a wallet asset.
@@ -238,9 +729,13 @@ type CreateWalletParams struct {
 	TokenMint              string
 	AssociatedTokenAddress *string
 	Status                 string
+	Metadata               json.RawMessage
+	Tags                   []string
 }
 
 // UpsertWalletParams contains the parameters for upserting a wallet asset.
+// A nil Metadata or Tags leaves the existing value untouched; an empty Tags
+// clears them.
 type UpsertWalletParams struct {
 	Address                string
 	Network                string
@@ -248,6 +743,14 @@ type UpsertWalletParams struct {
 	TokenMint              string
 	AssociatedTokenAddress *string
 	Status                 string
+	Metadata               json.RawMessage
+	Tags                   []string
+}
+
+// ListWalletsParams filters ListWallets.
+type ListWalletsParams struct {
+	IncludeDeleted bool     // include unregistered (soft-deleted) wallets
+	Tags           []string // only wallets carrying all of these tags
 }
 
 // CreateWallet registers a new wallet+asset for monitoring.
@@ -259,6 +762,8 @@ func (s *Store) CreateWallet(ctx context.Context, params CreateWalletParams) (*W
 		TokenMint:              params.TokenMint,
 		AssociatedTokenAddress: pgtextFromStringPtr(params.AssociatedTokenAddress),
 		Status:                 params.Status,
+		Metadata:               params.Metadata,
+		Tags:                   tagsToJSON(params.Tags),
 	}
 
 	result, err := s.q.CreateWallet(ctx, sqlcParams)
@@ -270,7 +775,8 @@ func (s *Store) CreateWallet(ctx context.Context, params CreateWalletParams) (*W
 }
 
 // UpsertWallet creates or updates a wallet+asset for monitoring.
-// If the wallet already exists, it updates the ATA and status.
+// If the wallet already exists, it updates the ATA and status, and clears
+// any soft-delete mark.
 func (s *Store) UpsertWallet(ctx context.Context, params UpsertWalletParams) (*Wallet, error) {
 	sqlcParams := dbgen.UpsertWalletParams{
 		Address:                params.Address,
@@ -279,6 +785,8 @@ func (s *Store) UpsertWallet(ctx context.Context, params UpsertWalletParams) (*W
 		TokenMint:              params.TokenMint,
 		AssociatedTokenAddress: pgtextFromStringPtr(params.AssociatedTokenAddress),
 		Status:                 params.Status,
+		Metadata:               params.Metadata,
+		Tags:                   tagsToJSON(params.Tags),
 	}
 
 	result, err := s.q.UpsertWallet(ctx, sqlcParams)
@@ -290,6 +798,7 @@ func (s *Store) UpsertWallet(ctx context.Context, params UpsertWalletParams) (*W
 }
 
 // GetWallet retrieves a wallet+asset by its address, network, asset type, and token mint.
+// Soft-deleted wallets are returned too; check DeletedAt.
 func (s *Store) GetWallet(ctx context.Context, address string, network string, assetType string, tokenMint string) (*Wallet, error) {
 	params := dbgen.GetWalletParams{
 		Address:   address,
@@ -305,9 +814,19 @@ func (s *Store) GetWallet(ctx context.Context, address string, network string, a
 	return dbWalletToDomain(&result), nil
 }
 
-// ListWallets retrieves all registered wallets.
-func (s *Store) ListWallets(ctx context.Context) ([]*Wallet, error) {
-	results, err := s.q.ListWallets(ctx)
+// ListWallets retrieves all registered wallets, optionally filtered by tags.
+// Soft-deleted wallets are included only when params.IncludeDeleted is true.
+// Wallets are ordered by creation time, oldest first, then by address,
+// network, asset type and token mint, so the order is stable across calls.
+func (s *Store) ListWallets(ctx context.Context, params ListWalletsParams) ([]*Wallet, error) {
+	tags := tagsToJSON(params.Tags)
+	if tags == nil {
+		tags = []byte("[]")
+	}
+	results, err := s.q.ListWallets(ctx, dbgen.ListWalletsParams{
+		IncludeDeleted: params.IncludeDeleted,
+		Tags:           tags,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -320,7 +839,8 @@ func (s *Store) ListWallets(ctx context.Context) ([]*Wallet, error) {
 	return wallets, nil
 }
 
-// ListActiveWallets retrieves all active wallets ordered by last poll time.
+// ListActiveWallets retrieves all active, non-deleted wallets in the same
+// order as ListWallets.
 func (s *Store) ListActiveWallets(ctx context.Context) ([]*Wallet, error) {
 	results, err := s.q.ListActiveWallets(ctx)
 	if err != nil {
@@ -335,6 +855,80 @@ func (s *Store) ListActiveWallets(ctx context.Context) ([]*Wallet, error) {
 	return wallets, nil
 }
 
+// WalletStatusCount is the number of registered wallet assets sharing a
+// network, asset type and status.
+type WalletStatusCount struct {
+	Network   string
+	AssetType string
+	Status    string
+	Count     int64
+}
+
+// CountWalletsByStatus counts registered wallet assets per network, asset
+// type and status. Unregistered wallets are not counted.
+func (s *Store) CountWalletsByStatus(ctx context.Context) ([]WalletStatusCount, error) {
+	results, err := s.q.CountWalletsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]WalletStatusCount, len(results))
+	for i, r := range results {
+		counts[i] = WalletStatusCount{
+			Network:   r.Network,
+			AssetType: r.AssetType,
+			Status:    r.Status,
+			Count:     r.Count,
+		}
+	}
+
+	return counts, nil
+}
+
+// WalletActivity is an active wallet asset with its most recent stored
+// transaction in that asset.
+type WalletActivity struct {
+	Wallet                   *Wallet
+	LastTransactionAt        time.Time // block time of the latest transaction
+	LastTransactionSignature string
+}
+
+// ListWalletsByRecentActivity retrieves active wallet assets ordered by their
+// latest transaction's block time, newest first. Wallets that have never
+// received a transaction are not included.
+func (s *Store) ListWalletsByRecentActivity(ctx context.Context, limit, offset int32) ([]*WalletActivity, error) {
+	results, err := s.q.ListWalletsByRecentActivity(ctx, dbgen.ListWalletsByRecentActivityParams{
+		LimitCount:  limit,
+		OffsetCount: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]*WalletActivity, len(results))
+	for i, r := range results {
+		activity[i] = &WalletActivity{
+			Wallet: dbWalletToDomain(&dbgen.Wallet{
+				Address:                r.Address,
+				Status:                 r.Status,
+				CreatedAt:              r.CreatedAt,
+				UpdatedAt:              r.UpdatedAt,
+				Network:                r.Network,
+				AssetType:              r.AssetType,
+				TokenMint:              r.TokenMint,
+				AssociatedTokenAddress: r.AssociatedTokenAddress,
+				Metadata:               r.Metadata,
+				DeletedAt:              r.DeletedAt,
+				Tags:                   r.Tags,
+			}),
+			LastTransactionAt:        r.LastTransactionAt.Time,
+			LastTransactionSignature: r.LastTransactionSignature,
+		}
+	}
+
+	return activity, nil
+}
+
 // UpdateWalletStatus updates the status of a wallet+asset.
 func (s *Store) UpdateWalletStatus(ctx context.Context, address string, network string, assetType string, tokenMint string, status string) (*Wallet, error) {
 	params := dbgen.UpdateWalletStatusParams{
@@ -353,7 +947,8 @@ func (s *Store) UpdateWalletStatus(ctx context.Context, address string, network
 	return dbWalletToDomain(&result), nil
 }
 
-// DeleteWallet removes a wallet+asset from monitoring.
+// DeleteWallet permanently removes a wallet+asset row. Unregistering goes
+// through SoftDeleteWallet; this is for rolling back failed registrations.
 func (s *Store) DeleteWallet(ctx context.Context, address string, network string, assetType string, tokenMint string) error {
 	params := dbgen.DeleteWalletParams{
 		Address:   address,
@@ -364,7 +959,28 @@ func (s *Store) DeleteWallet(ctx context.Context, address string, network string
 	return s.q.DeleteWallet(ctx, params)
 }
 
-// WalletExists checks if a wallet+asset is registered.
+// SoftDeleteWallet marks a wallet+asset as unregistered, keeping the row for
+// audit history. Returns false if it was not registered (or already deleted).
+func (s *Store) SoftDeleteWallet(ctx context.Context, address string, network string, assetType string, tokenMint string) (bool, error) {
+	rows, err := s.q.SoftDeleteWallet(ctx, dbgen.SoftDeleteWalletParams{
+		Address:   address,
+		Network:   network,
+		AssetType: assetType,
+		TokenMint: tokenMint,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// PurgeDeletedWallets permanently removes wallets soft-deleted before the
+// given time and returns how many were removed.
+func (s *Store) PurgeDeletedWallets(ctx context.Context, before time.Time) (int64, error) {
+	return s.q.PurgeDeletedWallets(ctx, pgtype.Timestamptz{Time: before, Valid: true})
+}
+
+// WalletExists checks if a wallet+asset is registered (and not soft-deleted).
 func (s *Store) WalletExists(ctx context.Context, address string, network string, assetType string, tokenMint string) (bool, error) {
 	params := dbgen.WalletExistsParams{
 		Address:   address,
@@ -375,7 +991,8 @@ func (s *Store) WalletExists(ctx context.Context, address string, network string
 	return s.q.WalletExists(ctx, params)
 }
 
-// ListWalletsByAddress retrieves all wallet+asset combinations for a given address.
+// ListWalletsByAddress retrieves all wallet+asset combinations for a given
+// address, ordered by network, asset type and token mint.
 func (s *Store) ListWalletsByAddress(ctx context.Context, address string) ([]*Wallet, error) {
 	results, err := s.q.ListWalletsByAddress(ctx, address)
 	if err != nil {
@@ -390,11 +1007,14 @@ func (s *Store) ListWalletsByAddress(ctx context.Context, address string) ([]*Wa
 	return wallets, nil
 }
 
-// ListWalletAssets retrieves all assets registered for a specific wallet and network.
-func (s *Store) ListWalletAssets(ctx context.Context, address string, network string) ([]*Wallet, error) {
+// ListWalletAssets retrieves all assets registered for a specific wallet and
+// network, ordered by asset type and token mint. Soft-deleted assets are
+// included only when includeDeleted is true.
+func (s *Store) ListWalletAssets(ctx context.Context, address string, network string, includeDeleted bool) ([]*Wallet, error) {
 	params := dbgen.ListWalletAssetsParams{
-		Address: address,
-		Network: network,
+		Address:        address,
+		Network:        network,
+		IncludeDeleted: includeDeleted,
 	}
 	results, err := s.q.ListWalletAssets(ctx, params)
 	if err != nil {
@@ -409,6 +1029,646 @@ func (s *Store) ListWalletAssets(ctx context.Context, address string, network st
 	return wallets, nil
 }
 
+// SupportedMint is an SPL token mint that wallets may register for on a network.
+type SupportedMint struct {
+	Network   string
+	Mint      string
+	CreatedAt time.Time
+	Decimals  *int    // nil until resolved on-chain
+	Symbol    *string // nil if unresolved or the mint has no metadata symbol
+}
+
+// AddSupportedMint adds a mint to the supported-mints registry for a network.
+// Adding a mint that is already registered is a no-op that returns the existing row.
+func (s *Store) AddSupportedMint(ctx context.Context, network string, mint string) (*SupportedMint, error) {
+	result, err := s.q.AddSupportedMint(ctx, dbgen.AddSupportedMintParams{
+		Network: network,
+		Mint:    mint,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbSupportedMintToDomain(&result), nil
+}
+
+// RemoveSupportedMint removes a mint from the supported-mints registry.
+// Returns false if the mint was not registered for the network.
+func (s *Store) RemoveSupportedMint(ctx context.Context, network string, mint string) (bool, error) {
+	rows, err := s.q.RemoveSupportedMint(ctx, dbgen.RemoveSupportedMintParams{
+		Network: network,
+		Mint:    mint,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// SetSupportedMintInfo records the on-chain decimals and symbol for a
+// registered mint. An empty symbol is stored as NULL.
+func (s *Store) SetSupportedMintInfo(ctx context.Context, network string, mint string, decimals int, symbol string) (*SupportedMint, error) {
+	var sym *string
+	if symbol != "" {
+		sym = &symbol
+	}
+	result, err := s.q.SetSupportedMintInfo(ctx, dbgen.SetSupportedMintInfoParams{
+		Network:  network,
+		Mint:     mint,
+		Decimals: pgtype.Int2{Int16: int16(decimals), Valid: true},
+		Symbol:   pgtextFromStringPtr(sym),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbSupportedMintToDomain(&result), nil
+}
+
+// ListSupportedMints retrieves the registered mints for a network.
+func (s *Store) ListSupportedMints(ctx context.Context, network string) ([]*SupportedMint, error) {
+	results, err := s.q.ListSupportedMints(ctx, network)
+	if err != nil {
+		return nil, err
+	}
+
+	mints := make([]*SupportedMint, len(results))
+	for i := range results {
+		mints[i] = dbSupportedMintToDomain(&results[i])
+	}
+
+	return mints, nil
+}
+
+// ListAllSupportedMints retrieves the registered mints across all networks.
+func (s *Store) ListAllSupportedMints(ctx context.Context) ([]*SupportedMint, error) {
+	results, err := s.q.ListAllSupportedMints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mints := make([]*SupportedMint, len(results))
+	for i := range results {
+		mints[i] = dbSupportedMintToDomain(&results[i])
+	}
+
+	return mints, nil
+}
+
+// DigestSubscription batches a wallet's transactions into one delivery to URL
+// every Interval.
+type DigestSubscription struct {
+	ID              int64
+	Address         string
+	Network         string
+	URL             string
+	Interval        time.Duration
+	LastDeliveredAt time.Time // end of the last delivered window; creation time until then
+	CreatedAt       time.Time
+	FilterID        *int64 // wallet filter applied to the digest; nil delivers every transaction
+}
+
+// CreateDigestSubscriptionParams contains parameters for creating a digest
+// subscription.
+type CreateDigestSubscriptionParams struct {
+	Address  string
+	Network  string
+	URL      string
+	Interval time.Duration // whole seconds
+	FilterID *int64        // optional wallet filter
+}
+
+// CreateDigestSubscription creates a digest subscription. Its first window
+// starts now.
+func (s *Store) CreateDigestSubscription(ctx context.Context, params CreateDigestSubscriptionParams) (*DigestSubscription, error) {
+	result, err := s.q.CreateDigestSubscription(ctx, dbgen.CreateDigestSubscriptionParams{
+		Address:         params.Address,
+		Network:         params.Network,
+		Url:             params.URL,
+		IntervalSeconds: int32(params.Interval / time.Second),
+		FilterID:        pgint8FromInt64Ptr(params.FilterID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbDigestSubscriptionToDomain(&result), nil
+}
+
+// DeleteDigestSubscription removes a digest subscription. Returns false if it
+// did not exist.
+func (s *Store) DeleteDigestSubscription(ctx context.Context, id int64) (bool, error) {
+	rows, err := s.q.DeleteDigestSubscription(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ListDigestSubscriptions retrieves digest subscriptions, optionally only
+// those for an address and/or network (empty matches all).
+func (s *Store) ListDigestSubscriptions(ctx context.Context, address string, network string) ([]*DigestSubscription, error) {
+	results, err := s.q.ListDigestSubscriptions(ctx, dbgen.ListDigestSubscriptionsParams{
+		Address: address,
+		Network: network,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]*DigestSubscription, len(results))
+	for i := range results {
+		subs[i] = dbDigestSubscriptionToDomain(&results[i])
+	}
+
+	return subs, nil
+}
+
+// ListDueDigestSubscriptions retrieves up to limit subscriptions whose
+// interval has elapsed by now, most overdue first.
+func (s *Store) ListDueDigestSubscriptions(ctx context.Context, now time.Time, limit int32) ([]*DigestSubscription, error) {
+	results, err := s.q.ListDueDigestSubscriptions(ctx, dbgen.ListDueDigestSubscriptionsParams{
+		Now:        pgtype.Timestamptz{Time: now, Valid: true},
+		LimitCount: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]*DigestSubscription, len(results))
+	for i := range results {
+		subs[i] = dbDigestSubscriptionToDomain(&results[i])
+	}
+
+	return subs, nil
+}
+
+// MarkDigestDelivered records that a subscription's digest was delivered up
+// to windowEnd, where its next window starts.
+func (s *Store) MarkDigestDelivered(ctx context.Context, id int64, windowEnd time.Time) error {
+	return s.q.MarkDigestDelivered(ctx, dbgen.MarkDigestDeliveredParams{
+		ID:              id,
+		LastDeliveredAt: pgtype.Timestamptz{Time: windowEnd, Valid: true},
+	})
+}
+
+// TransactionWebhook delivers each of a wallet's transactions to URL as it is
+// published, signed with Secret.
+type TransactionWebhook struct {
+	ID        int64
+	Address   string
+	Network   string
+	URL       string
+	Secret    string
+	CreatedAt time.Time
+	FilterID  *int64 // wallet filter a transaction must match; nil delivers every transaction
+}
+
+// CreateTransactionWebhookParams contains parameters for creating a
+// transaction webhook.
+type CreateTransactionWebhookParams struct {
+	Address  string
+	Network  string
+	URL      string
+	Secret   string
+	FilterID *int64 // optional wallet filter
+}
+
+// CreateTransactionWebhook creates a transaction webhook.
+func (s *Store) CreateTransactionWebhook(ctx context.Context, params CreateTransactionWebhookParams) (*TransactionWebhook, error) {
+	result, err := s.q.CreateTransactionWebhook(ctx, dbgen.CreateTransactionWebhookParams{
+		Address:  params.Address,
+		Network:  params.Network,
+		Url:      params.URL,
+		Secret:   params.Secret,
+		FilterID: pgint8FromInt64Ptr(params.FilterID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbTransactionWebhookToDomain(&result), nil
+}
+
+// DeleteTransactionWebhook removes a transaction webhook. Returns false if it
+// did not exist.
+func (s *Store) DeleteTransactionWebhook(ctx context.Context, id int64) (bool, error) {
+	rows, err := s.q.DeleteTransactionWebhook(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ListTransactionWebhooks retrieves transaction webhooks, optionally only
+// those for an address and/or network (empty matches all).
+func (s *Store) ListTransactionWebhooks(ctx context.Context, address string, network string) ([]*TransactionWebhook, error) {
+	results, err := s.q.ListTransactionWebhooks(ctx, dbgen.ListTransactionWebhooksParams{
+		Address: address,
+		Network: network,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := make([]*TransactionWebhook, len(results))
+	for i := range results {
+		hooks[i] = dbTransactionWebhookToDomain(&results[i])
+	}
+
+	return hooks, nil
+}
+
+// ErrWalletFilterInUse is returned when deleting a wallet filter that a
+// digest subscription or transaction webhook still applies.
+var ErrWalletFilterInUse = errors.New("wallet filter is used by a digest subscription or webhook")
+
+// WalletFilter is a named set of transaction criteria stored for a wallet.
+// Nil criteria match every transaction; the set ones must all match.
+type WalletFilter struct {
+	ID        int64
+	Address   string
+	Network   string
+	Name      string
+	AssetType *string // "sol" or "spl-token"
+	TokenMint *string // with AssetType "spl-token"
+	MinAmount *int64  // in base units of the asset
+	Memo      *string
+	MemoMatch string   // how Memo is matched: "exact", "prefix" or "contains"
+	JQ        []string // jq expressions that must all be truthy against the memo as JSON
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SaveWalletFilterParams contains parameters for saving a wallet filter.
+type SaveWalletFilterParams struct {
+	Address   string
+	Network   string
+	Name      string
+	AssetType *string
+	TokenMint *string
+	MinAmount *int64
+	Memo      *string
+	MemoMatch string
+	JQ        []string
+}
+
+// SaveWalletFilter creates the named filter for a wallet, or replaces the
+// criteria of an existing one. The filter keeps its ID, so digests applying
+// it pick up the new criteria.
+func (s *Store) SaveWalletFilter(ctx context.Context, params SaveWalletFilterParams) (*WalletFilter, error) {
+	jq := params.JQ
+	if jq == nil {
+		jq = []string{}
+	}
+	result, err := s.q.SaveWalletFilter(ctx, dbgen.SaveWalletFilterParams{
+		Address:   params.Address,
+		Network:   params.Network,
+		Name:      params.Name,
+		AssetType: pgtextFromStringPtr(params.AssetType),
+		TokenMint: pgtextFromStringPtr(params.TokenMint),
+		MinAmount: pgint8FromInt64Ptr(params.MinAmount),
+		Memo:      pgtextFromStringPtr(params.Memo),
+		MemoMatch: params.MemoMatch,
+		Jq:        jq,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbWalletFilterToDomain(&result), nil
+}
+
+// GetWalletFilter retrieves a wallet's filter by name. Returns pgx.ErrNoRows
+// if it doesn't exist.
+func (s *Store) GetWalletFilter(ctx context.Context, address, network, name string) (*WalletFilter, error) {
+	result, err := s.q.GetWalletFilter(ctx, dbgen.GetWalletFilterParams{
+		Address: address,
+		Network: network,
+		Name:    name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbWalletFilterToDomain(&result), nil
+}
+
+// GetWalletFilterByID retrieves a wallet filter by ID. Returns pgx.ErrNoRows
+// if it doesn't exist.
+func (s *Store) GetWalletFilterByID(ctx context.Context, id int64) (*WalletFilter, error) {
+	result, err := s.q.GetWalletFilterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbWalletFilterToDomain(&result), nil
+}
+
+// ListWalletFilters retrieves a wallet's filters, ordered by name.
+func (s *Store) ListWalletFilters(ctx context.Context, address, network string) ([]*WalletFilter, error) {
+	results, err := s.q.ListWalletFilters(ctx, dbgen.ListWalletFiltersParams{
+		Address: address,
+		Network: network,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]*WalletFilter, len(results))
+	for i := range results {
+		filters[i] = dbWalletFilterToDomain(&results[i])
+	}
+
+	return filters, nil
+}
+
+// DeleteWalletFilter removes a wallet's filter. Returns false if it did not
+// exist, and ErrWalletFilterInUse if a digest subscription or transaction
+// webhook applies it.
+func (s *Store) DeleteWalletFilter(ctx context.Context, address, network, name string) (bool, error) {
+	rows, err := s.q.DeleteWalletFilter(ctx, dbgen.DeleteWalletFilterParams{
+		Address: address,
+		Network: network,
+		Name:    name,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			return false, ErrWalletFilterInUse
+		}
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ErrAPIKeyNameTaken is returned when creating an API key with a name that
+// is already in use.
+var ErrAPIKeyNameTaken = errors.New("an API key with that name already exists")
+
+// APIKey is a named key for the HTTP API. The key itself isn't stored.
+type APIKey struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+// hashAPIKey is the stored form of an API key. Keys are long random strings,
+// so an unsalted SHA-256 is enough and lets a key be looked up by its hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey stores the hash of key under name. Returns ErrAPIKeyNameTaken
+// if name is in use.
+func (s *Store) CreateAPIKey(ctx context.Context, name, key string) (*APIKey, error) {
+	result, err := s.q.CreateAPIKey(ctx, dbgen.CreateAPIKeyParams{
+		Name:    name,
+		KeyHash: hashAPIKey(key),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "api_keys_name_key" { // unique_violation
+			return nil, ErrAPIKeyNameTaken
+		}
+		return nil, err
+	}
+	return dbAPIKeyToDomain(&result), nil
+}
+
+// GetAPIKey retrieves the stored API key matching key. Returns pgx.ErrNoRows
+// if there is none.
+func (s *Store) GetAPIKey(ctx context.Context, key string) (*APIKey, error) {
+	result, err := s.q.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	return dbAPIKeyToDomain(&result), nil
+}
+
+// AuditLogEntry records one mutating API call: who did what to which wallet,
+// and whether it succeeded.
+type AuditLogEntry struct {
+	ID            int64
+	CreatedAt     time.Time
+	Actor         string
+	Action        string
+	WalletAddress *string
+	Network       *string
+	Method        string
+	Path          string
+	Status        int
+	Outcome       string // "success" or "failure"
+	RequestID     string
+	RemoteAddr    string
+}
+
+// CreateAuditLogEntry appends an entry to the audit log. CreatedAt and ID are
+// assigned by the database.
+func (s *Store) CreateAuditLogEntry(ctx context.Context, entry AuditLogEntry) (*AuditLogEntry, error) {
+	result, err := s.q.CreateAuditLogEntry(ctx, dbgen.CreateAuditLogEntryParams{
+		Actor:         entry.Actor,
+		Action:        entry.Action,
+		WalletAddress: pgtextFromStringPtr(entry.WalletAddress),
+		Network:       pgtextFromStringPtr(entry.Network),
+		Method:        entry.Method,
+		Path:          entry.Path,
+		Status:        int32(entry.Status),
+		Outcome:       entry.Outcome,
+		RequestID:     entry.RequestID,
+		RemoteAddr:    entry.RemoteAddr,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbAuditLogToDomain(&result), nil
+}
+
+// ListAuditLogParams contains parameters for listing audit log entries.
+type ListAuditLogParams struct {
+	Start         time.Time // inclusive
+	End           time.Time // exclusive
+	Actor         string    // empty matches all
+	WalletAddress string    // empty matches all
+	Limit         int32
+}
+
+// ListAuditLog retrieves audit log entries recorded in [Start, End), newest
+// first.
+func (s *Store) ListAuditLog(ctx context.Context, params ListAuditLogParams) ([]*AuditLogEntry, error) {
+	results, err := s.q.ListAuditLog(ctx, dbgen.ListAuditLogParams{
+		StartTime:     pgtype.Timestamptz{Time: params.Start, Valid: true},
+		EndTime:       pgtype.Timestamptz{Time: params.End, Valid: true},
+		Actor:         params.Actor,
+		WalletAddress: params.WalletAddress,
+		LimitCount:    params.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*AuditLogEntry, len(results))
+	for i := range results {
+		entries[i] = dbAuditLogToDomain(&results[i])
+	}
+
+	return entries, nil
+}
+
+// RegistrationInvoice is the invoice issued for a payment-gated registration,
+// keyed by the registration's workflow ID.
+type RegistrationInvoice struct {
+	WorkflowID  string
+	Address     string
+	Network     string
+	AssetType   string
+	TokenMint   string          // "" for SOL
+	Invoice     json.RawMessage // the invoice as returned to the client
+	AcceptUntil time.Time       // payments are accepted until here
+	CreatedAt   time.Time
+}
+
+// ClaimRegistrationInvoiceParams contains parameters for claiming a
+// registration invoice.
+type ClaimRegistrationInvoiceParams struct {
+	WorkflowID  string
+	Address     string
+	Network     string
+	AssetType   string
+	TokenMint   string
+	Invoice     json.RawMessage
+	AcceptUntil time.Time
+}
+
+// ClaimRegistrationInvoice stores the invoice for a workflow ID unless an
+// unexpired one already exists. It returns the invoice in effect and whether
+// this call stored it. Concurrent claims for one workflow ID all return the
+// same invoice, and exactly one of them reports claimed.
+func (s *Store) ClaimRegistrationInvoice(ctx context.Context, params ClaimRegistrationInvoiceParams) (*RegistrationInvoice, bool, error) {
+	result, err := s.q.ClaimRegistrationInvoice(ctx, dbgen.ClaimRegistrationInvoiceParams{
+		WorkflowID:  params.WorkflowID,
+		Address:     params.Address,
+		Network:     params.Network,
+		AssetType:   params.AssetType,
+		TokenMint:   params.TokenMint,
+		Invoice:     params.Invoice,
+		AcceptUntil: pgtype.Timestamptz{Time: params.AcceptUntil, Valid: true},
+	})
+	if err == nil {
+		return dbRegistrationInvoiceToDomain(&result), true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, err
+	}
+
+	// An unexpired invoice already exists.
+	result, err = s.q.GetRegistrationInvoice(ctx, params.WorkflowID)
+	if err != nil {
+		return nil, false, err
+	}
+	return dbRegistrationInvoiceToDomain(&result), false, nil
+}
+
+// Webhook delivery statuses. A delivery is retrying while attempts remain,
+// and delivered or failed once the last attempt settles it.
+const (
+	WebhookDeliveryRetrying  = "retrying"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDeliveryStatuses lists the valid webhook delivery statuses.
+var WebhookDeliveryStatuses = []string{WebhookDeliveryRetrying, WebhookDeliveryDelivered, WebhookDeliveryFailed}
+
+// WebhookDelivery is an outbound webhook and the outcome of its latest
+// attempt. There is one per event and source, e.g. per registration for
+// registration.completed callbacks.
+type WebhookDelivery struct {
+	ID             int64
+	Event          string
+	SourceID       string // what the event is about, e.g. the registration's workflow ID
+	Network        string
+	URL            string
+	Payload        json.RawMessage
+	Status         string // one of the WebhookDelivery* statuses
+	Attempts       int
+	LastStatusCode *int    // nil when the last attempt got no response
+	LastError      *string // nil when the last attempt succeeded
+	LastAttemptAt  time.Time
+	DeliveredAt    *time.Time // last successful attempt
+	CreatedAt      time.Time
+}
+
+// RecordWebhookDeliveryAttemptParams describes one delivery attempt.
+type RecordWebhookDeliveryAttemptParams struct {
+	Event      string
+	SourceID   string
+	Network    string
+	URL        string
+	Payload    json.RawMessage
+	Status     string
+	StatusCode int    // 0 when no response was received
+	Error      string // empty on success
+}
+
+// RecordWebhookDeliveryAttempt records an attempt, creating the delivery on
+// its first attempt and updating it on later ones.
+func (s *Store) RecordWebhookDeliveryAttempt(ctx context.Context, params RecordWebhookDeliveryAttemptParams) (*WebhookDelivery, error) {
+	result, err := s.q.RecordWebhookDeliveryAttempt(ctx, dbgen.RecordWebhookDeliveryAttemptParams{
+		Event:          params.Event,
+		SourceID:       params.SourceID,
+		Network:        params.Network,
+		Url:            params.URL,
+		Payload:        params.Payload,
+		Status:         params.Status,
+		LastStatusCode: pgtype.Int4{Int32: int32(params.StatusCode), Valid: params.StatusCode != 0},
+		LastError:      pgtype.Text{String: params.Error, Valid: params.Error != ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dbWebhookDeliveryToDomain(&result), nil
+}
+
+// GetWebhookDelivery retrieves a delivery by ID. It returns pgx.ErrNoRows
+// when there is none.
+func (s *Store) GetWebhookDelivery(ctx context.Context, id int64) (*WebhookDelivery, error) {
+	result, err := s.q.GetWebhookDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dbWebhookDeliveryToDomain(&result), nil
+}
+
+// ListWebhookDeliveriesParams contains parameters for listing deliveries.
+type ListWebhookDeliveriesParams struct {
+	Status string // empty matches all
+	Limit  int32
+	Offset int32
+}
+
+// ListWebhookDeliveries retrieves deliveries by most recent attempt, newest
+// first.
+func (s *Store) ListWebhookDeliveries(ctx context.Context, params ListWebhookDeliveriesParams) ([]*WebhookDelivery, error) {
+	results, err := s.q.ListWebhookDeliveries(ctx, dbgen.ListWebhookDeliveriesParams{
+		Status:      params.Status,
+		LimitCount:  params.Limit,
+		OffsetCount: params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*WebhookDelivery, len(results))
+	for i := range results {
+		deliveries[i] = dbWebhookDeliveryToDomain(&results[i])
+	}
+
+	return deliveries, nil
+}
+
 // Helper functions to convert between sqlc types and domain types
 
 func dbTransactionToDomain(db *dbgen.Transaction) *Transaction {
@@ -424,7 +1684,21 @@ func dbTransactionToDomain(db *dbgen.Transaction) *Transaction {
 		ConfirmationStatus: db.ConfirmationStatus,
 		CreatedAt:          db.CreatedAt.Time,
 		FromAddress:        stringPtrFromPgtext(db.FromAddress),
+		Metadata:           db.Metadata,
+		Fee:                db.Fee,
+		MemoTruncated:      db.MemoTruncated,
+		PaymentID:          stringPtrFromPgtext(db.PaymentID),
+		DuplicateLogical:   db.DuplicateLogical,
+		Direction:          db.Direction,
+	}
+}
+
+// transactionDirection defaults an empty direction to DirectionIn.
+func transactionDirection(direction string) string {
+	if direction == "" {
+		return DirectionIn
 	}
+	return direction
 }
 
 func pgtextFromStringPtr(s *string) pgtype.Text {
@@ -434,6 +1708,13 @@ func pgtextFromStringPtr(s *string) pgtype.Text {
 	return pgtype.Text{String: *s, Valid: true}
 }
 
+func stringFromPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func stringPtrFromPgtext(t pgtype.Text) *string {
 	if !t.Valid {
 		return nil
@@ -441,6 +1722,27 @@ func stringPtrFromPgtext(t pgtype.Text) *string {
 	return &t.String
 }
 
+func pgint8FromInt64Ptr(n *int64) pgtype.Int8 {
+	if n == nil {
+		return pgtype.Int8{Valid: false}
+	}
+	return pgtype.Int8{Int64: *n, Valid: true}
+}
+
+func int64PtrFromPgint8(n pgtype.Int8) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+func timePtrFromPgtimestamptz(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
 func dbWalletToDomain(db *dbgen.Wallet) *Wallet {
 	return &Wallet{
 		Address:                db.Address,
@@ -451,5 +1753,143 @@ func dbWalletToDomain(db *dbgen.Wallet) *Wallet {
 		Status:                 db.Status,
 		CreatedAt:              db.CreatedAt.Time,
 		UpdatedAt:              db.UpdatedAt.Time,
+		Metadata:               db.Metadata,
+		DeletedAt:              timePtrFromPgtimestamptz(db.DeletedAt),
+		Tags:                   tagsFromJSON(db.Tags),
+	}
+}
+
+// tagsToJSON encodes tags as a JSON array. A nil slice returns nil so the
+// column keeps (or defaults) its value; an empty slice encodes as "[]".
+func tagsToJSON(tags []string) []byte {
+	if tags == nil {
+		return nil
+	}
+	data, _ := json.Marshal(tags) // []string always marshals
+	return data
+}
+
+// tagsFromJSON decodes the tags column. It never returns nil.
+func tagsFromJSON(data []byte) []string {
+	tags := []string{}
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &tags) // the column is always a JSON array of strings
+	}
+	return tags
+}
+
+func dbSupportedMintToDomain(db *dbgen.SupportedMint) *SupportedMint {
+	m := &SupportedMint{
+		Network:   db.Network,
+		Mint:      db.Mint,
+		CreatedAt: db.CreatedAt.Time,
+		Symbol:    stringPtrFromPgtext(db.Symbol),
+	}
+	if db.Decimals.Valid {
+		d := int(db.Decimals.Int16)
+		m.Decimals = &d
+	}
+	return m
+}
+
+func dbDigestSubscriptionToDomain(db *dbgen.DigestSubscription) *DigestSubscription {
+	return &DigestSubscription{
+		ID:              db.ID,
+		Address:         db.Address,
+		Network:         db.Network,
+		URL:             db.Url,
+		Interval:        time.Duration(db.IntervalSeconds) * time.Second,
+		LastDeliveredAt: db.LastDeliveredAt.Time,
+		CreatedAt:       db.CreatedAt.Time,
+		FilterID:        int64PtrFromPgint8(db.FilterID),
+	}
+}
+
+func dbTransactionWebhookToDomain(db *dbgen.TransactionWebhook) *TransactionWebhook {
+	return &TransactionWebhook{
+		ID:        db.ID,
+		Address:   db.Address,
+		Network:   db.Network,
+		URL:       db.Url,
+		Secret:    db.Secret,
+		CreatedAt: db.CreatedAt.Time,
+		FilterID:  int64PtrFromPgint8(db.FilterID),
+	}
+}
+
+func dbWalletFilterToDomain(db *dbgen.WalletFilter) *WalletFilter {
+	return &WalletFilter{
+		ID:        db.ID,
+		Address:   db.Address,
+		Network:   db.Network,
+		Name:      db.Name,
+		AssetType: stringPtrFromPgtext(db.AssetType),
+		TokenMint: stringPtrFromPgtext(db.TokenMint),
+		MinAmount: int64PtrFromPgint8(db.MinAmount),
+		Memo:      stringPtrFromPgtext(db.Memo),
+		MemoMatch: db.MemoMatch,
+		JQ:        db.Jq,
+		CreatedAt: db.CreatedAt.Time,
+		UpdatedAt: db.UpdatedAt.Time,
+	}
+}
+
+func dbRegistrationInvoiceToDomain(db *dbgen.RegistrationInvoice) *RegistrationInvoice {
+	return &RegistrationInvoice{
+		WorkflowID:  db.WorkflowID,
+		Address:     db.Address,
+		Network:     db.Network,
+		AssetType:   db.AssetType,
+		TokenMint:   db.TokenMint,
+		Invoice:     db.Invoice,
+		AcceptUntil: db.AcceptUntil.Time,
+		CreatedAt:   db.CreatedAt.Time,
+	}
+}
+
+func dbWebhookDeliveryToDomain(db *dbgen.WebhookDelivery) *WebhookDelivery {
+	d := &WebhookDelivery{
+		ID:            db.ID,
+		Event:         db.Event,
+		SourceID:      db.SourceID,
+		Network:       db.Network,
+		URL:           db.Url,
+		Payload:       db.Payload,
+		Status:        db.Status,
+		Attempts:      int(db.Attempts),
+		LastError:     stringPtrFromPgtext(db.LastError),
+		LastAttemptAt: db.LastAttemptAt.Time,
+		DeliveredAt:   timePtrFromPgtimestamptz(db.DeliveredAt),
+		CreatedAt:     db.CreatedAt.Time,
+	}
+	if db.LastStatusCode.Valid {
+		code := int(db.LastStatusCode.Int32)
+		d.LastStatusCode = &code
+	}
+	return d
+}
+
+func dbAPIKeyToDomain(db *dbgen.ApiKey) *APIKey {
+	return &APIKey{
+		ID:        db.ID,
+		Name:      db.Name,
+		CreatedAt: db.CreatedAt.Time,
+	}
+}
+
+func dbAuditLogToDomain(db *dbgen.AuditLog) *AuditLogEntry {
+	return &AuditLogEntry{
+		ID:            db.ID,
+		CreatedAt:     db.CreatedAt.Time,
+		Actor:         db.Actor,
+		Action:        db.Action,
+		WalletAddress: stringPtrFromPgtext(db.WalletAddress),
+		Network:       stringPtrFromPgtext(db.Network),
+		Method:        db.Method,
+		Path:          db.Path,
+		Status:        int(db.Status),
+		Outcome:       db.Outcome,
+		RequestID:     db.RequestID,
+		RemoteAddr:    db.RemoteAddr,
 	}
 }
@@ -2,6 +2,11 @@ package db
 
 import (
 	"context"
+	"crypto/md5"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,6 +38,7 @@ func TestCreateTransaction(t *testing.T) {
 			TokenMint:          nil,
 			Memo:               &memo,
 			ConfirmationStatus: "finalized",
+			Fee:                5000,
 		}
 
 		txn, err := store.CreateTransaction(ctx, params)
@@ -47,10 +53,68 @@ func TestCreateTransaction(t *testing.T) {
 		assert.NotNil(t, txn.Memo)
 		assert.Equal(t, memo, *txn.Memo)
 		assert.Equal(t, "finalized", txn.ConfirmationStatus)
+		assert.Equal(t, int64(5000), txn.Fee)
+		assert.False(t, txn.MemoTruncated)
 		assert.WithinDuration(t, now, txn.BlockTime, time.Microsecond)
 		assert.WithinDuration(t, time.Now(), txn.CreatedAt, 5*time.Second)
 	})
 
+	// Test creating a transaction whose memo was truncated at ingestion
+	t.Run("create transaction with truncated memo", func(t *testing.T) {
+		memo := "truncated"
+		txn, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          "sig-truncated",
+			WalletAddress:      "wallet123",
+			Network:            "mainnet",
+			Slot:               12344,
+			BlockTime:          now.Add(-time.Minute),
+			Amount:             1000,
+			Memo:               &memo,
+			ConfirmationStatus: "finalized",
+			MemoTruncated:      true,
+		})
+		require.NoError(t, err)
+		assert.True(t, txn.MemoTruncated)
+
+		got, err := store.GetTransaction(ctx, "sig-truncated", "mainnet")
+		require.NoError(t, err)
+		assert.True(t, got.MemoTruncated)
+	})
+
+	// Test flagging a repeated logical payment
+	t.Run("create transactions sharing a payment ID", func(t *testing.T) {
+		paymentID := "inv-1"
+		create := func(sig, wallet string, offset time.Duration) *Transaction {
+			txn, err := store.CreateTransaction(ctx, CreateTransactionParams{
+				Signature:          sig,
+				WalletAddress:      wallet,
+				Network:            "mainnet",
+				Slot:               12300,
+				BlockTime:          now.Add(offset),
+				Amount:             1000,
+				ConfirmationStatus: "finalized",
+				PaymentID:          &paymentID,
+			})
+			require.NoError(t, err)
+			return txn
+		}
+
+		first := create("sig-pay-1", "wallet-pay", -3*time.Minute)
+		require.NotNil(t, first.PaymentID)
+		assert.Equal(t, paymentID, *first.PaymentID)
+		assert.False(t, first.DuplicateLogical)
+
+		second := create("sig-pay-2", "wallet-pay", -2*time.Minute)
+		assert.True(t, second.DuplicateLogical)
+
+		other := create("sig-pay-3", "wallet-other", -time.Minute)
+		assert.False(t, other.DuplicateLogical, "payment IDs are scoped to a wallet")
+
+		got, err := store.GetTransaction(ctx, "sig-pay-2", "mainnet")
+		require.NoError(t, err)
+		assert.True(t, got.DuplicateLogical)
+	})
+
 	// Test creating a SPL token transaction
 	t.Run("create SPL token transaction", func(t *testing.T) {
 		tokenMint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v" // USDC
@@ -74,13 +138,15 @@ func TestCreateTransaction(t *testing.T) {
 		assert.NotNil(t, txn.TokenMint)
 		assert.Equal(t, tokenMint, *txn.TokenMint)
 		assert.Nil(t, txn.Memo)
+		assert.Zero(t, txn.Fee, "fee defaults to 0 when unknown")
 	})
 
-	// Test duplicate signature + block_time (should fail due to composite PK)
+	// Test duplicate row (should fail due to composite PK on signature,
+	// network, wallet, direction and block_time)
 	t.Run("duplicate signature and block_time", func(t *testing.T) {
 		params := CreateTransactionParams{
 			Signature:          "sig123", // Already exists
-			WalletAddress:      "wallet456",
+			WalletAddress:      "wallet123",
 			Network:            "mainnet",
 			Slot:               12347,
 			BlockTime:          now, // Same block_time as first transaction
@@ -234,6 +300,225 @@ func TestListTransactionsByWallet(t *testing.T) {
 	})
 }
 
+func TestListTransactionsByWalletBeforeCursor(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	wallet := "wallet123"
+	sender := "sender111"
+
+	// sigB and sigC share a block, so the signature breaks the tie.
+	for i, bt := range []time.Time{now, now.Add(time.Minute), now.Add(time.Minute), now.Add(2 * time.Minute)} {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          "sig" + string(rune('A'+i)),
+			WalletAddress:      wallet,
+			Network:            "mainnet",
+			Slot:               int64(12345 + i),
+			BlockTime:          bt,
+			Amount:             1000000,
+			FromAddress:        &sender,
+			ConfirmationStatus: "finalized",
+		})
+		require.NoError(t, err)
+	}
+
+	first, err := store.ListTransactionsByWallet(ctx, ListTransactionsByWalletParams{
+		WalletAddress: wallet,
+		Network:       "mainnet",
+		Limit:         2,
+	})
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, "sigD", first[0].Signature)
+	assert.Equal(t, "sigC", first[1].Signature)
+
+	// A transaction arriving between pages doesn't shift the next one.
+	_, err = store.CreateTransaction(ctx, CreateTransactionParams{
+		Signature:          "sigE",
+		WalletAddress:      wallet,
+		Network:            "mainnet",
+		Slot:               12400,
+		BlockTime:          now.Add(3 * time.Minute),
+		Amount:             1000000,
+		FromAddress:        &sender,
+		ConfirmationStatus: "finalized",
+	})
+	require.NoError(t, err)
+
+	last := first[1]
+	next, err := store.ListTransactionsByWalletBeforeCursor(ctx, ListTransactionsByWalletBeforeCursorParams{
+		WalletAddress: wallet,
+		Network:       "mainnet",
+		Before:        TransactionCursor{BlockTime: last.BlockTime, Signature: last.Signature},
+		Limit:         10,
+	})
+	require.NoError(t, err)
+	require.Len(t, next, 2)
+	assert.Equal(t, "sigB", next[0].Signature, "same block as the cursor, lower signature")
+	assert.Equal(t, "sigA", next[1].Signature)
+}
+
+func TestListTransactionsByWallet_Sort(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	sender := "sender111"
+
+	// Amounts deliberately out of block-time order: sigA is oldest, sigC
+	// newest, and sigB the largest.
+	amounts := map[string]int64{"sigA": 2000000, "sigB": 9000000, "sigC": 1000000}
+	for i, sig := range []string{"sigA", "sigB", "sigC"} {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          sig,
+			WalletAddress:      "wallet123",
+			Network:            "mainnet",
+			Slot:               int64(12345 + i),
+			BlockTime:          now.Add(time.Duration(i) * time.Minute),
+			Amount:             amounts[sig],
+			FromAddress:        &sender,
+			ConfirmationStatus: "finalized",
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		sort TransactionSort
+		want []string
+	}{
+		{"", []string{"sigC", "sigB", "sigA"}},
+		{SortBlockTimeDesc, []string{"sigC", "sigB", "sigA"}},
+		{SortBlockTimeAsc, []string{"sigA", "sigB", "sigC"}},
+		{SortAmountDesc, []string{"sigB", "sigA", "sigC"}},
+		{SortAmountAsc, []string{"sigC", "sigA", "sigB"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			txns, err := store.ListTransactionsByWallet(ctx, ListTransactionsByWalletParams{
+				WalletAddress: "wallet123",
+				Network:       "mainnet",
+				Limit:         10,
+				Sort:          tt.sort,
+			})
+			require.NoError(t, err)
+
+			got := make([]string, len(txns))
+			for i, txn := range txns {
+				got[i] = txn.Signature
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("paginates within the ordering", func(t *testing.T) {
+		txns, err := store.ListTransactionsByWallet(ctx, ListTransactionsByWalletParams{
+			WalletAddress: "wallet123",
+			Network:       "mainnet",
+			Limit:         1,
+			Offset:        1,
+			Sort:          SortAmountDesc,
+		})
+		require.NoError(t, err)
+		require.Len(t, txns, 1)
+		assert.Equal(t, "sigA", txns[0].Signature)
+	})
+
+	t.Run("unsupported sort", func(t *testing.T) {
+		_, err := store.ListTransactionsByWallet(ctx, ListTransactionsByWalletParams{
+			WalletAddress: "wallet123",
+			Network:       "mainnet",
+			Limit:         10,
+			Sort:          "slot_desc",
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestListTransactionsByWallet_Direction(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	wallet := "wallet123"
+	other := "other111"
+
+	rows := []struct {
+		sig       string
+		from      string
+		direction string
+	}{
+		{"sigDeposit", other, ""}, // empty defaults to in
+		{"sigWithdraw", wallet, DirectionOut},
+		{"sigSelf", wallet, DirectionSelf},
+	}
+	for i, row := range rows {
+		from := row.from
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          row.sig,
+			WalletAddress:      wallet,
+			Network:            "mainnet",
+			Slot:               int64(12345 + i),
+			BlockTime:          now.Add(time.Duration(i) * time.Minute),
+			Amount:             1000000,
+			FromAddress:        &from,
+			ConfirmationStatus: "finalized",
+			Direction:          row.direction,
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		direction string
+		want      []string
+	}{
+		{"", []string{"sigSelf", "sigWithdraw", "sigDeposit"}},
+		{DirectionIn, []string{"sigDeposit"}},
+		{DirectionOut, []string{"sigWithdraw"}},
+		{DirectionSelf, []string{"sigSelf"}},
+	}
+	for _, tt := range tests {
+		t.Run("direction="+tt.direction, func(t *testing.T) {
+			txns, err := store.ListTransactionsByWallet(ctx, ListTransactionsByWalletParams{
+				WalletAddress: wallet,
+				Network:       "mainnet",
+				Direction:     tt.direction,
+				Limit:         10,
+				Sort:          SortBlockTimeDesc,
+			})
+			require.NoError(t, err)
+
+			got := make([]string, len(txns))
+			for i, txn := range txns {
+				got[i] = txn.Signature
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("stored direction round-trips", func(t *testing.T) {
+		txn, err := store.GetTransaction(ctx, "sigDeposit", "mainnet")
+		require.NoError(t, err)
+		assert.Equal(t, DirectionIn, txn.Direction)
+
+		txn, err = store.GetTransaction(ctx, "sigWithdraw", "mainnet")
+		require.NoError(t, err)
+		assert.Equal(t, DirectionOut, txn.Direction)
+	})
+}
+
 func TestListTransactionsByWalletAndTimeRange(t *testing.T) {
 	SkipIfNoTestDB(t)
 
@@ -288,6 +573,73 @@ func TestListTransactionsByWalletAndTimeRange(t *testing.T) {
 	})
 }
 
+func TestListTransactionsByWallets(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sender := "sender123"
+
+	// walletA has three mainnet txns, walletB one mainnet and one devnet txn
+	txns := []struct {
+		sig, wallet, network string
+		offset               time.Duration
+	}{
+		{"multiA1", "walletA", "mainnet", 1 * time.Hour},
+		{"multiA2", "walletA", "mainnet", 2 * time.Hour},
+		{"multiA3", "walletA", "mainnet", 3 * time.Hour},
+		{"multiB1", "walletB", "mainnet", 1 * time.Hour},
+		{"multiB2", "walletB", "devnet", 2 * time.Hour},
+	}
+	for i, tx := range txns {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          tx.sig,
+			WalletAddress:      tx.wallet,
+			Network:            tx.network,
+			Slot:               int64(20000 + i),
+			BlockTime:          baseTime.Add(tx.offset),
+			Amount:             1000,
+			ConfirmationStatus: "finalized",
+			FromAddress:        &sender,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("limits per wallet and matches network", func(t *testing.T) {
+		result, err := store.ListTransactionsByWallets(ctx, ListTransactionsByWalletsParams{
+			Wallets: []WalletKey{
+				{Address: "walletA", Network: "mainnet"},
+				{Address: "walletB", Network: "mainnet"},
+			},
+			StartTime:      baseTime,
+			EndTime:        baseTime.Add(24 * time.Hour),
+			LimitPerWallet: 2,
+		})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+
+		assert.Equal(t, "multiA3", result[0].Signature)
+		assert.Equal(t, "multiA2", result[1].Signature)
+		assert.Equal(t, "multiB1", result[2].Signature)
+	})
+
+	t.Run("respects time window", func(t *testing.T) {
+		result, err := store.ListTransactionsByWallets(ctx, ListTransactionsByWalletsParams{
+			Wallets:        []WalletKey{{Address: "walletA", Network: "mainnet"}},
+			StartTime:      baseTime.Add(90 * time.Minute),
+			EndTime:        baseTime.Add(150 * time.Minute),
+			LimitPerWallet: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "multiA2", result[0].Signature)
+	})
+}
+
 func TestCountTransactionsByWallet(t *testing.T) {
 	SkipIfNoTestDB(t)
 
@@ -325,6 +677,59 @@ func TestCountTransactionsByWallet(t *testing.T) {
 	assert.Equal(t, int64(0), count)
 }
 
+func TestCountTransactionsByTimeBucket(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Two transactions written in the first hour (one on devnet), none in
+	// the second, one in the third. Block times are deliberately unrelated.
+	for i, row := range []struct {
+		network string
+		written time.Time
+	}{
+		{"mainnet", base.Add(5 * time.Minute)},
+		{"devnet", base.Add(50 * time.Minute)},
+		{"mainnet", base.Add(2*time.Hour + 30*time.Minute)},
+		{"mainnet", base.Add(5 * time.Hour)}, // outside the window
+	} {
+		store.MustExec(t, `
+			INSERT INTO transactions (signature, wallet_address, network, slot, block_time, amount, confirmation_status, created_at)
+			VALUES ($1, 'walletThroughput', $2, $3, $4, 1000, 'finalized', $5)`,
+			"throughput"+string(rune('A'+i)), row.network, 50000+i, base.AddDate(0, -1, 0), row.written)
+	}
+
+	buckets, err := store.CountTransactionsByTimeBucket(ctx, CountTransactionsByTimeBucketParams{
+		Start:       base,
+		End:         base.Add(3 * time.Hour),
+		BucketWidth: time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []TimeBucketCount{
+		{Start: base, Count: 2},
+		{Start: base.Add(time.Hour), Count: 0},
+		{Start: base.Add(2 * time.Hour), Count: 1},
+	}, buckets)
+
+	buckets, err = store.CountTransactionsByTimeBucket(ctx, CountTransactionsByTimeBucketParams{
+		Start:       base,
+		End:         base.Add(3 * time.Hour),
+		BucketWidth: time.Hour,
+		Network:     "devnet",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []TimeBucketCount{
+		{Start: base, Count: 1},
+		{Start: base.Add(time.Hour), Count: 0},
+		{Start: base.Add(2 * time.Hour), Count: 0},
+	}, buckets)
+}
+
 func TestGetLatestTransactionByWallet(t *testing.T) {
 	SkipIfNoTestDB(t)
 
@@ -472,3 +877,1107 @@ func TestDeleteTransactionsOlderThan(t *testing.T) {
 	assert.Equal(t, "newB", txns[0].Signature)
 	assert.Equal(t, "newA", txns[1].Signature)
 }
+
+func TestUpdateTransactionMetadata(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+
+	_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+		Signature:          "sig-metadata",
+		WalletAddress:      "wallet123",
+		Network:            "mainnet",
+		Slot:               12345,
+		BlockTime:          time.Now().UTC(),
+		Amount:             1000000,
+		ConfirmationStatus: "finalized",
+	})
+	require.NoError(t, err)
+
+	t.Run("set metadata", func(t *testing.T) {
+		txn, err := store.UpdateTransactionMetadata(ctx, "sig-metadata", "mainnet", []byte(`{"order_id": "abc"}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"order_id": "abc"}`, string(txn.Metadata))
+
+		got, err := store.GetTransaction(ctx, "sig-metadata", "mainnet")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"order_id": "abc"}`, string(got.Metadata))
+	})
+
+	t.Run("clear metadata", func(t *testing.T) {
+		txn, err := store.UpdateTransactionMetadata(ctx, "sig-metadata", "mainnet", nil)
+		require.NoError(t, err)
+		assert.Nil(t, txn.Metadata)
+	})
+
+	t.Run("wrong network", func(t *testing.T) {
+		_, err := store.UpdateTransactionMetadata(ctx, "sig-metadata", "devnet", []byte(`{}`))
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestUpdateTransactionStatus(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, status := range []string{"confirmed", "finalized", "confirmed"} {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          "status" + string(rune('A'+i)),
+			WalletAddress:      "walletStatus",
+			Network:            "mainnet",
+			Slot:               int64(30000 + i),
+			BlockTime:          baseTime.Add(time.Duration(i) * time.Hour),
+			Amount:             1000,
+			ConfirmationStatus: status,
+		})
+		require.NoError(t, err)
+	}
+
+	confirmed, err := store.ListTransactionsByConfirmationStatus(ctx, "confirmed", "mainnet", 10)
+	require.NoError(t, err)
+	require.Len(t, confirmed, 2)
+	assert.Equal(t, "statusA", confirmed[0].Signature, "oldest first")
+
+	updated, err := store.UpdateTransactionStatus(ctx, "statusA", "mainnet", "finalized")
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	assert.Equal(t, "finalized", updated[0].ConfirmationStatus)
+
+	confirmed, err = store.ListTransactionsByConfirmationStatus(ctx, "confirmed", "mainnet", 10)
+	require.NoError(t, err)
+	require.Len(t, confirmed, 1)
+	assert.Equal(t, "statusC", confirmed[0].Signature)
+
+	_, err = store.UpdateTransactionStatus(ctx, "missing", "mainnet", "finalized")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestSearchTransactionsByMemo(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	memos := []string{
+		`{"workflow_id":"payment-abc"}`,
+		`{"workflow_id":"payment-xyz"}`,
+		"Invoice 100% paid",
+		"invoice_1001",
+	}
+	for i, memo := range memos {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          "memo" + string(rune('A'+i)),
+			WalletAddress:      "walletMemo",
+			Network:            "mainnet",
+			Slot:               int64(40000 + i),
+			BlockTime:          baseTime.Add(time.Duration(i) * time.Hour),
+			Amount:             1000,
+			Memo:               &memo,
+			ConfirmationStatus: "finalized",
+		})
+		require.NoError(t, err)
+	}
+
+	signatures := func(txns []*Transaction) []string {
+		sigs := make([]string, len(txns))
+		for i, txn := range txns {
+			sigs[i] = txn.Signature
+		}
+		return sigs
+	}
+
+	tests := []struct {
+		name    string
+		params  SearchTransactionsByMemoParams
+		wantSig []string
+	}{
+		{
+			name:    "contains json fragment",
+			params:  SearchTransactionsByMemoParams{Query: `"workflow_id":"payment-`},
+			wantSig: []string{"memoB", "memoA"},
+		},
+		{
+			name:    "case insensitive",
+			params:  SearchTransactionsByMemoParams{Query: "INVOICE"},
+			wantSig: []string{"memoD", "memoC"},
+		},
+		{
+			name:    "prefix",
+			params:  SearchTransactionsByMemoParams{Query: "invoice", Prefix: true},
+			wantSig: []string{"memoD", "memoC"},
+		},
+		{
+			name:    "prefix excludes mid-memo match",
+			params:  SearchTransactionsByMemoParams{Query: "payment", Prefix: true},
+			wantSig: []string{},
+		},
+		{
+			name:    "percent is literal",
+			params:  SearchTransactionsByMemoParams{Query: "100%"},
+			wantSig: []string{"memoC"},
+		},
+		{
+			name:    "underscore is literal",
+			params:  SearchTransactionsByMemoParams{Query: "invoice_"},
+			wantSig: []string{"memoD"},
+		},
+		{
+			name:    "limit",
+			params:  SearchTransactionsByMemoParams{Query: "payment", Limit: 1},
+			wantSig: []string{"memoB"},
+		},
+		{
+			name:    "other network",
+			params:  SearchTransactionsByMemoParams{Query: "payment", Network: "devnet"},
+			wantSig: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			params.WalletAddress = "walletMemo"
+			if params.Network == "" {
+				params.Network = "mainnet"
+			}
+			if params.Limit == 0 {
+				params.Limit = 10
+			}
+
+			txns, err := store.SearchTransactionsByMemo(ctx, params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSig, signatures(txns))
+		})
+	}
+}
+
+func TestSearchTransactionsByMemo_UsesTrigramIndex(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// One busy wallet, so the wallet index alone can't narrow the search.
+	store.MustExec(t, `
+		INSERT INTO transactions (signature, wallet_address, network, slot, block_time, amount, memo, confirmation_status)
+		SELECT 'plan' || i, 'walletPlan', 'mainnet', i, $1::timestamptz + i * INTERVAL '1 minute', 1000,
+		       '{"workflow_id":"payment-' || md5(i::text) || '"}', 'finalized'
+		FROM generate_series(1, 2000) AS i`, baseTime)
+	store.MustExec(t, "ANALYZE transactions")
+
+	tx, err := store.pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	// Keep the planner off sequential scans so a small test table exercises
+	// the same plan a large production table would.
+	_, err = tx.Exec(ctx, "SET LOCAL enable_seqscan = off")
+	require.NoError(t, err)
+
+	// Mirrors the SearchTransactionsByMemo query in queries/transactions.sql.
+	rows, err := tx.Query(ctx, `
+		EXPLAIN SELECT * FROM transactions
+		WHERE wallet_address = $1
+		  AND network = $2
+		  AND memo ILIKE $3::text
+		ORDER BY block_time DESC
+		LIMIT $4`,
+		"walletPlan", "mainnet", "%"+fmt.Sprintf("%x", md5.Sum([]byte("1000")))+"%", 100)
+	require.NoError(t, err)
+
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan.WriteString(line + "\n")
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Contains(t, plan.String(), "idx_transactions_memo_trgm", "memo search should use the trigram index:\n%s", plan.String())
+}
+
+func TestFindPaymentByMemo(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	payments := []struct {
+		sig    string
+		memo   string
+		amount int64
+		mint   *string
+	}{
+		{"payUnderpaid", "order-42", 500, &usdc},
+		{"payUSDC", "order-42", 1000000, &usdc},
+		{"paySOL", "order-42", 2000000, nil},
+		{"payOther", "order-420", 1000000, &usdc},
+	}
+	for i, p := range payments {
+		memo := p.memo
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          p.sig,
+			WalletAddress:      "walletPay",
+			Network:            "mainnet",
+			Slot:               int64(50000 + i),
+			BlockTime:          baseTime.Add(time.Duration(i) * time.Hour),
+			Amount:             p.amount,
+			TokenMint:          p.mint,
+			Memo:               &memo,
+			ConfirmationStatus: "finalized",
+		})
+		require.NoError(t, err)
+	}
+
+	// A transfer the wallet sent is not a payment to it.
+	refund := "refund-7"
+	_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+		Signature:          "payOutgoing",
+		WalletAddress:      "walletPay",
+		Network:            "mainnet",
+		Slot:               60000,
+		BlockTime:          baseTime,
+		Amount:             1000000,
+		Memo:               &refund,
+		ConfirmationStatus: "finalized",
+		Direction:          DirectionOut,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		params  FindPaymentByMemoParams
+		wantSig string // empty for no match
+	}{
+		{"outgoing never matches", FindPaymentByMemoParams{Memo: "refund-7"}, ""},
+		{"earliest match", FindPaymentByMemoParams{Memo: "order-42"}, "payUnderpaid"},
+		{"min amount", FindPaymentByMemoParams{Memo: "order-42", MinAmount: 1000}, "payUSDC"},
+		{"sol only", FindPaymentByMemoParams{Memo: "order-42", Asset: "sol"}, "paySOL"},
+		{"token mint", FindPaymentByMemoParams{Memo: "order-42", MinAmount: 1000000, Asset: usdc}, "payUSDC"},
+		{"exact memo only", FindPaymentByMemoParams{Memo: "order-4"}, ""},
+		{"case sensitive", FindPaymentByMemoParams{Memo: "ORDER-42"}, ""},
+		{"amount too large", FindPaymentByMemoParams{Memo: "order-420", MinAmount: 1000001}, ""},
+		{"other network", FindPaymentByMemoParams{Memo: "order-42", Network: "devnet"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			params.WalletAddress = "walletPay"
+			if params.Network == "" {
+				params.Network = "mainnet"
+			}
+
+			txn, err := store.FindPaymentByMemo(ctx, params)
+			if tt.wantSig == "" {
+				assert.ErrorIs(t, err, pgx.ErrNoRows)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSig, txn.Signature)
+		})
+	}
+}
+
+func TestCreateTransactions(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	paymentID := "inv-batch"
+	memo := "hello"
+	txn := func(sig string, offset int, pid *string) CreateTransactionParams {
+		return CreateTransactionParams{
+			Signature:          sig,
+			WalletAddress:      "walletBatch",
+			Network:            "mainnet",
+			Slot:               int64(70000 + offset),
+			BlockTime:          baseTime.Add(time.Duration(offset) * time.Minute),
+			Amount:             1000,
+			Memo:               &memo,
+			ConfirmationStatus: "finalized",
+			PaymentID:          pid,
+		}
+	}
+
+	// Two transactions are already stored before the batch arrives.
+	_, err := store.CreateTransaction(ctx, txn("existing1", 0, nil))
+	require.NoError(t, err)
+	_, err = store.CreateTransaction(ctx, txn("existing2", 1, nil))
+	require.NoError(t, err)
+
+	batch := []CreateTransactionParams{
+		txn("existing1", 0, nil),
+		txn("new1", 2, &paymentID),
+		txn("existing2", 1, nil),
+		txn("new2", 3, &paymentID),
+		txn("new1", 2, &paymentID), // repeated within the batch
+		txn("new3", 4, nil),
+	}
+	written, err := store.CreateTransactions(ctx, batch)
+	require.NoError(t, err)
+
+	bySig := make(map[string]*Transaction)
+	for _, w := range written {
+		bySig[w.Signature] = w
+	}
+	require.Len(t, written, 3, "only new transactions are written")
+	assert.Contains(t, bySig, "new1")
+	assert.Contains(t, bySig, "new2")
+	assert.Contains(t, bySig, "new3")
+	assert.False(t, bySig["new1"].DuplicateLogical)
+	assert.True(t, bySig["new2"].DuplicateLogical, "an earlier row of the batch had the payment ID")
+	assert.Nil(t, bySig["new3"].PaymentID)
+	assert.Nil(t, bySig["new3"].TokenMint)
+	require.NotNil(t, bySig["new3"].Memo)
+	assert.Equal(t, memo, *bySig["new3"].Memo)
+
+	count, err := store.CountTransactionsByWallet(ctx, "walletBatch", "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+
+	// Replaying the whole batch writes nothing.
+	written, err = store.CreateTransactions(ctx, batch)
+	require.NoError(t, err)
+	assert.Empty(t, written)
+
+	written, err = store.CreateTransactions(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, written)
+}
+
+func TestCreateTransactions_TransferBetweenWallets(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	blockTime := time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)
+	sender := "walletSender"
+	row := func(wallet, direction string) CreateTransactionParams {
+		return CreateTransactionParams{
+			Signature:          "sig-between",
+			WalletAddress:      wallet,
+			Network:            "mainnet",
+			Slot:               80000,
+			BlockTime:          blockTime,
+			Amount:             5000,
+			ConfirmationStatus: "confirmed",
+			FromAddress:        &sender,
+			Direction:          direction,
+		}
+	}
+
+	// The receiver's incoming row and the sender's outgoing row share a
+	// signature; both are kept, and a redelivery of either is skipped.
+	batch := []CreateTransactionParams{
+		row("walletReceiver", DirectionIn),
+		row(sender, DirectionOut),
+		row("walletReceiver", DirectionIn),
+	}
+	written, err := store.CreateTransactions(ctx, batch)
+	require.NoError(t, err)
+	require.Len(t, written, 2)
+
+	_, err = store.CreateTransaction(ctx, row(sender, DirectionOut))
+	require.Error(t, err)
+
+	updated, err := store.UpdateTransactionStatus(ctx, "sig-between", "mainnet", "finalized")
+	require.NoError(t, err)
+	require.Len(t, updated, 2, "status is updated on both rows")
+	for _, txn := range updated {
+		assert.Equal(t, "finalized", txn.ConfirmationStatus)
+	}
+}
+
+// BenchmarkCreateTransactions compares writing transactions one row at a
+// time with writing them as a single batch.
+func BenchmarkCreateTransactions(b *testing.B) {
+	SkipIfNoTestDB(b)
+
+	store := NewTestStore(b)
+	defer store.Close()
+	defer store.Cleanup(b)
+
+	ctx := context.Background()
+	const batchSize = 100
+	params := func(run string, n int) []CreateTransactionParams {
+		out := make([]CreateTransactionParams, batchSize)
+		for i := range out {
+			out[i] = CreateTransactionParams{
+				Signature:          fmt.Sprintf("bench-%s-%d-%d", run, n, i),
+				WalletAddress:      "walletBench",
+				Network:            "mainnet",
+				Slot:               int64(i),
+				BlockTime:          time.Now().UTC(),
+				Amount:             1000,
+				ConfirmationStatus: "finalized",
+			}
+		}
+		return out
+	}
+
+	b.Run("loop", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, p := range params("loop", n) {
+				if _, err := store.CreateTransaction(ctx, p); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := store.CreateTransactions(ctx, params("batch", n)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestListTransactionsByAmountRange(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	payments := []struct {
+		sig    string
+		amount int64
+		mint   *string
+	}{
+		{"usdc10a", 10000000, &usdc},
+		{"usdc5", 5000000, &usdc},
+		{"usdc10b", 10000000, &usdc},
+		{"sol10", 10000000, nil}, // same base units, different asset
+	}
+	for i, p := range payments {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          p.sig,
+			WalletAddress:      "walletAmount",
+			Network:            "mainnet",
+			Slot:               int64(60000 + i),
+			BlockTime:          baseTime.Add(time.Duration(i) * time.Hour),
+			Amount:             p.amount,
+			TokenMint:          p.mint,
+			ConfirmationStatus: "finalized",
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		params   ListTransactionsByAmountRangeParams
+		wantSigs []string
+	}{
+		{"exact amount newest first", ListTransactionsByAmountRangeParams{Asset: usdc, MinAmount: 10000000, MaxAmount: 10000000}, []string{"usdc10b", "usdc10a"}},
+		{"range", ListTransactionsByAmountRangeParams{Asset: usdc, MinAmount: 1, MaxAmount: 9999999}, []string{"usdc5"}},
+		{"sol only", ListTransactionsByAmountRangeParams{Asset: "sol", MinAmount: 0, MaxAmount: 10000000}, []string{"sol10"}},
+		{"offset", ListTransactionsByAmountRangeParams{Asset: usdc, MinAmount: 0, MaxAmount: 10000000, Offset: 1}, []string{"usdc5", "usdc10a"}},
+		{"limit", ListTransactionsByAmountRangeParams{Asset: usdc, MinAmount: 0, MaxAmount: 10000000, Limit: 1}, []string{"usdc10b"}},
+		{"no match", ListTransactionsByAmountRangeParams{Asset: usdc, MinAmount: 20000000, MaxAmount: 30000000}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			params.WalletAddress = "walletAmount"
+			params.Network = "mainnet"
+			if params.Limit == 0 {
+				params.Limit = 100
+			}
+
+			txns, err := store.ListTransactionsByAmountRange(ctx, params)
+			require.NoError(t, err)
+			var sigs []string
+			for _, txn := range txns {
+				sigs = append(sigs, txn.Signature)
+			}
+			assert.Equal(t, tt.wantSigs, sigs)
+		})
+	}
+}
+
+func TestEscapeLikePattern(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"100%", `100\%`},
+		{"a_b", `a\_b`},
+		{`back\slash`, `back\\slash`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, escapeLikePattern(tt.in), tt.in)
+	}
+}
+
+func TestSetSupportedMintInfo(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	const usdc = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	added, err := store.AddSupportedMint(ctx, "mainnet", usdc)
+	require.NoError(t, err)
+	assert.Nil(t, added.Decimals)
+	assert.Nil(t, added.Symbol)
+
+	updated, err := store.SetSupportedMintInfo(ctx, "mainnet", usdc, 6, "USDC")
+	require.NoError(t, err)
+	require.NotNil(t, updated.Decimals)
+	assert.Equal(t, 6, *updated.Decimals)
+	require.NotNil(t, updated.Symbol)
+	assert.Equal(t, "USDC", *updated.Symbol)
+
+	mints, err := store.ListSupportedMints(ctx, "mainnet")
+	require.NoError(t, err)
+	require.Len(t, mints, 1)
+	assert.Equal(t, 6, *mints[0].Decimals)
+
+	_, err = store.SetSupportedMintInfo(ctx, "devnet", usdc, 6, "USDC")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestDigestSubscriptions(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+
+	sub, err := store.CreateDigestSubscription(ctx, CreateDigestSubscriptionParams{
+		Address:  "wallet1",
+		Network:  "mainnet",
+		URL:      "https://example.com/digest",
+		Interval: time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, sub.Interval)
+	assert.Equal(t, "https://example.com/digest", sub.URL)
+	_, err = store.CreateDigestSubscription(ctx, CreateDigestSubscriptionParams{
+		Address:  "wallet2",
+		Network:  "devnet",
+		URL:      "https://example.com/other",
+		Interval: 10 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	all, err := store.ListDigestSubscriptions(ctx, "", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	filtered, err := store.ListDigestSubscriptions(ctx, "wallet1", "mainnet")
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, sub.ID, filtered[0].ID)
+
+	// Neither is due yet; after 30m only the 10m one is.
+	due, err := store.ListDueDigestSubscriptions(ctx, sub.LastDeliveredAt.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = store.ListDueDigestSubscriptions(ctx, sub.LastDeliveredAt.Add(30*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "wallet2", due[0].Address)
+
+	windowEnd := sub.LastDeliveredAt.Add(time.Hour)
+	require.NoError(t, store.MarkDigestDelivered(ctx, sub.ID, windowEnd))
+	filtered, err = store.ListDigestSubscriptions(ctx, "wallet1", "")
+	require.NoError(t, err)
+	assert.True(t, filtered[0].LastDeliveredAt.Equal(windowEnd))
+
+	deleted, err := store.DeleteDigestSubscription(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = store.DeleteDigestSubscription(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestTransactionWebhooks(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+
+	hook, err := store.CreateTransactionWebhook(ctx, CreateTransactionWebhookParams{
+		Address: "wallet1",
+		Network: "mainnet",
+		URL:     "https://example.com/hook",
+		Secret:  "0123456789abcdef",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook", hook.URL)
+	assert.Equal(t, "0123456789abcdef", hook.Secret)
+	_, err = store.CreateTransactionWebhook(ctx, CreateTransactionWebhookParams{
+		Address: "wallet2",
+		Network: "devnet",
+		URL:     "https://example.com/other",
+		Secret:  "fedcba9876543210",
+	})
+	require.NoError(t, err)
+
+	all, err := store.ListTransactionWebhooks(ctx, "", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	filtered, err := store.ListTransactionWebhooks(ctx, "wallet1", "mainnet")
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, hook.ID, filtered[0].ID)
+	filtered, err = store.ListTransactionWebhooks(ctx, "wallet1", "devnet")
+	require.NoError(t, err)
+	assert.Empty(t, filtered)
+
+	deleted, err := store.DeleteTransactionWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = store.DeleteTransactionWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestWalletFilters(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	splToken, sol := "spl-token", "sol"
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	minAmount := int64(10000000)
+	memoPrefix := "order:"
+
+	filter, err := store.SaveWalletFilter(ctx, SaveWalletFilterParams{
+		Address:   "wallet1",
+		Network:   "mainnet",
+		Name:      "big-usdc",
+		AssetType: &splToken,
+		TokenMint: &usdc,
+		MinAmount: &minAmount,
+		MemoMatch: "exact",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "big-usdc", filter.Name)
+	require.NotNil(t, filter.MinAmount)
+	assert.Equal(t, minAmount, *filter.MinAmount)
+	assert.Nil(t, filter.Memo)
+	assert.Empty(t, filter.JQ)
+
+	// Saving again replaces the criteria and keeps the ID.
+	updated, err := store.SaveWalletFilter(ctx, SaveWalletFilterParams{
+		Address:   "wallet1",
+		Network:   "mainnet",
+		Name:      "big-usdc",
+		Memo:      &memoPrefix,
+		MemoMatch: "prefix",
+		JQ:        []string{`.order_id != null`},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filter.ID, updated.ID)
+	assert.Nil(t, updated.MinAmount)
+	assert.Equal(t, "prefix", updated.MemoMatch)
+	assert.Equal(t, []string{`.order_id != null`}, updated.JQ)
+
+	got, err := store.GetWalletFilter(ctx, "wallet1", "mainnet", "big-usdc")
+	require.NoError(t, err)
+	assert.Equal(t, updated.ID, got.ID)
+	_, err = store.GetWalletFilter(ctx, "wallet1", "devnet", "big-usdc")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	byID, err := store.GetWalletFilterByID(ctx, filter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "big-usdc", byID.Name)
+
+	_, err = store.SaveWalletFilter(ctx, SaveWalletFilterParams{Address: "wallet1", Network: "mainnet", Name: "any-sol", AssetType: &sol, MemoMatch: "exact"})
+	require.NoError(t, err)
+	filters, err := store.ListWalletFilters(ctx, "wallet1", "mainnet")
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+	assert.Equal(t, "any-sol", filters[0].Name)
+
+	// A filter applied by a digest can't be deleted.
+	sub, err := store.CreateDigestSubscription(ctx, CreateDigestSubscriptionParams{
+		Address:  "wallet1",
+		Network:  "mainnet",
+		URL:      "https://example.com/digest",
+		Interval: time.Hour,
+		FilterID: &filter.ID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, sub.FilterID)
+	assert.Equal(t, filter.ID, *sub.FilterID)
+	_, err = store.DeleteWalletFilter(ctx, "wallet1", "mainnet", "big-usdc")
+	assert.ErrorIs(t, err, ErrWalletFilterInUse)
+
+	_, err = store.DeleteDigestSubscription(ctx, sub.ID)
+	require.NoError(t, err)
+
+	// So can't one applied by a transaction webhook.
+	hook, err := store.CreateTransactionWebhook(ctx, CreateTransactionWebhookParams{
+		Address:  "wallet1",
+		Network:  "mainnet",
+		URL:      "https://example.com/hook",
+		Secret:   "0123456789abcdef",
+		FilterID: &filter.ID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, hook.FilterID)
+	assert.Equal(t, filter.ID, *hook.FilterID)
+	_, err = store.DeleteWalletFilter(ctx, "wallet1", "mainnet", "big-usdc")
+	assert.ErrorIs(t, err, ErrWalletFilterInUse)
+
+	_, err = store.DeleteTransactionWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	deleted, err := store.DeleteWalletFilter(ctx, "wallet1", "mainnet", "big-usdc")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = store.DeleteWalletFilter(ctx, "wallet1", "mainnet", "big-usdc")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestGetIngestionLatency(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// Written now (created_at defaults to NOW()), 10s to 5m after their block.
+	for i, lag := range []time.Duration{10 * time.Second, 20 * time.Second, 5 * time.Minute} {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          "lag" + string(rune('A'+i)),
+			WalletAddress:      "walletLag",
+			Network:            "mainnet",
+			Slot:               int64(50000 + i),
+			BlockTime:          now.Add(-lag),
+			Amount:             1000,
+			ConfirmationStatus: "confirmed",
+		})
+		require.NoError(t, err)
+	}
+
+	latency, err := store.GetIngestionLatency(ctx, IngestionLatencyParams{
+		Start: now.Add(-time.Minute),
+		End:   now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), latency.Count)
+	assert.InDelta(t, (20 * time.Second).Seconds(), latency.P50.Seconds(), 2)
+	assert.InDelta(t, (5 * time.Minute).Seconds(), latency.Max.Seconds(), 2)
+
+	latency, err = store.GetIngestionLatency(ctx, IngestionLatencyParams{
+		Start:         now.Add(-time.Minute),
+		End:           now.Add(time.Minute),
+		WalletAddress: "otherWallet",
+	})
+	require.NoError(t, err)
+	assert.Zero(t, latency.Count)
+	assert.Zero(t, latency.P99)
+}
+
+func TestSummarizeTransactionsByAsset(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	blockTime := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, txn := range []struct {
+		sig, network string
+		mint         *string
+		amount       int64
+		direction    string
+	}{
+		{"sumSol1", "mainnet", nil, 1000, DirectionIn},
+		{"sumSol2", "mainnet", nil, 2500, DirectionSelf},
+		{"sumSolOut", "mainnet", nil, 9999, DirectionOut},
+		{"sumUSDC1", "mainnet", &usdc, math.MaxInt64, DirectionIn},
+		{"sumUSDC2", "mainnet", &usdc, 1, DirectionIn},
+		{"sumDevnet", "devnet", nil, 7, DirectionIn},
+	} {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          txn.sig,
+			WalletAddress:      "walletSummary",
+			Network:            txn.network,
+			Slot:               int64(90000 + i),
+			BlockTime:          blockTime,
+			Amount:             txn.amount,
+			TokenMint:          txn.mint,
+			ConfirmationStatus: "confirmed",
+			Direction:          txn.direction,
+		})
+		require.NoError(t, err)
+	}
+
+	totals, err := store.SummarizeTransactionsByAsset(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []AssetTransactionTotals{
+		{Network: "devnet", Asset: "sol", Count: 1, ReceivedCount: 1, ReceivedAmount: "7"},
+		{Network: "mainnet", Asset: usdc, Count: 2, ReceivedCount: 2, ReceivedAmount: "9223372036854775808"},
+		{Network: "mainnet", Asset: "sol", Count: 3, ReceivedCount: 2, ReceivedAmount: "3500"},
+	}, totals, "outgoing transfers count as stored but not received, and sums may exceed int64")
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	attempt := func(source, status string, code int, errMsg string) *WebhookDelivery {
+		t.Helper()
+		d, err := store.RecordWebhookDeliveryAttempt(ctx, RecordWebhookDeliveryAttemptParams{
+			Event:      "registration.completed",
+			SourceID:   source,
+			Network:    "mainnet",
+			URL:        "https://example.com/hook",
+			Payload:    []byte(`{"workflow_id":"` + source + `"}`),
+			Status:     status,
+			StatusCode: code,
+			Error:      errMsg,
+		})
+		require.NoError(t, err)
+		return d
+	}
+
+	first := attempt("reg-1", WebhookDeliveryRetrying, 502, "callback endpoint returned 502")
+	assert.Equal(t, 1, first.Attempts)
+	require.NotNil(t, first.LastStatusCode)
+	assert.Equal(t, 502, *first.LastStatusCode)
+	assert.Nil(t, first.DeliveredAt)
+
+	second := attempt("reg-1", WebhookDeliveryDelivered, 204, "")
+	assert.Equal(t, first.ID, second.ID, "attempts for one source update the same delivery")
+	assert.Equal(t, 2, second.Attempts)
+	assert.Equal(t, WebhookDeliveryDelivered, second.Status)
+	assert.Nil(t, second.LastError)
+	assert.NotNil(t, second.DeliveredAt)
+
+	failed := attempt("reg-2", WebhookDeliveryFailed, 0, "connection refused")
+	assert.Nil(t, failed.LastStatusCode)
+	require.NotNil(t, failed.LastError)
+	assert.Equal(t, "connection refused", *failed.LastError)
+
+	got, err := store.GetWebhookDelivery(ctx, failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "reg-2", got.SourceID)
+	assert.JSONEq(t, `{"workflow_id":"reg-2"}`, string(got.Payload))
+
+	_, err = store.GetWebhookDelivery(ctx, failed.ID+1000)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	all, err := store.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "reg-2", all[0].SourceID, "newest attempt first")
+
+	onlyFailed, err := store.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{Status: WebhookDeliveryFailed, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, onlyFailed, 1)
+	assert.Equal(t, failed.ID, onlyFailed[0].ID)
+}
+
+func TestAPIKeys(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+
+	created, err := store.CreateAPIKey(ctx, "billing", "key-one")
+	require.NoError(t, err)
+	assert.Equal(t, "billing", created.Name)
+
+	got, err := store.GetAPIKey(ctx, "key-one")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, got.ID)
+	_, err = store.GetAPIKey(ctx, "key-two")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = store.CreateAPIKey(ctx, "billing", "key-two")
+	assert.ErrorIs(t, err, ErrAPIKeyNameTaken)
+}
+
+func TestAuditLog(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	wallet := "wallet1"
+	network := "mainnet"
+
+	first, err := store.CreateAuditLogEntry(ctx, AuditLogEntry{
+		Actor:         "anonymous",
+		Action:        "wallet.register",
+		WalletAddress: &wallet,
+		Network:       &network,
+		Method:        "POST",
+		Path:          "/api/v1/wallet-assets",
+		Status:        201,
+		Outcome:       "success",
+		RequestID:     "req-1",
+		RemoteAddr:    "10.0.0.1:1234",
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, first.ID)
+	assert.WithinDuration(t, time.Now(), first.CreatedAt, 5*time.Second)
+	require.NotNil(t, first.WalletAddress)
+	assert.Equal(t, wallet, *first.WalletAddress)
+
+	_, err = store.CreateAuditLogEntry(ctx, AuditLogEntry{
+		Actor:      "admin",
+		Action:     "mint.add",
+		Method:     "POST",
+		Path:       "/api/v1/supported-mints",
+		Status:     400,
+		Outcome:    "failure",
+		RequestID:  "req-2",
+		RemoteAddr: "10.0.0.2:1234",
+	})
+	require.NoError(t, err)
+
+	window := ListAuditLogParams{Start: time.Now().Add(-time.Hour), End: time.Now().Add(time.Hour), Limit: 10}
+
+	all, err := store.ListAuditLog(ctx, window)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "mint.add", all[0].Action, "newest first")
+	assert.Nil(t, all[0].WalletAddress)
+
+	byActor := window
+	byActor.Actor = "admin"
+	entries, err := store.ListAuditLog(ctx, byActor)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "failure", entries[0].Outcome)
+
+	byWallet := window
+	byWallet.WalletAddress = wallet
+	entries, err = store.ListAuditLog(ctx, byWallet)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, first.ID, entries[0].ID)
+
+	// The table is append-only.
+	_, err = store.pool.Exec(ctx, "UPDATE audit_log SET outcome = 'success'")
+	assert.ErrorContains(t, err, "append-only")
+	_, err = store.pool.Exec(ctx, "DELETE FROM audit_log")
+	assert.ErrorContains(t, err, "append-only")
+}
+
+func TestClaimRegistrationInvoice_Concurrent(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	const workflowID = "payment-registration:wallet1"
+	claim := func(n int, acceptUntil time.Time) (*RegistrationInvoice, bool, error) {
+		return store.ClaimRegistrationInvoice(ctx, ClaimRegistrationInvoiceParams{
+			WorkflowID:  workflowID,
+			Address:     "wallet1",
+			Network:     "mainnet",
+			AssetType:   "sol",
+			Invoice:     []byte(fmt.Sprintf(`{"n": %d}`, n)),
+			AcceptUntil: acceptUntil,
+		})
+	}
+
+	// Simultaneous registrations of the same new wallet.
+	const workers = 10
+	acceptUntil := time.Now().Add(time.Hour)
+	invoices := make([]*RegistrationInvoice, workers)
+	claimed := make([]bool, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			invoices[i], claimed[i], errs[i] = claim(i, acceptUntil)
+		}(i)
+	}
+	wg.Wait()
+
+	winners := 0
+	for i := 0; i < workers; i++ {
+		require.NoError(t, errs[i])
+		assert.JSONEq(t, string(invoices[0].Invoice), string(invoices[i].Invoice), "every caller gets the same invoice")
+		if claimed[i] {
+			winners++
+		}
+	}
+	assert.Equal(t, 1, winners)
+
+	// An expired invoice is replaced by the next claim.
+	_, err := store.pool.Exec(ctx, "UPDATE registration_invoices SET accept_until = NOW() - INTERVAL '1 minute'")
+	require.NoError(t, err)
+	inv, ok, err := claim(99, acceptUntil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"n": 99}`, string(inv.Invoice))
+}
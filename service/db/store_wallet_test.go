@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
@@ -130,13 +131,108 @@ func TestListWallets(t *testing.T) {
 	}
 
 	// List all wallets
-	allWallets, err := store.ListWallets(ctx)
+	allWallets, err := store.ListWallets(ctx, ListWalletsParams{})
 	require.NoError(t, err)
 	require.Len(t, allWallets, 3, "should list wallets from all networks")
 
-	// Should be ordered by created_at DESC
+	// Should be ordered by created_at, oldest first
 	assert.Equal(t, "wallet1", allWallets[0].Address)
-	assert.Equal(t, "devnet", allWallets[0].Network)
+	assert.Equal(t, "mainnet", allWallets[0].Network)
+	assert.Equal(t, "wallet1", allWallets[2].Address)
+	assert.Equal(t, "devnet", allWallets[2].Network)
+}
+
+func TestListWallets_StableOrdering(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+
+	// Inserted out of order; the primary key breaks created_at ties.
+	wallets := []CreateWalletParams{
+		{Address: "walletB", Network: "mainnet", AssetType: "sol", Status: "active"},
+		{Address: "walletA", Network: "mainnet", AssetType: "spl-token", TokenMint: "mint2", Status: "active"},
+		{Address: "walletA", Network: "devnet", AssetType: "sol", Status: "active"},
+		{Address: "walletA", Network: "mainnet", AssetType: "spl-token", TokenMint: "mint1", Status: "active"},
+		{Address: "walletA", Network: "mainnet", AssetType: "sol", Status: "active"},
+	}
+	for _, params := range wallets {
+		_, err := store.CreateWallet(ctx, params)
+		require.NoError(t, err)
+	}
+	_, err := store.pool.Exec(ctx, "UPDATE wallets SET created_at = '2025-01-01T00:00:00Z'")
+	require.NoError(t, err)
+
+	type key struct{ address, network, assetType, tokenMint string }
+	want := []key{
+		{"walletA", "devnet", "sol", ""},
+		{"walletA", "mainnet", "sol", ""},
+		{"walletA", "mainnet", "spl-token", "mint1"},
+		{"walletA", "mainnet", "spl-token", "mint2"},
+		{"walletB", "mainnet", "sol", ""},
+	}
+
+	for i := 0; i < 3; i++ {
+		listed, err := store.ListWallets(ctx, ListWalletsParams{})
+		require.NoError(t, err)
+		got := make([]key, len(listed))
+		for j, w := range listed {
+			got[j] = key{w.Address, w.Network, w.AssetType, w.TokenMint}
+		}
+		assert.Equal(t, want, got, "list %d should be in primary key order", i)
+	}
+
+	active, err := store.ListActiveWallets(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, len(want))
+	assert.Equal(t, "walletA", active[0].Address)
+	assert.Equal(t, "devnet", active[0].Network)
+}
+
+func TestListWallets_Tags(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+
+	_, err := store.CreateWallet(ctx, CreateWalletParams{
+		Address: "wallet1", Network: "mainnet", Status: "active",
+		Tags: []string{"customer:acme", "env:prod"},
+	})
+	require.NoError(t, err)
+	_, err = store.CreateWallet(ctx, CreateWalletParams{
+		Address: "wallet2", Network: "mainnet", Status: "active",
+		Tags: []string{"customer:acme", "env:staging"},
+	})
+	require.NoError(t, err)
+	untagged, err := store.CreateWallet(ctx, CreateWalletParams{Address: "wallet3", Network: "mainnet", Status: "active"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, untagged.Tags)
+
+	wallets, err := store.ListWallets(ctx, ListWalletsParams{Tags: []string{"customer:acme"}})
+	require.NoError(t, err)
+	assert.Len(t, wallets, 2)
+
+	wallets, err = store.ListWallets(ctx, ListWalletsParams{Tags: []string{"customer:acme", "env:prod"}})
+	require.NoError(t, err)
+	require.Len(t, wallets, 1, "all tags must match")
+	assert.Equal(t, "wallet1", wallets[0].Address)
+	assert.Equal(t, []string{"customer:acme", "env:prod"}, wallets[0].Tags)
+
+	// Upsert with nil tags keeps them; an empty slice clears them.
+	w, err := store.UpsertWallet(ctx, UpsertWalletParams{Address: "wallet1", Network: "mainnet", Status: "active"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customer:acme", "env:prod"}, w.Tags)
+
+	w, err = store.UpsertWallet(ctx, UpsertWalletParams{Address: "wallet1", Network: "mainnet", Status: "active", Tags: []string{}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, w.Tags)
 }
 
 func TestListWallets_Empty(t *testing.T) {
@@ -148,7 +244,7 @@ func TestListWallets_Empty(t *testing.T) {
 
 	ctx := context.Background()
 
-	wallets, err := store.ListWallets(ctx)
+	wallets, err := store.ListWallets(ctx, ListWalletsParams{})
 	require.NoError(t, err)
 	assert.Empty(t, wallets)
 }
@@ -196,6 +292,107 @@ func TestListActiveWallets(t *testing.T) {
 	}
 }
 
+func TestListWalletsByRecentActivity(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, w := range []CreateWalletParams{
+		{Address: "quiet", Network: "mainnet", AssetType: "sol", Status: "active"},
+		{Address: "older", Network: "mainnet", AssetType: "sol", Status: "active"},
+		{Address: "newer", Network: "mainnet", AssetType: "sol", Status: "active"},
+		{Address: "newer", Network: "mainnet", AssetType: "spl-token", TokenMint: usdc, Status: "active"},
+		{Address: "paused", Network: "mainnet", AssetType: "sol", Status: "paused"},
+	} {
+		_, err := store.CreateWallet(ctx, w)
+		require.NoError(t, err)
+	}
+
+	for i, txn := range []struct {
+		sig, wallet string
+		mint        *string
+		at          time.Duration
+	}{
+		{"older-1", "older", nil, 0},
+		{"older-2", "older", nil, time.Hour},
+		{"newer-sol", "newer", nil, 2 * time.Hour},
+		{"newer-usdc", "newer", &usdc, 3 * time.Hour},
+		{"paused-1", "paused", nil, 4 * time.Hour},
+	} {
+		_, err := store.CreateTransaction(ctx, CreateTransactionParams{
+			Signature:          txn.sig,
+			WalletAddress:      txn.wallet,
+			Network:            "mainnet",
+			Slot:               int64(80000 + i),
+			BlockTime:          base.Add(txn.at),
+			Amount:             1000,
+			TokenMint:          txn.mint,
+			ConfirmationStatus: "finalized",
+		})
+		require.NoError(t, err)
+	}
+
+	activity, err := store.ListWalletsByRecentActivity(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, activity, 3, "paused wallets and wallets without transactions are left out")
+
+	assert.Equal(t, "newer", activity[0].Wallet.Address)
+	assert.Equal(t, usdc, activity[0].Wallet.TokenMint)
+	assert.Equal(t, "newer-usdc", activity[0].LastTransactionSignature)
+	assert.Equal(t, "newer", activity[1].Wallet.Address)
+	assert.Equal(t, "sol", activity[1].Wallet.AssetType)
+	assert.Equal(t, "newer-sol", activity[1].LastTransactionSignature)
+	assert.Equal(t, "older", activity[2].Wallet.Address)
+	assert.Equal(t, "older-2", activity[2].LastTransactionSignature)
+	assert.True(t, base.Add(time.Hour).Equal(activity[2].LastTransactionAt))
+
+	page, err := store.ListWalletsByRecentActivity(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "older", page[0].Wallet.Address)
+}
+
+func TestCountWalletsByStatus(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	for _, w := range []CreateWalletParams{
+		{Address: "a", Network: "mainnet", AssetType: "sol", Status: "active"},
+		{Address: "b", Network: "mainnet", AssetType: "sol", Status: "active"},
+		{Address: "c", Network: "mainnet", AssetType: "sol", Status: "paused"},
+		{Address: "a", Network: "mainnet", AssetType: "spl-token", TokenMint: usdc, Status: "active"},
+		{Address: "d", Network: "devnet", AssetType: "sol", Status: "active"},
+		{Address: "gone", Network: "devnet", AssetType: "sol", Status: "active"},
+	} {
+		_, err := store.CreateWallet(ctx, w)
+		require.NoError(t, err)
+	}
+	deleted, err := store.SoftDeleteWallet(ctx, "gone", "devnet", "", "")
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	counts, err := store.CountWalletsByStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []WalletStatusCount{
+		{Network: "devnet", AssetType: "sol", Status: "active", Count: 1},
+		{Network: "mainnet", AssetType: "sol", Status: "active", Count: 2},
+		{Network: "mainnet", AssetType: "sol", Status: "paused", Count: 1},
+		{Network: "mainnet", AssetType: "spl-token", Status: "active", Count: 1},
+	}, counts, "unregistered wallets are not counted")
+}
+
 func TestUpdateWalletStatus(t *testing.T) {
 	SkipIfNoTestDB(t)
 
@@ -301,3 +498,99 @@ func TestWalletExists(t *testing.T) {
 	assert.False(t, exists)
 }
 
+
+func TestSoftDeleteWallet(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+
+	_, err := store.CreateWallet(ctx, CreateWalletParams{
+		Address: "wallet333",
+		Network: "mainnet",
+		Status:  "active",
+	})
+	require.NoError(t, err)
+
+	deleted, err := store.SoftDeleteWallet(ctx, "wallet333", "mainnet", "", "")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	// Deleting again is a no-op
+	deleted, err = store.SoftDeleteWallet(ctx, "wallet333", "mainnet", "", "")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	// The row is kept but hidden from default listings and monitoring
+	wallet, err := store.GetWallet(ctx, "wallet333", "mainnet", "", "")
+	require.NoError(t, err)
+	require.NotNil(t, wallet.DeletedAt)
+
+	exists, err := store.WalletExists(ctx, "wallet333", "mainnet", "", "")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	wallets, err := store.ListWallets(ctx, ListWalletsParams{})
+	require.NoError(t, err)
+	assert.Empty(t, wallets)
+
+	wallets, err = store.ListActiveWallets(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, wallets)
+
+	wallets, err = store.ListWallets(ctx, ListWalletsParams{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Len(t, wallets, 1)
+
+	wallets, err = store.ListWalletAssets(ctx, "wallet333", "mainnet", true)
+	require.NoError(t, err)
+	assert.Len(t, wallets, 1)
+
+	// Re-registering clears the mark
+	wallet, err = store.UpsertWallet(ctx, UpsertWalletParams{
+		Address: "wallet333",
+		Network: "mainnet",
+		Status:  "active",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, wallet.DeletedAt)
+}
+
+func TestPurgeDeletedWallets(t *testing.T) {
+	SkipIfNoTestDB(t)
+
+	store := NewTestStore(t)
+	defer store.Close()
+	defer store.Cleanup(t)
+
+	ctx := context.Background()
+
+	for _, addr := range []string{"wallet444", "wallet555"} {
+		_, err := store.CreateWallet(ctx, CreateWalletParams{
+			Address: addr,
+			Network: "mainnet",
+			Status:  "active",
+		})
+		require.NoError(t, err)
+	}
+	_, err := store.SoftDeleteWallet(ctx, "wallet444", "mainnet", "", "")
+	require.NoError(t, err)
+
+	// Nothing was deleted before an hour ago
+	purged, err := store.PurgeDeletedWallets(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = store.PurgeDeletedWallets(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	// Registered wallets are never purged
+	wallets, err := store.ListWallets(ctx, ListWalletsParams{IncludeDeleted: true})
+	require.NoError(t, err)
+	require.Len(t, wallets, 1)
+	assert.Equal(t, "wallet555", wallets[0].Address)
+}
@@ -18,7 +18,7 @@ type TestStore struct {
 // NewTestStore creates a new Store connected to the test database.
 // It reads the TEST_DATABASE_URL environment variable, or falls back to a default.
 // The test database should be isolated from the development database.
-func NewTestStore(t *testing.T) *TestStore {
+func NewTestStore(t testing.TB) *TestStore {
 	t.Helper()
 
 	dbURL := os.Getenv("TEST_DATABASE_URL")
@@ -52,11 +52,11 @@ func (ts *TestStore) Close() {
 
 // Cleanup removes all data from test tables.
 // Call this in tests to ensure clean state between test cases.
-func (ts *TestStore) Cleanup(t *testing.T) {
+func (ts *TestStore) Cleanup(t testing.TB) {
 	t.Helper()
 
 	ctx := context.Background()
-	_, err := ts.pool.Exec(ctx, "TRUNCATE TABLE transactions, wallets CASCADE")
+	_, err := ts.pool.Exec(ctx, "TRUNCATE TABLE transactions, wallets, supported_mints, digest_subscriptions, wallet_filters, audit_log, registration_invoices, webhook_deliveries, transaction_webhooks, api_keys CASCADE")
 	if err != nil {
 		t.Fatalf("failed to cleanup test database: %v", err)
 	}
@@ -76,7 +76,7 @@ func (ts *TestStore) MustExec(t *testing.T, query string, args ...interface{}) {
 
 // SkipIfNoTestDB skips the test if the test database is not available.
 // This is useful for running unit tests without requiring a database.
-func SkipIfNoTestDB(t *testing.T) {
+func SkipIfNoTestDB(t testing.TB) {
 	t.Helper()
 
 	if os.Getenv("SKIP_DB_TESTS") != "" {
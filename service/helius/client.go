@@ -8,11 +8,19 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 )
 
 const defaultBaseURL = "https://api-mainnet.helius-rpc.com/v0"
 
+// defaultRPCURLs are the Helius RPC endpoints per network, used for on-chain
+// lookups such as GetMintInfo.
+var defaultRPCURLs = map[string]string{
+	"mainnet": "https://mainnet.helius-rpc.com",
+	"devnet":  "https://devnet.helius-rpc.com",
+}
+
 // Client manages Helius webhooks via the Helius API.
 // It maintains a single webhook and adds/removes account addresses
 // as wallets are registered/unregistered.
@@ -26,6 +34,17 @@ type Client struct {
 
 	// Cached webhook ID, populated on EnsureWebhooks
 	mainnetWebhookID string
+
+	// addrMu serializes the read-modify-write of the webhook's address list,
+	// so concurrent registrations in this process don't drop each other's
+	// addresses.
+	addrMu sync.Mutex
+
+	rpcURLs    map[string]string // network -> RPC URL (overridable for testing)
+	txnAPIURLs map[string]string // network -> enhanced transactions API URL (overridable for testing)
+	rpc        *rpcFailover      // fallback endpoints and per-endpoint breakers
+	mintMu     sync.Mutex
+	mintCache  map[string]*MintInfo // network:mint -> info; decimals never change
 }
 
 // NewClient creates a new Helius API client.
@@ -37,6 +56,10 @@ func NewClient(apiKey, webhookURL, authHeader string, logger *slog.Logger) *Clie
 		authHeader: authHeader,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		logger:     logger,
+		rpcURLs:    defaultRPCURLs,
+		txnAPIURLs: defaultTransactionAPIURLs,
+		rpc:        newRPCFailover(),
+		mintCache:  make(map[string]*MintInfo),
 	}
 }
 
@@ -88,6 +111,9 @@ func (c *Client) WebhookID() string {
 // It fetches the current list and updates only if there's a difference.
 // Call this on startup to reconcile the webhook with all active wallets from the DB.
 func (c *Client) SyncAddresses(ctx context.Context, addresses []string) error {
+	c.addrMu.Lock()
+	defer c.addrMu.Unlock()
+
 	webhookID := c.mainnetWebhookID
 	if webhookID == "" {
 		return fmt.Errorf("no webhook configured; call EnsureWebhooks first")
@@ -277,6 +303,9 @@ func (c *Client) UpdateWebhookAddresses(ctx context.Context, webhookID string, a
 // AddAddress adds an address to the webhook's monitored account list.
 // It fetches the current list, appends the new address (if not already present), and updates.
 func (c *Client) AddAddress(ctx context.Context, address string) error {
+	c.addrMu.Lock()
+	defer c.addrMu.Unlock()
+
 	webhookID := c.mainnetWebhookID
 	if webhookID == "" {
 		return fmt.Errorf("no webhook configured; call EnsureWebhooks first")
@@ -311,6 +340,9 @@ func (c *Client) AddAddress(ctx context.Context, address string) error {
 
 // RemoveAddress removes an address from the webhook's monitored account list.
 func (c *Client) RemoveAddress(ctx context.Context, address string) error {
+	c.addrMu.Lock()
+	defer c.addrMu.Unlock()
+
 	webhookID := c.mainnetWebhookID
 	if webhookID == "" {
 		return fmt.Errorf("no webhook configured; call EnsureWebhooks first")
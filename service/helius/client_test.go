@@ -3,11 +3,13 @@ package helius
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -131,6 +133,43 @@ func TestAddAddress(t *testing.T) {
 	assert.Equal(t, []string{"existing-addr", "new-addr"}, gotAddresses)
 }
 
+func TestAddAddress_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	stored := []string{"existing-addr"}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(Webhook{WebhookID: "wh-1", AccountAddresses: stored})
+		case http.MethodPut:
+			var body UpdateWebhookRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			stored = body.AccountAddresses
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientWithBaseURL(srv.URL, "key", "https://example.com/webhook", "Bearer s", newTestLogger())
+	c.mainnetWebhookID = "wh-1"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, c.AddAddress(context.Background(), fmt.Sprintf("addr-%d", i)))
+		}(i)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, stored, 11, "no concurrent add should overwrite another")
+}
+
 func TestAddAddress_AlreadyExists(t *testing.T) {
 	putCalled := false
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,67 @@
+package helius
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RPCHealth is the result of a successful RPC health check.
+type RPCHealth struct {
+	Network  string        `json:"network"`
+	Endpoint string        `json:"endpoint"` // host that answered; a fallback if the primary failed
+	Slot     uint64        `json:"slot"`
+	Latency  time.Duration `json:"latency"`
+}
+
+// getSlotResponse is the getSlot response.
+type getSlotResponse struct {
+	Result *uint64 `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// HealthCheck makes a lightweight getSlot call against a network's RPC
+// endpoints (failing over like any other RPC call) and reports the serving
+// endpoint, the current slot and round-trip latency. It catches a
+// wrong API key or a rate-limited endpoint before it breaks finalization
+// tracking or mint lookups.
+func (c *Client) HealthCheck(ctx context.Context, network string) (*RPCHealth, error) {
+	data, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      "forohtoo",
+		"method":  "getSlot",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	start := time.Now()
+	resp, endpoint, err := c.postRPC(ctx, network, "getSlot", data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var slot getSlotResponse
+	if err := json.NewDecoder(resp.Body).Decode(&slot); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	latency := time.Since(start)
+	if slot.Error != nil {
+		return nil, fmt.Errorf("helius RPC error (code %d): %s", slot.Error.Code, slot.Error.Message)
+	}
+	if slot.Result == nil {
+		return nil, fmt.Errorf("unexpected getSlot response: missing result")
+	}
+
+	return &RPCHealth{
+		Network:  network,
+		Endpoint: endpoint,
+		Slot:     *slot.Result,
+		Latency:  latency,
+	}, nil
+}
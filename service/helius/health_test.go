@@ -0,0 +1,69 @@
+package helius
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-api-key", r.URL.Query().Get("api-key"))
+		var body struct {
+			Method string `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "getSlot", body.Method)
+
+		w.Write([]byte(`{"jsonrpc": "2.0", "id": "forohtoo", "result": 287654321}`))
+	}))
+	defer srv.Close()
+
+	c := newClientWithRPCURL(srv.URL)
+
+	health, err := c.HealthCheck(context.Background(), "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet", health.Network)
+	assert.Equal(t, uint64(287654321), health.Slot)
+	assert.Positive(t, health.Latency)
+}
+
+func TestHealthCheck_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `invalid api key`, wantErr: "status 401"},
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `rate limited`, wantErr: "status 429"},
+		{name: "rpc error", status: http.StatusOK, body: `{"jsonrpc": "2.0", "id": "forohtoo", "error": {"code": -32005, "message": "node is behind"}}`, wantErr: "node is behind"},
+		{name: "missing result", status: http.StatusOK, body: `{"jsonrpc": "2.0", "id": "forohtoo"}`, wantErr: "missing result"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := newClientWithRPCURL(srv.URL).HealthCheck(context.Background(), "mainnet")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestHealthCheck_UnsupportedNetwork(t *testing.T) {
+	c := newClientWithRPCURL("http://unused")
+	_, err := c.HealthCheck(context.Background(), "testnet")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported network")
+}
@@ -0,0 +1,95 @@
+package helius
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// MintInfo describes an SPL token mint.
+type MintInfo struct {
+	Mint     string `json:"mint"`
+	Decimals int    `json:"decimals"`
+	Symbol   string `json:"symbol,omitempty"` // empty if the mint has no Metaplex metadata
+}
+
+// getAssetResponse is the subset of the DAS getAsset response we read.
+type getAssetResponse struct {
+	Result *struct {
+		TokenInfo *struct {
+			Decimals *int   `json:"decimals"`
+			Symbol   string `json:"symbol"`
+		} `json:"token_info"`
+		Content struct {
+			Metadata struct {
+				Symbol string `json:"symbol"`
+			} `json:"metadata"`
+		} `json:"content"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GetMintInfo resolves a mint's decimals (from the mint account) and symbol
+// (from its Metaplex metadata, when present) using the Helius DAS getAsset
+// method. Results are cached for the life of the client since a mint's
+// decimals never change.
+func (c *Client) GetMintInfo(ctx context.Context, network, mint string) (*MintInfo, error) {
+	if _, ok := c.rpcURLs[network]; !ok {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	key := network + ":" + mint
+	c.mintMu.Lock()
+	cached, ok := c.mintCache[key]
+	c.mintMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      "forohtoo",
+		"method":  "getAsset",
+		"params":  map[string]string{"id": mint},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, _, err := c.postRPC(ctx, network, "getAsset", data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var asset getAssetResponse
+	if err := json.NewDecoder(resp.Body).Decode(&asset); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if asset.Error != nil {
+		return nil, fmt.Errorf("helius RPC error (code %d): %s", asset.Error.Code, asset.Error.Message)
+	}
+	if asset.Result == nil || asset.Result.TokenInfo == nil || asset.Result.TokenInfo.Decimals == nil {
+		return nil, fmt.Errorf("%s is not a token mint on %s", mint, network)
+	}
+
+	info := &MintInfo{
+		Mint:     mint,
+		Decimals: *asset.Result.TokenInfo.Decimals,
+		Symbol:   asset.Result.TokenInfo.Symbol,
+	}
+	if info.Symbol == "" {
+		info.Symbol = asset.Result.Content.Metadata.Symbol
+	}
+
+	c.mintMu.Lock()
+	c.mintCache[key] = info
+	c.mintMu.Unlock()
+
+	c.logger.Debug("resolved mint info", "network", network, "mint", mint, "decimals", info.Decimals, "symbol", info.Symbol)
+
+	return info, nil
+}
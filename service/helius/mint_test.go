@@ -0,0 +1,113 @@
+package helius
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usdcMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+// usdcAssetResponse is a trimmed DAS getAsset response for the USDC mint.
+const usdcAssetResponse = `{
+	"jsonrpc": "2.0",
+	"id": "forohtoo",
+	"result": {
+		"interface": "FungibleToken",
+		"id": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		"content": {"metadata": {"name": "USD Coin", "symbol": "USDC"}},
+		"token_info": {"symbol": "USDC", "supply": 8978034539870700, "decimals": 6, "token_program": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"}
+	}
+}`
+
+// newClientWithRPCURL creates a Client whose mainnet RPC points at a test server.
+func newClientWithRPCURL(rpcURL string) *Client {
+	c := NewClient("test-api-key", "https://example.com/webhook", "Bearer s", newTestLogger())
+	c.rpcURLs = map[string]string{"mainnet": rpcURL}
+	return c
+}
+
+func TestGetMintInfo_USDC(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "test-api-key", r.URL.Query().Get("api-key"))
+
+		var body struct {
+			Method string            `json:"method"`
+			Params map[string]string `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "getAsset", body.Method)
+		assert.Equal(t, usdcMint, body.Params["id"])
+
+		w.Write([]byte(usdcAssetResponse))
+	}))
+	defer srv.Close()
+
+	c := newClientWithRPCURL(srv.URL)
+
+	info, err := c.GetMintInfo(context.Background(), "mainnet", usdcMint)
+	require.NoError(t, err)
+	assert.Equal(t, usdcMint, info.Mint)
+	assert.Equal(t, 6, info.Decimals)
+	assert.Equal(t, "USDC", info.Symbol)
+
+	// Second lookup is served from the cache
+	_, err = c.GetMintInfo(context.Background(), "mainnet", usdcMint)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestGetMintInfo_SymbolFallsBackToMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result": {"content": {"metadata": {"symbol": "BONK"}}, "token_info": {"decimals": 5}}}`))
+	}))
+	defer srv.Close()
+
+	info, err := newClientWithRPCURL(srv.URL).GetMintInfo(context.Background(), "mainnet", "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263")
+	require.NoError(t, err)
+	assert.Equal(t, 5, info.Decimals)
+	assert.Equal(t, "BONK", info.Symbol)
+}
+
+func TestGetMintInfo_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"rpc error", http.StatusOK, `{"error": {"code": -32000, "message": "Asset not found"}}`, "Asset not found"},
+		{"not a token", http.StatusOK, `{"result": {"content": {"metadata": {"symbol": "NFT"}}}}`, "not a token mint"},
+		{"http error", http.StatusUnauthorized, `unauthorized`, "status 401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := newClientWithRPCURL(srv.URL)
+			_, err := c.GetMintInfo(context.Background(), "mainnet", usdcMint)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+
+			// Failures are not cached
+			assert.Empty(t, c.mintCache)
+		})
+	}
+
+	_, err := newClientWithRPCURL("http://unused").GetMintInfo(context.Background(), "testnet", usdcMint)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported network")
+}
@@ -22,26 +22,50 @@ type WalletLookup struct {
 	TokenMint     string
 }
 
+// ParseOptions configures ParseEnhancedTransactions.
+type ParseOptions struct {
+	// RecordOutgoing also matches transfers sent by monitored addresses, with
+	// direction "out". By default only transfers into them are matched.
+	RecordOutgoing bool
+}
+
 // ParseEnhancedTransactions converts a batch of Helius enhanced transactions into
 // db.CreateTransactionParams, matched against registered wallets.
 //
 // addressMap maps monitored addresses (wallet for SOL, ATA for SPL tokens) to WalletLookup.
 // This allows us to determine which registered wallet a transaction belongs to.
+//
+// Each match records its direction relative to the monitored wallet: "in"
+// for a transfer from another account, "self" for one the wallet sent to
+// itself, and, with opts.RecordOutgoing, "out" for one it sent elsewhere.
+// Amount is always the magnitude.
 func ParseEnhancedTransactions(
 	txns []EnhancedTransaction,
 	addressMap map[string]WalletLookup,
+	opts ParseOptions,
 	logger *slog.Logger,
 ) []db.CreateTransactionParams {
 	var results []db.CreateTransactionParams
 
 	for _, txn := range txns {
 		parsed := parseOneTransaction(txn, addressMap, logger)
+		if opts.RecordOutgoing {
+			parsed = append(parsed, parseOutgoingTransfers(txn, addressMap, logger)...)
+		}
 		results = append(results, parsed...)
 	}
 
 	return results
 }
 
+// transferDirection is the direction of a transfer into wallet from sender.
+func transferDirection(wallet, sender string) string {
+	if sender == wallet {
+		return db.DirectionSelf
+	}
+	return db.DirectionIn
+}
+
 func parseOneTransaction(
 	txn EnhancedTransaction,
 	addressMap map[string]WalletLookup,
@@ -58,6 +82,7 @@ func parseOneTransaction(
 
 	// Extract memo from description or instructions
 	memo := extractMemo(txn)
+	decimals := mintDecimals(txn)
 
 	// Match native SOL transfers against monitored wallet addresses
 	for _, nt := range txn.NativeTransfers {
@@ -80,6 +105,8 @@ func parseOneTransaction(
 			Amount:             int64(nt.Amount),
 			ConfirmationStatus: confirmationStatus,
 			FromAddress:        &from,
+			Fee:                int64(txn.Fee),
+			Direction:          transferDirection(lookup.WalletAddress, from),
 		}
 		if memo != nil {
 			params.Memo = memo
@@ -95,8 +122,14 @@ func parseOneTransaction(
 		)
 	}
 
-	// Match SPL token transfers against monitored ATAs
-	for _, tt := range txn.TokenTransfers {
+	// Match SPL token transfers against monitored ATAs. Helius lists
+	// transfers made by inner instructions too, so a payment routed through
+	// a program (DEX, aggregator, payment program) can reach the same ATA in
+	// several hops. We store one row per signature, network, wallet and
+	// direction, so those are summed into a single record instead of the
+	// later hops being dropped as duplicates.
+	splIndex := make(map[string]int)
+	for i, tt := range txn.TokenTransfers {
 		// Check toTokenAccount (the ATA) against our monitored addresses
 		lookup, ok := addressMap[tt.ToTokenAccount]
 		if !ok {
@@ -117,9 +150,22 @@ func parseOneTransaction(
 		// Convert float token amount to raw integer amount
 		// Helius provides tokenAmount as a float (e.g., 1.5 USDC = 1.5)
 		// We need the raw amount (e.g., 1500000 for USDC with 6 decimals)
-		rawAmount := tokenAmountToRaw(tt.TokenAmount, tt.Mint)
+		rawAmount := tokenAmountToRaw(tt.TokenAmount, tt.Mint, decimals)
+
+		key := lookup.WalletAddress + "|" + lookup.Network + "|" + tt.Mint
+		if idx, ok := splIndex[key]; ok {
+			results[idx].Amount += rawAmount
+			logger.Debug("merged token transfer",
+				"signature", txn.Signature,
+				"wallet", lookup.WalletAddress,
+				"mint", tt.Mint,
+				"raw_amount", rawAmount,
+				"total", results[idx].Amount,
+			)
+			continue
+		}
 
-		from := tt.FromUserAccount
+		from := tokenTransferSender(txn, i)
 		mint := tt.Mint
 		params := db.CreateTransactionParams{
 			Signature:          txn.Signature,
@@ -131,11 +177,14 @@ func parseOneTransaction(
 			TokenMint:          &mint,
 			ConfirmationStatus: confirmationStatus,
 			FromAddress:        &from,
+			Fee:                int64(txn.Fee),
+			Direction:          transferDirection(lookup.WalletAddress, from),
 		}
 		if memo != nil {
 			params.Memo = memo
 		}
 
+		splIndex[key] = len(results)
 		results = append(results, params)
 
 		logger.Debug("matched token transfer",
@@ -144,13 +193,139 @@ func parseOneTransaction(
 			"mint", tt.Mint,
 			"amount", tt.TokenAmount,
 			"raw_amount", rawAmount,
-			"from", tt.FromUserAccount,
+			"from", from,
 		)
 	}
 
 	return results
 }
 
+// parseOutgoingTransfers matches transfers sent by monitored addresses to
+// other accounts. A wallet's outgoing transfers of one asset are summed into
+// a single record per signature, like incoming token hops; FromAddress is the
+// wallet itself. Transfers back to the same wallet are left to
+// parseOneTransaction, which records them as "self". Rows are keyed on
+// wallet and direction as well as signature, so when a transaction also pays
+// a monitored wallet both the incoming and the outgoing record are stored.
+func parseOutgoingTransfers(
+	txn EnhancedTransaction,
+	addressMap map[string]WalletLookup,
+	logger *slog.Logger,
+) []db.CreateTransactionParams {
+	var results []db.CreateTransactionParams
+	index := make(map[string]int)
+	confirmationStatus := "confirmed"
+	if txn.TransactionError != nil {
+		confirmationStatus = "failed"
+	}
+	memo := extractMemo(txn)
+	decimals := mintDecimals(txn)
+
+	add := func(lookup WalletLookup, mint *string, amount int64) {
+		asset := "sol"
+		if mint != nil {
+			asset = *mint
+		}
+		key := lookup.WalletAddress + "|" + lookup.Network + "|" + asset
+		if idx, ok := index[key]; ok {
+			results[idx].Amount += amount
+			return
+		}
+		from := lookup.WalletAddress
+		index[key] = len(results)
+		results = append(results, db.CreateTransactionParams{
+			Signature:          txn.Signature,
+			WalletAddress:      lookup.WalletAddress,
+			Network:            lookup.Network,
+			Slot:               int64(txn.Slot),
+			BlockTime:          time.Unix(txn.Timestamp, 0).UTC(),
+			Amount:             amount,
+			TokenMint:          mint,
+			Memo:               memo,
+			ConfirmationStatus: confirmationStatus,
+			FromAddress:        &from,
+			Fee:                int64(txn.Fee),
+			Direction:          db.DirectionOut,
+		})
+	}
+
+	for _, nt := range txn.NativeTransfers {
+		lookup, ok := addressMap[nt.FromUserAccount]
+		if !ok || lookup.AssetType != "sol" || nt.ToUserAccount == lookup.WalletAddress {
+			continue
+		}
+		add(lookup, nil, int64(nt.Amount))
+		logger.Debug("matched outgoing native transfer",
+			"signature", txn.Signature,
+			"wallet", lookup.WalletAddress,
+			"amount", nt.Amount,
+			"to", nt.ToUserAccount,
+		)
+	}
+
+	for _, tt := range txn.TokenTransfers {
+		lookup, ok := addressMap[tt.FromTokenAccount]
+		if !ok {
+			lookup, ok = addressMap[tt.FromUserAccount]
+			if !ok {
+				continue
+			}
+		}
+		if lookup.AssetType != "spl-token" {
+			continue
+		}
+		if lookup.TokenMint != "" && lookup.TokenMint != tt.Mint {
+			continue
+		}
+		if tt.ToUserAccount == lookup.WalletAddress {
+			continue
+		}
+		mint := tt.Mint
+		rawAmount := tokenAmountToRaw(tt.TokenAmount, tt.Mint, decimals)
+		add(lookup, &mint, rawAmount)
+		logger.Debug("matched outgoing token transfer",
+			"signature", txn.Signature,
+			"wallet", lookup.WalletAddress,
+			"mint", tt.Mint,
+			"raw_amount", rawAmount,
+			"to", tt.ToUserAccount,
+		)
+	}
+
+	return results
+}
+
+// tokenTransferSender attributes txn.TokenTransfers[i] to the account that
+// paid it. When a program routes a payment, the transfer into the monitored
+// account comes from an intermediate account (a vault or pool) that was funded
+// by an earlier transfer of the same mint in the same transaction, so we
+// follow that chain back until it reaches the fee payer or an account that
+// wasn't funded in this transaction. Helius leaves fromUserAccount empty when
+// it can't resolve the owner; the fee payer is the best remaining guess.
+func tokenTransferSender(txn EnhancedTransaction, i int) string {
+	mint := txn.TokenTransfers[i].Mint
+	from := txn.TokenTransfers[i].FromUserAccount
+	seen := map[string]bool{}
+	for from != "" && from != txn.FeePayer && !seen[from] {
+		seen[from] = true
+		funded := false
+		for j := i - 1; j >= 0; j-- {
+			prev := txn.TokenTransfers[j]
+			if prev.Mint == mint && prev.ToUserAccount == from && prev.FromUserAccount != "" {
+				from, i, funded = prev.FromUserAccount, j, true
+				break
+			}
+		}
+		if !funded {
+			break
+		}
+	}
+	if from == "" {
+		return txn.FeePayer
+	}
+	return from
+}
+
 // extractMemo looks for memo data in the Helius enhanced transaction.
 // Helius includes memo program data in the instructions list. The instruction
 // data is base58-encoded raw bytes; the memo program's payload is just the
@@ -194,11 +369,29 @@ func decodeMemoData(data string) (string, bool) {
 	return string(raw), true
 }
 
+// mintDecimals returns the decimals of each mint whose balances txn changed,
+// as reported in its account data.
+func mintDecimals(txn EnhancedTransaction) map[string]int {
+	decimals := make(map[string]int)
+	for _, account := range txn.AccountData {
+		for _, change := range account.TokenBalanceChanges {
+			if change.Mint != "" {
+				decimals[change.Mint] = change.RawTokenAmount.Decimals
+			}
+		}
+	}
+	return decimals
+}
+
 // tokenAmountToRaw converts a float token amount to raw integer amount.
-// Uses known decimals for common tokens, defaults to 6 decimals (USDC standard).
-func tokenAmountToRaw(amount float64, mint string) int64 {
-	decimals := getTokenDecimals(mint)
-	return int64(math.Round(amount * math.Pow10(decimals)))
+// It uses the mint's decimals from the transaction when known, falling back
+// to known decimals for common tokens and then 6 decimals (USDC standard).
+func tokenAmountToRaw(amount float64, mint string, decimals map[string]int) int64 {
+	d, ok := decimals[mint]
+	if !ok {
+		d = getTokenDecimals(mint)
+	}
+	return int64(math.Round(amount * math.Pow10(d)))
 }
 
 // getTokenDecimals returns the number of decimals for known token mints.
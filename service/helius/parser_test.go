@@ -40,7 +40,7 @@ func TestParseEnhancedTransactions_NativeSOLTransfer(t *testing.T) {
 		},
 	}
 
-	results := ParseEnhancedTransactions(txns, addressMap, testLogger())
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
 
 	require.Len(t, results, 1)
 	assert.Equal(t, "sig123abc", results[0].Signature)
@@ -51,6 +51,8 @@ func TestParseEnhancedTransactions_NativeSOLTransfer(t *testing.T) {
 	assert.Equal(t, "SenderWallet1111111111111111111111111111111", *results[0].FromAddress)
 	assert.Nil(t, results[0].TokenMint)
 	assert.Equal(t, "confirmed", results[0].ConfirmationStatus)
+	assert.Equal(t, int64(5000), results[0].Fee)
+	assert.Equal(t, "in", results[0].Direction)
 }
 
 func TestParseEnhancedTransactions_SPLTokenTransfer(t *testing.T) {
@@ -83,7 +85,7 @@ func TestParseEnhancedTransactions_SPLTokenTransfer(t *testing.T) {
 		},
 	}
 
-	results := ParseEnhancedTransactions(txns, addressMap, testLogger())
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
 
 	require.Len(t, results, 1)
 	assert.Equal(t, "sig456def", results[0].Signature)
@@ -91,6 +93,7 @@ func TestParseEnhancedTransactions_SPLTokenTransfer(t *testing.T) {
 	assert.Equal(t, int64(5_000_000), results[0].Amount) // 5 USDC = 5_000_000 (6 decimals)
 	assert.Equal(t, usdcMint, *results[0].TokenMint)
 	assert.Equal(t, "SenderWallet1111111111111111111111111111111", *results[0].FromAddress)
+	assert.Zero(t, results[0].Fee, "fee defaults to 0 when the payload omits it")
 }
 
 func TestParseEnhancedTransactions_NoMatch(t *testing.T) {
@@ -117,7 +120,7 @@ func TestParseEnhancedTransactions_NoMatch(t *testing.T) {
 		},
 	}
 
-	results := ParseEnhancedTransactions(txns, addressMap, testLogger())
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
 	assert.Empty(t, results)
 }
 
@@ -146,7 +149,7 @@ func TestParseEnhancedTransactions_FailedTransaction(t *testing.T) {
 		},
 	}
 
-	results := ParseEnhancedTransactions(txns, addressMap, testLogger())
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
 	require.Len(t, results, 1)
 	assert.Equal(t, "failed", results[0].ConfirmationStatus)
 }
@@ -181,7 +184,7 @@ func TestParseEnhancedTransactions_WithMemo(t *testing.T) {
 		},
 	}
 
-	results := ParseEnhancedTransactions(txns, addressMap, testLogger())
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
 	require.Len(t, results, 1)
 	require.NotNil(t, results[0].Memo)
 	assert.Equal(t, "hello world payment", *results[0].Memo)
@@ -221,7 +224,7 @@ func TestParseEnhancedTransactions_MultipleTransfersInOneTx(t *testing.T) {
 		},
 	}
 
-	results := ParseEnhancedTransactions(txns, addressMap, testLogger())
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
 	require.Len(t, results, 2)
 	assert.Equal(t, int64(100_000_000), results[0].Amount)
 	assert.Equal(t, int64(200_000_000), results[1].Amount)
@@ -252,13 +255,13 @@ func TestParseEnhancedTransactions_MintMismatch(t *testing.T) {
 		},
 	}
 
-	results := ParseEnhancedTransactions(txns, addressMap, testLogger())
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
 	assert.Empty(t, results, "should not match when mint doesn't match registered token")
 }
 
 func TestParseEnhancedTransactions_EmptyBatch(t *testing.T) {
 	addressMap := map[string]WalletLookup{}
-	results := ParseEnhancedTransactions(nil, addressMap, testLogger())
+	results := ParseEnhancedTransactions(nil, addressMap, ParseOptions{}, testLogger())
 	assert.Empty(t, results)
 }
 
@@ -269,6 +272,12 @@ func TestParseWebhookPayload(t *testing.T) {
 	require.Len(t, txns, 1)
 	assert.Equal(t, "sig1", txns[0].Signature)
 	assert.Equal(t, uint64(100), txns[0].Slot)
+	assert.Equal(t, uint64(5000), txns[0].Fee)
+
+	txns, err = ParseWebhookPayload([]byte(`[{"signature":"sig2","slot":101,"timestamp":1700000000}]`))
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Zero(t, txns[0].Fee)
 }
 
 func TestParseWebhookPayload_Invalid(t *testing.T) {
@@ -278,12 +287,69 @@ func TestParseWebhookPayload_Invalid(t *testing.T) {
 
 func TestTokenAmountToRaw(t *testing.T) {
 	// USDC: 6 decimals
-	assert.Equal(t, int64(1_000_000), tokenAmountToRaw(1.0, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"))
-	assert.Equal(t, int64(500_000), tokenAmountToRaw(0.5, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"))
-	assert.Equal(t, int64(1_234_567), tokenAmountToRaw(1.234567, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"))
+	assert.Equal(t, int64(1_000_000), tokenAmountToRaw(1.0, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", nil))
+	assert.Equal(t, int64(500_000), tokenAmountToRaw(0.5, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", nil))
+	assert.Equal(t, int64(1_234_567), tokenAmountToRaw(1.234567, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", nil))
 
 	// Unknown token defaults to 6 decimals
-	assert.Equal(t, int64(1_000_000), tokenAmountToRaw(1.0, "unknown_mint"))
+	assert.Equal(t, int64(1_000_000), tokenAmountToRaw(1.0, "unknown_mint", nil))
+
+	// Decimals reported by the transaction win
+	assert.Equal(t, int64(1_500_000_000), tokenAmountToRaw(1.5, "unknown_mint", map[string]int{"unknown_mint": 9}))
+}
+
+// nineDecimalPayload pays 2.5 of a 9-decimal mint that isn't in the known
+// decimals table; its decimals are only in the account data.
+const nineDecimalPayload = `[{
+  "signature": "sig9dec",
+  "slot": 300000,
+  "timestamp": 1700002000,
+  "tokenTransfers": [
+    {
+      "fromUserAccount": "SenderWallet1111111111111111111111111111111",
+      "fromTokenAccount": "SenderATA11111111111111111111111111111111",
+      "toUserAccount": "ReceiverWallet111111111111111111111111111",
+      "toTokenAccount": "ReceiverATA1111111111111111111111111111111",
+      "mint": "NineDecMint11111111111111111111111111111111",
+      "tokenAmount": 2.5,
+      "tokenStandard": "Fungible"
+    }
+  ],
+  "accountData": [
+    {
+      "account": "ReceiverATA1111111111111111111111111111111",
+      "nativeBalanceChange": 0,
+      "tokenBalanceChanges": [
+        {
+          "userAccount": "ReceiverWallet111111111111111111111111111",
+          "tokenAccount": "ReceiverATA1111111111111111111111111111111",
+          "mint": "NineDecMint11111111111111111111111111111111",
+          "rawTokenAmount": {"tokenAmount": "2500000000", "decimals": 9}
+        }
+      ]
+    }
+  ]
+}]`
+
+func TestParseEnhancedTransactions_MintDecimalsFromAccountData(t *testing.T) {
+	mint := "NineDecMint11111111111111111111111111111111"
+	addressMap := map[string]WalletLookup{
+		"ReceiverATA1111111111111111111111111111111": {
+			WalletAddress: "ReceiverWallet111111111111111111111111111",
+			Network:       "mainnet",
+			AssetType:     "spl-token",
+			TokenMint:     mint,
+		},
+	}
+
+	txns, err := ParseWebhookPayload([]byte(nineDecimalPayload))
+	require.NoError(t, err)
+
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
+
+	require.Len(t, results, 1)
+	assert.Equal(t, int64(2_500_000_000), results[0].Amount)
+	assert.Equal(t, mint, *results[0].TokenMint)
 }
 
 func TestExtractMemo_SPLMemoProgram(t *testing.T) {
@@ -381,6 +447,290 @@ func TestParseEnhancedTransactions_SOLAssetIgnoresTokenTransfer(t *testing.T) {
 		},
 	}
 
-	results := ParseEnhancedTransactions(txns, addressMap, testLogger())
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
 	assert.Empty(t, results, "SOL-type wallet should not match token transfers via toUserAccount")
 }
+
+// routedPaymentPayload is a Helius webhook payload for a USDC payment made
+// through a payment program: the payer's transfer into the program vault and
+// the vault's transfer on to the merchant are both inner instructions of the
+// program's top-level instruction.
+const routedPaymentPayload = `[{
+  "signature": "sigRouted",
+  "slot": 900000,
+  "timestamp": 1700008000,
+  "fee": 5000,
+  "feePayer": "PayerWallet11111111111111111111111111111111",
+  "type": "TRANSFER",
+  "source": "UNKNOWN",
+  "nativeTransfers": [],
+  "tokenTransfers": [
+    {
+      "fromUserAccount": "PayerWallet11111111111111111111111111111111",
+      "fromTokenAccount": "PayerATA1111111111111111111111111111111111",
+      "toUserAccount": "VaultPDA11111111111111111111111111111111111",
+      "toTokenAccount": "VaultATA1111111111111111111111111111111111",
+      "mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+      "tokenAmount": 2.5,
+      "tokenStandard": "Fungible"
+    },
+    {
+      "fromUserAccount": "VaultPDA11111111111111111111111111111111111",
+      "fromTokenAccount": "VaultATA1111111111111111111111111111111111",
+      "toUserAccount": "MerchantWallet111111111111111111111111111",
+      "toTokenAccount": "MerchantATA11111111111111111111111111111111",
+      "mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+      "tokenAmount": 2,
+      "tokenStandard": "Fungible"
+    },
+    {
+      "fromUserAccount": "VaultPDA11111111111111111111111111111111111",
+      "fromTokenAccount": "VaultATA1111111111111111111111111111111111",
+      "toUserAccount": "MerchantWallet111111111111111111111111111",
+      "toTokenAccount": "MerchantATA11111111111111111111111111111111",
+      "mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+      "tokenAmount": 0.5,
+      "tokenStandard": "Fungible"
+    }
+  ],
+  "transactionError": null,
+  "instructions": [
+    {
+      "programId": "PayProgram1111111111111111111111111111111111",
+      "accounts": ["PayerWallet11111111111111111111111111111111", "VaultPDA11111111111111111111111111111111111"],
+      "data": "3Bxs4h24hBtQy9rw",
+      "innerInstructions": [
+        {"programId": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA", "accounts": ["PayerATA1111111111111111111111111111111111", "VaultATA1111111111111111111111111111111111"], "data": "3DdGGhkhJbjm"},
+        {"programId": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA", "accounts": ["VaultATA1111111111111111111111111111111111", "MerchantATA11111111111111111111111111111111"], "data": "3DdGGhkhJbjm"},
+        {"programId": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA", "accounts": ["VaultATA1111111111111111111111111111111111", "MerchantATA11111111111111111111111111111111"], "data": "3DdGGhkhJbjm"}
+      ]
+    }
+  ]
+}]`
+
+func TestParseEnhancedTransactions_InnerInstructionTransfers(t *testing.T) {
+	usdcMint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	addressMap := map[string]WalletLookup{
+		"MerchantATA11111111111111111111111111111111": {
+			WalletAddress: "MerchantWallet111111111111111111111111111",
+			Network:       "mainnet",
+			AssetType:     "spl-token",
+			TokenMint:     usdcMint,
+		},
+	}
+
+	txns, err := ParseWebhookPayload([]byte(routedPaymentPayload))
+	require.NoError(t, err)
+	require.Len(t, txns[0].Instructions[0].InnerInstructions, 3)
+
+	results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{}, testLogger())
+
+	require.Len(t, results, 1, "hops into the same ATA are one payment")
+	assert.Equal(t, "MerchantWallet111111111111111111111111111", results[0].WalletAddress)
+	assert.Equal(t, int64(2_500_000), results[0].Amount, "both inner transfers are summed")
+	assert.Equal(t, usdcMint, *results[0].TokenMint)
+	assert.Equal(t, "PayerWallet11111111111111111111111111111111", *results[0].FromAddress,
+		"sender is traced back through the program vault")
+	assert.Equal(t, int64(5000), results[0].Fee)
+}
+
+// withdrawalPayload is a Helius webhook payload for a monitored wallet paying
+// out: it sends SOL to two recipients and USDC from its ATA to a third.
+const withdrawalPayload = `[{
+  "signature": "sigWithdraw",
+  "slot": 910000,
+  "timestamp": 1700009000,
+  "fee": 5000,
+  "feePayer": "TreasuryWallet11111111111111111111111111111",
+  "type": "TRANSFER",
+  "source": "SYSTEM_PROGRAM",
+  "nativeTransfers": [
+    {"fromUserAccount": "TreasuryWallet11111111111111111111111111111", "toUserAccount": "PayeeWallet11111111111111111111111111111111", "amount": 300000000},
+    {"fromUserAccount": "TreasuryWallet11111111111111111111111111111", "toUserAccount": "OtherPayee111111111111111111111111111111111", "amount": 200000000}
+  ],
+  "tokenTransfers": [
+    {
+      "fromUserAccount": "TreasuryWallet11111111111111111111111111111",
+      "fromTokenAccount": "TreasuryATA1111111111111111111111111111111",
+      "toUserAccount": "VendorWallet1111111111111111111111111111111",
+      "toTokenAccount": "VendorATA11111111111111111111111111111111",
+      "mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+      "tokenAmount": 7.5,
+      "tokenStandard": "Fungible"
+    }
+  ],
+  "transactionError": null,
+  "instructions": []
+}]`
+
+func TestParseEnhancedTransactions_Direction(t *testing.T) {
+	usdcMint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	treasury := "TreasuryWallet11111111111111111111111111111"
+	addressMap := map[string]WalletLookup{
+		treasury: {
+			WalletAddress: treasury,
+			Network:       "mainnet",
+			AssetType:     "sol",
+		},
+		"TreasuryATA1111111111111111111111111111111": {
+			WalletAddress: treasury,
+			Network:       "mainnet",
+			AssetType:     "spl-token",
+			TokenMint:     usdcMint,
+		},
+	}
+
+	withdrawal, err := ParseWebhookPayload([]byte(withdrawalPayload))
+	require.NoError(t, err)
+
+	t.Run("outgoing ignored by default", func(t *testing.T) {
+		results := ParseEnhancedTransactions(withdrawal, addressMap, ParseOptions{}, testLogger())
+		assert.Empty(t, results)
+	})
+
+	t.Run("outgoing recorded when enabled", func(t *testing.T) {
+		results := ParseEnhancedTransactions(withdrawal, addressMap, ParseOptions{RecordOutgoing: true}, testLogger())
+
+		require.Len(t, results, 2, "one record per asset sent")
+		assert.Equal(t, "out", results[0].Direction)
+		assert.Equal(t, treasury, results[0].WalletAddress)
+		assert.Equal(t, int64(500_000_000), results[0].Amount, "native transfers are summed as a magnitude")
+		assert.Nil(t, results[0].TokenMint)
+		assert.Equal(t, treasury, *results[0].FromAddress)
+
+		assert.Equal(t, "out", results[1].Direction)
+		assert.Equal(t, int64(7_500_000), results[1].Amount)
+		assert.Equal(t, usdcMint, *results[1].TokenMint)
+	})
+
+	t.Run("incoming", func(t *testing.T) {
+		txns := []EnhancedTransaction{{
+			Signature: "sigDeposit",
+			Timestamp: 1700009100,
+			NativeTransfers: []NativeTransfer{
+				{FromUserAccount: "PayerWallet11111111111111111111111111111111", ToUserAccount: treasury, Amount: 1_000_000},
+			},
+		}}
+		results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{RecordOutgoing: true}, testLogger())
+
+		require.Len(t, results, 1)
+		assert.Equal(t, "in", results[0].Direction)
+		assert.Equal(t, int64(1_000_000), results[0].Amount)
+	})
+
+	t.Run("self-transfer", func(t *testing.T) {
+		txns := []EnhancedTransaction{{
+			Signature: "sigSelf",
+			Timestamp: 1700009200,
+			FeePayer:  treasury,
+			NativeTransfers: []NativeTransfer{
+				{FromUserAccount: treasury, ToUserAccount: treasury, Amount: 2_000_000},
+			},
+			TokenTransfers: []TokenTransfer{
+				{
+					FromUserAccount:  treasury,
+					FromTokenAccount: "TreasuryAux111111111111111111111111111111",
+					ToUserAccount:    treasury,
+					ToTokenAccount:   "TreasuryATA1111111111111111111111111111111",
+					Mint:             usdcMint,
+					TokenAmount:      1,
+				},
+			},
+		}}
+		results := ParseEnhancedTransactions(txns, addressMap, ParseOptions{RecordOutgoing: true}, testLogger())
+
+		require.Len(t, results, 2, "a self-transfer is not also recorded as outgoing")
+		assert.Equal(t, "self", results[0].Direction)
+		assert.Equal(t, int64(2_000_000), results[0].Amount)
+		assert.Equal(t, "self", results[1].Direction)
+		assert.Equal(t, int64(1_000_000), results[1].Amount)
+	})
+}
+
+func TestTokenTransferSender(t *testing.T) {
+	mint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	tests := []struct {
+		name      string
+		feePayer  string
+		transfers []TokenTransfer
+		want      string
+	}{
+		{
+			name:     "direct transfer",
+			feePayer: "Relayer",
+			transfers: []TokenTransfer{
+				{FromUserAccount: "Payer", ToUserAccount: "Merchant", Mint: mint},
+			},
+			want: "Payer",
+		},
+		{
+			name:     "routed through two vaults",
+			feePayer: "Payer",
+			transfers: []TokenTransfer{
+				{FromUserAccount: "Payer", ToUserAccount: "VaultA", Mint: mint},
+				{FromUserAccount: "VaultA", ToUserAccount: "VaultB", Mint: mint},
+				{FromUserAccount: "VaultB", ToUserAccount: "Merchant", Mint: mint},
+			},
+			want: "Payer",
+		},
+		{
+			name:     "swap then pay stops at the fee payer",
+			feePayer: "Payer",
+			transfers: []TokenTransfer{
+				{FromUserAccount: "Pool", ToUserAccount: "Payer", Mint: mint},
+				{FromUserAccount: "Payer", ToUserAccount: "Merchant", Mint: mint},
+			},
+			want: "Payer",
+		},
+		{
+			name:     "later transfers are not a funding source",
+			feePayer: "Relayer",
+			transfers: []TokenTransfer{
+				{FromUserAccount: "Payer", ToUserAccount: "Merchant", Mint: mint},
+				{FromUserAccount: "Other", ToUserAccount: "Payer", Mint: mint},
+			},
+			want: "Payer",
+		},
+		{
+			name:     "other mints are not followed",
+			feePayer: "Relayer",
+			transfers: []TokenTransfer{
+				{FromUserAccount: "Payer", ToUserAccount: "Vault", Mint: "OtherMint"},
+				{FromUserAccount: "Vault", ToUserAccount: "Merchant", Mint: mint},
+			},
+			want: "Vault",
+		},
+		{
+			name:     "cycle terminates",
+			feePayer: "Relayer",
+			transfers: []TokenTransfer{
+				{FromUserAccount: "VaultB", ToUserAccount: "VaultA", Mint: mint},
+				{FromUserAccount: "VaultA", ToUserAccount: "VaultB", Mint: mint},
+				{FromUserAccount: "VaultB", ToUserAccount: "Merchant", Mint: mint},
+			},
+			want: "VaultB",
+		},
+		{
+			name:     "unresolved owner falls back to fee payer",
+			feePayer: "Payer",
+			transfers: []TokenTransfer{
+				{FromUserAccount: "", ToUserAccount: "Merchant", Mint: mint},
+			},
+			want: "Payer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := EnhancedTransaction{FeePayer: tt.feePayer, TokenTransfers: tt.transfers}
+			idx := 0
+			for i, tr := range tt.transfers {
+				if tr.ToUserAccount == "Merchant" {
+					idx = i
+				}
+			}
+			assert.Equal(t, tt.want, tokenTransferSender(txn, idx))
+		})
+	}
+}
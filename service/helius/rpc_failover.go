@@ -0,0 +1,333 @@
+package helius
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// breakerThreshold is how many consecutive failures open an endpoint's
+	// circuit breaker.
+	breakerThreshold = 3
+	// breakerCooldown is how long an open breaker skips its endpoint before
+	// letting a single request through to probe it.
+	breakerCooldown = 30 * time.Second
+	// maxRetryAfter caps how long a Retry-After header can keep an endpoint
+	// out of rotation, so a bogus value can't disable it indefinitely.
+	maxRetryAfter = 10 * time.Minute
+)
+
+// RateLimitError is returned by RPC calls when an endpoint answered 429 Too
+// Many Requests, or when every endpoint is still within the Retry-After its
+// provider sent. Callers should wait RetryAfter (0 if the provider gave
+// none) before calling again rather than retrying immediately, which only
+// prolongs the rate limiting. It may be wrapped; use errors.As.
+type RateLimitError struct {
+	Endpoint   string // host of the rate-limited endpoint
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s: rate limited (status 429), retry after %s", e.Endpoint, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s: rate limited (status 429)", e.Endpoint)
+}
+
+// RPCRecorder records which endpoint served each RPC request.
+// *metrics.Metrics satisfies it.
+type RPCRecorder interface {
+	RecordRPCCall(method, status, endpoint string, duration float64)
+}
+
+// rpcEndpoint is one JSON-RPC endpoint for a network.
+type rpcEndpoint struct {
+	url   string // request URL, including any credentials
+	label string // host only, safe for logs and metric labels
+}
+
+// circuitBreaker tracks consecutive failures of one endpoint.
+type circuitBreaker struct {
+	failures  int
+	openUntil time.Time
+	// rateLimitedUntil honours the endpoint's last Retry-After. Unlike an
+	// open breaker, it is never overridden to probe the endpoint early.
+	rateLimitedUntil time.Time
+}
+
+// rpcFailover sends JSON-RPC requests to a network's primary Helius endpoint
+// and, when it fails, to each configured fallback in order. Each endpoint has
+// its own circuit breaker so a provider that is down isn't retried on every
+// request.
+type rpcFailover struct {
+	now func() time.Time // overridden in tests
+
+	mu        sync.Mutex
+	fallbacks map[string][]string        // network -> fallback URLs, in order
+	breakers  map[string]*circuitBreaker // endpoint URL -> breaker
+	recorder  RPCRecorder
+}
+
+func newRPCFailover() *rpcFailover {
+	return &rpcFailover{
+		now:       time.Now,
+		fallbacks: make(map[string][]string),
+		breakers:  make(map[string]*circuitBreaker),
+	}
+}
+
+// SetRPCFallbacks configures fallback JSON-RPC endpoints per network, tried in
+// order when the Helius RPC endpoint fails or is rate limited. Fallback URLs
+// are used as given, so any credentials must be part of the URL; the Helius
+// API key is never sent to them. The enhanced transactions API
+// (GetTransactions) is Helius-only and has no fallback.
+func (c *Client) SetRPCFallbacks(fallbacks map[string][]string) {
+	c.rpc.mu.Lock()
+	defer c.rpc.mu.Unlock()
+
+	c.rpc.fallbacks = make(map[string][]string, len(fallbacks))
+	for network, urls := range fallbacks {
+		c.rpc.fallbacks[network] = append([]string(nil), urls...)
+	}
+}
+
+// SetRPCRecorder records the outcome and serving endpoint of every RPC
+// request, e.g. to solana_rpc_calls_total.
+func (c *Client) SetRPCRecorder(r RPCRecorder) {
+	c.rpc.mu.Lock()
+	defer c.rpc.mu.Unlock()
+	c.rpc.recorder = r
+}
+
+// rpcEndpoints returns network's endpoints in the order they should be tried:
+// the Helius endpoint first, then the fallbacks.
+func (c *Client) rpcEndpoints(network string) ([]rpcEndpoint, error) {
+	rpcURL, ok := c.rpcURLs[network]
+	if !ok {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	endpoints := []rpcEndpoint{{
+		url:   fmt.Sprintf("%s/?api-key=%s", rpcURL, c.apiKey),
+		label: endpointLabel(rpcURL),
+	}}
+
+	c.rpc.mu.Lock()
+	defer c.rpc.mu.Unlock()
+	for _, u := range c.rpc.fallbacks[network] {
+		endpoints = append(endpoints, rpcEndpoint{url: u, label: endpointLabel(u)})
+	}
+	return endpoints, nil
+}
+
+// postRPC sends a JSON-RPC request body to network's endpoints, failing over
+// on transport errors, timeouts, rate limiting and server errors. Endpoints
+// whose breaker is open are skipped unless every endpoint's is; endpoints
+// within their Retry-After are always skipped, and if that leaves none a
+// *RateLimitError is returned without sending anything. Other
+// non-200 responses are returned as errors without failover, since another
+// endpoint would reject the same request. It returns the response and the
+// label of the endpoint that served it; the caller must close the response
+// body.
+func (c *Client) postRPC(ctx context.Context, network, method string, body []byte) (*http.Response, string, error) {
+	endpoints, err := c.rpcEndpoints(network)
+	if err != nil {
+		return nil, "", err
+	}
+
+	available, rateLimited := c.rpc.available(endpoints)
+	if rateLimited != nil {
+		return nil, "", rateLimited
+	}
+	var errs []error
+	for _, ep := range available {
+		if err := ctx.Err(); err != nil {
+			return nil, "", err
+		}
+
+		start := c.rpc.now()
+		resp, err := c.sendRPC(ctx, ep, body)
+		duration := c.rpc.now().Sub(start).Seconds()
+
+		switch {
+		case err != nil:
+			c.rpc.record(method, "error", ep.label, duration)
+			c.rpc.failure(ep.url)
+			errs = append(errs, fmt.Errorf("%s: %w", ep.label, err))
+		case resp.StatusCode == http.StatusTooManyRequests:
+			resp.Body.Close()
+			retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), c.rpc.now())
+			c.rpc.record(method, "rate_limited", ep.label, duration)
+			c.rpc.rateLimited(ep.url, retryAfter)
+			errs = append(errs, &RateLimitError{Endpoint: ep.label, RetryAfter: retryAfter})
+		case shouldFailOver(resp.StatusCode):
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			c.rpc.record(method, "error", ep.label, duration)
+			c.rpc.failure(ep.url)
+			errs = append(errs, fmt.Errorf("%s: helius API error (status %d): %s", ep.label, resp.StatusCode, string(respBody)))
+		case resp.StatusCode != http.StatusOK:
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			c.rpc.record(method, "error", ep.label, duration)
+			return nil, "", fmt.Errorf("helius API error (status %d): %s", resp.StatusCode, string(respBody))
+		default:
+			c.rpc.record(method, "success", ep.label, duration)
+			c.rpc.success(ep.url)
+			if len(errs) > 0 {
+				c.logger.Warn("RPC request served by fallback endpoint",
+					"network", network,
+					"method", method,
+					"endpoint", ep.label,
+					"error", errors.Join(errs...),
+				)
+			}
+			return resp, ep.label, nil
+		}
+	}
+
+	if len(errs) == 1 {
+		return nil, "", errs[0]
+	}
+	return nil, "", fmt.Errorf("all %d RPC endpoints failed: %w", len(errs), errors.Join(errs...))
+}
+
+func (c *Client) sendRPC(ctx context.Context, ep rpcEndpoint, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// The error embeds the URL, which may carry an API key.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// shouldFailOver reports whether a response status means the endpoint, not
+// the request, is at fault. 429 is handled separately to honour Retry-After.
+func shouldFailOver(status int) bool {
+	return status == http.StatusRequestTimeout ||
+		status >= http.StatusInternalServerError
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as
+// an HTTP date, capped at maxRetryAfter. It returns 0 if the header is
+// absent, malformed or in the past.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(header); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(header); err == nil {
+		d = t.Sub(now)
+	}
+	return min(max(d, 0), maxRetryAfter)
+}
+
+// available returns the endpoints whose breaker is closed or due a probe,
+// preserving order. If every breaker is open it returns all endpoints not
+// within a Retry-After: trying a probably-down provider beats failing
+// without trying. If every endpoint is within its Retry-After, it returns a
+// RateLimitError for the one that frees up first instead.
+func (f *rpcFailover) available(endpoints []rpcEndpoint) ([]rpcEndpoint, *RateLimitError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	var out, notRateLimited []rpcEndpoint
+	var soonest *RateLimitError
+	for _, ep := range endpoints {
+		b := f.breakers[ep.url]
+		if b != nil && now.Before(b.rateLimitedUntil) {
+			if wait := b.rateLimitedUntil.Sub(now); soonest == nil || wait < soonest.RetryAfter {
+				soonest = &RateLimitError{Endpoint: ep.label, RetryAfter: wait}
+			}
+			continue
+		}
+		notRateLimited = append(notRateLimited, ep)
+		if b == nil || !now.Before(b.openUntil) {
+			out = append(out, ep)
+		}
+	}
+	if len(notRateLimited) == 0 {
+		return nil, soonest
+	}
+	if len(out) == 0 {
+		return notRateLimited, nil
+	}
+	return out, nil
+}
+
+// failure counts a failed request. Once the breaker is open, each further
+// failure (e.g. of the probe after a cooldown) re-opens it.
+func (f *rpcFailover) failure(endpointURL string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b := f.breakers[endpointURL]
+	if b == nil {
+		b = &circuitBreaker{}
+		f.breakers[endpointURL] = b
+	}
+	b.failures++
+	if b.failures >= breakerThreshold {
+		b.openUntil = f.now().Add(breakerCooldown)
+	}
+}
+
+// rateLimited counts a 429 as a failure and, if the provider sent a
+// Retry-After, keeps the endpoint out of rotation until it has passed.
+func (f *rpcFailover) rateLimited(endpointURL string, retryAfter time.Duration) {
+	f.failure(endpointURL)
+	if retryAfter <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.breakers[endpointURL].rateLimitedUntil = f.now().Add(retryAfter)
+}
+
+// success closes the endpoint's breaker.
+func (f *rpcFailover) success(endpointURL string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.breakers, endpointURL)
+}
+
+func (f *rpcFailover) record(method, status, endpoint string, duration float64) {
+	f.mu.Lock()
+	r := f.recorder
+	f.mu.Unlock()
+	if r != nil {
+		r.RecordRPCCall(method, status, endpoint, duration)
+	}
+}
+
+// endpointLabel returns the host of an endpoint URL, dropping the path and
+// query where credentials usually live.
+func endpointLabel(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
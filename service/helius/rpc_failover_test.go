@@ -0,0 +1,269 @@
+package helius
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rpcCall struct {
+	method, status, endpoint string
+}
+
+type fakeRecorder struct {
+	mu    sync.Mutex
+	calls []rpcCall
+}
+
+func (r *fakeRecorder) RecordRPCCall(method, status, endpoint string, duration float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, rpcCall{method, status, endpoint})
+}
+
+// slotServer answers getSlot with status, counting requests.
+func slotServer(t *testing.T, status int, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"jsonrpc": "2.0", "id": "forohtoo", "result": 42}`))
+		} else {
+			w.Write([]byte(`unavailable`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func hostOf(srv *httptest.Server) string {
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestPostRPC_FailsOverToSecondEndpoint(t *testing.T) {
+	var primaryReqs, fallbackReqs atomic.Int32
+	primary := slotServer(t, http.StatusServiceUnavailable, &primaryReqs)
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackReqs.Add(1)
+		assert.Empty(t, r.URL.Query().Get("api-key"), "Helius API key must not be sent to fallbacks")
+		assert.Equal(t, "fallback-token", r.URL.Query().Get("token"))
+		w.Write([]byte(`{"jsonrpc": "2.0", "id": "forohtoo", "result": 42}`))
+	}))
+	defer fallback.Close()
+
+	c := newClientWithRPCURL(primary.URL)
+	c.SetRPCFallbacks(map[string][]string{"mainnet": {fallback.URL + "/?token=fallback-token"}})
+	rec := &fakeRecorder{}
+	c.SetRPCRecorder(rec)
+
+	health, err := c.HealthCheck(context.Background(), "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), health.Slot)
+	assert.Equal(t, hostOf(fallback), health.Endpoint)
+	assert.Equal(t, int32(1), primaryReqs.Load())
+	assert.Equal(t, int32(1), fallbackReqs.Load())
+
+	assert.Equal(t, []rpcCall{
+		{"getSlot", "error", hostOf(primary)},
+		{"getSlot", "success", hostOf(fallback)},
+	}, rec.calls)
+}
+
+func TestPostRPC_NoFailoverOnClientError(t *testing.T) {
+	var primaryReqs, fallbackReqs atomic.Int32
+	primary := slotServer(t, http.StatusBadRequest, &primaryReqs)
+	fallback := slotServer(t, http.StatusOK, &fallbackReqs)
+
+	c := newClientWithRPCURL(primary.URL)
+	c.SetRPCFallbacks(map[string][]string{"mainnet": {fallback.URL}})
+
+	_, err := c.HealthCheck(context.Background(), "mainnet")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+	assert.Equal(t, int32(0), fallbackReqs.Load())
+}
+
+func TestPostRPC_AllEndpointsFail(t *testing.T) {
+	var primaryReqs, fallbackReqs atomic.Int32
+	primary := slotServer(t, http.StatusTooManyRequests, &primaryReqs)
+	fallback := slotServer(t, http.StatusBadGateway, &fallbackReqs)
+
+	c := newClientWithRPCURL(primary.URL)
+	c.SetRPCFallbacks(map[string][]string{"mainnet": {fallback.URL}})
+
+	_, err := c.HealthCheck(context.Background(), "mainnet")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all 2 RPC endpoints failed")
+	assert.Contains(t, err.Error(), "status 429")
+	assert.Contains(t, err.Error(), "status 502")
+	assert.NotContains(t, err.Error(), "test-api-key")
+}
+
+func TestPostRPC_CircuitBreaker(t *testing.T) {
+	var primaryReqs, fallbackReqs atomic.Int32
+	primary := slotServer(t, http.StatusInternalServerError, &primaryReqs)
+	fallback := slotServer(t, http.StatusOK, &fallbackReqs)
+
+	c := newClientWithRPCURL(primary.URL)
+	c.SetRPCFallbacks(map[string][]string{"mainnet": {fallback.URL}})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.rpc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < breakerThreshold; i++ {
+		_, err := c.HealthCheck(ctx, "mainnet")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(breakerThreshold), primaryReqs.Load())
+
+	// Breaker is open: the primary is skipped.
+	_, err := c.HealthCheck(ctx, "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, int32(breakerThreshold), primaryReqs.Load())
+	assert.Equal(t, int32(breakerThreshold+1), fallbackReqs.Load())
+
+	// After the cooldown one probe goes through and re-opens it on failure.
+	now = now.Add(breakerCooldown)
+	_, err = c.HealthCheck(ctx, "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, int32(breakerThreshold+1), primaryReqs.Load())
+
+	_, err = c.HealthCheck(ctx, "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, int32(breakerThreshold+1), primaryReqs.Load())
+}
+
+func TestPostRPC_AllBreakersOpenStillTries(t *testing.T) {
+	var primaryReqs atomic.Int32
+	primary := slotServer(t, http.StatusInternalServerError, &primaryReqs)
+
+	c := newClientWithRPCURL(primary.URL)
+	for i := 0; i < breakerThreshold+2; i++ {
+		_, err := c.HealthCheck(context.Background(), "mainnet")
+		require.Error(t, err)
+	}
+	assert.Equal(t, int32(breakerThreshold+2), primaryReqs.Load())
+}
+
+// rateLimitedServer answers every request with 429 and retryAfter as the
+// Retry-After header (omitted if empty), counting requests.
+func rateLimitedServer(t *testing.T, retryAfter string, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPostRPC_RateLimitFailsOver(t *testing.T) {
+	var primaryReqs, fallbackReqs atomic.Int32
+	primary := rateLimitedServer(t, "60", &primaryReqs)
+	fallback := slotServer(t, http.StatusOK, &fallbackReqs)
+
+	c := newClientWithRPCURL(primary.URL)
+	c.SetRPCFallbacks(map[string][]string{"mainnet": {fallback.URL}})
+	rec := &fakeRecorder{}
+	c.SetRPCRecorder(rec)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.rpc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := c.HealthCheck(ctx, "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, []rpcCall{
+		{"getSlot", "rate_limited", hostOf(primary)},
+		{"getSlot", "success", hostOf(fallback)},
+	}, rec.calls)
+
+	// The primary is skipped until its Retry-After has passed.
+	now = now.Add(59 * time.Second)
+	_, err = c.HealthCheck(ctx, "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), primaryReqs.Load())
+
+	now = now.Add(time.Second)
+	_, err = c.HealthCheck(ctx, "mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), primaryReqs.Load())
+	assert.Equal(t, int32(3), fallbackReqs.Load())
+}
+
+func TestPostRPC_RateLimitError(t *testing.T) {
+	var primaryReqs atomic.Int32
+	primary := rateLimitedServer(t, "30", &primaryReqs)
+
+	c := newClientWithRPCURL(primary.URL)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.rpc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := c.GetSignatureStatuses(ctx, "mainnet", []string{"sig1"})
+	var rateLimited *RateLimitError
+	require.True(t, errors.As(err, &rateLimited), "got %v", err)
+	assert.Equal(t, hostOf(primary), rateLimited.Endpoint)
+	assert.Equal(t, 30*time.Second, rateLimited.RetryAfter)
+
+	// Within the Retry-After the endpoint isn't called at all, even though it
+	// is the only one.
+	now = now.Add(10 * time.Second)
+	_, err = c.GetSignatureStatuses(ctx, "mainnet", []string{"sig1"})
+	require.True(t, errors.As(err, &rateLimited), "got %v", err)
+	assert.Equal(t, 20*time.Second, rateLimited.RetryAfter)
+	assert.Equal(t, int32(1), primaryReqs.Load())
+}
+
+func TestPostRPC_RateLimitWithoutRetryAfter(t *testing.T) {
+	var primaryReqs atomic.Int32
+	primary := rateLimitedServer(t, "", &primaryReqs)
+
+	c := newClientWithRPCURL(primary.URL)
+	_, err := c.HealthCheck(context.Background(), "mainnet")
+	var rateLimited *RateLimitError
+	require.True(t, errors.As(err, &rateLimited), "got %v", err)
+	assert.Zero(t, rateLimited.RetryAfter)
+
+	// Without a Retry-After the breaker alone decides, so the next call is sent.
+	_, err = c.HealthCheck(context.Background(), "mainnet")
+	require.Error(t, err)
+	assert.Equal(t, int32(2), primaryReqs.Load())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-5", 0},
+		{"soon", 0},
+		{now.Add(45 * time.Second).Format(http.TimeFormat), 45 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"86400", maxRetryAfter},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseRetryAfter(tt.header, now), tt.header)
+	}
+}
+
+func TestEndpointLabel(t *testing.T) {
+	assert.Equal(t, "mainnet.helius-rpc.com", endpointLabel("https://mainnet.helius-rpc.com"))
+	assert.Equal(t, "rpc.example.com:8899", endpointLabel("https://rpc.example.com:8899/v1/secret?token=abc"))
+	assert.Equal(t, "unknown", endpointLabel("not a url"))
+}
@@ -0,0 +1,86 @@
+package helius
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxSignatureStatusBatch is the most signatures getSignatureStatuses accepts
+// per call.
+const MaxSignatureStatusBatch = 256
+
+// getSignatureStatusesResponse is the subset of the getSignatureStatuses
+// response we read. Unknown signatures have a null entry.
+type getSignatureStatusesResponse struct {
+	Result *struct {
+		Value []*struct {
+			ConfirmationStatus string `json:"confirmationStatus"`
+			Err                any    `json:"err"`
+		} `json:"value"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GetSignatureStatuses returns the RPC-reported commitment ("processed",
+// "confirmed" or "finalized") of each signature, or "failed" if the
+// transaction errored. Signatures the RPC node doesn't know are omitted from
+// the result. At most MaxSignatureStatusBatch signatures may be passed.
+func (c *Client) GetSignatureStatuses(ctx context.Context, network string, signatures []string) (map[string]string, error) {
+	if _, ok := c.rpcURLs[network]; !ok {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+	if len(signatures) > MaxSignatureStatusBatch {
+		return nil, fmt.Errorf("too many signatures: %d (max %d)", len(signatures), MaxSignatureStatusBatch)
+	}
+	if len(signatures) == 0 {
+		return map[string]string{}, nil
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      "forohtoo",
+		"method":  "getSignatureStatuses",
+		"params": []any{
+			signatures,
+			map[string]bool{"searchTransactionHistory": true},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, _, err := c.postRPC(ctx, network, "getSignatureStatuses", data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var statuses getSignatureStatusesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if statuses.Error != nil {
+		return nil, fmt.Errorf("helius RPC error (code %d): %s", statuses.Error.Code, statuses.Error.Message)
+	}
+	if statuses.Result == nil || len(statuses.Result.Value) != len(signatures) {
+		return nil, fmt.Errorf("unexpected getSignatureStatuses response for %d signatures", len(signatures))
+	}
+
+	result := make(map[string]string, len(signatures))
+	for i, status := range statuses.Result.Value {
+		if status == nil {
+			continue
+		}
+		if status.Err != nil {
+			result[signatures[i]] = "failed"
+			continue
+		}
+		result[signatures[i]] = status.ConfirmationStatus
+	}
+
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package helius
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSignatureStatuses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "getSignatureStatuses", body.Method)
+		require.Len(t, body.Params, 2)
+		assert.JSONEq(t, `["sigFinal","sigConfirmed","sigFailed","sigUnknown"]`, string(body.Params[0]))
+		assert.JSONEq(t, `{"searchTransactionHistory":true}`, string(body.Params[1]))
+
+		w.Write([]byte(`{
+			"jsonrpc": "2.0",
+			"id": "forohtoo",
+			"result": {
+				"context": {"slot": 100},
+				"value": [
+					{"slot": 90, "confirmations": null, "err": null, "confirmationStatus": "finalized"},
+					{"slot": 99, "confirmations": 1, "err": null, "confirmationStatus": "confirmed"},
+					{"slot": 91, "confirmations": null, "err": {"InstructionError": [0, "Custom"]}, "confirmationStatus": "finalized"},
+					null
+				]
+			}
+		}`))
+	}))
+	defer srv.Close()
+
+	c := newClientWithRPCURL(srv.URL)
+
+	statuses, err := c.GetSignatureStatuses(context.Background(), "mainnet", []string{"sigFinal", "sigConfirmed", "sigFailed", "sigUnknown"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"sigFinal":     "finalized",
+		"sigConfirmed": "confirmed",
+		"sigFailed":    "failed",
+	}, statuses)
+}
+
+func TestGetSignatureStatuses_RPCError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc": "2.0", "id": "forohtoo", "error": {"code": -32602, "message": "Invalid param"}}`))
+	}))
+	defer srv.Close()
+
+	c := newClientWithRPCURL(srv.URL)
+
+	_, err := c.GetSignatureStatuses(context.Background(), "mainnet", []string{"sig"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid param")
+}
+
+func TestGetSignatureStatuses_TooMany(t *testing.T) {
+	c := newClientWithRPCURL("http://unused.invalid")
+
+	_, err := c.GetSignatureStatuses(context.Background(), "mainnet", make([]string, MaxSignatureStatusBatch+1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many signatures")
+}
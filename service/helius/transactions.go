@@ -0,0 +1,76 @@
+package helius
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxTransactionLookupBatch is the most signatures the enhanced transactions
+// API accepts per call.
+const MaxTransactionLookupBatch = 100
+
+// defaultTransactionAPIURLs are the Helius enhanced transactions API endpoints
+// per network. They return transactions in the same format as webhook
+// deliveries.
+var defaultTransactionAPIURLs = map[string]string{
+	"mainnet": "https://api-mainnet.helius-rpc.com/v0/transactions",
+	"devnet":  "https://api-devnet.helius-rpc.com/v0/transactions",
+}
+
+// GetTransactions fetches transactions by signature from the Helius enhanced
+// transactions API. The result is in the webhook payload format, so it can be
+// fed to ParseEnhancedTransactions. Signatures Helius doesn't know are omitted.
+func (c *Client) GetTransactions(ctx context.Context, network string, signatures []string) ([]EnhancedTransaction, error) {
+	apiURL, ok := c.txnAPIURLs[network]
+	if !ok {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+	if len(signatures) > MaxTransactionLookupBatch {
+		return nil, fmt.Errorf("too many signatures: %d (max %d)", len(signatures), MaxTransactionLookupBatch)
+	}
+	if len(signatures) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(map[string][]string{"transactions": signatures})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s?api-key=%s", apiURL, c.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("helius API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var txns []EnhancedTransaction
+	if err := json.NewDecoder(resp.Body).Decode(&txns); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Unknown signatures may come back as null entries.
+	found := txns[:0]
+	for _, txn := range txns {
+		if txn.Signature != "" {
+			found = append(found, txn)
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,65 @@
+package helius
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransactions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "test-api-key", r.URL.Query().Get("api-key"))
+
+		var body struct {
+			Transactions []string `json:"transactions"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []string{"sigKnown", "sigUnknown"}, body.Transactions)
+
+		w.Write([]byte(`[
+			{"signature": "sigKnown", "slot": 100, "timestamp": 1700000000, "type": "TRANSFER",
+			 "nativeTransfers": [{"fromUserAccount": "from1", "toUserAccount": "to1", "amount": 5000}]},
+			null
+		]`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("test-api-key", "", "", newTestLogger())
+	c.txnAPIURLs = map[string]string{"devnet": srv.URL}
+
+	txns, err := c.GetTransactions(context.Background(), "devnet", []string{"sigKnown", "sigUnknown"})
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, "sigKnown", txns[0].Signature)
+	require.Len(t, txns[0].NativeTransfers, 1)
+	assert.Equal(t, "to1", txns[0].NativeTransfers[0].ToUserAccount)
+}
+
+func TestGetTransactions_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "invalid signature"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("test-api-key", "", "", newTestLogger())
+	c.txnAPIURLs = map[string]string{"mainnet": srv.URL}
+
+	_, err := c.GetTransactions(context.Background(), "mainnet", []string{"bad"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+
+	_, err = c.GetTransactions(context.Background(), "testnet", []string{"sig"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported network")
+
+	_, err = c.GetTransactions(context.Background(), "mainnet", make([]string, MaxTransactionLookupBatch+1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many signatures")
+}
@@ -80,10 +80,16 @@ type AccountData struct {
 
 // TokenBalanceChange represents a token balance change for an account.
 type TokenBalanceChange struct {
-	UserAccount  string  `json:"userAccount"`
-	TokenAccount string  `json:"tokenAccount"`
-	Mint         string  `json:"mint"`
-	RawAmount    string  `json:"rawTokenAmount"`
+	UserAccount    string         `json:"userAccount"`
+	TokenAccount   string         `json:"tokenAccount"`
+	Mint           string         `json:"mint"`
+	RawTokenAmount RawTokenAmount `json:"rawTokenAmount"`
+}
+
+// RawTokenAmount is a balance change in base units, with the mint's decimals.
+type RawTokenAmount struct {
+	TokenAmount string `json:"tokenAmount"`
+	Decimals    int    `json:"decimals"`
 }
 
 // InstructionGroup represents a parsed instruction in the transaction.
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ExemplarLabel is the exemplar label carrying the request ID, so an operator
+// can jump from a slow histogram bucket to the request's log lines.
+const ExemplarLabel = "trace_id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying a request ID. Histograms
+// observed with that context attach it as an exemplar.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// observe records value on o, with the context's request ID as an exemplar
+// when there is one. Exemplars are only exposed in the OpenMetrics format.
+func observe(ctx context.Context, o prometheus.Observer, value float64) {
+	id := RequestIDFromContext(ctx)
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && id != "" {
+		eo.ObserveWithExemplar(value, prometheus.Labels{ExemplarLabel: id})
+		return
+	}
+	o.Observe(value)
+}
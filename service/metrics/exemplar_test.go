@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordIngestionLatency_Exemplar(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	m.RecordIngestionLatency(WithRequestID(context.Background(), "req-123"), "mainnet", 3)
+	m.RecordIngestionLatency(context.Background(), "mainnet", 45)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var exemplars []string
+	var count uint64
+	for _, family := range families {
+		if family.GetName() != "transaction_ingestion_latency_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			count += metric.GetHistogram().GetSampleCount()
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				if ex := bucket.GetExemplar(); ex != nil {
+					for _, label := range ex.GetLabel() {
+						if label.GetName() == ExemplarLabel {
+							exemplars = append(exemplars, label.GetValue())
+						}
+					}
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, uint64(2), count)
+	assert.Equal(t, []string{"req-123"}, exemplars, "only the observation with a request ID carries an exemplar")
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	assert.Empty(t, RequestIDFromContext(context.Background()))
+	assert.Equal(t, "abc", RequestIDFromContext(WithRequestID(context.Background(), "abc")))
+}
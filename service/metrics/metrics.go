@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"context"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -22,11 +24,19 @@ type Metrics struct {
 	transactionsWrittenTotal       *prometheus.CounterVec
 	transactionsSkippedTotal       *prometheus.CounterVec
 	transactionsDeduplicationRatio *prometheus.GaugeVec
+	logicalPaymentDuplicatesTotal  *prometheus.CounterVec
+	ingestionLatency               *prometheus.HistogramVec
+	paymentDetectionLatency        *prometheus.HistogramVec
 
 	// Workflow Metrics
 	pollWorkflowDuration        *prometheus.HistogramVec
 	pollWorkflowExecutionsTotal *prometheus.CounterVec
 	pollActivityDuration        *prometheus.HistogramVec
+	workflowFailuresTotal       *prometheus.CounterVec
+	paymentRejectionsTotal      *prometheus.CounterVec
+	paymentFunnelEventsTotal    *prometheus.CounterVec
+	paymentCallbacksTotal       *prometheus.CounterVec
+	webhookDeliveryAttempts     *prometheus.CounterVec
 
 	// Database Metrics
 	dbQueryDuration   *prometheus.HistogramVec
@@ -39,10 +49,18 @@ type Metrics struct {
 	sseEventsSent        *prometheus.CounterVec
 
 	// NATS Metrics
-	natsMessagesPublished *prometheus.CounterVec
-	natsPublishDuration   *prometheus.HistogramVec
+	natsMessagesPublished   *prometheus.CounterVec
+	natsPublishDuration     *prometheus.HistogramVec
+	natsSubscriptionsActive prometheus.Gauge
+
+	// Build Metrics
+	buildInfo *prometheus.GaugeVec
 }
 
+// latencyBuckets covers block-to-write delays, from a prompt webhook delivery
+// to a transaction recovered by hand minutes later.
+var latencyBuckets = []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600}
+
 // NewMetrics creates a new Metrics instance and registers all collectors.
 // If registry is nil, prometheus.DefaultRegisterer is used.
 func NewMetrics(registry prometheus.Registerer) *Metrics {
@@ -128,6 +146,29 @@ func NewMetrics(registry prometheus.Registerer) *Metrics {
 			},
 			[]string{"wallet_address"},
 		),
+		logicalPaymentDuplicatesTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "logical_payment_duplicates_total",
+				Help: "Total number of transactions flagged as repeating an earlier transaction's payment ID",
+			},
+			[]string{"network"},
+		),
+		ingestionLatency: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "transaction_ingestion_latency_seconds",
+				Help:    "Delay from a transaction's block time to writing it, in seconds",
+				Buckets: latencyBuckets,
+			},
+			[]string{"network"},
+		),
+		paymentDetectionLatency: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "payment_detection_latency_seconds",
+				Help:    "Delay from a payment gateway transaction's block time to writing it, in seconds",
+				Buckets: latencyBuckets,
+			},
+			[]string{"network"},
+		),
 
 		// Workflow Metrics
 		pollWorkflowDuration: factory.NewHistogramVec(
@@ -153,6 +194,41 @@ func NewMetrics(registry prometheus.Registerer) *Metrics {
 			},
 			[]string{"activity", "wallet_address"},
 		),
+		workflowFailuresTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "workflow_failures_total",
+				Help: "Total number of terminal workflow failures by workflow, step, and kind (timeout or error)",
+			},
+			[]string{"workflow", "step", "kind"},
+		),
+		paymentRejectionsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_rejections_total",
+				Help: "Total number of otherwise-matching payments rejected by the payment gateway, by reason",
+			},
+			[]string{"network", "reason"},
+		),
+		paymentFunnelEventsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_funnel_events_total",
+				Help: "Total number of payment-gated registrations reaching each funnel stage, by network and stage",
+			},
+			[]string{"network", "stage"},
+		),
+		paymentCallbacksTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_callbacks_total",
+				Help: "Total number of registration-completed callback deliveries by network and result (delivered or failed)",
+			},
+			[]string{"network", "result"},
+		),
+		webhookDeliveryAttempts: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "webhook_delivery_attempts_total",
+				Help: "Total number of outbound webhook delivery attempts by event and status (success or error), retries included",
+			},
+			[]string{"event", "status"},
+		),
 
 		// Database Metrics
 		dbQueryDuration: factory.NewHistogramVec(
@@ -218,6 +294,21 @@ func NewMetrics(registry prometheus.Registerer) *Metrics {
 			},
 			[]string{"subject"},
 		),
+		natsSubscriptionsActive: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "nats_subscriptions_active",
+				Help: "Number of active NATS consumers backing SSE streams",
+			},
+		),
+
+		// Build Metrics
+		buildInfo: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "build_info",
+				Help: "Always 1; labels identify the running build and worker identity",
+			},
+			[]string{"version", "commit", "date", "identity"},
+		),
 	}
 }
 
@@ -271,6 +362,24 @@ func (m *Metrics) RecordDeduplicationRatio(walletAddress string, ratio float64)
 	m.transactionsDeduplicationRatio.WithLabelValues(walletAddress).Set(ratio)
 }
 
+// RecordLogicalPaymentDuplicate records a transaction flagged duplicate_logical.
+func (m *Metrics) RecordLogicalPaymentDuplicate(network string) {
+	m.logicalPaymentDuplicatesTotal.WithLabelValues(network).Inc()
+}
+
+// RecordIngestionLatency records how long after its block time a transaction
+// was written, with the request ID in ctx as an exemplar.
+func (m *Metrics) RecordIngestionLatency(ctx context.Context, network string, latency float64) {
+	observe(ctx, m.ingestionLatency.WithLabelValues(network), latency)
+}
+
+// RecordPaymentDetectionLatency records how long after its block time a
+// transaction to the payment gateway's service wallet was written, with the
+// request ID in ctx as an exemplar.
+func (m *Metrics) RecordPaymentDetectionLatency(ctx context.Context, network string, latency float64) {
+	observe(ctx, m.paymentDetectionLatency.WithLabelValues(network), latency)
+}
+
 // Workflow metric helpers
 
 // RecordWorkflowDuration records workflow execution duration.
@@ -284,6 +393,54 @@ func (m *Metrics) RecordActivityDuration(activity, walletAddress string, duratio
 	m.pollActivityDuration.WithLabelValues(activity, walletAddress).Observe(duration)
 }
 
+// RecordWorkflowFailure records a terminal workflow failure.
+func (m *Metrics) RecordWorkflowFailure(workflow, step, kind string) {
+	m.workflowFailuresTotal.WithLabelValues(workflow, step, kind).Inc()
+}
+
+// RecordPaymentRejection records a payment that matched an invoice's memo and
+// amount but was rejected (e.g. the sender isn't allowlisted).
+func (m *Metrics) RecordPaymentRejection(network, reason string) {
+	m.paymentRejectionsTotal.WithLabelValues(network, reason).Inc()
+}
+
+// Payment funnel stages, in order. Conversion is registration_completed over
+// invoice_issued; timed_out counts invoices that were never paid.
+const (
+	FunnelInvoiceIssued         = "invoice_issued"
+	FunnelPaymentDetected       = "payment_detected"
+	FunnelRegistrationCompleted = "registration_completed"
+	FunnelTimedOut              = "timed_out"
+)
+
+// RecordPaymentFunnel records a payment-gated registration reaching a funnel
+// stage. Callers must record each stage at most once per registration.
+func (m *Metrics) RecordPaymentFunnel(network, stage string) {
+	m.paymentFunnelEventsTotal.WithLabelValues(network, stage).Inc()
+}
+
+// Payment callback delivery results.
+const (
+	CallbackDelivered = "delivered"
+	CallbackFailed    = "failed"
+)
+
+// RecordPaymentCallback records the final outcome of delivering a
+// registration-completed callback: delivered, or failed after all retries.
+func (m *Metrics) RecordPaymentCallback(network, result string) {
+	m.paymentCallbacksTotal.WithLabelValues(network, result).Inc()
+}
+
+// RecordWebhookDeliveryAttempt records one attempt to deliver an outbound
+// webhook, whether it is later retried or not.
+func (m *Metrics) RecordWebhookDeliveryAttempt(event string, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	m.webhookDeliveryAttempts.WithLabelValues(event, status).Inc()
+}
+
 // Database metric helpers
 
 // RecordDBQuery records a database query with duration.
@@ -298,10 +455,11 @@ func (m *Metrics) RecordDBQuery(operation, table string, duration float64, err e
 
 // HTTP metric helpers
 
-// RecordHTTPRequest records an HTTP request with duration.
-func (m *Metrics) RecordHTTPRequest(handler, method string, statusCode int, duration float64) {
+// RecordHTTPRequest records an HTTP request with duration, with the request ID
+// in ctx as an exemplar.
+func (m *Metrics) RecordHTTPRequest(ctx context.Context, handler, method string, statusCode int, duration float64) {
 	status := statusCodeToString(statusCode)
-	m.httpRequestDuration.WithLabelValues(handler, method, status).Observe(duration)
+	observe(ctx, m.httpRequestDuration.WithLabelValues(handler, method, status), duration)
 	m.httpRequestsTotal.WithLabelValues(handler, method, status).Inc()
 }
 
@@ -323,6 +481,22 @@ func (m *Metrics) RecordNATSPublish(subject, status string, duration float64) {
 	m.natsPublishDuration.WithLabelValues(subject).Observe(duration)
 }
 
+// SetNATSSubscriptionsActive records the number of NATS consumers backing
+// SSE streams.
+func (m *Metrics) SetNATSSubscriptionsActive(count int) {
+	m.natsSubscriptionsActive.Set(float64(count))
+}
+
+// Build metric helpers
+
+// SetBuildInfo records the running build. identity is the Temporal worker
+// identity, so activity executions can be traced back to a replica and
+// version.
+func (m *Metrics) SetBuildInfo(version, commit, date, identity string) {
+	m.buildInfo.Reset()
+	m.buildInfo.WithLabelValues(version, commit, date, identity).Set(1)
+}
+
 // Helper functions
 
 func statusCodeToString(code int) string {
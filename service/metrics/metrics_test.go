@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBuildInfo(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	m.SetBuildInfo("v0.1.0", "abc123", "2026-01-01T00:00:00Z", "pod-1@v0.1.0")
+	m.SetBuildInfo("v0.2.0", "def456", "2026-02-01T00:00:00Z", "pod-1@v0.2.0")
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.buildInfo), "only the latest build is reported")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.buildInfo.WithLabelValues("v0.2.0", "def456", "2026-02-01T00:00:00Z", "pod-1@v0.2.0")))
+}
+
+func TestRecordWebhookDeliveryAttempt(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+
+	m.RecordWebhookDeliveryAttempt("registration.completed", errors.New("callback endpoint returned 502"))
+	m.RecordWebhookDeliveryAttempt("registration.completed", errors.New("callback endpoint returned 502"))
+	m.RecordWebhookDeliveryAttempt("registration.completed", nil)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.webhookDeliveryAttempts.WithLabelValues("registration.completed", "error")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.webhookDeliveryAttempts.WithLabelValues("registration.completed", "success")))
+}
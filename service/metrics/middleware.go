@@ -25,7 +25,7 @@ func HTTPMetricsMiddleware(m *Metrics, handlerName string) func(http.Handler) ht
 			// Record metrics
 			duration := time.Since(start).Seconds()
 			if m != nil {
-				m.RecordHTTPRequest(handlerName, r.Method, wrapped.statusCode, duration)
+				m.RecordHTTPRequest(r.Context(), handlerName, r.Method, wrapped.statusCode, duration)
 			}
 		})
 	}
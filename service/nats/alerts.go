@@ -0,0 +1,43 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WorkflowFailureSubject is the core NATS subject workflow failure alerts are
+// published to. Alerts are fire-and-forget and are not part of the
+// TRANSACTIONS stream.
+const WorkflowFailureSubject = "alerts.workflow_failures"
+
+// WorkflowFailureEvent describes a workflow that failed terminally.
+type WorkflowFailureEvent struct {
+	WorkflowID   string    `json:"workflow_id"`
+	WorkflowType string    `json:"workflow_type"`
+	Step         string    `json:"step"` // which step failed, e.g. "await_payment"
+	Kind         string    `json:"kind"` // "timeout" (expected) or "error" (unexpected)
+	Reason       string    `json:"reason"`
+	Address      string    `json:"address,omitempty"`
+	Network      string    `json:"network,omitempty"`
+	FailedAt     time.Time `json:"failed_at"`
+}
+
+// PublishWorkflowFailure publishes a workflow failure alert on core NATS.
+func (p *JetStreamPublisher) PublishWorkflowFailure(ctx context.Context, event *WorkflowFailureEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow failure event: %w", err)
+	}
+
+	if err := p.nc.Publish(WorkflowFailureSubject, data); err != nil {
+		return fmt.Errorf("failed to publish workflow failure event: %w", err)
+	}
+
+	p.logger.DebugContext(ctx, "published workflow failure alert",
+		"workflow_id", event.WorkflowID,
+		"kind", event.Kind,
+	)
+	return nil
+}
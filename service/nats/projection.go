@@ -0,0 +1,91 @@
+package nats
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EventFields lists the JSON field names of TransactionEvent that consumers may
+// select in a projection.
+var EventFields = []string{
+	"signature",
+	"slot",
+	"wallet_address",
+	"network",
+	"from_address",
+	"amount",
+	"token_type",
+	"memo",
+	"memo_truncated",
+	"fee",
+	"payment_id",
+	"duplicate_logical",
+	"direction",
+	"timestamp",
+	"block_time",
+	"confirmation_status",
+	"detected_at",
+	"detection_lag_seconds",
+	"published_at",
+}
+
+// ParseFields parses a comma-separated field projection (e.g. "signature,amount").
+// An empty spec returns nil, meaning the full event. Unknown field names are an error.
+func ParseFields(spec string) ([]string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	valid := make(map[string]bool, len(EventFields))
+	for _, f := range EventFields {
+		valid[f] = true
+	}
+
+	seen := make(map[string]bool)
+	var fields []string
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !valid[f] {
+			return nil, fmt.Errorf("unknown field %q: valid fields are %s", f, strings.Join(EventFields, ", "))
+		}
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// MarshalFields encodes the event keeping only the given fields. A nil or empty
+// fields slice encodes the full event. Fields omitted from the full encoding
+// (e.g. an empty memo) stay omitted.
+func (e *TransactionEvent) MarshalFields(fields []string) ([]byte, error) {
+	full, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return full, nil
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(full, &all); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if v, ok := all[f]; ok {
+			projected[f] = v
+		}
+	}
+
+	return json.Marshal(projected)
+}
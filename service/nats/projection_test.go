@@ -0,0 +1,63 @@
+package nats
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEventFields_MatchStruct guards against EventFields drifting from the
+// TransactionEvent JSON tags.
+func TestEventFields_MatchStruct(t *testing.T) {
+	typ := reflect.TypeOf(TransactionEvent{})
+	var tags []string
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		tags = append(tags, name)
+	}
+	assert.ElementsMatch(t, tags, EventFields)
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("")
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+
+	fields, err = ParseFields(" signature, amount,signature ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"signature", "amount"}, fields)
+
+	_, err = ParseFields("signature,password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "password"`)
+}
+
+func TestMarshalFields(t *testing.T) {
+	event := &TransactionEvent{
+		Signature:     "sig123",
+		WalletAddress: "wallet123",
+		Network:       "mainnet",
+		Amount:        1000000,
+		BlockTime:     time.Now(),
+	}
+
+	data, err := event.MarshalFields([]string{"signature", "amount", "memo"})
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, map[string]interface{}{
+		"signature": "sig123",
+		"amount":    float64(1000000),
+	}, got, "memo is empty so it stays omitted")
+
+	full, err := event.MarshalFields(nil)
+	require.NoError(t, err)
+	expected, _ := json.Marshal(event)
+	assert.JSONEq(t, string(expected), string(full))
+}
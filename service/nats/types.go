@@ -15,18 +15,29 @@ type TransactionEvent struct {
 
 	// Wallet information
 	WalletAddress string  `json:"wallet_address"`      // Destination/receiver wallet
+	Network       string  `json:"network"`             // "mainnet" or "devnet"
 	FromAddress   *string `json:"from_address,omitempty"` // Source/sender wallet
 
 	// Transaction details
 	Amount    int64  `json:"amount"`
 	TokenType string `json:"token_type"`
 	Memo      string `json:"memo,omitempty"`
+	MemoTruncated bool `json:"memo_truncated"` // memo was cut to the server's MAX_MEMO_LENGTH
+	Fee       int64  `json:"fee"` // network fee in lamports; 0 if unknown
+	PaymentID string `json:"payment_id,omitempty"`
+	DuplicateLogical bool `json:"duplicate_logical"` // payment_id was already seen for this wallet
+	Direction string `json:"direction"` // "in", "out" or "self", relative to wallet_address
 
 	// Timing information
 	Timestamp       time.Time `json:"timestamp"`
 	BlockTime       time.Time `json:"block_time"`
 	ConfirmationStatus string `json:"confirmation_status"`
 
+	// Detection latency: when the server stored the transaction, and how many
+	// seconds after its block time that was (never negative).
+	DetectedAt          time.Time `json:"detected_at"`
+	DetectionLagSeconds float64   `json:"detection_lag_seconds"`
+
 	// Metadata
 	PublishedAt time.Time `json:"published_at"`
 }
@@ -37,11 +48,18 @@ func FromDBTransaction(txn *db.Transaction) *TransactionEvent {
 		Signature:          txn.Signature,
 		Slot:               txn.Slot,
 		WalletAddress:      txn.WalletAddress,
+		Network:            txn.Network,
 		FromAddress:        txn.FromAddress,
 		Amount:             txn.Amount,
+		Fee:                txn.Fee,
+		MemoTruncated:      txn.MemoTruncated,
+		DuplicateLogical:   txn.DuplicateLogical,
+		Direction:          txn.Direction,
 		BlockTime:          txn.BlockTime,
 		Timestamp:          txn.CreatedAt,
 		ConfirmationStatus: txn.ConfirmationStatus,
+		DetectedAt:         txn.CreatedAt,
+		DetectionLagSeconds: detectionLag(txn),
 		PublishedAt:        time.Now().UTC(),
 	}
 
@@ -52,6 +70,16 @@ func FromDBTransaction(txn *db.Transaction) *TransactionEvent {
 	if txn.Memo != nil {
 		event.Memo = *txn.Memo
 	}
+	if txn.PaymentID != nil {
+		event.PaymentID = *txn.PaymentID
+	}
 
 	return event
 }
+
+// detectionLag is how long after its block time txn was stored, in seconds.
+// Clock skew between the validator and the server can put block time after
+// the write; that counts as no lag.
+func detectionLag(txn *db.Transaction) float64 {
+	return max(txn.CreatedAt.Sub(txn.BlockTime).Seconds(), 0)
+}
@@ -0,0 +1,31 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+func TestFromDBTransaction_DetectionLag(t *testing.T) {
+	blockTime := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("stored after block time", func(t *testing.T) {
+		stored := blockTime.Add(2500 * time.Millisecond)
+		event := FromDBTransaction(&db.Transaction{BlockTime: blockTime, CreatedAt: stored})
+
+		assert.Equal(t, stored, event.DetectedAt)
+		assert.InDelta(t, 2.5, event.DetectionLagSeconds, 1e-9)
+		assert.Positive(t, event.DetectionLagSeconds)
+	})
+
+	t.Run("clock skew is clamped to zero", func(t *testing.T) {
+		stored := blockTime.Add(-time.Second)
+		event := FromDBTransaction(&db.Transaction{BlockTime: blockTime, CreatedAt: stored})
+
+		assert.Equal(t, stored, event.DetectedAt)
+		assert.Zero(t, event.DetectionLagSeconds)
+	})
+}
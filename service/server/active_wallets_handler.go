@@ -0,0 +1,83 @@
+package server
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+const (
+	defaultActiveWalletsLimit = 50
+	maxActiveWalletsLimit     = 1000
+)
+
+// activeWalletResponse is a wallet asset with its latest transaction. Wallets
+// are not polled, so activity means a transaction was received, not checked
+// for.
+type activeWalletResponse struct {
+	walletResponse
+	LastTransactionAt        time.Time `json:"last_transaction_at"`
+	LastTransactionSignature string    `json:"last_transaction_signature"`
+}
+
+// validateActiveWalletsQuery parses the limit and offset query parameters.
+func validateActiveWalletsQuery(query url.Values) (limit, offset int32, err error) {
+	limit = defaultActiveWalletsLimit
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxActiveWalletsLimit {
+			return 0, 0, errorf("limit must be between 1 and %d", maxActiveWalletsLimit)
+		}
+		limit = int32(n)
+	}
+	if raw := query.Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > math.MaxInt32 {
+			return 0, 0, errorf("offset must be a non-negative integer")
+		}
+		offset = int32(n)
+	}
+	return limit, offset, nil
+}
+
+// handleListActiveWallets returns a handler that lists active wallet assets
+// by most recent transaction, newest first, for a "recent activity" view.
+// Wallet assets that have never received a transaction are left out.
+// GET /api/v1/admin/active-wallets?limit=&offset=
+func handleListActiveWallets(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := validateActiveWalletsQuery(r.URL.Query())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		activity, err := store.ListWalletsByRecentActivity(r.Context(), limit, offset)
+		if err != nil {
+			logger.Error("failed to list wallets by recent activity", "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]activeWalletResponse, len(activity))
+		for i, a := range activity {
+			resp[i] = activeWalletResponse{
+				walletResponse:           walletToResponse(a.Wallet),
+				LastTransactionAt:        a.LastTransactionAt,
+				LastTransactionSignature: a.LastTransactionSignature,
+			}
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"wallets": resp,
+			"count":   len(resp),
+			"limit":   limit,
+			"offset":  offset,
+		}, http.StatusOK)
+	})
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateActiveWalletsQuery(t *testing.T) {
+	limit, offset, err := validateActiveWalletsQuery(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(defaultActiveWalletsLimit), limit)
+	assert.Zero(t, offset)
+
+	limit, offset, err = validateActiveWalletsQuery(url.Values{"limit": {"10"}, "offset": {"20"}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), limit)
+	assert.Equal(t, int32(20), offset)
+
+	for name, query := range map[string]url.Values{
+		"limit zero":      {"limit": {"0"}},
+		"limit large":     {"limit": {"1001"}},
+		"limit not int":   {"limit": {"ten"}},
+		"negative offset": {"offset": {"-1"}},
+		"offset overflow": {"offset": {"99999999999"}},
+	} {
+		_, _, err := validateActiveWalletsQuery(query)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestActiveWalletResponse_JSON(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	resp := activeWalletResponse{
+		walletResponse: walletToResponse(&db.Wallet{
+			Address:   "wallet1",
+			Network:   "mainnet",
+			AssetType: "sol",
+			Status:    "active",
+			Tags:      []string{},
+		}),
+		LastTransactionAt:        at,
+		LastTransactionSignature: "sig1",
+	}
+
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "wallet1", got["address"], "wallet fields are inlined")
+	assert.Equal(t, "2025-03-01T12:00:00Z", got["last_transaction_at"])
+	assert.Equal(t, "sig1", got["last_transaction_signature"])
+}
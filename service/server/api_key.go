@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// apiKeyExemptPaths are /api/v1 routes that authenticate themselves and so
+// skip the shared API key: Helius can only send its own webhook secret.
+var apiKeyExemptPaths = map[string]bool{
+	"/api/v1/webhooks/helius": true,
+}
+
+// apiKeyStore looks up stored API keys. Satisfied by *db.Store.
+type apiKeyStore interface {
+	GetAPIKey(ctx context.Context, key string) (*db.APIKey, error)
+}
+
+// apiKeyAuth configures apiKeyMiddleware.
+type apiKeyAuth struct {
+	sharedKey   string      // API_KEY; empty accepts no shared key
+	adminToken  string      // accepted in place of a key
+	keys        apiKeyStore // stored keys; nil accepts none
+	publicReads bool        // GET and HEAD requests need no key
+}
+
+type apiKeyNameKey struct{}
+
+// apiKeyName returns the name of the stored API key a request was
+// authenticated with. It is false for the shared key, the admin token and
+// unauthenticated requests.
+func apiKeyName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(apiKeyNameKey{}).(string)
+	return name, ok
+}
+
+// apiKeyMiddleware requires "Authorization: Bearer <key>" on every /api/v1
+// route except apiKeyExemptPaths, answering 401 otherwise. The key is the
+// shared API key or a stored one, whose name is attached to the request
+// context. The admin token is accepted in its place, so admin routes need only
+// one header. With publicReads, GET and HEAD requests need no key. Paths
+// outside /api/v1 (health, metrics, HTML pages) are left open. With neither a
+// shared key nor a key store the check is disabled.
+func apiKeyMiddleware(next http.Handler, auth apiKeyAuth, logger *slog.Logger) http.Handler {
+	if auth.sharedKey == "" && auth.keys == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/v1/") || apiKeyExemptPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if auth.publicReads && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if hasAdminToken(r, auth.sharedKey) || hasAdminToken(r, auth.adminToken) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && key != "" && auth.keys != nil {
+			stored, err := auth.keys.GetAPIKey(r.Context(), key)
+			switch {
+			case err == nil:
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyNameKey{}, stored.Name)))
+				return
+			case !errors.Is(err, pgx.ErrNoRows):
+				logger.Error("failed to look up api key", "path", r.URL.Path, "error", err)
+				writeError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+		logger.Warn("api key auth failed", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+		writeError(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:                 "k3y",
+		AdminAuthToken:         "s3cret",
+		HeliusWebhookAuthToken: "Bearer hook",
+	}
+	handler := New(":0", cfg, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	serve := func(method, path, body, auth string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Invalid input fails validation before any store access, so a 400 shows
+	// the request got past the API key check.
+	read := "/api/v1/wallet-assets/bad!addr?network=mainnet"
+	write := "/api/v1/wallet-assets"
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, read, "", ""), "missing key")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, read, "", "Bearer wrong"), "wrong key")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, read, "", "k3y"), "key without Bearer")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, write, `{}`, ""), "missing key on a mutating route")
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, read, "", "Bearer k3y"))
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, write, `{}`, "Bearer k3y"))
+
+	// Admin routes take the admin token alone; the API key doesn't grant admin.
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/admin/maintenance", "", "Bearer s3cret"))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/admin/maintenance", "", "Bearer k3y"))
+
+	// Health stays open, and the webhook authenticates with its own secret.
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/health", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/v1/webhooks/helius", "not json", ""))
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/api/v1/webhooks/helius", "not json", "Bearer hook"))
+}
+
+func TestAPIKeyMiddleware_DisabledByDefault(t *testing.T) {
+	handler := New(":0", &config.Config{}, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallet-assets/bad!addr?network=mainnet", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// fakeAPIKeyStore serves stored API keys by key.
+type fakeAPIKeyStore struct {
+	keys map[string]string // key -> name
+	err  error
+}
+
+func (s *fakeAPIKeyStore) GetAPIKey(_ context.Context, key string) (*db.APIKey, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	name, ok := s.keys[key]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &db.APIKey{Name: name}, nil
+}
+
+func TestAPIKeyMiddleware_StoredKeys(t *testing.T) {
+	var gotName string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotName, _ = apiKeyName(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	store := &fakeAPIKeyStore{keys: map[string]string{"stored-key": "billing"}}
+	serve := func(auth apiKeyAuth, method, authHeader string) int {
+		gotName = ""
+		req := httptest.NewRequest(method, "/api/v1/wallet-assets", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		rec := httptest.NewRecorder()
+		apiKeyMiddleware(next, auth, webhookTestLogger()).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	auth := apiKeyAuth{sharedKey: "k3y", keys: store}
+	assert.Equal(t, http.StatusNoContent, serve(auth, http.MethodPost, "Bearer stored-key"))
+	assert.Equal(t, "billing", gotName)
+	assert.Equal(t, http.StatusNoContent, serve(auth, http.MethodPost, "Bearer k3y"))
+	assert.Empty(t, gotName, "the shared key has no name")
+	assert.Equal(t, http.StatusUnauthorized, serve(auth, http.MethodPost, "Bearer wrong"))
+	assert.Equal(t, http.StatusUnauthorized, serve(auth, http.MethodGet, ""))
+
+	// Stored keys alone enable the check.
+	assert.Equal(t, http.StatusUnauthorized, serve(apiKeyAuth{keys: store}, http.MethodPost, ""))
+	assert.Equal(t, http.StatusNoContent, serve(apiKeyAuth{keys: store}, http.MethodPost, "Bearer stored-key"))
+
+	// Public reads let GET through but not writes.
+	public := apiKeyAuth{keys: store, publicReads: true}
+	assert.Equal(t, http.StatusNoContent, serve(public, http.MethodGet, ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(public, http.MethodPost, ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(public, http.MethodDelete, ""))
+
+	// A failed lookup is a server error, not a rejected key.
+	failing := apiKeyAuth{keys: &fakeAPIKeyStore{err: errors.New("db down")}}
+	assert.Equal(t, http.StatusInternalServerError, serve(failing, http.MethodPost, "Bearer stored-key"))
+}
@@ -0,0 +1,275 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/metrics"
+)
+
+const (
+	defaultAuditWindow = 7 * 24 * time.Hour
+	maxAuditWindow     = 366 * 24 * time.Hour
+	defaultAuditLimit  = 100
+	maxAuditLimit      = 1000
+
+	// auditWriteAttempts and auditRetryDelay bound how hard an audit entry is
+	// retried before it is given up on and logged instead.
+	auditWriteAttempts = 3
+	auditRetryDelay    = 100 * time.Millisecond
+	auditWriteTimeout  = 5 * time.Second
+)
+
+// Audit actors. There are no user accounts, so the actor is whether the
+// caller presented the admin token, or the name of the stored API key it
+// used, prefixed with auditActorKeyPrefix.
+const (
+	auditActorAdmin     = "admin"
+	auditActorAnonymous = "anonymous"
+	auditActorKeyPrefix = "key:"
+)
+
+// auditRecorder appends audit log entries. Satisfied by *db.Store.
+type auditRecorder interface {
+	CreateAuditLogEntry(ctx context.Context, entry db.AuditLogEntry) (*db.AuditLogEntry, error)
+}
+
+type auditTargetKey struct{}
+
+// auditTarget is the wallet an audited request acted on.
+type auditTarget struct {
+	WalletAddress string
+	Network       string
+}
+
+// setAuditTarget records the wallet an audited request acts on, for handlers
+// whose target is in the request body rather than the path or query. Either
+// value may be empty. It is a no-op outside auditMiddleware.
+func setAuditTarget(r *http.Request, address, network string) {
+	if target, ok := r.Context().Value(auditTargetKey{}).(*auditTarget); ok {
+		target.WalletAddress = address
+		target.Network = network
+	}
+}
+
+// statusRecorder captures the status code a handler writes.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// auditMiddleware appends an audit log entry for every call to a mutating
+// route: the actor, action, target wallet (from the {address} path value and
+// network query parameter unless the handler sets one), and whether it
+// succeeded. The entry is written after the handler, so a failed write never
+// fails the operation; it is retried and, if it still can't be written, logged
+// in full at error level. A nil recorder disables auditing.
+func auditMiddleware(next http.Handler, recorder auditRecorder, action, adminToken string, logger *slog.Logger) http.Handler {
+	if recorder == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := &auditTarget{
+			WalletAddress: r.PathValue("address"),
+			Network:       r.URL.Query().Get("network"),
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), auditTargetKey{}, target)))
+
+		entry := db.AuditLogEntry{
+			Actor:      auditActorAnonymous,
+			Action:     action,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Status:     rec.status,
+			Outcome:    "success",
+			RequestID:  metrics.RequestIDFromContext(r.Context()),
+			RemoteAddr: r.RemoteAddr,
+		}
+		if hasAdminToken(r, adminToken) {
+			entry.Actor = auditActorAdmin
+		} else if name, ok := apiKeyName(r.Context()); ok {
+			entry.Actor = auditActorKeyPrefix + name
+		}
+		if rec.status >= http.StatusBadRequest {
+			entry.Outcome = "failure"
+		}
+		if target.WalletAddress != "" {
+			entry.WalletAddress = &target.WalletAddress
+		}
+		if target.Network != "" {
+			entry.Network = &target.Network
+		}
+
+		writeAuditEntry(context.WithoutCancel(r.Context()), recorder, entry, logger)
+	})
+}
+
+// writeAuditEntry appends entry, retrying transient failures.
+func writeAuditEntry(ctx context.Context, recorder auditRecorder, entry db.AuditLogEntry, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(ctx, auditWriteTimeout)
+	defer cancel()
+
+	var err error
+	for attempt := 1; attempt <= auditWriteAttempts && ctx.Err() == nil; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt-1) * auditRetryDelay)
+		}
+		if _, err = recorder.CreateAuditLogEntry(ctx, entry); err == nil {
+			return
+		}
+	}
+
+	logger.Error("failed to write audit log entry",
+		"actor", entry.Actor,
+		"action", entry.Action,
+		"wallet_address", entry.WalletAddress,
+		"network", entry.Network,
+		"method", entry.Method,
+		"path", entry.Path,
+		"status", entry.Status,
+		"outcome", entry.Outcome,
+		"request_id", entry.RequestID,
+		"remote_addr", entry.RemoteAddr,
+		"error", err,
+	)
+}
+
+// auditQuery is a validated audit log query.
+type auditQuery struct {
+	Actor   string
+	Address string
+	From    time.Time
+	To      time.Time
+	Limit   int32
+}
+
+// validateAuditQuery parses the audit log query parameters. The window
+// defaults to the 7 days before now.
+func validateAuditQuery(query url.Values, now time.Time) (auditQuery, error) {
+	q := auditQuery{Actor: query.Get("actor"), To: now, Limit: defaultAuditLimit}
+
+	if address := query.Get("address"); address != "" {
+		if err := validateAddress(address); err != nil {
+			return q, err
+		}
+		q.Address = address
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return q, errorf("invalid to: must be an RFC 3339 timestamp")
+		}
+		q.To = t
+	}
+	q.From = q.To.Add(-defaultAuditWindow)
+	if from := query.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return q, errorf("invalid from: must be an RFC 3339 timestamp")
+		}
+		q.From = t
+	}
+	if !q.From.Before(q.To) {
+		return q, errorf("from must be before to")
+	}
+	if q.To.Sub(q.From) > maxAuditWindow {
+		return q, errorf("window too large: maximum is 366 days")
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			return q, errorf("limit must be between 1 and %d", maxAuditLimit)
+		}
+		q.Limit = int32(limit)
+	}
+
+	return q, nil
+}
+
+// auditEntryResponse is the JSON response format for an audit log entry.
+type auditEntryResponse struct {
+	ID            int64     `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	Actor         string    `json:"actor"`
+	Action        string    `json:"action"`
+	WalletAddress *string   `json:"wallet_address,omitempty"`
+	Network       *string   `json:"network,omitempty"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	Status        int       `json:"status"`
+	Outcome       string    `json:"outcome"`
+	RequestID     string    `json:"request_id"`
+	RemoteAddr    string    `json:"remote_addr"`
+}
+
+func auditEntryToResponse(e *db.AuditLogEntry) auditEntryResponse {
+	return auditEntryResponse{
+		ID:            e.ID,
+		CreatedAt:     e.CreatedAt,
+		Actor:         e.Actor,
+		Action:        e.Action,
+		WalletAddress: e.WalletAddress,
+		Network:       e.Network,
+		Method:        e.Method,
+		Path:          e.Path,
+		Status:        e.Status,
+		Outcome:       e.Outcome,
+		RequestID:     e.RequestID,
+		RemoteAddr:    e.RemoteAddr,
+	}
+}
+
+// handleListAuditLog returns a handler that lists audit log entries, newest
+// first.
+// GET /api/v1/admin/audit?actor=&address=&from=&to=&limit=
+func handleListAuditLog(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q, err := validateAuditQuery(r.URL.Query(), time.Now().UTC())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		entries, err := store.ListAuditLog(r.Context(), db.ListAuditLogParams{
+			Start:         q.From,
+			End:           q.To,
+			Actor:         q.Actor,
+			WalletAddress: q.Address,
+			Limit:         q.Limit,
+		})
+		if err != nil {
+			logger.Error("failed to list audit log", "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]auditEntryResponse, len(entries))
+		for i, e := range entries {
+			resp[i] = auditEntryToResponse(e)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"from":    q.From,
+			"to":      q.To,
+			"entries": resp,
+			"count":   len(resp),
+		}, http.StatusOK)
+	})
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditRecorder records entries, failing the first failures writes.
+type fakeAuditRecorder struct {
+	entries  []db.AuditLogEntry
+	failures int
+	calls    int
+}
+
+func (f *fakeAuditRecorder) CreateAuditLogEntry(_ context.Context, entry db.AuditLogEntry) (*db.AuditLogEntry, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("db down")
+	}
+	f.entries = append(f.entries, entry)
+	return &entry, nil
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	serve := func(recorder *fakeAuditRecorder, handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		mux := http.NewServeMux()
+		mux.Handle("DELETE /api/v1/wallet-assets/{address}", auditMiddleware(handler, recorder, "wallet.unregister", "s3cret", webhookTestLogger()))
+		mux.Handle("POST /api/v1/wallet-assets", auditMiddleware(handler, recorder, "wallet.register", "s3cret", webhookTestLogger()))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req.WithContext(metrics.WithRequestID(req.Context(), "req-1")))
+		return rec
+	}
+	noContent := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("target from path and query", func(t *testing.T) {
+		recorder := &fakeAuditRecorder{}
+		rec := serve(recorder, noContent, httptest.NewRequest(http.MethodDelete, "/api/v1/wallet-assets/wallet1?network=devnet", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		require.Len(t, recorder.entries, 1)
+		e := recorder.entries[0]
+		assert.Equal(t, auditActorAnonymous, e.Actor)
+		assert.Equal(t, "wallet.unregister", e.Action)
+		require.NotNil(t, e.WalletAddress)
+		assert.Equal(t, "wallet1", *e.WalletAddress)
+		require.NotNil(t, e.Network)
+		assert.Equal(t, "devnet", *e.Network)
+		assert.Equal(t, http.MethodDelete, e.Method)
+		assert.Equal(t, "/api/v1/wallet-assets/wallet1", e.Path)
+		assert.Equal(t, http.StatusNoContent, e.Status)
+		assert.Equal(t, "success", e.Outcome)
+		assert.Equal(t, "req-1", e.RequestID)
+	})
+
+	t.Run("handler sets target and fails", func(t *testing.T) {
+		recorder := &fakeAuditRecorder{}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			setAuditTarget(r, "wallet2", "mainnet")
+			writeError(w, "bad", http.StatusBadRequest)
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		serve(recorder, handler, req)
+
+		require.Len(t, recorder.entries, 1)
+		e := recorder.entries[0]
+		assert.Equal(t, auditActorAdmin, e.Actor)
+		require.NotNil(t, e.WalletAddress)
+		assert.Equal(t, "wallet2", *e.WalletAddress)
+		assert.Equal(t, http.StatusBadRequest, e.Status)
+		assert.Equal(t, "failure", e.Outcome)
+	})
+
+	t.Run("wrong token is anonymous", func(t *testing.T) {
+		recorder := &fakeAuditRecorder{}
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil)
+		req.Header.Set("Authorization", "Bearer nope")
+		serve(recorder, noContent, req)
+
+		require.Len(t, recorder.entries, 1)
+		assert.Equal(t, auditActorAnonymous, recorder.entries[0].Actor)
+		assert.Nil(t, recorder.entries[0].WalletAddress)
+	})
+
+	t.Run("stored api key names the actor", func(t *testing.T) {
+		recorder := &fakeAuditRecorder{}
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil)
+		req = req.WithContext(context.WithValue(req.Context(), apiKeyNameKey{}, "billing"))
+		serve(recorder, noContent, req)
+
+		require.Len(t, recorder.entries, 1)
+		assert.Equal(t, "key:billing", recorder.entries[0].Actor)
+	})
+
+	t.Run("write is retried", func(t *testing.T) {
+		recorder := &fakeAuditRecorder{failures: auditWriteAttempts - 1}
+		rec := serve(recorder, noContent, httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Len(t, recorder.entries, 1)
+	})
+
+	t.Run("failed write does not fail the operation", func(t *testing.T) {
+		recorder := &fakeAuditRecorder{failures: auditWriteAttempts}
+		rec := serve(recorder, noContent, httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, recorder.entries)
+		assert.Equal(t, auditWriteAttempts, recorder.calls)
+	})
+}
+
+func TestValidateAuditQuery(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	q, err := validateAuditQuery(url.Values{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, q.To)
+	assert.Equal(t, now.Add(-defaultAuditWindow), q.From)
+	assert.Equal(t, int32(defaultAuditLimit), q.Limit)
+
+	q, err = validateAuditQuery(url.Values{
+		"actor":   {"admin"},
+		"address": {"DRpbCBMxVnDK7maPM5tGv6MvB3v1sRMC86PZ8okm21hy"},
+		"from":    {"2026-02-01T00:00:00Z"},
+		"to":      {"2026-02-02T00:00:00Z"},
+		"limit":   {"10"},
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", q.Actor)
+	assert.Equal(t, "DRpbCBMxVnDK7maPM5tGv6MvB3v1sRMC86PZ8okm21hy", q.Address)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), q.From)
+	assert.Equal(t, int32(10), q.Limit)
+
+	for name, query := range map[string]url.Values{
+		"bad address":  {"address": {"not-an-address!"}},
+		"bad from":     {"from": {"yesterday"}},
+		"inverted":     {"from": {"2026-02-02T00:00:00Z"}, "to": {"2026-02-01T00:00:00Z"}},
+		"window large": {"from": {"2024-01-01T00:00:00Z"}},
+		"limit zero":   {"limit": {"0"}},
+		"limit large":  {"limit": {"1001"}},
+	} {
+		_, err := validateAuditQuery(query, now)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.5", true},
+		{"GZIP", true},
+		{"br, deflate", false},
+		{"gzip;q=0", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"*, gzip;q=0", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, acceptsGzip(tt.header), "Accept-Encoding: %q", tt.header)
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	large := strings.Repeat(`{"signature":"abc","amount":1000}`, 100)
+	handler := func(body string, status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			io.WriteString(w, body)
+		})
+	}
+	serve := func(h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("compresses large body when gzip accepted", func(t *testing.T) {
+		rec := serve(compressMiddleware(handler(large, http.StatusOK), 1024), "gzip, deflate")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		assert.Less(t, rec.Body.Len(), len(large))
+
+		zr, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(decoded))
+	})
+
+	t.Run("passes through without gzip", func(t *testing.T) {
+		rec := serve(compressMiddleware(handler(large, http.StatusOK), 1024), "")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		assert.Equal(t, large, rec.Body.String())
+	})
+
+	t.Run("refused with q=0", func(t *testing.T) {
+		rec := serve(compressMiddleware(handler(large, http.StatusOK), 1024), "gzip;q=0")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, rec.Body.String())
+	})
+
+	t.Run("small body sent uncompressed", func(t *testing.T) {
+		rec := serve(compressMiddleware(handler(`{"error":"not found"}`, http.StatusNotFound), 1024), "gzip")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"error":"not found"}`, rec.Body.String())
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		rec := serve(compressMiddleware(handler("", http.StatusNoContent), 0), "gzip")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Zero(t, rec.Body.Len())
+	})
+}
+
+func TestCompressMiddleware_Stream(t *testing.T) {
+	events := make(chan string)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		for event := range events {
+			io.WriteString(w, event)
+			w.(http.Flusher).Flush()
+		}
+	})
+	srv := httptest.NewServer(compressMiddleware(handler, 0))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	// Setting Accept-Encoding ourselves stops the transport from decoding
+	// transparently, so we can check the header and read the raw stream.
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+	zr, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+
+	// Each flushed event must be readable before the stream ends.
+	for _, event := range []string{"event: connected\ndata: {}\n\n", "event: transaction\ndata: {\"signature\":\"abc\"}\n\n"} {
+		events <- event
+		got := make([]byte, len(event))
+		done := make(chan error, 1)
+		go func() {
+			_, err := io.ReadFull(zr, got)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+			assert.Equal(t, event, string(got))
+		case <-time.After(2 * time.Second):
+			t.Fatal("flushed event not delivered")
+		}
+	}
+	close(events)
+}
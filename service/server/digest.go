@@ -0,0 +1,298 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/temporal"
+)
+
+const (
+	// digestEvent identifies the digest payload type.
+	digestEvent = "transactions.digest"
+
+	// digestBatchSize caps the subscriptions handled per check; the rest are
+	// picked up on the next one, most overdue first.
+	digestBatchSize = 100
+
+	// maxDigestTransactions caps the transactions listed in one digest. The
+	// count and summary always cover the whole window.
+	maxDigestTransactions = 1000
+
+	// digestSettleDelay holds a window open a little past its end, so a
+	// webhook delivery that lags its block time still lands in the right
+	// digest.
+	digestSettleDelay = time.Minute
+)
+
+// DigestStore defines the database operations needed by the Digester.
+type DigestStore interface {
+	ListDueDigestSubscriptions(ctx context.Context, now time.Time, limit int32) ([]*db.DigestSubscription, error)
+	GetWalletFilterByID(ctx context.Context, id int64) (*db.WalletFilter, error)
+	ListTransactionsByWalletAndTimeRange(ctx context.Context, params db.ListTransactionsByWalletAndTimeRangeParams) ([]*db.Transaction, error)
+	MarkDigestDelivered(ctx context.Context, id int64, windowEnd time.Time) error
+}
+
+// DigestNotifier delivers a digest to its subscriber. WebhookDigestNotifier
+// POSTs it to the subscription URL; other channels (e.g. email) can be
+// plugged in by implementing this interface.
+type DigestNotifier interface {
+	NotifyDigest(ctx context.Context, sub *db.DigestSubscription, digest *DigestPayload) error
+}
+
+// compile-time assertion that the store satisfies the interface.
+var _ DigestStore = (*db.Store)(nil)
+
+// DigestPayload is one digest: a wallet's transactions over
+// [WindowStart, WindowEnd), ordered by block time.
+type DigestPayload struct {
+	Event          string                `json:"event"` // always "transactions.digest"
+	SubscriptionID int64                 `json:"subscription_id"`
+	Address        string                `json:"address"`
+	Network        string                `json:"network"`
+	WindowStart    time.Time             `json:"window_start"`
+	WindowEnd      time.Time             `json:"window_end"`
+	Count          int                   `json:"count"`
+	Summary        DigestSummary         `json:"summary"`
+	Transactions   []transactionResponse `json:"transactions"`
+	Truncated      bool                  `json:"truncated"` // more than maxDigestTransactions; Count and Summary are still complete
+}
+
+// DigestSummary totals a digest's transactions.
+type DigestSummary struct {
+	Assets         []DigestAssetTotal `json:"assets"` // one per asset, SOL first, then by mint
+	FirstBlockTime time.Time          `json:"first_block_time"`
+	LastBlockTime  time.Time          `json:"last_block_time"`
+}
+
+// DigestAssetTotal is the number and summed amount of a digest's
+// transactions in one asset. Amount is in the asset's base units.
+type DigestAssetTotal struct {
+	TokenMint string `json:"token_mint,omitempty"` // empty for SOL
+	Count     int    `json:"count"`
+	Amount    int64  `json:"amount"`
+}
+
+// Digester periodically delivers digests for subscriptions whose interval
+// has elapsed.
+type Digester struct {
+	store    DigestStore
+	notifier DigestNotifier
+	interval time.Duration
+	now      func() time.Time
+	logger   *slog.Logger
+}
+
+// NewDigester creates a Digester that checks for due digests every interval.
+func NewDigester(store DigestStore, notifier DigestNotifier, interval time.Duration, logger *slog.Logger) *Digester {
+	return &Digester{
+		store:    store,
+		notifier: notifier,
+		interval: interval,
+		now:      time.Now,
+		logger:   logger,
+	}
+}
+
+// Run delivers due digests every interval until ctx is cancelled.
+func (d *Digester) Run(ctx context.Context) {
+	d.logger.Info("transaction digests started", "interval", d.interval)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.deliverDue(ctx); err != nil {
+				d.logger.Error("digest check failed", "error", err)
+			}
+		}
+	}
+}
+
+// deliverDue delivers one batch of due digests. A failed delivery leaves the
+// subscription's window open, so the next check retries it with any
+// transactions that arrived meanwhile.
+func (d *Digester) deliverDue(ctx context.Context) error {
+	cutoff := d.now().Add(-digestSettleDelay)
+	subs, err := d.store.ListDueDigestSubscriptions(ctx, cutoff, digestBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if err := d.deliver(ctx, sub, cutoff); err != nil {
+			d.logger.Warn("digest delivery failed",
+				"subscription_id", sub.ID,
+				"address", sub.Address,
+				"network", sub.Network,
+				"error", err,
+			)
+		}
+	}
+	return nil
+}
+
+// deliver sends sub's digest for every whole interval that ended by cutoff.
+// A subscription with a filter only counts the transactions it matches.
+// Empty windows are skipped without a delivery.
+func (d *Digester) deliver(ctx context.Context, sub *db.DigestSubscription, cutoff time.Time) error {
+	periods := cutoff.Sub(sub.LastDeliveredAt) / sub.Interval
+	if periods < 1 {
+		return nil
+	}
+	windowStart := sub.LastDeliveredAt
+	windowEnd := windowStart.Add(periods * sub.Interval)
+
+	txns, err := d.store.ListTransactionsByWalletAndTimeRange(ctx, db.ListTransactionsByWalletAndTimeRangeParams{
+		WalletAddress: sub.Address,
+		Network:       sub.Network,
+		StartTime:     windowStart,
+		EndTime:       windowEnd,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list transactions: %w", err)
+	}
+	if sub.FilterID != nil {
+		stored, err := d.store.GetWalletFilterByID(ctx, *sub.FilterID)
+		if err != nil {
+			return fmt.Errorf("failed to get wallet filter: %w", err)
+		}
+		filter, err := compileWalletFilter(stored)
+		if err != nil {
+			return fmt.Errorf("failed to compile wallet filter %q: %w", stored.Name, err)
+		}
+		txns = slices.DeleteFunc(txns, func(t *db.Transaction) bool {
+			return !filter.matchesTransaction(t)
+		})
+	}
+
+	digest := buildDigest(sub, windowStart, windowEnd, txns)
+	if digest.Count > 0 {
+		if err := d.notifier.NotifyDigest(ctx, sub, digest); err != nil {
+			return err
+		}
+		d.logger.Info("digest delivered",
+			"subscription_id", sub.ID,
+			"address", sub.Address,
+			"network", sub.Network,
+			"count", digest.Count,
+		)
+	}
+
+	return d.store.MarkDigestDelivered(ctx, sub.ID, windowEnd)
+}
+
+// buildDigest assembles the digest for [windowStart, windowEnd) from txns,
+// which the store returns newest first with both ends inclusive.
+func buildDigest(sub *db.DigestSubscription, windowStart, windowEnd time.Time, txns []*db.Transaction) *DigestPayload {
+	digest := &DigestPayload{
+		Event:          digestEvent,
+		SubscriptionID: sub.ID,
+		Address:        sub.Address,
+		Network:        sub.Network,
+		WindowStart:    windowStart,
+		WindowEnd:      windowEnd,
+		Summary:        DigestSummary{Assets: []DigestAssetTotal{}},
+		Transactions:   []transactionResponse{},
+	}
+
+	totals := make(map[string]*DigestAssetTotal)
+	for i := len(txns) - 1; i >= 0; i-- {
+		t := txns[i]
+		if !t.BlockTime.Before(windowEnd) {
+			continue // belongs to the next window
+		}
+
+		digest.Count++
+		if digest.Count == 1 {
+			digest.Summary.FirstBlockTime = t.BlockTime
+		}
+		digest.Summary.LastBlockTime = t.BlockTime
+
+		var mint string
+		if t.TokenMint != nil {
+			mint = *t.TokenMint
+		}
+		total, ok := totals[mint]
+		if !ok {
+			total = &DigestAssetTotal{TokenMint: mint}
+			totals[mint] = total
+		}
+		total.Count++
+		total.Amount += t.Amount
+
+		if len(digest.Transactions) < maxDigestTransactions {
+			digest.Transactions = append(digest.Transactions, transactionToResponse(t))
+		} else {
+			digest.Truncated = true
+		}
+	}
+
+	for _, total := range totals {
+		digest.Summary.Assets = append(digest.Summary.Assets, *total)
+	}
+	slices.SortFunc(digest.Summary.Assets, func(a, b DigestAssetTotal) int {
+		return strings.Compare(a.TokenMint, b.TokenMint)
+	})
+
+	return digest
+}
+
+// WebhookDigestNotifier POSTs digests to the subscription URL, signed like
+// registration-completed callbacks: X-Forohtoo-Signature is "sha256="
+// followed by the hex HMAC-SHA256 of "<X-Forohtoo-Timestamp>.<body>".
+type WebhookDigestNotifier struct {
+	secret string
+	client *http.Client
+}
+
+// NewWebhookDigestNotifier creates a notifier that signs digests with secret.
+func NewWebhookDigestNotifier(secret string) *WebhookDigestNotifier {
+	return &WebhookDigestNotifier{
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NotifyDigest POSTs digest to sub.URL. Any non-2xx response is an error.
+func (n *WebhookDigestNotifier) NotifyDigest(ctx context.Context, sub *db.DigestSubscription, digest *DigestPayload) error {
+	body, err := json.Marshal(digest)
+	if err != nil {
+		return fmt.Errorf("failed to encode digest: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid digest URL: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(temporal.CallbackTimestampHeader, timestamp)
+	req.Header.Set(temporal.CallbackSignatureHeader, temporal.SignCallback(n.secret, timestamp, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("digest request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("digest endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/temporal"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDigestStore serves due subscriptions and a wallet's transactions, and
+// records delivered windows.
+type fakeDigestStore struct {
+	subs      []*db.DigestSubscription
+	txns      []*db.Transaction // newest first, like the store
+	filters   map[int64]*db.WalletFilter
+	delivered map[int64]time.Time
+}
+
+func (s *fakeDigestStore) ListDueDigestSubscriptions(_ context.Context, now time.Time, _ int32) ([]*db.DigestSubscription, error) {
+	var out []*db.DigestSubscription
+	for _, sub := range s.subs {
+		if !sub.LastDeliveredAt.Add(sub.Interval).After(now) {
+			out = append(out, sub)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeDigestStore) ListTransactionsByWalletAndTimeRange(_ context.Context, params db.ListTransactionsByWalletAndTimeRangeParams) ([]*db.Transaction, error) {
+	var out []*db.Transaction
+	for _, t := range s.txns {
+		if !t.BlockTime.Before(params.StartTime) && !t.BlockTime.After(params.EndTime) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeDigestStore) GetWalletFilterByID(_ context.Context, id int64) (*db.WalletFilter, error) {
+	if f, ok := s.filters[id]; ok {
+		return f, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *fakeDigestStore) MarkDigestDelivered(_ context.Context, id int64, windowEnd time.Time) error {
+	s.delivered[id] = windowEnd
+	return nil
+}
+
+type fakeDigestNotifier struct {
+	digests []*DigestPayload
+	err     error
+}
+
+func (n *fakeDigestNotifier) NotifyDigest(_ context.Context, _ *db.DigestSubscription, digest *DigestPayload) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.digests = append(n.digests, digest)
+	return nil
+}
+
+func digestTxn(sig string, blockTime time.Time, amount int64, mint *string) *db.Transaction {
+	return &db.Transaction{
+		Signature:     sig,
+		WalletAddress: "wallet1",
+		Network:       "mainnet",
+		BlockTime:     blockTime,
+		Amount:        amount,
+		TokenMint:     mint,
+	}
+}
+
+func newTestDigester(store DigestStore, notifier DigestNotifier, now time.Time) *Digester {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	d := NewDigester(store, notifier, time.Minute, logger)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func TestDigester_DeliverDue(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	store := &fakeDigestStore{
+		subs: []*db.DigestSubscription{
+			{ID: 1, Address: "wallet1", Network: "mainnet", Interval: time.Hour, LastDeliveredAt: start},
+		},
+		txns: []*db.Transaction{
+			digestTxn("sig-next", start.Add(time.Hour), 1, nil), // at the window end: next window
+			digestTxn("sig3", start.Add(50*time.Minute), 2000000, &usdc),
+			digestTxn("sig2", start.Add(20*time.Minute), 300, nil),
+			digestTxn("sig1", start.Add(10*time.Minute), 200, nil),
+		},
+		delivered: map[int64]time.Time{},
+	}
+	notifier := &fakeDigestNotifier{}
+
+	// Past the window end plus the settle delay.
+	d := newTestDigester(store, notifier, start.Add(time.Hour+digestSettleDelay+time.Second))
+	require.NoError(t, d.deliverDue(context.Background()))
+
+	require.Len(t, notifier.digests, 1)
+	digest := notifier.digests[0]
+	assert.Equal(t, "transactions.digest", digest.Event)
+	assert.Equal(t, start, digest.WindowStart)
+	assert.Equal(t, start.Add(time.Hour), digest.WindowEnd)
+	assert.Equal(t, 3, digest.Count)
+	assert.False(t, digest.Truncated)
+
+	require.Len(t, digest.Transactions, 3)
+	assert.Equal(t, "sig1", digest.Transactions[0].Signature, "oldest first")
+	assert.Equal(t, "sig3", digest.Transactions[2].Signature)
+
+	assert.Equal(t, []DigestAssetTotal{
+		{Count: 2, Amount: 500},
+		{TokenMint: usdc, Count: 1, Amount: 2000000},
+	}, digest.Summary.Assets)
+	assert.Equal(t, start.Add(10*time.Minute), digest.Summary.FirstBlockTime)
+	assert.Equal(t, start.Add(50*time.Minute), digest.Summary.LastBlockTime)
+
+	assert.Equal(t, start.Add(time.Hour), store.delivered[1])
+}
+
+func TestDigester_DeliverDue_SettleDelay(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeDigestStore{
+		subs: []*db.DigestSubscription{
+			{ID: 1, Address: "wallet1", Network: "mainnet", Interval: time.Hour, LastDeliveredAt: start},
+		},
+		txns:      []*db.Transaction{digestTxn("sig1", start.Add(time.Minute), 1, nil)},
+		delivered: map[int64]time.Time{},
+	}
+	notifier := &fakeDigestNotifier{}
+
+	// The window has ended, but a late webhook delivery could still land in it.
+	d := newTestDigester(store, notifier, start.Add(time.Hour+time.Second))
+	require.NoError(t, d.deliverDue(context.Background()))
+
+	assert.Empty(t, notifier.digests)
+	assert.Empty(t, store.delivered)
+}
+
+func TestDigester_DeliverDue_CatchesUpMissedWindows(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeDigestStore{
+		subs: []*db.DigestSubscription{
+			{ID: 1, Address: "wallet1", Network: "mainnet", Interval: time.Hour, LastDeliveredAt: start},
+		},
+		txns: []*db.Transaction{
+			digestTxn("sig2", start.Add(2*time.Hour+10*time.Minute), 1, nil),
+			digestTxn("sig1", start.Add(10*time.Minute), 1, nil),
+		},
+		delivered: map[int64]time.Time{},
+	}
+	notifier := &fakeDigestNotifier{}
+
+	// Three whole windows have ended since the last delivery.
+	d := newTestDigester(store, notifier, start.Add(3*time.Hour+30*time.Minute))
+	require.NoError(t, d.deliverDue(context.Background()))
+
+	require.Len(t, notifier.digests, 1)
+	assert.Equal(t, 2, notifier.digests[0].Count)
+	assert.Equal(t, start.Add(3*time.Hour), store.delivered[1])
+}
+
+func TestDigester_DeliverDue_EmptyWindow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeDigestStore{
+		subs: []*db.DigestSubscription{
+			{ID: 1, Address: "wallet1", Network: "mainnet", Interval: time.Hour, LastDeliveredAt: start},
+		},
+		delivered: map[int64]time.Time{},
+	}
+	notifier := &fakeDigestNotifier{}
+
+	d := newTestDigester(store, notifier, start.Add(2*time.Hour))
+	require.NoError(t, d.deliverDue(context.Background()))
+
+	assert.Empty(t, notifier.digests, "nothing to deliver")
+	assert.Equal(t, start.Add(time.Hour), store.delivered[1], "the window still advances")
+}
+
+func TestDigester_DeliverDue_NotifierError(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeDigestStore{
+		subs: []*db.DigestSubscription{
+			{ID: 1, Address: "wallet1", Network: "mainnet", Interval: time.Hour, LastDeliveredAt: start},
+		},
+		txns:      []*db.Transaction{digestTxn("sig1", start.Add(time.Minute), 1, nil)},
+		delivered: map[int64]time.Time{},
+	}
+	notifier := &fakeDigestNotifier{err: errors.New("endpoint down")}
+
+	d := newTestDigester(store, notifier, start.Add(2*time.Hour))
+	require.NoError(t, d.deliverDue(context.Background()))
+
+	assert.Empty(t, store.delivered, "the window stays open for a retry")
+}
+
+func TestDigester_DeliverDue_Filter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	filterID := int64(9)
+	minAmount := int64(250)
+	assetType := "sol"
+	store := &fakeDigestStore{
+		subs: []*db.DigestSubscription{
+			{ID: 1, Address: "wallet1", Network: "mainnet", Interval: time.Hour, LastDeliveredAt: start, FilterID: &filterID},
+		},
+		txns: []*db.Transaction{
+			digestTxn("sig3", start.Add(30*time.Minute), 2000000, &usdc),
+			digestTxn("sig2", start.Add(20*time.Minute), 300, nil),
+			digestTxn("sig1", start.Add(10*time.Minute), 200, nil),
+		},
+		filters: map[int64]*db.WalletFilter{
+			filterID: {ID: filterID, Name: "big-sol", AssetType: &assetType, MinAmount: &minAmount, MemoMatch: "exact"},
+		},
+		delivered: map[int64]time.Time{},
+	}
+	notifier := &fakeDigestNotifier{}
+
+	d := newTestDigester(store, notifier, start.Add(2*time.Hour))
+	require.NoError(t, d.deliverDue(context.Background()))
+
+	require.Len(t, notifier.digests, 1)
+	digest := notifier.digests[0]
+	assert.Equal(t, 1, digest.Count)
+	require.Len(t, digest.Transactions, 1)
+	assert.Equal(t, "sig2", digest.Transactions[0].Signature)
+	assert.Equal(t, start.Add(time.Hour), store.delivered[1])
+}
+
+func TestBuildDigest_Truncated(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	txns := make([]*db.Transaction, maxDigestTransactions+5)
+	for i := range txns {
+		txns[i] = digestTxn("sig", start.Add(time.Duration(len(txns)-i)*time.Millisecond), 1, nil)
+	}
+
+	digest := buildDigest(&db.DigestSubscription{ID: 1}, start, start.Add(time.Hour), txns)
+	assert.Equal(t, maxDigestTransactions+5, digest.Count)
+	assert.Len(t, digest.Transactions, maxDigestTransactions)
+	assert.True(t, digest.Truncated)
+	assert.Equal(t, int64(maxDigestTransactions+5), digest.Summary.Assets[0].Amount)
+}
+
+func TestWebhookDigestNotifier(t *testing.T) {
+	var gotBody []byte
+	var gotTimestamp, gotSignature string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotTimestamp = r.Header.Get(temporal.CallbackTimestampHeader)
+		gotSignature = r.Header.Get(temporal.CallbackSignatureHeader)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookDigestNotifier("s3cret")
+	sub := &db.DigestSubscription{ID: 7, URL: srv.URL}
+	digest := &DigestPayload{Event: digestEvent, SubscriptionID: 7, Count: 1}
+
+	require.NoError(t, n.NotifyDigest(context.Background(), sub, digest))
+
+	var decoded DigestPayload
+	require.NoError(t, json.Unmarshal(gotBody, &decoded))
+	assert.Equal(t, int64(7), decoded.SubscriptionID)
+	assert.Equal(t, temporal.SignCallback("s3cret", gotTimestamp, gotBody), gotSignature)
+
+	status = http.StatusInternalServerError
+	err := n.NotifyDigest(context.Background(), sub, digest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "500")
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// Digest intervals outside these bounds are rejected: shorter ones are
+// better served by the SSE stream, and longer ones would batch an unbounded
+// number of transactions.
+const (
+	minDigestInterval = 5 * time.Minute
+	maxDigestInterval = 7 * 24 * time.Hour
+)
+
+// digestSubscriptionResponse is the JSON response format for a digest
+// subscription.
+type digestSubscriptionResponse struct {
+	ID              int64     `json:"id"`
+	Address         string    `json:"address"`
+	Network         string    `json:"network"`
+	URL             string    `json:"url"`
+	Interval        string    `json:"interval"`
+	LastDeliveredAt time.Time `json:"last_delivered_at"` // start of the next digest's window
+	CreatedAt       time.Time `json:"created_at"`
+	FilterID        *int64    `json:"filter_id,omitempty"` // wallet filter applied to each digest
+}
+
+func digestSubscriptionToResponse(sub *db.DigestSubscription) digestSubscriptionResponse {
+	return digestSubscriptionResponse{
+		ID:              sub.ID,
+		Address:         sub.Address,
+		Network:         sub.Network,
+		URL:             sub.URL,
+		Interval:        sub.Interval.String(),
+		LastDeliveredAt: sub.LastDeliveredAt,
+		CreatedAt:       sub.CreatedAt,
+		FilterID:        sub.FilterID,
+	}
+}
+
+// handleCreateDigestSubscription returns a handler that subscribes a URL to a
+// periodic digest of a registered wallet's transactions. The optional filter
+// names one of the wallet's filters; only matching transactions are included.
+// POST /api/v1/digests
+func handleCreateDigestSubscription(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+		var req struct {
+			Address  string `json:"address"`
+			Network  string `json:"network"`
+			URL      string `json:"url"`
+			Interval string `json:"interval"`
+			Filter   string `json:"filter,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Debug("failed to decode digest subscription request", "error", err)
+			writeError(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		setAuditTarget(r, req.Address, req.Network)
+
+		if err := validateAddress(req.Address); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateNetwork(req.Network); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateDigestURL(req.URL); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		interval, err := parseDigestInterval(req.Interval)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		assets, err := store.ListWalletAssets(r.Context(), req.Address, req.Network, false)
+		if err != nil {
+			logger.Error("failed to get wallet assets", "address", req.Address, "network", req.Network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if len(assets) == 0 {
+			writeError(w, "wallet not found", http.StatusNotFound)
+			return
+		}
+
+		var filterID *int64
+		if req.Filter != "" {
+			if err := validateFilterName(req.Filter); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			filter, err := store.GetWalletFilter(r.Context(), req.Address, req.Network, req.Filter)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					writeError(w, "filter not found", http.StatusNotFound)
+					return
+				}
+				logger.Error("failed to get wallet filter", "address", req.Address, "network", req.Network, "name", req.Filter, "error", err)
+				writeError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			filterID = &filter.ID
+		}
+
+		sub, err := store.CreateDigestSubscription(r.Context(), db.CreateDigestSubscriptionParams{
+			Address:  req.Address,
+			Network:  req.Network,
+			URL:      req.URL,
+			Interval: interval,
+			FilterID: filterID,
+		})
+		if err != nil {
+			logger.Error("failed to create digest subscription", "address", req.Address, "network", req.Network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("digest subscription created",
+			"id", sub.ID,
+			"address", sub.Address,
+			"network", sub.Network,
+			"interval", sub.Interval,
+		)
+
+		writeJSON(w, digestSubscriptionToResponse(sub), http.StatusCreated)
+	})
+}
+
+// handleListDigestSubscriptions returns a handler that lists digest
+// subscriptions.
+// GET /api/v1/digests?address={address}&network={network}
+// Both parameters are optional filters.
+func handleListDigestSubscriptions(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address := r.URL.Query().Get("address")
+		network := r.URL.Query().Get("network")
+		if address != "" {
+			if err := validateAddress(address); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if network != "" {
+			if err := validateNetwork(network); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		subs, err := store.ListDigestSubscriptions(r.Context(), address, network)
+		if err != nil {
+			logger.Error("failed to list digest subscriptions", "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]digestSubscriptionResponse, len(subs))
+		for i, sub := range subs {
+			resp[i] = digestSubscriptionToResponse(sub)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"digests": resp,
+		}, http.StatusOK)
+	})
+}
+
+// handleDeleteDigestSubscription returns a handler that cancels a digest
+// subscription.
+// DELETE /api/v1/digests/{id}
+func handleDeleteDigestSubscription(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			writeError(w, "invalid digest subscription id", http.StatusBadRequest)
+			return
+		}
+
+		deleted, err := store.DeleteDigestSubscription(r.Context(), id)
+		if err != nil {
+			logger.Error("failed to delete digest subscription", "id", id, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			writeError(w, "digest subscription not found", http.StatusNotFound)
+			return
+		}
+
+		logger.Info("digest subscription deleted", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// validateDigestURL validates a digest delivery URL.
+func validateDigestURL(raw string) error {
+	if raw == "" {
+		return errorf("url is required")
+	}
+	if len(raw) > maxCallbackURLLen {
+		return errorf("url too long: maximum length is %d", maxCallbackURLLen)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errorf("url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// parseDigestInterval parses a digest interval such as "1h", in whole
+// seconds between minDigestInterval and maxDigestInterval.
+func parseDigestInterval(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, errorf("interval is required")
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, errorf("invalid interval: %v", err)
+	}
+	if interval%time.Second != 0 {
+		return 0, errorf("interval must be a whole number of seconds")
+	}
+	if interval < minDigestInterval || interval > maxDigestInterval {
+		return 0, errorf("interval must be between %s and %s", minDigestInterval, maxDigestInterval)
+	}
+	return interval, nil
+}
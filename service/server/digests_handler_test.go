@@ -0,0 +1,39 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDigestInterval(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr string
+	}{
+		{"1h", time.Hour, ""},
+		{"5m", 5 * time.Minute, ""},
+		{"168h", 7 * 24 * time.Hour, ""},
+		{"", 0, "interval is required"},
+		{"daily", 0, "invalid interval"},
+		{"1m", 0, "between"},
+		{"169h", 0, "between"},
+		{"10m500ms", 0, "whole number of seconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseDigestInterval(tt.raw)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	forohtoo "github.com/brojonat/forohtoo/client"
+	"github.com/brojonat/forohtoo/service/db"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
+	"github.com/itchyny/gojq"
+)
+
+// transactionFilter is a compiled wallet filter. The criteria follow the
+// client library's matchers: asset and minimum amount, memo by
+// forohtoo.MemoMatches, and jq by forohtoo.MemoMatchesJQ. A nil
+// *transactionFilter matches every transaction.
+type transactionFilter struct {
+	name      string
+	assetType string // "", "sol" or "spl-token"
+	tokenMint string
+	minAmount *int64
+	memo      *string
+	memoMatch forohtoo.MemoMatchMode
+	jq        []*gojq.Code
+}
+
+// compileWalletFilter compiles a stored filter's jq expressions. They were
+// validated on save, so an error here means the row was edited by hand.
+func compileWalletFilter(f *db.WalletFilter) (*transactionFilter, error) {
+	jq, err := forohtoo.CompileJQ(f.JQ)
+	if err != nil {
+		return nil, err
+	}
+	compiled := &transactionFilter{
+		name:      f.Name,
+		minAmount: f.MinAmount,
+		memo:      f.Memo,
+		memoMatch: forohtoo.MemoMatchMode(f.MemoMatch),
+		jq:        jq,
+	}
+	if f.AssetType != nil {
+		compiled.assetType = *f.AssetType
+	}
+	if f.TokenMint != nil {
+		compiled.tokenMint = *f.TokenMint
+	}
+	return compiled, nil
+}
+
+// matches reports whether a transaction meets every criterion. tokenMint is
+// nil for SOL.
+func (f *transactionFilter) matches(tokenMint *string, amount int64, memo *string, memoTruncated bool) bool {
+	if f == nil {
+		return true
+	}
+	switch f.assetType {
+	case "sol":
+		if tokenMint != nil {
+			return false
+		}
+	case "spl-token":
+		if tokenMint == nil || *tokenMint != f.tokenMint {
+			return false
+		}
+	}
+	if f.minAmount != nil && amount < *f.minAmount {
+		return false
+	}
+	if f.memo != nil && !forohtoo.MemoMatches(memo, *f.memo, f.memoMatch) {
+		return false
+	}
+	if len(f.jq) > 0 && !forohtoo.MemoMatchesJQ(memo, memoTruncated, f.jq) {
+		return false
+	}
+	return true
+}
+
+// matchesTransaction reports whether a stored transaction passes the filter.
+func (f *transactionFilter) matchesTransaction(t *db.Transaction) bool {
+	return f.matches(t.TokenMint, t.Amount, t.Memo, t.MemoTruncated)
+}
+
+// matchesEvent reports whether a published transaction event passes the
+// filter. Events carry SOL and "no memo" as empty strings.
+func (f *transactionFilter) matchesEvent(e *natspkg.TransactionEvent) bool {
+	var tokenMint, memo *string
+	if e.TokenType != "" {
+		tokenMint = &e.TokenType
+	}
+	if e.Memo != "" {
+		memo = &e.Memo
+	}
+	return f.matches(tokenMint, e.Amount, memo, e.MemoTruncated)
+}
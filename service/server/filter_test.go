@@ -0,0 +1,112 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/brojonat/forohtoo/service/db"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionFilter_Matches(t *testing.T) {
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	other := "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"
+	sol := "sol"
+	splToken := "spl-token"
+	minAmount := int64(100)
+	memo := "order-"
+	memoPrefix := "order-42"
+	jsonMemo := `{"order":42}`
+
+	tests := []struct {
+		name      string
+		filter    db.WalletFilter
+		tokenMint *string
+		amount    int64
+		memo      *string
+		truncated bool
+		want      bool
+	}{
+		{"sol matches sol", db.WalletFilter{AssetType: &sol}, nil, 1, nil, false, true},
+		{"sol rejects token", db.WalletFilter{AssetType: &sol}, &usdc, 1, nil, false, false},
+		{"token matches mint", db.WalletFilter{AssetType: &splToken, TokenMint: &usdc}, &usdc, 1, nil, false, true},
+		{"token rejects other mint", db.WalletFilter{AssetType: &splToken, TokenMint: &usdc}, &other, 1, nil, false, false},
+		{"token rejects sol", db.WalletFilter{AssetType: &splToken, TokenMint: &usdc}, nil, 1, nil, false, false},
+		{"min amount met", db.WalletFilter{MinAmount: &minAmount}, nil, 100, nil, false, true},
+		{"min amount not met", db.WalletFilter{MinAmount: &minAmount}, nil, 99, nil, false, false},
+		{"memo exact", db.WalletFilter{Memo: &memo, MemoMatch: "exact"}, nil, 1, &memoPrefix, false, false},
+		{"memo prefix", db.WalletFilter{Memo: &memo, MemoMatch: "prefix"}, nil, 1, &memoPrefix, false, true},
+		{"memo missing", db.WalletFilter{Memo: &memo, MemoMatch: "prefix"}, nil, 1, nil, false, false},
+		{"jq truthy", db.WalletFilter{JQ: []string{".order == 42"}}, nil, 1, &jsonMemo, false, true},
+		{"jq falsy", db.WalletFilter{JQ: []string{".order == 7"}}, nil, 1, &jsonMemo, false, false},
+		{"jq truncated memo", db.WalletFilter{JQ: []string{".order == 42"}}, nil, 1, &jsonMemo, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := compileWalletFilter(&tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, f.matches(tt.tokenMint, tt.amount, tt.memo, tt.truncated))
+		})
+	}
+}
+
+func TestTransactionFilter_Nil(t *testing.T) {
+	var f *transactionFilter
+	assert.True(t, f.matchesTransaction(&db.Transaction{Amount: 1}))
+	assert.True(t, f.matchesEvent(&natspkg.TransactionEvent{Amount: 1}))
+}
+
+func TestTransactionFilter_MatchesEvent(t *testing.T) {
+	sol := "sol"
+	f, err := compileWalletFilter(&db.WalletFilter{AssetType: &sol, MemoMatch: "exact"})
+	require.NoError(t, err)
+
+	assert.True(t, f.matchesEvent(&natspkg.TransactionEvent{Amount: 1}), "empty token type is SOL")
+	assert.False(t, f.matchesEvent(&natspkg.TransactionEvent{Amount: 1, TokenType: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"}))
+}
+
+func TestValidateWalletFilter(t *testing.T) {
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	minAmount := int64(1000)
+	negative := int64(-1)
+
+	params, err := validateWalletFilter(walletFilterRequest{
+		AssetType: "spl-token",
+		TokenMint: usdc,
+		MinAmount: &minAmount,
+		Memo:      "inv-",
+		MemoMatch: "prefix",
+		JQ:        []string{".order != null"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, params.AssetType)
+	assert.Equal(t, "spl-token", *params.AssetType)
+	require.NotNil(t, params.TokenMint)
+	assert.Equal(t, usdc, *params.TokenMint)
+	assert.Equal(t, &minAmount, params.MinAmount)
+	assert.Equal(t, "prefix", params.MemoMatch)
+	assert.Equal(t, []string{".order != null"}, params.JQ)
+
+	params, err = validateWalletFilter(walletFilterRequest{Memo: "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "exact", params.MemoMatch, "memo_match defaults to exact")
+
+	for name, req := range map[string]walletFilterRequest{
+		"empty":              {},
+		"bad asset type":     {AssetType: "nft"},
+		"token without mint": {AssetType: "spl-token"},
+		"mint without token": {AssetType: "sol", TokenMint: usdc},
+		"negative amount":    {MinAmount: &negative},
+		"bad memo match":     {Memo: "x", MemoMatch: "regex"},
+		"bad jq":             {JQ: []string{".order =="}},
+	} {
+		_, err := validateWalletFilter(req)
+		assert.Error(t, err, name)
+	}
+
+	assert.NoError(t, validateFilterName("large-sol_v1.2"))
+	assert.Error(t, validateFilterName(""))
+	assert.Error(t, validateFilterName("has space"))
+}
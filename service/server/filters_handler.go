@@ -0,0 +1,297 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	forohtoo "github.com/brojonat/forohtoo/client"
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxFilterMemoLength = 256
+	maxFilterJQ         = 10 // jq expressions per filter
+)
+
+// Filter names are referenced in query strings, so they're kept URL-safe.
+var validFilterNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// walletFilterRequest is the body of a filter save. Omitted criteria match
+// every transaction.
+type walletFilterRequest struct {
+	AssetType string   `json:"asset_type,omitempty"` // "sol" or "spl-token"
+	TokenMint string   `json:"token_mint,omitempty"` // required with asset_type "spl-token"
+	MinAmount *int64   `json:"min_amount,omitempty"` // in base units of the asset
+	Memo      string   `json:"memo,omitempty"`
+	MemoMatch string   `json:"memo_match,omitempty"` // "exact" (default), "prefix" or "contains"
+	JQ        []string `json:"jq,omitempty"`         // must all be truthy against the memo as JSON
+}
+
+// walletFilterResponse is the JSON response format for a wallet filter.
+type walletFilterResponse struct {
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Network   string    `json:"network"`
+	AssetType *string   `json:"asset_type,omitempty"`
+	TokenMint *string   `json:"token_mint,omitempty"`
+	MinAmount *int64    `json:"min_amount,omitempty"`
+	Memo      *string   `json:"memo,omitempty"`
+	MemoMatch string    `json:"memo_match"`
+	JQ        []string  `json:"jq"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func walletFilterToResponse(f *db.WalletFilter) walletFilterResponse {
+	jq := f.JQ
+	if jq == nil {
+		jq = []string{}
+	}
+	return walletFilterResponse{
+		Name:      f.Name,
+		Address:   f.Address,
+		Network:   f.Network,
+		AssetType: f.AssetType,
+		TokenMint: f.TokenMint,
+		MinAmount: f.MinAmount,
+		Memo:      f.Memo,
+		MemoMatch: f.MemoMatch,
+		JQ:        jq,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
+	}
+}
+
+// walletFilterScope validates the wallet a filter route acts on: the
+// {address} path value and the required network query parameter.
+func walletFilterScope(r *http.Request) (address, network string, err error) {
+	address = r.PathValue("address")
+	network = r.URL.Query().Get("network")
+	if err := validateAddress(address); err != nil {
+		return "", "", err
+	}
+	if err := validateNetwork(network); err != nil {
+		return "", "", err
+	}
+	return address, network, nil
+}
+
+// validateFilterName validates a wallet filter name.
+func validateFilterName(name string) error {
+	if !validFilterNameRegex.MatchString(name) {
+		return errorf("invalid filter name: must be 1-64 letters, digits, '.', '_' or '-'")
+	}
+	return nil
+}
+
+// validateWalletFilter validates a filter's criteria, compiling its jq
+// expressions, and returns them as store parameters (without the wallet and
+// name).
+func validateWalletFilter(req walletFilterRequest) (db.SaveWalletFilterParams, error) {
+	var params db.SaveWalletFilterParams
+
+	if req.AssetType != "" {
+		if err := validateAssetType(req.AssetType); err != nil {
+			return params, err
+		}
+		params.AssetType = &req.AssetType
+	}
+	if req.TokenMint != "" {
+		if req.AssetType != "spl-token" {
+			return params, errorf("token_mint requires asset_type 'spl-token'")
+		}
+		if err := validateTokenMint(req.TokenMint); err != nil {
+			return params, err
+		}
+		params.TokenMint = &req.TokenMint
+	} else if req.AssetType == "spl-token" {
+		return params, errorf("asset_type 'spl-token' requires token_mint")
+	}
+
+	if req.MinAmount != nil {
+		if *req.MinAmount < 0 {
+			return params, errorf("min_amount must not be negative")
+		}
+		params.MinAmount = req.MinAmount
+	}
+
+	if len(req.Memo) > maxFilterMemoLength {
+		return params, errorf("memo too long: maximum length is %d characters", maxFilterMemoLength)
+	}
+	if req.Memo != "" {
+		params.Memo = &req.Memo
+	}
+	mode, err := forohtoo.ParseMemoMatchMode(req.MemoMatch)
+	if err != nil {
+		return params, errorf("invalid memo_match: must be exact, prefix, or contains")
+	}
+	params.MemoMatch = string(mode)
+
+	if len(req.JQ) > maxFilterJQ {
+		return params, errorf("too many jq expressions: maximum is %d", maxFilterJQ)
+	}
+	if _, err := forohtoo.CompileJQ(req.JQ); err != nil {
+		return params, errorf("invalid jq: %v", err)
+	}
+	params.JQ = req.JQ
+
+	if params.AssetType == nil && params.MinAmount == nil && params.Memo == nil && len(params.JQ) == 0 {
+		return params, errorf("filter must set at least one of asset_type, min_amount, memo or jq")
+	}
+
+	return params, nil
+}
+
+// handleSaveWalletFilter returns a handler that creates or replaces a named
+// filter for a registered wallet.
+// PUT /api/v1/wallet-assets/{address}/filters/{name}?network={network}
+func handleSaveWalletFilter(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address, network, err := walletFilterScope(r)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		name := r.PathValue("name")
+		if err := validateFilterName(name); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+		var req walletFilterRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Debug("failed to decode wallet filter request", "error", err)
+			writeError(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		params, err := validateWalletFilter(req)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		params.Address = address
+		params.Network = network
+		params.Name = name
+
+		assets, err := store.ListWalletAssets(r.Context(), address, network, false)
+		if err != nil {
+			logger.Error("failed to get wallet assets", "address", address, "network", network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if len(assets) == 0 {
+			writeError(w, "wallet not found", http.StatusNotFound)
+			return
+		}
+
+		filter, err := store.SaveWalletFilter(r.Context(), params)
+		if err != nil {
+			logger.Error("failed to save wallet filter", "address", address, "network", network, "name", name, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("wallet filter saved", "address", address, "network", network, "name", name)
+		writeJSON(w, walletFilterToResponse(filter), http.StatusOK)
+	})
+}
+
+// handleListWalletFilters returns a handler that lists a wallet's filters.
+// GET /api/v1/wallet-assets/{address}/filters?network={network}
+func handleListWalletFilters(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address, network, err := walletFilterScope(r)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		filters, err := store.ListWalletFilters(r.Context(), address, network)
+		if err != nil {
+			logger.Error("failed to list wallet filters", "address", address, "network", network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]walletFilterResponse, len(filters))
+		for i, f := range filters {
+			resp[i] = walletFilterToResponse(f)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"filters": resp,
+		}, http.StatusOK)
+	})
+}
+
+// handleGetWalletFilter returns a handler that retrieves a wallet's filter.
+// GET /api/v1/wallet-assets/{address}/filters/{name}?network={network}
+func handleGetWalletFilter(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address, network, err := walletFilterScope(r)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		name := r.PathValue("name")
+		if err := validateFilterName(name); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		filter, err := store.GetWalletFilter(r.Context(), address, network, name)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeError(w, "filter not found", http.StatusNotFound)
+				return
+			}
+			logger.Error("failed to get wallet filter", "address", address, "network", network, "name", name, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, walletFilterToResponse(filter), http.StatusOK)
+	})
+}
+
+// handleDeleteWalletFilter returns a handler that deletes a wallet's filter.
+// A filter still applied by a digest subscription can't be deleted.
+// DELETE /api/v1/wallet-assets/{address}/filters/{name}?network={network}
+func handleDeleteWalletFilter(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address, network, err := walletFilterScope(r)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		name := r.PathValue("name")
+		if err := validateFilterName(name); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		deleted, err := store.DeleteWalletFilter(r.Context(), address, network, name)
+		if err != nil {
+			if errors.Is(err, db.ErrWalletFilterInUse) {
+				writeError(w, "filter is used by a digest subscription or webhook", http.StatusConflict)
+				return
+			}
+			logger.Error("failed to delete wallet filter", "address", address, "network", network, "name", name, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			writeError(w, "filter not found", http.StatusNotFound)
+			return
+		}
+
+		logger.Info("wallet filter deleted", "address", address, "network", network, "name", name)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/helius"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
+)
+
+// FinalizationStore defines the database operations needed by the Finalizer.
+type FinalizationStore interface {
+	ListTransactionsByConfirmationStatus(ctx context.Context, status string, network string, limit int32) ([]*db.Transaction, error)
+	UpdateTransactionStatus(ctx context.Context, signature string, network string, status string) ([]*db.Transaction, error)
+}
+
+// SignatureStatusChecker reports the RPC commitment of transaction signatures.
+type SignatureStatusChecker interface {
+	GetSignatureStatuses(ctx context.Context, network string, signatures []string) (map[string]string, error)
+}
+
+// compile-time assertions that the concrete types satisfy the interfaces.
+var (
+	_ FinalizationStore      = (*db.Store)(nil)
+	_ SignatureStatusChecker = (*helius.Client)(nil)
+)
+
+// Finalizer upgrades "confirmed" transactions to "finalized" once the RPC node
+// reports finalization. Helius webhooks deliver transactions at confirmed
+// commitment, so without it stored transactions never reach "finalized".
+type Finalizer struct {
+	store     FinalizationStore
+	checker   SignatureStatusChecker
+	publisher natspkg.Publisher // optional; re-publishes finalized transactions
+	interval  time.Duration
+	logger    *slog.Logger
+
+	now         func() time.Time     // overridden in tests
+	pausedUntil map[string]time.Time // network -> end of an RPC rate limit
+}
+
+// NewFinalizer creates a Finalizer that checks every interval. If publisher is
+// non-nil, finalized transactions are re-published so consumers waiting on
+// finality (e.g. SSE clients) see the upgraded status.
+func NewFinalizer(store FinalizationStore, checker SignatureStatusChecker, publisher natspkg.Publisher, interval time.Duration, logger *slog.Logger) *Finalizer {
+	return &Finalizer{
+		store:     store,
+		checker:   checker,
+		publisher: publisher,
+		interval:  interval,
+		logger:    logger,
+		now:       time.Now,
+
+		pausedUntil: make(map[string]time.Time),
+	}
+}
+
+// Run checks for finalized transactions every interval until ctx is cancelled.
+func (f *Finalizer) Run(ctx context.Context) {
+	f.logger.Info("finalization tracking started", "interval", f.interval, "publish", f.publisher != nil)
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.checkAll(ctx)
+		}
+	}
+}
+
+// checkAll checks each network once. A network whose RPC endpoint rate
+// limited the last check is skipped until the Retry-After it sent has
+// passed, so ticks don't keep the rate limit going.
+func (f *Finalizer) checkAll(ctx context.Context) {
+	for _, network := range []string{"mainnet", "devnet"} {
+		if f.now().Before(f.pausedUntil[network]) {
+			continue
+		}
+
+		err := f.checkNetwork(ctx, network)
+		var rateLimited *helius.RateLimitError
+		switch {
+		case errors.As(err, &rateLimited):
+			f.pausedUntil[network] = f.now().Add(rateLimited.RetryAfter)
+			f.logger.Warn("finalization check rate limited",
+				"network", network,
+				"endpoint", rateLimited.Endpoint,
+				"retry_after", rateLimited.RetryAfter,
+			)
+		case err != nil:
+			f.logger.Error("finalization check failed", "network", network, "error", err)
+		}
+	}
+}
+
+// checkNetwork checks one batch of confirmed transactions on a network,
+// oldest first, and records the status the RPC node reports. A transaction
+// stored for more than one wallet is checked and updated once, and each of
+// its rows is published.
+func (f *Finalizer) checkNetwork(ctx context.Context, network string) error {
+	txns, err := f.store.ListTransactionsByConfirmationStatus(ctx, "confirmed", network, helius.MaxSignatureStatusBatch)
+	if err != nil {
+		return err
+	}
+	if len(txns) == 0 {
+		return nil
+	}
+
+	var signatures []string
+	checked := make(map[string]bool, len(txns))
+	for _, t := range txns {
+		if !checked[t.Signature] {
+			checked[t.Signature] = true
+			signatures = append(signatures, t.Signature)
+		}
+	}
+
+	statuses, err := f.checker.GetSignatureStatuses(ctx, network, signatures)
+	if err != nil {
+		return err
+	}
+
+	var finalized []*natspkg.TransactionEvent
+	for _, signature := range signatures {
+		status := statuses[signature]
+		if status != "finalized" && status != "failed" {
+			continue
+		}
+
+		updated, err := f.store.UpdateTransactionStatus(ctx, signature, network, status)
+		if err != nil {
+			f.logger.Error("failed to update transaction status",
+				"signature", signature,
+				"network", network,
+				"status", status,
+				"error", err,
+			)
+			continue
+		}
+		if status == "finalized" {
+			for _, t := range updated {
+				finalized = append(finalized, natspkg.FromDBTransaction(t))
+			}
+		}
+	}
+
+	f.logger.Debug("finalization check complete",
+		"network", network,
+		"checked", len(signatures),
+		"finalized", len(finalized),
+	)
+
+	if len(finalized) > 0 && f.publisher != nil {
+		if err := f.publisher.PublishTransactionBatch(ctx, finalized); err != nil {
+			f.logger.Error("failed to publish finalized transactions", "count", len(finalized), "error", err)
+		}
+	}
+
+	return nil
+}
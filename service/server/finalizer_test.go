@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/helius"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFinalizationStore serves confirmed transactions and records status updates.
+type fakeFinalizationStore struct {
+	txns      map[string][]*db.Transaction // by network
+	updates   map[string]string            // signature -> status
+	updateErr map[string]error             // signature -> error
+}
+
+func (s *fakeFinalizationStore) ListTransactionsByConfirmationStatus(_ context.Context, status string, network string, limit int32) ([]*db.Transaction, error) {
+	var out []*db.Transaction
+	for _, t := range s.txns[network] {
+		if t.ConfirmationStatus == status && int32(len(out)) < limit {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeFinalizationStore) UpdateTransactionStatus(_ context.Context, signature string, network string, status string) ([]*db.Transaction, error) {
+	if err := s.updateErr[signature]; err != nil {
+		return nil, err
+	}
+	if _, ok := s.updates[signature]; ok {
+		return nil, fmt.Errorf("%s updated twice", signature)
+	}
+	var out []*db.Transaction
+	for _, t := range s.txns[network] {
+		if t.Signature == signature {
+			s.updates[signature] = status
+			updated := *t
+			updated.ConfirmationStatus = status
+			out = append(out, &updated)
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("not found")
+	}
+	return out, nil
+}
+
+type fakeStatusChecker struct {
+	statuses map[string]string
+	err      error
+	calls    [][]string
+}
+
+func (c *fakeStatusChecker) GetSignatureStatuses(_ context.Context, _ string, signatures []string) (map[string]string, error) {
+	c.calls = append(c.calls, signatures)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.statuses, nil
+}
+
+func confirmedTxn(sig string) *db.Transaction {
+	return &db.Transaction{
+		Signature:          sig,
+		WalletAddress:      "wallet1",
+		Network:            "mainnet",
+		BlockTime:          time.Now(),
+		ConfirmationStatus: "confirmed",
+	}
+}
+
+func TestFinalizer_CheckNetwork(t *testing.T) {
+	store := &fakeFinalizationStore{
+		txns: map[string][]*db.Transaction{
+			"mainnet": {confirmedTxn("sig1"), confirmedTxn("sig2"), confirmedTxn("sig3"), confirmedTxn("sig4")},
+		},
+		updates:   map[string]string{},
+		updateErr: map[string]error{"sig4": errors.New("db down")},
+	}
+	checker := &fakeStatusChecker{statuses: map[string]string{
+		"sig1": "finalized",
+		"sig2": "confirmed", // not yet final
+		"sig3": "failed",
+		"sig4": "finalized", // update fails; retried next tick
+	}}
+	publisher := &mockPublisher{}
+
+	f := NewFinalizer(store, checker, publisher, time.Minute, webhookTestLogger())
+	require.NoError(t, f.checkNetwork(context.Background(), "mainnet"))
+
+	require.Len(t, checker.calls, 1)
+	assert.Equal(t, []string{"sig1", "sig2", "sig3", "sig4"}, checker.calls[0])
+	assert.Equal(t, map[string]string{"sig1": "finalized", "sig3": "failed"}, store.updates)
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "sig1", publisher.events[0].Signature)
+	assert.Equal(t, "finalized", publisher.events[0].ConfirmationStatus)
+}
+
+func TestFinalizer_CheckNetwork_SeveralWallets(t *testing.T) {
+	// A transfer between two monitored wallets: the sender's outgoing row
+	// and the receiver's incoming row share a signature.
+	out := confirmedTxn("sig1")
+	out.WalletAddress = "wallet2"
+	out.Direction = db.DirectionOut
+	store := &fakeFinalizationStore{
+		txns:    map[string][]*db.Transaction{"mainnet": {confirmedTxn("sig1"), out}},
+		updates: map[string]string{},
+	}
+	checker := &fakeStatusChecker{statuses: map[string]string{"sig1": "finalized"}}
+	publisher := &mockPublisher{}
+
+	f := NewFinalizer(store, checker, publisher, time.Minute, webhookTestLogger())
+	require.NoError(t, f.checkNetwork(context.Background(), "mainnet"))
+
+	require.Len(t, checker.calls, 1)
+	assert.Equal(t, []string{"sig1"}, checker.calls[0], "each signature is checked once")
+	assert.Equal(t, map[string]string{"sig1": "finalized"}, store.updates)
+
+	require.Len(t, publisher.events, 2, "each wallet's row is published")
+	assert.Equal(t, "wallet1", publisher.events[0].WalletAddress)
+	assert.Equal(t, "wallet2", publisher.events[1].WalletAddress)
+}
+
+func TestFinalizer_CheckNetwork_NoPublisher(t *testing.T) {
+	store := &fakeFinalizationStore{
+		txns:    map[string][]*db.Transaction{"mainnet": {confirmedTxn("sig1")}},
+		updates: map[string]string{},
+	}
+	checker := &fakeStatusChecker{statuses: map[string]string{"sig1": "finalized"}}
+
+	f := NewFinalizer(store, checker, nil, time.Minute, webhookTestLogger())
+	require.NoError(t, f.checkNetwork(context.Background(), "mainnet"))
+	assert.Equal(t, map[string]string{"sig1": "finalized"}, store.updates)
+}
+
+func TestFinalizer_CheckNetwork_NothingPending(t *testing.T) {
+	store := &fakeFinalizationStore{updates: map[string]string{}}
+	checker := &fakeStatusChecker{}
+
+	f := NewFinalizer(store, checker, nil, time.Minute, webhookTestLogger())
+	require.NoError(t, f.checkNetwork(context.Background(), "devnet"))
+	assert.Empty(t, checker.calls, "RPC should not be called with no pending transactions")
+}
+
+func TestFinalizer_CheckNetwork_RPCError(t *testing.T) {
+	store := &fakeFinalizationStore{
+		txns:    map[string][]*db.Transaction{"mainnet": {confirmedTxn("sig1")}},
+		updates: map[string]string{},
+	}
+	checker := &fakeStatusChecker{err: errors.New("rpc unavailable")}
+
+	f := NewFinalizer(store, checker, nil, time.Minute, webhookTestLogger())
+	assert.Error(t, f.checkNetwork(context.Background(), "mainnet"))
+	assert.Empty(t, store.updates)
+}
+
+func TestFinalizer_CheckAll_RateLimited(t *testing.T) {
+	store := &fakeFinalizationStore{
+		txns: map[string][]*db.Transaction{
+			"mainnet": {confirmedTxn("sig1")},
+			"devnet":  {confirmedTxn("sig2")},
+		},
+		updates: map[string]string{},
+	}
+	checker := &fakeStatusChecker{err: fmt.Errorf("get signature statuses: %w", &helius.RateLimitError{Endpoint: "rpc.example.com", RetryAfter: 2 * time.Minute})}
+
+	f := NewFinalizer(store, checker, nil, time.Minute, webhookTestLogger())
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	f.checkAll(ctx)
+	assert.Len(t, checker.calls, 2, "both networks are checked")
+
+	// Both networks wait out the Retry-After instead of checking every tick.
+	now = now.Add(time.Minute)
+	f.checkAll(ctx)
+	assert.Len(t, checker.calls, 2)
+
+	now = now.Add(time.Minute)
+	checker.err = nil
+	checker.statuses = map[string]string{"sig1": "finalized", "sig2": "finalized"}
+	f.checkAll(ctx)
+	assert.Len(t, checker.calls, 4)
+	assert.Equal(t, map[string]string{"sig1": "finalized", "sig2": "finalized"}, store.updates)
+}
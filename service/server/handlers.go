@@ -1,36 +1,57 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
 	"github.com/brojonat/forohtoo/service/config"
 	"github.com/brojonat/forohtoo/service/db"
 	"github.com/brojonat/forohtoo/service/helius"
+	"github.com/brojonat/forohtoo/service/metrics"
 	"github.com/brojonat/forohtoo/service/temporal"
 	solanago "github.com/gagliardetto/solana-go"
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/sdk/client"
 )
 
 const (
 	maxRequestBodySize = 1 << 20 // 1MB - plenty for wallet registration
 	maxAddressLength   = 100     // Solana addresses are 44 chars, give buffer
+	maxSignatureLength = 88      // base58-encoded 64-byte signature
+	maxMetadataSize    = 4 << 10 // 4KB - annotations, not documents
+	maxAllowedSenders  = 100     // per-registration payment sender allowlist
+	maxWalletTags      = 20      // organizational tags per wallet asset
+	maxTagLength       = 64
+	maxCallbackURLLen  = 2048
 )
 
 var (
 	// Valid Solana address characters: base58 (no 0, O, I, l)
 	validAddressRegex = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]+$`)
+
+	// Tags are a name or a key:value pair, e.g. "billing" or "customer:acme"
+	validTagRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+(:[A-Za-z0-9_.-]+)?$`)
 )
 
 // handleGetWalletAsset returns a handler that retrieves all assets for a wallet address.
-// GET /api/v1/wallet-assets/{address}?network={network}
+// GET /api/v1/wallet-assets/{address}?network={network}&include_deleted={bool}
 // Returns all registered assets for the given wallet address and network.
+// Unregistered (soft-deleted) assets are only included with include_deleted=true.
 func handleGetWalletAsset(store *db.Store, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		address := r.PathValue("address")
@@ -51,7 +72,8 @@ func handleGetWalletAsset(store *db.Store, logger *slog.Logger) http.Handler {
 		}
 
 		// Get all assets for this wallet + network
-		assets, err := store.ListWalletAssets(r.Context(), address, network)
+		includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+		assets, err := store.ListWalletAssets(r.Context(), address, network, includeDeleted)
 		if err != nil {
 			logger.Error("failed to get wallet assets", "address", address, "network", network, "error", err)
 			writeError(w, "internal server error", http.StatusInternalServerError)
@@ -79,11 +101,66 @@ func handleGetWalletAsset(store *db.Store, logger *slog.Logger) http.Handler {
 	})
 }
 
+// handleGetWalletAssetsAllNetworks returns a handler that retrieves every
+// asset registered for a wallet address, grouped by network.
+// GET /api/v1/wallet-assets/{address}/all?include_deleted={bool}
+// Both networks are always present, so an unregistered address gets empty
+// lists rather than a 404.
+func handleGetWalletAssetsAllNetworks(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address := r.PathValue("address")
+
+		if err := validateAddress(address); err != nil {
+			logger.Debug("invalid address", "address", address, "error", err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		assets, err := store.ListWalletsByAddress(r.Context(), address)
+		if err != nil {
+			logger.Error("failed to get wallet assets", "address", address, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+		networks := map[string][]walletResponse{
+			"mainnet": {},
+			"devnet":  {},
+		}
+		for _, asset := range assets {
+			if asset.DeletedAt != nil && !includeDeleted {
+				continue
+			}
+			networks[asset.Network] = append(networks[asset.Network], walletToResponse(asset))
+		}
+
+		logger.Debug("wallet assets retrieved across networks", "address", address, "count", len(assets))
+
+		writeJSON(w, map[string]interface{}{
+			"address":  address,
+			"networks": networks,
+		}, http.StatusOK)
+	})
+}
+
 // handleListWalletAssets returns a handler that lists all registered wallet assets.
-// GET /api/v1/wallet-assets
+// GET /api/v1/wallet-assets?include_deleted={bool}&tag={tag}
+// The tag parameter may be repeated; only wallets carrying every tag are listed.
 func handleListWalletAssets(store *db.Store, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		wallets, err := store.ListWallets(r.Context())
+		tags := r.URL.Query()["tag"]
+		for _, tag := range tags {
+			if err := validateTag(tag); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		wallets, err := store.ListWallets(r.Context(), db.ListWalletsParams{
+			IncludeDeleted: r.URL.Query().Get("include_deleted") == "true",
+			Tags:           tags,
+		})
 		if err != nil {
 			logger.Error("failed to list wallets", "error", err)
 			writeError(w, "internal server error", http.StatusInternalServerError)
@@ -108,7 +185,7 @@ func handleListWalletAssets(store *db.Store, logger *slog.Logger) http.Handler {
 // and adds it to the Helius webhook for monitoring.
 // With payment gateway enabled, new wallets require payment first.
 // POST /api/v1/wallet-assets
-func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, temporalClient *temporal.Client, cfg *config.Config, logger *slog.Logger) http.Handler {
+func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, temporalClient *temporal.Client, cfg *config.Config, m *metrics.Metrics, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Limit request body size to prevent memory exhaustion
 		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
@@ -120,6 +197,10 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 				Type      string `json:"type"`       // "sol" or "spl-token"
 				TokenMint string `json:"token_mint"` // required when type == "spl-token"
 			} `json:"asset"`
+			Metadata       json.RawMessage `json:"metadata,omitempty"`        // optional JSON object
+			Tags           []string        `json:"tags,omitempty"`            // optional grouping tags; [] clears them on re-registration
+			AllowedSenders []string        `json:"allowed_senders,omitempty"` // optional: only accept the registration fee from these addresses
+			CallbackURL    string          `json:"callback_url,omitempty"`    // optional: POSTed once a payment-gated registration completes
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -132,6 +213,7 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 			writeError(w, "invalid request body: must be valid JSON", http.StatusBadRequest)
 			return
 		}
+		setAuditTarget(r, req.Address, req.Network)
 
 		// Validate address
 		if err := validateAddress(req.Address); err != nil {
@@ -154,6 +236,36 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 			return
 		}
 
+		// Validate optional metadata
+		metadata, err := normalizeMetadata(req.Metadata)
+		if err != nil {
+			logger.Debug("invalid metadata", "address", req.Address, "error", err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Validate optional tags
+		tags, err := normalizeTags(req.Tags)
+		if err != nil {
+			logger.Debug("invalid tags", "address", req.Address, "error", err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Validate optional sender allowlist
+		if err := validateAllowedSenders(req.AllowedSenders); err != nil {
+			logger.Debug("invalid allowed_senders", "address", req.Address, "error", err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Validate optional completion callback
+		if err := validateCallbackURL(req.CallbackURL, cfg.PaymentGateway.CallbackSecret); err != nil {
+			logger.Debug("invalid callback_url", "address", req.Address, "error", err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		// Validate and process asset-specific fields
 		var tokenMint string
 		var ata *string
@@ -176,9 +288,15 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 				return
 			}
 
-			// Verify mint is supported for this network
-			if !cfg.IsMintSupported(req.Network, req.Asset.TokenMint) {
-				supportedMints, _ := cfg.GetSupportedMints(req.Network)
+			// Verify mint is supported for this network (config mints plus the
+			// operator-managed registry)
+			supported, supportedMints, err := isMintSupported(r.Context(), store, cfg, req.Network, req.Asset.TokenMint)
+			if err != nil {
+				logger.Error("failed to check supported mints", "network", req.Network, "error", err)
+				writeError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			if !supported {
 				writeError(w, fmt.Sprintf("unsupported token mint for %s: supported mints are %v", req.Network, supportedMints), http.StatusBadRequest)
 				return
 			}
@@ -188,8 +306,8 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 			// Compute ATA
 			ataAddr, err := computeAssociatedTokenAddress(req.Address, tokenMint)
 			if err != nil {
-				logger.Error("failed to compute ATA", "address", req.Address, "mint", tokenMint, "error", err)
-				writeError(w, "failed to compute associated token address", http.StatusInternalServerError)
+				logger.Debug("failed to compute ATA", "address", req.Address, "mint", tokenMint, "error", err)
+				writeATAError(w, err)
 				return
 			}
 			ata = &ataAddr
@@ -203,27 +321,33 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 			return
 		}
 
-		// If wallet doesn't exist and payment gateway is enabled, require payment
-		if !walletExists && cfg.PaymentGateway.Enabled {
+		// If wallet doesn't exist and registering it carries a fee, require
+		// payment
+		if !walletExists && cfg.PaymentGateway.RequiresPayment(req.Network, req.Asset.Type) {
 			logger.Debug("new wallet registration with payment gateway enabled",
 				"address", req.Address,
 				"network", req.Network,
 				"asset_type", req.Asset.Type,
 			)
 
-			// Determine USDC mint based on service network
-			var usdcMint string
-			if cfg.PaymentGateway.ServiceNetwork == "mainnet" {
-				usdcMint = cfg.USDCMainnetMintAddress
-			} else {
-				usdcMint = cfg.USDCDevnetMintAddress
+			allowedSenders, err := effectiveAllowedSenders(cfg.PaymentGateway.AllowedSenders, req.AllowedSenders)
+			if err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
 			}
 
-			// Generate payment invoice (always in USDC)
+			// Generate payment invoice in the configured fee asset
 			// Invoice ID is the wallet address being registered
-			invoice := generatePaymentInvoice(&cfg.PaymentGateway, req.Address, usdcMint)
+			feeMint := cfg.PaymentFeeMint()
+			invoice, err := generatePaymentInvoice(&cfg.PaymentGateway, cfg.BasePath, req.Address, req.Network, req.Asset.Type, feeMint)
+			if err != nil {
+				logger.Error("failed to generate payment invoice", "address", req.Address, "error", err)
+				writeError(w, "failed to generate payment invoice", http.StatusInternalServerError)
+				return
+			}
 
-			// Start Temporal workflow for payment-gated registration
+			// Start Temporal workflow for payment-gated registration. The
+			// fee terms come from the invoice startPaymentRegistration settles on.
 			workflowID := fmt.Sprintf("payment-registration:%s", invoice.ID)
 			workflowInput := temporal.PaymentGatedRegistrationInput{
 				Address:                req.Address,
@@ -231,45 +355,60 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 				AssetType:              req.Asset.Type,
 				TokenMint:              tokenMint,
 				AssociatedTokenAddress: ata,
+				Metadata:               metadata,
+				Tags:                   tags,
 				ServiceWallet:          cfg.PaymentGateway.ServiceWallet,
 				ServiceNetwork:         cfg.PaymentGateway.ServiceNetwork,
-				FeeAmount:              cfg.PaymentGateway.FeeAmount,
-				PaymentMemo:            invoice.Memo,
-				PaymentTimeout:         cfg.PaymentGateway.PaymentTimeout,
-			}
-
-			// Use SDK client directly for workflow operations
-			sdkClient := temporalClient.SDKClient()
-			workflowOptions := client.StartWorkflowOptions{
-				ID:        workflowID,
-				TaskQueue: cfg.TemporalTaskQueue,
+				MemoMatch:              cfg.PaymentGateway.MemoMatchMode,
+				AllowedSenders:         allowedSenders,
+				RequireFinalized:       cfg.PaymentGateway.RequireFinalized,
+				FinalizationTimeout:    cfg.PaymentGateway.FinalizationTimeout,
+				CallbackURL:            req.CallbackURL,
 			}
 
-			_, err = sdkClient.ExecuteWorkflow(r.Context(), workflowOptions, "PaymentGatedRegistrationWorkflow", workflowInput)
-			if err != nil {
+			// Concurrent requests for the same wallet get the same invoice
+			// and workflow.
+			invoice, started, err := startPaymentRegistration(r.Context(), store, temporalClient.SDKClient(), cfg.TemporalTaskQueue, workflowID, invoice, workflowInput)
+			switch {
+			case errors.Is(err, errRegistrationPending):
+				writeError(w, err.Error(), http.StatusConflict)
+				return
+			case err != nil:
 				logger.Error("failed to start payment workflow", "error", err, "workflow_id", workflowID)
 				writeError(w, "failed to start payment workflow", http.StatusInternalServerError)
 				return
+			case started:
+				logger.Info("payment workflow started",
+					"workflow_id", workflowID,
+					"invoice_id", invoice.ID,
+					"address", req.Address,
+					"fee_amount", invoice.Amount,
+					"fee_tier", invoice.FeeTier,
+				)
+				if m != nil {
+					m.RecordPaymentFunnel(req.Network, metrics.FunnelInvoiceIssued)
+				}
+			default:
+				logger.Info("payment workflow already running",
+					"workflow_id", workflowID,
+					"invoice_id", invoice.ID,
+					"address", req.Address,
+				)
 			}
 
-			logger.Info("payment workflow started",
-				"workflow_id", workflowID,
-				"invoice_id", invoice.ID,
-				"address", req.Address,
-			)
-
 			// Return 402 Payment Required with invoice and workflow ID
 			response := map[string]interface{}{
 				"status":      "payment_required",
 				"invoice":     invoice,
 				"workflow_id": workflowID,
-				"status_url":  fmt.Sprintf("/api/v1/registration-status/%s", workflowID),
+				"status_url":  fmt.Sprintf("%s/api/v1/registration-status/%s", cfg.BasePath, workflowID),
 			}
 			writeJSON(w, response, http.StatusPaymentRequired)
 			return
 		}
 
-		// Wallet exists or payment gateway disabled - proceed with normal upsert
+		// Wallet exists, payment gateway disabled, or no fee on this network -
+		// proceed with normal upsert
 		// Upsert wallet+asset in database (create or update if exists)
 		params := db.UpsertWalletParams{
 			Address:                req.Address,
@@ -278,6 +417,8 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 			TokenMint:              tokenMint,
 			AssociatedTokenAddress: ata,
 			Status:                 "active",
+			Metadata:               metadata,
+			Tags:                   tags,
 		}
 
 		wallet, err := store.UpsertWallet(r.Context(), params)
@@ -318,8 +459,139 @@ func handleRegisterWalletAsset(store *db.Store, heliusClient *helius.Client, tem
 	})
 }
 
+const (
+	maxBatchRegistrations    = 100 // entries per batch registration
+	batchRegisterConcurrency = 4   // entries registered at once
+)
+
+// batchRegisterResult is the outcome of one entry in a batch registration.
+// Body is what POST /api/v1/wallet-assets would have returned for it: the
+// wallet, a payment_required invoice, or an error.
+type batchRegisterResult struct {
+	Index  int             `json:"index"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// handleBatchRegisterWalletAssets returns a handler that registers several
+// wallet assets in one request. Each entry takes the same fields as a single
+// registration and is passed to register, so it is validated (address,
+// network, asset type, ...) and charged for exactly as if posted alone. A bad
+// entry fails on its own: the response is 207 Multi-Status with a result per
+// entry, in request order.
+// POST /api/v1/wallet-assets/batch [{"address": ..., "network": ..., "asset": {...}}, ...]
+func handleBatchRegisterWalletAssets(register http.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+		var entries []json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+			logger.Debug("failed to decode batch register request", "error", err)
+			if strings.Contains(err.Error(), "http: request body too large") {
+				writeError(w, "request body too large: maximum size is 1MB", http.StatusBadRequest)
+				return
+			}
+			writeError(w, "invalid request body: must be a JSON array of registrations", http.StatusBadRequest)
+			return
+		}
+		if len(entries) == 0 {
+			writeError(w, "at least one registration is required", http.StatusBadRequest)
+			return
+		}
+		if len(entries) > maxBatchRegistrations {
+			writeError(w, fmt.Sprintf("too many registrations: maximum is %d per batch", maxBatchRegistrations), http.StatusBadRequest)
+			return
+		}
+
+		results := make([]batchRegisterResult, len(entries))
+		sem := make(chan struct{}, batchRegisterConcurrency)
+		var wg sync.WaitGroup
+		for i, entry := range entries {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				status, body := serveBatchEntry(register, r, entry)
+				results[i] = batchRegisterResult{Index: i, Status: status, Body: body}
+			}()
+		}
+		wg.Wait()
+
+		var registered, paymentRequired, failed int
+		for _, res := range results {
+			switch {
+			case res.Status == http.StatusPaymentRequired:
+				paymentRequired++
+			case res.Status >= 200 && res.Status < 300:
+				registered++
+			default:
+				failed++
+			}
+		}
+		logger.Info("batch registration processed",
+			"entries", len(entries),
+			"registered", registered,
+			"payment_required", paymentRequired,
+			"failed", failed,
+		)
+
+		writeJSON(w, map[string]interface{}{
+			"results":          results,
+			"registered":       registered,
+			"payment_required": paymentRequired,
+			"failed":           failed,
+		}, http.StatusMultiStatus)
+	})
+}
+
+// serveBatchEntry runs register on one batch entry and returns the status
+// and body it wrote. The entry gets its own audit target, so concurrent
+// entries don't overwrite the batch's; the batch's audit entry names none.
+func serveBatchEntry(register http.Handler, r *http.Request, entry json.RawMessage) (int, json.RawMessage) {
+	ctx := context.WithValue(r.Context(), auditTargetKey{}, &auditTarget{})
+	req := r.Clone(ctx)
+	req.Body = io.NopCloser(bytes.NewReader(entry))
+	req.ContentLength = int64(len(entry))
+
+	rec := &bufferedResponse{header: http.Header{}}
+	register.ServeHTTP(rec, req)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	body := bytes.TrimSpace(rec.body.Bytes())
+	if !json.Valid(body) {
+		body, _ = json.Marshal(map[string]string{"error": string(body)})
+	}
+	return rec.status, body
+}
+
+// bufferedResponse is an in-memory http.ResponseWriter for running a handler
+// on part of a request.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
 // handleUnregisterWalletAsset returns a handler that unregisters a wallet+asset
-// and removes it from the Helius webhook.
+// and removes it from the Helius webhook. The row is soft-deleted so the
+// registration history is preserved.
 // DELETE /api/v1/wallet-assets/{address}?network={network}&asset_type={type}&token_mint={mint}
 func handleUnregisterWalletAsset(store *db.Store, heliusClient *helius.Client, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -369,7 +641,8 @@ func handleUnregisterWalletAsset(store *db.Store, heliusClient *helius.Client, l
 			}
 		}
 
-		if err := store.DeleteWallet(r.Context(), address, network, assetType, tokenMint); err != nil {
+		// Keep the row for audit history; `forohtoo db purge-wallets` removes it permanently.
+		if _, err := store.SoftDeleteWallet(r.Context(), address, network, assetType, tokenMint); err != nil {
 			logger.Error("failed to delete wallet asset", "address", address, "error", err)
 			writeError(w, "failed to unregister wallet asset", http.StatusInternalServerError)
 			return
@@ -382,7 +655,7 @@ func handleUnregisterWalletAsset(store *db.Store, heliusClient *helius.Client, l
 
 // handleGetRegistrationStatus returns a handler that checks the status of a payment-gated registration workflow.
 // GET /api/v1/registration-status/{workflow_id}
-func handleGetRegistrationStatus(temporalClient *temporal.Client, logger *slog.Logger) http.Handler {
+func handleGetRegistrationStatus(temporalClient *temporal.Client, cfg *config.Config, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		workflowID := r.PathValue("workflow_id")
 
@@ -403,12 +676,29 @@ func handleGetRegistrationStatus(temporalClient *temporal.Client, logger *slog.L
 		// Check if workflow is still running (status 1 = Running)
 		isRunning := describeResp.WorkflowExecutionInfo.Status == 1
 		if isRunning {
-			logger.Debug("workflow still running", "workflow_id", workflowID)
-			writeJSON(w, map[string]interface{}{
+			// Past the invoice expiry the workflow may still accept a late
+			// payment during the grace period, but we report "expired".
+			expiresAt := describeResp.WorkflowExecutionInfo.GetStartTime().AsTime().Add(cfg.PaymentGateway.InvoiceWindow())
+			status := pendingRegistrationStatus(expiresAt, time.Now())
+			logger.Debug("workflow still running", "workflow_id", workflowID, "status", status)
+			response := map[string]interface{}{
 				"workflow_id": workflowID,
-				"status":      "pending",
+				"status":      status,
 				"state":       describeResp.WorkflowExecutionInfo.Status.String(),
-			}, http.StatusOK)
+				"expires_at":  expiresAt,
+			}
+			// Workflows started before the progress query existed can't
+			// answer it; they report the status alone.
+			if progress, err := queryRegistrationProgress(r.Context(), sdkClient, workflowID); err != nil {
+				logger.Debug("registration progress unavailable", "workflow_id", workflowID, "error", err)
+			} else {
+				response["stage"] = progress.Stage
+				response["payment_memo"] = progress.PaymentMemo
+				if progress.PaymentSignature != nil {
+					response["payment_signature"] = *progress.PaymentSignature
+				}
+			}
+			writeJSON(w, response, http.StatusOK)
 			return
 		}
 
@@ -441,6 +731,9 @@ func handleGetRegistrationStatus(temporalClient *temporal.Client, logger *slog.L
 		if wfResult.PaymentSignature != nil {
 			response["payment_signature"] = *wfResult.PaymentSignature
 		}
+		if wfResult.PaymentMemo != nil {
+			response["payment_memo"] = *wfResult.PaymentMemo
+		}
 		if !wfResult.RegisteredAt.IsZero() {
 			response["registered_at"] = wfResult.RegisteredAt
 		}
@@ -452,35 +745,155 @@ func handleGetRegistrationStatus(temporalClient *temporal.Client, logger *slog.L
 	})
 }
 
+// queryRegistrationProgress asks a running registration workflow how far it
+// has got.
+func queryRegistrationProgress(ctx context.Context, sdkClient client.Client, workflowID string) (*temporal.RegistrationProgress, error) {
+	value, err := sdkClient.QueryWorkflow(ctx, workflowID, "", temporal.RegistrationProgressQuery)
+	if err != nil {
+		return nil, err
+	}
+	var progress temporal.RegistrationProgress
+	if err := value.Get(&progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// pendingRegistrationStatus returns the status to report for a registration
+// workflow that is still waiting: "pending" until the invoice expires, then
+// "expired" for the remainder of the grace period.
+func pendingRegistrationStatus(expiresAt, now time.Time) string {
+	if now.After(expiresAt) {
+		return "expired"
+	}
+	return "pending"
+}
+
+// errRegistrationPending is returned when the address already has an
+// unexpired invoice for another network or asset. Registrations of one address
+// share a workflow ID, so that invoice must be paid or expire first.
+var errRegistrationPending = errors.New("a registration for this address is already awaiting payment")
+
+// registrationInvoiceClaimer stores the invoice for a payment-gated
+// registration. Satisfied by *db.Store.
+type registrationInvoiceClaimer interface {
+	ClaimRegistrationInvoice(ctx context.Context, params db.ClaimRegistrationInvoiceParams) (*db.RegistrationInvoice, bool, error)
+}
+
+// workflowStarter starts Temporal workflows. Satisfied by client.Client.
+type workflowStarter interface {
+	ExecuteWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (client.WorkflowRun, error)
+}
+
+// startPaymentRegistration starts the payment workflow for a new wallet
+// registration and returns the invoice to present, and whether this call
+// started the workflow.
+//
+// Concurrent registrations of the same wallet collapse onto one invoice and
+// one workflow. The invoice is claimed in the database under the workflow ID,
+// so the first request's invoice wins and later ones get it back. Every
+// request then starts the workflow under that deterministic ID; Temporal runs
+// one and rejects the rest as already started. Because every request attempts
+// the start, a claim whose start failed is retried by the next request.
+func startPaymentRegistration(ctx context.Context, claimer registrationInvoiceClaimer, starter workflowStarter, taskQueue, workflowID string, invoice Invoice, input temporal.PaymentGatedRegistrationInput) (Invoice, bool, error) {
+	raw, err := json.Marshal(invoice)
+	if err != nil {
+		return Invoice{}, false, fmt.Errorf("failed to encode invoice: %w", err)
+	}
+	stored, _, err := claimer.ClaimRegistrationInvoice(ctx, db.ClaimRegistrationInvoiceParams{
+		WorkflowID:  workflowID,
+		Address:     input.Address,
+		Network:     input.Network,
+		AssetType:   input.AssetType,
+		TokenMint:   input.TokenMint,
+		Invoice:     raw,
+		AcceptUntil: invoice.AcceptUntil,
+	})
+	if err != nil {
+		return Invoice{}, false, fmt.Errorf("failed to claim registration invoice: %w", err)
+	}
+	if stored.Network != input.Network || stored.AssetType != input.AssetType || stored.TokenMint != input.TokenMint {
+		return Invoice{}, false, errRegistrationPending
+	}
+	if err := json.Unmarshal(stored.Invoice, &invoice); err != nil {
+		return Invoice{}, false, fmt.Errorf("failed to decode registration invoice: %w", err)
+	}
+
+	// The workflow waits for the payment the stored invoice asks for.
+	input.FeeAssetType = invoice.AssetType
+	input.FeeMint = invoice.TokenMint
+	input.FeeAmount = invoice.Amount
+	input.PaymentMemo = invoice.Memo
+	input.PaymentTimeout = time.Until(invoice.AcceptUntil)
+
+	options := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: taskQueue,
+		// Surface an already-running workflow so a repeated request
+		// isn't counted as a new invoice.
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+	_, err = starter.ExecuteWorkflow(ctx, options, "PaymentGatedRegistrationWorkflow", input)
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	switch {
+	case errors.As(err, &alreadyStarted):
+		return invoice, false, nil
+	case err != nil:
+		return Invoice{}, false, err
+	}
+	return invoice, true, nil
+}
+
 // walletResponse is the JSON response format for a wallet asset.
 type walletResponse struct {
-	Address                string    `json:"address"`
-	Network                string    `json:"network"`
-	AssetType              string    `json:"asset_type"`
-	TokenMint              string    `json:"token_mint"`
-	AssociatedTokenAddress *string   `json:"associated_token_address,omitempty"`
-	Status                 string    `json:"status"`
-	CreatedAt              time.Time `json:"created_at"`
-	UpdatedAt              time.Time `json:"updated_at"`
+	Address                string          `json:"address"`
+	Network                string          `json:"network"`
+	AssetType              string          `json:"asset_type"`
+	TokenMint              string          `json:"token_mint"`
+	AssociatedTokenAddress *string         `json:"associated_token_address,omitempty"`
+	TokenProgram           string          `json:"token_program,omitempty"` // program the ATA is derived under; spl-token only
+	Status                 string          `json:"status"`
+	CreatedAt              time.Time       `json:"created_at"`
+	UpdatedAt              time.Time       `json:"updated_at"`
+	Metadata               json.RawMessage `json:"metadata,omitempty"`
+	Tags                   []string        `json:"tags"`
+	DeletedAt              *time.Time      `json:"deleted_at,omitempty"`
 }
 
 // walletToResponse converts a domain Wallet to a response format.
 func walletToResponse(w *db.Wallet) walletResponse {
+	var tokenProgram string
+	if w.AssetType == "spl-token" {
+		// computeAssociatedTokenAddress derives ATAs under the SPL Token program.
+		tokenProgram = solanago.TokenProgramID.String()
+	}
 	return walletResponse{
 		Address:                w.Address,
 		Network:                w.Network,
 		AssetType:              w.AssetType,
 		TokenMint:              w.TokenMint,
 		AssociatedTokenAddress: w.AssociatedTokenAddress,
+		TokenProgram:           tokenProgram,
 		Status:                 w.Status,
 		CreatedAt:              w.CreatedAt,
 		UpdatedAt:              w.UpdatedAt,
+		Metadata:               w.Metadata,
+		Tags:                   w.Tags,
+		DeletedAt:              w.DeletedAt,
 	}
 }
 
-// writeJSON writes a JSON response.
+// writeJSON writes a JSON response, with camelCase field names if the request
+// asked for them (see jsonCaseMiddleware).
 func writeJSON(w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
+	if wantsCamelCase(w) {
+		if body, err := marshalCamelCase(data); err == nil {
+			w.WriteHeader(statusCode)
+			w.Write(body)
+			return
+		}
+	}
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(data)
 }
@@ -494,6 +907,17 @@ func writeError(w http.ResponseWriter, message string, statusCode int) {
 	})
 }
 
+// writeErrorCode writes a JSON error response with a machine-readable code
+// alongside the message, for failures a client may want to branch on.
+func writeErrorCode(w http.ResponseWriter, message, code string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+		"code":  code,
+	})
+}
+
 // validateAddress validates a wallet address for security and format.
 func validateAddress(address string) error {
 	if address == "" {
@@ -570,6 +994,138 @@ func validateTokenMint(mint string) error {
 	return nil
 }
 
+// validateSignature validates a transaction signature. It must decode from
+// base58 to exactly 64 bytes, so malformed signatures are rejected before a
+// Helius call or database lookup. Every handler taking a signature uses it.
+func validateSignature(signature string) error {
+	if signature == "" {
+		return errorf("signature is required")
+	}
+
+	if len(signature) > maxSignatureLength {
+		return errorf("signature too long: maximum length is %d characters", maxSignatureLength)
+	}
+
+	if _, err := solanago.SignatureFromBase58(signature); err != nil {
+		return errorf("invalid signature format: must be a base58-encoded 64-byte signature")
+	}
+
+	return nil
+}
+
+// normalizeMetadata validates client-supplied metadata. It must be a JSON
+// object no larger than maxMetadataSize. An absent or null value returns nil.
+func normalizeMetadata(raw json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+
+	if len(trimmed) > maxMetadataSize {
+		return nil, errorf("metadata too large: maximum size is %d bytes", maxMetadataSize)
+	}
+
+	if trimmed[0] != '{' || !json.Valid(trimmed) {
+		return nil, errorf("invalid metadata: must be a JSON object")
+	}
+
+	return trimmed, nil
+}
+
+// validateTag validates a single wallet tag.
+func validateTag(tag string) error {
+	if tag == "" {
+		return errorf("tag must not be empty")
+	}
+	if len(tag) > maxTagLength {
+		return errorf("tag too long: maximum length is %d characters", maxTagLength)
+	}
+	if !validTagRegex.MatchString(tag) {
+		return errorf("invalid tag %q: must be a name or key:value using letters, digits, '_', '.' or '-'", tag)
+	}
+	return nil
+}
+
+// normalizeTags validates a registration's tags and removes duplicates,
+// preserving order. A nil slice (tags absent) stays nil so re-registering
+// keeps the existing tags; an empty slice clears them.
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	if len(tags) > maxWalletTags {
+		return nil, errorf("too many tags: maximum is %d per wallet", maxWalletTags)
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if err := validateTag(tag); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// validateAllowedSenders validates a registration's optional payment sender allowlist.
+func validateAllowedSenders(senders []string) error {
+	if len(senders) > maxAllowedSenders {
+		return errorf("too many allowed_senders: maximum is %d", maxAllowedSenders)
+	}
+	for _, sender := range senders {
+		if err := validateAddress(sender); err != nil {
+			return errorf("invalid allowed_senders entry %q: %v", sender, err)
+		}
+	}
+	return nil
+}
+
+// validateCallbackURL validates a registration's optional completion callback.
+// Callbacks are signed with the operator's secret, so they're refused when
+// none is configured.
+func validateCallbackURL(raw, secret string) error {
+	if raw == "" {
+		return nil
+	}
+	if secret == "" {
+		return errorf("callback_url is not supported: payment callbacks are not enabled on this server")
+	}
+	if len(raw) > maxCallbackURLLen {
+		return errorf("callback_url too long: maximum length is %d", maxCallbackURLLen)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errorf("callback_url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// effectiveAllowedSenders combines the operator's sender allowlist with the
+// one supplied on a registration. Each non-empty list is an independent
+// requirement, so when both are set a sender must appear in both. An empty
+// result means any sender is accepted.
+func effectiveAllowedSenders(configured, requested []string) ([]string, error) {
+	if len(configured) == 0 {
+		return requested, nil
+	}
+	if len(requested) == 0 {
+		return configured, nil
+	}
+
+	var allowed []string
+	for _, sender := range requested {
+		if slices.Contains(configured, sender) && !slices.Contains(allowed, sender) {
+			allowed = append(allowed, sender)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, errorf("none of the allowed_senders are permitted to pay this service")
+	}
+	return allowed, nil
+}
+
 // errorf is a helper to format error strings.
 func errorf(format string, args ...interface{}) error {
 	return &validationError{msg: strings.TrimSpace(fmt.Sprintf(format, args...))}
@@ -583,29 +1139,51 @@ func (e *validationError) Error() string {
 	return e.msg
 }
 
+// Errors wrapped by computeAssociatedTokenAddress, identifying which input
+// the computation failed on.
+var (
+	errInvalidWalletAddress = errors.New("invalid wallet address")
+	errInvalidTokenMint     = errors.New("invalid token mint")
+	errATADerivation        = errors.New("failed to compute ATA")
+)
+
 // computeAssociatedTokenAddress computes the ATA for a wallet address and token mint.
 // Returns the ATA address as a string, or an error if the computation fails.
 func computeAssociatedTokenAddress(walletAddress string, tokenMint string) (string, error) {
 	wallet, err := solanago.PublicKeyFromBase58(walletAddress)
 	if err != nil {
-		return "", fmt.Errorf("invalid wallet address: %w", err)
+		return "", fmt.Errorf("%w: %w", errInvalidWalletAddress, err)
 	}
 
 	mint, err := solanago.PublicKeyFromBase58(tokenMint)
 	if err != nil {
-		return "", fmt.Errorf("invalid token mint: %w", err)
+		return "", fmt.Errorf("%w: %w", errInvalidTokenMint, err)
 	}
 
 	ata, _, err := solanago.FindAssociatedTokenAddress(wallet, mint)
 	if err != nil {
-		return "", fmt.Errorf("failed to compute ATA: %w", err)
+		return "", fmt.Errorf("%w: %w", errATADerivation, err)
 	}
 
 	return ata.String(), nil
 }
 
+// writeATAError reports a computeAssociatedTokenAddress failure. Every cause
+// traces back to the address or mint in the request, so it's a 400 whose code
+// tells the client which input to fix.
+func writeATAError(w http.ResponseWriter, err error) {
+	code := "ata_derivation_failed"
+	switch {
+	case errors.Is(err, errInvalidWalletAddress):
+		code = "invalid_wallet_address"
+	case errors.Is(err, errInvalidTokenMint):
+		code = "invalid_token_mint"
+	}
+	writeErrorCode(w, err.Error(), code, http.StatusBadRequest)
+}
+
 // handleListTransactions returns a handler that lists transactions for a specific wallet.
-// GET /api/v1/transactions?wallet_address=ADDRESS&network=NETWORK&limit=N&offset=N
+// GET /api/v1/transactions?wallet_address=ADDRESS&network=NETWORK&limit=N&offset=N&sort=SORT
 func handleListTransactions(store *db.Store, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		query := r.URL.Query()
@@ -665,13 +1243,59 @@ func handleListTransactions(store *db.Store, logger *slog.Logger) http.Handler {
 			offset = int32(parsedOffset)
 		}
 
+		// Parse sort (default newest first)
+		sort, err := parseTransactionSort(query.Get("sort"))
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Parse direction (default every direction)
+		direction, err := parseTransactionDirection(query.Get("direction"))
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Parse before (keyset pagination, newest first only)
+		var before *db.TransactionCursor
+		if raw := query.Get("before"); raw != "" {
+			cursor, err := parseTransactionCursor(raw)
+			if err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if sort != db.SortBlockTimeDesc {
+				writeError(w, "before requires the default sort (block_time_desc)", http.StatusBadRequest)
+				return
+			}
+			if offset != 0 {
+				writeError(w, "before cannot be combined with offset", http.StatusBadRequest)
+				return
+			}
+			before = &cursor
+		}
+
 		// Query transactions
-		transactions, err := store.ListTransactionsByWallet(r.Context(), db.ListTransactionsByWalletParams{
-			WalletAddress: walletAddress,
-			Network:       network,
-			Limit:         limit,
-			Offset:        offset,
-		})
+		var transactions []*db.Transaction
+		if before != nil {
+			transactions, err = store.ListTransactionsByWalletBeforeCursor(r.Context(), db.ListTransactionsByWalletBeforeCursorParams{
+				WalletAddress: walletAddress,
+				Network:       network,
+				Direction:     direction,
+				Before:        *before,
+				Limit:         limit,
+			})
+		} else {
+			transactions, err = store.ListTransactionsByWallet(r.Context(), db.ListTransactionsByWalletParams{
+				WalletAddress: walletAddress,
+				Network:       network,
+				Limit:         limit,
+				Direction:     direction,
+				Offset:        offset,
+				Sort:          sort,
+			})
+		}
 		if err != nil {
 			logger.Error("failed to list transactions", "wallet", walletAddress, "error", err)
 			writeError(w, "internal server error", http.StatusInternalServerError)
@@ -680,33 +1304,104 @@ func handleListTransactions(store *db.Store, logger *slog.Logger) http.Handler {
 
 		logger.Debug("transactions listed", "wallet", walletAddress, "network", network, "count", len(transactions))
 
+		// Decimals let clients render amounts without configuring mints.
+		// They're an enhancement, so a lookup failure doesn't fail the request.
+		decimals, err := mintDecimals(r.Context(), store)
+		if err != nil {
+			logger.Warn("failed to load mint decimals", "error", err)
+		}
+
 		// Convert to response format
 		resp := make([]transactionResponse, len(transactions))
 		for i := range transactions {
 			resp[i] = transactionToResponse(transactions[i])
+			resp[i].Decimals = transactionDecimals(transactions[i], decimals)
 		}
 
-		writeJSON(w, map[string]interface{}{
+		body := map[string]interface{}{
 			"transactions": resp,
 			"count":        len(resp),
 			"limit":        limit,
 			"offset":       offset,
-		}, http.StatusOK)
+			"sort":         sort,
+			"direction":    direction,
+		}
+		// A full page may have more after it. The cursor only applies to the
+		// newest-first order.
+		if sort == db.SortBlockTimeDesc && len(transactions) == int(limit) {
+			body["next_cursor"] = encodeTransactionCursor(transactions[len(transactions)-1])
+		}
+		writeJSON(w, body, http.StatusOK)
 	})
 }
 
+// encodeTransactionCursor returns the opaque cursor for the page after t,
+// passed back as ?before=. It encodes t's block time (in microseconds, the
+// database's precision) and signature.
+func encodeTransactionCursor(t *db.Transaction) string {
+	raw := strconv.FormatInt(t.BlockTime.UnixMicro(), 10) + ":" + t.Signature
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseTransactionCursor decodes a cursor from encodeTransactionCursor.
+func parseTransactionCursor(cursor string) (db.TransactionCursor, error) {
+	invalid := errorf("invalid before cursor: use the next_cursor from a previous page")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return db.TransactionCursor{}, invalid
+	}
+	micros, signature, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return db.TransactionCursor{}, invalid
+	}
+	usec, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil || validateSignature(signature) != nil {
+		return db.TransactionCursor{}, invalid
+	}
+	return db.TransactionCursor{BlockTime: time.UnixMicro(usec).UTC(), Signature: signature}, nil
+}
+
+// parseTransactionSort validates the sort query parameter. Empty means newest
+// first.
+func parseTransactionSort(raw string) (db.TransactionSort, error) {
+	if raw == "" {
+		return db.SortBlockTimeDesc, nil
+	}
+	sort := db.TransactionSort(raw)
+	if !slices.Contains(db.TransactionSorts, sort) {
+		return "", errorf("invalid sort: must be one of %v", db.TransactionSorts)
+	}
+	return sort, nil
+}
+
+// parseTransactionDirection validates the direction query parameter. Empty
+// means every direction.
+func parseTransactionDirection(raw string) (string, error) {
+	if raw == "" || slices.Contains(db.Directions, raw) {
+		return raw, nil
+	}
+	return "", errorf("invalid direction: must be one of %v", db.Directions)
+}
+
 // transactionResponse is the JSON response format for a transaction.
 type transactionResponse struct {
-	Signature          string    `json:"signature"`
-	WalletAddress      string    `json:"wallet_address"`
-	FromAddress        *string   `json:"from_address,omitempty"`
-	Slot               int64     `json:"slot"`
-	BlockTime          time.Time `json:"block_time"`
-	Amount             int64     `json:"amount"`
-	TokenType          *string   `json:"token_type,omitempty"`
-	Memo               *string   `json:"memo,omitempty"`
-	ConfirmationStatus string    `json:"confirmation_status"`
-	CreatedAt          time.Time `json:"created_at"`
+	Signature          string          `json:"signature"`
+	WalletAddress      string          `json:"wallet_address"`
+	FromAddress        *string         `json:"from_address,omitempty"`
+	Slot               int64           `json:"slot"`
+	BlockTime          time.Time       `json:"block_time"`
+	Amount             int64           `json:"amount"`
+	TokenType          *string         `json:"token_type,omitempty"`
+	Decimals           *int            `json:"decimals,omitempty"` // for rendering Amount; omitted if unknown
+	Memo               *string         `json:"memo,omitempty"`
+	MemoTruncated      bool            `json:"memo_truncated"` // memo was cut to MAX_MEMO_LENGTH
+	Fee                int64           `json:"fee"` // network fee in lamports; 0 if unknown
+	PaymentID          *string         `json:"payment_id,omitempty"`
+	DuplicateLogical   bool            `json:"duplicate_logical"` // payment_id was already seen for this wallet
+	Direction          string          `json:"direction"`         // "in", "out" or "self", relative to wallet_address
+	ConfirmationStatus string          `json:"confirmation_status"`
+	CreatedAt          time.Time       `json:"created_at"`
+	Metadata           json.RawMessage `json:"metadata,omitempty"`
 }
 
 // transactionToResponse converts a domain Transaction to a response format.
@@ -720,7 +1415,13 @@ func transactionToResponse(t *db.Transaction) transactionResponse {
 		Amount:             t.Amount,
 		TokenType:          t.TokenMint,
 		Memo:               t.Memo,
+		MemoTruncated:      t.MemoTruncated,
+		Fee:                t.Fee,
+		PaymentID:          t.PaymentID,
+		DuplicateLogical:   t.DuplicateLogical,
+		Direction:          t.Direction,
 		ConfirmationStatus: t.ConfirmationStatus,
 		CreatedAt:          t.CreatedAt,
+		Metadata:           t.Metadata,
 	}
 }
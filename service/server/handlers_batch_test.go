@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchResponse struct {
+	Results         []batchRegisterResult `json:"results"`
+	Registered      int                   `json:"registered"`
+	PaymentRequired int                   `json:"payment_required"`
+	Failed          int                   `json:"failed"`
+}
+
+func TestBatchRegisterWalletAssets(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	// register answers by address, like the real handler would.
+	register := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+
+		var req struct {
+			Address string `json:"address"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch {
+		case strings.HasPrefix(req.Address, "paid"):
+			writeJSON(w, map[string]string{"status": "payment_required", "workflow_id": "payment-registration:" + req.Address}, http.StatusPaymentRequired)
+		case strings.HasPrefix(req.Address, "bad"):
+			writeError(w, "invalid address", http.StatusBadRequest)
+		default:
+			writeJSON(w, map[string]string{"address": req.Address}, http.StatusCreated)
+		}
+	})
+	handler := handleBatchRegisterWalletAssets(register, webhookTestLogger())
+
+	var entries []string
+	for i := 0; i < 10; i++ {
+		entries = append(entries, fmt.Sprintf(`{"address":"wallet%d"}`, i))
+	}
+	entries = append(entries, `{"address":"paid1"}`, `{"address":"bad1"}`)
+	body := "[" + strings.Join(entries, ",") + "]"
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets/batch", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	var resp batchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 10, resp.Registered)
+	assert.Equal(t, 1, resp.PaymentRequired)
+	assert.Equal(t, 1, resp.Failed)
+
+	require.Len(t, resp.Results, 12)
+	for i, res := range resp.Results {
+		assert.Equal(t, i, res.Index, "results are in request order")
+	}
+	assert.Equal(t, http.StatusCreated, resp.Results[3].Status)
+	assert.JSONEq(t, `{"address":"wallet3"}`, string(resp.Results[3].Body))
+	assert.Equal(t, http.StatusPaymentRequired, resp.Results[10].Status)
+	assert.JSONEq(t, `{"status":"payment_required","workflow_id":"payment-registration:paid1"}`, string(resp.Results[10].Body))
+	assert.Equal(t, http.StatusBadRequest, resp.Results[11].Status)
+	assert.JSONEq(t, `{"error":"invalid address"}`, string(resp.Results[11].Body))
+
+	assert.LessOrEqual(t, peak.Load(), int32(batchRegisterConcurrency))
+}
+
+func TestBatchRegisterWalletAssets_InvalidBatch(t *testing.T) {
+	register := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("register should not be called")
+	})
+	handler := handleBatchRegisterWalletAssets(register, webhookTestLogger())
+
+	tooMany := "[" + strings.Repeat(`{},`, maxBatchRegistrations) + "{}]"
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"not an array", `{"address":"wallet1"}`, "invalid request body: must be a JSON array of registrations"},
+		{"empty", `[]`, "at least one registration is required"},
+		{"too many", tooMany, "too many registrations: maximum is 100 per batch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets/batch", strings.NewReader(tt.body)))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantErr)
+		})
+	}
+}
+
+// TestBatchRegisterWalletAssets_Route runs entries through the real
+// registration handler; invalid ones fail validation before any store access.
+func TestBatchRegisterWalletAssets_Route(t *testing.T) {
+	handler := New(":0", &config.Config{}, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	body := `[
+		{"address": "bad!addr", "network": "mainnet", "asset": {"type": "sol"}},
+		{"address": "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK", "network": "testnet", "asset": {"type": "sol"}}
+	]`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets/batch", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	var resp batchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Failed)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, http.StatusBadRequest, resp.Results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, resp.Results[1].Status)
+	assert.Contains(t, string(resp.Results[1].Body), "network")
+}
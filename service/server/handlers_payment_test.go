@@ -1,10 +1,19 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/brojonat/forohtoo/service/config"
+	"github.com/brojonat/forohtoo/service/db"
 	"github.com/brojonat/forohtoo/service/temporal"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
 )
 
 // TestPaymentGatewayConfig tests that payment gateway config works
@@ -32,3 +41,260 @@ func TestTemporalClientInterface(t *testing.T) {
 	var client *temporal.Client
 	_ = client
 }
+
+// TestPendingRegistrationStatus tests that a running workflow is reported as
+// "expired" once the invoice expiry passes, even though it keeps accepting
+// payment through the grace period.
+func TestPendingRegistrationStatus(t *testing.T) {
+	expiresAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"before expiry", expiresAt.Add(-time.Minute), "pending"},
+		{"at expiry", expiresAt, "pending"},
+		{"within grace period", expiresAt.Add(time.Minute), "expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pendingRegistrationStatus(expiresAt, tt.now); got != tt.want {
+				t.Errorf("Expected status %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestEffectiveAllowedSenders tests how the operator allowlist and a
+// registration's own allowlist combine.
+func TestEffectiveAllowedSenders(t *testing.T) {
+	a := "SenderAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	b := "SenderBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB"
+	c := "SenderCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCC"
+
+	tests := []struct {
+		name       string
+		configured []string
+		requested  []string
+		want       []string
+		wantErr    bool
+	}{
+		{"open by default", nil, nil, nil, false},
+		{"config only", []string{a, b}, nil, []string{a, b}, false},
+		{"request only", nil, []string{c}, []string{c}, false},
+		{"both narrows to intersection", []string{a, b}, []string{b, c, b}, []string{b}, false},
+		{"disjoint lists are rejected", []string{a}, []string{c}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := effectiveAllowedSenders(tt.configured, tt.requested)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestValidateAllowedSenders tests validation of a registration's allowlist.
+func TestValidateAllowedSenders(t *testing.T) {
+	if err := validateAllowedSenders(nil); err != nil {
+		t.Errorf("Expected no error for empty allowlist, got: %v", err)
+	}
+	if err := validateAllowedSenders([]string{"DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"}); err != nil {
+		t.Errorf("Expected no error for valid address, got: %v", err)
+	}
+	if err := validateAllowedSenders([]string{"not a wallet!"}); err == nil {
+		t.Error("Expected error for invalid address, got nil")
+	}
+
+	tooMany := make([]string, maxAllowedSenders+1)
+	for i := range tooMany {
+		tooMany[i] = "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
+	}
+	if err := validateAllowedSenders(tooMany); err == nil {
+		t.Error("Expected error for too many senders, got nil")
+	}
+}
+
+// TestValidateCallbackURL tests validation of a registration's completion
+// callback, which requires the operator to have configured a signing secret.
+func TestValidateCallbackURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		secret  string
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"https", "https://example.com/hooks/paid", "s3cret", false},
+		{"http with port", "http://10.0.0.5:8080/paid", "s3cret", false},
+		{"callbacks disabled", "https://example.com/hooks/paid", "", true},
+		{"relative", "/hooks/paid", "s3cret", true},
+		{"wrong scheme", "ftp://example.com/paid", "s3cret", true},
+		{"too long", "https://example.com/" + strings.Repeat("a", maxCallbackURLLen), "s3cret", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCallbackURL(tt.url, tt.secret)
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected error for %q, got nil", tt.url)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error for %q, got: %v", tt.url, err)
+			}
+		})
+	}
+}
+
+// fakeInvoiceClaimer mimics ClaimRegistrationInvoice: the first claim for a
+// workflow ID wins.
+type fakeInvoiceClaimer struct {
+	mu     sync.Mutex
+	stored map[string]*db.RegistrationInvoice
+}
+
+func (f *fakeInvoiceClaimer) ClaimRegistrationInvoice(_ context.Context, params db.ClaimRegistrationInvoiceParams) (*db.RegistrationInvoice, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if inv, ok := f.stored[params.WorkflowID]; ok {
+		return inv, false, nil
+	}
+	inv := &db.RegistrationInvoice{
+		WorkflowID:  params.WorkflowID,
+		Address:     params.Address,
+		Network:     params.Network,
+		AssetType:   params.AssetType,
+		TokenMint:   params.TokenMint,
+		Invoice:     params.Invoice,
+		AcceptUntil: params.AcceptUntil,
+	}
+	f.stored[params.WorkflowID] = inv
+	return inv, true, nil
+}
+
+// fakeWorkflowStarter mimics Temporal's workflow ID uniqueness, failing the
+// first failures starts.
+type fakeWorkflowStarter struct {
+	mu       sync.Mutex
+	running  map[string]temporal.PaymentGatedRegistrationInput
+	failures int
+	calls    int
+}
+
+func (f *fakeWorkflowStarter) ExecuteWorkflow(_ context.Context, options client.StartWorkflowOptions, _ interface{}, args ...interface{}) (client.WorkflowRun, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("temporal unavailable")
+	}
+	if _, ok := f.running[options.ID]; ok {
+		return nil, serviceerror.NewWorkflowExecutionAlreadyStarted("already started", "", "")
+	}
+	f.running[options.ID] = args[0].(temporal.PaymentGatedRegistrationInput)
+	return nil, nil
+}
+
+// TestStartPaymentRegistration_Concurrent fires simultaneous registrations of
+// the same new wallet and checks they share one invoice and one workflow.
+func TestStartPaymentRegistration_Concurrent(t *testing.T) {
+	claimer := &fakeInvoiceClaimer{stored: map[string]*db.RegistrationInvoice{}}
+	starter := &fakeWorkflowStarter{running: map[string]temporal.PaymentGatedRegistrationInput{}}
+	input := temporal.PaymentGatedRegistrationInput{Address: "wallet1", Network: "mainnet", AssetType: "sol"}
+
+	const workers = 10
+	now := time.Now()
+	invoices := make([]Invoice, workers)
+	started := make([]bool, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each request generates its own invoice, as the handler does.
+			invoice := Invoice{
+				ID:          "wallet1",
+				AssetType:   "sol",
+				Amount:      int64(1000 + i),
+				Memo:        "forohtoo-reg:wallet1",
+				AcceptUntil: now.Add(time.Hour + time.Duration(i)*time.Second),
+			}
+			invoices[i], started[i], errs[i] = startPaymentRegistration(context.Background(), claimer, starter, "queue", "payment-registration:wallet1", invoice, input)
+		}(i)
+	}
+	wg.Wait()
+
+	startedCount := 0
+	for i := 0; i < workers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("request %d failed: %v", i, errs[i])
+		}
+		if invoices[i].Amount != invoices[0].Amount || !invoices[i].AcceptUntil.Equal(invoices[0].AcceptUntil) {
+			t.Errorf("request %d got invoice %+v, want %+v", i, invoices[i], invoices[0])
+		}
+		if started[i] {
+			startedCount++
+		}
+	}
+	if startedCount != 1 {
+		t.Errorf("Expected exactly one request to start the workflow, got %d", startedCount)
+	}
+	if len(starter.running) != 1 {
+		t.Fatalf("Expected one workflow, got %d", len(starter.running))
+	}
+	if got := starter.running["payment-registration:wallet1"].FeeAmount; got != invoices[0].Amount {
+		t.Errorf("Expected the workflow to wait for the shared invoice's amount %d, got %d", invoices[0].Amount, got)
+	}
+}
+
+// TestStartPaymentRegistration_RetriesFailedStart tests that a request
+// sharing an invoice whose workflow failed to start starts it.
+func TestStartPaymentRegistration_RetriesFailedStart(t *testing.T) {
+	claimer := &fakeInvoiceClaimer{stored: map[string]*db.RegistrationInvoice{}}
+	starter := &fakeWorkflowStarter{running: map[string]temporal.PaymentGatedRegistrationInput{}, failures: 1}
+	input := temporal.PaymentGatedRegistrationInput{Address: "wallet1", Network: "mainnet", AssetType: "sol"}
+	invoice := Invoice{ID: "wallet1", Amount: 1000, AcceptUntil: time.Now().Add(time.Hour)}
+
+	if _, _, err := startPaymentRegistration(context.Background(), claimer, starter, "queue", "payment-registration:wallet1", invoice, input); err == nil {
+		t.Fatal("Expected the first start to fail")
+	}
+	_, started, err := startPaymentRegistration(context.Background(), claimer, starter, "queue", "payment-registration:wallet1", invoice, input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !started {
+		t.Error("Expected the retry to start the workflow")
+	}
+}
+
+// TestStartPaymentRegistration_OtherAssetPending tests that a registration of
+// the same address for another asset waits for the pending invoice.
+func TestStartPaymentRegistration_OtherAssetPending(t *testing.T) {
+	claimer := &fakeInvoiceClaimer{stored: map[string]*db.RegistrationInvoice{}}
+	starter := &fakeWorkflowStarter{running: map[string]temporal.PaymentGatedRegistrationInput{}}
+	invoice := Invoice{ID: "wallet1", Amount: 1000, AcceptUntil: time.Now().Add(time.Hour)}
+
+	sol := temporal.PaymentGatedRegistrationInput{Address: "wallet1", Network: "mainnet", AssetType: "sol"}
+	if _, _, err := startPaymentRegistration(context.Background(), claimer, starter, "queue", "payment-registration:wallet1", invoice, sol); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	usdc := temporal.PaymentGatedRegistrationInput{Address: "wallet1", Network: "mainnet", AssetType: "spl-token", TokenMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"}
+	_, _, err := startPaymentRegistration(context.Background(), claimer, starter, "queue", "payment-registration:wallet1", invoice, usdc)
+	if !errors.Is(err, errRegistrationPending) {
+		t.Errorf("Expected errRegistrationPending, got %v", err)
+	}
+}
@@ -2,13 +2,17 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brojonat/forohtoo/service/config"
 	"github.com/brojonat/forohtoo/service/db"
@@ -18,6 +22,9 @@ import (
 )
 
 
+// testSignature is a well-formed transaction signature (64 bytes, base58).
+const testSignature = "5eeBPs2Zx8d8oLErJgDoNNNh227FAmPAbRRXJ44ZbLMtHP2Bfc2ydYXoawUatz3FHYxq92hDqZLxLz4BE6qXujiZ"
+
 func setupTestStore(t *testing.T) *db.Store {
 	t.Helper()
 
@@ -50,7 +57,7 @@ func TestRegisterWallet_PathologicalInput(t *testing.T) {
 		USDCMainnetMintAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
 		USDCDevnetMintAddress:  "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
 	}
-	handler := handleRegisterWalletAsset(store, nil, nil, cfg, logger)
+	handler := handleRegisterWalletAsset(store, nil, nil, cfg, nil, logger)
 
 	tests := []struct {
 		name           string
@@ -179,7 +186,7 @@ func TestRegisterWallet_ValidInput(t *testing.T) {
 		USDCMainnetMintAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
 		USDCDevnetMintAddress:  "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
 	}
-	handler := handleRegisterWalletAsset(store, nil, nil, cfg, logger)
+	handler := handleRegisterWalletAsset(store, nil, nil, cfg, nil, logger)
 
 	tests := []struct {
 		name    string
@@ -209,6 +216,36 @@ func TestRegisterWallet_ValidInput(t *testing.T) {
 	}
 }
 
+func TestRegisterWallet_FreeNetworkSkipsPaymentGateway(t *testing.T) {
+	store := setupTestStore(t)
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	freeDevnet := int64(0)
+	cfg := &config.Config{
+		USDCMainnetMintAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		USDCDevnetMintAddress:  "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+		PaymentGateway: config.PaymentGatewayConfig{
+			Enabled:         true,
+			ServiceWallet:   "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+			ServiceNetwork:  "mainnet",
+			FeeAmount:       1000000,
+			FeeAmountDevnet: &freeDevnet,
+		},
+	}
+	// No Temporal client: a registration that reached the gateway would fail.
+	handler := handleRegisterWalletAsset(store, nil, nil, cfg, nil, logger)
+
+	const address = "SysvarRent111111111111111111111111111111111"
+	body := `{"address":"` + address + `","network":"devnet","asset":{"type":"sol"}}`
+	req := httptest.NewRequest("POST", "/api/v1/wallet-assets", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	store.DeleteWallet(context.Background(), address, "devnet", "sol", "")
+}
+
 func TestGetWallet_PathologicalInput(t *testing.T) {
 	store := setupTestStore(t)
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
@@ -236,3 +273,279 @@ func TestGetWallet_PathologicalInput(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWalletAllNetworks_InvalidAddress(t *testing.T) {
+	store := setupTestStore(t)
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := handleGetWalletAssetsAllNetworks(store, logger)
+
+	for _, address := range []string{"", strings.Repeat("A", 500), "0OIl"} {
+		req := httptest.NewRequest("GET", "/api/v1/wallet-assets/x/all", nil)
+		req.SetPathValue("address", address)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "address %q", address)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tooMany := make([]string, maxWalletTags+1)
+	for i := range tooMany {
+		tooMany[i] = "tag" + strings.Repeat("x", i)
+	}
+
+	tests := []struct {
+		name    string
+		input   []string
+		want    []string
+		wantErr string
+	}{
+		{name: "absent", input: nil, want: nil},
+		{name: "empty clears", input: []string{}, want: []string{}},
+		{name: "name and key:value", input: []string{"billing", "customer:acme", "env:prod-eu.1"}, want: []string{"billing", "customer:acme", "env:prod-eu.1"}},
+		{name: "duplicates collapsed", input: []string{"env:prod", "billing", "env:prod"}, want: []string{"env:prod", "billing"}},
+		{name: "empty tag", input: []string{""}, wantErr: "must not be empty"},
+		{name: "spaces", input: []string{"customer acme"}, wantErr: "invalid tag"},
+		{name: "two colons", input: []string{"a:b:c"}, wantErr: "invalid tag"},
+		{name: "empty value", input: []string{"customer:"}, wantErr: "invalid tag"},
+		{name: "too long", input: []string{strings.Repeat("x", maxTagLength+1)}, wantErr: "tag too long"},
+		{name: "too many", input: tooMany, wantErr: "too many tags"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListWalletAssets_InvalidTag(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := handleListWalletAssets(nil, logger)
+
+	req := httptest.NewRequest("GET", "/api/v1/wallet-assets?tag=customer:acme&tag=bad%20tag", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid tag")
+}
+
+func TestWalletToResponse_TokenProgram(t *testing.T) {
+	ata := "5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1"
+
+	spl := walletToResponse(&db.Wallet{
+		Address:                "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+		Network:                "mainnet",
+		AssetType:              "spl-token",
+		TokenMint:              "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		AssociatedTokenAddress: &ata,
+	})
+	body, err := json.Marshal(spl)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"associated_token_address":"`+ata+`"`)
+	assert.Contains(t, string(body), `"token_program":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"`)
+
+	sol := walletToResponse(&db.Wallet{
+		Address:   "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+		Network:   "mainnet",
+		AssetType: "sol",
+	})
+	body, err = json.Marshal(sol)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "associated_token_address")
+	assert.NotContains(t, string(body), "token_program")
+}
+
+func TestParseTransactionSort(t *testing.T) {
+	sort, err := parseTransactionSort("")
+	require.NoError(t, err)
+	assert.Equal(t, db.SortBlockTimeDesc, sort, "default is newest first")
+
+	for _, raw := range []string{"block_time_asc", "block_time_desc", "amount_asc", "amount_desc"} {
+		sort, err := parseTransactionSort(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, db.TransactionSort(raw), sort)
+	}
+
+	for _, raw := range []string{"amount", "AMOUNT_DESC", "block_time desc", "amount_desc; DROP TABLE transactions"} {
+		_, err := parseTransactionSort(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestParseTransactionDirection(t *testing.T) {
+	for _, raw := range []string{"", "in", "out", "self"} {
+		direction, err := parseTransactionDirection(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, raw, direction)
+	}
+
+	for _, raw := range []string{"IN", "incoming", "both"} {
+		_, err := parseTransactionDirection(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestListTransactions_InvalidSort(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := handleListTransactions(nil, logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions?wallet_address=DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK&network=mainnet&sort=random", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid sort")
+}
+
+func TestTransactionCursor_RoundTrip(t *testing.T) {
+	blockTime := time.Date(2026, 1, 2, 15, 4, 5, 123456000, time.UTC)
+	cursor := encodeTransactionCursor(&db.Transaction{Signature: testSignature, BlockTime: blockTime})
+
+	got, err := parseTransactionCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, db.TransactionCursor{BlockTime: blockTime, Signature: testSignature}, got)
+}
+
+func TestParseTransactionCursor_Invalid(t *testing.T) {
+	for _, cursor := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("no-separator")),
+		base64.RawURLEncoding.EncodeToString([]byte("abc:" + testSignature)),
+		base64.RawURLEncoding.EncodeToString([]byte("1700000000000000:not-a-signature")),
+	} {
+		_, err := parseTransactionCursor(cursor)
+		assert.EqualError(t, err, "invalid before cursor: use the next_cursor from a previous page", cursor)
+	}
+}
+
+func TestListTransactions_BeforeValidation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := handleListTransactions(nil, logger)
+	cursor := encodeTransactionCursor(&db.Transaction{Signature: testSignature, BlockTime: time.Now()})
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"malformed", "&before=garbage", "invalid before cursor"},
+		{"with sort", "&sort=amount_desc&before=" + cursor, "before requires the default sort"},
+		{"with offset", "&offset=10&before=" + cursor, "before cannot be combined with offset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions?wallet_address=DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK&network=mainnet"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantErr)
+		})
+	}
+}
+
+func TestTransactionToResponse_Fee(t *testing.T) {
+	withFee := transactionToResponse(&db.Transaction{Signature: "sig1", Amount: 1000000, Fee: 5000})
+	body, err := json.Marshal(withFee)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"fee":5000`)
+
+	// Transactions ingested before fees were recorded report 0 rather than
+	// omitting the field, so consumers can always compute net amounts.
+	withoutFee := transactionToResponse(&db.Transaction{Signature: "sig2", Amount: 1000000})
+	body, err = json.Marshal(withoutFee)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"fee":0`)
+}
+
+func TestTransactionToResponse_MemoTruncated(t *testing.T) {
+	memo := "abc"
+	body, err := json.Marshal(transactionToResponse(&db.Transaction{Signature: "sig1", Memo: &memo, MemoTruncated: true}))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"memo_truncated":true`)
+
+	body, err = json.Marshal(transactionToResponse(&db.Transaction{Signature: "sig2", Memo: &memo}))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"memo_truncated":false`)
+}
+
+func TestValidateSignature(t *testing.T) {
+	assert.NoError(t, validateSignature(testSignature))
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   string
+	}{
+		{"empty", "", "signature is required"},
+		{"too long", strings.Repeat("1", maxSignatureLength+1), "signature too long"},
+		{"not base58", "0OIl" + testSignature[4:], "invalid signature format"},
+		{"too short", testSignature[:43], "invalid signature format"},
+		{"address", "DRpbCBMxVnDK7maPM5tGv6MvB3v1sRMC86PZ8okm21hy", "invalid signature format"},
+		{"wrong decoded length", strings.Repeat("1", 88), "invalid signature format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSignature(tt.signature)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestComputeAssociatedTokenAddress_Errors(t *testing.T) {
+	usdcMint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	wallet := "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
+
+	tests := []struct {
+		name     string
+		wallet   string
+		mint     string
+		wantErr  error
+		wantCode string
+	}{
+		{"invalid wallet", "not-base58-0OIl", usdcMint, errInvalidWalletAddress, "invalid_wallet_address"},
+		{"short wallet", "abc", usdcMint, errInvalidWalletAddress, "invalid_wallet_address"},
+		{"invalid mint", wallet, "not-base58-0OIl", errInvalidTokenMint, "invalid_token_mint"},
+		{"derivation failure", wallet, usdcMint, errATADerivation, "ata_derivation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.wantErr
+			if tt.wantErr != errATADerivation {
+				_, err = computeAssociatedTokenAddress(tt.wallet, tt.mint)
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				// Derivation only fails if no bump seed yields an off-curve
+				// address, which valid inputs never hit; wrap the sentinel as
+				// computeAssociatedTokenAddress would.
+				err = fmt.Errorf("%w: %w", errATADerivation, errors.New("unable to find a viable program address nonce"))
+			}
+
+			w := httptest.NewRecorder()
+			writeATAError(w, err)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantCode, body["code"])
+			assert.Equal(t, err.Error(), body["error"])
+			assert.True(t, strings.HasPrefix(body["error"], tt.wantErr.Error()), body["error"])
+		})
+	}
+
+	ata, err := computeAssociatedTokenAddress(wallet, usdcMint)
+	require.NoError(t, err)
+	assert.NotEmpty(t, ata)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/helius"
+)
+
+const rpcHealthTimeout = 5 * time.Second
+
+// rpcHealthChecker checks an RPC endpoint's connectivity and latency.
+// Satisfied by *helius.Client.
+type rpcHealthChecker interface {
+	HealthCheck(ctx context.Context, network string) (*helius.RPCHealth, error)
+}
+
+// rpcHealthResponse reports one network's RPC health.
+type rpcHealthResponse struct {
+	Network   string  `json:"network"`
+	OK        bool    `json:"ok"`
+	Endpoint  string  `json:"endpoint,omitempty"` // host that answered; a fallback if the primary failed
+	Slot      uint64  `json:"slot,omitempty"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// checkRPCHealth checks every network concurrently. Results are in the order
+// of networks.
+func checkRPCHealth(ctx context.Context, checker rpcHealthChecker, networks []string) []rpcHealthResponse {
+	results := make([]rpcHealthResponse, len(networks))
+	var wg sync.WaitGroup
+	for i, network := range networks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = rpcHealthResponse{Network: network}
+			health, err := checker.HealthCheck(ctx, network)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].OK = true
+			results[i].Endpoint = health.Endpoint
+			results[i].Slot = health.Slot
+			results[i].LatencyMS = float64(health.Latency.Microseconds()) / 1000
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// handleRPCHealth returns a handler that checks RPC connectivity for each
+// network. It is separate from /health, which backs the liveness probe and
+// must not fail because a third-party endpoint is slow.
+// GET /health/rpc
+// Responds 503 if any network's check fails.
+func handleRPCHealth(checker rpcHealthChecker, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if checker == nil {
+			writeError(w, "RPC health checks require Helius to be configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), rpcHealthTimeout)
+		defer cancel()
+
+		results := checkRPCHealth(ctx, checker, []string{"mainnet", "devnet"})
+
+		status, code := "ok", http.StatusOK
+		for _, res := range results {
+			if !res.OK {
+				logger.Warn("RPC health check failed", "network", res.Network, "error", res.Error)
+				status, code = "degraded", http.StatusServiceUnavailable
+			}
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"status":   status,
+			"networks": results,
+		}, code)
+	})
+}
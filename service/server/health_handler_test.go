@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/helius"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRPCHealthChecker fails for networks listed in errs.
+type fakeRPCHealthChecker struct {
+	errs map[string]error
+}
+
+func (f *fakeRPCHealthChecker) HealthCheck(_ context.Context, network string) (*helius.RPCHealth, error) {
+	if err := f.errs[network]; err != nil {
+		return nil, err
+	}
+	return &helius.RPCHealth{Network: network, Endpoint: network + ".helius-rpc.com", Slot: 1000, Latency: 42500 * time.Microsecond}, nil
+}
+
+func TestHandleRPCHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		checker    rpcHealthChecker
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "all healthy",
+			checker:    &fakeRPCHealthChecker{},
+			wantStatus: http.StatusOK,
+			wantBody: `{"status": "ok", "networks": [
+				{"network": "mainnet", "ok": true, "endpoint": "mainnet.helius-rpc.com", "slot": 1000, "latency_ms": 42.5},
+				{"network": "devnet", "ok": true, "endpoint": "devnet.helius-rpc.com", "slot": 1000, "latency_ms": 42.5}
+			]}`,
+		},
+		{
+			name:       "one network failing",
+			checker:    &fakeRPCHealthChecker{errs: map[string]error{"devnet": errors.New("helius API error (status 429): rate limited")}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: `{"status": "degraded", "networks": [
+				{"network": "mainnet", "ok": true, "endpoint": "mainnet.helius-rpc.com", "slot": 1000, "latency_ms": 42.5},
+				{"network": "devnet", "ok": false, "error": "helius API error (status 429): rate limited"}
+			]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handleRPCHealth(tt.checker, webhookTestLogger())
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/health/rpc", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandleRPCHealth_NotConfigured(t *testing.T) {
+	handler := handleRPCHealth(nil, webhookTestLogger())
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/health/rpc", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Contains(t, body["error"], "Helius")
+}
@@ -41,9 +41,10 @@ func (tr *TemplateRenderer) Render(w http.ResponseWriter, name string, data inte
 }
 
 // handleSSEClientPage serves the SSE client demo page
-func handleSSEClientPage(renderer *TemplateRenderer) http.HandlerFunc {
+func handleSSEClientPage(renderer *TemplateRenderer, basePath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		data := map[string]interface{}{
+			"BasePath":               basePath,
 			"USDCMainnetMintAddress": os.Getenv("USDC_MAINNET_MINT_ADDRESS"),
 			"USDCDevnetMintAddress":  os.Getenv("USDC_DEVNET_MINT_ADDRESS"),
 		}
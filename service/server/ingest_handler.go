@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/helius"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
+)
+
+// transactionFetcher fetches transactions by signature in the webhook payload
+// format. Satisfied by *helius.Client.
+type transactionFetcher interface {
+	GetTransactions(ctx context.Context, network string, signatures []string) ([]helius.EnhancedTransaction, error)
+}
+
+// handleIngestTransaction returns a handler that ingests a single transaction
+// by signature, for recovering one a webhook delivery missed. The transaction
+// is fetched from Helius and goes through the same match/write/publish path as
+// webhook deliveries, so ingesting an already-stored transaction is a no-op.
+// POST /api/v1/admin/ingest
+func handleIngestTransaction(store *db.Store, fetcher transactionFetcher, publisher natspkg.Publisher, opts ingestOptions, payloads *payloadLogger, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+		var req struct {
+			Signature string `json:"signature"`
+			Network   string `json:"network"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Debug("failed to decode ingest request", "error", err)
+			if strings.Contains(err.Error(), "http: request body too large") {
+				writeError(w, "request body too large: maximum size is 1MB", http.StatusBadRequest)
+				return
+			}
+			writeError(w, "invalid request body: must be valid JSON", http.StatusBadRequest)
+			return
+		}
+		setAuditTarget(r, "", req.Network)
+
+		if err := validateSignature(req.Signature); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateNetwork(req.Network); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if fetcher == nil {
+			writeError(w, "ingest requires Helius to be configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		txns, err := fetcher.GetTransactions(r.Context(), req.Network, []string{req.Signature})
+		if err != nil {
+			logger.Error("failed to fetch transaction", "signature", req.Signature, "network", req.Network, "error", err)
+			writeError(w, "failed to fetch transaction from Helius", http.StatusBadGateway)
+			return
+		}
+		if len(txns) == 0 {
+			writeError(w, "transaction not found", http.StatusNotFound)
+			return
+		}
+
+		// Only match wallets on the requested network; the same address may be
+		// registered on both.
+		wallets, err := store.ListActiveWallets(r.Context())
+		if err != nil {
+			logger.Error("failed to list active wallets", "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		onNetwork := make([]*db.Wallet, 0, len(wallets))
+		for _, wallet := range wallets {
+			if wallet.Network == req.Network {
+				onNetwork = append(onNetwork, wallet)
+			}
+		}
+
+		result := ingestTransactions(r.Context(), store, publisher, txns, walletAddressMap(onNetwork), "ingest", opts, payloads, logger)
+		if result.Matched == 0 {
+			writeError(w, "transaction does not involve a monitored wallet on "+req.Network, http.StatusUnprocessableEntity)
+			return
+		}
+		if result.Failed > 0 && len(result.Written) == 0 && result.Skipped == 0 {
+			writeError(w, "failed to store transaction", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("ingested transaction",
+			"signature", req.Signature,
+			"network", req.Network,
+			"matched", result.Matched,
+			"written", len(result.Written),
+			"skipped", result.Skipped,
+		)
+
+		resp := make([]transactionResponse, len(result.Written))
+		for i, t := range result.Written {
+			resp[i] = transactionToResponse(t)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"signature":    req.Signature,
+			"network":      req.Network,
+			"matched":      result.Matched,
+			"written":      len(result.Written),
+			"skipped":      result.Skipped,
+			"transactions": resp,
+		}, http.StatusOK)
+	})
+}
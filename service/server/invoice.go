@@ -3,7 +3,9 @@ package server
 import (
 	"encoding/base64"
 	"fmt"
+	"math"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/skip2/go-qrcode"
@@ -12,39 +14,66 @@ import (
 )
 
 // Invoice represents a payment invoice for wallet registration.
-// All payments are in USDC.
+// Payments are in the configured fee asset: USDC by default, SOL, or another
+// SPL token.
 type Invoice struct {
-	ID           string        `json:"id"`             // Invoice ID (wallet address being registered)
-	PayToAddress string        `json:"pay_to_address"` // Forohtoo's wallet
-	Network      string        `json:"network"`        // "mainnet" or "devnet"
-	USDCMint     string        `json:"usdc_mint"`      // USDC token mint address for the network
-	Amount       int64         `json:"amount"`         // Amount in USDC base units (6 decimals)
-	AmountUSDC   float64       `json:"amount_usdc"`    // Human-readable USDC amount
-	Memo         string        `json:"memo"`           // Required in payment txn
-	ExpiresAt    time.Time     `json:"expires_at"`     // Payment deadline
-	Timeout      time.Duration `json:"timeout"`        // Duration until expiry
-	StatusURL    string        `json:"status_url"`     // Where to check payment status
-	PaymentURL   string        `json:"payment_url"`    // Solana Pay URL for wallet apps
-	QRCodeData   string        `json:"qr_code_data"`   // Base64 encoded QR code image
+	ID           string        `json:"id"`                   // Invoice ID (wallet address being registered)
+	PayToAddress string        `json:"pay_to_address"`       // Forohtoo's wallet
+	PayToAccount string        `json:"pay_to_account"`       // Account credited: the wallet's ATA for tokens, the wallet for SOL
+	Network      string        `json:"network"`              // "mainnet" or "devnet"
+	AssetType    string        `json:"asset_type"`           // "sol" or "spl-token"
+	TokenMint    string        `json:"token_mint,omitempty"` // Fee token mint (empty for SOL)
+	Amount       int64         `json:"amount"`               // Amount in base units of the fee asset
+	FeeTier      string        `json:"fee_tier,omitempty"`   // Fee tier charged, if any
+	FeeReason    string        `json:"fee_reason"`           // Why Amount applies
+	Decimals     int           `json:"decimals"`             // Decimals of the fee asset
+	AmountUI     float64       `json:"amount_ui"`            // Human-readable amount
+	Memo         string        `json:"memo"`                 // Required in payment txn
+	ExpiresAt    time.Time     `json:"expires_at"`           // Displayed payment deadline
+	AcceptUntil  time.Time     `json:"accept_until"`         // Late payments are accepted until here (expiry + grace period)
+	Timeout      time.Duration `json:"timeout"`              // Duration until expiry
+	StatusURL    string        `json:"status_url"`           // Where to check payment status
+	PaymentURL   string        `json:"payment_url"`          // Solana Pay URL for wallet apps
+	QRCodeData   string        `json:"qr_code_data"`         // Base64 encoded QR code image
 	CreatedAt    time.Time     `json:"created_at"`
 }
 
 // generatePaymentInvoice creates a new payment invoice for wallet registration.
-// Payment is always in USDC for the specified network.
+// network and assetType are the network and asset type of the wallet being
+// registered, which set the fee. feeMint is the SPL mint fees are charged in, or "" when fees are in SOL.
 // The invoice ID is the wallet address being registered (ensures uniqueness and traceability).
-func generatePaymentInvoice(cfg *config.PaymentGatewayConfig, walletAddress, usdcMint string) Invoice {
+// basePath prefixes the status URL when the server is mounted under a base path.
+func generatePaymentInvoice(cfg *config.PaymentGatewayConfig, basePath, walletAddress, network, assetType, feeMint string) (Invoice, error) {
 	invoiceID := walletAddress
 	memo := fmt.Sprintf("%s%s", cfg.MemoPrefix, invoiceID)
 	now := time.Now()
 
-	// Convert USDC base units to human-readable amount (USDC has 6 decimals)
-	amountUSDC := float64(cfg.FeeAmount) / 1e6
+	feeAssetType := "spl-token"
+	payToAccount := cfg.ServiceWallet
+	if feeMint == "" {
+		feeAssetType = "sol"
+	} else {
+		// Token payments land in the service wallet's ATA, which is the
+		// address we monitor.
+		ata, err := computeAssociatedTokenAddress(cfg.ServiceWallet, feeMint)
+		if err != nil {
+			return Invoice{}, fmt.Errorf("failed to compute service wallet ATA: %w", err)
+		}
+		payToAccount = ata
+	}
+
+	fee := cfg.FeeFor(network, assetType)
+	amount := fee.Amount
+	decimals := cfg.FeeAssetDecimals()
+	amountUI := float64(amount) / math.Pow10(decimals)
 
-	// Build Solana Pay URL for USDC payment
+	// Solana Pay recipients are always the wallet; wallet apps derive the ATA
+	// from the spl-token parameter.
 	paymentURL := buildSolanaPayURL(
 		cfg.ServiceWallet,
-		cfg.FeeAmount,
-		usdcMint,
+		amount,
+		decimals,
+		feeMint,
 		memo,
 	)
 
@@ -58,29 +87,38 @@ func generatePaymentInvoice(cfg *config.PaymentGatewayConfig, walletAddress, usd
 	return Invoice{
 		ID:           invoiceID,
 		PayToAddress: cfg.ServiceWallet,
+		PayToAccount: payToAccount,
 		Network:      cfg.ServiceNetwork,
-		USDCMint:     usdcMint,
-		Amount:       cfg.FeeAmount,
-		AmountUSDC:   amountUSDC,
+		AssetType:    feeAssetType,
+		TokenMint:    feeMint,
+		Amount:       amount,
+		FeeTier:      fee.Tier,
+		FeeReason:    fee.Reason,
+		Decimals:     decimals,
+		AmountUI:     amountUI,
 		Memo:         memo,
-		ExpiresAt:    now.Add(cfg.PaymentTimeout),
-		Timeout:      cfg.PaymentTimeout,
-		StatusURL:    fmt.Sprintf("/api/v1/registration-status/payment-registration:%s", invoiceID),
+		ExpiresAt:    now.Add(cfg.InvoiceWindow()),
+		AcceptUntil:  now.Add(cfg.AcceptanceWindow()),
+		Timeout:      cfg.InvoiceWindow(),
+		StatusURL:    fmt.Sprintf("%s/api/v1/registration-status/payment-registration:%s", basePath, invoiceID),
 		PaymentURL:   paymentURL,
 		QRCodeData:   qrCodeData,
 		CreatedAt:    now,
-	}
+	}, nil
 }
 
-// buildSolanaPayURL creates a Solana Pay-compatible URL for USDC payment.
-// Format: solana:{recipient}?amount={amount}&spl-token={usdcMint}&memo={memo}&label={label}&message={message}
-func buildSolanaPayURL(recipient string, amountBaseUnits int64, usdcMint, memo string) string {
-	// Convert USDC base units to human-readable amount (6 decimals)
-	amountUSDC := float64(amountBaseUnits) / 1e6
+// buildSolanaPayURL creates a Solana Pay-compatible URL for the fee payment.
+// The spl-token parameter is omitted when tokenMint is empty (a SOL payment).
+// Format: solana:{recipient}?amount={amount}&spl-token={tokenMint}&memo={memo}&label={label}&message={message}
+func buildSolanaPayURL(recipient string, amountBaseUnits int64, decimals int, tokenMint, memo string) string {
+	// Convert base units to the human-readable amount Solana Pay expects
+	amount := float64(amountBaseUnits) / math.Pow10(decimals)
 
 	params := url.Values{}
-	params.Set("amount", fmt.Sprintf("%.6f", amountUSDC))
-	params.Set("spl-token", usdcMint) // Always USDC
+	params.Set("amount", strconv.FormatFloat(amount, 'f', decimals, 64))
+	if tokenMint != "" {
+		params.Set("spl-token", tokenMint)
+	}
 	params.Set("memo", memo)
 	params.Set("label", "Forohtoo Registration")
 	params.Set("message", "Payment for wallet monitoring service")
@@ -16,7 +16,7 @@ func TestGeneratePaymentInvoice(t *testing.T) {
 	walletAddress := "TestWalletAddress123456789012345678901234"
 	usdcMint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v" // USDC mainnet
 	cfg := &config.PaymentGatewayConfig{
-		ServiceWallet:  "FoRoHtOoWaLLeTaDdReSs1234567890123456789012",
+		ServiceWallet:  "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
 		ServiceNetwork: "mainnet",
 		FeeAmount:      1000000, // 1 USDC
 		PaymentTimeout: 24 * time.Hour,
@@ -24,7 +24,10 @@ func TestGeneratePaymentInvoice(t *testing.T) {
 	}
 
 	beforeGeneration := time.Now()
-	invoice := generatePaymentInvoice(cfg, walletAddress, usdcMint)
+	invoice, err := generatePaymentInvoice(cfg, "", walletAddress, "mainnet", "sol", usdcMint)
+	if err != nil {
+		t.Fatalf("generatePaymentInvoice failed: %v", err)
+	}
 	afterGeneration := time.Now()
 
 	// Verify invoice ID is the wallet address
@@ -43,10 +46,10 @@ func TestGeneratePaymentInvoice(t *testing.T) {
 		t.Errorf("Expected Amount %d, got %d", cfg.FeeAmount, invoice.Amount)
 	}
 
-	// Verify AmountUSDC calculation (base units to USDC)
-	expectedAmountUSDC := float64(cfg.FeeAmount) / 1e6
-	if invoice.AmountUSDC != expectedAmountUSDC {
-		t.Errorf("Expected AmountUSDC %.6f, got %.6f", expectedAmountUSDC, invoice.AmountUSDC)
+	// Verify AmountUI calculation (base units to USDC)
+	expectedAmountUI := float64(cfg.FeeAmount) / 1e6
+	if invoice.AmountUI != expectedAmountUI {
+		t.Errorf("Expected AmountUI %.6f, got %.6f", expectedAmountUI, invoice.AmountUI)
 	}
 
 	// Verify network
@@ -54,15 +57,22 @@ func TestGeneratePaymentInvoice(t *testing.T) {
 		t.Errorf("Expected Network %q, got %q", cfg.ServiceNetwork, invoice.Network)
 	}
 
-	// Verify USDC mint
-	if invoice.USDCMint != usdcMint {
-		t.Errorf("Expected USDCMint %q, got %q", usdcMint, invoice.USDCMint)
+	// Verify USDC asset
+	if invoice.AssetType != "spl-token" || invoice.TokenMint != usdcMint {
+		t.Errorf("Expected spl-token asset with mint %q, got %q/%q", usdcMint, invoice.AssetType, invoice.TokenMint)
 	}
 
-	// Verify pay to address
+	// Verify pay to address and the token account payments land in
 	if invoice.PayToAddress != cfg.ServiceWallet {
 		t.Errorf("Expected PayToAddress %q, got %q", cfg.ServiceWallet, invoice.PayToAddress)
 	}
+	expectedATA, err := computeAssociatedTokenAddress(cfg.ServiceWallet, usdcMint)
+	if err != nil {
+		t.Fatalf("computeAssociatedTokenAddress failed: %v", err)
+	}
+	if invoice.PayToAccount != expectedATA {
+		t.Errorf("Expected PayToAccount %q (ATA), got %q", expectedATA, invoice.PayToAccount)
+	}
 
 	// Verify expiry
 	if invoice.ExpiresAt.Before(beforeGeneration.Add(cfg.PaymentTimeout)) {
@@ -102,7 +112,7 @@ func TestGeneratePaymentInvoice(t *testing.T) {
 	if invoice.QRCodeData == "" {
 		t.Error("QRCodeData should not be empty")
 	}
-	_, err := base64.StdEncoding.DecodeString(invoice.QRCodeData)
+	_, err = base64.StdEncoding.DecodeString(invoice.QRCodeData)
 	if err != nil {
 		t.Errorf("QRCodeData should be valid base64: %v", err)
 	}
@@ -113,14 +123,65 @@ func TestGeneratePaymentInvoice(t *testing.T) {
 	}
 }
 
+// TestGeneratePaymentInvoice_BasePath tests that the status URL honors the
+// server's base path.
+func TestGeneratePaymentInvoice_BasePath(t *testing.T) {
+	cfg := &config.PaymentGatewayConfig{
+		ServiceWallet:  "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+		ServiceNetwork: "mainnet",
+		FeeAmount:      1000000,
+		PaymentTimeout: 24 * time.Hour,
+		MemoPrefix:     "forohtoo-reg:",
+	}
+
+	invoice, err := generatePaymentInvoice(cfg, "/forohtoo", "TestWalletAddress123456789012345678901234", "mainnet", "sol", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	if err != nil {
+		t.Fatalf("generatePaymentInvoice failed: %v", err)
+	}
+
+	expectedStatusURL := "/forohtoo/api/v1/registration-status/payment-registration:" + invoice.ID
+	if invoice.StatusURL != expectedStatusURL {
+		t.Errorf("Expected StatusURL %q, got %q", expectedStatusURL, invoice.StatusURL)
+	}
+}
+
+// TestGeneratePaymentInvoice_GracePeriod tests that the invoice displays the
+// shorter invoice expiry while advertising the later acceptance deadline.
+func TestGeneratePaymentInvoice_GracePeriod(t *testing.T) {
+	cfg := &config.PaymentGatewayConfig{
+		ServiceWallet:  "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+		ServiceNetwork: "mainnet",
+		FeeAmount:      1000000,
+		PaymentTimeout: 24 * time.Hour,
+		InvoiceExpiry:  30 * time.Minute,
+		GracePeriod:    10 * time.Minute,
+		MemoPrefix:     "forohtoo-reg:",
+	}
+
+	invoice, err := generatePaymentInvoice(cfg, "", "TestWalletAddress123456789012345678901234", "mainnet", "sol", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	if err != nil {
+		t.Fatalf("generatePaymentInvoice failed: %v", err)
+	}
+
+	if invoice.Timeout != 30*time.Minute {
+		t.Errorf("Expected Timeout 30m, got %v", invoice.Timeout)
+	}
+	if got := invoice.ExpiresAt.Sub(invoice.CreatedAt); got != 30*time.Minute {
+		t.Errorf("Expected ExpiresAt 30m after creation, got %v", got)
+	}
+	if got := invoice.AcceptUntil.Sub(invoice.ExpiresAt); got != 10*time.Minute {
+		t.Errorf("Expected AcceptUntil 10m after ExpiresAt, got %v", got)
+	}
+}
+
 // TestBuildSolanaPayURL tests Solana Pay URL generation for USDC.
 func TestBuildSolanaPayURL(t *testing.T) {
-	recipient := "FoRoHtOoWaLLeTaDdReSs1234567890123456789012"
+	recipient := "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
 	amount := int64(1000000) // 1 USDC
 	usdcMint := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
 	memo := "forohtoo-reg:test-invoice-123"
 
-	paymentURL := buildSolanaPayURL(recipient, amount, usdcMint, memo)
+	paymentURL := buildSolanaPayURL(recipient, amount, 6, usdcMint, memo)
 
 	// Verify URL starts with solana: scheme
 	if !strings.HasPrefix(paymentURL, "solana:") {
@@ -172,6 +233,178 @@ func TestBuildSolanaPayURL(t *testing.T) {
 	}
 }
 
+// TestGeneratePaymentInvoice_SOLFee tests invoice generation when fees are
+// charged in SOL: no token mint, payment goes straight to the service wallet,
+// and amounts use SOL's 9 decimals.
+func TestGeneratePaymentInvoice_SOLFee(t *testing.T) {
+	cfg := &config.PaymentGatewayConfig{
+		ServiceWallet:  "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+		ServiceNetwork: "devnet",
+		FeeAssetType:   "sol",
+		FeeAmount:      10000000, // 0.01 SOL
+		PaymentTimeout: 24 * time.Hour,
+		MemoPrefix:     "forohtoo-reg:",
+	}
+
+	invoice, err := generatePaymentInvoice(cfg, "", "TestWalletAddress123456789012345678901234", "mainnet", "sol", "")
+	if err != nil {
+		t.Fatalf("generatePaymentInvoice failed: %v", err)
+	}
+
+	if invoice.AssetType != "sol" || invoice.TokenMint != "" {
+		t.Errorf("Expected sol asset without mint, got %q/%q", invoice.AssetType, invoice.TokenMint)
+	}
+	if invoice.PayToAccount != cfg.ServiceWallet {
+		t.Errorf("Expected PayToAccount to be the service wallet %q, got %q", cfg.ServiceWallet, invoice.PayToAccount)
+	}
+	if invoice.Decimals != 9 || invoice.AmountUI != 0.01 {
+		t.Errorf("Expected 0.01 SOL with 9 decimals, got %v with %d decimals", invoice.AmountUI, invoice.Decimals)
+	}
+
+	params, err := url.ParseQuery(strings.SplitN(invoice.PaymentURL, "?", 2)[1])
+	if err != nil {
+		t.Fatalf("Failed to parse URL params: %v", err)
+	}
+	if params.Has("spl-token") {
+		t.Errorf("SOL payment URL must not have an spl-token parameter, got %q", invoice.PaymentURL)
+	}
+	if params.Get("amount") != "0.010000000" {
+		t.Errorf("Expected amount=0.010000000, got %q", params.Get("amount"))
+	}
+}
+
+// TestGeneratePaymentInvoice_CustomTokenFee tests invoice generation for a
+// non-USDC SPL token with its own decimals.
+func TestGeneratePaymentInvoice_CustomTokenFee(t *testing.T) {
+	bonkMint := "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+	cfg := &config.PaymentGatewayConfig{
+		ServiceWallet:  "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+		ServiceNetwork: "mainnet",
+		FeeAssetType:   "spl-token",
+		FeeMint:        bonkMint,
+		FeeDecimals:    5,
+		FeeAmount:      150000, // 1.5 tokens
+		PaymentTimeout: 24 * time.Hour,
+		MemoPrefix:     "forohtoo-reg:",
+	}
+
+	invoice, err := generatePaymentInvoice(cfg, "", "TestWalletAddress123456789012345678901234", "mainnet", "sol", bonkMint)
+	if err != nil {
+		t.Fatalf("generatePaymentInvoice failed: %v", err)
+	}
+
+	if invoice.TokenMint != bonkMint || invoice.AmountUI != 1.5 {
+		t.Errorf("Expected 1.5 of %s, got %v of %s", bonkMint, invoice.AmountUI, invoice.TokenMint)
+	}
+	if !strings.Contains(invoice.PaymentURL, "amount=1.50000") {
+		t.Errorf("Expected amount with 5 decimals in %q", invoice.PaymentURL)
+	}
+}
+
+// TestGeneratePaymentInvoice_PerNetworkFee tests that the invoice charges the
+// fee for the network the wallet is being registered on.
+func TestGeneratePaymentInvoice_PerNetworkFee(t *testing.T) {
+	mainnetFee := int64(5000000) // 5 USDC
+	devnetFee := int64(10000)    // 0.01 USDC
+	cfg := &config.PaymentGatewayConfig{
+		ServiceWallet:    "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+		ServiceNetwork:   "mainnet",
+		FeeAmount:        1000000,
+		FeeAmountMainnet: &mainnetFee,
+		FeeAmountDevnet:  &devnetFee,
+		PaymentTimeout:   24 * time.Hour,
+		MemoPrefix:       "forohtoo-reg:",
+	}
+
+	tests := []struct {
+		network  string
+		amount   int64
+		amountUI string
+	}{
+		{"mainnet", 5000000, "amount=5.000000"},
+		{"devnet", 10000, "amount=0.010000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			invoice, err := generatePaymentInvoice(cfg, "", "TestWalletAddress123456789012345678901234", tt.network, "sol", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+			if err != nil {
+				t.Fatalf("generatePaymentInvoice failed: %v", err)
+			}
+			if invoice.Amount != tt.amount {
+				t.Errorf("Expected Amount %d, got %d", tt.amount, invoice.Amount)
+			}
+			if !strings.Contains(invoice.PaymentURL, tt.amountUI) {
+				t.Errorf("Expected %s in %q", tt.amountUI, invoice.PaymentURL)
+			}
+			// Payment is always made on the service network.
+			if invoice.Network != "mainnet" {
+				t.Errorf("Expected invoice on the service network, got %q", invoice.Network)
+			}
+		})
+	}
+}
+
+// TestGeneratePaymentInvoice_FeeTiers tests that the invoice charges the fee
+// tier matching the registered wallet's asset type and says why.
+func TestGeneratePaymentInvoice_FeeTiers(t *testing.T) {
+	cfg := &config.PaymentGatewayConfig{
+		ServiceWallet:  "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK",
+		ServiceNetwork: "mainnet",
+		FeeAmount:      1000000,
+		FeeTiers: []config.FeeTier{
+			{Name: "spl", AssetType: "spl-token", Amount: 2000000},
+		},
+		PaymentTimeout: 24 * time.Hour,
+		MemoPrefix:     "forohtoo-reg:",
+	}
+
+	tests := []struct {
+		assetType string
+		amount    int64
+		tier      string
+		reason    string
+	}{
+		{"spl-token", 2000000, "spl", `fee tier "spl" matches asset_type=spl-token`},
+		{"sol", 1000000, "", "default fee"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.assetType, func(t *testing.T) {
+			invoice, err := generatePaymentInvoice(cfg, "", "TestWalletAddress123456789012345678901234", "mainnet", tt.assetType, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+			if err != nil {
+				t.Fatalf("generatePaymentInvoice failed: %v", err)
+			}
+			if invoice.Amount != tt.amount {
+				t.Errorf("Expected Amount %d, got %d", tt.amount, invoice.Amount)
+			}
+			if invoice.FeeTier != tt.tier {
+				t.Errorf("Expected FeeTier %q, got %q", tt.tier, invoice.FeeTier)
+			}
+			if invoice.FeeReason != tt.reason {
+				t.Errorf("Expected FeeReason %q, got %q", tt.reason, invoice.FeeReason)
+			}
+		})
+	}
+}
+
+// TestGeneratePaymentInvoice_InvalidServiceWallet tests that an unusable
+// service wallet is reported instead of producing an invoice without a
+// pay-to token account.
+func TestGeneratePaymentInvoice_InvalidServiceWallet(t *testing.T) {
+	cfg := &config.PaymentGatewayConfig{
+		ServiceWallet:  "not-a-base58-address",
+		ServiceNetwork: "mainnet",
+		FeeAmount:      1000000,
+		PaymentTimeout: 24 * time.Hour,
+		MemoPrefix:     "forohtoo-reg:",
+	}
+
+	if _, err := generatePaymentInvoice(cfg, "", "TestWalletAddress123456789012345678901234", "mainnet", "sol", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"); err == nil {
+		t.Error("Expected error for invalid service wallet, got nil")
+	}
+}
+
 // TestGenerateQRCode tests QR code generation.
 func TestGenerateQRCode(t *testing.T) {
 	testURL := "solana:TestWallet?amount=1.0&memo=test"
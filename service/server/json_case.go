@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// jsonCaseHeader selects the case of JSON response field names. The API is
+// snake_case; web frontends can send "camel" to get camelCase instead.
+const jsonCaseHeader = "X-Forohtoo-JSON-Case"
+
+// opaqueJSONFields hold client-supplied JSON, which is returned as stored
+// whatever the requested case.
+var opaqueJSONFields = map[string]bool{
+	"metadata": true,
+}
+
+// dataKeyedJSONFields hold maps keyed by data (statuses, networks, mints)
+// rather than field names. Their keys are returned as-is; their values are
+// still converted.
+var dataKeyedJSONFields = map[string]bool{
+	"by_status":     true,
+	"by_network":    true,
+	"by_asset_type": true,
+	"networks":      true,
+	"totals":        true,
+}
+
+// jsonCaseMiddleware marks requests that ask for camelCase field names, so
+// writeJSON converts their responses. Other requests pass through untouched.
+func jsonCaseMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", jsonCaseHeader)
+		if !strings.EqualFold(strings.TrimSpace(r.Header.Get(jsonCaseHeader)), "camel") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&camelCaseWriter{ResponseWriter: w}, r)
+	})
+}
+
+// camelCaseWriter marks a response whose JSON field names writeJSON should
+// convert to camelCase. Writes pass through unchanged.
+type camelCaseWriter struct {
+	http.ResponseWriter
+}
+
+// Flush passes through to the underlying writer, so streams still flush.
+func (w *camelCaseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *camelCaseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// wantsCamelCase reports whether w, or a writer it wraps, is a
+// camelCaseWriter. Middleware inside jsonCaseMiddleware (compression, audit)
+// wraps the writer again, so the Unwrap chain is followed.
+func wantsCamelCase(w http.ResponseWriter) bool {
+	for {
+		switch v := w.(type) {
+		case *camelCaseWriter:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = v.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// marshalCamelCase encodes v as JSON with every object key converted from
+// snake_case to camelCase, except inside opaqueJSONFields and the keys of
+// dataKeyedJSONFields. Numbers are kept
+// verbatim so large integers don't lose precision.
+func marshalCamelCase(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(camelCaseKeys(decoded)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func camelCaseKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			switch {
+			case opaqueJSONFields[key]:
+				out[snakeToCamel(key)] = value
+			case dataKeyedJSONFields[key]:
+				out[snakeToCamel(key)] = camelCaseValues(value)
+			default:
+				out[snakeToCamel(key)] = camelCaseKeys(value)
+			}
+		}
+		return out
+	case []interface{}:
+		for i := range v {
+			v[i] = camelCaseKeys(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// camelCaseValues is camelCaseKeys for a map keyed by data: its keys are kept
+// and only its values are converted. Anything else is converted as usual.
+func camelCaseValues(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return camelCaseKeys(v)
+	}
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		out[key] = camelCaseKeys(value)
+	}
+	return out
+}
+
+// snakeToCamel converts a snake_case name to camelCase, e.g. "wallet_address"
+// to "walletAddress". Names without underscores are returned as-is.
+func snakeToCamel(s string) string {
+	if !strings.Contains(s, "_") {
+		return s
+	}
+	parts := strings.Split(s, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
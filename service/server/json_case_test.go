@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveJSONCase writes body through jsonCaseMiddleware and compression, as
+// the server does, and decodes the response.
+func serveJSONCase(t *testing.T, jsonCase string, body interface{}) map[string]interface{} {
+	t.Helper()
+	handler := jsonCaseMiddleware(compressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, body, http.StatusOK)
+	}), 0))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if jsonCase != "" {
+		req.Header.Set(jsonCaseHeader, jsonCase)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Values("Vary"), jsonCaseHeader)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	return decoded
+}
+
+func TestJSONCase_Wallet(t *testing.T) {
+	ata := "ATA1111111111111111111111111111111111111111"
+	wallet := walletToResponse(&db.Wallet{
+		Address:                "wallet1",
+		Network:                "mainnet",
+		AssetType:              "spl-token",
+		TokenMint:              "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		AssociatedTokenAddress: &ata,
+		Status:                 "active",
+		CreatedAt:              time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Metadata:               json.RawMessage(`{"order_id": 42}`),
+	})
+
+	snake := serveJSONCase(t, "", wallet)
+	assert.Equal(t, "spl-token", snake["asset_type"])
+	assert.Equal(t, ata, snake["associated_token_address"])
+	assert.NotContains(t, snake, "assetType")
+
+	camel := serveJSONCase(t, "camel", wallet)
+	assert.Equal(t, "spl-token", camel["assetType"])
+	assert.Equal(t, "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", camel["tokenMint"])
+	assert.Equal(t, ata, camel["associatedTokenAddress"])
+	assert.Equal(t, "2026-01-01T00:00:00Z", camel["createdAt"])
+	assert.NotContains(t, camel, "asset_type")
+	assert.Equal(t, map[string]interface{}{"order_id": float64(42)}, camel["metadata"], "client metadata keeps its keys")
+}
+
+func TestJSONCase_Transactions(t *testing.T) {
+	memo := "inv-1"
+	txn := transactionToResponse(&db.Transaction{
+		Signature:     "sig1",
+		WalletAddress: "wallet1",
+		Amount:        9007199254740993, // above 2^53
+		Memo:          &memo,
+	})
+	body := map[string]interface{}{
+		"transactions": []transactionResponse{txn},
+		"count":        1,
+	}
+
+	snake := serveJSONCase(t, "snake", body)
+	first := snake["transactions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "wallet1", first["wallet_address"])
+	assert.Contains(t, first, "memo_truncated")
+
+	camel := serveJSONCase(t, "Camel", body)
+	assert.Equal(t, float64(1), camel["count"])
+	first = camel["transactions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "wallet1", first["walletAddress"])
+	assert.Contains(t, first, "memoTruncated")
+	assert.Contains(t, first, "confirmationStatus")
+	assert.NotContains(t, first, "wallet_address")
+}
+
+func TestMarshalCamelCase_DataKeyedMaps(t *testing.T) {
+	body, err := marshalCamelCase(map[string]interface{}{
+		"wallets": walletSummary{
+			Total:       3,
+			ByStatus:    map[string]int64{"pending_payment": 1, "active": 2},
+			ByNetwork:   map[string]int64{"mainnet": 3},
+			ByAssetType: map[string]int64{"spl_token_2022": 3},
+		},
+		"totals": map[string]int64{"So11111111111111111111111111111111111111112": 5, "usdc_devnet": 1},
+		"networks": map[string][]map[string]string{
+			"main_net": {{"wallet_address": "wallet1"}},
+		},
+		"metadata": map[string]string{"order_id": "42"},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"wallets": {
+			"total": 3,
+			"byStatus": {"pending_payment": 1, "active": 2},
+			"byNetwork": {"mainnet": 3},
+			"byAssetType": {"spl_token_2022": 3}
+		},
+		"totals": {"So11111111111111111111111111111111111111112": 5, "usdc_devnet": 1},
+		"networks": {"main_net": [{"walletAddress": "wallet1"}]},
+		"metadata": {"order_id": "42"}
+	}`, string(body))
+}
+
+func TestMarshalCamelCase_KeepsNumbers(t *testing.T) {
+	body, err := marshalCamelCase(map[string]int64{"block_slot": 9007199254740993})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"blockSlot": 9007199254740993}`, string(body))
+	assert.Contains(t, string(body), "9007199254740993")
+}
+
+func TestSnakeToCamel(t *testing.T) {
+	for in, want := range map[string]string{
+		"address":                  "address",
+		"wallet_address":           "walletAddress",
+		"associated_token_address": "associatedTokenAddress",
+		"qr_code_data":             "qrCodeData",
+		"trailing_":                "trailing",
+	} {
+		assert.Equal(t, want, snakeToCamel(in), in)
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultMaintenanceMessage is returned by blocked routes when maintenance
+// mode was turned on without a message.
+const defaultMaintenanceMessage = "the service is in maintenance mode; try again later"
+
+// maintenanceState is the JSON form of the maintenance flag.
+type maintenanceState struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"` // when maintenance mode was last turned on
+}
+
+// maintenanceMode is the runtime maintenance flag. While it is on, mutating
+// routes answer 503 and reads keep working. It lives in memory, so it
+// applies to this replica only and resets to MAINTENANCE_MODE on restart.
+type maintenanceMode struct {
+	mu    sync.RWMutex
+	state maintenanceState
+}
+
+func newMaintenanceMode(enabled bool, now time.Time) *maintenanceMode {
+	m := &maintenanceMode{}
+	if enabled {
+		m.set(true, "", now)
+	}
+	return m
+}
+
+// get returns the current state.
+func (m *maintenanceMode) get() maintenanceState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// set turns maintenance mode on or off and returns the new state. An empty
+// message while enabling uses defaultMaintenanceMessage.
+func (m *maintenanceMode) set(enabled bool, message string, now time.Time) maintenanceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !enabled {
+		m.state = maintenanceState{}
+		return m.state
+	}
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+	if !m.state.Enabled {
+		m.state.Since = &now
+	}
+	m.state.Enabled = true
+	m.state.Message = message
+	return m.state
+}
+
+// maintenanceMiddleware refuses requests with 503 and code "maintenance"
+// while maintenance mode is on. It wraps mutating routes only.
+func maintenanceMiddleware(next http.Handler, mode *maintenanceMode) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if state := mode.get(); state.Enabled {
+			writeErrorCode(w, state.Message, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGetMaintenance returns a handler that reports the maintenance flag.
+// GET /api/v1/admin/maintenance
+func handleGetMaintenance(mode *maintenanceMode) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, mode.get(), http.StatusOK)
+	})
+}
+
+// handleSetMaintenance returns a handler that turns maintenance mode on or
+// off without a restart. Every change is logged at warn level.
+// PUT /api/v1/admin/maintenance {"enabled": true, "message": "..."}
+func handleSetMaintenance(mode *maintenanceMode, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+		var req struct {
+			Enabled *bool  `json:"enabled"`
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, "invalid request body: must be valid JSON", http.StatusBadRequest)
+			return
+		}
+		if req.Enabled == nil {
+			writeError(w, "enabled is required", http.StatusBadRequest)
+			return
+		}
+
+		state := mode.set(*req.Enabled, req.Message, time.Now().UTC())
+		if state.Enabled {
+			logger.Warn("MAINTENANCE MODE ON: mutating routes return 503",
+				"message", state.Message,
+				"remote_addr", r.RemoteAddr,
+			)
+		} else {
+			logger.Warn("MAINTENANCE MODE OFF: mutating routes accepted again",
+				"remote_addr", r.RemoteAddr,
+			)
+		}
+
+		writeJSON(w, state, http.StatusOK)
+	})
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	cfg := &config.Config{AdminAuthToken: "s3cret"}
+	handler := New(":0", cfg, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	serve := func(method, path, body string, admin bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if admin {
+			req.Header.Set("Authorization", "Bearer s3cret")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Invalid input fails validation before any store access, so a 400 shows
+	// the request reached its handler.
+	mutating := []struct{ method, path, body string }{
+		{http.MethodPost, "/api/v1/wallet-assets", `{}`},
+		{http.MethodDelete, "/api/v1/wallet-assets/bad!addr?network=mainnet", ""},
+		{http.MethodPatch, "/api/v1/transactions/bad!sig/metadata?network=mainnet", `{}`},
+	}
+	reads := []string{
+		"/api/v1/wallet-assets/bad!addr?network=mainnet",
+		"/api/v1/transactions?wallet_address=bad!addr&network=mainnet",
+	}
+
+	for _, m := range mutating {
+		assert.Equal(t, http.StatusBadRequest, serve(m.method, m.path, m.body, false).Code, "%s %s before maintenance", m.method, m.path)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPut, "/api/v1/admin/maintenance", `{"enabled":true}`, false).Code)
+
+	rec := serve(http.MethodPut, "/api/v1/admin/maintenance", `{"enabled":true,"message":"migrating, back at 14:00 UTC"}`, true)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	for _, m := range mutating {
+		rec := serve(m.method, m.path, m.body, false)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "%s %s during maintenance", m.method, m.path)
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "migrating, back at 14:00 UTC", body["error"])
+		assert.Equal(t, "maintenance", body["code"])
+	}
+	for _, path := range reads {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, path, "", false).Code, "GET %s during maintenance", path)
+	}
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/health", "", false).Code)
+
+	rec = serve(http.MethodGet, "/api/v1/admin/maintenance", "", true)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var state maintenanceState
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+	assert.True(t, state.Enabled)
+	assert.NotNil(t, state.Since)
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/api/v1/admin/maintenance", `{"enabled":false}`, true).Code)
+	for _, m := range mutating {
+		assert.Equal(t, http.StatusBadRequest, serve(m.method, m.path, m.body, false).Code, "%s %s after maintenance", m.method, m.path)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/api/v1/admin/maintenance", `{}`, true).Code, "enabled is required")
+}
+
+func TestMaintenanceMode_FromConfig(t *testing.T) {
+	cfg := &config.Config{MaintenanceMode: true}
+	handler := New(":0", cfg, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/wallet-assets/bad!addr?network=mainnet", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), defaultMaintenanceMessage)
+}
+
+func TestMaintenanceModeSet(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mode := newMaintenanceMode(false, start)
+	assert.False(t, mode.get().Enabled)
+
+	state := mode.set(true, "", start)
+	assert.Equal(t, defaultMaintenanceMessage, state.Message)
+	require.NotNil(t, state.Since)
+	assert.Equal(t, start, *state.Since)
+
+	state = mode.set(true, "still migrating", start.Add(time.Hour))
+	assert.Equal(t, "still migrating", state.Message)
+	assert.Equal(t, start, *state.Since, "updating the message keeps the start time")
+
+	state = mode.set(false, "", start.Add(2*time.Hour))
+	assert.Equal(t, maintenanceState{}, state)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// handleUpdateTransactionMetadata returns a handler that replaces the
+// client-supplied metadata on a transaction. Sending "metadata": null clears it.
+// PATCH /api/v1/transactions/{signature}/metadata
+func handleUpdateTransactionMetadata(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature := r.PathValue("signature")
+		if err := validateSignature(signature); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+		var req struct {
+			Network  string          `json:"network"`
+			Metadata json.RawMessage `json:"metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Debug("failed to decode metadata request", "error", err)
+			if strings.Contains(err.Error(), "http: request body too large") {
+				writeError(w, "request body too large: maximum size is 1MB", http.StatusBadRequest)
+				return
+			}
+			writeError(w, "invalid request body: must be valid JSON", http.StatusBadRequest)
+			return
+		}
+		setAuditTarget(r, "", req.Network)
+
+		if err := validateNetwork(req.Network); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		metadata, err := normalizeMetadata(req.Metadata)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		txn, err := store.UpdateTransactionMetadata(r.Context(), signature, req.Network, metadata)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeError(w, "transaction not found", http.StatusNotFound)
+				return
+			}
+			logger.Error("failed to update transaction metadata", "signature", signature, "network", req.Network, "error", err)
+			writeError(w, "failed to update transaction metadata", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("transaction metadata updated", "signature", signature, "network", req.Network, "cleared", metadata == nil)
+
+		writeJSON(w, transactionToResponse(txn), http.StatusOK)
+	})
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "absent", input: "", want: ""},
+		{name: "null", input: "null", want: ""},
+		{name: "object", input: ` {"order_id": "abc"} `, want: `{"order_id": "abc"}`},
+		{name: "array", input: `["a"]`, wantErr: "must be a JSON object"},
+		{name: "string", input: `"hello"`, wantErr: "must be a JSON object"},
+		{name: "too large", input: `{"k": "` + strings.Repeat("x", maxMetadataSize) + `"}`, wantErr: "metadata too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeMetadata([]byte(tt.input))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+// TestUpdateTransactionMetadata_Validation covers the request validation that
+// happens before the store is touched.
+func TestUpdateTransactionMetadata_Validation(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	mux := http.NewServeMux()
+	mux.Handle("PATCH /api/v1/transactions/{signature}/metadata", handleUpdateTransactionMetadata(nil, logger))
+
+	tests := []struct {
+		name      string
+		signature string
+		body      string
+		wantErr   string
+	}{
+		{name: "invalid signature", signature: "not0valid", body: `{"network": "mainnet", "metadata": {}}`, wantErr: "invalid signature format"},
+		{name: "invalid json", signature: testSignature, body: `{`, wantErr: "must be valid JSON"},
+		{name: "missing network", signature: testSignature, body: `{"metadata": {}}`, wantErr: "network is required"},
+		{name: "non-object metadata", signature: testSignature, body: `{"network": "mainnet", "metadata": [1, 2]}`, wantErr: "must be a JSON object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/api/v1/transactions/"+tt.signature+"/metadata", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/helius"
+)
+
+// nativeSOLDecimals is the decimals for transactions with no token mint.
+const nativeSOLDecimals = 9
+
+// mintInfoResolver resolves a mint's on-chain decimals and symbol.
+// Satisfied by *helius.Client.
+type mintInfoResolver interface {
+	GetMintInfo(ctx context.Context, network, mint string) (*helius.MintInfo, error)
+}
+
+// supportedMintResponse is the JSON response format for a supported mint.
+// Configured mints come from the environment (USDC_*_MINT_ADDRESS) and can't
+// be removed through the API.
+type supportedMintResponse struct {
+	Network    string     `json:"network"`
+	Mint       string     `json:"mint"`
+	Configured bool       `json:"configured"`
+	Decimals   *int       `json:"decimals,omitempty"`
+	Symbol     *string    `json:"symbol,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+}
+
+// supportedMintsForNetwork returns the merged set of mints a wallet may register
+// for on the given network: the config-defined mints followed by any mints
+// added through the admin API.
+func supportedMintsForNetwork(ctx context.Context, store *db.Store, cfg *config.Config, network string) ([]string, error) {
+	configured, err := cfg.GetSupportedMints(network)
+	if err != nil {
+		return nil, err
+	}
+
+	registered, err := store.ListSupportedMints(ctx, network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list supported mints: %w", err)
+	}
+
+	seen := make(map[string]bool, len(configured)+len(registered))
+	mints := make([]string, 0, len(configured)+len(registered))
+	for _, m := range configured {
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		mints = append(mints, m)
+	}
+	for _, m := range registered {
+		if seen[m.Mint] {
+			continue
+		}
+		seen[m.Mint] = true
+		mints = append(mints, m.Mint)
+	}
+
+	return mints, nil
+}
+
+// isMintSupported checks a mint against the merged config + registry set.
+func isMintSupported(ctx context.Context, store *db.Store, cfg *config.Config, network string, mint string) (bool, []string, error) {
+	mints, err := supportedMintsForNetwork(ctx, store, cfg, network)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, m := range mints {
+		if m == mint {
+			return true, mints, nil
+		}
+	}
+	return false, mints, nil
+}
+
+// seedSupportedMints writes the config-defined mints into the supported_mints
+// table so the registry reflects everything the server accepts. Mints whose
+// token info hasn't been resolved yet are looked up via resolver (if non-nil).
+func seedSupportedMints(ctx context.Context, store *db.Store, cfg *config.Config, resolver mintInfoResolver, logger *slog.Logger) error {
+	for _, network := range []string{"mainnet", "devnet"} {
+		mints, err := cfg.GetSupportedMints(network)
+		if err != nil {
+			return err
+		}
+		for _, mint := range mints {
+			if mint == "" {
+				continue
+			}
+			added, err := store.AddSupportedMint(ctx, network, mint)
+			if err != nil {
+				return fmt.Errorf("failed to seed supported mint %s on %s: %w", mint, network, err)
+			}
+			if added.Decimals == nil {
+				resolveSupportedMintInfo(ctx, store, resolver, added, logger)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveSupportedMintInfo looks up a registry mint's decimals and symbol
+// on-chain and stores them, updating m in place. It is best-effort: failures
+// are logged and m is left unresolved.
+func resolveSupportedMintInfo(ctx context.Context, store *db.Store, resolver mintInfoResolver, m *db.SupportedMint, logger *slog.Logger) {
+	if resolver == nil {
+		return
+	}
+
+	info, err := resolver.GetMintInfo(ctx, m.Network, m.Mint)
+	if err != nil {
+		logger.Warn("failed to resolve mint info", "network", m.Network, "mint", m.Mint, "error", err)
+		return
+	}
+
+	updated, err := store.SetSupportedMintInfo(ctx, m.Network, m.Mint, info.Decimals, info.Symbol)
+	if err != nil {
+		logger.Warn("failed to store mint info", "network", m.Network, "mint", m.Mint, "error", err)
+		return
+	}
+	*m = *updated
+}
+
+// mintDecimals returns the known decimals for every registry mint, keyed by
+// network and mint (see mintDecimalsKey).
+func mintDecimals(ctx context.Context, store *db.Store) (map[string]int, error) {
+	mints, err := store.ListAllSupportedMints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	decimals := make(map[string]int, len(mints))
+	for _, m := range mints {
+		if m.Decimals != nil {
+			decimals[mintDecimalsKey(m.Network, m.Mint)] = *m.Decimals
+		}
+	}
+	return decimals, nil
+}
+
+func mintDecimalsKey(network, mint string) string {
+	return network + ":" + mint
+}
+
+// transactionDecimals returns the decimals to render a transaction's amount
+// with, or nil if the mint's decimals are unknown.
+func transactionDecimals(t *db.Transaction, decimals map[string]int) *int {
+	if t.TokenMint == nil {
+		d := nativeSOLDecimals
+		return &d
+	}
+	if d, ok := decimals[mintDecimalsKey(t.Network, *t.TokenMint)]; ok {
+		return &d
+	}
+	return nil
+}
+
+// handleListSupportedMints returns a handler that lists the supported mints.
+// GET /api/v1/supported-mints?network={network}
+// The network parameter is optional; without it, mints for all networks are returned.
+func handleListSupportedMints(store *db.Store, cfg *config.Config, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		network := r.URL.Query().Get("network")
+
+		networks := []string{"mainnet", "devnet"}
+		if network != "" {
+			if err := validateNetwork(network); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			networks = []string{network}
+		}
+
+		resp := make([]supportedMintResponse, 0)
+		for _, n := range networks {
+			configured, err := cfg.GetSupportedMints(n)
+			if err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			registered, err := store.ListSupportedMints(r.Context(), n)
+			if err != nil {
+				logger.Error("failed to list supported mints", "network", n, "error", err)
+				writeError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			byMint := make(map[string]*db.SupportedMint, len(registered))
+			for _, m := range registered {
+				byMint[m.Mint] = m
+			}
+
+			seen := make(map[string]bool)
+			for _, m := range configured {
+				if m == "" || seen[m] {
+					continue
+				}
+				seen[m] = true
+				item := supportedMintResponse{Network: n, Mint: m, Configured: true}
+				if reg, ok := byMint[m]; ok {
+					item.CreatedAt = &reg.CreatedAt
+					item.Decimals = reg.Decimals
+					item.Symbol = reg.Symbol
+				}
+				resp = append(resp, item)
+			}
+			for _, m := range registered {
+				if seen[m.Mint] {
+					continue
+				}
+				seen[m.Mint] = true
+				resp = append(resp, supportedMintResponse{
+					Network:   n,
+					Mint:      m.Mint,
+					Decimals:  m.Decimals,
+					Symbol:    m.Symbol,
+					CreatedAt: &m.CreatedAt,
+				})
+			}
+		}
+
+		logger.Debug("supported mints listed", "network", network, "count", len(resp))
+
+		writeJSON(w, map[string]interface{}{
+			"mints": resp,
+		}, http.StatusOK)
+	})
+}
+
+// handleAddSupportedMint returns a handler that adds a mint to the registry.
+// The mint's decimals and symbol are resolved on-chain when resolver is set.
+// POST /api/v1/supported-mints
+func handleAddSupportedMint(store *db.Store, cfg *config.Config, resolver mintInfoResolver, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+		var req struct {
+			Network string `json:"network"`
+			Mint    string `json:"mint"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Debug("failed to decode add supported mint request", "error", err)
+			if strings.Contains(err.Error(), "http: request body too large") {
+				writeError(w, "request body too large: maximum size is 1MB", http.StatusBadRequest)
+				return
+			}
+			writeError(w, "invalid request body: must be valid JSON", http.StatusBadRequest)
+			return
+		}
+		setAuditTarget(r, "", req.Network)
+
+		if err := validateNetwork(req.Network); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Mint == "" {
+			writeError(w, "mint is required", http.StatusBadRequest)
+			return
+		}
+		if err := validateTokenMint(req.Mint); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mint, err := store.AddSupportedMint(r.Context(), req.Network, req.Mint)
+		if err != nil {
+			logger.Error("failed to add supported mint", "network", req.Network, "mint", req.Mint, "error", err)
+			writeError(w, "failed to add supported mint", http.StatusInternalServerError)
+			return
+		}
+
+		if mint.Decimals == nil {
+			resolveSupportedMintInfo(r.Context(), store, resolver, mint, logger)
+		}
+
+		logger.Info("supported mint added", "network", req.Network, "mint", req.Mint, "decimals", mint.Decimals)
+
+		writeJSON(w, supportedMintResponse{
+			Network:    mint.Network,
+			Mint:       mint.Mint,
+			Configured: cfg.IsMintSupported(mint.Network, mint.Mint),
+			Decimals:   mint.Decimals,
+			Symbol:     mint.Symbol,
+			CreatedAt:  &mint.CreatedAt,
+		}, http.StatusCreated)
+	})
+}
+
+// handleRemoveSupportedMint returns a handler that removes a mint from the registry.
+// Existing wallet registrations for the mint are left in place; only new
+// registrations are rejected.
+// DELETE /api/v1/supported-mints/{mint}?network={network}
+func handleRemoveSupportedMint(store *db.Store, cfg *config.Config, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mint := r.PathValue("mint")
+		network := r.URL.Query().Get("network")
+
+		if err := validateNetwork(network); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateAddress(mint); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if cfg.IsMintSupported(network, mint) {
+			writeError(w, "mint is configured via the environment and cannot be removed", http.StatusBadRequest)
+			return
+		}
+
+		removed, err := store.RemoveSupportedMint(r.Context(), network, mint)
+		if err != nil {
+			logger.Error("failed to remove supported mint", "network", network, "mint", mint, "error", err)
+			writeError(w, "failed to remove supported mint", http.StatusInternalServerError)
+			return
+		}
+		if !removed {
+			writeError(w, "supported mint not found", http.StatusNotFound)
+			return
+		}
+
+		logger.Info("supported mint removed", "network", network, "mint", mint)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
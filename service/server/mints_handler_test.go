@@ -0,0 +1,27 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionDecimals(t *testing.T) {
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	unknown := "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+	decimals := map[string]int{mintDecimalsKey("mainnet", usdc): 6}
+
+	sol := transactionDecimals(&db.Transaction{Network: "mainnet"}, decimals)
+	require.NotNil(t, sol)
+	assert.Equal(t, nativeSOLDecimals, *sol)
+
+	got := transactionDecimals(&db.Transaction{Network: "mainnet", TokenMint: &usdc}, decimals)
+	require.NotNil(t, got)
+	assert.Equal(t, 6, *got)
+
+	assert.Nil(t, transactionDecimals(&db.Transaction{Network: "devnet", TokenMint: &usdc}, decimals), "decimals are per network")
+	assert.Nil(t, transactionDecimals(&db.Transaction{Network: "mainnet", TokenMint: &unknown}, decimals))
+	assert.Nil(t, transactionDecimals(&db.Transaction{Network: "mainnet", TokenMint: &unknown}, nil), "nil map after a lookup failure")
+}
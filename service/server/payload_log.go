@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+// Pipeline decisions recorded alongside logged transaction payloads.
+const (
+	payloadUnmatched = "unmatched" // webhook txn touched no registered wallet
+	payloadWritten   = "written"   // stored in the database
+	payloadSkipped   = "skipped"   // already stored (duplicate signature)
+	payloadFailed    = "failed"    // database write failed
+	payloadPublished = "published" // published to NATS
+	payloadSent      = "sent"      // sent to an SSE client
+	payloadFiltered  = "filtered"  // excluded from an SSE client's history by its filters
+)
+
+// payloadLogger logs full transaction payloads with the decision taken at
+// each pipeline stage, so operators can trace a specific signature when
+// diagnosing "my payment wasn't detected" reports. Payloads are large and may
+// contain PII, so logging requires both LOG_TRANSACTION_PAYLOADS=true and
+// LOG_LEVEL=debug. A nil *payloadLogger logs nothing.
+type payloadLogger struct {
+	logger *slog.Logger
+}
+
+// newPayloadLogger returns a payloadLogger, or nil if payload logging is disabled.
+func newPayloadLogger(enabled bool, logger *slog.Logger) *payloadLogger {
+	if !enabled {
+		return nil
+	}
+	return &payloadLogger{logger: logger}
+}
+
+// Log records payload at debug level. The payload is only serialized when
+// debug logging is enabled.
+func (p *payloadLogger) Log(ctx context.Context, stage, decision, signature string, payload any) {
+	if p == nil || !p.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		p.logger.DebugContext(ctx, "failed to serialize transaction payload", "stage", stage, "signature", signature, "error", err)
+		return
+	}
+
+	p.logger.DebugContext(ctx, "transaction payload",
+		"stage", stage,
+		"decision", decision,
+		"signature", signature,
+		"payload", string(data),
+	)
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadLogger(t *testing.T) {
+	payload := map[string]any{"signature": "sig1", "amount": 1000}
+
+	t.Run("logs payload at debug level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		newPayloadLogger(true, logger).Log(context.Background(), "webhook", payloadWritten, "sig1", payload)
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "transaction payload", entry["msg"])
+		assert.Equal(t, "webhook", entry["stage"])
+		assert.Equal(t, payloadWritten, entry["decision"])
+		assert.Equal(t, "sig1", entry["signature"])
+		assert.JSONEq(t, `{"signature":"sig1","amount":1000}`, entry["payload"].(string))
+	})
+
+	t.Run("silent above debug level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+		newPayloadLogger(true, logger).Log(context.Background(), "webhook", payloadWritten, "sig1", payload)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("silent when disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		p := newPayloadLogger(false, logger)
+		assert.Nil(t, p)
+		p.Log(context.Background(), "webhook", payloadWritten, "sig1", payload)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// validatePaymentCheckQuery parses the payment check query parameters. The
+// asset is optional: asset_type "sol" matches native transfers only,
+// "spl-token" requires token_mint, and no asset_type matches any asset.
+func validatePaymentCheckQuery(query url.Values) (db.FindPaymentByMemoParams, error) {
+	params := db.FindPaymentByMemoParams{
+		WalletAddress: query.Get("address"),
+		Network:       query.Get("network"),
+		Memo:          query.Get("memo"),
+	}
+
+	if err := validateAddress(params.WalletAddress); err != nil {
+		return params, err
+	}
+	if err := validateNetwork(params.Network); err != nil {
+		return params, err
+	}
+	if params.Memo == "" {
+		return params, errorf("memo is required")
+	}
+	if len(params.Memo) > maxMemoQueryLength {
+		return params, errorf("memo cannot exceed %d bytes", maxMemoQueryLength)
+	}
+	if !utf8.ValidString(params.Memo) || strings.ContainsRune(params.Memo, 0) {
+		return params, errorf("memo must be valid UTF-8 without NUL bytes")
+	}
+
+	if raw := query.Get("min_amount"); raw != "" {
+		amount, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || amount < 0 {
+			return params, errorf("min_amount must be a non-negative integer")
+		}
+		params.MinAmount = amount
+	}
+
+	assetType := query.Get("asset_type")
+	tokenMint := query.Get("token_mint")
+	switch assetType {
+	case "":
+		if tokenMint != "" {
+			return params, errorf("token_mint requires asset_type 'spl-token'")
+		}
+	case "sol":
+		if tokenMint != "" {
+			return params, errorf("token_mint must not be set for asset_type 'sol'")
+		}
+		params.Asset = "sol"
+	case "spl-token":
+		if tokenMint == "" {
+			return params, errorf("token_mint is required for asset_type 'spl-token'")
+		}
+		if err := validateTokenMint(tokenMint); err != nil {
+			return params, err
+		}
+		params.Asset = tokenMint
+	default:
+		return params, validateAssetType(assetType)
+	}
+
+	return params, nil
+}
+
+// handlePaymentCheck returns a handler that reports whether a payment with
+// the given memo has been received, without waiting for one. It is the
+// one-shot counterpart to streaming with Await. The memo must match exactly;
+// the earliest matching transaction is returned, or 404 if there is none.
+// GET /api/v1/payments/check?address=&network=&memo=&min_amount=&asset_type=&token_mint=
+func handlePaymentCheck(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, err := validatePaymentCheckQuery(r.URL.Query())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		txn, err := store.FindPaymentByMemo(r.Context(), params)
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, "payment not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to check payment", "address", params.WalletAddress, "network", params.Network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		decimals, err := mintDecimals(r.Context(), store)
+		if err != nil {
+			logger.Warn("failed to load mint decimals", "error", err)
+		}
+
+		resp := transactionToResponse(txn)
+		resp.Decimals = transactionDecimals(txn, decimals)
+		writeJSON(w, resp, http.StatusOK)
+	})
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePaymentCheckQuery(t *testing.T) {
+	const wallet = "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
+	const usdc = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	base := func(extra url.Values) url.Values {
+		q := url.Values{"address": {wallet}, "network": {"mainnet"}, "memo": {"order-42"}}
+		for k, v := range extra {
+			q[k] = v
+		}
+		return q
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		params, err := validatePaymentCheckQuery(base(nil))
+		require.NoError(t, err)
+		assert.Equal(t, wallet, params.WalletAddress)
+		assert.Equal(t, "order-42", params.Memo)
+		assert.Equal(t, int64(0), params.MinAmount)
+		assert.Equal(t, "", params.Asset, "any asset")
+	})
+
+	t.Run("sol", func(t *testing.T) {
+		params, err := validatePaymentCheckQuery(base(url.Values{"asset_type": {"sol"}, "min_amount": {"5000"}}))
+		require.NoError(t, err)
+		assert.Equal(t, "sol", params.Asset)
+		assert.Equal(t, int64(5000), params.MinAmount)
+	})
+
+	t.Run("spl token", func(t *testing.T) {
+		params, err := validatePaymentCheckQuery(base(url.Values{"asset_type": {"spl-token"}, "token_mint": {usdc}}))
+		require.NoError(t, err)
+		assert.Equal(t, usdc, params.Asset)
+	})
+
+	tests := []struct {
+		name    string
+		query   url.Values
+		wantErr string
+	}{
+		{"missing address", url.Values{"network": {"mainnet"}, "memo": {"m"}}, "address is required"},
+		{"invalid network", base(url.Values{"network": {"testnet"}}), "network"},
+		{"missing memo", base(url.Values{"memo": {""}}), "memo is required"},
+		{"memo too long", base(url.Values{"memo": {strings.Repeat("x", maxMemoQueryLength+1)}}), "memo cannot exceed"},
+		{"memo with NUL", base(url.Values{"memo": {"a\x00b"}}), "NUL"},
+		{"negative min amount", base(url.Values{"min_amount": {"-1"}}), "min_amount"},
+		{"non-integer min amount", base(url.Values{"min_amount": {"1.5"}}), "min_amount"},
+		{"mint without asset type", base(url.Values{"token_mint": {usdc}}), "token_mint requires"},
+		{"mint with sol", base(url.Values{"asset_type": {"sol"}, "token_mint": {usdc}}), "must not be set"},
+		{"spl token without mint", base(url.Values{"asset_type": {"spl-token"}}), "token_mint is required"},
+		{"unknown asset type", base(url.Values{"asset_type": {"nft"}}), "invalid asset_type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validatePaymentCheckQuery(tt.query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+const (
+	defaultPaymentsWindow = 30 * 24 * time.Hour
+	maxPaymentsWindow     = 366 * 24 * time.Hour
+)
+
+// paymentsQuery is a validated service payment history query.
+type paymentsQuery struct {
+	Network string
+	From    time.Time
+	To      time.Time
+}
+
+// paymentRegistrationResponse is the registration a service payment paid for,
+// resolved from its invoice memo.
+type paymentRegistrationResponse struct {
+	Address    string `json:"address"`     // wallet the invoice was issued for
+	WorkflowID string `json:"workflow_id"` // payment-gated registration workflow
+	Registered bool   `json:"registered"`  // wallet currently has an active (non-deleted) asset on the network
+}
+
+// servicePaymentResponse is one incoming transaction to the service wallet.
+type servicePaymentResponse struct {
+	transactionResponse
+	InvoiceMemo  bool                         `json:"invoice_memo"` // memo carries the invoice memo prefix
+	Registration *paymentRegistrationResponse `json:"registration,omitempty"`
+}
+
+// validatePaymentsQuery parses the payment history query parameters. The
+// network defaults to the service network and the window to the 30 days
+// before now.
+func validatePaymentsQuery(query url.Values, serviceNetwork string, now time.Time) (paymentsQuery, error) {
+	q := paymentsQuery{Network: serviceNetwork, To: now}
+
+	if network := query.Get("network"); network != "" {
+		if err := validateNetwork(network); err != nil {
+			return q, err
+		}
+		q.Network = network
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return q, errorf("invalid to: must be an RFC 3339 timestamp")
+		}
+		q.To = t
+	}
+	q.From = q.To.Add(-defaultPaymentsWindow)
+	if from := query.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return q, errorf("invalid from: must be an RFC 3339 timestamp")
+		}
+		q.From = t
+	}
+	if !q.From.Before(q.To) {
+		return q, errorf("from must be before to")
+	}
+	if q.To.Sub(q.From) > maxPaymentsWindow {
+		return q, errorf("window too large: maximum is 366 days")
+	}
+
+	return q, nil
+}
+
+// invoiceAddress returns the wallet address an invoice memo was issued for.
+// Invoice memos are the memo prefix followed by the invoice ID, which is the
+// address being registered.
+func invoiceAddress(memo *string, prefix string) (string, bool) {
+	if memo == nil || prefix == "" || !strings.HasPrefix(*memo, prefix) {
+		return "", false
+	}
+	address := strings.TrimPrefix(*memo, prefix)
+	if address == "" {
+		return "", false
+	}
+	return address, true
+}
+
+// paymentAssetKey is the key a payment is totalled under: its token mint, or
+// "sol" for native transfers.
+func paymentAssetKey(t *db.Transaction) string {
+	if t.TokenMint == nil || *t.TokenMint == "" {
+		return "sol"
+	}
+	return *t.TokenMint
+}
+
+// handleListServicePayments returns a handler that lists the transactions
+// received by the payment gateway's service wallet, for reconciling fee
+// income. Payments carrying an invoice memo are linked to the registration
+// they paid for.
+// GET /api/v1/admin/payments?network=&from=&to=
+func handleListServicePayments(store *db.Store, cfg *config.Config, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gateway := cfg.PaymentGateway
+		if !gateway.Enabled || gateway.ServiceWallet == "" {
+			writeError(w, "payment gateway is not enabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		q, err := validatePaymentsQuery(r.URL.Query(), gateway.ServiceNetwork, time.Now().UTC())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		transactions, err := store.ListTransactionsByWalletAndTimeRange(r.Context(), db.ListTransactionsByWalletAndTimeRangeParams{
+			WalletAddress: gateway.ServiceWallet,
+			Network:       q.Network,
+			StartTime:     q.From,
+			EndTime:       q.To,
+		})
+		if err != nil {
+			logger.Error("failed to list service payments", "network", q.Network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		decimals, err := mintDecimals(r.Context(), store)
+		if err != nil {
+			logger.Warn("failed to load mint decimals", "error", err)
+		}
+
+		// Several payments may reference the same invoice (e.g. a retry after
+		// an underpayment), so look each registration up once.
+		registered := make(map[string]bool)
+		payments := make([]servicePaymentResponse, len(transactions))
+		totals := make(map[string]int64)
+		for i, t := range transactions {
+			payments[i].transactionResponse = transactionToResponse(t)
+			payments[i].Decimals = transactionDecimals(t, decimals)
+			totals[paymentAssetKey(t)] += t.Amount
+
+			address, ok := invoiceAddress(t.Memo, gateway.MemoPrefix)
+			if !ok {
+				continue
+			}
+			payments[i].InvoiceMemo = true
+
+			isRegistered, seen := registered[address]
+			if !seen {
+				assets, err := store.ListWalletAssets(r.Context(), address, q.Network, false)
+				if err != nil {
+					logger.Error("failed to look up paid registration", "address", address, "error", err)
+					writeError(w, "internal server error", http.StatusInternalServerError)
+					return
+				}
+				isRegistered = len(assets) > 0
+				registered[address] = isRegistered
+			}
+			payments[i].Registration = &paymentRegistrationResponse{
+				Address:    address,
+				WorkflowID: fmt.Sprintf("payment-registration:%s", address),
+				Registered: isRegistered,
+			}
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"service_wallet": gateway.ServiceWallet,
+			"network":        q.Network,
+			"from":           q.From,
+			"to":             q.To,
+			"payments":       payments,
+			"count":          len(payments),
+			"totals":         totals,
+		}, http.StatusOK)
+	})
+}
+
+// adminAuthMiddleware requires "Authorization: Bearer <token>" on admin routes
+// that expose operator-only data. With no token configured the route is
+// unavailable rather than open.
+func adminAuthMiddleware(next http.Handler, token string, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			writeError(w, "admin auth is not configured", http.StatusServiceUnavailable)
+			return
+		}
+		if !hasAdminToken(r, token) {
+			logger.Warn("admin auth failed", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			writeError(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hasAdminToken reports whether r carries "Authorization: Bearer <token>". It
+// is false when no token is configured.
+func hasAdminToken(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePaymentsQuery(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("defaults", func(t *testing.T) {
+		q, err := validatePaymentsQuery(url.Values{}, "mainnet", now)
+		require.NoError(t, err)
+		assert.Equal(t, "mainnet", q.Network)
+		assert.Equal(t, now.Add(-30*24*time.Hour), q.From)
+		assert.Equal(t, now, q.To)
+	})
+
+	t.Run("explicit window", func(t *testing.T) {
+		q, err := validatePaymentsQuery(url.Values{
+			"network": {"devnet"},
+			"from":    {"2025-05-01T00:00:00Z"},
+			"to":      {"2025-05-31T00:00:00Z"},
+		}, "mainnet", now)
+		require.NoError(t, err)
+		assert.Equal(t, "devnet", q.Network)
+		assert.Equal(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), q.From)
+		assert.Equal(t, time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC), q.To)
+	})
+
+	errTests := []struct {
+		name    string
+		query   url.Values
+		wantErr string
+	}{
+		{"bad network", url.Values{"network": {"testnet"}}, "network"},
+		{"bad from", url.Values{"from": {"yesterday"}}, "invalid from"},
+		{"bad to", url.Values{"to": {"1717243200"}}, "invalid to"},
+		{"reversed window", url.Values{"from": {"2025-06-01T12:00:00Z"}, "to": {"2025-06-01T11:00:00Z"}}, "from must be before to"},
+		{"window too large", url.Values{"from": {"2023-01-01T00:00:00Z"}}, "window too large"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validatePaymentsQuery(tt.query, "mainnet", now)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestInvoiceAddress(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name   string
+		memo   *string
+		want   string
+		wantOK bool
+	}{
+		{"invoice memo", str("forohtoo-reg:wallet1"), "wallet1", true},
+		{"no memo", nil, "", false},
+		{"other memo", str(`{"order_id": 1}`), "", false},
+		{"prefix only", str("forohtoo-reg:"), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := invoiceAddress(tt.memo, "forohtoo-reg:")
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(token, header string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/payments", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		adminAuthMiddleware(next, token, webhookTestLogger()).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("s3cret", "Bearer s3cret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", "Bearer wrong"))
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", "s3cret"), "bare token must be rejected")
+	assert.Equal(t, http.StatusServiceUnavailable, serve("", "Bearer "), "unset token must not open the route")
+}
+
+func TestHandleListServicePayments_GatewayDisabled(t *testing.T) {
+	handler := handleListServicePayments(nil, &config.Config{}, webhookTestLogger())
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/payments", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "payment gateway is not enabled")
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitPruneInterval is how often idle buckets are swept.
+const rateLimitPruneInterval = time.Minute
+
+// rateLimiter is a token bucket per client. Each bucket holds up to burst
+// tokens and refills at perMinute tokens a minute; a request takes one. It is
+// safe for concurrent use.
+type rateLimiter struct {
+	rate           float64 // tokens per second
+	burst          float64
+	trustedProxies []netip.Prefix // peers whose forwarding headers name the client
+	now            func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time // when tokens was last brought up to date
+}
+
+func newRateLimiter(perMinute, burst int, trustedProxies []netip.Prefix) *rateLimiter {
+	return &rateLimiter{
+		rate:           float64(perMinute) / 60,
+		burst:          float64(burst),
+		trustedProxies: trustedProxies,
+		now:            time.Now,
+		buckets:        make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from key's bucket. When the bucket is empty it returns
+// false and how long until a token is available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastPrune) >= rateLimitPruneInterval {
+		l.prune(now)
+		l.lastPrune = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune drops buckets that have refilled completely. A full bucket behaves
+// like a missing one, so this frees memory without changing any outcome.
+func (l *rateLimiter) prune(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitKey identifies the client a request is counted against: its
+// stored API key when it used one, else its IP address.
+func rateLimitKey(r *http.Request, trustedProxies []netip.Prefix) string {
+	if name, ok := apiKeyName(r.Context()); ok {
+		return "key:" + name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !isTrustedProxy(peer, trustedProxies) {
+		return "ip:" + host
+	}
+	if client, ok := forwardedClientIP(r, trustedProxies); ok {
+		return "ip:" + client.String()
+	}
+	return "ip:" + host
+}
+
+// forwardedClientIP returns the client a trusted proxy forwarded the request
+// for. X-Forwarded-For is read right to left, since each proxy appends the
+// peer it saw, and the first hop that isn't a trusted proxy is the client;
+// entries further left were supplied by the client and can be forged.
+// X-Real-IP is used when there is no X-Forwarded-For.
+func forwardedClientIP(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !isTrustedProxy(client, trustedProxies) {
+			return client, true
+		}
+	}
+	if client.IsValid() {
+		// Every parsed hop was a trusted proxy: the left-most is closest
+		// to the client.
+		return client, true
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+// isTrustedProxy reports whether addr is in one of the trusted proxy ranges.
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitMiddleware answers 429 with a Retry-After header (whole seconds)
+// once a client has used up its requests. A nil limiter disables it.
+func rateLimitMiddleware(next http.Handler, limiter *rateLimiter, logger *slog.Logger) http.Handler {
+	if limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := rateLimitKey(r, limiter.trustedProxies)
+		ok, wait := limiter.allow(key)
+		if !ok {
+			logger.Warn("rate limit exceeded", "path", r.URL.Path, "client", key)
+			w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(wait.Seconds())))))
+			writeError(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
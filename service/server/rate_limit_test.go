@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	const burst, requests = 3, 10
+	limiter := newRateLimiter(6, burst, nil) // a token every 10s
+	now := time.Unix(1700000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	handler := rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}), limiter, webhookTestLogger())
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var allowed, rejected int
+	for range requests {
+		rec := serve("192.0.2.1:1234")
+		switch rec.Code {
+		case http.StatusCreated:
+			allowed++
+		case http.StatusTooManyRequests:
+			rejected++
+			assert.Equal(t, "10", rec.Header().Get("Retry-After"))
+		default:
+			t.Fatalf("unexpected status %d", rec.Code)
+		}
+	}
+	assert.Equal(t, burst, allowed)
+	assert.Equal(t, requests-burst, rejected)
+
+	// Another address has its own bucket; another port on the same one
+	// doesn't.
+	assert.Equal(t, http.StatusCreated, serve("192.0.2.2:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("192.0.2.1:5678").Code)
+
+	// A token comes back after 10s.
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, http.StatusCreated, serve("192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("192.0.2.1:1234").Code)
+}
+
+func TestRateLimitMiddleware_BatchEntries(t *testing.T) {
+	const burst, entries = 2, 5
+	cfg := &config.Config{RegistrationRateLimit: 1, RegistrationBurst: burst}
+	handler := New(":0", cfg, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	// Entries fail validation before any store access, but only once they've
+	// got past the limiter.
+	entry := `{"address": "bad!addr", "network": "mainnet", "asset": {"type": "sol"}}`
+	body := "[" + strings.Repeat(entry+",", entries-1) + entry + "]"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets/batch", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	var resp batchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	statuses := make(map[int]int)
+	for _, res := range resp.Results {
+		statuses[res.Status]++
+	}
+	assert.Equal(t, map[int]int{http.StatusBadRequest: burst, http.StatusTooManyRequests: entries - burst}, statuses)
+
+	// The batch used up the client's tokens for single registrations too.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", strings.NewReader(entry)))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestRateLimitMiddleware_Disabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := rateLimitMiddleware(next, nil, webhookTestLogger())
+	for range 100 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil)
+	req.RemoteAddr = "[2001:db8::1]:443"
+	assert.Equal(t, "ip:2001:db8::1", rateLimitKey(req, nil))
+
+	req = req.WithContext(context.WithValue(req.Context(), apiKeyNameKey{}, "billing"))
+	assert.Equal(t, "key:billing", rateLimitKey(req, nil))
+}
+
+func TestRateLimitKey_TrustedProxies(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.42.0.0/16")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{
+			name:       "untrusted peer's headers are ignored",
+			remoteAddr: "203.0.113.9:5000",
+			forwarded:  []string{"198.51.100.1"},
+			realIP:     "198.51.100.1",
+			want:       "ip:203.0.113.9",
+		},
+		{
+			name:       "forwarded client",
+			remoteAddr: "10.42.0.5:5000",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "ip:198.51.100.1",
+		},
+		{
+			name:       "forged entries left of the client are ignored",
+			remoteAddr: "10.42.0.5:5000",
+			forwarded:  []string{"1.2.3.4, 198.51.100.1"},
+			want:       "ip:198.51.100.1",
+		},
+		{
+			name:       "trusted hops are skipped",
+			remoteAddr: "10.42.0.5:5000",
+			forwarded:  []string{"198.51.100.1, 10.42.3.3", "10.42.0.9"},
+			want:       "ip:198.51.100.1",
+		},
+		{
+			name:       "real IP without forwarded for",
+			remoteAddr: "10.42.0.5:5000",
+			realIP:     "2001:db8::7",
+			want:       "ip:2001:db8::7",
+		},
+		{
+			name:       "no forwarding headers",
+			remoteAddr: "10.42.0.5:5000",
+			want:       "ip:10.42.0.5",
+		},
+		{
+			name:       "malformed forwarded for",
+			remoteAddr: "10.42.0.5:5000",
+			forwarded:  []string{"unknown"},
+			want:       "ip:10.42.0.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			assert.Equal(t, tt.want, rateLimitKey(req, trusted))
+		})
+	}
+}
+
+func TestRateLimitMiddleware_BehindProxy(t *testing.T) {
+	cfg := &config.Config{
+		RegistrationRateLimit: 1,
+		RegistrationBurst:     1,
+		TrustedProxies:        []netip.Prefix{netip.MustParsePrefix("10.42.0.0/16")},
+	}
+	handler := New(":0", cfg, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	// Every request arrives from the ingress; the forwarded client decides
+	// the bucket. Validation fails before any store access.
+	serve := func(client string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet-assets", strings.NewReader(`{"address": "bad!addr"}`))
+		req.RemoteAddr = "10.42.0.5:5000"
+		req.Header.Set("X-Forwarded-For", client)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, serve("198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("198.51.100.1"))
+	assert.Equal(t, http.StatusBadRequest, serve("198.51.100.2"), "another client behind the ingress has its own bucket")
+}
+
+func TestRateLimiter_Prune(t *testing.T) {
+	limiter := newRateLimiter(60, 2, nil) // refills completely in 2s
+	now := time.Unix(1700000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	limiter.allow("a")
+	now = now.Add(30 * time.Second)
+	limiter.allow("b")
+	assert.Len(t, limiter.buckets, 2, "not swept until the prune interval has passed")
+
+	now = now.Add(rateLimitPruneInterval)
+	limiter.allow("c")
+	assert.Len(t, limiter.buckets, 1, "full buckets are dropped")
+	assert.Contains(t, limiter.buckets, "c")
+}
+
+func TestRateLimiter_Concurrent(t *testing.T) {
+	limiter := newRateLimiter(1, 50, nil)
+	var mu sync.Mutex
+	var allowed int
+	var wg sync.WaitGroup
+	for range 200 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := limiter.allow("client"); ok {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, allowed)
+}
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,6 +15,7 @@ import (
 	"github.com/brojonat/forohtoo/service/metrics"
 	natspkg "github.com/brojonat/forohtoo/service/nats"
 	"github.com/brojonat/forohtoo/service/temporal"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -27,6 +30,8 @@ type Server struct {
 	ssePublisher   *SSEPublisher
 	renderer       *TemplateRenderer
 	metrics        *metrics.Metrics
+	maintenance    *maintenanceMode
+	registrations  *rateLimiter // nil when registration isn't rate limited
 	logger         *slog.Logger
 	server         *http.Server
 }
@@ -37,6 +42,10 @@ type Server struct {
 // The natsPublisher is used by the webhook handler to publish events.
 // The ssePublisher is optional - if nil, SSE endpoints won't be available.
 func New(addr string, cfg *config.Config, store *db.Store, temporalClient *temporal.Client, heliusClient *helius.Client, natsPublisher natspkg.Publisher, ssePublisher *SSEPublisher, m *metrics.Metrics, logger *slog.Logger) *Server {
+	var registrations *rateLimiter
+	if cfg.RegistrationRateLimit > 0 {
+		registrations = newRateLimiter(cfg.RegistrationRateLimit, cfg.RegistrationBurst, cfg.TrustedProxies)
+	}
 	return &Server{
 		addr:           addr,
 		cfg:            cfg,
@@ -46,6 +55,8 @@ func New(addr string, cfg *config.Config, store *db.Store, temporalClient *tempo
 		natsPublisher:  natsPublisher,
 		ssePublisher:   ssePublisher,
 		metrics:        m,
+		maintenance:    newMaintenanceMode(cfg.MaintenanceMode, time.Now().UTC()),
+		registrations:  registrations,
 		logger:         logger,
 	}
 }
@@ -63,39 +74,152 @@ func (s *Server) WithTemplates() error {
 
 // Start starts the HTTP server.
 func (s *Server) Start() error {
+	// Seed the supported-mints registry with the config-defined mints. Config
+	// mints are always accepted regardless, so a failure here isn't fatal.
+	if err := seedSupportedMints(context.Background(), s.store, s.cfg, s.mintResolver(), s.logger); err != nil {
+		s.logger.Warn("failed to seed supported mints", "error", err)
+	}
+
 	// Ensure service wallet is registered if payment gateway is enabled
 	if err := s.ensureServiceWalletRegistered(context.Background()); err != nil {
 		return fmt.Errorf("failed to ensure service wallet registered: %w", err)
 	}
 
+	s.server = &http.Server{
+		Addr:         s.addr,
+		Handler:      s.routes(),
+		ReadTimeout:  s.cfg.ServerReadTimeout,
+		WriteTimeout: s.cfg.ServerWriteTimeout,
+		IdleTimeout:  s.cfg.ServerIdleTimeout,
+	}
+
+	s.logger.Info("starting HTTP server",
+		"addr", s.addr,
+		"base_path", s.cfg.BasePath,
+		"read_timeout", s.cfg.ServerReadTimeout,
+		"write_timeout", s.cfg.ServerWriteTimeout,
+		"idle_timeout", s.cfg.ServerIdleTimeout,
+	)
+	if s.cfg.MaintenanceMode {
+		s.logger.Warn("MAINTENANCE MODE ON: mutating routes return 503 until it is turned off")
+	}
+	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("server failed: %w", err)
+	}
+
+	return nil
+}
+
+// routes builds the server's handler. Routes are registered relative to "/"
+// and mounted under cfg.BasePath when one is configured, so the server can be
+// hosted behind a reverse proxy at e.g. /forohtoo.
+func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
+	payloads := newPayloadLogger(s.cfg.LogTransactionPayloads, s.logger)
+	compress, compressStream := s.compression()
+
+	// Wallet asset routes. Registration can start a payment workflow, so it
+	// is rate limited per client; a batch counts as one request.
+	mux.Handle("POST /api/v1/wallet-assets", s.audit("wallet.register", rateLimitMiddleware(handleRegisterWalletAsset(s.store, s.heliusClient, s.temporalClient, s.cfg, s.metrics, s.logger), s.registrations, s.logger)))
+	// Each batch entry takes its own token, so a batch can't register more
+	// wallets than the same number of single requests.
+	mux.Handle("POST /api/v1/wallet-assets/batch", s.audit("wallet.register_batch", handleBatchRegisterWalletAssets(rateLimitMiddleware(handleRegisterWalletAsset(s.store, s.heliusClient, s.temporalClient, s.cfg, s.metrics, s.logger), s.registrations, s.logger), s.logger)))
+	mux.Handle("DELETE /api/v1/wallet-assets/{address}", s.audit("wallet.unregister", handleUnregisterWalletAsset(s.store, s.heliusClient, s.logger)))
+	mux.Handle("GET /api/v1/wallet-assets/{address}", compress(handleGetWalletAsset(s.store, s.logger)))
+	mux.Handle("GET /api/v1/wallet-assets/{address}/all", compress(handleGetWalletAssetsAllNetworks(s.store, s.logger)))
+	mux.Handle("GET /api/v1/wallet-assets", compress(handleListWalletAssets(s.store, s.logger)))
+	mux.Handle("GET /api/v1/transactions", compress(handleListTransactions(s.store, s.logger)))
+	mux.Handle("POST /api/v1/transactions/query", compress(handleQueryTransactions(s.store, s.logger)))
+	mux.Handle("GET /api/v1/transactions/search", compress(handleSearchTransactions(s.store, s.logger)))
+	mux.Handle("GET /api/v1/wallets/{address}/transactions", compress(handleListTransactionsByAmount(s.store, s.logger)))
+	mux.Handle("GET /api/v1/payments/check", handlePaymentCheck(s.store, s.logger))
+	mux.Handle("PATCH /api/v1/transactions/{signature}/metadata", s.audit("transaction.metadata_update", handleUpdateTransactionMetadata(s.store, s.logger)))
+
+	// Named per-wallet transaction filters, applied server-side by SSE streams
+	// (?filter=name) and digest subscriptions
+	mux.Handle("PUT /api/v1/wallet-assets/{address}/filters/{name}", s.audit("filter.save", handleSaveWalletFilter(s.store, s.logger)))
+	mux.Handle("GET /api/v1/wallet-assets/{address}/filters", compress(handleListWalletFilters(s.store, s.logger)))
+	mux.Handle("GET /api/v1/wallet-assets/{address}/filters/{name}", handleGetWalletFilter(s.store, s.logger))
+	mux.Handle("DELETE /api/v1/wallet-assets/{address}/filters/{name}", s.audit("filter.delete", handleDeleteWalletFilter(s.store, s.logger)))
+
+	// Manual recovery of a single transaction a webhook delivery missed (admin,
+	// bearer token required)
+	mux.Handle("POST /api/v1/admin/ingest", s.audit("admin.ingest", adminAuthMiddleware(handleIngestTransaction(s.store, s.transactionFetcher(), s.natsPublisher, s.ingestOptions(), payloads, s.logger), s.cfg.AdminAuthToken, s.logger)))
+
+	// Ingestion volume per time bucket, for throughput charts (admin, bearer
+	// token required)
+	mux.Handle("GET /api/v1/admin/throughput", adminAuthMiddleware(compress(handleThroughput(s.store, s.logger)), s.cfg.AdminAuthToken, s.logger))
+
+	// Ingestion and payment detection latency over a recent window, for a
+	// status page (admin, bearer token required)
+	mux.Handle("GET /api/v1/admin/sla", adminAuthMiddleware(handleSLA(s.store, s.cfg, s.logger), s.cfg.AdminAuthToken, s.logger))
 
-	// Wallet asset routes
-	mux.Handle("POST /api/v1/wallet-assets", handleRegisterWalletAsset(s.store, s.heliusClient, s.temporalClient, s.cfg, s.logger))
-	mux.Handle("DELETE /api/v1/wallet-assets/{address}", handleUnregisterWalletAsset(s.store, s.heliusClient, s.logger))
-	mux.Handle("GET /api/v1/wallet-assets/{address}", handleGetWalletAsset(s.store, s.logger))
-	mux.Handle("GET /api/v1/wallet-assets", handleListWalletAssets(s.store, s.logger))
-	mux.Handle("GET /api/v1/transactions", handleListTransactions(s.store, s.logger))
+	// Fee income received by the service wallet, for reconciliation (admin,
+	// bearer token required)
+	mux.Handle("GET /api/v1/admin/payments", adminAuthMiddleware(compress(handleListServicePayments(s.store, s.cfg, s.logger)), s.cfg.AdminAuthToken, s.logger))
+
+	// Wallets by most recent transaction, for a "recent activity" view (admin,
+	// bearer token required)
+	mux.Handle("GET /api/v1/admin/active-wallets", adminAuthMiddleware(compress(handleListActiveWallets(s.store, s.logger)), s.cfg.AdminAuthToken, s.logger))
+
+	// System overview for a global dashboard (admin, bearer token required)
+	mux.Handle("GET /api/v1/admin/summary", adminAuthMiddleware(compress(handleAdminSummary(s.store, s.logger)), s.cfg.AdminAuthToken, s.logger))
+
+	// Outbound webhook deliveries (payment callbacks) and their status, to
+	// find the ones that failed (admin, bearer token required)
+	mux.Handle("GET /api/v1/admin/webhooks", adminAuthMiddleware(compress(handleListWebhookDeliveries(s.store, s.logger)), s.cfg.AdminAuthToken, s.logger))
+
+	// Maintenance mode: freezes mutating routes without a restart (admin,
+	// bearer token required). The toggle itself is never blocked.
+	mux.Handle("GET /api/v1/admin/maintenance", adminAuthMiddleware(handleGetMaintenance(s.maintenance), s.cfg.AdminAuthToken, s.logger))
+	mux.Handle("PUT /api/v1/admin/maintenance", s.auditLog("admin.maintenance", adminAuthMiddleware(handleSetMaintenance(s.maintenance, s.logger), s.cfg.AdminAuthToken, s.logger)))
+
+	// Audit trail of mutating calls (admin, bearer token required)
+	mux.Handle("GET /api/v1/admin/audit", adminAuthMiddleware(compress(handleListAuditLog(s.store, s.logger)), s.cfg.AdminAuthToken, s.logger))
+
+	// Supported-mints registry (changes need the admin bearer token)
+	mux.Handle("GET /api/v1/supported-mints", compress(handleListSupportedMints(s.store, s.cfg, s.logger)))
+	mux.Handle("POST /api/v1/supported-mints", s.audit("mint.add", adminAuthMiddleware(handleAddSupportedMint(s.store, s.cfg, s.mintResolver(), s.logger), s.cfg.AdminAuthToken, s.logger)))
+	mux.Handle("DELETE /api/v1/supported-mints/{mint}", s.audit("mint.remove", adminAuthMiddleware(handleRemoveSupportedMint(s.store, s.cfg, s.logger), s.cfg.AdminAuthToken, s.logger)))
+
+	// Periodic transaction digest subscriptions (delivered by the Digester,
+	// so only served when digests are enabled)
+	if s.cfg.DigestSigningSecret != "" {
+		mux.Handle("POST /api/v1/digests", s.audit("digest.create", handleCreateDigestSubscription(s.store, s.logger)))
+		mux.Handle("GET /api/v1/digests", compress(handleListDigestSubscriptions(s.store, s.logger)))
+		mux.Handle("DELETE /api/v1/digests/{id}", s.audit("digest.delete", handleDeleteDigestSubscription(s.store, s.logger)))
+	}
+
+	// Per-transaction webhooks (delivered by the TransactionWebhookDispatcher,
+	// so only served when it is enabled)
+	if s.cfg.TransactionWebhooksEnabled {
+		mux.Handle("POST /api/v1/webhooks", s.audit("webhook.create", handleCreateTransactionWebhook(s.store, s.logger)))
+		mux.Handle("GET /api/v1/webhooks", compress(handleListTransactionWebhooks(s.store, s.logger)))
+		mux.Handle("DELETE /api/v1/webhooks/{id}", s.audit("webhook.delete", handleDeleteTransactionWebhook(s.store, s.logger)))
+	}
 
 	// Helius webhook endpoint (receives push notifications from Helius)
-	mux.Handle("POST /api/v1/webhooks/helius", handleHeliusWebhook(s.store, s.natsPublisher, s.cfg.HeliusWebhookAuthToken, s.logger))
+	mux.Handle("POST /api/v1/webhooks/helius", handleHeliusWebhook(s.store, s.natsPublisher, s.cfg.HeliusWebhookAuthToken, s.ingestOptions(), payloads, s.logger))
 
 	// Payment gateway routes (uses Temporal for workflow orchestration)
 	if s.temporalClient != nil {
-		mux.Handle("GET /api/v1/registration-status/{workflow_id}", handleGetRegistrationStatus(s.temporalClient, s.logger))
+		mux.Handle("GET /api/v1/registration-status/{workflow_id}", handleGetRegistrationStatus(s.temporalClient, s.cfg, s.logger))
+		mux.Handle("POST /api/v1/admin/webhooks/{id}/redeliver", s.audit("admin.webhook_redeliver", adminAuthMiddleware(handleRedeliverWebhook(s.store, s.temporalClient.SDKClient(), s.cfg.TemporalTaskQueue, s.logger), s.cfg.AdminAuthToken, s.logger)))
 	}
 
-	// SSE streaming endpoints (if SSE publisher is configured)
+	// SSE streaming endpoints (if SSE publisher is configured). These are the
+	// only long-lived routes, so they're exempt from the server's read/write
+	// timeouts.
 	if s.ssePublisher != nil {
-		mux.Handle("GET /api/v1/stream/transactions/{address}", handleStreamTransactions(s.ssePublisher, s.logger))
-		mux.Handle("GET /api/v1/stream/transactions", handleStreamTransactions(s.ssePublisher, s.logger))
+		mux.Handle("GET /api/v1/stream/transactions/{address}", longLivedMiddleware(compressStream(handleStreamTransactions(s.ssePublisher, payloads, s.logger)), s.logger))
+		mux.Handle("GET /api/v1/stream/transactions", longLivedMiddleware(compressStream(handleStreamTransactions(s.ssePublisher, payloads, s.logger)), s.logger))
 		s.logger.Info("SSE streaming endpoints enabled")
 	}
 
 	// HTML pages (if template renderer is configured)
 	if s.renderer != nil {
-		mux.HandleFunc("GET /", handleSSEClientPage(s.renderer))
-		mux.HandleFunc("GET /stream", handleSSEClientPage(s.renderer))
+		mux.HandleFunc("GET /", handleSSEClientPage(s.renderer, s.cfg.BasePath))
+		mux.HandleFunc("GET /stream", handleSSEClientPage(s.renderer, s.cfg.BasePath))
 		mux.HandleFunc("GET /favicon.ico", handleFavicon())
 		mux.HandleFunc("GET /favicon.svg", handleFavicon())
 	}
@@ -106,31 +230,115 @@ func (s *Server) Start() error {
 		w.Write([]byte("OK"))
 	})
 
+	// RPC connectivity check (not used by probes; see handleRPCHealth)
+	mux.Handle("GET /health/rpc", handleRPCHealth(s.rpcHealthChecker(), s.logger))
+
 	// Prometheus metrics endpoint
 	if s.metrics != nil {
-		mux.Handle("GET /metrics", promhttp.Handler())
+		// OpenMetrics is served to scrapers that negotiate it; it's the only
+		// format that carries exemplars.
+		mux.Handle("GET /metrics", promhttp.InstrumentMetricHandler(
+			prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+		))
 	}
 
-	handler := corsMiddleware(mux)
+	auth := apiKeyAuth{
+		sharedKey:   s.cfg.APIKey,
+		adminToken:  s.cfg.AdminAuthToken,
+		publicReads: s.cfg.APIKeyPublicReads,
+	}
+	if s.cfg.APIKeysEnabled && s.store != nil {
+		auth.keys = s.store
+	}
+	handler := apiKeyMiddleware(mux, auth, s.logger)
+	if s.cfg.BasePath != "" {
+		// Only requests under the base path reach the routes; everything
+		// else (including the unprefixed /api/v1 paths) is a 404.
+		prefixed := http.NewServeMux()
+		prefixed.Handle(s.cfg.BasePath+"/", http.StripPrefix(s.cfg.BasePath, handler))
+		handler = prefixed
+	}
 
-	s.server = &http.Server{
-		Addr:         s.addr,
-		Handler:      handler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	return requestIDMiddleware(corsMiddleware(jsonCaseMiddleware(handler)))
+}
+
+// compression returns the middleware for JSON list/get routes and for SSE
+// streams. Both are pass-throughs when response compression is disabled.
+// Streams compress from the first byte since their length is unknown; JSON
+// responses below the configured minimum go out uncompressed.
+func (s *Server) compression() (compress, compressStream func(http.Handler) http.Handler) {
+	if !s.cfg.ResponseCompressionEnabled {
+		passthrough := func(h http.Handler) http.Handler { return h }
+		return passthrough, passthrough
 	}
+	minSize := s.cfg.ResponseCompressionMinBytes
+	compress = func(h http.Handler) http.Handler { return compressMiddleware(h, minSize) }
+	compressStream = func(h http.Handler) http.Handler { return compressMiddleware(h, 0) }
+	return compress, compressStream
+}
 
-	s.logger.Info("starting HTTP server", "addr", s.addr)
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("server failed: %w", err)
+// mintResolver returns the Helius client as a mintInfoResolver, or nil (not a
+// typed nil) when Helius isn't configured.
+func (s *Server) mintResolver() mintInfoResolver {
+	if s.heliusClient == nil {
+		return nil
 	}
+	return s.heliusClient
+}
 
-	return nil
+// rpcHealthChecker returns the Helius client as an rpcHealthChecker, or nil
+// (not a typed nil) when Helius isn't configured.
+func (s *Server) rpcHealthChecker() rpcHealthChecker {
+	if s.heliusClient == nil {
+		return nil
+	}
+	return s.heliusClient
+}
+
+// transactionFetcher returns the Helius client as a transactionFetcher, or nil
+// (not a typed nil) when Helius isn't configured.
+func (s *Server) transactionFetcher() transactionFetcher {
+	if s.heliusClient == nil {
+		return nil
+	}
+	return s.heliusClient
+}
+
+// audit wraps a mutating route: calls are refused while maintenance mode is
+// on, and recorded in the audit log either way.
+func (s *Server) audit(action string, h http.Handler) http.Handler {
+	return s.auditLog(action, maintenanceMiddleware(h, s.maintenance))
+}
+
+// auditLog records calls to a route in the audit log. Auditing is off when
+// there is no store.
+func (s *Server) auditLog(action string, h http.Handler) http.Handler {
+	if s.store == nil {
+		return h
+	}
+	return auditMiddleware(h, s.store, action, s.cfg.AdminAuthToken, s.logger)
+}
+
+// ingestOptions returns the transaction ingestion settings shared by the
+// webhook and admin ingest handlers.
+func (s *Server) ingestOptions() ingestOptions {
+	opts := ingestOptions{
+		MaxMemoLength:  s.cfg.MaxMemoLength,
+		PaymentIDField: s.cfg.LogicalPaymentIDField,
+		Metrics:        s.metrics,
+		WriteBatchSize: s.cfg.IngestWriteBatchSize,
+		RecordOutgoing: s.cfg.RecordOutgoingTransfers,
+	}
+	if s.cfg.PaymentGateway.Enabled {
+		opts.PaymentWallet = s.cfg.PaymentGateway.ServiceWallet
+		opts.PaymentNetwork = s.cfg.PaymentGateway.ServiceNetwork
+	}
+	return opts
 }
 
 // ensureServiceWalletRegistered ensures the service wallet is registered for monitoring
-// when the payment gateway is enabled.
+// in the fee asset when the payment gateway is enabled.
 func (s *Server) ensureServiceWalletRegistered(ctx context.Context) error {
 	if !s.cfg.PaymentGateway.Enabled {
 		return nil
@@ -138,12 +346,10 @@ func (s *Server) ensureServiceWalletRegistered(ctx context.Context) error {
 
 	serviceWallet := s.cfg.PaymentGateway.ServiceWallet
 	serviceNetwork := s.cfg.PaymentGateway.ServiceNetwork
+	tokenMint := s.cfg.PaymentFeeMint()
 	assetType := "spl-token"
-	var tokenMint string
-	if serviceNetwork == "mainnet" {
-		tokenMint = s.cfg.USDCMainnetMintAddress
-	} else {
-		tokenMint = s.cfg.USDCDevnetMintAddress
+	if tokenMint == "" {
+		assetType = "sol"
 	}
 
 	exists, err := s.store.WalletExists(ctx, serviceWallet, serviceNetwork, assetType, tokenMint)
@@ -154,16 +360,24 @@ func (s *Server) ensureServiceWalletRegistered(ctx context.Context) error {
 		return nil
 	}
 
-	s.logger.Info("registering service wallet for USDC payment monitoring",
+	s.logger.Info("registering service wallet for payment monitoring",
 		"address", serviceWallet,
 		"network", serviceNetwork,
+		"asset_type", assetType,
+		"token_mint", tokenMint,
 	)
 
-	ataAddr, err := computeAssociatedTokenAddress(serviceWallet, tokenMint)
-	if err != nil {
-		return fmt.Errorf("failed to compute service wallet ATA: %w", err)
+	// SOL payments are monitored at the wallet itself, token payments at its ATA.
+	var ata *string
+	monitoredAddress := serviceWallet
+	if assetType == "spl-token" {
+		ataAddr, err := computeAssociatedTokenAddress(serviceWallet, tokenMint)
+		if err != nil {
+			return fmt.Errorf("failed to compute service wallet ATA: %w", err)
+		}
+		ata = &ataAddr
+		monitoredAddress = ataAddr
 	}
-	ata := &ataAddr
 
 	_, err = s.store.UpsertWallet(ctx, db.UpsertWalletParams{
 		Address:                serviceWallet,
@@ -177,9 +391,9 @@ func (s *Server) ensureServiceWalletRegistered(ctx context.Context) error {
 		return fmt.Errorf("failed to register service wallet: %w", err)
 	}
 
-	// Add the ATA to the Helius webhook
+	// Add the monitored address to the Helius webhook
 	if s.heliusClient != nil {
-		if err := s.heliusClient.AddAddress(ctx, *ata); err != nil {
+		if err := s.heliusClient.AddAddress(ctx, monitoredAddress); err != nil {
 			s.store.DeleteWallet(ctx, serviceWallet, serviceNetwork, assetType, tokenMint)
 			return fmt.Errorf("failed to add service wallet to Helius webhook: %w", err)
 		}
@@ -189,10 +403,15 @@ func (s *Server) ensureServiceWalletRegistered(ctx context.Context) error {
 	return nil
 }
 
-// Shutdown gracefully shuts down the HTTP server.
+// Shutdown gracefully shuts down the HTTP server. SSE streams are drained
+// first: each gets a reconnect event with a resume cursor, so clients pick up
+// on another replica without missing transactions.
 func (s *Server) Shutdown(ctx context.Context) error {
 	if s.ssePublisher != nil {
-		s.ssePublisher.Close()
+		if err := s.ssePublisher.Drain(ctx); err != nil {
+			s.logger.Warn("failed to drain SSE streams", "error", err)
+		}
+		defer s.ssePublisher.Close()
 	}
 	if s.server != nil {
 		return s.server.Shutdown(ctx)
@@ -204,8 +423,9 @@ func (s *Server) Shutdown(ctx context.Context) error {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+requestIDHeader+", "+jsonCaseHeader)
+		w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		if r.Method == "OPTIONS" {
@@ -216,3 +436,64 @@ func corsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// requestIDHeader carries a request's ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds a caller-supplied request ID, keeping it within the
+// 128-character limit Prometheus places on an exemplar's labels.
+const maxRequestIDLen = 64
+
+// requestIDMiddleware tags each request with an ID: the caller's X-Request-ID
+// if it's well-formed, or a random one. The ID is echoed in the response and
+// carried in the context, where latency histograms attach it as an exemplar
+// and log lines can include it.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(metrics.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID reports whether id is 1-64 characters of letters, digits,
+// '.', '_' and '-'.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 16-character hex ID.
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// longLivedMiddleware clears the connection's read and write deadlines so a
+// streaming handler isn't cut off by the server-wide ReadTimeout/WriteTimeout.
+// Only wrap routes that are expected to hold the connection open (SSE).
+func longLivedMiddleware(next http.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			logger.WarnContext(r.Context(), "failed to disable write deadline", "error", err)
+		}
+		if err := rc.SetReadDeadline(time.Time{}); err != nil {
+			logger.WarnContext(r.Context(), "failed to disable read deadline", "error", err)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/brojonat/forohtoo/service/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowStream writes one chunk, waits past the server's write timeout, then
+// writes a second chunk.
+func slowStream(delay time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(delay)
+		w.Write([]byte("second\n"))
+		w.(http.Flusher).Flush()
+	})
+}
+
+func newTimeoutServer(h http.Handler, timeout time.Duration) *httptest.Server {
+	srv := httptest.NewUnstartedServer(h)
+	srv.Config.ReadTimeout = timeout
+	srv.Config.WriteTimeout = timeout
+	srv.Start()
+	return srv
+}
+
+func TestLongLivedMiddleware_OutlivesServerTimeouts(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	srv := newTimeoutServer(longLivedMiddleware(slowStream(300*time.Millisecond), logger), 100*time.Millisecond)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(body))
+}
+
+func TestServerTimeouts_CutOffRegularRoutes(t *testing.T) {
+	srv := newTimeoutServer(slowStream(300*time.Millisecond), 100*time.Millisecond)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.NotContains(t, string(body), "second")
+}
+
+func TestRoutes_BasePath(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	tests := []struct {
+		name       string
+		basePath   string
+		path       string
+		wantStatus int
+	}{
+		{"root health", "", "/health", http.StatusOK},
+		{"prefixed health", "/forohtoo", "/forohtoo/health", http.StatusOK},
+		{"unprefixed health is not served", "/forohtoo", "/health", http.StatusNotFound},
+		{"unprefixed api is not served", "/forohtoo", "/api/v1/wallet-assets/bad!addr?network=mainnet", http.StatusNotFound},
+		// Path values survive prefix stripping and reach validation.
+		{"prefixed api route", "/forohtoo", "/forohtoo/api/v1/wallet-assets/bad!addr?network=mainnet", http.StatusBadRequest},
+		{"other prefix", "/forohtoo", "/forohtoo2/health", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{BasePath: tt.basePath}
+			s := New(":0", cfg, nil, nil, nil, nil, nil, nil, logger)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			s.routes().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+// TestRoutes_AdminAuth checks that admin routes need the admin token. Each
+// request is invalid, so a 400 shows it got past the token check without
+// touching the store.
+func TestRoutes_AdminAuth(t *testing.T) {
+	cfg := &config.Config{AdminAuthToken: "s3cret"}
+	handler := New(":0", cfg, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/api/v1/supported-mints", `{}`},
+		{http.MethodDelete, "/api/v1/supported-mints/mint1?network=testnet", ""},
+		{http.MethodGet, "/api/v1/admin/sla?window=bogus", ""},
+		{http.MethodGet, "/api/v1/admin/throughput?granularity=weekly", ""},
+		{http.MethodPost, "/api/v1/admin/ingest", "not json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			serve := func(auth string) int {
+				req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+				if auth != "" {
+					req.Header.Set("Authorization", auth)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec.Code
+			}
+			assert.Equal(t, http.StatusUnauthorized, serve(""))
+			assert.Equal(t, http.StatusUnauthorized, serve("Bearer wrong"))
+			assert.Equal(t, http.StatusBadRequest, serve("Bearer s3cret"))
+		})
+	}
+}
+
+// TestRoutes_CORSPreflight checks that browsers may send every method the
+// routes use, including PUT for wallet filters and maintenance mode.
+func TestRoutes_CORSPreflight(t *testing.T) {
+	handler := New(":0", &config.Config{}, nil, nil, nil, nil, nil, nil, webhookTestLogger()).routes()
+
+	for _, path := range []string{
+		"/api/v1/wallet-assets/addr1/filters/big",
+		"/api/v1/admin/maintenance",
+	} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, path, nil)
+			req.Header.Set("Origin", "https://example.com")
+			req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			methods := strings.Split(rec.Header().Get("Access-Control-Allow-Methods"), ", ")
+			assert.Contains(t, methods, http.MethodPut)
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var gotID string
+	handler := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = metrics.RequestIDFromContext(r.Context())
+	}))
+
+	serve := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		if header != "" {
+			req.Header.Set(requestIDHeader, header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("caller ID is kept", func(t *testing.T) {
+		rec := serve("abc-123.x_y")
+		assert.Equal(t, "abc-123.x_y", gotID)
+		assert.Equal(t, "abc-123.x_y", rec.Header().Get(requestIDHeader))
+	})
+
+	t.Run("missing ID is generated", func(t *testing.T) {
+		rec := serve("")
+		assert.Len(t, gotID, 16)
+		assert.Equal(t, gotID, rec.Header().Get(requestIDHeader))
+	})
+
+	t.Run("malformed ID is replaced", func(t *testing.T) {
+		serve("bad id\n")
+		assert.Len(t, gotID, 16)
+		serve(strings.Repeat("a", maxRequestIDLen+1))
+		assert.Len(t, gotID, 16)
+	})
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+const (
+	defaultSLAWindow = time.Hour
+	maxSLAWindow     = 7 * 24 * time.Hour
+)
+
+// latencyResponse summarizes block-to-write delays, in seconds.
+type latencyResponse struct {
+	Count      int64   `json:"count"`
+	P50Seconds float64 `json:"p50_seconds"`
+	P99Seconds float64 `json:"p99_seconds"`
+	MaxSeconds float64 `json:"max_seconds"`
+}
+
+func latencyToResponse(l *db.IngestionLatency) latencyResponse {
+	return latencyResponse{
+		Count:      l.Count,
+		P50Seconds: l.P50.Seconds(),
+		P99Seconds: l.P99.Seconds(),
+		MaxSeconds: l.Max.Seconds(),
+	}
+}
+
+// validateSLAQuery parses the SLA query parameters into the window ending at
+// now and an optional network.
+func validateSLAQuery(query url.Values, now time.Time) (db.IngestionLatencyParams, error) {
+	params := db.IngestionLatencyParams{End: now, Start: now.Add(-defaultSLAWindow)}
+
+	if raw := query.Get("window"); raw != "" {
+		window, err := time.ParseDuration(raw)
+		if err != nil {
+			return params, errorf("invalid window: %v", err)
+		}
+		if window <= 0 || window > maxSLAWindow {
+			return params, errorf("window must be positive and at most %s", maxSLAWindow)
+		}
+		params.Start = now.Add(-window)
+	}
+
+	if network := query.Get("network"); network != "" {
+		if err := validateNetwork(network); err != nil {
+			return params, err
+		}
+		params.Network = network
+	}
+
+	return params, nil
+}
+
+// handleSLA returns a handler that reports service-level figures over a
+// recent window, for a status page. Ingestion latency is how long after its
+// block time each transaction was written; payment detection is the same for
+// the payment gateway's service wallet, which is when a waiting registration
+// sees its payment.
+// GET /api/v1/admin/sla?window=1h&network=
+func handleSLA(store *db.Store, cfg *config.Config, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, err := validateSLAQuery(r.URL.Query(), time.Now().UTC())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ingestion, err := store.GetIngestionLatency(r.Context(), params)
+		if err != nil {
+			logger.Error("failed to compute ingestion latency", "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := map[string]interface{}{
+			"window_start":      params.Start,
+			"window_end":        params.End,
+			"network":           params.Network,
+			"ingestion_latency": latencyToResponse(ingestion),
+		}
+
+		gateway := cfg.PaymentGateway
+		if gateway.Enabled && gateway.ServiceWallet != "" && (params.Network == "" || params.Network == gateway.ServiceNetwork) {
+			paymentParams := params
+			paymentParams.Network = gateway.ServiceNetwork
+			paymentParams.WalletAddress = gateway.ServiceWallet
+			detection, err := store.GetIngestionLatency(r.Context(), paymentParams)
+			if err != nil {
+				logger.Error("failed to compute payment detection latency", "error", err)
+				writeError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			resp["payment_detection"] = latencyToResponse(detection)
+		}
+
+		writeJSON(w, resp, http.StatusOK)
+	})
+}
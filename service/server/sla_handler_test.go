@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSLAQuery(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("defaults", func(t *testing.T) {
+		params, err := validateSLAQuery(url.Values{}, now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(-time.Hour), params.Start)
+		assert.Equal(t, now, params.End)
+		assert.Empty(t, params.Network)
+		assert.Empty(t, params.WalletAddress)
+	})
+
+	t.Run("explicit window and network", func(t *testing.T) {
+		params, err := validateSLAQuery(url.Values{"window": {"24h"}, "network": {"devnet"}}, now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(-24*time.Hour), params.Start)
+		assert.Equal(t, "devnet", params.Network)
+	})
+
+	errTests := []struct {
+		name    string
+		query   url.Values
+		wantErr string
+	}{
+		{"bad window", url.Values{"window": {"hour"}}, "invalid window"},
+		{"negative window", url.Values{"window": {"-1h"}}, "window must be positive"},
+		{"window too large", url.Values{"window": {"169h"}}, "at most"},
+		{"bad network", url.Values{"network": {"testnet"}}, "network"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateSLAQuery(tt.query, now)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLatencyToResponse(t *testing.T) {
+	resp := latencyToResponse(&db.IngestionLatency{
+		Count: 3,
+		P50:   1500 * time.Millisecond,
+		P99:   4 * time.Second,
+		Max:   time.Minute,
+	})
+	assert.Equal(t, latencyResponse{Count: 3, P50Seconds: 1.5, P99Seconds: 4, MaxSeconds: 60}, resp)
+}
@@ -3,13 +3,20 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/metrics"
 	natspkg "github.com/brojonat/forohtoo/service/nats"
+	"github.com/jackc/pgx/v5"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 )
@@ -20,10 +27,172 @@ type SSEPublisher struct {
 	js     jetstream.JetStream
 	logger *slog.Logger
 	store  *db.Store
+	conns  *sseConnections
+	subs   *sseSubscriptions
+
+	// cleanupInterval is how often Run sweeps for subscriptions whose
+	// stream has gone away.
+	cleanupInterval time.Duration
+
+	// reconnectDelay is how long clients are told to wait before
+	// reconnecting when the server drains.
+	reconnectDelay time.Duration
+}
+
+// sseConnections tracks open SSE streams so a shutdown can hand them off
+// instead of cutting them.
+type sseConnections struct {
+	mu       sync.Mutex
+	draining bool
+	drainCh  chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newSSEConnections() *sseConnections {
+	return &sseConnections{drainCh: make(chan struct{})}
+}
+
+// acquire registers a new stream. It returns false once draining has started.
+func (c *sseConnections) acquire() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.draining {
+		return false
+	}
+	c.wg.Add(1)
+	return true
+}
+
+// release unregisters a stream registered with acquire.
+func (c *sseConnections) release() {
+	c.wg.Done()
+}
+
+// drained is closed when draining starts.
+func (c *sseConnections) drained() <-chan struct{} {
+	return c.drainCh
+}
+
+// drain stops new streams, signals open ones to close, and waits for them
+// until ctx is done.
+func (c *sseConnections) drain(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.draining {
+		c.draining = true
+		close(c.drainCh)
+	}
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sseSubscriptions tracks the NATS consumer behind each open SSE stream.
+// Streams stop their own subscription when they return; the periodic sweep
+// catches any whose stream context ended without that happening, so a
+// vanished client can't leave a consumer behind on the NATS server.
+type sseSubscriptions struct {
+	mu       sync.Mutex
+	nextID   uint64
+	entries  map[uint64]*sseSubscription
+	onChange func(active int) // called with the new count; may be nil
+}
+
+type sseSubscription struct {
+	done <-chan struct{}
+	stop func()
+}
+
+func newSSESubscriptions(onChange func(active int)) *sseSubscriptions {
+	return &sseSubscriptions{entries: make(map[uint64]*sseSubscription), onChange: onChange}
+}
+
+// track registers a subscription that belongs to a stream until done is
+// closed. stop must be safe to call more than once.
+func (s *sseSubscriptions) track(done <-chan struct{}, stop func()) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.entries[s.nextID] = &sseSubscription{done: done, stop: stop}
+	s.changed()
+	return s.nextID
+}
+
+// untrack stops and unregisters a subscription. It is a no-op if the sweep
+// already removed it.
+func (s *sseSubscriptions) untrack(id uint64) {
+	s.mu.Lock()
+	sub, ok := s.entries[id]
+	if ok {
+		delete(s.entries, id)
+		s.changed()
+	}
+	s.mu.Unlock()
+	if ok {
+		sub.stop()
+	}
+}
+
+// sweep stops and unregisters every subscription whose stream is done and
+// returns how many it removed.
+func (s *sseSubscriptions) sweep() int {
+	s.mu.Lock()
+	var stale []*sseSubscription
+	for id, sub := range s.entries {
+		select {
+		case <-sub.done:
+			stale = append(stale, sub)
+			delete(s.entries, id)
+		default:
+		}
+	}
+	if len(stale) > 0 {
+		s.changed()
+	}
+	s.mu.Unlock()
+
+	for _, sub := range stale {
+		sub.stop()
+	}
+	return len(stale)
+}
+
+// active returns the number of tracked subscriptions.
+func (s *sseSubscriptions) active() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// changed reports the current count. Callers hold s.mu.
+func (s *sseSubscriptions) changed() {
+	if s.onChange != nil {
+		s.onChange(len(s.entries))
+	}
+}
+
+// sseReconnectEvent is sent to open streams when the server drains. Cursor is
+// the signature of the last transaction delivered on the stream (empty if
+// none); passing it back as ?cursor= resumes without a gap.
+type sseReconnectEvent struct {
+	Cursor  string `json:"cursor,omitempty"`
+	RetryMS int64  `json:"retry_ms"`
 }
 
 // NewSSEPublisher creates a new SSE publisher that subscribes to NATS internally.
-func NewSSEPublisher(natsURL string, store *db.Store, logger *slog.Logger) (*SSEPublisher, error) {
+// reconnectDelay is the delay clients are asked to wait before reconnecting
+// when the publisher drains. cleanupInterval is how often Run sweeps for
+// abandoned NATS subscriptions. m may be nil.
+func NewSSEPublisher(natsURL string, store *db.Store, reconnectDelay, cleanupInterval time.Duration, m *metrics.Metrics, logger *slog.Logger) (*SSEPublisher, error) {
 	// Connect to NATS
 	nc, err := nats.Connect(natsURL,
 		nats.Name("forohtoo-sse-publisher"),
@@ -44,14 +213,54 @@ func NewSSEPublisher(natsURL string, store *db.Store, logger *slog.Logger) (*SSE
 
 	logger.Info("SSE publisher initialized", "nats_url", natsURL)
 
+	var onChange func(int)
+	if m != nil {
+		onChange = m.SetNATSSubscriptionsActive
+	}
+
 	return &SSEPublisher{
-		nc:     nc,
-		js:     js,
-		logger: logger,
-		store:  store,
+		nc:              nc,
+		js:              js,
+		logger:          logger,
+		store:           store,
+		conns:           newSSEConnections(),
+		subs:            newSSESubscriptions(onChange),
+		cleanupInterval: cleanupInterval,
+		reconnectDelay:  reconnectDelay,
 	}, nil
 }
 
+// Run sweeps for abandoned NATS subscriptions every cleanup interval until
+// ctx is cancelled.
+func (p *SSEPublisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := p.subs.sweep(); n > 0 {
+				p.logger.Warn("cleaned up abandoned SSE subscriptions", "count", n, "active", p.subs.active())
+			}
+		}
+	}
+}
+
+// Drain stops accepting new streams and tells open ones to reconnect, each
+// with a cursor to resume from. It returns once every stream has closed or
+// ctx is done. Call it before shutting down the HTTP server, which otherwise
+// waits on streams that never go idle.
+func (p *SSEPublisher) Drain(ctx context.Context) error {
+	p.logger.Info("draining SSE streams")
+	if err := p.conns.drain(ctx); err != nil {
+		return fmt.Errorf("SSE streams still open: %w", err)
+	}
+	p.logger.Info("SSE streams drained")
+	return nil
+}
+
 // Close closes the NATS connection.
 func (p *SSEPublisher) Close() error {
 	if p.nc != nil {
@@ -63,21 +272,78 @@ func (p *SSEPublisher) Close() error {
 
 // handleStreamTransactions handles SSE streaming for transactions.
 // If address path parameter is empty, streams all wallets. Otherwise, streams specific wallet.
-func handleStreamTransactions(publisher *SSEPublisher, logger *slog.Logger) http.Handler {
+// The optional fields parameter limits each transaction event to the listed fields.
+// The optional cursor parameter (the cursor from a reconnect event; requires
+// network) replays transactions from the cursor's block time instead of
+// lookback. Transactions in the same block as the cursor may be resent.
+// When the server drains, open streams get a reconnect event and new ones 503.
+// The optional filter parameter (requires an address and network) applies
+// the wallet's named filter to history and live events. It is resolved when
+// the stream opens; later edits apply on reconnect.
+func handleStreamTransactions(publisher *SSEPublisher, payloads *payloadLogger, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Disable write deadline for SSE streaming (long-lived connection)
-		// The default server WriteTimeout of 15s would kill the connection
-		rc := http.NewResponseController(w)
-		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
-			logger.WarnContext(r.Context(), "failed to disable write deadline", "error", err)
-		}
-
 		// Get wallet address from URL path parameter (may be empty for "all wallets" route)
 		address := r.PathValue("address")
 
 		// Get network from query parameter (required for filtering transactions)
 		network := r.URL.Query().Get("network")
 
+		// Optional field projection (e.g. ?fields=signature,amount). Validate
+		// before the stream starts so bad requests still get a 400.
+		fields, err := natspkg.ParseFields(r.URL.Query().Get("fields"))
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		cursor := r.URL.Query().Get("cursor")
+		if cursor != "" {
+			if err := validateSignature(cursor); err != nil {
+				writeError(w, "invalid cursor: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if network == "" {
+				writeError(w, "cursor requires network", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var txnFilter *transactionFilter
+		if name := r.URL.Query().Get("filter"); name != "" {
+			if address == "" || network == "" {
+				writeError(w, "filter requires a wallet address and network", http.StatusBadRequest)
+				return
+			}
+			if err := validateFilterName(name); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+			stored, err := publisher.store.GetWalletFilter(ctx, address, network, name)
+			cancel()
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					writeError(w, "filter not found", http.StatusNotFound)
+					return
+				}
+				logger.ErrorContext(r.Context(), "failed to get wallet filter", "address", address, "network", network, "name", name, "error", err)
+				writeError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			if txnFilter, err = compileWalletFilter(stored); err != nil {
+				logger.ErrorContext(r.Context(), "failed to compile wallet filter", "address", address, "network", network, "name", name, "error", err)
+				writeError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if !publisher.conns.acquire() {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(publisher.reconnectDelay.Seconds()))))
+			writeError(w, "server is shutting down, reconnect shortly", http.StatusServiceUnavailable)
+			return
+		}
+		defer publisher.conns.release()
+
 		// Determine subject filter and description for logging/responses
 		var subject string
 		var walletDesc string
@@ -104,6 +370,11 @@ func handleStreamTransactions(publisher *SSEPublisher, logger *slog.Logger) http
 			"remote_addr", r.RemoteAddr,
 		)
 
+		// lastDelivered is the resume cursor handed out if the server drains.
+		// A reconnecting client keeps its old cursor until something newer is
+		// sent.
+		lastDelivered := &resumeCursor{signature: cursor}
+
 		// Send initial connection event
 		fmt.Fprintf(w, "event: connected\ndata: {\"wallet\":\"%s\"}\n\n", walletDesc)
 		if flusher, ok := w.(http.Flusher); ok {
@@ -113,7 +384,6 @@ func handleStreamTransactions(publisher *SSEPublisher, logger *slog.Logger) http
 		// 1) Parse and validate lookback parameter
 		lookbackParam := r.URL.Query().Get("lookback")
 		var lookback time.Duration
-		var err error
 
 		if lookbackParam != "" {
 			lookback, err = time.ParseDuration(lookbackParam)
@@ -134,9 +404,27 @@ func handleStreamTransactions(publisher *SSEPublisher, logger *slog.Logger) http
 			}
 		}
 
-		// 2) Send historical transactions if lookback > 0
+		// A cursor replaces lookback: everything up to the cursor was delivered
+		// before the client reconnected.
+		var start time.Time
 		if lookback > 0 {
-			start := time.Now().Add(-lookback)
+			start = time.Now().Add(-lookback)
+		}
+		if cursor != "" {
+			ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+			resumeFrom, err := publisher.store.GetTransaction(ctx, cursor, network)
+			cancel()
+			if err != nil {
+				// Pruned or unknown; fall back to lookback.
+				logger.WarnContext(r.Context(), "failed to resolve SSE cursor", "cursor", cursor, "network", network, "error", err)
+			} else {
+				start = resumeFrom.BlockTime
+				lastDelivered.blockTime = resumeFrom.BlockTime
+			}
+		}
+
+		// 2) Send historical transactions from start, if any
+		if !start.IsZero() {
 			end := time.Now()
 
 			// Fetch historical transactions from database
@@ -166,6 +454,7 @@ func handleStreamTransactions(publisher *SSEPublisher, logger *slog.Logger) http
 						}
 						// Filter by network if specified
 						if network != "" && t.Network != network {
+							payloads.Log(r.Context(), "sse_history", payloadFiltered, t.Signature, t)
 							continue
 						}
 						filtered = append(filtered, t)
@@ -186,14 +475,12 @@ func handleStreamTransactions(publisher *SSEPublisher, logger *slog.Logger) http
 			historical = historical[:maxHistoricalEvents]
 		}
 
-		// Send each historical transaction as individual transaction events
-		for _, t := range historical {
-			event := natspkg.FromDBTransaction(t)
-			payload, _ := json.Marshal(event)
-			fmt.Fprintf(w, "event: transaction\ndata: %s\n\n", string(payload))
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
-			}
+		// Send each historical transaction as individual transaction events,
+		// flushing once for the whole batch so a compressed stream can
+		// compress the replay as a unit
+		writeHistory(r.Context(), w, historical, cursor, txnFilter, fields, payloads, lastDelivered)
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
 		}
 		}
 
@@ -213,24 +500,29 @@ func handleStreamTransactions(publisher *SSEPublisher, logger *slog.Logger) http
 		}
 
 		msgChan := make(chan jetstream.Msg, 64)
-		doneChan := make(chan struct{})
-
-		go func() {
-			defer close(doneChan)
-			cc, err := cons.Consume(func(msg jetstream.Msg) {
-				select {
-				case msgChan <- msg:
-				case <-r.Context().Done():
-					return
-				}
-			})
-			if err != nil {
-				logger.ErrorContext(r.Context(), "failed to start consuming messages", "error", err)
-				return
+		cc, err := cons.Consume(func(msg jetstream.Msg) {
+			select {
+			case msgChan <- msg:
+			case <-r.Context().Done():
 			}
-			<-r.Context().Done()
-			cc.Stop()
-		}()
+		})
+		if err != nil {
+			publisher.deleteConsumer(cons)
+			logger.ErrorContext(r.Context(), "failed to start consuming messages", "error", err)
+			fmt.Fprintf(w, "event: error\ndata: {\"error\": \"failed to subscribe\"}\n\n")
+			return
+		}
+
+		// Stop consuming and delete the consumer as soon as the stream ends,
+		// rather than leaving it for the server's inactivity reaper.
+		var stopOnce sync.Once
+		subID := publisher.subs.track(r.Context().Done(), func() {
+			stopOnce.Do(func() {
+				cc.Stop()
+				publisher.deleteConsumer(cons)
+			})
+		})
+		defer publisher.subs.untrack(subID)
 
 		keepalive := time.NewTicker(10 * time.Second)
 		defer keepalive.Stop()
@@ -249,18 +541,84 @@ func handleStreamTransactions(publisher *SSEPublisher, logger *slog.Logger) http
 					msg.Ack()
 					continue
 				}
-				data, _ := json.Marshal(event)
+				if !txnFilter.matchesEvent(&event) {
+					payloads.Log(r.Context(), "sse_live", payloadFiltered, event.Signature, event)
+					msg.Ack()
+					continue
+				}
+				data, _ := event.MarshalFields(fields)
 				fmt.Fprintf(w, "event: transaction\ndata: %s\n\n", string(data))
+				payloads.Log(r.Context(), "sse_live", payloadSent, event.Signature, event)
+				lastDelivered.advance(event.Signature, event.BlockTime)
 				if flusher, ok := w.(http.Flusher); ok {
 					flusher.Flush()
 				}
 				msg.Ack()
+			case <-publisher.conns.drained():
+				writeReconnectEvent(w, lastDelivered.signature, publisher.reconnectDelay)
+				logger.DebugContext(r.Context(), "SSE stream handed off for shutdown", "wallet", walletDesc, "cursor", lastDelivered.signature)
+				return
 			case <-r.Context().Done():
 				logger.DebugContext(r.Context(), "SSE client disconnected", "wallet", walletDesc, "remote_addr", r.RemoteAddr)
 				return
-			case <-doneChan:
-				return
 			}
 		}
 	})
 }
+
+// deleteConsumer removes a stream's ephemeral consumer from the NATS server.
+// It runs after the request context is done, so it uses its own timeout.
+func (p *SSEPublisher) deleteConsumer(cons jetstream.Consumer) {
+	name := cons.CachedInfo().Name
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.js.DeleteConsumer(ctx, natspkg.StreamName, name); err != nil {
+		p.logger.Warn("failed to delete SSE consumer", "consumer", name, "error", err)
+	}
+}
+
+// resumeCursor is the transaction a draining stream tells its client to resume
+// from: the newest delivered by block time. History is replayed newest first,
+// so the last transaction sent is often the oldest.
+type resumeCursor struct {
+	signature string
+	blockTime time.Time // zero when the client's cursor couldn't be resolved
+}
+
+// advance moves the cursor to a delivered transaction unless it is older than
+// the one the cursor is on.
+func (c *resumeCursor) advance(signature string, blockTime time.Time) {
+	if c.signature == "" || !blockTime.Before(c.blockTime) {
+		c.signature = signature
+		c.blockTime = blockTime
+	}
+}
+
+// writeHistory writes historical transactions that pass filter as transaction
+// events, skipping the client's cursor, and advances last past them.
+func writeHistory(ctx context.Context, w io.Writer, historical []*db.Transaction, cursor string, filter *transactionFilter, fields []string, payloads *payloadLogger, last *resumeCursor) {
+	for _, t := range historical {
+		if t.Signature == cursor {
+			continue
+		}
+		if !filter.matchesTransaction(t) {
+			payloads.Log(ctx, "sse_history", payloadFiltered, t.Signature, t)
+			continue
+		}
+		event := natspkg.FromDBTransaction(t)
+		payload, _ := event.MarshalFields(fields)
+		fmt.Fprintf(w, "event: transaction\ndata: %s\n\n", string(payload))
+		payloads.Log(ctx, "sse_history", payloadSent, event.Signature, event)
+		last.advance(event.Signature, event.BlockTime)
+	}
+}
+
+// writeReconnectEvent tells the client to reconnect after delay, resuming
+// from cursor.
+func writeReconnectEvent(w http.ResponseWriter, cursor string, delay time.Duration) {
+	data, _ := json.Marshal(sseReconnectEvent{Cursor: cursor, RetryMS: delay.Milliseconds()})
+	fmt.Fprintf(w, "event: reconnect\ndata: %s\n\n", data)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamTransactions_InvalidFields(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := handleStreamTransactions(nil, nil, logger)
+
+	req := httptest.NewRequest("GET", "/api/v1/stream/transactions?network=mainnet&fields=signature,bogus", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `unknown field \"bogus\"`)
+	assert.NotEqual(t, "text/event-stream", w.Header().Get("Content-Type"))
+}
+
+func TestSSEConnections_Drain(t *testing.T) {
+	conns := newSSEConnections()
+	require.True(t, conns.acquire())
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- conns.drain(context.Background())
+	}()
+
+	select {
+	case <-conns.drained():
+	case <-time.After(time.Second):
+		t.Fatal("drain should signal open streams")
+	}
+	assert.False(t, conns.acquire(), "no new streams once draining")
+
+	select {
+	case <-drained:
+		t.Fatal("drain should wait for open streams")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	conns.release()
+	require.NoError(t, <-drained)
+
+	// Draining again is a no-op.
+	require.NoError(t, conns.drain(context.Background()))
+}
+
+func TestSSEConnections_DrainTimeout(t *testing.T) {
+	conns := newSSEConnections()
+	require.True(t, conns.acquire())
+	defer conns.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, conns.drain(ctx), context.DeadlineExceeded)
+}
+
+func TestSSESubscriptions_UntrackStopsPromptly(t *testing.T) {
+	var active []int
+	subs := newSSESubscriptions(func(n int) { active = append(active, n) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := 0
+	id := subs.track(ctx.Done(), func() { stopped++ })
+	assert.Equal(t, 1, subs.active())
+
+	subs.untrack(id)
+	assert.Equal(t, 1, stopped, "a closing stream unsubscribes without waiting for a sweep")
+	assert.Equal(t, 0, subs.active())
+
+	subs.untrack(id)
+	assert.Equal(t, 1, stopped, "untracking twice is a no-op")
+	assert.Equal(t, []int{1, 0}, active)
+}
+
+func TestSSESubscriptions_SweepAbruptDisconnects(t *testing.T) {
+	var lastActive int
+	subs := newSSESubscriptions(func(n int) { lastActive = n })
+
+	// Three clients connect; two vanish without their streams unsubscribing.
+	stopped := make([]int, 3)
+	cancels := make([]context.CancelFunc, 3)
+	ids := make([]uint64, 3)
+	for i := range ids {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancels[i] = cancel
+		i := i
+		ids[i] = subs.track(ctx.Done(), func() { stopped[i]++ })
+	}
+	defer cancels[2]()
+	require.Equal(t, 3, lastActive)
+
+	cancels[0]()
+	cancels[1]()
+
+	assert.Equal(t, 2, subs.sweep())
+	assert.Equal(t, []int{1, 1, 0}, stopped, "only subscriptions of gone streams are stopped")
+	assert.Equal(t, 1, subs.active())
+	assert.Equal(t, 1, lastActive)
+
+	// A late untrack from a swept stream doesn't stop it again.
+	subs.untrack(ids[0])
+	assert.Equal(t, 1, stopped[0])
+
+	assert.Equal(t, 0, subs.sweep(), "nothing left to clean up")
+	assert.Equal(t, 0, stopped[2])
+}
+
+func TestStreamTransactions_Draining(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	publisher := &SSEPublisher{logger: logger, conns: newSSEConnections(), reconnectDelay: 1500 * time.Millisecond}
+	require.NoError(t, publisher.Drain(context.Background()))
+
+	req := httptest.NewRequest("GET", "/api/v1/stream/transactions/wallet1?network=mainnet", nil)
+	w := httptest.NewRecorder()
+	handleStreamTransactions(publisher, nil, logger).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
+
+func TestStreamTransactions_InvalidCursor(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := handleStreamTransactions(nil, nil, logger)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"malformed cursor", "?network=mainnet&cursor=not-a-signature", "invalid cursor"},
+		{"cursor without network", "?cursor=" + testSignature, "cursor requires network"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/stream/transactions/wallet1"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
+
+// TestWriteHistory_ResumeCursor drains a stream right after a replay. History
+// comes newest first, so the resume cursor must be the newest transaction
+// sent, not the last.
+func TestWriteHistory_ResumeCursor(t *testing.T) {
+	t0 := time.Unix(1700000000, 0).UTC()
+	// ListTransactionsByWalletAndTimeRange order: block_time DESC.
+	historical := []*db.Transaction{
+		{Signature: "sig3", Network: "mainnet", BlockTime: t0.Add(3 * time.Second)},
+		{Signature: "sig2", Network: "mainnet", BlockTime: t0.Add(2 * time.Second)},
+		{Signature: "sig1", Network: "mainnet", BlockTime: t0.Add(time.Second)},
+		{Signature: "cursor-sig", Network: "mainnet", BlockTime: t0},
+	}
+
+	tests := []struct {
+		name   string
+		cursor *resumeCursor
+	}{
+		{"lookback", &resumeCursor{}},
+		{"resolved cursor", &resumeCursor{signature: "cursor-sig", blockTime: t0}},
+		{"unresolved cursor", &resumeCursor{signature: "pruned-sig"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeHistory(context.Background(), w, historical, tt.cursor.signature, nil, nil, nil, tt.cursor)
+			writeReconnectEvent(w, tt.cursor.signature, time.Second)
+
+			assert.Contains(t, w.Body.String(), "event: reconnect\ndata: {\"cursor\":\"sig3\",\"retry_ms\":1000}\n\n")
+		})
+	}
+
+	// Nothing newer delivered: the client keeps its cursor.
+	last := &resumeCursor{signature: "cursor-sig", blockTime: t0.Add(4 * time.Second)}
+	writeHistory(context.Background(), httptest.NewRecorder(), historical, last.signature, nil, nil, nil, last)
+	assert.Equal(t, "cursor-sig", last.signature)
+}
+
+func TestWriteReconnectEvent(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeReconnectEvent(w, "sig123", 2*time.Second)
+	assert.Equal(t, "event: reconnect\ndata: {\"cursor\":\"sig123\",\"retry_ms\":2000}\n\n", w.Body.String())
+}
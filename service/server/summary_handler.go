@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+// summaryCacheTTL is how long a computed summary is served before the
+// aggregates are run again. They scan the wallets and transactions tables,
+// so a dashboard refreshing every few seconds shouldn't trigger each one.
+const summaryCacheTTL = 30 * time.Second
+
+type walletSummary struct {
+	Total       int64            `json:"total"`
+	ByStatus    map[string]int64 `json:"by_status"`
+	ByNetwork   map[string]int64 `json:"by_network"`
+	ByAssetType map[string]int64 `json:"by_asset_type"`
+}
+
+// assetSummary is the totals for one asset on one network. ReceivedAmount is
+// a JSON number that can exceed int64, so decode it with json.Number or a big
+// integer type.
+type assetSummary struct {
+	Network        string      `json:"network"`
+	Asset          string      `json:"asset"` // "sol" or a token mint
+	Count          int64       `json:"count"`
+	ReceivedCount  int64       `json:"received_count"`
+	ReceivedAmount json.Number `json:"received_amount"`
+}
+
+type transactionSummary struct {
+	Total   int64          `json:"total"`
+	ByAsset []assetSummary `json:"by_asset"`
+}
+
+type ingestionRate struct {
+	Transactions int64   `json:"transactions"`
+	PerMinute    float64 `json:"per_minute"`
+}
+
+// summaryResponse is the system overview served by GET /api/v1/admin/summary.
+type summaryResponse struct {
+	GeneratedAt       time.Time          `json:"generated_at"`
+	Wallets           walletSummary      `json:"wallets"`
+	Transactions      transactionSummary `json:"transactions"`
+	IngestionLastHour ingestionRate      `json:"ingestion_last_hour"`
+}
+
+// buildSummary folds the aggregate rows into a summaryResponse. lastHour is
+// the number of transactions written in the hour before now.
+func buildSummary(wallets []db.WalletStatusCount, assets []db.AssetTransactionTotals, lastHour int64, now time.Time) *summaryResponse {
+	resp := &summaryResponse{
+		GeneratedAt: now,
+		Wallets: walletSummary{
+			ByStatus:    map[string]int64{},
+			ByNetwork:   map[string]int64{},
+			ByAssetType: map[string]int64{},
+		},
+		Transactions: transactionSummary{ByAsset: make([]assetSummary, len(assets))},
+		IngestionLastHour: ingestionRate{
+			Transactions: lastHour,
+			PerMinute:    float64(lastHour) / time.Hour.Minutes(),
+		},
+	}
+
+	for _, w := range wallets {
+		resp.Wallets.Total += w.Count
+		resp.Wallets.ByStatus[w.Status] += w.Count
+		resp.Wallets.ByNetwork[w.Network] += w.Count
+		resp.Wallets.ByAssetType[w.AssetType] += w.Count
+	}
+
+	for i, a := range assets {
+		resp.Transactions.Total += a.Count
+		resp.Transactions.ByAsset[i] = assetSummary{
+			Network:        a.Network,
+			Asset:          a.Asset,
+			Count:          a.Count,
+			ReceivedCount:  a.ReceivedCount,
+			ReceivedAmount: json.Number(a.ReceivedAmount),
+		}
+	}
+
+	return resp
+}
+
+// summaryCache serves a computed summary until it is ttl old. Callers wait
+// on one computation rather than each running the aggregates.
+type summaryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	compute func(ctx context.Context, now time.Time) (*summaryResponse, error)
+	cached  *summaryResponse
+}
+
+func (c *summaryCache) get(ctx context.Context, now time.Time) (*summaryResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && now.Sub(c.cached.GeneratedAt) < c.ttl {
+		return c.cached, nil
+	}
+	resp, err := c.compute(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	c.cached = resp
+	return resp, nil
+}
+
+// computeSummary runs the aggregate queries behind the admin summary.
+func computeSummary(ctx context.Context, store *db.Store, now time.Time) (*summaryResponse, error) {
+	wallets, err := store.CountWalletsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := store.SummarizeTransactionsByAsset(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastHour, err := store.GetIngestionLatency(ctx, db.IngestionLatencyParams{Start: now.Add(-time.Hour), End: now})
+	if err != nil {
+		return nil, err
+	}
+	return buildSummary(wallets, assets, lastHour.Count, now), nil
+}
+
+// handleAdminSummary returns a handler that reports a system overview for a
+// global dashboard: registered wallet assets by status, network and asset
+// type, stored transactions and amounts received per asset, and the
+// ingestion rate over the last hour. Results are cached for
+// summaryCacheTTL; generated_at says when they were computed.
+// GET /api/v1/admin/summary
+func handleAdminSummary(store *db.Store, logger *slog.Logger) http.Handler {
+	cache := &summaryCache{
+		ttl: summaryCacheTTL,
+		compute: func(ctx context.Context, now time.Time) (*summaryResponse, error) {
+			return computeSummary(ctx, store, now)
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := cache.get(r.Context(), time.Now().UTC())
+		if err != nil {
+			logger.Error("failed to compute admin summary", "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, resp, http.StatusOK)
+	})
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSummary(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	resp := buildSummary(
+		[]db.WalletStatusCount{
+			{Network: "devnet", AssetType: "sol", Status: "active", Count: 1},
+			{Network: "mainnet", AssetType: "sol", Status: "active", Count: 2},
+			{Network: "mainnet", AssetType: "sol", Status: "paused", Count: 1},
+			{Network: "mainnet", AssetType: "spl-token", Status: "active", Count: 3},
+		},
+		[]db.AssetTransactionTotals{
+			{Network: "mainnet", Asset: usdc, Count: 2, ReceivedCount: 2, ReceivedAmount: "9223372036854775808"},
+			{Network: "mainnet", Asset: "sol", Count: 3, ReceivedCount: 2, ReceivedAmount: "3500"},
+		},
+		120, now,
+	)
+
+	assert.Equal(t, now, resp.GeneratedAt)
+	assert.Equal(t, int64(7), resp.Wallets.Total)
+	assert.Equal(t, map[string]int64{"active": 6, "paused": 1}, resp.Wallets.ByStatus)
+	assert.Equal(t, map[string]int64{"devnet": 1, "mainnet": 6}, resp.Wallets.ByNetwork)
+	assert.Equal(t, map[string]int64{"sol": 4, "spl-token": 3}, resp.Wallets.ByAssetType)
+	assert.Equal(t, int64(5), resp.Transactions.Total)
+	require.Len(t, resp.Transactions.ByAsset, 2)
+	assert.Equal(t, ingestionRate{Transactions: 120, PerMinute: 2}, resp.IngestionLastHour)
+
+	// Amounts beyond int64 are written as exact JSON numbers.
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"received_amount":9223372036854775808`)
+}
+
+func TestBuildSummary_Empty(t *testing.T) {
+	data, err := json.Marshal(buildSummary(nil, nil, 0, time.Now()))
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &body))
+	assert.Equal(t, map[string]interface{}{}, body["wallets"].(map[string]interface{})["by_status"])
+	assert.Equal(t, []interface{}{}, body["transactions"].(map[string]interface{})["by_asset"])
+}
+
+func TestSummaryCache(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	fail := false
+	cache := &summaryCache{
+		ttl: 30 * time.Second,
+		compute: func(_ context.Context, at time.Time) (*summaryResponse, error) {
+			calls++
+			if fail {
+				return nil, errors.New("db down")
+			}
+			return &summaryResponse{GeneratedAt: at}, nil
+		},
+	}
+	ctx := context.Background()
+
+	first, err := cache.get(ctx, now)
+	require.NoError(t, err)
+	cached, err := cache.get(ctx, now.Add(29*time.Second))
+	require.NoError(t, err)
+	assert.Same(t, first, cached)
+	assert.Equal(t, 1, calls)
+
+	refreshed, err := cache.get(ctx, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(30*time.Second), refreshed.GeneratedAt)
+	assert.Equal(t, 2, calls)
+
+	// A failed refresh is reported and retried on the next call.
+	fail = true
+	_, err = cache.get(ctx, now.Add(time.Minute))
+	assert.Error(t, err)
+	_, err = cache.get(ctx, now.Add(time.Minute))
+	assert.Error(t, err)
+	assert.Equal(t, 4, calls)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+const (
+	defaultThroughputWindow = 24 * time.Hour
+	maxThroughputBuckets    = 1000
+)
+
+// throughputGranularities maps the granularity parameter to a bucket width.
+var throughputGranularities = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// throughputBucketResponse is one bucket of a throughput response.
+type throughputBucketResponse struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// validateThroughputQuery parses the throughput query parameters into store
+// parameters. The window defaults to the 24 hours before now, bucketed by
+// hour.
+func validateThroughputQuery(query url.Values, now time.Time) (db.CountTransactionsByTimeBucketParams, string, error) {
+	var params db.CountTransactionsByTimeBucketParams
+
+	granularity := query.Get("granularity")
+	if granularity == "" {
+		granularity = "hour"
+	}
+	width, ok := throughputGranularities[granularity]
+	if !ok {
+		return params, "", errorf("granularity must be 'minute', 'hour' or 'day'")
+	}
+	params.BucketWidth = width
+
+	params.End = now
+	if to := query.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return params, "", errorf("invalid to: must be an RFC 3339 timestamp")
+		}
+		params.End = t
+	}
+	params.Start = params.End.Add(-defaultThroughputWindow)
+	if from := query.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return params, "", errorf("invalid from: must be an RFC 3339 timestamp")
+		}
+		params.Start = t
+	}
+	if !params.Start.Before(params.End) {
+		return params, "", errorf("from must be before to")
+	}
+	if params.End.Sub(params.Start) > maxThroughputBuckets*width {
+		return params, "", errorf("window too large for %s granularity: maximum is %d buckets", granularity, maxThroughputBuckets)
+	}
+
+	if network := query.Get("network"); network != "" {
+		if err := validateNetwork(network); err != nil {
+			return params, "", err
+		}
+		params.Network = network
+	}
+
+	return params, granularity, nil
+}
+
+// handleThroughput returns a handler that reports how many transactions were
+// written per time bucket across all wallets, for charting ingestion volume.
+// A run of empty buckets usually means webhook deliveries stopped.
+// GET /api/v1/admin/throughput?from=&to=&granularity=minute|hour|day&network=
+func handleThroughput(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, granularity, err := validateThroughputQuery(r.URL.Query(), time.Now().UTC())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		buckets, err := store.CountTransactionsByTimeBucket(r.Context(), params)
+		if err != nil {
+			logger.Error("failed to count transactions by time bucket", "granularity", granularity, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]throughputBucketResponse, len(buckets))
+		var total int64
+		for i, b := range buckets {
+			resp[i] = throughputBucketResponse{Start: b.Start, Count: b.Count}
+			total += b.Count
+		}
+
+		body := map[string]interface{}{
+			"from":        params.Start,
+			"to":          params.End,
+			"granularity": granularity,
+			"buckets":     resp,
+			"total":       total,
+		}
+		if params.Network != "" {
+			body["network"] = params.Network
+		}
+		writeJSON(w, body, http.StatusOK)
+	})
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateThroughputQuery(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("defaults", func(t *testing.T) {
+		params, granularity, err := validateThroughputQuery(url.Values{}, now)
+		require.NoError(t, err)
+		assert.Equal(t, "hour", granularity)
+		assert.Equal(t, time.Hour, params.BucketWidth)
+		assert.Equal(t, now.Add(-24*time.Hour), params.Start)
+		assert.Equal(t, now, params.End)
+		assert.Empty(t, params.Network)
+	})
+
+	t.Run("explicit window", func(t *testing.T) {
+		params, granularity, err := validateThroughputQuery(url.Values{
+			"from":        {"2025-05-01T00:00:00Z"},
+			"to":          {"2025-05-31T00:00:00Z"},
+			"granularity": {"day"},
+			"network":     {"devnet"},
+		}, now)
+		require.NoError(t, err)
+		assert.Equal(t, "day", granularity)
+		assert.Equal(t, 24*time.Hour, params.BucketWidth)
+		assert.Equal(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), params.Start)
+		assert.Equal(t, time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC), params.End)
+		assert.Equal(t, "devnet", params.Network)
+	})
+
+	t.Run("from defaults relative to to", func(t *testing.T) {
+		params, _, err := validateThroughputQuery(url.Values{"to": {"2025-05-02T00:00:00Z"}}, now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), params.Start)
+	})
+
+	errTests := []struct {
+		name    string
+		query   url.Values
+		wantErr string
+	}{
+		{"unknown granularity", url.Values{"granularity": {"week"}}, "granularity must be"},
+		{"bad from", url.Values{"from": {"yesterday"}}, "invalid from"},
+		{"bad to", url.Values{"to": {"1717243200"}}, "invalid to"},
+		{"reversed window", url.Values{"from": {"2025-06-01T12:00:00Z"}, "to": {"2025-06-01T11:00:00Z"}}, "from must be before to"},
+		{"empty window", url.Values{"from": {"2025-06-01T12:00:00Z"}, "to": {"2025-06-01T12:00:00Z"}}, "from must be before to"},
+		{"too many buckets", url.Values{"from": {"2025-05-01T00:00:00Z"}, "granularity": {"minute"}}, "window too large"},
+		{"bad network", url.Values{"network": {"testnet"}}, "network"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := validateThroughputQuery(tt.query, now)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
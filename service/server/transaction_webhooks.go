@@ -0,0 +1,312 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
+	"github.com/brojonat/forohtoo/service/temporal"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// transactionWebhookEvent identifies the transaction webhook payload type.
+	transactionWebhookEvent = "transaction.received"
+
+	// transactionWebhookConsumer is the durable JetStream consumer the
+	// dispatcher reads from. Replicas share it, so each transaction is
+	// dispatched by one server.
+	transactionWebhookConsumer = "transaction-webhooks"
+
+	// maxTransactionWebhookAttempts caps the POSTs made for one delivery
+	// before it is recorded as failed.
+	maxTransactionWebhookAttempts = 5
+
+	// transactionWebhookAckWait covers a message's full retry schedule, so
+	// JetStream doesn't redeliver it while it is still being dispatched.
+	transactionWebhookAckWait = 2 * time.Minute
+
+	// maxTransactionWebhooksInFlight caps the transactions dispatched at once.
+	maxTransactionWebhooksInFlight = 64
+)
+
+// TransactionWebhookStore defines the database operations needed by the
+// TransactionWebhookDispatcher.
+type TransactionWebhookStore interface {
+	ListTransactionWebhooks(ctx context.Context, address string, network string) ([]*db.TransactionWebhook, error)
+	GetWalletFilterByID(ctx context.Context, id int64) (*db.WalletFilter, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, params db.RecordWebhookDeliveryAttemptParams) (*db.WebhookDelivery, error)
+}
+
+// compile-time assertion that the store satisfies the interface.
+var _ TransactionWebhookStore = (*db.Store)(nil)
+
+// TransactionWebhookPayload is the body POSTed to a transaction webhook.
+type TransactionWebhookPayload struct {
+	Event       string                    `json:"event"` // always "transaction.received"
+	WebhookID   int64                     `json:"webhook_id"`
+	Transaction *natspkg.TransactionEvent `json:"transaction"`
+}
+
+// TransactionWebhookDispatcher POSTs each transaction published to NATS to
+// the webhooks registered for its wallet, skipping those whose filter it
+// doesn't match. Deliveries are signed like digests,
+// but with the webhook's own secret, retried with exponential backoff, and
+// recorded in webhook_deliveries.
+type TransactionWebhookDispatcher struct {
+	store      TransactionWebhookStore
+	client     *http.Client
+	retryDelay time.Duration // before the second attempt; doubles after each
+	logger     *slog.Logger
+}
+
+// NewTransactionWebhookDispatcher creates a TransactionWebhookDispatcher.
+func NewTransactionWebhookDispatcher(store TransactionWebhookStore, logger *slog.Logger) *TransactionWebhookDispatcher {
+	return &TransactionWebhookDispatcher{
+		store:      store,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		retryDelay: time.Second,
+		logger:     logger,
+	}
+}
+
+// Run consumes transactions from NATS and dispatches them until ctx is
+// cancelled. A message is acknowledged once every webhook for it has been
+// delivered or given up on; one left unacknowledged by a crash is dispatched
+// again.
+func (d *TransactionWebhookDispatcher) Run(ctx context.Context, natsURL string) error {
+	nc, err := nats.Connect(natsURL,
+		nats.Name("forohtoo-transaction-webhooks"),
+		nats.Timeout(10*time.Second),
+		nats.ReconnectWait(1*time.Second),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	cons, err := js.CreateOrUpdateConsumer(ctx, natspkg.StreamName, jetstream.ConsumerConfig{
+		Durable:       transactionWebhookConsumer,
+		FilterSubject: natspkg.StreamSubjects,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		AckWait:       transactionWebhookAckWait,
+		MaxAckPending: maxTransactionWebhooksInFlight,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	cc, err := cons.Consume(func(msg jetstream.Msg) {
+		// MaxAckPending bounds the messages handled at once.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.handleMessage(ctx, msg)
+		}()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start consuming messages: %w", err)
+	}
+
+	d.logger.Info("transaction webhooks started", "consumer", transactionWebhookConsumer)
+	<-ctx.Done()
+	cc.Stop()
+	wg.Wait()
+	return nil
+}
+
+// handleMessage dispatches one NATS message. A message that can't be decoded
+// is acknowledged and dropped; one whose webhooks or their filters can't be
+// loaded is redelivered.
+func (d *TransactionWebhookDispatcher) handleMessage(ctx context.Context, msg jetstream.Msg) {
+	var event natspkg.TransactionEvent
+	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+		d.logger.Warn("failed to unmarshal transaction event", "subject", msg.Subject(), "error", err)
+		msg.Ack()
+		return
+	}
+	if err := d.dispatch(ctx, &event); err != nil {
+		d.logger.Error("transaction webhook dispatch failed",
+			"signature", event.Signature,
+			"wallet", event.WalletAddress,
+			"network", event.Network,
+			"error", err,
+		)
+		msg.Nak()
+		return
+	}
+	if ctx.Err() != nil {
+		// Interrupted by shutdown: left for redelivery, so a webhook that
+		// was already delivered may receive it again.
+		return
+	}
+	msg.Ack()
+}
+
+// dispatch delivers event to every webhook registered for its wallet whose
+// filter it matches, concurrently, and returns once all of them have
+// finished. Filters are loaded before anything is delivered, so a failure
+// doesn't leave the message redelivered to webhooks that already have it.
+func (d *TransactionWebhookDispatcher) dispatch(ctx context.Context, event *natspkg.TransactionEvent) error {
+	hooks, err := d.store.ListTransactionWebhooks(ctx, event.WalletAddress, event.Network)
+	if err != nil {
+		return fmt.Errorf("failed to list transaction webhooks: %w", err)
+	}
+
+	filters := make(map[int64]*transactionFilter)
+	for _, hook := range hooks {
+		if hook.FilterID == nil {
+			continue
+		}
+		if _, ok := filters[*hook.FilterID]; ok {
+			continue
+		}
+		stored, err := d.store.GetWalletFilterByID(ctx, *hook.FilterID)
+		if err != nil {
+			return fmt.Errorf("failed to get wallet filter: %w", err)
+		}
+		filter, err := compileWalletFilter(stored)
+		if err != nil {
+			return fmt.Errorf("failed to compile wallet filter %q: %w", stored.Name, err)
+		}
+		filters[*hook.FilterID] = filter
+	}
+
+	var wg sync.WaitGroup
+	for _, hook := range hooks {
+		if hook.FilterID != nil && !filters[*hook.FilterID].matchesEvent(event) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, hook, event)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver POSTs event to hook, retrying 408, 429, 5xx and network errors up
+// to maxTransactionWebhookAttempts times. Other responses are permanent
+// failures. Every attempt is recorded.
+func (d *TransactionWebhookDispatcher) deliver(ctx context.Context, hook *db.TransactionWebhook, event *natspkg.TransactionEvent) {
+	body, err := json.Marshal(TransactionWebhookPayload{
+		Event:       transactionWebhookEvent,
+		WebhookID:   hook.ID,
+		Transaction: event,
+	})
+	if err != nil {
+		d.logger.Error("failed to encode transaction webhook", "webhook_id", hook.ID, "error", err)
+		return
+	}
+	record := db.RecordWebhookDeliveryAttemptParams{
+		Event: transactionWebhookEvent,
+		// A transaction is published again when it is finalized; that is
+		// a separate delivery.
+		SourceID: fmt.Sprintf("%d:%s:%s", hook.ID, event.Signature, event.ConfirmationStatus),
+		Network:  hook.Network,
+		URL:      hook.URL,
+		Payload:  body,
+	}
+
+	delay := d.retryDelay
+	for attempt := 1; ; attempt++ {
+		statusCode, err := d.post(ctx, hook, body)
+		record.StatusCode = statusCode
+		record.Error = ""
+		var permanent *permanentWebhookError
+		switch {
+		case err == nil:
+			record.Status = db.WebhookDeliveryDelivered
+		case errors.As(err, &permanent), attempt == maxTransactionWebhookAttempts, ctx.Err() != nil:
+			record.Status = db.WebhookDeliveryFailed
+			record.Error = err.Error()
+		default:
+			record.Status = db.WebhookDeliveryRetrying
+			record.Error = err.Error()
+		}
+		// Recorded even after ctx ends, so an interrupted delivery isn't left
+		// retrying forever.
+		if _, err := d.store.RecordWebhookDeliveryAttempt(context.WithoutCancel(ctx), record); err != nil {
+			d.logger.Warn("failed to record transaction webhook attempt", "webhook_id", hook.ID, "error", err)
+		}
+		if record.Status != db.WebhookDeliveryRetrying {
+			if record.Status == db.WebhookDeliveryFailed {
+				d.logger.Warn("transaction webhook delivery failed",
+					"webhook_id", hook.ID,
+					"signature", event.Signature,
+					"attempts", attempt,
+					"error", record.Error,
+				)
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// permanentWebhookError is a response that retrying won't change.
+type permanentWebhookError struct {
+	statusCode int
+}
+
+func (e *permanentWebhookError) Error() string {
+	return fmt.Sprintf("webhook endpoint rejected delivery with %d", e.statusCode)
+}
+
+// post makes one signed delivery attempt and returns the response status
+// code, or 0 if no response was received.
+func (d *TransactionWebhookDispatcher) post(ctx context.Context, hook *db.TransactionWebhook, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(temporal.CallbackTimestampHeader, timestamp)
+	req.Header.Set(temporal.CallbackSignatureHeader, temporal.SignCallback(hook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, nil
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	default:
+		return resp.StatusCode, &permanentWebhookError{statusCode: resp.StatusCode}
+	}
+}
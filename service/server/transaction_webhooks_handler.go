@@ -0,0 +1,229 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// Webhook secrets shorter than minWebhookSecretLen are too easy to guess;
+// longer than maxWebhookSecretLen is no stronger and only bloats the row.
+const (
+	minWebhookSecretLen = 16
+	maxWebhookSecretLen = 256
+)
+
+// transactionWebhookResponse is the JSON response format for a transaction
+// webhook. The secret is never returned.
+type transactionWebhookResponse struct {
+	ID            int64     `json:"id"`
+	WalletAddress string    `json:"wallet_address"`
+	Network       string    `json:"network"`
+	CallbackURL   string    `json:"callback_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	FilterID      *int64    `json:"filter_id,omitempty"` // wallet filter a transaction must match
+}
+
+func transactionWebhookToResponse(hook *db.TransactionWebhook) transactionWebhookResponse {
+	return transactionWebhookResponse{
+		ID:            hook.ID,
+		WalletAddress: hook.Address,
+		Network:       hook.Network,
+		CallbackURL:   hook.URL,
+		CreatedAt:     hook.CreatedAt,
+		FilterID:      hook.FilterID,
+	}
+}
+
+// handleCreateTransactionWebhook returns a handler that registers a URL to
+// receive each of a registered wallet's transactions as it arrives, signed
+// with the caller's secret. The optional filter names one of the wallet's
+// filters; only matching transactions are delivered.
+// POST /api/v1/webhooks
+func handleCreateTransactionWebhook(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+		var req struct {
+			WalletAddress string `json:"wallet_address"`
+			Network       string `json:"network"`
+			CallbackURL   string `json:"callback_url"`
+			Secret        string `json:"secret"`
+			Filter        string `json:"filter,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Debug("failed to decode transaction webhook request", "error", err)
+			writeError(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		setAuditTarget(r, req.WalletAddress, req.Network)
+
+		if err := validateAddress(req.WalletAddress); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateNetwork(req.Network); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateWebhookURL(req.CallbackURL); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateWebhookSecret(req.Secret); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		assets, err := store.ListWalletAssets(r.Context(), req.WalletAddress, req.Network, false)
+		if err != nil {
+			logger.Error("failed to get wallet assets", "address", req.WalletAddress, "network", req.Network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if len(assets) == 0 {
+			writeError(w, "wallet not found", http.StatusNotFound)
+			return
+		}
+
+		var filterID *int64
+		if req.Filter != "" {
+			if err := validateFilterName(req.Filter); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			filter, err := store.GetWalletFilter(r.Context(), req.WalletAddress, req.Network, req.Filter)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					writeError(w, "filter not found", http.StatusNotFound)
+					return
+				}
+				logger.Error("failed to get wallet filter", "address", req.WalletAddress, "network", req.Network, "name", req.Filter, "error", err)
+				writeError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			filterID = &filter.ID
+		}
+
+		hook, err := store.CreateTransactionWebhook(r.Context(), db.CreateTransactionWebhookParams{
+			Address:  req.WalletAddress,
+			Network:  req.Network,
+			URL:      req.CallbackURL,
+			Secret:   req.Secret,
+			FilterID: filterID,
+		})
+		if err != nil {
+			logger.Error("failed to create transaction webhook", "address", req.WalletAddress, "network", req.Network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("transaction webhook created",
+			"id", hook.ID,
+			"address", hook.Address,
+			"network", hook.Network,
+		)
+
+		writeJSON(w, transactionWebhookToResponse(hook), http.StatusCreated)
+	})
+}
+
+// handleListTransactionWebhooks returns a handler that lists transaction
+// webhooks.
+// GET /api/v1/webhooks?address={address}&network={network}
+// Both parameters are optional filters.
+func handleListTransactionWebhooks(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address := r.URL.Query().Get("address")
+		network := r.URL.Query().Get("network")
+		if address != "" {
+			if err := validateAddress(address); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if network != "" {
+			if err := validateNetwork(network); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		hooks, err := store.ListTransactionWebhooks(r.Context(), address, network)
+		if err != nil {
+			logger.Error("failed to list transaction webhooks", "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]transactionWebhookResponse, len(hooks))
+		for i, hook := range hooks {
+			resp[i] = transactionWebhookToResponse(hook)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"webhooks": resp,
+		}, http.StatusOK)
+	})
+}
+
+// handleDeleteTransactionWebhook returns a handler that removes a
+// transaction webhook.
+// DELETE /api/v1/webhooks/{id}
+func handleDeleteTransactionWebhook(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			writeError(w, "invalid webhook id", http.StatusBadRequest)
+			return
+		}
+
+		deleted, err := store.DeleteTransactionWebhook(r.Context(), id)
+		if err != nil {
+			logger.Error("failed to delete transaction webhook", "id", id, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			writeError(w, "webhook not found", http.StatusNotFound)
+			return
+		}
+
+		logger.Info("transaction webhook deleted", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// validateWebhookURL validates a transaction webhook's callback URL.
+func validateWebhookURL(raw string) error {
+	if raw == "" {
+		return errorf("callback_url is required")
+	}
+	if len(raw) > maxCallbackURLLen {
+		return errorf("callback_url too long: maximum length is %d", maxCallbackURLLen)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errorf("callback_url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// validateWebhookSecret validates the secret a transaction webhook's
+// deliveries are signed with.
+func validateWebhookSecret(secret string) error {
+	if secret == "" {
+		return errorf("secret is required")
+	}
+	if len(secret) < minWebhookSecretLen || len(secret) > maxWebhookSecretLen {
+		return errorf("secret must be between %d and %d characters", minWebhookSecretLen, maxWebhookSecretLen)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
+	"github.com/brojonat/forohtoo/service/temporal"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransactionWebhookStore serves webhooks and filters and records
+// delivery attempts.
+type fakeTransactionWebhookStore struct {
+	hooks    []*db.TransactionWebhook
+	filters  map[int64]*db.WalletFilter
+	listErr  error
+	mu       sync.Mutex
+	attempts []db.RecordWebhookDeliveryAttemptParams
+}
+
+func (s *fakeTransactionWebhookStore) ListTransactionWebhooks(_ context.Context, address string, network string) ([]*db.TransactionWebhook, error) {
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	var out []*db.TransactionWebhook
+	for _, h := range s.hooks {
+		if h.Address == address && h.Network == network {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeTransactionWebhookStore) GetWalletFilterByID(_ context.Context, id int64) (*db.WalletFilter, error) {
+	if f, ok := s.filters[id]; ok {
+		return f, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *fakeTransactionWebhookStore) RecordWebhookDeliveryAttempt(_ context.Context, params db.RecordWebhookDeliveryAttemptParams) (*db.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, params)
+	return &db.WebhookDelivery{}, nil
+}
+
+func (s *fakeTransactionWebhookStore) statuses() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, len(s.attempts))
+	for i, a := range s.attempts {
+		out[i] = a.Status
+	}
+	return out
+}
+
+func newTestTransactionWebhookDispatcher(store TransactionWebhookStore) *TransactionWebhookDispatcher {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	d := NewTransactionWebhookDispatcher(store, logger)
+	d.retryDelay = time.Millisecond
+	return d
+}
+
+func webhookEvent() *natspkg.TransactionEvent {
+	return &natspkg.TransactionEvent{
+		Signature:          "sig1",
+		WalletAddress:      "wallet1",
+		Network:            "mainnet",
+		Amount:             1000,
+		ConfirmationStatus: "confirmed",
+	}
+}
+
+func TestTransactionWebhookDispatcher_Dispatch(t *testing.T) {
+	var gotBody []byte
+	var gotTimestamp, gotSignature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotTimestamp = r.Header.Get(temporal.CallbackTimestampHeader)
+		gotSignature = r.Header.Get(temporal.CallbackSignatureHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	store := &fakeTransactionWebhookStore{hooks: []*db.TransactionWebhook{
+		{ID: 7, Address: "wallet1", Network: "mainnet", URL: srv.URL, Secret: "0123456789abcdef"},
+		{ID: 8, Address: "wallet2", Network: "mainnet", URL: srv.URL, Secret: "fedcba9876543210"},
+	}}
+	d := newTestTransactionWebhookDispatcher(store)
+
+	require.NoError(t, d.dispatch(context.Background(), webhookEvent()))
+
+	var decoded TransactionWebhookPayload
+	require.NoError(t, json.Unmarshal(gotBody, &decoded))
+	assert.Equal(t, transactionWebhookEvent, decoded.Event)
+	assert.Equal(t, int64(7), decoded.WebhookID)
+	assert.Equal(t, "sig1", decoded.Transaction.Signature)
+	assert.Equal(t, temporal.SignCallback("0123456789abcdef", gotTimestamp, gotBody), gotSignature)
+
+	require.Len(t, store.attempts, 1)
+	assert.Equal(t, "7:sig1:confirmed", store.attempts[0].SourceID)
+	assert.Equal(t, db.WebhookDeliveryDelivered, store.attempts[0].Status)
+	assert.Equal(t, http.StatusOK, store.attempts[0].StatusCode)
+}
+
+func TestTransactionWebhookDispatcher_Filter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	filterID := int64(3)
+	assetType := "sol"
+	minAmount := int64(5000)
+	store := &fakeTransactionWebhookStore{
+		hooks: []*db.TransactionWebhook{
+			{ID: 7, Address: "wallet1", Network: "mainnet", URL: srv.URL, Secret: "0123456789abcdef"},
+			{ID: 8, Address: "wallet1", Network: "mainnet", URL: srv.URL, Secret: "fedcba9876543210", FilterID: &filterID},
+		},
+		filters: map[int64]*db.WalletFilter{
+			filterID: {ID: filterID, Name: "big-sol", AssetType: &assetType, MinAmount: &minAmount, MemoMatch: "exact"},
+		},
+	}
+	d := newTestTransactionWebhookDispatcher(store)
+
+	// 1000 lamports is below the filter's minimum: only the unfiltered
+	// webhook gets it.
+	require.NoError(t, d.dispatch(context.Background(), webhookEvent()))
+	assert.Equal(t, int32(1), calls.Load())
+	require.Len(t, store.attempts, 1)
+	assert.Equal(t, "7:sig1:confirmed", store.attempts[0].SourceID)
+
+	big := webhookEvent()
+	big.Signature = "sig2"
+	big.Amount = 10000
+	require.NoError(t, d.dispatch(context.Background(), big))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTransactionWebhookDispatcher_FilterError(t *testing.T) {
+	filterID := int64(3)
+	store := &fakeTransactionWebhookStore{hooks: []*db.TransactionWebhook{
+		{ID: 7, Address: "wallet1", Network: "mainnet", URL: "http://127.0.0.1:1", Secret: "0123456789abcdef"},
+		{ID: 8, Address: "wallet1", Network: "mainnet", URL: "http://127.0.0.1:1", Secret: "fedcba9876543210", FilterID: &filterID},
+	}}
+	d := newTestTransactionWebhookDispatcher(store)
+
+	// The missing filter fails the dispatch before any webhook is tried.
+	err := d.dispatch(context.Background(), webhookEvent())
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	assert.Empty(t, store.attempts)
+}
+
+func TestTransactionWebhookDispatcher_Retries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	store := &fakeTransactionWebhookStore{hooks: []*db.TransactionWebhook{
+		{ID: 7, Address: "wallet1", Network: "mainnet", URL: srv.URL, Secret: "0123456789abcdef"},
+	}}
+	d := newTestTransactionWebhookDispatcher(store)
+
+	require.NoError(t, d.dispatch(context.Background(), webhookEvent()))
+	assert.Equal(t, []string{db.WebhookDeliveryRetrying, db.WebhookDeliveryRetrying, db.WebhookDeliveryDelivered}, store.statuses())
+	assert.Equal(t, http.StatusServiceUnavailable, store.attempts[0].StatusCode)
+	assert.Contains(t, store.attempts[0].Error, "503")
+}
+
+func TestTransactionWebhookDispatcher_GivesUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantCalls int32
+	}{
+		{name: "rejected", status: http.StatusBadRequest, wantCalls: 1},
+		{name: "attempts exhausted", status: http.StatusInternalServerError, wantCalls: maxTransactionWebhookAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			store := &fakeTransactionWebhookStore{hooks: []*db.TransactionWebhook{
+				{ID: 7, Address: "wallet1", Network: "mainnet", URL: srv.URL, Secret: "0123456789abcdef"},
+			}}
+			d := newTestTransactionWebhookDispatcher(store)
+
+			require.NoError(t, d.dispatch(context.Background(), webhookEvent()))
+			assert.Equal(t, tt.wantCalls, calls.Load())
+			statuses := store.statuses()
+			require.Len(t, statuses, int(tt.wantCalls))
+			assert.Equal(t, db.WebhookDeliveryFailed, statuses[len(statuses)-1])
+		})
+	}
+}
+
+func TestTransactionWebhookDispatcher_ListError(t *testing.T) {
+	store := &fakeTransactionWebhookStore{listErr: errors.New("db down")}
+	d := newTestTransactionWebhookDispatcher(store)
+
+	err := d.dispatch(context.Background(), webhookEvent())
+	assert.ErrorContains(t, err, "db down")
+	assert.Empty(t, store.attempts)
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{name: "valid", url: "https://example.com/hook"},
+		{name: "missing", url: "", wantErr: "callback_url is required"},
+		{name: "relative", url: "/hook", wantErr: "callback_url must be an absolute http(s) URL"},
+		{name: "wrong scheme", url: "ftp://example.com/hook", wantErr: "callback_url must be an absolute http(s) URL"},
+		{name: "too long", url: "https://example.com/" + strings.Repeat("a", maxCallbackURLLen), wantErr: fmt.Sprintf("callback_url too long: maximum length is %d", maxCallbackURLLen)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookURL(tt.url)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateWebhookSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr string
+	}{
+		{name: "valid", secret: "0123456789abcdef"},
+		{name: "missing", secret: "", wantErr: "secret is required"},
+		{name: "too short", secret: "short", wantErr: "secret must be between 16 and 256 characters"},
+		{name: "too long", secret: string(make([]byte, 257)), wantErr: "secret must be between 16 and 256 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookSecret(tt.secret)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+// validateAmountRangeQuery parses the amount range query parameters for the
+// wallet at address. Amounts are inclusive base units of a single asset:
+// lamports of native SOL when token_mint is omitted, otherwise base units of
+// that token. An omitted bound leaves that end of the range open.
+func validateAmountRangeQuery(address string, query url.Values) (db.ListTransactionsByAmountRangeParams, error) {
+	params := db.ListTransactionsByAmountRangeParams{
+		WalletAddress: address,
+		Network:       query.Get("network"),
+		Asset:         "sol",
+		MaxAmount:     math.MaxInt64,
+		Limit:         100,
+	}
+
+	if err := validateAddress(params.WalletAddress); err != nil {
+		return params, err
+	}
+	if err := validateNetwork(params.Network); err != nil {
+		return params, err
+	}
+
+	if raw := query.Get("min_amount"); raw != "" {
+		amount, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || amount < 0 {
+			return params, errorf("min_amount must be a non-negative integer")
+		}
+		params.MinAmount = amount
+	}
+	if raw := query.Get("max_amount"); raw != "" {
+		amount, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || amount < 0 {
+			return params, errorf("max_amount must be a non-negative integer")
+		}
+		params.MaxAmount = amount
+	}
+	if params.MinAmount > params.MaxAmount {
+		return params, errorf("min_amount cannot exceed max_amount")
+	}
+
+	if mint := query.Get("token_mint"); mint != "" {
+		if err := validateTokenMint(mint); err != nil {
+			return params, err
+		}
+		params.Asset = mint
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > 1000 {
+			return params, errorf("limit must be between 1 and 1000")
+		}
+		params.Limit = int32(limit)
+	}
+	if raw := query.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 || offset > math.MaxInt32 {
+			return params, errorf("offset must be a non-negative integer")
+		}
+		params.Offset = int32(offset)
+	}
+
+	return params, nil
+}
+
+// handleListTransactionsByAmount returns a handler that lists a wallet's
+// stored transactions in one asset whose amount is within a range, newest
+// first. It is the historical counterpart to an amount-matching Await, e.g.
+// every payment of exactly 10 USDC.
+// GET /api/v1/wallets/{address}/transactions?network=&min_amount=&max_amount=&token_mint=&limit=&offset=
+func handleListTransactionsByAmount(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, err := validateAmountRangeQuery(r.PathValue("address"), r.URL.Query())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		transactions, err := store.ListTransactionsByAmountRange(r.Context(), params)
+		if err != nil {
+			logger.Error("failed to list transactions by amount", "wallet", params.WalletAddress, "network", params.Network, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		decimals, err := mintDecimals(r.Context(), store)
+		if err != nil {
+			logger.Warn("failed to load mint decimals", "error", err)
+		}
+
+		resp := make([]transactionResponse, len(transactions))
+		for i := range transactions {
+			resp[i] = transactionToResponse(transactions[i])
+			resp[i].Decimals = transactionDecimals(transactions[i], decimals)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"transactions": resp,
+			"count":        len(resp),
+			"asset":        params.Asset,
+			"min_amount":   params.MinAmount,
+			"max_amount":   params.MaxAmount,
+			"limit":        params.Limit,
+			"offset":       params.Offset,
+		}, http.StatusOK)
+	})
+}
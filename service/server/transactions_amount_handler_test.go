@@ -0,0 +1,74 @@
+package server
+
+import (
+	"math"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAmountRangeQuery(t *testing.T) {
+	const wallet = "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
+	const usdc = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	base := func(extra url.Values) url.Values {
+		q := url.Values{"network": {"mainnet"}}
+		for k, v := range extra {
+			q[k] = v
+		}
+		return q
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		params, err := validateAmountRangeQuery(wallet, base(nil))
+		require.NoError(t, err)
+		assert.Equal(t, wallet, params.WalletAddress)
+		assert.Equal(t, "sol", params.Asset, "native SOL without token_mint")
+		assert.Equal(t, int64(0), params.MinAmount)
+		assert.Equal(t, int64(math.MaxInt64), params.MaxAmount)
+		assert.Equal(t, int32(100), params.Limit)
+		assert.Equal(t, int32(0), params.Offset)
+	})
+
+	t.Run("exact token amount", func(t *testing.T) {
+		params, err := validateAmountRangeQuery(wallet, base(url.Values{
+			"token_mint": {usdc},
+			"min_amount": {"10000000"},
+			"max_amount": {"10000000"},
+			"limit":      {"10"},
+			"offset":     {"20"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, usdc, params.Asset)
+		assert.Equal(t, int64(10000000), params.MinAmount)
+		assert.Equal(t, int64(10000000), params.MaxAmount)
+		assert.Equal(t, int32(10), params.Limit)
+		assert.Equal(t, int32(20), params.Offset)
+	})
+
+	tests := []struct {
+		name    string
+		address string
+		query   url.Values
+		wantErr string
+	}{
+		{"invalid address", "not-base58-0OIl", base(nil), "address"},
+		{"invalid network", wallet, base(url.Values{"network": {"testnet"}}), "network"},
+		{"negative min", wallet, base(url.Values{"min_amount": {"-1"}}), "min_amount"},
+		{"decimal max", wallet, base(url.Values{"max_amount": {"10.5"}}), "max_amount"},
+		{"inverted range", wallet, base(url.Values{"min_amount": {"10"}, "max_amount": {"5"}}), "cannot exceed"},
+		{"invalid mint", wallet, base(url.Values{"token_mint": {"0OIl"}}), "invalid token_mint"},
+		{"limit too large", wallet, base(url.Values{"limit": {"1001"}}), "limit"},
+		{"negative offset", wallet, base(url.Values{"offset": {"-1"}}), "offset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateAmountRangeQuery(tt.address, tt.query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
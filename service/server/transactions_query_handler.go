@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+const (
+	maxQueryWallets    = 50 // wallets per batch transaction query
+	defaultQueryLimit  = 100
+	maxQueryLimit      = 1000
+	walletKeySeparator = "|"
+)
+
+// walletKeyRequest identifies one wallet in a batch transaction query.
+type walletKeyRequest struct {
+	Address string `json:"address"`
+	Network string `json:"network"`
+}
+
+// transactionsQueryRequest is the body of a batch transaction query.
+type transactionsQueryRequest struct {
+	Wallets []walletKeyRequest `json:"wallets"`
+	Start   *time.Time         `json:"start,omitempty"` // default: unbounded
+	End     *time.Time         `json:"end,omitempty"`   // default: now
+	Limit   int                `json:"limit,omitempty"` // per wallet; default 100, max 1000
+}
+
+// walletTransactionsResponse groups query results for one wallet.
+type walletTransactionsResponse struct {
+	Address      string                `json:"address"`
+	Network      string                `json:"network"`
+	Transactions []transactionResponse `json:"transactions"`
+	Count        int                   `json:"count"`
+}
+
+// validateTransactionsQuery checks a batch query and converts it to store
+// parameters. Duplicate wallets are collapsed, preserving request order.
+func validateTransactionsQuery(req transactionsQueryRequest, now time.Time) (db.ListTransactionsByWalletsParams, error) {
+	var params db.ListTransactionsByWalletsParams
+
+	if len(req.Wallets) == 0 {
+		return params, errorf("wallets is required")
+	}
+	if len(req.Wallets) > maxQueryWallets {
+		return params, errorf("too many wallets: maximum is %d per query", maxQueryWallets)
+	}
+
+	seen := make(map[string]bool, len(req.Wallets))
+	for _, w := range req.Wallets {
+		if err := validateAddress(w.Address); err != nil {
+			return params, err
+		}
+		if err := validateNetwork(w.Network); err != nil {
+			return params, err
+		}
+		key := w.Address + walletKeySeparator + w.Network
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		params.Wallets = append(params.Wallets, db.WalletKey{Address: w.Address, Network: w.Network})
+	}
+
+	params.LimitPerWallet = defaultQueryLimit
+	if req.Limit != 0 {
+		if req.Limit < 1 {
+			return params, errorf("limit must be at least 1")
+		}
+		if req.Limit > maxQueryLimit {
+			return params, errorf("limit cannot exceed %d", maxQueryLimit)
+		}
+		params.LimitPerWallet = int32(req.Limit)
+	}
+
+	params.StartTime = time.Unix(0, 0).UTC()
+	if req.Start != nil {
+		params.StartTime = *req.Start
+	}
+	params.EndTime = now
+	if req.End != nil {
+		params.EndTime = *req.End
+	}
+	if params.EndTime.Before(params.StartTime) {
+		return params, errorf("end must not be before start")
+	}
+
+	return params, nil
+}
+
+// handleQueryTransactions returns a handler that lists recent transactions for
+// several wallets in one request, grouped by wallet. It is backed by a single
+// query, so dashboards showing many wallets don't need one request per wallet.
+// POST /api/v1/transactions/query
+func handleQueryTransactions(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+		var req transactionsQueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Debug("failed to decode transactions query", "error", err)
+			if strings.Contains(err.Error(), "http: request body too large") {
+				writeError(w, "request body too large: maximum size is 1MB", http.StatusBadRequest)
+				return
+			}
+			writeError(w, "invalid request body: must be valid JSON", http.StatusBadRequest)
+			return
+		}
+
+		params, err := validateTransactionsQuery(req, time.Now())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		transactions, err := store.ListTransactionsByWallets(r.Context(), params)
+		if err != nil {
+			logger.Error("failed to query transactions", "wallets", len(params.Wallets), "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		// Decimals are an enhancement, so a lookup failure doesn't fail the request.
+		decimals, err := mintDecimals(r.Context(), store)
+		if err != nil {
+			logger.Warn("failed to load mint decimals", "error", err)
+		}
+
+		resp := groupTransactionsByWallet(params.Wallets, transactions, decimals)
+
+		logger.Debug("transactions queried", "wallets", len(params.Wallets), "count", len(transactions))
+
+		writeJSON(w, map[string]interface{}{
+			"wallets": resp,
+			"limit":   params.LimitPerWallet,
+			"start":   params.StartTime,
+			"end":     params.EndTime,
+		}, http.StatusOK)
+	})
+}
+
+// groupTransactionsByWallet returns one entry per requested wallet, in request
+// order, including wallets with no transactions.
+func groupTransactionsByWallet(wallets []db.WalletKey, transactions []*db.Transaction, decimals map[string]int) []walletTransactionsResponse {
+	resp := make([]walletTransactionsResponse, len(wallets))
+	index := make(map[string]int, len(wallets))
+	for i, w := range wallets {
+		resp[i] = walletTransactionsResponse{
+			Address:      w.Address,
+			Network:      w.Network,
+			Transactions: []transactionResponse{},
+		}
+		index[w.Address+walletKeySeparator+w.Network] = i
+	}
+
+	for _, t := range transactions {
+		i, ok := index[t.WalletAddress+walletKeySeparator+t.Network]
+		if !ok {
+			continue
+		}
+		tr := transactionToResponse(t)
+		tr.Decimals = transactionDecimals(t, decimals)
+		resp[i].Transactions = append(resp[i].Transactions, tr)
+		resp[i].Count++
+	}
+
+	return resp
+}
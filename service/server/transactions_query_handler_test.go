@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const queryTestAddress = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+func TestValidateTransactionsQuery(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mainnet := walletKeyRequest{Address: queryTestAddress, Network: "mainnet"}
+	devnet := walletKeyRequest{Address: queryTestAddress, Network: "devnet"}
+
+	t.Run("defaults", func(t *testing.T) {
+		params, err := validateTransactionsQuery(transactionsQueryRequest{Wallets: []walletKeyRequest{mainnet}}, now)
+		require.NoError(t, err)
+		assert.Equal(t, []db.WalletKey{{Address: queryTestAddress, Network: "mainnet"}}, params.Wallets)
+		assert.Equal(t, int32(defaultQueryLimit), params.LimitPerWallet)
+		assert.Equal(t, time.Unix(0, 0).UTC(), params.StartTime)
+		assert.Equal(t, now, params.EndTime)
+	})
+
+	t.Run("collapses duplicates", func(t *testing.T) {
+		req := transactionsQueryRequest{Wallets: []walletKeyRequest{mainnet, devnet, mainnet}}
+		params, err := validateTransactionsQuery(req, now)
+		require.NoError(t, err)
+		assert.Equal(t, []db.WalletKey{
+			{Address: queryTestAddress, Network: "mainnet"},
+			{Address: queryTestAddress, Network: "devnet"},
+		}, params.Wallets)
+	})
+
+	tooMany := make([]walletKeyRequest, maxQueryWallets+1)
+	for i := range tooMany {
+		tooMany[i] = mainnet
+	}
+	start := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		req     transactionsQueryRequest
+		wantErr string
+	}{
+		{name: "no wallets", req: transactionsQueryRequest{}, wantErr: "wallets is required"},
+		{name: "too many wallets", req: transactionsQueryRequest{Wallets: tooMany}, wantErr: fmt.Sprintf("maximum is %d", maxQueryWallets)},
+		{name: "invalid address", req: transactionsQueryRequest{Wallets: []walletKeyRequest{{Address: "not0valid", Network: "mainnet"}}}, wantErr: "invalid"},
+		{name: "invalid network", req: transactionsQueryRequest{Wallets: []walletKeyRequest{{Address: queryTestAddress, Network: "testnet"}}}, wantErr: "network"},
+		{name: "limit too large", req: transactionsQueryRequest{Wallets: []walletKeyRequest{mainnet}, Limit: maxQueryLimit + 1}, wantErr: "limit cannot exceed"},
+		{name: "negative limit", req: transactionsQueryRequest{Wallets: []walletKeyRequest{mainnet}, Limit: -1}, wantErr: "limit must be at least 1"},
+		{name: "end before start", req: transactionsQueryRequest{Wallets: []walletKeyRequest{mainnet}, Start: &start}, wantErr: "end must not be before start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateTransactionsQuery(tt.req, now)
+			require.Error(t, err)
+			assert.Contains(t, strings.ToLower(err.Error()), tt.wantErr)
+		})
+	}
+}
+
+func TestGroupTransactionsByWallet(t *testing.T) {
+	wallets := []db.WalletKey{
+		{Address: "walletA", Network: "mainnet"},
+		{Address: "walletB", Network: "mainnet"},
+		{Address: "walletA", Network: "devnet"},
+	}
+	transactions := []*db.Transaction{
+		{Signature: "a1", WalletAddress: "walletA", Network: "mainnet"},
+		{Signature: "a2", WalletAddress: "walletA", Network: "mainnet"},
+		{Signature: "d1", WalletAddress: "walletA", Network: "devnet"},
+	}
+
+	resp := groupTransactionsByWallet(wallets, transactions, nil)
+	require.Len(t, resp, 3)
+
+	assert.Equal(t, 2, resp[0].Count)
+	assert.Equal(t, "a1", resp[0].Transactions[0].Signature)
+	assert.Equal(t, "a2", resp[0].Transactions[1].Signature)
+
+	// Wallets without transactions are still present, with an empty list.
+	assert.Equal(t, "walletB", resp[1].Address)
+	assert.Equal(t, 0, resp[1].Count)
+	assert.NotNil(t, resp[1].Transactions)
+
+	assert.Equal(t, "devnet", resp[2].Network)
+	assert.Equal(t, 1, resp[2].Count)
+}
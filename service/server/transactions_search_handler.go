@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/brojonat/forohtoo/service/db"
+)
+
+// maxMemoQueryLength bounds memo search queries. Solana memos are limited to
+// 566 bytes, so nothing longer can match.
+const maxMemoQueryLength = 566
+
+// handleSearchTransactions returns a handler that searches a wallet's transactions by memo.
+// GET /api/v1/transactions/search?wallet_address=ADDRESS&network=NETWORK&q=TEXT&match=contains|prefix&limit=N
+func handleSearchTransactions(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		walletAddress := query.Get("wallet_address")
+		network := query.Get("network")
+		q := query.Get("q")
+
+		if walletAddress == "" {
+			writeError(w, "wallet_address query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := validateNetwork(network); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := validateAddress(walletAddress); err != nil {
+			logger.Debug("invalid address", "address", walletAddress, "error", err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if q == "" {
+			writeError(w, "q query parameter is required", http.StatusBadRequest)
+			return
+		}
+		if len(q) > maxMemoQueryLength {
+			writeError(w, fmt.Sprintf("q cannot exceed %d bytes", maxMemoQueryLength), http.StatusBadRequest)
+			return
+		}
+
+		var prefix bool
+		switch match := query.Get("match"); match {
+		case "", "contains":
+		case "prefix":
+			prefix = true
+		default:
+			writeError(w, "match must be 'contains' or 'prefix'", http.StatusBadRequest)
+			return
+		}
+
+		// Parse limit (default 100, max 1000)
+		limit := int32(100)
+		if limitStr := query.Get("limit"); limitStr != "" {
+			var parsedLimit int
+			if _, err := fmt.Sscanf(limitStr, "%d", &parsedLimit); err != nil {
+				writeError(w, "invalid limit parameter: must be an integer", http.StatusBadRequest)
+				return
+			}
+			if parsedLimit < 1 {
+				writeError(w, "limit must be at least 1", http.StatusBadRequest)
+				return
+			}
+			if parsedLimit > 1000 {
+				writeError(w, "limit cannot exceed 1000", http.StatusBadRequest)
+				return
+			}
+			limit = int32(parsedLimit)
+		}
+
+		transactions, err := store.SearchTransactionsByMemo(r.Context(), db.SearchTransactionsByMemoParams{
+			WalletAddress: walletAddress,
+			Network:       network,
+			Query:         q,
+			Prefix:        prefix,
+			Limit:         limit,
+		})
+		if err != nil {
+			logger.Error("failed to search transactions", "wallet", walletAddress, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Debug("transactions searched", "wallet", walletAddress, "network", network, "count", len(transactions))
+
+		decimals, err := mintDecimals(r.Context(), store)
+		if err != nil {
+			logger.Warn("failed to load mint decimals", "error", err)
+		}
+
+		resp := make([]transactionResponse, len(transactions))
+		for i := range transactions {
+			resp[i] = transactionToResponse(transactions[i])
+			resp[i].Decimals = transactionDecimals(transactions[i], decimals)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"transactions": resp,
+			"count":        len(resp),
+			"limit":        limit,
+		}, http.StatusOK)
+	})
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSearchTransactions_Validation covers the request validation that
+// happens before the store is touched.
+func TestSearchTransactions_Validation(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := handleSearchTransactions(nil, logger)
+
+	const wallet = "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
+
+	tests := []struct {
+		name    string
+		params  url.Values
+		wantErr string
+	}{
+		{
+			name:    "missing wallet",
+			params:  url.Values{"network": {"mainnet"}, "q": {"invoice"}},
+			wantErr: "wallet_address query parameter is required",
+		},
+		{
+			name:    "invalid network",
+			params:  url.Values{"wallet_address": {wallet}, "network": {"testnet"}, "q": {"invoice"}},
+			wantErr: "network",
+		},
+		{
+			name:    "invalid wallet",
+			params:  url.Values{"wallet_address": {"not-a-wallet"}, "network": {"mainnet"}, "q": {"invoice"}},
+			wantErr: "invalid",
+		},
+		{
+			name:    "missing query",
+			params:  url.Values{"wallet_address": {wallet}, "network": {"mainnet"}},
+			wantErr: "q query parameter is required",
+		},
+		{
+			name:    "query too long",
+			params:  url.Values{"wallet_address": {wallet}, "network": {"mainnet"}, "q": {strings.Repeat("x", maxMemoQueryLength+1)}},
+			wantErr: "q cannot exceed",
+		},
+		{
+			name:    "unknown match mode",
+			params:  url.Values{"wallet_address": {wallet}, "network": {"mainnet"}, "q": {"invoice"}, "match": {"regex"}},
+			wantErr: "match must be",
+		},
+		{
+			name:    "limit too large",
+			params:  url.Values{"wallet_address": {wallet}, "network": {"mainnet"}, "q": {"invoice"}, "limit": {"1001"}},
+			wantErr: "limit cannot exceed 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/transactions/search?"+tt.params.Encode(), nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantErr)
+		})
+	}
+}
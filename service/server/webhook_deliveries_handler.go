@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/temporal"
+	"github.com/jackc/pgx/v5"
+	"go.temporal.io/sdk/client"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 1000
+)
+
+// errUnsupportedWebhookEvent is returned when asked to redeliver an event
+// this server doesn't know how to send.
+var errUnsupportedWebhookEvent = errors.New("webhook event cannot be redelivered")
+
+// webhookDeliveryResponse is the JSON response format for a webhook delivery.
+type webhookDeliveryResponse struct {
+	ID             int64           `json:"id"`
+	Event          string          `json:"event"`
+	SourceID       string          `json:"source_id"`
+	Network        string          `json:"network"`
+	URL            string          `json:"url"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	LastAttemptAt  time.Time       `json:"last_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func webhookDeliveryToResponse(d *db.WebhookDelivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		ID:             d.ID,
+		Event:          d.Event,
+		SourceID:       d.SourceID,
+		Network:        d.Network,
+		URL:            d.URL,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		LastAttemptAt:  d.LastAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+// validateWebhookDeliveriesQuery parses the status, limit and offset query
+// parameters.
+func validateWebhookDeliveriesQuery(query url.Values) (db.ListWebhookDeliveriesParams, error) {
+	params := db.ListWebhookDeliveriesParams{Limit: defaultWebhookDeliveriesLimit}
+
+	if status := query.Get("status"); status != "" {
+		if !slices.Contains(db.WebhookDeliveryStatuses, status) {
+			return params, errorf("status must be one of: %s", strings.Join(db.WebhookDeliveryStatuses, ", "))
+		}
+		params.Status = status
+	}
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxWebhookDeliveriesLimit {
+			return params, errorf("limit must be between 1 and %d", maxWebhookDeliveriesLimit)
+		}
+		params.Limit = int32(n)
+	}
+	if raw := query.Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > math.MaxInt32 {
+			return params, errorf("offset must be a non-negative integer")
+		}
+		params.Offset = int32(n)
+	}
+	return params, nil
+}
+
+// handleListWebhookDeliveries returns a handler that lists outbound webhook
+// deliveries by most recent attempt, newest first. ?status=failed lists the
+// ones that gave up.
+// GET /api/v1/admin/webhooks?status=&limit=&offset=
+func handleListWebhookDeliveries(store *db.Store, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, err := validateWebhookDeliveriesQuery(r.URL.Query())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		deliveries, err := store.ListWebhookDeliveries(r.Context(), params)
+		if err != nil {
+			logger.Error("failed to list webhook deliveries", "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]webhookDeliveryResponse, len(deliveries))
+		for i, d := range deliveries {
+			resp[i] = webhookDeliveryToResponse(d)
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"deliveries": resp,
+			"count":      len(resp),
+			"status":     params.Status,
+			"limit":      params.Limit,
+			"offset":     params.Offset,
+		}, http.StatusOK)
+	})
+}
+
+// startWebhookRedelivery starts a new PaymentCallbackWorkflow sending the
+// stored payload to the stored URL, and returns its workflow ID. Its attempts
+// are recorded on the same delivery. Redeliveries started within the same
+// second share a workflow ID, so a repeated request doesn't send twice.
+func startWebhookRedelivery(ctx context.Context, starter workflowStarter, taskQueue string, d *db.WebhookDelivery, now time.Time) (string, error) {
+	if d.Event != temporal.PaymentCallbackEvent {
+		return "", errUnsupportedWebhookEvent
+	}
+	var payload temporal.PaymentCallbackPayload
+	if err := json.Unmarshal(d.Payload, &payload); err != nil {
+		return "", fmt.Errorf("failed to decode stored payload: %w", err)
+	}
+
+	workflowID := fmt.Sprintf("%s:callback:redeliver-%d", d.SourceID, now.Unix())
+	options := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: taskQueue,
+	}
+	input := temporal.DeliverPaymentCallbackInput{URL: d.URL, Payload: payload}
+	if _, err := starter.ExecuteWorkflow(ctx, options, "PaymentCallbackWorkflow", input); err != nil {
+		return "", err
+	}
+	return workflowID, nil
+}
+
+// handleRedeliverWebhook returns a handler that sends a stored webhook
+// delivery again, with the usual retries, for when the consumer's endpoint
+// was down. Any delivery can be resent, including delivered ones.
+// POST /api/v1/admin/webhooks/{id}/redeliver
+func handleRedeliverWebhook(store *db.Store, starter workflowStarter, taskQueue string, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			writeError(w, "invalid webhook delivery id", http.StatusBadRequest)
+			return
+		}
+
+		delivery, err := store.GetWebhookDelivery(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeError(w, "webhook delivery not found", http.StatusNotFound)
+				return
+			}
+			logger.Error("failed to get webhook delivery", "id", id, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		workflowID, err := startWebhookRedelivery(r.Context(), starter, taskQueue, delivery, time.Now())
+		if err != nil {
+			if errors.Is(err, errUnsupportedWebhookEvent) {
+				writeError(w, err.Error(), http.StatusConflict)
+				return
+			}
+			logger.Error("failed to start webhook redelivery", "id", id, "error", err)
+			writeError(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("webhook redelivery started", "id", id, "event", delivery.Event, "workflow_id", workflowID)
+		writeJSON(w, map[string]interface{}{
+			"id":          id,
+			"workflow_id": workflowID,
+		}, http.StatusAccepted)
+	})
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/temporal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+func TestValidateWebhookDeliveriesQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   url.Values
+		want    db.ListWebhookDeliveriesParams
+		wantErr string
+	}{
+		{
+			name:  "defaults",
+			query: url.Values{},
+			want:  db.ListWebhookDeliveriesParams{Limit: defaultWebhookDeliveriesLimit},
+		},
+		{
+			name:  "all params",
+			query: url.Values{"status": {"failed"}, "limit": {"10"}, "offset": {"20"}},
+			want:  db.ListWebhookDeliveriesParams{Status: db.WebhookDeliveryFailed, Limit: 10, Offset: 20},
+		},
+		{
+			name:    "unknown status",
+			query:   url.Values{"status": {"pending"}},
+			wantErr: "status must be one of",
+		},
+		{
+			name:    "limit too large",
+			query:   url.Values{"limit": {"1001"}},
+			wantErr: "limit must be between 1 and 1000",
+		},
+		{
+			name:    "limit zero",
+			query:   url.Values{"limit": {"0"}},
+			wantErr: "limit must be between 1 and 1000",
+		},
+		{
+			name:    "negative offset",
+			query:   url.Values{"offset": {"-1"}},
+			wantErr: "offset must be a non-negative integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateWebhookDeliveriesQuery(tt.query)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// recordingWorkflowStarter records the workflows it is asked to start.
+type recordingWorkflowStarter struct {
+	options  []client.StartWorkflowOptions
+	workflow []interface{}
+	args     [][]interface{}
+	err      error
+}
+
+func (r *recordingWorkflowStarter) ExecuteWorkflow(_ context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (client.WorkflowRun, error) {
+	r.options = append(r.options, options)
+	r.workflow = append(r.workflow, workflow)
+	r.args = append(r.args, args)
+	return nil, r.err
+}
+
+func TestStartWebhookRedelivery(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := temporal.PaymentCallbackPayload{
+		Event:      temporal.PaymentCallbackEvent,
+		WorkflowID: "payment-registration:mainnet:wallet1",
+		Address:    "wallet1",
+		Network:    "mainnet",
+	}
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	delivery := &db.WebhookDelivery{
+		ID:       7,
+		Event:    temporal.PaymentCallbackEvent,
+		SourceID: payload.WorkflowID,
+		URL:      "https://example.com/hook",
+		Payload:  raw,
+		Status:   db.WebhookDeliveryFailed,
+	}
+
+	t.Run("starts callback workflow", func(t *testing.T) {
+		starter := &recordingWorkflowStarter{}
+		workflowID, err := startWebhookRedelivery(context.Background(), starter, "forohtoo", delivery, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, "payment-registration:mainnet:wallet1:callback:redeliver-1700000000", workflowID)
+		require.Len(t, starter.options, 1)
+		assert.Equal(t, workflowID, starter.options[0].ID)
+		assert.Equal(t, "forohtoo", starter.options[0].TaskQueue)
+		assert.Equal(t, "PaymentCallbackWorkflow", starter.workflow[0])
+		assert.Equal(t, []interface{}{temporal.DeliverPaymentCallbackInput{URL: delivery.URL, Payload: payload}}, starter.args[0])
+	})
+
+	t.Run("start error", func(t *testing.T) {
+		starter := &recordingWorkflowStarter{err: errors.New("temporal unavailable")}
+		_, err := startWebhookRedelivery(context.Background(), starter, "forohtoo", delivery, now)
+		assert.EqualError(t, err, "temporal unavailable")
+	})
+
+	t.Run("unsupported event", func(t *testing.T) {
+		other := *delivery
+		other.Event = "digest.sent"
+		starter := &recordingWorkflowStarter{}
+		_, err := startWebhookRedelivery(context.Background(), starter, "forohtoo", &other, now)
+		assert.ErrorIs(t, err, errUnsupportedWebhookEvent)
+		assert.Empty(t, starter.options)
+	})
+
+	t.Run("corrupt payload", func(t *testing.T) {
+		other := *delivery
+		other.Payload = json.RawMessage(`"not an object"`)
+		starter := &recordingWorkflowStarter{}
+		_, err := startWebhookRedelivery(context.Background(), starter, "forohtoo", &other, now)
+		assert.ErrorContains(t, err, "failed to decode stored payload")
+		assert.Empty(t, starter.options)
+	})
+}
@@ -2,14 +2,17 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/brojonat/forohtoo/service/db"
 	"github.com/brojonat/forohtoo/service/helius"
+	"github.com/brojonat/forohtoo/service/metrics"
 	natspkg "github.com/brojonat/forohtoo/service/nats"
 )
 
@@ -24,6 +27,8 @@ func handleHeliusWebhook(
 	store *db.Store,
 	publisher natspkg.Publisher,
 	authToken string,
+	opts ingestOptions,
+	payloads *payloadLogger,
 	logger *slog.Logger,
 ) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -72,67 +77,273 @@ func handleHeliusWebhook(
 			return
 		}
 
-		// Parse transactions and match against registered wallets
-		params := helius.ParseEnhancedTransactions(txns, addressMap, logger)
-
-		if len(params) == 0 {
-			logger.Debug("no transactions matched registered wallets",
-				"transaction_count", len(txns),
-			)
+		result := ingestTransactions(r.Context(), store, publisher, txns, addressMap, "webhook", opts, payloads, logger)
+		if result.Matched == 0 {
 			w.WriteHeader(http.StatusOK)
 			return
 		}
 
-		// Write matched transactions to database and publish to NATS
-		written := 0
-		skipped := 0
-		var writtenTxns []*db.Transaction
+		logger.Info("processed Helius webhook",
+			"request_id", metrics.RequestIDFromContext(r.Context()),
+			"received", len(txns),
+			"matched", result.Matched,
+			"written", len(result.Written),
+			"skipped", result.Skipped,
+		)
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// ingestOptions configures how ingestTransactions prepares transactions for
+// writing.
+type ingestOptions struct {
+	MaxMemoLength  int              // memos longer than this many bytes are truncated; 0 means unlimited
+	PaymentIDField string           // JSON memo field holding a logical payment ID; empty disables the check
+	Metrics        *metrics.Metrics // optional
+	PaymentWallet  string           // payment gateway service wallet, for the payment detection latency metric
+	PaymentNetwork string
+	WriteBatchSize int  // matched transactions written per round trip; 0 or 1 writes row by row
+	RecordOutgoing bool // also store transfers sent by monitored wallets
+}
 
+// ingestResult summarizes an ingestTransactions call.
+type ingestResult struct {
+	Matched int               // transfers involving a monitored address
+	Written []*db.Transaction // newly stored transactions
+	Skipped int               // already stored (duplicates)
+	Failed  int               // write errors
+}
+
+// ingestTransactions matches Helius enhanced transactions against the
+// monitored addresses in addressMap, writes the matches to the database and
+// publishes the newly written ones to NATS. Transactions that are already
+// stored are skipped, so ingesting the same transaction twice is safe. stage
+// labels payload log lines ("webhook", "ingest"). Memos longer than
+// opts.MaxMemoLength bytes are truncated before writing, and a transaction
+// whose memo repeats the payment ID of one already stored for the same wallet
+// is written flagged duplicate_logical rather than dropped; transfers a
+// wallet sent (with opts.RecordOutgoing) never carry a payment ID. Matches are
+// written opts.WriteBatchSize per round trip, falling back to one at a time
+// when a batch fails.
+func ingestTransactions(
+	ctx context.Context,
+	store *db.Store,
+	publisher natspkg.Publisher,
+	txns []helius.EnhancedTransaction,
+	addressMap map[string]helius.WalletLookup,
+	stage string,
+	opts ingestOptions,
+	payloads *payloadLogger,
+	logger *slog.Logger,
+) ingestResult {
+	var result ingestResult
+
+	// Parse transactions and match against registered wallets
+	params := helius.ParseEnhancedTransactions(txns, addressMap, helius.ParseOptions{
+		RecordOutgoing: opts.RecordOutgoing,
+	}, logger)
+	result.Matched = len(params)
+
+	for i := range params {
+		// Read the payment ID before truncation can cut the memo's JSON short.
+		if params[i].Direction != db.DirectionOut {
+			params[i].PaymentID = extractPaymentID(params[i].Memo, opts.PaymentIDField)
+		}
+
+		memo, truncated := truncateMemo(params[i].Memo, opts.MaxMemoLength)
+		if truncated {
+			logger.Debug("truncated oversized memo",
+				"signature", params[i].Signature,
+				"memo_bytes", len(*params[i].Memo),
+				"max_memo_length", opts.MaxMemoLength,
+			)
+			params[i].Memo = memo
+			params[i].MemoTruncated = true
+		}
+	}
+
+	if payloads != nil {
+		matched := make(map[string]bool, len(params))
 		for _, p := range params {
-			dbTxn, err := store.CreateTransaction(r.Context(), p)
+			matched[p.Signature] = true
+		}
+		for _, txn := range txns {
+			if !matched[txn.Signature] {
+				payloads.Log(ctx, stage, payloadUnmatched, txn.Signature, txn)
+			}
+		}
+	}
+
+	if len(params) == 0 {
+		logger.Debug("no transactions matched registered wallets",
+			"transaction_count", len(txns),
+		)
+		return result
+	}
+
+	// Write matched transactions to database
+	written := func(p db.CreateTransactionParams, dbTxn *db.Transaction) {
+		payloads.Log(ctx, stage, payloadWritten, p.Signature, p)
+		result.Written = append(result.Written, dbTxn)
+		recordIngestionLatency(ctx, opts, dbTxn)
+
+		if dbTxn.DuplicateLogical {
+			logger.Warn("transaction repeats a logical payment",
+				"signature", dbTxn.Signature,
+				"wallet_address", dbTxn.WalletAddress,
+				"network", dbTxn.Network,
+				"payment_id", *dbTxn.PaymentID,
+			)
+			if opts.Metrics != nil {
+				opts.Metrics.RecordLogicalPaymentDuplicate(dbTxn.Network)
+			}
+		}
+	}
+
+	batchSize := max(opts.WriteBatchSize, 1)
+	for start := 0; start < len(params); start += batchSize {
+		batch := params[start:min(start+batchSize, len(params))]
+
+		if len(batch) > 1 {
+			stored, err := store.CreateTransactions(ctx, batch)
+			if err == nil {
+				byRow := make(map[string]*db.Transaction, len(stored))
+				for _, txn := range stored {
+					byRow[transactionRowKey(txn.Signature, txn.Network, txn.WalletAddress, txn.Direction)] = txn
+				}
+				for _, p := range batch {
+					key := transactionRowKey(p.Signature, p.Network, p.WalletAddress, p.Direction)
+					if dbTxn, ok := byRow[key]; ok {
+						delete(byRow, key)
+						written(p, dbTxn)
+						continue
+					}
+					result.Skipped++
+					payloads.Log(ctx, stage, payloadSkipped, p.Signature, p)
+				}
+				continue
+			}
+			logger.Warn("batched transaction write failed, writing row by row",
+				"count", len(batch),
+				"error", err,
+			)
+		}
+
+		for _, p := range batch {
+			dbTxn, err := store.CreateTransaction(ctx, p)
 			if err != nil {
 				if isDuplicateError(err) {
-					skipped++
+					result.Skipped++
+					payloads.Log(ctx, stage, payloadSkipped, p.Signature, p)
 					continue
 				}
 				logger.Error("failed to write transaction",
 					"signature", p.Signature,
 					"error", err,
 				)
+				result.Failed++
+				payloads.Log(ctx, stage, payloadFailed, p.Signature, p)
 				continue
 			}
-			written++
-			writtenTxns = append(writtenTxns, dbTxn)
+			written(p, dbTxn)
 		}
+	}
 
-		// Publish to NATS for SSE subscribers
-		if len(writtenTxns) > 0 && publisher != nil {
-			events := make([]*natspkg.TransactionEvent, 0, len(writtenTxns))
-			for _, txn := range writtenTxns {
-				events = append(events, natspkg.FromDBTransaction(txn))
-			}
+	// Publish to NATS for SSE subscribers
+	if len(result.Written) > 0 && publisher != nil {
+		events := make([]*natspkg.TransactionEvent, 0, len(result.Written))
+		for _, txn := range result.Written {
+			events = append(events, natspkg.FromDBTransaction(txn))
+		}
 
-			if err := publisher.PublishTransactionBatch(r.Context(), events); err != nil {
-				logger.Error("failed to publish transactions to NATS",
-					"count", len(events),
-					"error", err,
-				)
-			} else {
-				logger.Debug("published webhook transactions to NATS",
-					"count", len(events),
-				)
+		if err := publisher.PublishTransactionBatch(ctx, events); err != nil {
+			logger.Error("failed to publish transactions to NATS",
+				"count", len(events),
+				"error", err,
+			)
+		} else {
+			logger.Debug("published transactions to NATS",
+				"stage", stage,
+				"count", len(events),
+			)
+			for _, event := range events {
+				payloads.Log(ctx, "nats", payloadPublished, event.Signature, event)
 			}
 		}
+	}
 
-		logger.Info("processed Helius webhook",
-			"received", len(txns),
-			"matched", len(params),
-			"written", written,
-			"skipped", skipped,
-		)
+	return result
+}
 
-		w.WriteHeader(http.StatusOK)
-	})
+// transactionRowKey identifies a stored transaction row. A transfer between
+// two monitored wallets is stored twice under one signature, once for each
+// wallet and direction.
+func transactionRowKey(signature, network, wallet, direction string) string {
+	if direction == "" {
+		direction = db.DirectionIn
+	}
+	return network + ":" + signature + ":" + wallet + ":" + direction
+}
+
+// recordIngestionLatency observes how long after its block time txn was
+// written, and for the payment gateway's service wallet how long the payment
+// took to detect. The request ID in ctx becomes the exemplar.
+func recordIngestionLatency(ctx context.Context, opts ingestOptions, txn *db.Transaction) {
+	if opts.Metrics == nil {
+		return
+	}
+	latency := max(txn.CreatedAt.Sub(txn.BlockTime).Seconds(), 0)
+	opts.Metrics.RecordIngestionLatency(ctx, txn.Network, latency)
+	if txn.WalletAddress == opts.PaymentWallet && txn.Network == opts.PaymentNetwork && txn.Direction != db.DirectionOut {
+		opts.Metrics.RecordPaymentDetectionLatency(ctx, txn.Network, latency)
+	}
+}
+
+// truncateMemo cuts memo to at most maxBytes bytes, backing off to the start of
+// a UTF-8 sequence so the stored memo is still valid text. It reports whether
+// anything was cut; a nil memo or a maxBytes of 0 leaves the memo untouched.
+func truncateMemo(memo *string, maxBytes int) (*string, bool) {
+	if memo == nil || maxBytes <= 0 || len(*memo) <= maxBytes {
+		return memo, false
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart((*memo)[cut]) {
+		cut--
+	}
+	truncated := (*memo)[:cut]
+	return &truncated, true
+}
+
+// extractPaymentID reads field from a memo holding a JSON object, returning
+// nil when field is empty, the memo is not a JSON object, or the field is
+// missing or empty. String and number values are accepted; a number is kept
+// in its JSON form.
+func extractPaymentID(memo *string, field string) *string {
+	if memo == nil || field == "" {
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(*memo), &obj); err != nil {
+		return nil
+	}
+	raw, ok := obj[field]
+	if !ok {
+		return nil
+	}
+
+	var id string
+	if err := json.Unmarshal(raw, &id); err != nil {
+		var num json.Number
+		if err := json.Unmarshal(raw, &num); err != nil {
+			return nil
+		}
+		id = num.String()
+	}
+	if id == "" {
+		return nil
+	}
+	return &id
 }
 
 // buildAddressMap creates a lookup from monitored addresses to wallet info
@@ -150,6 +361,11 @@ func buildAddressMap(ctx context.Context, store *db.Store) (map[string]helius.Wa
 		return nil, err
 	}
 
+	return walletAddressMap(wallets), nil
+}
+
+// walletAddressMap builds the monitored-address lookup for the given wallets.
+func walletAddressMap(wallets []*db.Wallet) map[string]helius.WalletLookup {
 	addressMap := make(map[string]helius.WalletLookup, len(wallets))
 	for _, w := range wallets {
 		lookup := helius.WalletLookup{
@@ -168,7 +384,7 @@ func buildAddressMap(ctx context.Context, store *db.Store) (map[string]helius.Wa
 		}
 	}
 
-	return addressMap, nil
+	return addressMap
 }
 
 // isDuplicateError checks if an error is a duplicate key constraint violation.
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	natspkg "github.com/brojonat/forohtoo/service/nats"
 	"github.com/stretchr/testify/assert"
@@ -37,7 +38,7 @@ func webhookTestLogger() *slog.Logger {
 }
 
 func TestWebhookHandler_AuthRequired(t *testing.T) {
-	handler := handleHeliusWebhook(nil, nil, "Bearer my-secret", webhookTestLogger())
+	handler := handleHeliusWebhook(nil, nil, "Bearer my-secret", ingestOptions{}, nil, webhookTestLogger())
 
 	tests := []struct {
 		name       string
@@ -63,7 +64,7 @@ func TestWebhookHandler_AuthRequired(t *testing.T) {
 }
 
 func TestWebhookHandler_EmptyPayload(t *testing.T) {
-	handler := handleHeliusWebhook(nil, nil, "secret", webhookTestLogger())
+	handler := handleHeliusWebhook(nil, nil, "secret", ingestOptions{}, nil, webhookTestLogger())
 
 	req := httptest.NewRequest("POST", "/api/v1/webhooks/helius", strings.NewReader("[]"))
 	req.Header.Set("Authorization", "secret")
@@ -74,7 +75,7 @@ func TestWebhookHandler_EmptyPayload(t *testing.T) {
 }
 
 func TestWebhookHandler_InvalidJSON(t *testing.T) {
-	handler := handleHeliusWebhook(nil, nil, "secret", webhookTestLogger())
+	handler := handleHeliusWebhook(nil, nil, "secret", ingestOptions{}, nil, webhookTestLogger())
 
 	req := httptest.NewRequest("POST", "/api/v1/webhooks/helius", strings.NewReader("not json at all"))
 	req.Header.Set("Authorization", "secret")
@@ -88,7 +89,7 @@ func TestWebhookHandler_ValidPayload_NoMatchingWallets(t *testing.T) {
 	// Use a nil store - buildAddressMap will fail, but we test that
 	// the handler returns 500 for the DB error.
 	// For a unit test without a real DB, we test the flow up to address map building.
-	handler := handleHeliusWebhook(nil, nil, "secret", webhookTestLogger())
+	handler := handleHeliusWebhook(nil, nil, "secret", ingestOptions{}, nil, webhookTestLogger())
 
 	payload := mustJSON(t, []map[string]interface{}{
 		{
@@ -113,6 +114,74 @@ func TestWebhookHandler_ValidPayload_NoMatchingWallets(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestTruncateMemo(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name          string
+		memo          *string
+		max           int
+		want          *string
+		wantTruncated bool
+	}{
+		{"nil memo", nil, 4, nil, false},
+		{"unlimited", str("a long memo"), 0, str("a long memo"), false},
+		{"under limit", str("abc"), 4, str("abc"), false},
+		{"at limit", str("abcd"), 4, str("abcd"), false},
+		{"over limit", str("abcdef"), 4, str("abcd"), true},
+		// "é" is two bytes; cutting at 4 would split it.
+		{"multibyte boundary", str("abcé"), 4, str("abc"), true},
+		// "€" is three bytes; cutting at 1 or 2 leaves nothing.
+		{"multibyte at start", str("€uro"), 2, str(""), true},
+		{"json cut mid-string", str(`{"workflow_id":"abc"}`), 18, str(`{"workflow_id":"ab`), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := truncateMemo(tt.memo, tt.max)
+			assert.Equal(t, tt.wantTruncated, truncated)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, *tt.want, *got)
+			assert.True(t, utf8.ValidString(*got))
+		})
+	}
+}
+
+func TestExtractPaymentID(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name  string
+		memo  *string
+		field string
+		want  *string
+	}{
+		{"nil memo", nil, "payment_id", nil},
+		{"disabled", str(`{"payment_id":"inv-1"}`), "", nil},
+		{"string id", str(`{"payment_id":"inv-1","note":"x"}`), "payment_id", str("inv-1")},
+		{"numeric id", str(`{"payment_id":42}`), "payment_id", str("42")},
+		{"missing field", str(`{"order":"inv-1"}`), "payment_id", nil},
+		{"empty id", str(`{"payment_id":""}`), "payment_id", nil},
+		{"object id", str(`{"payment_id":{"n":1}}`), "payment_id", nil},
+		{"plain text memo", str("payment_id inv-1"), "payment_id", nil},
+		{"json array memo", str(`["inv-1"]`), "payment_id", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractPaymentID(tt.memo, tt.field)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, *tt.want, *got)
+		})
+	}
+}
+
 func TestBuildAddressMap_NilStore(t *testing.T) {
 	// buildAddressMap with nil store should return an error
 	_, err := buildAddressMap(context.Background(), nil)
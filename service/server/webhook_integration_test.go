@@ -68,7 +68,7 @@ func TestWebhookIntegration_EndToEnd(t *testing.T) {
 
 	// Create the webhook handler
 	authToken := "Bearer test-integration-secret"
-	handler := handleHeliusWebhook(store, pub, authToken, logger)
+	handler := handleHeliusWebhook(store, pub, authToken, ingestOptions{}, nil, logger)
 
 	// Simulate a Helius webhook delivery with a native SOL transfer TO our monitored wallet
 	payload := []map[string]interface{}{
@@ -184,7 +184,7 @@ func TestWebhookIntegration_SPLToken(t *testing.T) {
 
 	pub := &mockPublisher{}
 	authToken := "Bearer spl-test-secret"
-	handler := handleHeliusWebhook(store, pub, authToken, logger)
+	handler := handleHeliusWebhook(store, pub, authToken, ingestOptions{}, nil, logger)
 
 	// Simulate a USDC transfer to our monitored ATA
 	payload := []map[string]interface{}{
@@ -285,7 +285,7 @@ func TestWebhookIntegration_BatchMultipleTransactions(t *testing.T) {
 
 	pub := &mockPublisher{}
 	authToken := "Bearer batch-test-secret"
-	handler := handleHeliusWebhook(store, pub, authToken, logger)
+	handler := handleHeliusWebhook(store, pub, authToken, ingestOptions{}, nil, logger)
 
 	// Send 3 transactions in one batch
 	now := time.Now().Unix()
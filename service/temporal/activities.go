@@ -3,11 +3,14 @@ package temporal
 import (
 	"context"
 	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/brojonat/forohtoo/client"
 	"github.com/brojonat/forohtoo/service/db"
 	"github.com/brojonat/forohtoo/service/helius"
 	"github.com/brojonat/forohtoo/service/metrics"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
 )
 
 // StoreInterface defines the database operations needed by activities.
@@ -15,12 +18,20 @@ type StoreInterface interface {
 	UpsertWallet(context.Context, db.UpsertWalletParams) (*db.Wallet, error)
 	DeleteWallet(context.Context, string, string, string, string) error
 	GetWallet(context.Context, string, string, string, string) (*db.Wallet, error)
+	RecordWebhookDeliveryAttempt(context.Context, db.RecordWebhookDeliveryAttemptParams) (*db.WebhookDelivery, error)
 }
 
-// HeliusClientInterface defines the Helius webhook operations needed by activities.
+// HeliusClientInterface defines the Helius webhook and RPC operations needed
+// by activities.
 type HeliusClientInterface interface {
 	AddAddress(ctx context.Context, address string) error
 	RemoveAddress(ctx context.Context, address string) error
+	GetSignatureStatuses(ctx context.Context, network string, signatures []string) (map[string]string, error)
+}
+
+// AlertPublisher publishes operational alerts (e.g. terminal workflow failures).
+type AlertPublisher interface {
+	PublishWorkflowFailure(ctx context.Context, event *natspkg.WorkflowFailureEvent) error
 }
 
 // Activities holds the dependencies needed by Temporal activities.
@@ -28,15 +39,22 @@ type Activities struct {
 	store          StoreInterface
 	heliusClient   HeliusClientInterface
 	forohtooClient *client.Client
+	alerts         AlertPublisher // optional
+	callbackSecret string         // signs registration-completed callbacks
+	callbackClient *http.Client
+	finalityPoll   time.Duration // how often AwaitPayment re-checks an unfinalized payment
 	metrics        *metrics.Metrics
 	logger         *slog.Logger
 }
 
 // NewActivities creates a new Activities instance with explicit dependencies.
+// alerts and m may be nil; an empty callbackSecret disables payment callbacks.
 func NewActivities(
 	store StoreInterface,
 	heliusClient HeliusClientInterface,
 	forohtooClient *client.Client,
+	alerts AlertPublisher,
+	callbackSecret string,
 	m *metrics.Metrics,
 	logger *slog.Logger,
 ) *Activities {
@@ -47,10 +65,18 @@ func NewActivities(
 		store:          store,
 		heliusClient:   heliusClient,
 		forohtooClient: forohtooClient,
+		alerts:         alerts,
+		callbackSecret: callbackSecret,
+		callbackClient: &http.Client{Timeout: 10 * time.Second},
+		finalityPoll:   2 * time.Second,
 		metrics:        m,
 		logger:         logger,
 	}
 }
 
-// compile-time assertion that *helius.Client satisfies HeliusClientInterface.
-var _ HeliusClientInterface = (*helius.Client)(nil)
+// compile-time assertions that the concrete clients satisfy the interfaces.
+var (
+	_ StoreInterface        = (*db.Store)(nil)
+	_ HeliusClientInterface = (*helius.Client)(nil)
+	_ AlertPublisher        = (*natspkg.JetStreamPublisher)(nil)
+)
@@ -0,0 +1,183 @@
+package temporal
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/metrics"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Headers sent with every registration-completed callback. The signature is
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// the operator's callback secret.
+const (
+	CallbackSignatureHeader = "X-Forohtoo-Signature"
+	CallbackTimestampHeader = "X-Forohtoo-Timestamp"
+)
+
+// PaymentCallbackEvent identifies the callback payload type, and is the event
+// its webhook deliveries are recorded under.
+const PaymentCallbackEvent = "registration.completed"
+
+// PaymentCallbackPayload is the JSON body POSTed to a registration's callback
+// URL once its payment-gated registration completes.
+type PaymentCallbackPayload struct {
+	Event            string    `json:"event"` // always "registration.completed"
+	WorkflowID       string    `json:"workflow_id"`
+	Address          string    `json:"address"`
+	Network          string    `json:"network"`
+	AssetType        string    `json:"asset_type"`
+	TokenMint        string    `json:"token_mint,omitempty"`
+	PaymentSignature string    `json:"payment_signature"`
+	PaymentAmount    int64     `json:"payment_amount"` // in base units of the fee asset
+	FeeAssetType     string    `json:"fee_asset_type"`
+	FeeMint          string    `json:"fee_mint,omitempty"`
+	RegisteredAt     time.Time `json:"registered_at"`
+}
+
+// DeliverPaymentCallbackInput contains parameters for delivering a callback.
+type DeliverPaymentCallbackInput struct {
+	URL     string                 `json:"url"`
+	Payload PaymentCallbackPayload `json:"payload"`
+}
+
+// DeliverPaymentCallback activity POSTs a signed registration-completed
+// payload to the registration's callback URL. Transport errors, 408, 429 and
+// 5xx responses are retried by Temporal; other 4xx responses are permanent.
+// Every attempt is recorded in the webhook_deliveries table and counted in
+// a metric; the final outcome (delivered, or failed once retries are
+// exhausted) is recorded as a separate metric.
+func (a *Activities) DeliverPaymentCallback(ctx context.Context, input DeliverPaymentCallbackInput) error {
+	statusCode, err := a.deliverPaymentCallback(ctx, input)
+	if a.metrics != nil {
+		a.metrics.RecordWebhookDeliveryAttempt(input.Payload.Event, err)
+	}
+	if err == nil {
+		a.logger.InfoContext(ctx, "payment callback delivered",
+			"workflow_id", input.Payload.WorkflowID,
+			"address", input.Payload.Address,
+		)
+		a.recordPaymentCallback(input.Payload.Network, metrics.CallbackDelivered)
+		a.recordCallbackAttempt(ctx, input, db.WebhookDeliveryDelivered, statusCode, nil)
+		return nil
+	}
+
+	attempt := activity.GetInfo(ctx).Attempt
+	var appErr *temporal.ApplicationError
+	final := attempt >= paymentCallbackMaxAttempts ||
+		(errors.As(err, &appErr) && appErr.NonRetryable())
+	a.logger.WarnContext(ctx, "payment callback delivery failed",
+		"workflow_id", input.Payload.WorkflowID,
+		"address", input.Payload.Address,
+		"attempt", attempt,
+		"final", final,
+		"error", err,
+	)
+	status := db.WebhookDeliveryRetrying
+	if final {
+		a.recordPaymentCallback(input.Payload.Network, metrics.CallbackFailed)
+		status = db.WebhookDeliveryFailed
+	}
+	a.recordCallbackAttempt(ctx, input, status, statusCode, err)
+	return err
+}
+
+// deliverPaymentCallback sends the callback and returns the endpoint's status
+// code, or 0 if no response was received.
+func (a *Activities) deliverPaymentCallback(ctx context.Context, input DeliverPaymentCallbackInput) (int, error) {
+	if a.callbackSecret == "" {
+		return 0, temporal.NewNonRetryableApplicationError("payment callbacks are not configured", "callback_disabled", nil)
+	}
+
+	body, err := json.Marshal(input.Payload)
+	if err != nil {
+		return 0, temporal.NewNonRetryableApplicationError("failed to encode callback payload", "callback_encode", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, input.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, temporal.NewNonRetryableApplicationError("invalid callback URL", "callback_url", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(CallbackTimestampHeader, timestamp)
+	req.Header.Set(CallbackSignatureHeader, SignCallback(a.callbackSecret, timestamp, body))
+
+	resp, err := a.callbackClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("callback request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, nil
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return resp.StatusCode, fmt.Errorf("callback endpoint returned %d", resp.StatusCode)
+	default:
+		return resp.StatusCode, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("callback endpoint rejected delivery with %d", resp.StatusCode), "callback_rejected", nil)
+	}
+}
+
+// recordCallbackAttempt records a delivery attempt in the webhook_deliveries
+// table, keyed by the registration's workflow ID. Failing to record it is
+// logged and does not affect delivery.
+func (a *Activities) recordCallbackAttempt(ctx context.Context, input DeliverPaymentCallbackInput, status string, statusCode int, deliveryErr error) {
+	if a.store == nil {
+		return
+	}
+	payload, err := json.Marshal(input.Payload)
+	if err != nil {
+		a.logger.WarnContext(ctx, "failed to encode callback payload for delivery record", "workflow_id", input.Payload.WorkflowID, "error", err)
+		return
+	}
+	params := db.RecordWebhookDeliveryAttemptParams{
+		Event:      input.Payload.Event,
+		SourceID:   input.Payload.WorkflowID,
+		Network:    input.Payload.Network,
+		URL:        input.URL,
+		Payload:    payload,
+		Status:     status,
+		StatusCode: statusCode,
+	}
+	if deliveryErr != nil {
+		params.Error = deliveryErr.Error()
+	}
+	if _, err := a.store.RecordWebhookDeliveryAttempt(ctx, params); err != nil {
+		a.logger.WarnContext(ctx, "failed to record webhook delivery attempt", "workflow_id", input.Payload.WorkflowID, "error", err)
+	}
+}
+
+func (a *Activities) recordPaymentCallback(network, result string) {
+	if a.metrics != nil {
+		a.metrics.RecordPaymentCallback(network, result)
+	}
+}
+
+// SignCallback returns the signature header value for a callback body sent at
+// the given Unix timestamp. Receivers recompute it with their copy of the
+// secret and compare with hmac.Equal.
+func SignCallback(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
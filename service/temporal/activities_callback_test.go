@@ -0,0 +1,130 @@
+package temporal
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// deliveryRecorder records webhook delivery attempts. Other store methods
+// are not used by DeliverPaymentCallback.
+type deliveryRecorder struct {
+	StoreInterface
+	attempts []db.RecordWebhookDeliveryAttemptParams
+}
+
+func (r *deliveryRecorder) RecordWebhookDeliveryAttempt(_ context.Context, params db.RecordWebhookDeliveryAttemptParams) (*db.WebhookDelivery, error) {
+	r.attempts = append(r.attempts, params)
+	return &db.WebhookDelivery{}, nil
+}
+
+func TestDeliverPaymentCallback(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	payload := PaymentCallbackPayload{
+		Event:            PaymentCallbackEvent,
+		WorkflowID:       "payment-registration:wallet1",
+		Address:          "wallet1",
+		Network:          "devnet",
+		AssetType:        "sol",
+		PaymentSignature: "sig1",
+		PaymentAmount:    1000000,
+	}
+
+	tests := []struct {
+		name         string
+		status       int
+		secret       string
+		wantErr      bool
+		nonRetryable bool
+		wantMetric   string
+		wantRecord   string // delivery status recorded for the attempt
+		wantCode     int    // status code recorded for the attempt
+	}{
+		{"delivered", http.StatusNoContent, "s3cret", false, false, metrics.CallbackDelivered, db.WebhookDeliveryDelivered, http.StatusNoContent},
+		{"server error is retried", http.StatusBadGateway, "s3cret", true, false, "", db.WebhookDeliveryRetrying, http.StatusBadGateway},
+		{"rate limited is retried", http.StatusTooManyRequests, "s3cret", true, false, "", db.WebhookDeliveryRetrying, http.StatusTooManyRequests},
+		{"client error is permanent", http.StatusBadRequest, "s3cret", true, true, metrics.CallbackFailed, db.WebhookDeliveryFailed, http.StatusBadRequest},
+		{"not configured", http.StatusOK, "", true, true, metrics.CallbackFailed, db.WebhookDeliveryFailed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Contains(t, string(body), `"event":"registration.completed"`)
+				assert.Contains(t, string(body), `"payment_signature":"sig1"`)
+
+				want := SignCallback(tt.secret, r.Header.Get(CallbackTimestampHeader), body)
+				assert.True(t, hmac.Equal([]byte(want), []byte(r.Header.Get(CallbackSignatureHeader))), "signature must verify")
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			reg := prometheus.NewRegistry()
+			store := &deliveryRecorder{}
+			a := NewActivities(store, nil, nil, nil, tt.secret, metrics.NewMetrics(reg), logger)
+
+			var ts testsuite.WorkflowTestSuite
+			env := ts.NewTestActivityEnvironment()
+			env.RegisterActivity(a)
+
+			_, err := env.ExecuteActivity(a.DeliverPaymentCallback, DeliverPaymentCallbackInput{URL: srv.URL, Payload: payload})
+			if !tt.wantErr {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				var appErr *temporal.ApplicationError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.nonRetryable, appErr.NonRetryable())
+			}
+
+			expected := ""
+			if tt.wantMetric != "" {
+				expected = `
+# HELP payment_callbacks_total Total number of registration-completed callback deliveries by network and result (delivered or failed)
+# TYPE payment_callbacks_total counter
+payment_callbacks_total{network="devnet",result="` + tt.wantMetric + `"} 1
+`
+			}
+			require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "payment_callbacks_total"))
+
+			require.Len(t, store.attempts, 1)
+			attempt := store.attempts[0]
+			assert.Equal(t, tt.wantRecord, attempt.Status)
+			assert.Equal(t, tt.wantCode, attempt.StatusCode)
+			assert.Equal(t, "registration.completed", attempt.Event)
+			assert.Equal(t, "payment-registration:wallet1", attempt.SourceID)
+			assert.Equal(t, srv.URL, attempt.URL)
+			assert.Contains(t, string(attempt.Payload), `"payment_signature":"sig1"`)
+			assert.Equal(t, tt.wantErr, attempt.Error != "")
+		})
+	}
+}
+
+func TestSignCallback(t *testing.T) {
+	body := []byte(`{"event":"registration.completed"}`)
+	sig := SignCallback("s3cret", "1700000000", body)
+
+	assert.True(t, strings.HasPrefix(sig, "sha256="))
+	assert.Len(t, sig, len("sha256=")+64)
+	assert.Equal(t, sig, SignCallback("s3cret", "1700000000", body), "signing must be deterministic")
+	assert.NotEqual(t, sig, SignCallback("other", "1700000000", body))
+	assert.NotEqual(t, sig, SignCallback("s3cret", "1700000001", body), "timestamp must be covered")
+}
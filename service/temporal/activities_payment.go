@@ -2,21 +2,36 @@ package temporal
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/brojonat/forohtoo/client"
 	"github.com/brojonat/forohtoo/service/db"
+	"github.com/brojonat/forohtoo/service/helius"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
 	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 )
 
 // AwaitPaymentInput contains parameters for awaiting payment.
 type AwaitPaymentInput struct {
 	PayToAddress   string        `json:"pay_to_address"`
 	Network        string        `json:"network"`
-	Amount         int64         `json:"amount"`
+	AssetType      string        `json:"asset_type,omitempty"` // fee asset; empty accepts any asset
+	TokenMint      string        `json:"token_mint,omitempty"` // fee mint when AssetType is "spl-token"
+	Amount         int64         `json:"amount"`               // in base units of the fee asset
 	Memo           string        `json:"memo"`
+	MemoMatch      string        `json:"memo_match,omitempty"` // "exact" (default), "prefix", or "contains"
 	LookbackPeriod time.Duration `json:"lookback_period"`
+	AllowedSenders []string      `json:"allowed_senders,omitempty"` // if non-empty, payments from other senders are rejected
+
+	// RequireFinalized holds a matching payment until the RPC node reports it
+	// finalized, for at most FinalizationTimeout.
+	RequireFinalized    bool          `json:"require_finalized,omitempty"`
+	FinalizationTimeout time.Duration `json:"finalization_timeout,omitempty"`
 }
 
 // AwaitPaymentResult contains the result of awaiting payment.
@@ -25,15 +40,18 @@ type AwaitPaymentResult struct {
 	Amount               int64     `json:"amount"`
 	FromAddress          *string   `json:"from_address,omitempty"`
 	BlockTime            time.Time `json:"block_time"`
+	Memo                 *string   `json:"memo,omitempty"` // memo as observed on-chain, which may wrap the expected memo
 }
 
 // RegisterWalletInput contains parameters for registering a wallet.
 type RegisterWalletInput struct {
-	Address                string  `json:"address"`
-	Network                string  `json:"network"`
-	AssetType              string  `json:"asset_type"`
-	TokenMint              string  `json:"token_mint"`
-	AssociatedTokenAddress *string `json:"associated_token_address"`
+	Address                string          `json:"address"`
+	Network                string          `json:"network"`
+	AssetType              string          `json:"asset_type"`
+	TokenMint              string          `json:"token_mint"`
+	AssociatedTokenAddress *string         `json:"associated_token_address"`
+	Metadata               json.RawMessage `json:"metadata,omitempty"`
+	Tags                   []string        `json:"tags,omitempty"`
 }
 
 // RegisterWalletResult contains the result of registering a wallet.
@@ -51,8 +69,11 @@ func (a *Activities) AwaitPayment(ctx context.Context, input AwaitPaymentInput)
 	a.logger.InfoContext(ctx, "waiting for payment",
 		"address", input.PayToAddress,
 		"network", input.Network,
+		"asset_type", input.AssetType,
+		"token_mint", input.TokenMint,
 		"amount", input.Amount,
 		"memo", input.Memo,
+		"memo_match", input.MemoMatch,
 	)
 
 	heartbeatCtx, cancel := context.WithCancel(ctx)
@@ -76,18 +97,39 @@ func (a *Activities) AwaitPayment(ctx context.Context, input AwaitPaymentInput)
 	}
 
 	txn, err := a.forohtooClient.Await(ctx, input.PayToAddress, input.Network, input.LookbackPeriod, func(t *client.Transaction) bool {
+		// A transfer the wallet sent is never a payment to it.
+		if t.Direction == client.DirectionOut {
+			return false
+		}
+		if !assetMatches(t, input.AssetType, input.TokenMint) {
+			return false
+		}
 		meetsAmount := t.Amount >= input.Amount
-		matchesMemo := t.Memo != nil && *t.Memo == input.Memo
-		return meetsAmount && matchesMemo
+		matchesMemo := client.MemoMatches(t.Memo, input.Memo, client.MemoMatchMode(input.MemoMatch))
+		if !meetsAmount || !matchesMemo {
+			return false
+		}
+		if !senderAllowed(t.FromAddress, input.AllowedSenders) {
+			a.recordSenderRejection(ctx, input, t)
+			return false
+		}
+		return true
 	})
 	if err != nil {
 		return nil, fmt.Errorf("payment await failed: %w", err)
 	}
 
+	if input.RequireFinalized && txn.ConfirmationStatus != "finalized" {
+		if err := a.awaitFinalized(ctx, input.Network, txn.Signature, input.FinalizationTimeout); err != nil {
+			return nil, err
+		}
+	}
+
 	a.logger.InfoContext(ctx, "payment received",
 		"txn_signature", txn.Signature,
 		"amount", txn.Amount,
 		"from", txn.FromAddress,
+		"memo", txn.Memo,
 	)
 
 	return &AwaitPaymentResult{
@@ -95,9 +137,114 @@ func (a *Activities) AwaitPayment(ctx context.Context, input AwaitPaymentInput)
 		Amount:               txn.Amount,
 		FromAddress:          txn.FromAddress,
 		BlockTime:            txn.BlockTime,
+		Memo:                 txn.Memo,
 	}, nil
 }
 
+// awaitFinalized polls the RPC node until signature is finalized. A payment
+// that fails, or isn't finalized within timeout (e.g. it was dropped with its
+// fork), is a non-retryable error: retrying would only find the same
+// transaction again. When the RPC node rate limits the check, the next one
+// waits out its Retry-After.
+func (a *Activities) awaitFinalized(ctx context.Context, network, signature string, timeout time.Duration) error {
+	if a.heliusClient == nil {
+		return temporal.NewNonRetryableApplicationError("finality check requires a Helius client", "finality_unavailable", nil)
+	}
+
+	a.logger.InfoContext(ctx, "waiting for payment to finalize",
+		"txn_signature", signature,
+		"network", network,
+		"timeout", timeout,
+	)
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	lastStatus := "unknown"
+	for {
+		delay := a.finalityPoll
+		statuses, err := a.heliusClient.GetSignatureStatuses(ctx, network, []string{signature})
+		if err != nil {
+			// Transient RPC errors are retried until the deadline.
+			var rateLimited *helius.RateLimitError
+			if errors.As(err, &rateLimited) {
+				delay = max(delay, rateLimited.RetryAfter)
+			}
+			a.logger.WarnContext(ctx, "failed to check payment finality", "txn_signature", signature, "retry_in", delay, "error", err)
+		} else {
+			status, ok := statuses[signature]
+			switch {
+			case !ok:
+				lastStatus = "not found"
+			case status == "finalized":
+				return nil
+			case status == "failed":
+				return temporal.NewNonRetryableApplicationError(
+					fmt.Sprintf("payment %s failed on-chain", signature), "payment_failed", nil)
+			default:
+				lastStatus = status
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("payment %s not finalized within %s (last status: %s)", signature, timeout, lastStatus),
+				"payment_not_finalized", nil)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// assetMatches reports whether a transaction is in the fee asset. SOL transfers
+// carry no token mint. Inputs without an asset type (from workflows started
+// before fees were configurable) accept any asset, as they did then.
+func assetMatches(t *client.Transaction, assetType, tokenMint string) bool {
+	switch assetType {
+	case "sol":
+		return t.TokenType == ""
+	case "spl-token":
+		return t.TokenType == tokenMint
+	default:
+		return true
+	}
+}
+
+// senderAllowed reports whether a payment from the given sender is acceptable.
+// An empty allowlist accepts everyone; otherwise the sender must be known and
+// listed.
+func senderAllowed(from *string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	if from == nil {
+		return false
+	}
+	return slices.Contains(allowed, *from)
+}
+
+// recordSenderRejection leaves an audit trail for a payment that matched the
+// invoice but came from a sender outside the allowlist.
+func (a *Activities) recordSenderRejection(ctx context.Context, input AwaitPaymentInput, t *client.Transaction) {
+	from := "unknown"
+	if t.FromAddress != nil {
+		from = *t.FromAddress
+	}
+	a.logger.WarnContext(ctx, "payment rejected: sender not in allowlist",
+		"txn_signature", t.Signature,
+		"from", from,
+		"pay_to", input.PayToAddress,
+		"network", input.Network,
+		"amount", t.Amount,
+		"memo", input.Memo,
+	)
+	if a.metrics != nil {
+		a.metrics.RecordPaymentRejection(input.Network, "sender_not_allowed")
+	}
+}
+
 // RegisterWallet activity persists a wallet asset and adds the monitored
 // address to the Helius webhook so its transactions begin streaming.
 func (a *Activities) RegisterWallet(ctx context.Context, input RegisterWalletInput) (*RegisterWalletResult, error) {
@@ -114,6 +261,8 @@ func (a *Activities) RegisterWallet(ctx context.Context, input RegisterWalletInp
 		TokenMint:              input.TokenMint,
 		AssociatedTokenAddress: input.AssociatedTokenAddress,
 		Status:                 "active",
+		Metadata:               input.Metadata,
+		Tags:                   input.Tags,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to upsert wallet: %w", err)
@@ -152,3 +301,70 @@ func (a *Activities) RegisterWallet(ctx context.Context, input RegisterWalletInp
 		Status:    wallet.Status,
 	}, nil
 }
+
+// RecordWorkflowFailureInput describes a terminal workflow failure.
+type RecordWorkflowFailureInput struct {
+	WorkflowID   string `json:"workflow_id"`
+	WorkflowType string `json:"workflow_type"`
+	Step         string `json:"step"`
+	Kind         string `json:"kind"` // "timeout" or "error"
+	Reason       string `json:"reason"`
+	Address      string `json:"address"`
+	Network      string `json:"network"`
+}
+
+// RecordWorkflowFailure activity records a failure metric and publishes an
+// alert event so unexpected failures can be alerted on outside the Temporal UI.
+// It is best-effort: publish failures are logged, not returned.
+func (a *Activities) RecordWorkflowFailure(ctx context.Context, input RecordWorkflowFailureInput) error {
+	logArgs := []any{
+		"workflow_id", input.WorkflowID,
+		"workflow_type", input.WorkflowType,
+		"step", input.Step,
+		"kind", input.Kind,
+		"reason", input.Reason,
+	}
+	if input.Kind == failureKindTimeout {
+		a.logger.InfoContext(ctx, "workflow timed out", logArgs...)
+	} else {
+		a.logger.ErrorContext(ctx, "workflow failed unexpectedly", logArgs...)
+	}
+
+	if a.metrics != nil {
+		a.metrics.RecordWorkflowFailure(input.WorkflowType, input.Step, input.Kind)
+	}
+
+	if a.alerts != nil {
+		err := a.alerts.PublishWorkflowFailure(ctx, &natspkg.WorkflowFailureEvent{
+			WorkflowID:   input.WorkflowID,
+			WorkflowType: input.WorkflowType,
+			Step:         input.Step,
+			Kind:         input.Kind,
+			Reason:       input.Reason,
+			Address:      input.Address,
+			Network:      input.Network,
+			FailedAt:     time.Now().UTC(),
+		})
+		if err != nil {
+			a.logger.WarnContext(ctx, "failed to publish workflow failure alert", "workflow_id", input.WorkflowID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// RecordPaymentFunnelInput describes a payment-gated registration reaching a
+// funnel stage.
+type RecordPaymentFunnelInput struct {
+	Network string `json:"network"`
+	Stage   string `json:"stage"` // one of the metrics.Funnel* stages
+}
+
+// RecordPaymentFunnel activity counts a funnel stage. Metrics can't be
+// recorded from workflow code, which is replayed.
+func (a *Activities) RecordPaymentFunnel(ctx context.Context, input RecordPaymentFunnelInput) error {
+	if a.metrics != nil {
+		a.metrics.RecordPaymentFunnel(input.Network, input.Stage)
+	}
+	return nil
+}
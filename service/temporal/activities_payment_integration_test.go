@@ -70,6 +70,9 @@ type stubHeliusClient struct {
 
 func (s *stubHeliusClient) AddAddress(_ context.Context, _ string) error    { return s.addErr }
 func (s *stubHeliusClient) RemoveAddress(_ context.Context, _ string) error { return nil }
+func (s *stubHeliusClient) GetSignatureStatuses(_ context.Context, _ string, _ []string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
 
 // TestRegisterWallet_Integration_Rollback verifies that RegisterWallet rolls
 // back the wallet upsert when the Helius webhook subscription fails.
@@ -0,0 +1,436 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/client"
+	"github.com/brojonat/forohtoo/service/config"
+	"github.com/brojonat/forohtoo/service/helius"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestSenderAllowed(t *testing.T) {
+	allowed := []string{"SenderA", "SenderB"}
+
+	tests := []struct {
+		name    string
+		from    *string
+		allowed []string
+		want    bool
+	}{
+		{"empty allowlist accepts anyone", stringPtr("Stranger"), nil, true},
+		{"empty allowlist accepts unknown sender", nil, nil, true},
+		{"listed sender", stringPtr("SenderB"), allowed, true},
+		{"unlisted sender", stringPtr("Stranger"), allowed, false},
+		{"unknown sender with allowlist", nil, allowed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, senderAllowed(tt.from, tt.allowed))
+		})
+	}
+}
+
+func TestAwaitPayment_AllowedSenders(t *testing.T) {
+	memo := "forohtoo-reg:wallet1"
+	payments := []client.Transaction{
+		// Correct memo and amount, wrong senders
+		{Signature: "sig-stranger", FromAddress: stringPtr("Stranger"), Amount: 1000000, Memo: &memo},
+		{Signature: "sig-nil-sender", Amount: 1000000, Memo: &memo},
+		// Allowed sender
+		{Signature: "sig-allowed", FromAddress: stringPtr("SenderA"), Amount: 1000000, Memo: &memo},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, p := range payments {
+			p.Network = "mainnet"
+			p.BlockTime = time.Now()
+			data, _ := json.Marshal(p)
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	a := NewActivities(nil, nil, client.NewClient(srv.URL, nil, logger), nil, "", nil, logger)
+
+	tests := []struct {
+		name    string
+		allowed []string
+		wantSig string
+	}{
+		{"open by default", nil, "sig-stranger"},
+		{"allowlist skips unknown senders", []string{"SenderA"}, "sig-allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := a.AwaitPayment(ctx, AwaitPaymentInput{
+				PayToAddress:   "ServiceWallet",
+				Network:        "mainnet",
+				Amount:         1000000,
+				Memo:           memo,
+				AllowedSenders: tt.allowed,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSig, result.TransactionSignature)
+		})
+	}
+}
+
+func TestAwaitPayment_MemoMatch(t *testing.T) {
+	memo := "forohtoo-reg:wallet1"
+	payments := []client.Transaction{
+		{Signature: "sig-wrong", Amount: 1000000, Memo: stringPtr("forohtoo-reg:wallet2")},
+		{Signature: "sig-wrapped", Amount: 1000000, Memo: stringPtr("phantom: forohtoo-reg:wallet1")},
+		{Signature: "sig-suffixed", Amount: 1000000, Memo: stringPtr("forohtoo-reg:wallet1 thanks")},
+		{Signature: "sig-exact", Amount: 1000000, Memo: stringPtr(memo)},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, p := range payments {
+			p.Network = "mainnet"
+			p.BlockTime = time.Now()
+			data, _ := json.Marshal(p)
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	a := NewActivities(nil, nil, client.NewClient(srv.URL, nil, logger), nil, "", nil, logger)
+
+	tests := []struct {
+		name     string
+		mode     string
+		wantSig  string
+		wantMemo string
+	}{
+		{"default is exact", "", "sig-exact", memo},
+		{"exact", "exact", "sig-exact", memo},
+		{"prefix", "prefix", "sig-suffixed", "forohtoo-reg:wallet1 thanks"},
+		{"contains matches wrapped memo", "contains", "sig-wrapped", "phantom: forohtoo-reg:wallet1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := a.AwaitPayment(ctx, AwaitPaymentInput{
+				PayToAddress: "ServiceWallet",
+				Network:      "mainnet",
+				Amount:       1000000,
+				Memo:         memo,
+				MemoMatch:    tt.mode,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSig, result.TransactionSignature)
+			require.NotNil(t, result.Memo)
+			assert.Equal(t, tt.wantMemo, *result.Memo, "result records the observed memo")
+		})
+	}
+}
+
+// sequenceHeliusClient reports the next status in statuses on each
+// GetSignatureStatuses call, repeating the last one. "" means the RPC node
+// doesn't know the signature.
+type sequenceHeliusClient struct {
+	statuses []string
+	calls    int
+}
+
+func (s *sequenceHeliusClient) AddAddress(_ context.Context, _ string) error    { return nil }
+func (s *sequenceHeliusClient) RemoveAddress(_ context.Context, _ string) error { return nil }
+func (s *sequenceHeliusClient) GetSignatureStatuses(_ context.Context, _ string, signatures []string) (map[string]string, error) {
+	status := s.statuses[min(s.calls, len(s.statuses)-1)]
+	s.calls++
+	if status == "" {
+		return map[string]string{}, nil
+	}
+	return map[string]string{signatures[0]: status}, nil
+}
+
+func TestAwaitPayment_RequireFinalized(t *testing.T) {
+	memo := "forohtoo-reg:wallet1"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		data, _ := json.Marshal(client.Transaction{
+			Signature:          "sig-payment",
+			Network:            "mainnet",
+			Amount:             1000000,
+			Memo:               &memo,
+			BlockTime:          time.Now(),
+			ConfirmationStatus: "confirmed",
+		})
+		w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	tests := []struct {
+		name      string
+		statuses  []string
+		wantErr   string
+		wantCalls int
+	}{
+		{"confirmed then finalized", []string{"confirmed", "confirmed", "finalized"}, "", 3},
+		{"confirmed then dropped", []string{"confirmed", ""}, "not finalized within 50ms (last status: not found)", 0},
+		{"failed on-chain", []string{"confirmed", "failed"}, "failed on-chain", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helius := &sequenceHeliusClient{statuses: tt.statuses}
+			a := NewActivities(nil, helius, client.NewClient(srv.URL, nil, logger), nil, "", nil, logger)
+			a.finalityPoll = 5 * time.Millisecond
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := a.AwaitPayment(ctx, AwaitPaymentInput{
+				PayToAddress:        "ServiceWallet",
+				Network:             "mainnet",
+				Amount:              1000000,
+				Memo:                memo,
+				RequireFinalized:    true,
+				FinalizationTimeout: 50 * time.Millisecond,
+			})
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "sig-payment", result.TransactionSignature)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				var appErr *temporal.ApplicationError
+				require.ErrorAs(t, err, &appErr)
+				assert.True(t, appErr.NonRetryable(), "a payment that won't finalize must not be retried")
+			}
+			if tt.wantCalls > 0 {
+				assert.Equal(t, tt.wantCalls, helius.calls)
+			}
+		})
+	}
+
+	t.Run("not required", func(t *testing.T) {
+		helius := &sequenceHeliusClient{statuses: []string{""}}
+		a := NewActivities(nil, helius, client.NewClient(srv.URL, nil, logger), nil, "", nil, logger)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		result, err := a.AwaitPayment(ctx, AwaitPaymentInput{PayToAddress: "ServiceWallet", Network: "mainnet", Amount: 1000000, Memo: memo})
+		require.NoError(t, err)
+		assert.Equal(t, "sig-payment", result.TransactionSignature)
+		assert.Zero(t, helius.calls, "the RPC node shouldn't be consulted")
+	})
+}
+
+// rateLimitedHeliusClient rate limits the first GetSignatureStatuses call,
+// then reports the payment finalized.
+type rateLimitedHeliusClient struct {
+	sequenceHeliusClient
+	retryAfter time.Duration
+	callTimes  []time.Time
+}
+
+func (r *rateLimitedHeliusClient) GetSignatureStatuses(ctx context.Context, network string, signatures []string) (map[string]string, error) {
+	r.callTimes = append(r.callTimes, time.Now())
+	if len(r.callTimes) == 1 {
+		return nil, fmt.Errorf("get signature statuses: %w", &helius.RateLimitError{Endpoint: "rpc.example.com", RetryAfter: r.retryAfter})
+	}
+	return map[string]string{signatures[0]: "finalized"}, nil
+}
+
+func TestAwaitFinalized_HonoursRetryAfter(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	rpc := &rateLimitedHeliusClient{retryAfter: 50 * time.Millisecond}
+	a := NewActivities(nil, rpc, nil, nil, "", nil, logger)
+	a.finalityPoll = time.Millisecond
+
+	err := a.awaitFinalized(context.Background(), "mainnet", "sig-payment", 5*time.Second)
+	require.NoError(t, err)
+	require.Len(t, rpc.callTimes, 2)
+	assert.GreaterOrEqual(t, rpc.callTimes[1].Sub(rpc.callTimes[0]), rpc.retryAfter,
+		"the next check must wait out the Retry-After, not the poll interval")
+}
+
+func TestAssetMatches(t *testing.T) {
+	usdc := "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	sol := &client.Transaction{}
+	token := &client.Transaction{TokenType: usdc}
+	other := &client.Transaction{TokenType: "OtherMint"}
+
+	tests := []struct {
+		name      string
+		txn       *client.Transaction
+		assetType string
+		tokenMint string
+		want      bool
+	}{
+		{"sol fee accepts sol", sol, "sol", "", true},
+		{"sol fee rejects token", token, "sol", "", false},
+		{"token fee accepts mint", token, "spl-token", usdc, true},
+		{"token fee rejects other mint", other, "spl-token", usdc, false},
+		{"token fee rejects sol", sol, "spl-token", usdc, false},
+		{"unset asset accepts anything", other, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, assetMatches(tt.txn, tt.assetType, tt.tokenMint))
+		})
+	}
+}
+
+func TestAwaitPayment_SOLFee(t *testing.T) {
+	memo := "forohtoo-reg:wallet1"
+	payments := []client.Transaction{
+		// Right memo and amount, but in USDC rather than SOL
+		{Signature: "sig-usdc", TokenType: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Amount: 10000000, Memo: &memo},
+		// SOL, but short of the fee
+		{Signature: "sig-sol-short", Amount: 9999999, Memo: &memo},
+		{Signature: "sig-sol", Amount: 10000000, Memo: &memo},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, p := range payments {
+			p.Network = "devnet"
+			p.BlockTime = time.Now()
+			data, _ := json.Marshal(p)
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	a := NewActivities(nil, nil, client.NewClient(srv.URL, nil, logger), nil, "", nil, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := a.AwaitPayment(ctx, AwaitPaymentInput{
+		PayToAddress: "ServiceWallet",
+		Network:      "devnet",
+		AssetType:    "sol",
+		Amount:       10000000,
+		Memo:         memo,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sig-sol", result.TransactionSignature)
+}
+
+// TestAwaitPayment_NineDecimalFeeMint runs a fee payment in a 9-decimal SPL
+// mint from the Helius payload through to AwaitPayment: the invoice is priced
+// with the configured decimals, so the ingested amount must be scaled by the
+// mint's decimals, not the 6-decimal default.
+func TestAwaitPayment_NineDecimalFeeMint(t *testing.T) {
+	const mint = "NineDecMint11111111111111111111111111111111"
+	memo := "forohtoo-reg:wallet1"
+	cfg := config.PaymentGatewayConfig{
+		FeeAssetType: "spl-token",
+		FeeMint:      mint,
+		FeeDecimals:  9,
+		FeeAmount:    2_500_000_000, // 2.5 tokens
+	}
+
+	payment := func(signature string, tokenAmount float64) helius.EnhancedTransaction {
+		raw := fmt.Sprintf("%.0f", tokenAmount*math.Pow10(cfg.FeeAssetDecimals()))
+		return helius.EnhancedTransaction{
+			Signature: signature,
+			Timestamp: time.Now().Unix(),
+			TokenTransfers: []helius.TokenTransfer{{
+				FromUserAccount: "Payer",
+				ToUserAccount:   "ServiceWallet",
+				ToTokenAccount:  "ServiceATA",
+				Mint:            mint,
+				TokenAmount:     tokenAmount,
+			}},
+			AccountData: []helius.AccountData{{
+				Account: "ServiceATA",
+				TokenBalanceChanges: []helius.TokenBalanceChange{{
+					UserAccount:    "ServiceWallet",
+					TokenAccount:   "ServiceATA",
+					Mint:           mint,
+					RawTokenAmount: helius.RawTokenAmount{TokenAmount: raw, Decimals: 9},
+				}},
+			}},
+			Instructions: []helius.InstructionGroup{{
+				ProgramID: "MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr",
+				Data:      base58.Encode([]byte(memo)),
+			}},
+		}
+	}
+	addressMap := map[string]helius.WalletLookup{
+		"ServiceATA": {WalletAddress: "ServiceWallet", Network: "mainnet", AssetType: "spl-token", TokenMint: mint},
+	}
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	parsed := helius.ParseEnhancedTransactions([]helius.EnhancedTransaction{
+		payment("sig-short", 2.4),
+		payment("sig-paid", 2.5),
+	}, addressMap, helius.ParseOptions{}, logger)
+	require.Len(t, parsed, 2)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, p := range parsed {
+			data, _ := json.Marshal(client.Transaction{
+				Signature:   p.Signature,
+				Network:     p.Network,
+				Amount:      p.Amount,
+				TokenType:   *p.TokenMint,
+				Memo:        p.Memo,
+				FromAddress: p.FromAddress,
+				BlockTime:   p.BlockTime,
+			})
+			w.Write([]byte("event: transaction\ndata: " + string(data) + "\n\n"))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	a := NewActivities(nil, nil, client.NewClient(srv.URL, nil, logger), nil, "", nil, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := a.AwaitPayment(ctx, AwaitPaymentInput{
+		PayToAddress: "ServiceWallet",
+		Network:      "mainnet",
+		AssetType:    cfg.FeeAssetType,
+		TokenMint:    cfg.FeeMint,
+		Amount:       cfg.FeeAmount,
+		Memo:         memo,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sig-paid", result.TransactionSignature)
+	assert.Equal(t, cfg.FeeAmount, result.Amount)
+}
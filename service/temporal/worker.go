@@ -3,6 +3,7 @@ package temporal
 import (
 	"fmt"
 	"log/slog"
+	"os"
 
 	forohtoo "github.com/brojonat/forohtoo/client"
 	"github.com/brojonat/forohtoo/service/helius"
@@ -17,18 +18,39 @@ type WorkerConfig struct {
 	TemporalNamespace string
 	TaskQueue         string
 
+	// Identity names this replica in Temporal's activity and workflow task
+	// history. Empty means WorkerIdentity("", "dev").
+	Identity string
+
 	Store          StoreInterface
 	HeliusClient   *helius.Client
 	ForohtooClient *forohtoo.Client
+	AlertPublisher AlertPublisher // optional; receives terminal workflow failure alerts
+	CallbackSecret string         // optional; signs registration-completed callbacks
 	Metrics        *metrics.Metrics
 	Logger         *slog.Logger
 }
 
 // Worker wraps a Temporal worker and provides lifecycle management.
 type Worker struct {
-	client client.Client
-	worker worker.Worker
-	logger *slog.Logger
+	client   client.Client
+	worker   worker.Worker
+	identity string
+	logger   *slog.Logger
+}
+
+// WorkerIdentity returns the Temporal worker identity: override if set,
+// otherwise "<hostname>@<version>". In Kubernetes the hostname is the pod
+// name, so executions can be traced to a pod and build during a rollout.
+func WorkerIdentity(override, version string) string {
+	if override != "" {
+		return override
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return hostname + "@" + version
 }
 
 // NewWorker creates and configures a new Temporal worker for payment-gated
@@ -39,7 +61,11 @@ func NewWorker(config WorkerConfig) (*Worker, error) {
 		config.Logger = slog.Default()
 	}
 
-	logger := config.Logger.With("component", "temporal_worker")
+	if config.Identity == "" {
+		config.Identity = WorkerIdentity("", "dev")
+	}
+
+	logger := config.Logger.With("component", "temporal_worker", "identity", config.Identity)
 
 	logger.Info("creating temporal worker",
 		"host", config.TemporalHost,
@@ -57,31 +83,44 @@ func NewWorker(config WorkerConfig) (*Worker, error) {
 	}
 
 	w := worker.New(c, config.TaskQueue, worker.Options{
+		Identity:                               config.Identity,
 		MaxConcurrentActivityExecutionSize:     10,
 		MaxConcurrentWorkflowTaskExecutionSize: 10,
 	})
 
 	w.RegisterWorkflow(PaymentGatedRegistrationWorkflow)
+	w.RegisterWorkflow(PaymentCallbackWorkflow)
 
 	activities := NewActivities(
 		config.Store,
 		config.HeliusClient,
 		config.ForohtooClient,
+		config.AlertPublisher,
+		config.CallbackSecret,
 		config.Metrics,
 		logger,
 	)
 	w.RegisterActivity(activities.AwaitPayment)
 	w.RegisterActivity(activities.RegisterWallet)
+	w.RegisterActivity(activities.RecordWorkflowFailure)
+	w.RegisterActivity(activities.RecordPaymentFunnel)
+	w.RegisterActivity(activities.DeliverPaymentCallback)
 
 	logger.Info("registered payment-gateway workflow and activities")
 
 	return &Worker{
-		client: c,
-		worker: w,
-		logger: logger,
+		client:   c,
+		worker:   w,
+		identity: config.Identity,
+		logger:   logger,
 	}, nil
 }
 
+// Identity returns the identity the worker reports to Temporal.
+func (w *Worker) Identity() string {
+	return w.identity
+}
+
 // Start begins processing workflows and activities. Non-blocking.
 func (w *Worker) Start() error {
 	w.logger.Info("starting temporal worker")
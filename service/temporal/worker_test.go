@@ -0,0 +1,18 @@
+package temporal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerIdentity(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no hostname: %v", err)
+	}
+
+	assert.Equal(t, hostname+"@v1.2.3", WorkerIdentity("", "v1.2.3"))
+	assert.Equal(t, "worker-a", WorkerIdentity("worker-a", "v1.2.3"), "override wins")
+}
@@ -1,28 +1,52 @@
 package temporal
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/brojonat/forohtoo/service/metrics"
+	enumspb "go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
+// Failure kinds reported by RecordWorkflowFailure.
+const (
+	failureKindTimeout = "timeout" // expected: e.g. the user never paid
+	failureKindError   = "error"   // unexpected: worth alerting on
+)
+
 // PaymentGatedRegistrationInput contains input for payment-gated registration.
 type PaymentGatedRegistrationInput struct {
 	// Wallet to register
-	Address                string  `json:"address"`
-	Network                string  `json:"network"`
-	AssetType              string  `json:"asset_type"`
-	TokenMint              string  `json:"token_mint"`
-	AssociatedTokenAddress *string `json:"associated_token_address"`
+	Address                string          `json:"address"`
+	Network                string          `json:"network"`
+	AssetType              string          `json:"asset_type"`
+	TokenMint              string          `json:"token_mint"`
+	AssociatedTokenAddress *string         `json:"associated_token_address"`
+	Metadata               json.RawMessage `json:"metadata,omitempty"`
+	Tags                   []string        `json:"tags,omitempty"`
 
 	// Payment details
 	ServiceWallet  string        `json:"service_wallet"`  // Forohtoo's wallet
 	ServiceNetwork string        `json:"service_network"` // Where to monitor payment
+	FeeAssetType   string        `json:"fee_asset_type"`  // "sol" or "spl-token"
+	FeeMint        string        `json:"fee_mint,omitempty"`
 	FeeAmount      int64         `json:"fee_amount"`
 	PaymentMemo    string        `json:"payment_memo"`
+	MemoMatch      string        `json:"memo_match,omitempty"` // how PaymentMemo is matched; empty means exact
 	PaymentTimeout time.Duration `json:"payment_timeout"`
+	AllowedSenders []string      `json:"allowed_senders,omitempty"` // empty means any sender
+
+	// RequireFinalized waits up to FinalizationTimeout for the payment to
+	// finalize before registering
+	RequireFinalized    bool          `json:"require_finalized,omitempty"`
+	FinalizationTimeout time.Duration `json:"finalization_timeout,omitempty"`
+
+	// CallbackURL, if set, receives a signed registration-completed POST
+	CallbackURL string `json:"callback_url,omitempty"`
 }
 
 // PaymentGatedRegistrationResult contains the result of payment-gated registration.
@@ -33,16 +57,37 @@ type PaymentGatedRegistrationResult struct {
 	TokenMint        string    `json:"token_mint"`
 	PaymentSignature *string   `json:"payment_signature,omitempty"`
 	PaymentAmount    int64     `json:"payment_amount"`
+	PaymentMemo      *string   `json:"payment_memo,omitempty"` // memo as observed on-chain
 	RegisteredAt     time.Time `json:"registered_at"`
 	Status           string    `json:"status"` // "pending", "completed", "failed"
 	Error            *string   `json:"error,omitempty"`
 }
 
+// RegistrationProgressQuery is answered by PaymentGatedRegistrationWorkflow
+// with its RegistrationProgress.
+const RegistrationProgressQuery = "registration_progress"
+
+// Registration stages reported by RegistrationProgressQuery.
+const (
+	StageAwaitingPayment = "awaiting_payment"
+	StageRegistering     = "registering" // payment detected
+	StageCompleted       = "completed"
+	StageFailed          = "failed"
+)
+
+// RegistrationProgress is how far a payment-gated registration has got.
+type RegistrationProgress struct {
+	Stage            string  `json:"stage"`
+	PaymentMemo      string  `json:"payment_memo"`                // memo the payment must carry
+	PaymentSignature *string `json:"payment_signature,omitempty"` // set once payment is detected
+}
+
 // PaymentGatedRegistrationWorkflow handles wallet registration with payment gating.
 // This workflow:
 // 1. Waits for payment via AwaitPayment activity (uses client.Await over SSE)
 // 2. Registers the wallet and adds it to the Helius webhook
-// 3. Returns registration confirmation
+// 3. Hands off the registration-completed callback, if one was requested
+// 4. Returns registration confirmation
 func PaymentGatedRegistrationWorkflow(ctx workflow.Context, input PaymentGatedRegistrationInput) (*PaymentGatedRegistrationResult, error) {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("PaymentGatedRegistrationWorkflow started",
@@ -58,10 +103,23 @@ func PaymentGatedRegistrationWorkflow(ctx workflow.Context, input PaymentGatedRe
 		TokenMint: input.TokenMint,
 	}
 
-	// Configure activity options
+	// Queries don't touch history, so older executions replay unchanged.
+	progress := RegistrationProgress{Stage: StageAwaitingPayment, PaymentMemo: input.PaymentMemo}
+	if err := workflow.SetQueryHandler(ctx, RegistrationProgressQuery, func() (RegistrationProgress, error) {
+		return progress, nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to register progress query: %w", err)
+	}
+
+	// Configure activity options. A payment detected near the end of the
+	// window still gets its full finality wait.
+	paymentTimeout := input.PaymentTimeout
+	if input.RequireFinalized {
+		paymentTimeout += input.FinalizationTimeout
+	}
 	activityOptions := workflow.ActivityOptions{
-		StartToCloseTimeout: input.PaymentTimeout, // Long timeout for payment wait
-		HeartbeatTimeout:    30 * time.Second,     // Heartbeat every 30s while waiting
+		StartToCloseTimeout: paymentTimeout,   // Long timeout for payment wait
+		HeartbeatTimeout:    30 * time.Second, // Heartbeat every 30s while waiting
 		RetryPolicy: &temporal.RetryPolicy{
 			InitialInterval:    time.Second,
 			BackoffCoefficient: 2.0,
@@ -75,9 +133,16 @@ func PaymentGatedRegistrationWorkflow(ctx workflow.Context, input PaymentGatedRe
 	awaitInput := AwaitPaymentInput{
 		PayToAddress:   input.ServiceWallet,
 		Network:        input.ServiceNetwork,
+		AssetType:      input.FeeAssetType,
+		TokenMint:      input.FeeMint,
 		Amount:         input.FeeAmount,
 		Memo:           input.PaymentMemo,
+		MemoMatch:      input.MemoMatch,
 		LookbackPeriod: 24 * time.Hour, // Check last 24h in case payment came before workflow started
+		AllowedSenders: input.AllowedSenders,
+
+		RequireFinalized:    input.RequireFinalized,
+		FinalizationTimeout: input.FinalizationTimeout,
 	}
 
 	var awaitResult *AwaitPaymentResult
@@ -87,16 +152,26 @@ func PaymentGatedRegistrationWorkflow(ctx workflow.Context, input PaymentGatedRe
 		errMsg := fmt.Sprintf("payment await failed: %v", err)
 		result.Error = &errMsg
 		result.Status = "failed"
+		progress.Stage = StageFailed
+		recordPaymentWorkflowFailure(ctx, input, "await_payment", err)
+		if classifyFailure(err) == failureKindTimeout {
+			recordPaymentFunnel(ctx, input, metrics.FunnelTimedOut)
+		}
 		return result, fmt.Errorf("payment await failed: %w", err)
 	}
 
 	logger.Info("payment received",
 		"txn_signature", awaitResult.TransactionSignature,
 		"amount", awaitResult.Amount,
+		"memo", awaitResult.Memo,
 	)
 
 	result.PaymentSignature = &awaitResult.TransactionSignature
 	result.PaymentAmount = awaitResult.Amount
+	result.PaymentMemo = awaitResult.Memo
+	progress.Stage = StageRegistering
+	progress.PaymentSignature = result.PaymentSignature
+	recordPaymentFunnel(ctx, input, metrics.FunnelPaymentDetected)
 
 	// Step 2: Register wallet
 	registerInput := RegisterWalletInput{
@@ -105,6 +180,8 @@ func PaymentGatedRegistrationWorkflow(ctx workflow.Context, input PaymentGatedRe
 		AssetType:              input.AssetType,
 		TokenMint:              input.TokenMint,
 		AssociatedTokenAddress: input.AssociatedTokenAddress,
+		Metadata:               input.Metadata,
+		Tags:                   input.Tags,
 	}
 
 	var registerResult *RegisterWalletResult
@@ -114,6 +191,8 @@ func PaymentGatedRegistrationWorkflow(ctx workflow.Context, input PaymentGatedRe
 		errMsg := fmt.Sprintf("wallet registration failed: %v", err)
 		result.Error = &errMsg
 		result.Status = "failed"
+		progress.Stage = StageFailed
+		recordPaymentWorkflowFailure(ctx, input, "register_wallet", err)
 		return result, fmt.Errorf("wallet registration failed: %w", err)
 	}
 
@@ -125,6 +204,141 @@ func PaymentGatedRegistrationWorkflow(ctx workflow.Context, input PaymentGatedRe
 
 	result.RegisteredAt = workflow.Now(ctx)
 	result.Status = "completed"
+	progress.Stage = StageCompleted
+	recordPaymentFunnel(ctx, input, metrics.FunnelRegistrationCompleted)
+
+	// Step 3: Notify the integrator
+	if input.CallbackURL != "" {
+		startPaymentCallback(ctx, input, result)
+	}
 
 	return result, nil
 }
+
+// classifyFailure distinguishes expected timeouts (the activity ran out its
+// start-to-close or schedule-to-close budget, e.g. nobody paid) from
+// unexpected errors. Heartbeat timeouts mean a worker died, so they count as
+// errors.
+func classifyFailure(err error) string {
+	var timeoutErr *temporal.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		switch timeoutErr.TimeoutType() {
+		case enumspb.TIMEOUT_TYPE_START_TO_CLOSE, enumspb.TIMEOUT_TYPE_SCHEDULE_TO_CLOSE:
+			return failureKindTimeout
+		}
+	}
+	return failureKindError
+}
+
+// recordPaymentWorkflowFailure runs the RecordWorkflowFailure activity with a
+// short timeout. Failures to record are logged and otherwise ignored so they
+// never mask the original error.
+func recordPaymentWorkflowFailure(ctx workflow.Context, input PaymentGatedRegistrationInput, step string, cause error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 3,
+		},
+	})
+
+	info := workflow.GetInfo(ctx)
+	failure := RecordWorkflowFailureInput{
+		WorkflowID:   info.WorkflowExecution.ID,
+		WorkflowType: info.WorkflowType.Name,
+		Step:         step,
+		Kind:         classifyFailure(cause),
+		Reason:       cause.Error(),
+		Address:      input.Address,
+		Network:      input.Network,
+	}
+
+	if err := workflow.ExecuteActivity(ctx, "RecordWorkflowFailure", failure).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("failed to record workflow failure", "error", err)
+	}
+}
+
+// paymentFunnelChangeID versions the funnel activities, which were added to
+// the middle of the workflow; histories from before them skip recording.
+const paymentFunnelChangeID = "payment-funnel-metrics"
+
+// recordPaymentFunnel runs the RecordPaymentFunnel activity for a stage.
+// Completed activities aren't re-run on replay and the activity gets a single
+// attempt, so each stage is counted at most once per workflow. Failures are
+// logged and otherwise ignored.
+func recordPaymentFunnel(ctx workflow.Context, input PaymentGatedRegistrationInput, stage string) {
+	if workflow.GetVersion(ctx, paymentFunnelChangeID, workflow.DefaultVersion, 1) == workflow.DefaultVersion {
+		return
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 1,
+		},
+	})
+
+	funnel := RecordPaymentFunnelInput{
+		Network: input.Network,
+		Stage:   stage,
+	}
+	if err := workflow.ExecuteActivity(ctx, "RecordPaymentFunnel", funnel).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("failed to record payment funnel stage", "stage", stage, "error", err)
+	}
+}
+
+// paymentCallbackMaxAttempts bounds delivery of a registration-completed
+// callback. With the backoff below, retries span roughly an hour.
+const paymentCallbackMaxAttempts = 10
+
+// startPaymentCallback starts PaymentCallbackWorkflow as an abandoned child so
+// callback retries neither hold the registration open (its status would read
+// "pending") nor can fail it. It waits only for the child to start. Failures
+// to start are logged and otherwise ignored.
+func startPaymentCallback(ctx workflow.Context, input PaymentGatedRegistrationInput, result *PaymentGatedRegistrationResult) {
+	workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	ctx = workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        workflowID + ":callback",
+		ParentClosePolicy: enumspb.PARENT_CLOSE_POLICY_ABANDON,
+	})
+
+	callback := DeliverPaymentCallbackInput{
+		URL: input.CallbackURL,
+		Payload: PaymentCallbackPayload{
+			Event:            PaymentCallbackEvent,
+			WorkflowID:       workflowID,
+			Address:          result.Address,
+			Network:          result.Network,
+			AssetType:        result.AssetType,
+			TokenMint:        result.TokenMint,
+			PaymentSignature: *result.PaymentSignature,
+			PaymentAmount:    result.PaymentAmount,
+			FeeAssetType:     input.FeeAssetType,
+			FeeMint:          input.FeeMint,
+			RegisteredAt:     result.RegisteredAt,
+		},
+	}
+
+	child := workflow.ExecuteChildWorkflow(ctx, PaymentCallbackWorkflow, callback)
+	if err := child.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("failed to start payment callback workflow", "error", err)
+	}
+}
+
+// PaymentCallbackWorkflow delivers a registration-completed callback, retrying
+// with backoff. It runs detached from the registration that started it.
+func PaymentCallbackWorkflow(ctx workflow.Context, input DeliverPaymentCallbackInput) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    5 * time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    15 * time.Minute,
+			MaximumAttempts:    paymentCallbackMaxAttempts,
+		},
+	})
+
+	if err := workflow.ExecuteActivity(ctx, "DeliverPaymentCallback", input).Get(ctx, nil); err != nil {
+		return fmt.Errorf("payment callback delivery failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brojonat/forohtoo/service/metrics"
+	natspkg "github.com/brojonat/forohtoo/service/nats"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"start to close", temporal.NewTimeoutError(enumspb.TIMEOUT_TYPE_START_TO_CLOSE, nil), failureKindTimeout},
+		{"schedule to close", temporal.NewTimeoutError(enumspb.TIMEOUT_TYPE_SCHEDULE_TO_CLOSE, nil), failureKindTimeout},
+		{"heartbeat", temporal.NewTimeoutError(enumspb.TIMEOUT_TYPE_HEARTBEAT, nil), failureKindError},
+		{"application error", errors.New("helius down"), failureKindError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyFailure(tt.err))
+		})
+	}
+}
+
+func TestPaymentGatedRegistrationWorkflow_RecordsFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		awaitErr error
+		wantKind string
+	}{
+		{"payment timeout", temporal.NewTimeoutError(enumspb.TIMEOUT_TYPE_START_TO_CLOSE, nil), failureKindTimeout},
+		{"unexpected error", temporal.NewNonRetryableApplicationError("sse failed", "test", nil), failureKindError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ts testsuite.WorkflowTestSuite
+			env := ts.NewTestWorkflowEnvironment()
+			env.RegisterWorkflow(PaymentGatedRegistrationWorkflow)
+			env.RegisterActivity(&Activities{})
+
+			env.OnActivity("AwaitPayment", mock.Anything, mock.Anything).Return(nil, tt.awaitErr)
+
+			var recorded RecordWorkflowFailureInput
+			env.OnActivity("RecordWorkflowFailure", mock.Anything, mock.Anything).Return(
+				func(_ context.Context, input RecordWorkflowFailureInput) error {
+					recorded = input
+					return nil
+				})
+
+			env.ExecuteWorkflow(PaymentGatedRegistrationWorkflow, PaymentGatedRegistrationInput{
+				Address:        "wallet1",
+				Network:        "mainnet",
+				PaymentTimeout: time.Minute,
+			})
+
+			require.True(t, env.IsWorkflowCompleted())
+			require.Error(t, env.GetWorkflowError())
+			assert.Equal(t, "PaymentGatedRegistrationWorkflow", recorded.WorkflowType)
+			assert.Equal(t, "await_payment", recorded.Step)
+			assert.Equal(t, tt.wantKind, recorded.Kind)
+			assert.Equal(t, "wallet1", recorded.Address)
+			assert.NotEmpty(t, recorded.WorkflowID)
+		})
+	}
+}
+
+func TestPaymentGatedRegistrationWorkflow_AwaitsFeeAsset(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(PaymentGatedRegistrationWorkflow)
+	env.RegisterActivity(&Activities{})
+
+	var awaited AwaitPaymentInput
+	env.OnActivity("AwaitPayment", mock.Anything, mock.Anything).Return(
+		func(_ context.Context, input AwaitPaymentInput) (*AwaitPaymentResult, error) {
+			awaited = input
+			return nil, temporal.NewNonRetryableApplicationError("stop", "test", nil)
+		})
+	env.OnActivity("RecordWorkflowFailure", mock.Anything, mock.Anything).Return(nil)
+
+	env.ExecuteWorkflow(PaymentGatedRegistrationWorkflow, PaymentGatedRegistrationInput{
+		Address:        "wallet1",
+		Network:        "mainnet",
+		ServiceWallet:  "ServiceWallet",
+		ServiceNetwork: "devnet",
+		FeeAssetType:   "sol",
+		FeeAmount:      10000000,
+		PaymentTimeout: time.Minute,
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	assert.Equal(t, "ServiceWallet", awaited.PayToAddress)
+	assert.Equal(t, "devnet", awaited.Network)
+	assert.Equal(t, "sol", awaited.AssetType)
+	assert.Empty(t, awaited.TokenMint)
+	assert.Equal(t, int64(10000000), awaited.Amount)
+}
+
+type fakeAlertPublisher struct {
+	events []*natspkg.WorkflowFailureEvent
+	err    error
+}
+
+func (f *fakeAlertPublisher) PublishWorkflowFailure(ctx context.Context, event *natspkg.WorkflowFailureEvent) error {
+	f.events = append(f.events, event)
+	return f.err
+}
+
+func TestRecordWorkflowFailure_PublishesAlert(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+	alerts := &fakeAlertPublisher{err: errors.New("nats unavailable")}
+	a := NewActivities(nil, nil, nil, alerts, "", nil, logger)
+
+	err := a.RecordWorkflowFailure(context.Background(), RecordWorkflowFailureInput{
+		WorkflowID:   "payment-registration:abc",
+		WorkflowType: "PaymentGatedRegistrationWorkflow",
+		Step:         "register_wallet",
+		Kind:         failureKindError,
+		Reason:       "helius down",
+	})
+
+	require.NoError(t, err, "publish failures must not fail the activity")
+	require.Len(t, alerts.events, 1)
+	assert.Equal(t, "register_wallet", alerts.events[0].Step)
+	assert.Equal(t, failureKindError, alerts.events[0].Kind)
+	assert.False(t, alerts.events[0].FailedAt.IsZero())
+}
+
+func TestPaymentGatedRegistrationWorkflow_RecordsFunnel(t *testing.T) {
+	tests := []struct {
+		name       string
+		awaitErr   error
+		wantStages []string
+	}{
+		{"completed", nil, []string{metrics.FunnelPaymentDetected, metrics.FunnelRegistrationCompleted}},
+		{"timed out", temporal.NewTimeoutError(enumspb.TIMEOUT_TYPE_START_TO_CLOSE, nil), []string{metrics.FunnelTimedOut}},
+		{"unexpected error", temporal.NewNonRetryableApplicationError("sse failed", "test", nil), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ts testsuite.WorkflowTestSuite
+			env := ts.NewTestWorkflowEnvironment()
+			env.RegisterWorkflow(PaymentGatedRegistrationWorkflow)
+			env.RegisterActivity(&Activities{})
+
+			if tt.awaitErr != nil {
+				env.OnActivity("AwaitPayment", mock.Anything, mock.Anything).Return(nil, tt.awaitErr)
+			} else {
+				env.OnActivity("AwaitPayment", mock.Anything, mock.Anything).Return(&AwaitPaymentResult{TransactionSignature: "sig1", Amount: 1000000}, nil)
+			}
+			env.OnActivity("RegisterWallet", mock.Anything, mock.Anything).Return(&RegisterWalletResult{Address: "wallet1", Status: "active"}, nil)
+			env.OnActivity("RecordWorkflowFailure", mock.Anything, mock.Anything).Return(nil)
+
+			var stages []string
+			env.OnActivity("RecordPaymentFunnel", mock.Anything, mock.Anything).Return(
+				func(_ context.Context, input RecordPaymentFunnelInput) error {
+					assert.Equal(t, "devnet", input.Network)
+					stages = append(stages, input.Stage)
+					return nil
+				})
+
+			env.ExecuteWorkflow(PaymentGatedRegistrationWorkflow, PaymentGatedRegistrationInput{
+				Address:        "wallet1",
+				Network:        "devnet",
+				PaymentTimeout: time.Minute,
+			})
+
+			require.True(t, env.IsWorkflowCompleted())
+			assert.Equal(t, tt.wantStages, stages)
+		})
+	}
+}
+
+func TestRecordPaymentFunnel(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	reg := prometheus.NewRegistry()
+	a := NewActivities(nil, nil, nil, nil, "", metrics.NewMetrics(reg), logger)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, a.RecordPaymentFunnel(context.Background(), RecordPaymentFunnelInput{Network: "mainnet", Stage: metrics.FunnelPaymentDetected}))
+	}
+
+	expected := `
+# HELP payment_funnel_events_total Total number of payment-gated registrations reaching each funnel stage, by network and stage
+# TYPE payment_funnel_events_total counter
+payment_funnel_events_total{network="mainnet",stage="payment_detected"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "payment_funnel_events_total"))
+}
+
+func TestPaymentGatedRegistrationWorkflow_Callback(t *testing.T) {
+	tests := []struct {
+		name        string
+		callbackURL string
+		deliverErr  error
+		wantCalls   int
+	}{
+		{"no callback requested", "", nil, 0},
+		{"delivered", "https://example.com/paid", nil, 1},
+		{"delivery failure doesn't fail registration", "https://example.com/paid", temporal.NewNonRetryableApplicationError("rejected", "callback_rejected", nil), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ts testsuite.WorkflowTestSuite
+			env := ts.NewTestWorkflowEnvironment()
+			env.RegisterWorkflow(PaymentGatedRegistrationWorkflow)
+			env.RegisterWorkflow(PaymentCallbackWorkflow)
+			env.RegisterActivity(&Activities{})
+
+			env.OnActivity("AwaitPayment", mock.Anything, mock.Anything).Return(&AwaitPaymentResult{TransactionSignature: "sig1", Amount: 1000000}, nil)
+			env.OnActivity("RegisterWallet", mock.Anything, mock.Anything).Return(&RegisterWalletResult{Address: "wallet1", Status: "active"}, nil)
+			env.OnActivity("RecordPaymentFunnel", mock.Anything, mock.Anything).Return(nil)
+			env.OnActivity("RecordWorkflowFailure", mock.Anything, mock.Anything).Return(nil)
+
+			var delivered []DeliverPaymentCallbackInput
+			env.OnActivity("DeliverPaymentCallback", mock.Anything, mock.Anything).Return(
+				func(_ context.Context, input DeliverPaymentCallbackInput) error {
+					delivered = append(delivered, input)
+					return tt.deliverErr
+				})
+
+			env.ExecuteWorkflow(PaymentGatedRegistrationWorkflow, PaymentGatedRegistrationInput{
+				Address:        "wallet1",
+				Network:        "devnet",
+				AssetType:      "sol",
+				FeeAssetType:   "sol",
+				PaymentTimeout: time.Minute,
+				CallbackURL:    tt.callbackURL,
+			})
+
+			require.True(t, env.IsWorkflowCompleted())
+			require.NoError(t, env.GetWorkflowError())
+			var result PaymentGatedRegistrationResult
+			require.NoError(t, env.GetWorkflowResult(&result))
+			assert.Equal(t, "completed", result.Status)
+
+			require.Len(t, delivered, tt.wantCalls)
+			if tt.wantCalls > 0 {
+				assert.Equal(t, tt.callbackURL, delivered[0].URL)
+				assert.Equal(t, "registration.completed", delivered[0].Payload.Event)
+				assert.Equal(t, "wallet1", delivered[0].Payload.Address)
+				assert.Equal(t, "sig1", delivered[0].Payload.PaymentSignature)
+				assert.Equal(t, int64(1000000), delivered[0].Payload.PaymentAmount)
+				assert.Equal(t, "sol", delivered[0].Payload.FeeAssetType)
+			}
+		})
+	}
+}
+
+func TestPaymentGatedRegistrationWorkflow_ProgressQuery(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(PaymentGatedRegistrationWorkflow)
+	env.RegisterActivity(&Activities{})
+
+	env.OnActivity("AwaitPayment", mock.Anything, mock.Anything).After(time.Hour).Return(&AwaitPaymentResult{TransactionSignature: "sig1", Amount: 1000000}, nil)
+	env.OnActivity("RegisterWallet", mock.Anything, mock.Anything).Return(&RegisterWalletResult{Address: "wallet1", Status: "active"}, nil)
+	env.OnActivity("RecordPaymentFunnel", mock.Anything, mock.Anything).Return(nil)
+
+	queryProgress := func() RegistrationProgress {
+		value, err := env.QueryWorkflow(RegistrationProgressQuery)
+		require.NoError(t, err)
+		var progress RegistrationProgress
+		require.NoError(t, value.Get(&progress))
+		return progress
+	}
+
+	var awaiting RegistrationProgress
+	env.RegisterDelayedCallback(func() {
+		awaiting = queryProgress()
+	}, time.Minute)
+
+	env.ExecuteWorkflow(PaymentGatedRegistrationWorkflow, PaymentGatedRegistrationInput{
+		Address:        "wallet1",
+		Network:        "devnet",
+		PaymentMemo:    "forohtoo-reg:abc",
+		PaymentTimeout: 2 * time.Hour,
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	assert.Equal(t, RegistrationProgress{Stage: StageAwaitingPayment, PaymentMemo: "forohtoo-reg:abc"}, awaiting)
+
+	done := queryProgress()
+	assert.Equal(t, StageCompleted, done.Stage)
+	require.NotNil(t, done.PaymentSignature)
+	assert.Equal(t, "sig1", *done.PaymentSignature)
+}